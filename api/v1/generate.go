@@ -0,0 +1,5 @@
+// Package golizerv1 is the generated Go code of golizer.proto, the gRPC
+// control and telemetry API.
+package golizerv1
+
+//go:generate buf generate
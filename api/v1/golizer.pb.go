@@ -0,0 +1,2017 @@
+// The gRPC control and telemetry API of golizer. It mirrors the REST API
+// under /api/v1 (see GET /api/v1/spec) and adds a stream of the analyzed
+// audio features for programs that react to the music themselves.
+//
+// Regenerate the Go code with `go generate ./api/...` (needs buf,
+// protoc-gen-go and protoc-gen-go-grpc on the PATH).
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: golizer.proto
+
+package golizerv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_golizer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{0}
+}
+
+type Status struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fps           float64                `protobuf:"fixed64,1,opt,name=fps,proto3" json:"fps,omitempty"`
+	Features      *Features              `protobuf:"bytes,2,opt,name=features,proto3" json:"features,omitempty"`
+	Palette       string                 `protobuf:"bytes,3,opt,name=palette,proto3" json:"palette,omitempty"`
+	Pattern       string                 `protobuf:"bytes,4,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	ColorMode     string                 `protobuf:"bytes,5,opt,name=color_mode,json=colorMode,proto3" json:"color_mode,omitempty"`
+	Quality       string                 `protobuf:"bytes,6,opt,name=quality,proto3" json:"quality,omitempty"`
+	ShowStatusBar bool                   `protobuf:"varint,7,opt,name=show_status_bar,json=showStatusBar,proto3" json:"show_status_bar,omitempty"`
+	ReadOnly      bool                   `protobuf:"varint,8,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	// BPM while tap tempo or an incoming MIDI clock drives the beat clock,
+	// 0 otherwise.
+	TapTempo    float64        `protobuf:"fixed64,9,opt,name=tap_tempo,json=tapTempo,proto3" json:"tap_tempo,omitempty"`
+	MidiClock   float64        `protobuf:"fixed64,10,opt,name=midi_clock,json=midiClock,proto3" json:"midi_clock,omitempty"`
+	RandomLocks *RandomLocks   `protobuf:"bytes,11,opt,name=random_locks,json=randomLocks,proto3" json:"random_locks,omitempty"`
+	Playlist    *PlaylistState `protobuf:"bytes,12,opt,name=playlist,proto3" json:"playlist,omitempty"`
+	// paused is set while the frame is held.
+	Paused        bool `protobuf:"varint,13,opt,name=paused,proto3" json:"paused,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	mi := &file_golizer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Status) GetFps() float64 {
+	if x != nil {
+		return x.Fps
+	}
+	return 0
+}
+
+func (x *Status) GetFeatures() *Features {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+func (x *Status) GetPalette() string {
+	if x != nil {
+		return x.Palette
+	}
+	return ""
+}
+
+func (x *Status) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *Status) GetColorMode() string {
+	if x != nil {
+		return x.ColorMode
+	}
+	return ""
+}
+
+func (x *Status) GetQuality() string {
+	if x != nil {
+		return x.Quality
+	}
+	return ""
+}
+
+func (x *Status) GetShowStatusBar() bool {
+	if x != nil {
+		return x.ShowStatusBar
+	}
+	return false
+}
+
+func (x *Status) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+func (x *Status) GetTapTempo() float64 {
+	if x != nil {
+		return x.TapTempo
+	}
+	return 0
+}
+
+func (x *Status) GetMidiClock() float64 {
+	if x != nil {
+		return x.MidiClock
+	}
+	return 0
+}
+
+func (x *Status) GetRandomLocks() *RandomLocks {
+	if x != nil {
+		return x.RandomLocks
+	}
+	return nil
+}
+
+func (x *Status) GetPlaylist() *PlaylistState {
+	if x != nil {
+		return x.Playlist
+	}
+	return nil
+}
+
+func (x *Status) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+type RandomLocks struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pattern       bool                   `protobuf:"varint,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	Palette       bool                   `protobuf:"varint,2,opt,name=palette,proto3" json:"palette,omitempty"`
+	ColorMode     bool                   `protobuf:"varint,3,opt,name=color_mode,json=colorMode,proto3" json:"color_mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RandomLocks) Reset() {
+	*x = RandomLocks{}
+	mi := &file_golizer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RandomLocks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RandomLocks) ProtoMessage() {}
+
+func (x *RandomLocks) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RandomLocks.ProtoReflect.Descriptor instead.
+func (*RandomLocks) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RandomLocks) GetPattern() bool {
+	if x != nil {
+		return x.Pattern
+	}
+	return false
+}
+
+func (x *RandomLocks) GetPalette() bool {
+	if x != nil {
+		return x.Palette
+	}
+	return false
+}
+
+func (x *RandomLocks) GetColorMode() bool {
+	if x != nil {
+		return x.ColorMode
+	}
+	return false
+}
+
+type PlaylistState struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Playing bool                   `protobuf:"varint,1,opt,name=playing,proto3" json:"playing,omitempty"`
+	Step    int32                  `protobuf:"varint,2,opt,name=step,proto3" json:"step,omitempty"`
+	// progress is the share of the current step that has played.
+	Progress      float64 `protobuf:"fixed64,3,opt,name=progress,proto3" json:"progress,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaylistState) Reset() {
+	*x = PlaylistState{}
+	mi := &file_golizer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaylistState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaylistState) ProtoMessage() {}
+
+func (x *PlaylistState) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaylistState.ProtoReflect.Descriptor instead.
+func (*PlaylistState) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PlaylistState) GetPlaying() bool {
+	if x != nil {
+		return x.Playing
+	}
+	return false
+}
+
+func (x *PlaylistState) GetStep() int32 {
+	if x != nil {
+		return x.Step
+	}
+	return 0
+}
+
+func (x *PlaylistState) GetProgress() float64 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+type Envelope struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AttackMs      float64                `protobuf:"fixed64,1,opt,name=attack_ms,json=attackMs,proto3" json:"attack_ms,omitempty"`
+	ReleaseMs     float64                `protobuf:"fixed64,2,opt,name=release_ms,json=releaseMs,proto3" json:"release_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_golizer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Envelope) GetAttackMs() float64 {
+	if x != nil {
+		return x.AttackMs
+	}
+	return 0
+}
+
+func (x *Envelope) GetReleaseMs() float64 {
+	if x != nil {
+		return x.ReleaseMs
+	}
+	return 0
+}
+
+type UpdateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// params are the panel knobs by field name (Speed, Brightness,
+	// BassInfluence ...); knobs left out or set to 0 stay.
+	Params     map[string]float64 `protobuf:"bytes,1,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	Palette    *string            `protobuf:"bytes,2,opt,name=palette,proto3,oneof" json:"palette,omitempty"`
+	Pattern    *string            `protobuf:"bytes,3,opt,name=pattern,proto3,oneof" json:"pattern,omitempty"`
+	ColorMode  *string            `protobuf:"bytes,4,opt,name=color_mode,json=colorMode,proto3,oneof" json:"color_mode,omitempty"`
+	Quality    *string            `protobuf:"bytes,5,opt,name=quality,proto3,oneof" json:"quality,omitempty"`
+	NoiseFloor *float64           `protobuf:"fixed64,6,opt,name=noise_floor,json=noiseFloor,proto3,oneof" json:"noise_floor,omitempty"`
+	BufferSize *int32             `protobuf:"varint,7,opt,name=buffer_size,json=bufferSize,proto3,oneof" json:"buffer_size,omitempty"`
+	// envelopes are keyed by band: sub, bass, lowMid, mid, highMid, treble.
+	Envelopes     map[string]*Envelope `protobuf:"bytes,8,rep,name=envelopes,proto3" json:"envelopes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Width         *int32               `protobuf:"varint,9,opt,name=width,proto3,oneof" json:"width,omitempty"`
+	Height        *int32               `protobuf:"varint,10,opt,name=height,proto3,oneof" json:"height,omitempty"`
+	AutoRandomize *bool                `protobuf:"varint,11,opt,name=auto_randomize,json=autoRandomize,proto3,oneof" json:"auto_randomize,omitempty"`
+	// random_interval is in seconds.
+	RandomInterval *int32       `protobuf:"varint,12,opt,name=random_interval,json=randomInterval,proto3,oneof" json:"random_interval,omitempty"`
+	ShowStatusBar  *bool        `protobuf:"varint,13,opt,name=show_status_bar,json=showStatusBar,proto3,oneof" json:"show_status_bar,omitempty"`
+	RandomLocks    *RandomLocks `protobuf:"bytes,14,opt,name=random_locks,json=randomLocks,proto3,oneof" json:"random_locks,omitempty"`
+	// paused holds the current frame on screen or lets it run again.
+	Paused        *bool `protobuf:"varint,15,opt,name=paused,proto3,oneof" json:"paused,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRequest) Reset() {
+	*x = UpdateRequest{}
+	mi := &file_golizer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRequest) ProtoMessage() {}
+
+func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateRequest) GetParams() map[string]float64 {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *UpdateRequest) GetPalette() string {
+	if x != nil && x.Palette != nil {
+		return *x.Palette
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetPattern() string {
+	if x != nil && x.Pattern != nil {
+		return *x.Pattern
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetColorMode() string {
+	if x != nil && x.ColorMode != nil {
+		return *x.ColorMode
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetQuality() string {
+	if x != nil && x.Quality != nil {
+		return *x.Quality
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetNoiseFloor() float64 {
+	if x != nil && x.NoiseFloor != nil {
+		return *x.NoiseFloor
+	}
+	return 0
+}
+
+func (x *UpdateRequest) GetBufferSize() int32 {
+	if x != nil && x.BufferSize != nil {
+		return *x.BufferSize
+	}
+	return 0
+}
+
+func (x *UpdateRequest) GetEnvelopes() map[string]*Envelope {
+	if x != nil {
+		return x.Envelopes
+	}
+	return nil
+}
+
+func (x *UpdateRequest) GetWidth() int32 {
+	if x != nil && x.Width != nil {
+		return *x.Width
+	}
+	return 0
+}
+
+func (x *UpdateRequest) GetHeight() int32 {
+	if x != nil && x.Height != nil {
+		return *x.Height
+	}
+	return 0
+}
+
+func (x *UpdateRequest) GetAutoRandomize() bool {
+	if x != nil && x.AutoRandomize != nil {
+		return *x.AutoRandomize
+	}
+	return false
+}
+
+func (x *UpdateRequest) GetRandomInterval() int32 {
+	if x != nil && x.RandomInterval != nil {
+		return *x.RandomInterval
+	}
+	return 0
+}
+
+func (x *UpdateRequest) GetShowStatusBar() bool {
+	if x != nil && x.ShowStatusBar != nil {
+		return *x.ShowStatusBar
+	}
+	return false
+}
+
+func (x *UpdateRequest) GetRandomLocks() *RandomLocks {
+	if x != nil {
+		return x.RandomLocks
+	}
+	return nil
+}
+
+func (x *UpdateRequest) GetPaused() bool {
+	if x != nil && x.Paused != nil {
+		return *x.Paused
+	}
+	return false
+}
+
+type UpdateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateResponse) Reset() {
+	*x = UpdateResponse{}
+	mi := &file_golizer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateResponse) ProtoMessage() {}
+
+func (x *UpdateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateResponse.ProtoReflect.Descriptor instead.
+func (*UpdateResponse) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{6}
+}
+
+type ListPatternsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPatternsRequest) Reset() {
+	*x = ListPatternsRequest{}
+	mi := &file_golizer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPatternsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPatternsRequest) ProtoMessage() {}
+
+func (x *ListPatternsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPatternsRequest.ProtoReflect.Descriptor instead.
+func (*ListPatternsRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{7}
+}
+
+type ListPatternsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Names         []string               `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPatternsResponse) Reset() {
+	*x = ListPatternsResponse{}
+	mi := &file_golizer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPatternsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPatternsResponse) ProtoMessage() {}
+
+func (x *ListPatternsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPatternsResponse.ProtoReflect.Descriptor instead.
+func (*ListPatternsResponse) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListPatternsResponse) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+type ListPalettesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPalettesRequest) Reset() {
+	*x = ListPalettesRequest{}
+	mi := &file_golizer_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPalettesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPalettesRequest) ProtoMessage() {}
+
+func (x *ListPalettesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPalettesRequest.ProtoReflect.Descriptor instead.
+func (*ListPalettesRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{9}
+}
+
+type ListPalettesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Names         []string               `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPalettesResponse) Reset() {
+	*x = ListPalettesResponse{}
+	mi := &file_golizer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPalettesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPalettesResponse) ProtoMessage() {}
+
+func (x *ListPalettesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPalettesResponse.ProtoReflect.Descriptor instead.
+func (*ListPalettesResponse) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListPalettesResponse) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+type ListColorModesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListColorModesRequest) Reset() {
+	*x = ListColorModesRequest{}
+	mi := &file_golizer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListColorModesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListColorModesRequest) ProtoMessage() {}
+
+func (x *ListColorModesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListColorModesRequest.ProtoReflect.Descriptor instead.
+func (*ListColorModesRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{11}
+}
+
+type ListColorModesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Names         []string               `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListColorModesResponse) Reset() {
+	*x = ListColorModesResponse{}
+	mi := &file_golizer_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListColorModesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListColorModesResponse) ProtoMessage() {}
+
+func (x *ListColorModesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListColorModesResponse.ProtoReflect.Descriptor instead.
+func (*ListColorModesResponse) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListColorModesResponse) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+type Preset struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Pattern       string                 `protobuf:"bytes,2,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	Palette       string                 `protobuf:"bytes,3,opt,name=palette,proto3" json:"palette,omitempty"`
+	ColorMode     string                 `protobuf:"bytes,4,opt,name=color_mode,json=colorMode,proto3" json:"color_mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Preset) Reset() {
+	*x = Preset{}
+	mi := &file_golizer_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Preset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Preset) ProtoMessage() {}
+
+func (x *Preset) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Preset.ProtoReflect.Descriptor instead.
+func (*Preset) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Preset) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Preset) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *Preset) GetPalette() string {
+	if x != nil {
+		return x.Palette
+	}
+	return ""
+}
+
+func (x *Preset) GetColorMode() string {
+	if x != nil {
+		return x.ColorMode
+	}
+	return ""
+}
+
+type ListPresetsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPresetsRequest) Reset() {
+	*x = ListPresetsRequest{}
+	mi := &file_golizer_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPresetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPresetsRequest) ProtoMessage() {}
+
+func (x *ListPresetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPresetsRequest.ProtoReflect.Descriptor instead.
+func (*ListPresetsRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{14}
+}
+
+type ListPresetsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Presets       []*Preset              `protobuf:"bytes,1,rep,name=presets,proto3" json:"presets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPresetsResponse) Reset() {
+	*x = ListPresetsResponse{}
+	mi := &file_golizer_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPresetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPresetsResponse) ProtoMessage() {}
+
+func (x *ListPresetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPresetsResponse.ProtoReflect.Descriptor instead.
+func (*ListPresetsResponse) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListPresetsResponse) GetPresets() []*Preset {
+	if x != nil {
+		return x.Presets
+	}
+	return nil
+}
+
+type SavePresetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SavePresetRequest) Reset() {
+	*x = SavePresetRequest{}
+	mi := &file_golizer_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SavePresetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SavePresetRequest) ProtoMessage() {}
+
+func (x *SavePresetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SavePresetRequest.ProtoReflect.Descriptor instead.
+func (*SavePresetRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SavePresetRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type LoadPresetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoadPresetRequest) Reset() {
+	*x = LoadPresetRequest{}
+	mi := &file_golizer_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoadPresetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadPresetRequest) ProtoMessage() {}
+
+func (x *LoadPresetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadPresetRequest.ProtoReflect.Descriptor instead.
+func (*LoadPresetRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *LoadPresetRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type LoadPresetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoadPresetResponse) Reset() {
+	*x = LoadPresetResponse{}
+	mi := &file_golizer_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoadPresetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadPresetResponse) ProtoMessage() {}
+
+func (x *LoadPresetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadPresetResponse.ProtoReflect.Descriptor instead.
+func (*LoadPresetResponse) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{18}
+}
+
+type DeletePresetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePresetRequest) Reset() {
+	*x = DeletePresetRequest{}
+	mi := &file_golizer_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePresetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePresetRequest) ProtoMessage() {}
+
+func (x *DeletePresetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePresetRequest.ProtoReflect.Descriptor instead.
+func (*DeletePresetRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DeletePresetRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeletePresetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePresetResponse) Reset() {
+	*x = DeletePresetResponse{}
+	mi := &file_golizer_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePresetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePresetResponse) ProtoMessage() {}
+
+func (x *DeletePresetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePresetResponse.ProtoReflect.Descriptor instead.
+func (*DeletePresetResponse) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{20}
+}
+
+type PlayPlaylistRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlayPlaylistRequest) Reset() {
+	*x = PlayPlaylistRequest{}
+	mi := &file_golizer_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlayPlaylistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlayPlaylistRequest) ProtoMessage() {}
+
+func (x *PlayPlaylistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlayPlaylistRequest.ProtoReflect.Descriptor instead.
+func (*PlayPlaylistRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{21}
+}
+
+type StopPlaylistRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopPlaylistRequest) Reset() {
+	*x = StopPlaylistRequest{}
+	mi := &file_golizer_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopPlaylistRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopPlaylistRequest) ProtoMessage() {}
+
+func (x *StopPlaylistRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopPlaylistRequest.ProtoReflect.Descriptor instead.
+func (*StopPlaylistRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{22}
+}
+
+type TapRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TapRequest) Reset() {
+	*x = TapRequest{}
+	mi := &file_golizer_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TapRequest) ProtoMessage() {}
+
+func (x *TapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TapRequest.ProtoReflect.Descriptor instead.
+func (*TapRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{23}
+}
+
+type TapResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// bpm is the tapped tempo, 0 until two taps came in.
+	Bpm           float64 `protobuf:"fixed64,1,opt,name=bpm,proto3" json:"bpm,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TapResponse) Reset() {
+	*x = TapResponse{}
+	mi := &file_golizer_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TapResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TapResponse) ProtoMessage() {}
+
+func (x *TapResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TapResponse.ProtoReflect.Descriptor instead.
+func (*TapResponse) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *TapResponse) GetBpm() float64 {
+	if x != nil {
+		return x.Bpm
+	}
+	return 0
+}
+
+type TriggerRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// effect is flash, strobe, drop or blackout.
+	Effect string `protobuf:"bytes,1,opt,name=effect,proto3" json:"effect,omitempty"`
+	// duration is in seconds, 0 for the effect's default.
+	Duration      float64 `protobuf:"fixed64,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerRequest) Reset() {
+	*x = TriggerRequest{}
+	mi := &file_golizer_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerRequest) ProtoMessage() {}
+
+func (x *TriggerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerRequest.ProtoReflect.Descriptor instead.
+func (*TriggerRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *TriggerRequest) GetEffect() string {
+	if x != nil {
+		return x.Effect
+	}
+	return ""
+}
+
+func (x *TriggerRequest) GetDuration() float64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+type TriggerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TriggerResponse) Reset() {
+	*x = TriggerResponse{}
+	mi := &file_golizer_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TriggerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TriggerResponse) ProtoMessage() {}
+
+func (x *TriggerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TriggerResponse.ProtoReflect.Descriptor instead.
+func (*TriggerResponse) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{26}
+}
+
+type SaveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveRequest) Reset() {
+	*x = SaveRequest{}
+	mi := &file_golizer_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveRequest) ProtoMessage() {}
+
+func (x *SaveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveRequest.ProtoReflect.Descriptor instead.
+func (*SaveRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{27}
+}
+
+type SaveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveResponse) Reset() {
+	*x = SaveResponse{}
+	mi := &file_golizer_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveResponse) ProtoMessage() {}
+
+func (x *SaveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveResponse.ProtoReflect.Descriptor instead.
+func (*SaveResponse) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *SaveResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type StreamFeaturesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// fps is how many messages a second to send, 1-60; 0 means 30.
+	Fps           int32 `protobuf:"varint,1,opt,name=fps,proto3" json:"fps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamFeaturesRequest) Reset() {
+	*x = StreamFeaturesRequest{}
+	mi := &file_golizer_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamFeaturesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamFeaturesRequest) ProtoMessage() {}
+
+func (x *StreamFeaturesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamFeaturesRequest.ProtoReflect.Descriptor instead.
+func (*StreamFeaturesRequest) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *StreamFeaturesRequest) GetFps() int32 {
+	if x != nil {
+		return x.Fps
+	}
+	return 0
+}
+
+// Features is one frame of audio analysis. Band levels are 0-1.
+type Features struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Sub          float64                `protobuf:"fixed64,1,opt,name=sub,proto3" json:"sub,omitempty"`
+	Bass         float64                `protobuf:"fixed64,2,opt,name=bass,proto3" json:"bass,omitempty"`
+	LowMid       float64                `protobuf:"fixed64,3,opt,name=low_mid,json=lowMid,proto3" json:"low_mid,omitempty"`
+	Mid          float64                `protobuf:"fixed64,4,opt,name=mid,proto3" json:"mid,omitempty"`
+	HighMid      float64                `protobuf:"fixed64,5,opt,name=high_mid,json=highMid,proto3" json:"high_mid,omitempty"`
+	Treble       float64                `protobuf:"fixed64,6,opt,name=treble,proto3" json:"treble,omitempty"`
+	Overall      float64                `protobuf:"fixed64,7,opt,name=overall,proto3" json:"overall,omitempty"`
+	Harmonic     float64                `protobuf:"fixed64,8,opt,name=harmonic,proto3" json:"harmonic,omitempty"`
+	Percussive   float64                `protobuf:"fixed64,9,opt,name=percussive,proto3" json:"percussive,omitempty"`
+	BeatStrength float64                `protobuf:"fixed64,10,opt,name=beat_strength,json=beatStrength,proto3" json:"beat_strength,omitempty"`
+	IsDrop       bool                   `protobuf:"varint,11,opt,name=is_drop,json=isDrop,proto3" json:"is_drop,omitempty"`
+	// onset is set when a beat was detected since the previous message.
+	Onset bool `protobuf:"varint,12,opt,name=onset,proto3" json:"onset,omitempty"`
+	// tempo is the estimated BPM, 0 until enough onsets were seen.
+	Tempo           float64 `protobuf:"fixed64,13,opt,name=tempo,proto3" json:"tempo,omitempty"`
+	TempoConfidence float64 `protobuf:"fixed64,14,opt,name=tempo_confidence,json=tempoConfidence,proto3" json:"tempo_confidence,omitempty"`
+	// level is the RMS and peak the largest magnitude of the input, as a
+	// share of full scale.
+	Level float64 `protobuf:"fixed64,15,opt,name=level,proto3" json:"level,omitempty"`
+	Peak  float64 `protobuf:"fixed64,16,opt,name=peak,proto3" json:"peak,omitempty"`
+	// correlation is how alike left and right are, -1 to 1.
+	Correlation float64 `protobuf:"fixed64,17,opt,name=correlation,proto3" json:"correlation,omitempty"`
+	// spectrum holds log-spaced band levels, lowest first.
+	Spectrum []float64 `protobuf:"fixed64,18,rep,packed,name=spectrum,proto3" json:"spectrum,omitempty"`
+	// waveform is the newest stretch of input from -1 to 1.
+	Waveform      []float64 `protobuf:"fixed64,19,rep,packed,name=waveform,proto3" json:"waveform,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Features) Reset() {
+	*x = Features{}
+	mi := &file_golizer_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Features) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Features) ProtoMessage() {}
+
+func (x *Features) ProtoReflect() protoreflect.Message {
+	mi := &file_golizer_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Features.ProtoReflect.Descriptor instead.
+func (*Features) Descriptor() ([]byte, []int) {
+	return file_golizer_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *Features) GetSub() float64 {
+	if x != nil {
+		return x.Sub
+	}
+	return 0
+}
+
+func (x *Features) GetBass() float64 {
+	if x != nil {
+		return x.Bass
+	}
+	return 0
+}
+
+func (x *Features) GetLowMid() float64 {
+	if x != nil {
+		return x.LowMid
+	}
+	return 0
+}
+
+func (x *Features) GetMid() float64 {
+	if x != nil {
+		return x.Mid
+	}
+	return 0
+}
+
+func (x *Features) GetHighMid() float64 {
+	if x != nil {
+		return x.HighMid
+	}
+	return 0
+}
+
+func (x *Features) GetTreble() float64 {
+	if x != nil {
+		return x.Treble
+	}
+	return 0
+}
+
+func (x *Features) GetOverall() float64 {
+	if x != nil {
+		return x.Overall
+	}
+	return 0
+}
+
+func (x *Features) GetHarmonic() float64 {
+	if x != nil {
+		return x.Harmonic
+	}
+	return 0
+}
+
+func (x *Features) GetPercussive() float64 {
+	if x != nil {
+		return x.Percussive
+	}
+	return 0
+}
+
+func (x *Features) GetBeatStrength() float64 {
+	if x != nil {
+		return x.BeatStrength
+	}
+	return 0
+}
+
+func (x *Features) GetIsDrop() bool {
+	if x != nil {
+		return x.IsDrop
+	}
+	return false
+}
+
+func (x *Features) GetOnset() bool {
+	if x != nil {
+		return x.Onset
+	}
+	return false
+}
+
+func (x *Features) GetTempo() float64 {
+	if x != nil {
+		return x.Tempo
+	}
+	return 0
+}
+
+func (x *Features) GetTempoConfidence() float64 {
+	if x != nil {
+		return x.TempoConfidence
+	}
+	return 0
+}
+
+func (x *Features) GetLevel() float64 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *Features) GetPeak() float64 {
+	if x != nil {
+		return x.Peak
+	}
+	return 0
+}
+
+func (x *Features) GetCorrelation() float64 {
+	if x != nil {
+		return x.Correlation
+	}
+	return 0
+}
+
+func (x *Features) GetSpectrum() []float64 {
+	if x != nil {
+		return x.Spectrum
+	}
+	return nil
+}
+
+func (x *Features) GetWaveform() []float64 {
+	if x != nil {
+		return x.Waveform
+	}
+	return nil
+}
+
+var File_golizer_proto protoreflect.FileDescriptor
+
+const file_golizer_proto_rawDesc = "" +
+	"\n" +
+	"\rgolizer.proto\x12\n" +
+	"golizer.v1\"\x12\n" +
+	"\x10GetStatusRequest\"\xc5\x03\n" +
+	"\x06Status\x12\x10\n" +
+	"\x03fps\x18\x01 \x01(\x01R\x03fps\x120\n" +
+	"\bfeatures\x18\x02 \x01(\v2\x14.golizer.v1.FeaturesR\bfeatures\x12\x18\n" +
+	"\apalette\x18\x03 \x01(\tR\apalette\x12\x18\n" +
+	"\apattern\x18\x04 \x01(\tR\apattern\x12\x1d\n" +
+	"\n" +
+	"color_mode\x18\x05 \x01(\tR\tcolorMode\x12\x18\n" +
+	"\aquality\x18\x06 \x01(\tR\aquality\x12&\n" +
+	"\x0fshow_status_bar\x18\a \x01(\bR\rshowStatusBar\x12\x1b\n" +
+	"\tread_only\x18\b \x01(\bR\breadOnly\x12\x1b\n" +
+	"\ttap_tempo\x18\t \x01(\x01R\btapTempo\x12\x1d\n" +
+	"\n" +
+	"midi_clock\x18\n" +
+	" \x01(\x01R\tmidiClock\x12:\n" +
+	"\frandom_locks\x18\v \x01(\v2\x17.golizer.v1.RandomLocksR\vrandomLocks\x125\n" +
+	"\bplaylist\x18\f \x01(\v2\x19.golizer.v1.PlaylistStateR\bplaylist\x12\x16\n" +
+	"\x06paused\x18\r \x01(\bR\x06paused\"`\n" +
+	"\vRandomLocks\x12\x18\n" +
+	"\apattern\x18\x01 \x01(\bR\apattern\x12\x18\n" +
+	"\apalette\x18\x02 \x01(\bR\apalette\x12\x1d\n" +
+	"\n" +
+	"color_mode\x18\x03 \x01(\bR\tcolorMode\"Y\n" +
+	"\rPlaylistState\x12\x18\n" +
+	"\aplaying\x18\x01 \x01(\bR\aplaying\x12\x12\n" +
+	"\x04step\x18\x02 \x01(\x05R\x04step\x12\x1a\n" +
+	"\bprogress\x18\x03 \x01(\x01R\bprogress\"F\n" +
+	"\bEnvelope\x12\x1b\n" +
+	"\tattack_ms\x18\x01 \x01(\x01R\battackMs\x12\x1d\n" +
+	"\n" +
+	"release_ms\x18\x02 \x01(\x01R\treleaseMs\"\xce\a\n" +
+	"\rUpdateRequest\x12=\n" +
+	"\x06params\x18\x01 \x03(\v2%.golizer.v1.UpdateRequest.ParamsEntryR\x06params\x12\x1d\n" +
+	"\apalette\x18\x02 \x01(\tH\x00R\apalette\x88\x01\x01\x12\x1d\n" +
+	"\apattern\x18\x03 \x01(\tH\x01R\apattern\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"color_mode\x18\x04 \x01(\tH\x02R\tcolorMode\x88\x01\x01\x12\x1d\n" +
+	"\aquality\x18\x05 \x01(\tH\x03R\aquality\x88\x01\x01\x12$\n" +
+	"\vnoise_floor\x18\x06 \x01(\x01H\x04R\n" +
+	"noiseFloor\x88\x01\x01\x12$\n" +
+	"\vbuffer_size\x18\a \x01(\x05H\x05R\n" +
+	"bufferSize\x88\x01\x01\x12F\n" +
+	"\tenvelopes\x18\b \x03(\v2(.golizer.v1.UpdateRequest.EnvelopesEntryR\tenvelopes\x12\x19\n" +
+	"\x05width\x18\t \x01(\x05H\x06R\x05width\x88\x01\x01\x12\x1b\n" +
+	"\x06height\x18\n" +
+	" \x01(\x05H\aR\x06height\x88\x01\x01\x12*\n" +
+	"\x0eauto_randomize\x18\v \x01(\bH\bR\rautoRandomize\x88\x01\x01\x12,\n" +
+	"\x0frandom_interval\x18\f \x01(\x05H\tR\x0erandomInterval\x88\x01\x01\x12+\n" +
+	"\x0fshow_status_bar\x18\r \x01(\bH\n" +
+	"R\rshowStatusBar\x88\x01\x01\x12?\n" +
+	"\frandom_locks\x18\x0e \x01(\v2\x17.golizer.v1.RandomLocksH\vR\vrandomLocks\x88\x01\x01\x12\x1b\n" +
+	"\x06paused\x18\x0f \x01(\bH\fR\x06paused\x88\x01\x01\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\x1aR\n" +
+	"\x0eEnvelopesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.golizer.v1.EnvelopeR\x05value:\x028\x01B\n" +
+	"\n" +
+	"\b_paletteB\n" +
+	"\n" +
+	"\b_patternB\r\n" +
+	"\v_color_modeB\n" +
+	"\n" +
+	"\b_qualityB\x0e\n" +
+	"\f_noise_floorB\x0e\n" +
+	"\f_buffer_sizeB\b\n" +
+	"\x06_widthB\t\n" +
+	"\a_heightB\x11\n" +
+	"\x0f_auto_randomizeB\x12\n" +
+	"\x10_random_intervalB\x12\n" +
+	"\x10_show_status_barB\x0f\n" +
+	"\r_random_locksB\t\n" +
+	"\a_paused\"\x10\n" +
+	"\x0eUpdateResponse\"\x15\n" +
+	"\x13ListPatternsRequest\",\n" +
+	"\x14ListPatternsResponse\x12\x14\n" +
+	"\x05names\x18\x01 \x03(\tR\x05names\"\x15\n" +
+	"\x13ListPalettesRequest\",\n" +
+	"\x14ListPalettesResponse\x12\x14\n" +
+	"\x05names\x18\x01 \x03(\tR\x05names\"\x17\n" +
+	"\x15ListColorModesRequest\".\n" +
+	"\x16ListColorModesResponse\x12\x14\n" +
+	"\x05names\x18\x01 \x03(\tR\x05names\"o\n" +
+	"\x06Preset\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\apattern\x18\x02 \x01(\tR\apattern\x12\x18\n" +
+	"\apalette\x18\x03 \x01(\tR\apalette\x12\x1d\n" +
+	"\n" +
+	"color_mode\x18\x04 \x01(\tR\tcolorMode\"\x14\n" +
+	"\x12ListPresetsRequest\"C\n" +
+	"\x13ListPresetsResponse\x12,\n" +
+	"\apresets\x18\x01 \x03(\v2\x12.golizer.v1.PresetR\apresets\"'\n" +
+	"\x11SavePresetRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"'\n" +
+	"\x11LoadPresetRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x14\n" +
+	"\x12LoadPresetResponse\")\n" +
+	"\x13DeletePresetRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x16\n" +
+	"\x14DeletePresetResponse\"\x15\n" +
+	"\x13PlayPlaylistRequest\"\x15\n" +
+	"\x13StopPlaylistRequest\"\f\n" +
+	"\n" +
+	"TapRequest\"\x1f\n" +
+	"\vTapResponse\x12\x10\n" +
+	"\x03bpm\x18\x01 \x01(\x01R\x03bpm\"D\n" +
+	"\x0eTriggerRequest\x12\x16\n" +
+	"\x06effect\x18\x01 \x01(\tR\x06effect\x12\x1a\n" +
+	"\bduration\x18\x02 \x01(\x01R\bduration\"\x11\n" +
+	"\x0fTriggerResponse\"\r\n" +
+	"\vSaveRequest\"\"\n" +
+	"\fSaveResponse\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\")\n" +
+	"\x15StreamFeaturesRequest\x12\x10\n" +
+	"\x03fps\x18\x01 \x01(\x05R\x03fps\"\xfd\x03\n" +
+	"\bFeatures\x12\x10\n" +
+	"\x03sub\x18\x01 \x01(\x01R\x03sub\x12\x12\n" +
+	"\x04bass\x18\x02 \x01(\x01R\x04bass\x12\x17\n" +
+	"\alow_mid\x18\x03 \x01(\x01R\x06lowMid\x12\x10\n" +
+	"\x03mid\x18\x04 \x01(\x01R\x03mid\x12\x19\n" +
+	"\bhigh_mid\x18\x05 \x01(\x01R\ahighMid\x12\x16\n" +
+	"\x06treble\x18\x06 \x01(\x01R\x06treble\x12\x18\n" +
+	"\aoverall\x18\a \x01(\x01R\aoverall\x12\x1a\n" +
+	"\bharmonic\x18\b \x01(\x01R\bharmonic\x12\x1e\n" +
+	"\n" +
+	"percussive\x18\t \x01(\x01R\n" +
+	"percussive\x12#\n" +
+	"\rbeat_strength\x18\n" +
+	" \x01(\x01R\fbeatStrength\x12\x17\n" +
+	"\ais_drop\x18\v \x01(\bR\x06isDrop\x12\x14\n" +
+	"\x05onset\x18\f \x01(\bR\x05onset\x12\x14\n" +
+	"\x05tempo\x18\r \x01(\x01R\x05tempo\x12)\n" +
+	"\x10tempo_confidence\x18\x0e \x01(\x01R\x0ftempoConfidence\x12\x14\n" +
+	"\x05level\x18\x0f \x01(\x01R\x05level\x12\x12\n" +
+	"\x04peak\x18\x10 \x01(\x01R\x04peak\x12 \n" +
+	"\vcorrelation\x18\x11 \x01(\x01R\vcorrelation\x12\x1a\n" +
+	"\bspectrum\x18\x12 \x03(\x01R\bspectrum\x12\x1a\n" +
+	"\bwaveform\x18\x13 \x03(\x01R\bwaveform2\xd5\b\n" +
+	"\aGolizer\x12=\n" +
+	"\tGetStatus\x12\x1c.golizer.v1.GetStatusRequest\x1a\x12.golizer.v1.Status\x12?\n" +
+	"\x06Update\x12\x19.golizer.v1.UpdateRequest\x1a\x1a.golizer.v1.UpdateResponse\x12Q\n" +
+	"\fListPatterns\x12\x1f.golizer.v1.ListPatternsRequest\x1a .golizer.v1.ListPatternsResponse\x12Q\n" +
+	"\fListPalettes\x12\x1f.golizer.v1.ListPalettesRequest\x1a .golizer.v1.ListPalettesResponse\x12W\n" +
+	"\x0eListColorModes\x12!.golizer.v1.ListColorModesRequest\x1a\".golizer.v1.ListColorModesResponse\x12N\n" +
+	"\vListPresets\x12\x1e.golizer.v1.ListPresetsRequest\x1a\x1f.golizer.v1.ListPresetsResponse\x12?\n" +
+	"\n" +
+	"SavePreset\x12\x1d.golizer.v1.SavePresetRequest\x1a\x12.golizer.v1.Preset\x12K\n" +
+	"\n" +
+	"LoadPreset\x12\x1d.golizer.v1.LoadPresetRequest\x1a\x1e.golizer.v1.LoadPresetResponse\x12Q\n" +
+	"\fDeletePreset\x12\x1f.golizer.v1.DeletePresetRequest\x1a .golizer.v1.DeletePresetResponse\x12J\n" +
+	"\fPlayPlaylist\x12\x1f.golizer.v1.PlayPlaylistRequest\x1a\x19.golizer.v1.PlaylistState\x12J\n" +
+	"\fStopPlaylist\x12\x1f.golizer.v1.StopPlaylistRequest\x1a\x19.golizer.v1.PlaylistState\x126\n" +
+	"\x03Tap\x12\x16.golizer.v1.TapRequest\x1a\x17.golizer.v1.TapResponse\x12B\n" +
+	"\aTrigger\x12\x1a.golizer.v1.TriggerRequest\x1a\x1b.golizer.v1.TriggerResponse\x129\n" +
+	"\x04Save\x12\x17.golizer.v1.SaveRequest\x1a\x18.golizer.v1.SaveResponse\x12K\n" +
+	"\x0eStreamFeatures\x12!.golizer.v1.StreamFeaturesRequest\x1a\x14.golizer.v1.Features0\x01B.Z,github.com/guidoenr/golizer/api/v1;golizerv1b\x06proto3"
+
+var (
+	file_golizer_proto_rawDescOnce sync.Once
+	file_golizer_proto_rawDescData []byte
+)
+
+func file_golizer_proto_rawDescGZIP() []byte {
+	file_golizer_proto_rawDescOnce.Do(func() {
+		file_golizer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_golizer_proto_rawDesc), len(file_golizer_proto_rawDesc)))
+	})
+	return file_golizer_proto_rawDescData
+}
+
+var file_golizer_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
+var file_golizer_proto_goTypes = []any{
+	(*GetStatusRequest)(nil),       // 0: golizer.v1.GetStatusRequest
+	(*Status)(nil),                 // 1: golizer.v1.Status
+	(*RandomLocks)(nil),            // 2: golizer.v1.RandomLocks
+	(*PlaylistState)(nil),          // 3: golizer.v1.PlaylistState
+	(*Envelope)(nil),               // 4: golizer.v1.Envelope
+	(*UpdateRequest)(nil),          // 5: golizer.v1.UpdateRequest
+	(*UpdateResponse)(nil),         // 6: golizer.v1.UpdateResponse
+	(*ListPatternsRequest)(nil),    // 7: golizer.v1.ListPatternsRequest
+	(*ListPatternsResponse)(nil),   // 8: golizer.v1.ListPatternsResponse
+	(*ListPalettesRequest)(nil),    // 9: golizer.v1.ListPalettesRequest
+	(*ListPalettesResponse)(nil),   // 10: golizer.v1.ListPalettesResponse
+	(*ListColorModesRequest)(nil),  // 11: golizer.v1.ListColorModesRequest
+	(*ListColorModesResponse)(nil), // 12: golizer.v1.ListColorModesResponse
+	(*Preset)(nil),                 // 13: golizer.v1.Preset
+	(*ListPresetsRequest)(nil),     // 14: golizer.v1.ListPresetsRequest
+	(*ListPresetsResponse)(nil),    // 15: golizer.v1.ListPresetsResponse
+	(*SavePresetRequest)(nil),      // 16: golizer.v1.SavePresetRequest
+	(*LoadPresetRequest)(nil),      // 17: golizer.v1.LoadPresetRequest
+	(*LoadPresetResponse)(nil),     // 18: golizer.v1.LoadPresetResponse
+	(*DeletePresetRequest)(nil),    // 19: golizer.v1.DeletePresetRequest
+	(*DeletePresetResponse)(nil),   // 20: golizer.v1.DeletePresetResponse
+	(*PlayPlaylistRequest)(nil),    // 21: golizer.v1.PlayPlaylistRequest
+	(*StopPlaylistRequest)(nil),    // 22: golizer.v1.StopPlaylistRequest
+	(*TapRequest)(nil),             // 23: golizer.v1.TapRequest
+	(*TapResponse)(nil),            // 24: golizer.v1.TapResponse
+	(*TriggerRequest)(nil),         // 25: golizer.v1.TriggerRequest
+	(*TriggerResponse)(nil),        // 26: golizer.v1.TriggerResponse
+	(*SaveRequest)(nil),            // 27: golizer.v1.SaveRequest
+	(*SaveResponse)(nil),           // 28: golizer.v1.SaveResponse
+	(*StreamFeaturesRequest)(nil),  // 29: golizer.v1.StreamFeaturesRequest
+	(*Features)(nil),               // 30: golizer.v1.Features
+	nil,                            // 31: golizer.v1.UpdateRequest.ParamsEntry
+	nil,                            // 32: golizer.v1.UpdateRequest.EnvelopesEntry
+}
+var file_golizer_proto_depIdxs = []int32{
+	30, // 0: golizer.v1.Status.features:type_name -> golizer.v1.Features
+	2,  // 1: golizer.v1.Status.random_locks:type_name -> golizer.v1.RandomLocks
+	3,  // 2: golizer.v1.Status.playlist:type_name -> golizer.v1.PlaylistState
+	31, // 3: golizer.v1.UpdateRequest.params:type_name -> golizer.v1.UpdateRequest.ParamsEntry
+	32, // 4: golizer.v1.UpdateRequest.envelopes:type_name -> golizer.v1.UpdateRequest.EnvelopesEntry
+	2,  // 5: golizer.v1.UpdateRequest.random_locks:type_name -> golizer.v1.RandomLocks
+	13, // 6: golizer.v1.ListPresetsResponse.presets:type_name -> golizer.v1.Preset
+	4,  // 7: golizer.v1.UpdateRequest.EnvelopesEntry.value:type_name -> golizer.v1.Envelope
+	0,  // 8: golizer.v1.Golizer.GetStatus:input_type -> golizer.v1.GetStatusRequest
+	5,  // 9: golizer.v1.Golizer.Update:input_type -> golizer.v1.UpdateRequest
+	7,  // 10: golizer.v1.Golizer.ListPatterns:input_type -> golizer.v1.ListPatternsRequest
+	9,  // 11: golizer.v1.Golizer.ListPalettes:input_type -> golizer.v1.ListPalettesRequest
+	11, // 12: golizer.v1.Golizer.ListColorModes:input_type -> golizer.v1.ListColorModesRequest
+	14, // 13: golizer.v1.Golizer.ListPresets:input_type -> golizer.v1.ListPresetsRequest
+	16, // 14: golizer.v1.Golizer.SavePreset:input_type -> golizer.v1.SavePresetRequest
+	17, // 15: golizer.v1.Golizer.LoadPreset:input_type -> golizer.v1.LoadPresetRequest
+	19, // 16: golizer.v1.Golizer.DeletePreset:input_type -> golizer.v1.DeletePresetRequest
+	21, // 17: golizer.v1.Golizer.PlayPlaylist:input_type -> golizer.v1.PlayPlaylistRequest
+	22, // 18: golizer.v1.Golizer.StopPlaylist:input_type -> golizer.v1.StopPlaylistRequest
+	23, // 19: golizer.v1.Golizer.Tap:input_type -> golizer.v1.TapRequest
+	25, // 20: golizer.v1.Golizer.Trigger:input_type -> golizer.v1.TriggerRequest
+	27, // 21: golizer.v1.Golizer.Save:input_type -> golizer.v1.SaveRequest
+	29, // 22: golizer.v1.Golizer.StreamFeatures:input_type -> golizer.v1.StreamFeaturesRequest
+	1,  // 23: golizer.v1.Golizer.GetStatus:output_type -> golizer.v1.Status
+	6,  // 24: golizer.v1.Golizer.Update:output_type -> golizer.v1.UpdateResponse
+	8,  // 25: golizer.v1.Golizer.ListPatterns:output_type -> golizer.v1.ListPatternsResponse
+	10, // 26: golizer.v1.Golizer.ListPalettes:output_type -> golizer.v1.ListPalettesResponse
+	12, // 27: golizer.v1.Golizer.ListColorModes:output_type -> golizer.v1.ListColorModesResponse
+	15, // 28: golizer.v1.Golizer.ListPresets:output_type -> golizer.v1.ListPresetsResponse
+	13, // 29: golizer.v1.Golizer.SavePreset:output_type -> golizer.v1.Preset
+	18, // 30: golizer.v1.Golizer.LoadPreset:output_type -> golizer.v1.LoadPresetResponse
+	20, // 31: golizer.v1.Golizer.DeletePreset:output_type -> golizer.v1.DeletePresetResponse
+	3,  // 32: golizer.v1.Golizer.PlayPlaylist:output_type -> golizer.v1.PlaylistState
+	3,  // 33: golizer.v1.Golizer.StopPlaylist:output_type -> golizer.v1.PlaylistState
+	24, // 34: golizer.v1.Golizer.Tap:output_type -> golizer.v1.TapResponse
+	26, // 35: golizer.v1.Golizer.Trigger:output_type -> golizer.v1.TriggerResponse
+	28, // 36: golizer.v1.Golizer.Save:output_type -> golizer.v1.SaveResponse
+	30, // 37: golizer.v1.Golizer.StreamFeatures:output_type -> golizer.v1.Features
+	23, // [23:38] is the sub-list for method output_type
+	8,  // [8:23] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_golizer_proto_init() }
+func file_golizer_proto_init() {
+	if File_golizer_proto != nil {
+		return
+	}
+	file_golizer_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_golizer_proto_rawDesc), len(file_golizer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   33,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_golizer_proto_goTypes,
+		DependencyIndexes: file_golizer_proto_depIdxs,
+		MessageInfos:      file_golizer_proto_msgTypes,
+	}.Build()
+	File_golizer_proto = out.File
+	file_golizer_proto_goTypes = nil
+	file_golizer_proto_depIdxs = nil
+}
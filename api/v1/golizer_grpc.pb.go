@@ -0,0 +1,682 @@
+// The gRPC control and telemetry API of golizer. It mirrors the REST API
+// under /api/v1 (see GET /api/v1/spec) and adds a stream of the analyzed
+// audio features for programs that react to the music themselves.
+//
+// Regenerate the Go code with `go generate ./api/...` (needs buf,
+// protoc-gen-go and protoc-gen-go-grpc on the PATH).
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: golizer.proto
+
+package golizerv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Golizer_GetStatus_FullMethodName      = "/golizer.v1.Golizer/GetStatus"
+	Golizer_Update_FullMethodName         = "/golizer.v1.Golizer/Update"
+	Golizer_ListPatterns_FullMethodName   = "/golizer.v1.Golizer/ListPatterns"
+	Golizer_ListPalettes_FullMethodName   = "/golizer.v1.Golizer/ListPalettes"
+	Golizer_ListColorModes_FullMethodName = "/golizer.v1.Golizer/ListColorModes"
+	Golizer_ListPresets_FullMethodName    = "/golizer.v1.Golizer/ListPresets"
+	Golizer_SavePreset_FullMethodName     = "/golizer.v1.Golizer/SavePreset"
+	Golizer_LoadPreset_FullMethodName     = "/golizer.v1.Golizer/LoadPreset"
+	Golizer_DeletePreset_FullMethodName   = "/golizer.v1.Golizer/DeletePreset"
+	Golizer_PlayPlaylist_FullMethodName   = "/golizer.v1.Golizer/PlayPlaylist"
+	Golizer_StopPlaylist_FullMethodName   = "/golizer.v1.Golizer/StopPlaylist"
+	Golizer_Tap_FullMethodName            = "/golizer.v1.Golizer/Tap"
+	Golizer_Trigger_FullMethodName        = "/golizer.v1.Golizer/Trigger"
+	Golizer_Save_FullMethodName           = "/golizer.v1.Golizer/Save"
+	Golizer_StreamFeatures_FullMethodName = "/golizer.v1.Golizer/StreamFeatures"
+)
+
+// GolizerClient is the client API for Golizer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GolizerClient interface {
+	// GetStatus returns the current look, levels and frame rate.
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error)
+	// Update changes params, look, size and analysis settings; fields left
+	// out stay as they are.
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
+	ListPatterns(ctx context.Context, in *ListPatternsRequest, opts ...grpc.CallOption) (*ListPatternsResponse, error)
+	ListPalettes(ctx context.Context, in *ListPalettesRequest, opts ...grpc.CallOption) (*ListPalettesResponse, error)
+	ListColorModes(ctx context.Context, in *ListColorModesRequest, opts ...grpc.CallOption) (*ListColorModesResponse, error)
+	ListPresets(ctx context.Context, in *ListPresetsRequest, opts ...grpc.CallOption) (*ListPresetsResponse, error)
+	// SavePreset stores the current look under a name.
+	SavePreset(ctx context.Context, in *SavePresetRequest, opts ...grpc.CallOption) (*Preset, error)
+	LoadPreset(ctx context.Context, in *LoadPresetRequest, opts ...grpc.CallOption) (*LoadPresetResponse, error)
+	DeletePreset(ctx context.Context, in *DeletePresetRequest, opts ...grpc.CallOption) (*DeletePresetResponse, error)
+	PlayPlaylist(ctx context.Context, in *PlayPlaylistRequest, opts ...grpc.CallOption) (*PlaylistState, error)
+	StopPlaylist(ctx context.Context, in *StopPlaylistRequest, opts ...grpc.CallOption) (*PlaylistState, error)
+	// Tap registers one tap-tempo tap.
+	Tap(ctx context.Context, in *TapRequest, opts ...grpc.CallOption) (*TapResponse, error)
+	// Trigger fires a one-shot flash, strobe, drop or blackout.
+	Trigger(ctx context.Context, in *TriggerRequest, opts ...grpc.CallOption) (*TriggerResponse, error)
+	// Save writes the current settings to the config file.
+	Save(ctx context.Context, in *SaveRequest, opts ...grpc.CallOption) (*SaveResponse, error)
+	// StreamFeatures sends the analyzed audio at the requested rate until
+	// the client goes away or golizer exits.
+	StreamFeatures(ctx context.Context, in *StreamFeaturesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Features], error)
+}
+
+type golizerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGolizerClient(cc grpc.ClientConnInterface) GolizerClient {
+	return &golizerClient{cc}
+}
+
+func (c *golizerClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Status)
+	err := c.cc.Invoke(ctx, Golizer_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateResponse)
+	err := c.cc.Invoke(ctx, Golizer_Update_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) ListPatterns(ctx context.Context, in *ListPatternsRequest, opts ...grpc.CallOption) (*ListPatternsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPatternsResponse)
+	err := c.cc.Invoke(ctx, Golizer_ListPatterns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) ListPalettes(ctx context.Context, in *ListPalettesRequest, opts ...grpc.CallOption) (*ListPalettesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPalettesResponse)
+	err := c.cc.Invoke(ctx, Golizer_ListPalettes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) ListColorModes(ctx context.Context, in *ListColorModesRequest, opts ...grpc.CallOption) (*ListColorModesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListColorModesResponse)
+	err := c.cc.Invoke(ctx, Golizer_ListColorModes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) ListPresets(ctx context.Context, in *ListPresetsRequest, opts ...grpc.CallOption) (*ListPresetsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPresetsResponse)
+	err := c.cc.Invoke(ctx, Golizer_ListPresets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) SavePreset(ctx context.Context, in *SavePresetRequest, opts ...grpc.CallOption) (*Preset, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Preset)
+	err := c.cc.Invoke(ctx, Golizer_SavePreset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) LoadPreset(ctx context.Context, in *LoadPresetRequest, opts ...grpc.CallOption) (*LoadPresetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoadPresetResponse)
+	err := c.cc.Invoke(ctx, Golizer_LoadPreset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) DeletePreset(ctx context.Context, in *DeletePresetRequest, opts ...grpc.CallOption) (*DeletePresetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeletePresetResponse)
+	err := c.cc.Invoke(ctx, Golizer_DeletePreset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) PlayPlaylist(ctx context.Context, in *PlayPlaylistRequest, opts ...grpc.CallOption) (*PlaylistState, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlaylistState)
+	err := c.cc.Invoke(ctx, Golizer_PlayPlaylist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) StopPlaylist(ctx context.Context, in *StopPlaylistRequest, opts ...grpc.CallOption) (*PlaylistState, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlaylistState)
+	err := c.cc.Invoke(ctx, Golizer_StopPlaylist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) Tap(ctx context.Context, in *TapRequest, opts ...grpc.CallOption) (*TapResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TapResponse)
+	err := c.cc.Invoke(ctx, Golizer_Tap_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) Trigger(ctx context.Context, in *TriggerRequest, opts ...grpc.CallOption) (*TriggerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TriggerResponse)
+	err := c.cc.Invoke(ctx, Golizer_Trigger_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) Save(ctx context.Context, in *SaveRequest, opts ...grpc.CallOption) (*SaveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SaveResponse)
+	err := c.cc.Invoke(ctx, Golizer_Save_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *golizerClient) StreamFeatures(ctx context.Context, in *StreamFeaturesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Features], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Golizer_ServiceDesc.Streams[0], Golizer_StreamFeatures_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamFeaturesRequest, Features]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Golizer_StreamFeaturesClient = grpc.ServerStreamingClient[Features]
+
+// GolizerServer is the server API for Golizer service.
+// All implementations must embed UnimplementedGolizerServer
+// for forward compatibility.
+type GolizerServer interface {
+	// GetStatus returns the current look, levels and frame rate.
+	GetStatus(context.Context, *GetStatusRequest) (*Status, error)
+	// Update changes params, look, size and analysis settings; fields left
+	// out stay as they are.
+	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
+	ListPatterns(context.Context, *ListPatternsRequest) (*ListPatternsResponse, error)
+	ListPalettes(context.Context, *ListPalettesRequest) (*ListPalettesResponse, error)
+	ListColorModes(context.Context, *ListColorModesRequest) (*ListColorModesResponse, error)
+	ListPresets(context.Context, *ListPresetsRequest) (*ListPresetsResponse, error)
+	// SavePreset stores the current look under a name.
+	SavePreset(context.Context, *SavePresetRequest) (*Preset, error)
+	LoadPreset(context.Context, *LoadPresetRequest) (*LoadPresetResponse, error)
+	DeletePreset(context.Context, *DeletePresetRequest) (*DeletePresetResponse, error)
+	PlayPlaylist(context.Context, *PlayPlaylistRequest) (*PlaylistState, error)
+	StopPlaylist(context.Context, *StopPlaylistRequest) (*PlaylistState, error)
+	// Tap registers one tap-tempo tap.
+	Tap(context.Context, *TapRequest) (*TapResponse, error)
+	// Trigger fires a one-shot flash, strobe, drop or blackout.
+	Trigger(context.Context, *TriggerRequest) (*TriggerResponse, error)
+	// Save writes the current settings to the config file.
+	Save(context.Context, *SaveRequest) (*SaveResponse, error)
+	// StreamFeatures sends the analyzed audio at the requested rate until
+	// the client goes away or golizer exits.
+	StreamFeatures(*StreamFeaturesRequest, grpc.ServerStreamingServer[Features]) error
+	mustEmbedUnimplementedGolizerServer()
+}
+
+// UnimplementedGolizerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGolizerServer struct{}
+
+func (UnimplementedGolizerServer) GetStatus(context.Context, *GetStatusRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedGolizerServer) Update(context.Context, *UpdateRequest) (*UpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedGolizerServer) ListPatterns(context.Context, *ListPatternsRequest) (*ListPatternsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPatterns not implemented")
+}
+func (UnimplementedGolizerServer) ListPalettes(context.Context, *ListPalettesRequest) (*ListPalettesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPalettes not implemented")
+}
+func (UnimplementedGolizerServer) ListColorModes(context.Context, *ListColorModesRequest) (*ListColorModesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListColorModes not implemented")
+}
+func (UnimplementedGolizerServer) ListPresets(context.Context, *ListPresetsRequest) (*ListPresetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPresets not implemented")
+}
+func (UnimplementedGolizerServer) SavePreset(context.Context, *SavePresetRequest) (*Preset, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SavePreset not implemented")
+}
+func (UnimplementedGolizerServer) LoadPreset(context.Context, *LoadPresetRequest) (*LoadPresetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoadPreset not implemented")
+}
+func (UnimplementedGolizerServer) DeletePreset(context.Context, *DeletePresetRequest) (*DeletePresetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePreset not implemented")
+}
+func (UnimplementedGolizerServer) PlayPlaylist(context.Context, *PlayPlaylistRequest) (*PlaylistState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PlayPlaylist not implemented")
+}
+func (UnimplementedGolizerServer) StopPlaylist(context.Context, *StopPlaylistRequest) (*PlaylistState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopPlaylist not implemented")
+}
+func (UnimplementedGolizerServer) Tap(context.Context, *TapRequest) (*TapResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Tap not implemented")
+}
+func (UnimplementedGolizerServer) Trigger(context.Context, *TriggerRequest) (*TriggerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Trigger not implemented")
+}
+func (UnimplementedGolizerServer) Save(context.Context, *SaveRequest) (*SaveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Save not implemented")
+}
+func (UnimplementedGolizerServer) StreamFeatures(*StreamFeaturesRequest, grpc.ServerStreamingServer[Features]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamFeatures not implemented")
+}
+func (UnimplementedGolizerServer) mustEmbedUnimplementedGolizerServer() {}
+func (UnimplementedGolizerServer) testEmbeddedByValue()                 {}
+
+// UnsafeGolizerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GolizerServer will
+// result in compilation errors.
+type UnsafeGolizerServer interface {
+	mustEmbedUnimplementedGolizerServer()
+}
+
+func RegisterGolizerServer(s grpc.ServiceRegistrar, srv GolizerServer) {
+	// If the following call pancis, it indicates UnimplementedGolizerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Golizer_ServiceDesc, srv)
+}
+
+func _Golizer_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_ListPatterns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPatternsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).ListPatterns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_ListPatterns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).ListPatterns(ctx, req.(*ListPatternsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_ListPalettes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPalettesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).ListPalettes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_ListPalettes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).ListPalettes(ctx, req.(*ListPalettesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_ListColorModes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListColorModesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).ListColorModes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_ListColorModes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).ListColorModes(ctx, req.(*ListColorModesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_ListPresets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPresetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).ListPresets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_ListPresets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).ListPresets(ctx, req.(*ListPresetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_SavePreset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SavePresetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).SavePreset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_SavePreset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).SavePreset(ctx, req.(*SavePresetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_LoadPreset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoadPresetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).LoadPreset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_LoadPreset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).LoadPreset(ctx, req.(*LoadPresetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_DeletePreset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePresetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).DeletePreset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_DeletePreset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).DeletePreset(ctx, req.(*DeletePresetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_PlayPlaylist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlayPlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).PlayPlaylist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_PlayPlaylist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).PlayPlaylist(ctx, req.(*PlayPlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_StopPlaylist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopPlaylistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).StopPlaylist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_StopPlaylist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).StopPlaylist(ctx, req.(*StopPlaylistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_Tap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).Tap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_Tap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).Tap(ctx, req.(*TapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_Trigger_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).Trigger(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_Trigger_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).Trigger(ctx, req.(*TriggerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_Save_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GolizerServer).Save(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Golizer_Save_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GolizerServer).Save(ctx, req.(*SaveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Golizer_StreamFeatures_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamFeaturesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GolizerServer).StreamFeatures(m, &grpc.GenericServerStream[StreamFeaturesRequest, Features]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Golizer_StreamFeaturesServer = grpc.ServerStreamingServer[Features]
+
+// Golizer_ServiceDesc is the grpc.ServiceDesc for Golizer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Golizer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "golizer.v1.Golizer",
+	HandlerType: (*GolizerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _Golizer_GetStatus_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _Golizer_Update_Handler,
+		},
+		{
+			MethodName: "ListPatterns",
+			Handler:    _Golizer_ListPatterns_Handler,
+		},
+		{
+			MethodName: "ListPalettes",
+			Handler:    _Golizer_ListPalettes_Handler,
+		},
+		{
+			MethodName: "ListColorModes",
+			Handler:    _Golizer_ListColorModes_Handler,
+		},
+		{
+			MethodName: "ListPresets",
+			Handler:    _Golizer_ListPresets_Handler,
+		},
+		{
+			MethodName: "SavePreset",
+			Handler:    _Golizer_SavePreset_Handler,
+		},
+		{
+			MethodName: "LoadPreset",
+			Handler:    _Golizer_LoadPreset_Handler,
+		},
+		{
+			MethodName: "DeletePreset",
+			Handler:    _Golizer_DeletePreset_Handler,
+		},
+		{
+			MethodName: "PlayPlaylist",
+			Handler:    _Golizer_PlayPlaylist_Handler,
+		},
+		{
+			MethodName: "StopPlaylist",
+			Handler:    _Golizer_StopPlaylist_Handler,
+		},
+		{
+			MethodName: "Tap",
+			Handler:    _Golizer_Tap_Handler,
+		},
+		{
+			MethodName: "Trigger",
+			Handler:    _Golizer_Trigger_Handler,
+		},
+		{
+			MethodName: "Save",
+			Handler:    _Golizer_Save_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamFeatures",
+			Handler:       _Golizer_StreamFeatures_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "golizer.proto",
+}
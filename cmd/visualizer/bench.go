@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/app"
+	"github.com/guidoenr/golizer/internal/render"
+)
+
+// runBench implements `golizer bench`: render every pattern, palette and
+// quality combo off-screen with synthetic audio and print how fast each
+// one runs, to pick settings for the machine.
+func runBench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	duration := fs.Duration("duration", 2*time.Second, "How long to render each combo")
+	width := fs.Int("width", 120, "Frame width (ASCII columns, or pixels with --backend none)")
+	height := fs.Int("height", 40, "Frame height (ASCII rows, or pixels with --backend none)")
+	backend := fs.String("backend", "ascii", "Renderer to measure (ascii|none); nothing is drawn either way")
+	patterns := fs.String("patterns", "", "Comma separated patterns to run (default: all)")
+	palettes := fs.String("palettes", "", "Comma separated palettes to run (default: all)")
+	qualities := fs.String("qualities", "", "Comma separated quality presets to run (default: high,balanced,eco)")
+	_ = fs.Parse(args)
+
+	logger := log.New(os.Stderr, "[golizer] ", 0)
+	if *duration <= 0 {
+		logger.Fatalf("duration must be positive (got %s)", *duration)
+	}
+	if *width <= 0 || *height <= 0 {
+		logger.Fatalf("invalid dimensions: width=%d height=%d", *width, *height)
+	}
+	var backendName render.Backend
+	switch *backend {
+	case "ascii", "none":
+		backendName = render.Backend(*backend)
+	default:
+		logger.Fatalf("backend: bench runs ascii or none, not %q", *backend)
+	}
+	cfg := app.BenchConfig{
+		Width:     *width,
+		Height:    *height,
+		Backend:   backendName,
+		Patterns:  splitWords(*patterns),
+		Palettes:  splitWords(*palettes),
+		Qualities: splitWords(*qualities),
+		Duration:  *duration,
+	}
+	if err := cfg.Check(); err != nil {
+		logger.Fatalf("bench: %v", err)
+	}
+	cfg = cfg.WithDefaults()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	combos := cfg.Combos()
+	logger.Printf("bench: %d combos at %dx%d on %s, %s each, about %s (%s/%s, %d cores)",
+		combos, cfg.Width, cfg.Height, backendName, cfg.Duration,
+		(time.Duration(combos) * cfg.Duration).Round(time.Second), runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+	fmt.Printf("%-14s %-10s %-8s %8s %8s %8s %8s %8s\n", "pattern", "palette", "quality", "fps", "p50 ms", "p95 ms", "p99 ms", "max ms")
+	err := app.Bench(ctx, cfg, func(r app.BenchResult) {
+		fmt.Printf("%-14s %-10s %-8s %8.1f %8.2f %8.2f %8.2f %8.2f\n",
+			r.Pattern, r.Palette, r.Quality, r.FPS, ms(r.P50), ms(r.P95), ms(r.P99), ms(r.Max))
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		logger.Fatalf("bench: %v", err)
+	}
+}
+
+// ms is d in milliseconds.
+func ms(d time.Duration) float64 {
+	return d.Seconds() * 1000
+}
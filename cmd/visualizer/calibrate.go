@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/audio"
+	"github.com/guidoenr/golizer/internal/config"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// calibrationWarmup lets the analyzer's adaptive peaks settle before we
+// start sampling the room.
+const calibrationWarmup = 750 * time.Millisecond
+
+// runCalibrate implements `golizer calibrate`: listen to a quiet room, measure
+// per-band noise and store the floors in the saved config.
+func runCalibrate(args []string) {
+	fs := flag.NewFlagSet("calibrate", flag.ExitOnError)
+	deviceName := fs.String("audio-device", "", "Optional PortAudio device name (substring match)")
+	bufferSize := fs.Int("buffer-size", 2048, "FFT buffer size (power of two recommended)")
+	analysisMode := fs.String("analysis", "fft", "Spectrum analysis (fft|cqt|goertzel), should match the mode you play with")
+	duration := fs.Duration("duration", 5*time.Second, "How long to listen for room noise")
+	configFile := fs.String("config", "", "Config file to store the floors in (default: $XDG_CONFIG_HOME/golizer/config.json)")
+	_ = fs.Parse(args)
+	configPath = config.Path(*configFile)
+
+	logger := log.New(os.Stderr, "[golizer] ", 0)
+	mode, err := resolveAnalysisMode(*analysisMode, "")
+	if err != nil {
+		logger.Fatalf("analysis: %v", err)
+	}
+
+	if err := audio.Initialize(); err != nil {
+		logger.Fatalf("failed to initialize PortAudio: %v", err)
+	}
+	defer audio.Terminate()
+
+	capture, err := audio.NewCapture(audio.Config{
+		DeviceName: *deviceName,
+		BufferSize: *bufferSize,
+		Channels:   2,
+	})
+	if err != nil {
+		logger.Fatalf("audio capture: %v", err)
+	}
+	defer capture.Close()
+
+	an := analyzer.New(analyzer.Config{SampleRate: capture.SampleRate(), HistorySize: 60, Mode: mode})
+	cal := analyzer.NewCalibrator()
+	logger.Printf("calibrating for %s, keep the room quiet...", *duration)
+
+	ticker := time.NewTicker(time.Second / 60)
+	defer ticker.Stop()
+	start := time.Now()
+	last := start
+	var samples []float32
+	for now := range ticker.C {
+		samples = capture.SamplesInto(samples)
+		feat := an.Analyze(samples, now.Sub(last).Seconds())
+		last = now
+		elapsed := now.Sub(start)
+		if elapsed < calibrationWarmup {
+			continue
+		}
+		cal.Add(feat)
+		if elapsed >= calibrationWarmup+*duration {
+			break
+		}
+	}
+
+	floors := cal.Floors()
+	if err := storeNoiseFloors(configPath, floors); err != nil {
+		logger.Fatalf("save noise floors: %v", err)
+	}
+	fmt.Printf("sub %.2f bass %.2f low-mid %.2f high-mid %.2f mid %.2f treble %.2f overall %.2f beat %.2f\n",
+		floors.Sub, floors.Bass, floors.LowMid, floors.HighMid, floors.Mid, floors.Treble, floors.Overall, floors.Beat)
+	logger.Printf("noise floors from %d frames saved to %s", cal.Frames(), configPath)
+}
+
+// storeNoiseFloors writes floors into the saved config, keeping every other
+// field as it is. A fresh file starts from default params.
+func storeNoiseFloors(path string, floors analyzer.NoiseFloors) error {
+	doc := map[string]any{}
+	if data, err := os.ReadFile(path); err == nil {
+		migrated, _, err := config.Migrate(data)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(migrated, &doc); err != nil {
+			return err
+		}
+	} else {
+		fresh, err := json.Marshal(savedConfig{Params: params.Defaults(), ShowStatusBar: true})
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(fresh, &doc); err != nil {
+			return err
+		}
+	}
+
+	doc["version"] = config.Version
+	doc["noiseFloors"] = floors
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/params"
+	"github.com/guidoenr/golizer/internal/web"
+)
+
+// ctlTimeout bounds one request to the running instance.
+const ctlTimeout = 10 * time.Second
+
+const ctlUsage = `usage: golizer ctl [--url URL | --socket PATH] [--token TOKEN] <command> [args]
+
+commands:
+  status                          the current look, levels and frame rate
+  watch                           print each status update as a line of json
+  set pattern|palette|colorMode|quality <name>
+  set <param> <value>             a panel knob: speed, brightness, bassInfluence ...
+  set width|height|bufferSize|randomInterval <n>
+  set autoRandomize|statusBar|paused on|off
+  preset list|save|load|delete <name>
+  playlist play|stop
+  trigger flash|strobe|drop|blackout [seconds]
+  tap                             one tap-tempo tap
+  save                            write the current settings to the config file
+  api <method> <path> [json]      any other endpoint, e.g. api GET /patterns
+`
+
+// ctlClient talks to a running instance's API.
+type ctlClient struct {
+	base  string // e.g. http://localhost:8080
+	token string
+	http  *http.Client
+}
+
+// runCtl implements `golizer ctl`: remote control of a running instance
+// over its web API, for scripting headless installations from the shell or
+// cron.
+func runCtl(args []string) {
+	fs := flag.NewFlagSet("ctl", flag.ExitOnError)
+	baseURL := fs.String("url", "http://localhost:8080", "Web panel of the instance to control, when its control socket isn't there")
+	socket := fs.String("socket", defaultSocketPath(), "Control socket of the instance (see --control-socket)")
+	token := fs.String("token", "", "The instance's --web-token (or set GOLIZER_WEB_TOKEN)")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), ctlUsage, "\nflags:\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *token == "" {
+		*token = strings.TrimSpace(os.Getenv("GOLIZER_WEB_TOKEN"))
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	logger := log.New(os.Stderr, "[golizer] ", 0)
+	c := &ctlClient{
+		base:  strings.TrimSuffix(*baseURL, "/"),
+		token: *token,
+		http:  &http.Client{Timeout: ctlTimeout},
+	}
+	if !strings.Contains(c.base, "://") {
+		c.base = "http://" + c.base
+	}
+	// the socket works with --no-web too, so it goes first unless a URL
+	// was asked for; a user may also reach a service running as root
+	if !set["url"] {
+		candidates := []string{*socket}
+		if !set["socket"] {
+			candidates = append(candidates, "/run/golizer.sock")
+		}
+		for _, path := range candidates {
+			if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+				c.useSocket(path)
+				break
+			}
+		}
+	}
+	if err := c.run(fs.Args(), os.Stdout); err != nil {
+		logger.Fatalf("ctl: %v", err)
+	}
+}
+
+// useSocket sends every request over the unix socket at path.
+func (c *ctlClient) useSocket(path string) {
+	c.base = "http://golizer"
+	c.http.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}
+}
+
+// defaultSocketPath is where the control socket goes unless
+// --control-socket says otherwise: /run for root, a per-user name in the
+// temp directory otherwise (the same with or without a login session).
+func defaultSocketPath() string {
+	if os.Geteuid() == 0 {
+		return "/run/golizer.sock"
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("golizer-%d.sock", os.Geteuid()))
+}
+
+// run carries out one command and prints what the instance answered.
+func (c *ctlClient) run(args []string, out io.Writer) error {
+	cmd, rest := args[0], args[1:]
+	want := func(n int, usage string) error {
+		if len(rest) != n {
+			return fmt.Errorf("usage: ctl %s %s", cmd, usage)
+		}
+		return nil
+	}
+
+	switch cmd {
+	case "status":
+		if err := want(0, ""); err != nil {
+			return err
+		}
+		return c.do(http.MethodGet, "/status", nil, out)
+	case "watch":
+		if err := want(0, ""); err != nil {
+			return err
+		}
+		return c.watch(out)
+	case "set":
+		if err := want(2, "<name> <value>"); err != nil {
+			return err
+		}
+		req, err := updateFor(rest[0], rest[1])
+		if err != nil {
+			return err
+		}
+		return c.do(http.MethodPost, "/update", req, out)
+	case "preset":
+		if len(rest) == 1 && rest[0] == "list" {
+			return c.do(http.MethodGet, "/presets", nil, out)
+		}
+		if err := want(2, "list|save|load|delete <name>"); err != nil {
+			return err
+		}
+		name := rest[1]
+		switch rest[0] {
+		case "save":
+			return c.do(http.MethodPost, "/presets", map[string]string{"name": name}, out)
+		case "load":
+			return c.do(http.MethodPost, "/presets/"+url.PathEscape(name)+"/load", nil, out)
+		case "delete":
+			return c.do(http.MethodDelete, "/presets?name="+url.QueryEscape(name), nil, out)
+		}
+		return fmt.Errorf("unknown preset command %q", rest[0])
+	case "playlist":
+		if err := want(1, "play|stop"); err != nil {
+			return err
+		}
+		return c.do(http.MethodPost, "/playlist/"+rest[0], nil, out)
+	case "trigger":
+		if len(rest) != 1 && len(rest) != 2 {
+			return errors.New("usage: ctl trigger <effect> [seconds]")
+		}
+		req := web.TriggerRequest{Effect: rest[0]}
+		if len(rest) == 2 {
+			d, err := strconv.ParseFloat(rest[1], 64)
+			if err != nil {
+				return fmt.Errorf("seconds: %v", err)
+			}
+			req.Duration = d
+		}
+		return c.do(http.MethodPost, "/trigger", req, out)
+	case "tap":
+		if err := want(0, ""); err != nil {
+			return err
+		}
+		return c.do(http.MethodPost, "/tap", nil, out)
+	case "save":
+		if err := want(0, ""); err != nil {
+			return err
+		}
+		return c.do(http.MethodPost, "/save", nil, out)
+	case "api":
+		if len(rest) != 2 && len(rest) != 3 {
+			return errors.New("usage: ctl api <method> <path> [json]")
+		}
+		var body any
+		if len(rest) == 3 {
+			body = json.RawMessage(rest[2])
+		}
+		return c.do(strings.ToUpper(rest[0]), "/"+strings.TrimPrefix(rest[1], "/"), body, out)
+	}
+	return fmt.Errorf("unknown command %q (see golizer ctl -h)", cmd)
+}
+
+// updateFor builds the /api/v1/update request that sets name to value.
+func updateFor(name, value string) (any, error) {
+	var req web.UpdateRequest
+	integer := func() (*int, error) {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: want a whole number", name)
+		}
+		return &n, nil
+	}
+	toggle := func() (*bool, error) {
+		switch strings.ToLower(value) {
+		case "on", "true", "1":
+			on := true
+			return &on, nil
+		case "off", "false", "0":
+			off := false
+			return &off, nil
+		}
+		return nil, fmt.Errorf("%s: want on or off", name)
+	}
+
+	var err error
+	switch strings.ToLower(name) {
+	case "pattern":
+		req.Pattern = &value
+	case "palette":
+		req.Palette = &value
+	case "colormode":
+		req.ColorMode = &value
+	case "quality":
+		req.Quality = &value
+	case "width":
+		req.Width, err = integer()
+	case "height":
+		req.Height, err = integer()
+	case "buffersize":
+		req.BufferSize, err = integer()
+	case "randominterval":
+		req.RandomInterval, err = integer()
+	case "autorandomize":
+		req.AutoRandomize, err = toggle()
+	case "statusbar", "showstatusbar":
+		req.ShowStatusBar, err = toggle()
+	case "paused", "pause":
+		req.Paused, err = toggle()
+	default:
+		for field := range params.Ranges() {
+			if strings.EqualFold(field, name) {
+				v, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%s: want a number", name)
+				}
+				// params merge, the knobs left out stay as they are
+				return map[string]any{"params": map[string]float64{field: v}}, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown setting %q", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// request builds a call to path below /api/v1.
+func (c *ctlClient) request(method, path string, body any) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.base+"/api/v1"+path, r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return req, nil
+}
+
+// do sends one request and prints the JSON answer indented. Errors carry
+// the instance's error message.
+func (c *ctlClient) do(method, path string, body any, out io.Writer) error {
+	req, err := c.request(method, path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return apiFailure(resp.StatusCode, data)
+	}
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, data, "", "  ") != nil {
+		_, err = out.Write(data)
+		return err
+	}
+	_, err = fmt.Fprintln(out, strings.TrimSpace(pretty.String()))
+	return err
+}
+
+// apiFailure turns an error envelope into an error, one line per bad field.
+func apiFailure(status int, data []byte) error {
+	var envelope web.ErrorResponse
+	if json.Unmarshal(data, &envelope) != nil || envelope.Error.Message == "" {
+		return fmt.Errorf("%d %s", status, strings.TrimSpace(string(data)))
+	}
+	msg := envelope.Error.Message
+	for _, f := range envelope.Error.Fields {
+		msg += fmt.Sprintf("\n  %s: %s", f.Field, f.Message)
+	}
+	return errors.New(msg)
+}
+
+// watch follows /api/v1/events and prints every status update on a line of
+// its own, until the instance goes away.
+func (c *ctlClient) watch(out io.Writer) error {
+	req, err := c.request(http.MethodGet, "/events", nil)
+	if err != nil {
+		return err
+	}
+	// the stream stays open, so no timeout here
+	resp, err := (&http.Client{Transport: c.http.Transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return apiFailure(resp.StatusCode, data)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			if _, err := fmt.Fprintln(out, data); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("the instance closed the stream")
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUpdateFor(t *testing.T) {
+	cases := []struct {
+		name, value string
+		want        string // the request as json, empty when it fails
+	}{
+		{"pattern", "tunnel", `{"pattern":"tunnel"}`},
+		{"colorMode", "fire", `{"colorMode":"fire"}`},
+		{"width", "120", `{"width":120}`},
+		{"width", "wide", ""},
+		{"autoRandomize", "on", `{"autoRandomize":true}`},
+		{"pause", "0", `{"paused":false}`},
+		{"statusBar", "maybe", ""},
+		{"speed", "1.5", `{"params":{"Speed":1.5}}`},
+		{"BRIGHTNESS", "2", `{"params":{"Brightness":2}}`},
+		{"speed", "fast", ""},
+		{"volume", "11", ""},
+	}
+	for _, c := range cases {
+		req, err := updateFor(c.name, c.value)
+		if c.want == "" {
+			if err == nil {
+				t.Errorf("%q %q: got %v, want an error", c.name, c.value, req)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q %q: %v", c.name, c.value, err)
+			continue
+		}
+		got, _ := json.Marshal(req)
+		if string(got) != c.want {
+			t.Errorf("%q %q: got %s, want %s", c.name, c.value, got, c.want)
+		}
+	}
+}
+
+func TestCtlRun(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, strings.TrimSpace(r.Method+" "+r.URL.RequestURI()+" "+string(body)))
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"error": {"status": 401, "message": "unauthorized"}}`)
+			return
+		}
+		io.WriteString(w, `{"ok":true}`)
+	}))
+	defer srv.Close()
+	c := &ctlClient{base: srv.URL, token: "secret", http: srv.Client()}
+
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"status"}, "GET /api/v1/status"},
+		{[]string{"set", "pattern", "tunnel"}, `POST /api/v1/update {"pattern":"tunnel"}`},
+		{[]string{"preset", "list"}, "GET /api/v1/presets"},
+		{[]string{"preset", "load", "late set"}, "POST /api/v1/presets/late%20set/load"},
+		{[]string{"preset", "delete", "a&b"}, "DELETE /api/v1/presets?name=a%26b"},
+		{[]string{"playlist", "stop"}, "POST /api/v1/playlist/stop"},
+		{[]string{"trigger", "strobe", "2.5"}, `POST /api/v1/trigger {"effect":"strobe","duration":2.5}`},
+		{[]string{"api", "get", "patterns"}, "GET /api/v1/patterns"},
+		{[]string{"api", "POST", "/update", `{"width":80}`}, `POST /api/v1/update {"width":80}`},
+	}
+	for _, tc := range cases {
+		got = nil
+		var out bytes.Buffer
+		if err := c.run(tc.args, &out); err != nil {
+			t.Errorf("%q: %v", tc.args, err)
+			continue
+		}
+		if len(got) != 1 || got[0] != tc.want {
+			t.Errorf("%q: sent %q, want %q", tc.args, got, tc.want)
+		}
+		if out.String() != "{\n  \"ok\": true\n}\n" {
+			t.Errorf("%q: printed %q", tc.args, out.String())
+		}
+	}
+
+	// bad usage never reaches the instance
+	got = nil
+	for _, args := range [][]string{{"status", "now"}, {"set", "pattern"}, {"preset", "rename", "a"}, {"trigger", "flash", "soon"}, {"dance"}} {
+		if err := c.run(args, io.Discard); err == nil {
+			t.Errorf("%q: no error", args)
+		}
+	}
+	if len(got) != 0 {
+		t.Errorf("bad usage sent %q", got)
+	}
+
+	c.token = "wrong"
+	if err := c.run([]string{"tap"}, io.Discard); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("wrong token: got %v", err)
+	}
+}
+
+func TestAPIFailure(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+		want   string
+	}{
+		{400, `{"error": {"status": 400, "message": "2 invalid field(s)", "fields": [{"field": "width", "message": "too small"}, {"field": "pattern", "message": "unknown"}]}}`,
+			"2 invalid field(s)\n  width: too small\n  pattern: unknown"},
+		{502, "Bad Gateway\n", "502 Bad Gateway"},
+		{404, `{"other": true}`, `404 {"other": true}`},
+	}
+	for _, c := range cases {
+		if got := apiFailure(c.status, []byte(c.body)).Error(); got != c.want {
+			t.Errorf("%d %s: got %q, want %q", c.status, c.body, got, c.want)
+		}
+	}
+}
+
+func TestCtlWatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/events" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, "retry: 2000\n\nevent: status\ndata: {\"fps\":30}\n\n: keep-alive\n\nevent: status\ndata: {\"fps\":60}\n\n")
+	}))
+	defer srv.Close()
+	c := &ctlClient{base: srv.URL, http: srv.Client()}
+	var out bytes.Buffer
+	if err := c.run([]string{"watch"}, &out); err == nil || !strings.Contains(err.Error(), "closed the stream") {
+		t.Errorf("got %v, want the stream closed", err)
+	}
+	if out.String() != "{\"fps\":30}\n{\"fps\":60}\n" {
+		t.Errorf("printed %q", out.String())
+	}
+}
@@ -5,85 +5,191 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"os"
-	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"runtime"
 	rdebug "runtime/debug"
+	"slices"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/guidoenr/golizer/internal/analyzer"
 	"github.com/guidoenr/golizer/internal/app"
 	"github.com/guidoenr/golizer/internal/audio"
+	"github.com/guidoenr/golizer/internal/config"
+	"github.com/guidoenr/golizer/internal/crash"
+	"github.com/guidoenr/golizer/internal/lyrics"
 	"github.com/guidoenr/golizer/internal/params"
+	"github.com/guidoenr/golizer/internal/relay"
 	"github.com/guidoenr/golizer/internal/render"
+	"github.com/guidoenr/golizer/internal/sink"
 	"github.com/guidoenr/golizer/internal/web"
 	"golang.org/x/term"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "calibrate" {
+		runCalibrate(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "ctl" {
+		runCtl(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		runBench(os.Args[2:])
+		return
+	}
+
 	var (
 		deviceName = flag.String("audio-device", "", "Optional PortAudio device name (substring match)")
 		width      = flag.Int("width", 120, "Frame width (ASCII columns or SDL resolution)")
 		height     = flag.Int("height", 40, "Frame height (ASCII rows or SDL resolution)")
-		// FPS removed - always unlimited, each machine runs at its max
-		bufferSize = flag.Int("buffer-size", 2048, "FFT buffer size (power of two recommended)")
-		noAudio    = flag.Bool("no-audio", false, "Run with synthetic audio (for testing)")
-		debug      = flag.Bool("debug", false, "Enable verbose logging")
-		showStatus = flag.Bool("status", true, "Display status bar")
-		palette    = flag.String("palette", "auto", "ASCII palette (auto|default|box|lines|spark|retro|minimal|block|bubble)")
-		pattern    = flag.String("pattern", "auto", "Visual pattern (auto|flash|spark|scatter|beam|ripple|laser|orbit|explosion|rings|zigzag|cross|spiral|star|tunnel|neurons|fractal)")
-		colorMode  = flag.String("color-mode", "chromatic", "Color mode (chromatic|fire|aurora|mono)")
-		listDevs   = flag.Bool("list-audio-devices", false, "List available audio input devices and exit")
-		noColor    = flag.Bool("no-color", false, "Disable ANSI color output")
-		quality    = flag.String("quality", "balanced", "Quality preset (auto|high|balanced|eco)")
-		autoRandom = flag.Bool("auto-randomize", true, "Automatically randomize visuals periodically")
-		randomFreq = flag.Duration("randomize-interval", 10*time.Second, "Interval between automatic visual randomization")
-		backend    = flag.String("backend", "ascii", "Renderer backend (auto|ascii|sdl)")
-		stride     = flag.Int("stride", 1, "Render every Nth frame (1 = no skip)")
-		frameScale = flag.Float64("scale", 1.0, "Pixel scale multiplier (SDL)")
-		fullscreen = flag.Bool("fullscreen", false, "Use fullscreen SDL window")
-		profileLog = flag.String("profile-log", "", "Optional path to append frame timing metrics")
-		noiseFloor = flag.Float64("noise-floor", 0.20, "Energy gate to ignore ambient noise (0-0.5)")
-		webPort    = flag.Int("web-port", 8080, "Web server port (0 = disabled, default: 8080)")
-		noWeb      = flag.Bool("no-web", false, "Disable web server")
-		showWebURL = flag.Bool("show-web-url", true, "Show web panel URL in status bar")
+		// frame rate: unlimited unless --max-fps caps it
+		bufferSize    = flag.Int("buffer-size", 2048, "FFT buffer size (power of two recommended)")
+		analysisMode  = flag.String("analysis", "auto", "Spectrum analysis (auto|fft|cqt|goertzel); cqt resolves low end better, pair with --buffer-size 8192; auto picks goertzel on eco quality")
+		noAudio       = flag.Bool("no-audio", false, "Run with synthetic audio (for testing)")
+		debug         = flag.Bool("debug", false, "Enable verbose logging")
+		showStatus    = flag.Bool("status", true, "Display status bar")
+		statusFormat  = flag.String("status-format", "", "Status bar on one row from a template, e.g. \"{fps} fps {temp} {pattern} {device}\" (default: one field per row)")
+		statusPos     = flag.String("status-position", "top", "Where the status bar goes (top|bottom)")
+		statusOverlay = flag.Bool("status-overlay", false, "Draw the status and the playing track into the SDL window, for fullscreen (I toggles it)")
+		palette       = flag.String("palette", "auto", "ASCII palette (auto|default|box|lines|spark|retro|minimal|block|bubble|braille|halfblock|<custom>)")
+		paletteChars  = flag.String("palette-chars", "", "Characters of a custom palette, lightest first (e.g. \" ░▒▓█\"); selects it as \"custom\"")
+		pattern       = flag.String("pattern", "auto", "Visual pattern (auto|flash|spark|scatter|beam|ripple|laser|orbit|explosion|rings|zigzag|cross|spiral|star|tunnel|neurons|fractal|tunnel3d|metaballs|torus|plasma|interference|blobs|copper|bars|scope|spectrogram|vu|life|lissajous|flow|starfield|eqring|<plugin>|lua:<name>|shader:<name>|expr:<formula>; stack with a+b[:mode][@opacity])")
+		symmetry      = flag.String("symmetry", "", "Fold patterns into a symmetry (off|mirror-x|mirror-y|mirror-xy|kaleid:N, joined with +; default: keep the saved effects)")
+		colorMode     = flag.String("color-mode", "chromatic", "Color mode (chromatic|fire|aurora|mono|gradient)")
+		listDevs      = flag.Bool("list-audio-devices", false, "List available audio input devices and exit")
+		noColor       = flag.Bool("no-color", false, "Disable ANSI color output")
+		quality       = flag.String("quality", "balanced", "Quality preset (auto|high|balanced|eco)")
+		maxFPS        = flag.Float64("max-fps", 0, "Cap the frame rate, e.g. 30 to keep a pi cool (0 = as fast as the machine renders)")
+		thermalHot    = flag.Float64("thermal-hot", 75, "Step quality down (high -> balanced -> eco) when the SoC reaches this many °C or throttles (0 = off)")
+		thermalCool   = flag.Float64("thermal-cool", 65, "Step quality back up once the SoC is below this many °C")
+		autoRandom    = flag.Bool("auto-randomize", true, "Automatically randomize visuals periodically")
+		randomFreq    = flag.Duration("randomize-interval", 10*time.Second, "Interval between automatic visual randomization")
+		transition    = flag.Duration("transition", time.Second, "Crossfade to a new pattern, palette or color mode over this long (0 = cut)")
+		backend       = flag.String("backend", "ascii", "Renderer backend (auto|ascii|sdl|sixel|drm|gl|none)")
+		stride        = flag.Int("stride", 1, "Render every Nth frame (1 = no skip)")
+		frameBlend    = flag.Duration("frame-blend", 0, "Blend parameter state across rendered frames for slow backends (0 = off, e.g. 60ms)")
+		beatLookahead = flag.Duration("beat-lookahead", 0, "Fire beat effects this far ahead of the predicted beat to hide pipeline latency (0 = off, e.g. 40ms)")
+		beatSwapEvery = flag.Int("beat-swap-every", 0, "Swap palette on every Nth predicted beat (requires --beat-lookahead, 0 = never)")
+		frameScale    = flag.Float64("scale", 1.0, "Pixel scale multiplier (SDL, sixel, DRM)")
+		dynamicRes    = flag.String("dynamic-res", render.DynResOff, "Dynamic resolution (off|energy|frametime): energy coarsens quiet SDL frames, frametime coarsens SDL and ASCII frames that run over --frame-budget")
+		frameBudget   = flag.Duration("frame-budget", 0, "Render time per frame --dynamic-res frametime keeps to (0 = 3/4 of the frame interval)")
+		fullscreen    = flag.Bool("fullscreen", false, "Use fullscreen SDL window")
+		profileLog    = flag.String("profile-log", "", "Optional path to append frame timing metrics")
+		crashDir      = flag.String("crash-dir", "", "Where crash reports are written (default: next to the saved config)")
+		shaderDir     = flag.String("shader-dir", "", "Directory of .frag files selectable as --pattern shader:<name> with --backend gl (default: shaders/ next to the saved config)")
+		luaDir        = flag.String("lua-dir", "", "Directory of .lua scripts selectable as --pattern lua:<name> (default: ~/.config/golizer/patterns)")
+		pluginDir     = flag.String("plugin-dir", "", "Directory of pattern plugins (.so) registered as patterns at startup (default: plugins/ next to the saved config)")
+		gifBuffer     = flag.Duration("gif-buffer", 10*time.Second, "Recent frames kept for GIF export with the G key or POST /api/capture/gif (0 = off)")
+		captureDir    = flag.String("capture-dir", "", "Where GIF clips and PNG snapshots are saved (default: next to the saved config)")
+		midiOut       = flag.String("midi-out", "", "Send kick/snare/hat notes and clock to a rawmidi port (auto or /dev/snd/midiCxDy)")
+		midiChannel   = flag.Int("midi-channel", 10, "MIDI channel for drum notes (1-16)")
+		midiNotes     = flag.String("midi-notes", "36,38,42", "Kick,snare,hat note numbers")
+		midiClock     = flag.Bool("midi-clock", true, "Send MIDI clock derived from the detected tempo (with --midi-out)")
+		gpioPin       = flag.Int("gpio-pin", 0, "Pulse this GPIO (BCM number) on every kick (0 = off)")
+		gpioPulse     = flag.Duration("gpio-pulse", 10*time.Millisecond, "GPIO trigger pulse length")
+		tapMIDIIn     = flag.String("tap-midi-in", "", "Rawmidi input whose notes tap the tempo (auto or /dev/snd/midiC1D0)")
+		tapMIDINote   = flag.Int("tap-midi-note", -1, "Only this note taps the tempo (-1 = any note)")
+		oscPort       = flag.Int("osc-port", 0, "Listen for OSC control messages on this UDP port, e.g. 9000 (0 = off)")
+		mqttBroker    = flag.String("mqtt-broker", "", "Publish features and take commands over MQTT via this broker (host:port)")
+		mqttTopic     = flag.String("mqtt-topic", "golizer", "Prefix of the MQTT topics")
+		mqttRate      = flag.Float64("mqtt-rate", 10, "Features published to MQTT per second (0 = status and commands only)")
+		mqttUser      = flag.String("mqtt-user", "", "MQTT username")
+		mqttPassword  = flag.String("mqtt-password", "", "MQTT password (or set GOLIZER_MQTT_PASSWORD)")
+		clockMIDIIn   = flag.String("midi-clock-in", "", "Follow the MIDI clock on this rawmidi input as the beat source (auto or /dev/snd/midiC1D0)")
+		inputGain     = flag.Float64("gain", 1.0, "Input gain applied before analysis (0.1-8)")
+		pipeWire      = flag.Bool("pipewire", false, "Name the capture node \"golizer\" in PipeWire and follow golizer.* metadata (gain, noise-floor)")
+		gateHyst      = flag.Float64("gate-hysteresis", 0.05, "How far below the noise floor a band must fall before the gate closes again")
+		gateHold      = flag.Duration("gate-hold", 150*time.Millisecond, "How long a closing gate fades out before going silent")
+		noiseFloor    = flag.Float64("noise-floor", 0.20, "Energy gate to ignore ambient noise (0-0.5)")
+		kiosk         = flag.Bool("kiosk", false, "Unattended exhibit mode: read-only panel and config, only the secret chord quits, restart subsystems on error")
+		kioskChord    = flag.String("kiosk-chord", app.DefaultKioskChord, "Key sequence that quits in kiosk mode (space separated, e.g. \"ctrl+x ctrl+x q\")")
+		words         = flag.String("words", "", "Comma separated words flashed in big letters, one per beat")
+		lyricsPath    = flag.String("lyrics", "", "Timed .lrc file flashed in big letters on beats (starts with the first sound)")
+		recordPath    = flag.String("record-features", "", "Write every frame's analyzer output to this JSONL file")
+		castPath      = flag.String("record-cast", "", "Write the terminal output to this asciinema v2 cast (ascii backend)")
+		replayPath    = flag.String("replay-features", "", "Replay a --record-features file instead of live audio (exits at the end)")
+		relayListen   = flag.String("relay-listen", "", "Serve this instance's audio to followers on addr (e.g. :9091)")
+		relayFrom     = flag.String("relay-from", "", "Follow another instance's audio relay (host:port) instead of a sound card")
+		relayMode     = flag.String("relay-mode", relay.ModeFeatures, "What --relay-from receives (features|pcm)")
+		outputProfile = flag.String("output-profile", "default", "Output profile from the saved config that selects extra output sinks")
+		listSinks     = flag.Bool("list-sinks", false, "List available output sinks and exit")
+		ledOutput     = flag.String("led-output", "", "Drive an LED strip or matrix over SPI (ws2812|apa102, empty = off)")
+		ledLayout     = flag.String("led-layout", "", "LED layout file for --led-output (length, or width/height/serpentine)")
+		ledDevice     = flag.String("led-device", "/dev/spidev0.0", "SPI device for --led-output")
+		webPort       = flag.Int("web-port", 8080, "Web server port (0 = disabled, default: 8080)")
+		noWeb         = flag.Bool("no-web", false, "Disable web server")
+		grpcPort      = flag.Int("grpc-port", 0, "Serve the gRPC control and telemetry API on this port (0 = off)")
+		controlSocket = flag.String("control-socket", defaultSocketPath(), "Also serve the API on this unix socket, with or without the web server (\"\" = off)")
+		showWebURL    = flag.Bool("show-web-url", true, "Show web panel URL in status bar")
+		debugHTTP     = flag.Bool("debug-http", false, "Serve pprof profiles and runtime stats under /debug/ on the web port")
+		webToken      = flag.String("web-token", "", "Require this token for the web panel, API and audio relay (or set GOLIZER_WEB_TOKEN)")
+		fleetToken    = flag.String("fleet-token", "", "Token the fleet proxy sends to other instances, their --web-token (default: none; this instance's own is never sent)")
+		mdns          = flag.Bool("mdns", true, "Advertise the web panel as golizer.local over mDNS")
+		webRateLimit  = flag.Float64("web-rate-limit", 20, "API requests per second allowed per client IP (0 = unlimited)")
+		configFile    = flag.String("config", "", "Config file to load and save (default: $XDG_CONFIG_HOME/golizer/config.json)")
+		settingsFile  = flag.String("settings", "", "Settings file with any of these flags and more (default: golizer.yaml next to the config)")
 	)
 
+	// flags win over the environment (GOLIZER_WEB_PORT ...), which wins over
+	// the settings file
 	flag.Parse()
+	if err := config.ApplyEnv(flag.CommandLine, os.LookupEnv); err != nil {
+		log.Fatalf("environment: %v", err)
+	}
+	configPath = config.Path(*configFile)
+	settings, err := loadSettings(*settingsFile)
+	if err != nil {
+		log.Fatalf("settings: %v", err)
+	}
+	if err := settings.ApplyFile(flag.CommandLine, "config", "settings"); err != nil {
+		log.Fatalf("settings: %v", err)
+	}
 
 	runtime.GOMAXPROCS(runtime.NumCPU())
 	rdebug.SetGCPercent(200)
 
 	if *profileLog == "" {
-		if envPath := strings.TrimSpace(os.Getenv("GOLIZER_PROFILE_LOG")); envPath != "" {
-			*profileLog = envPath
-		} else {
-			*profileLog = filepath.Join(os.TempDir(), "golizer_profile.csv")
-		}
+		*profileLog = filepath.Join(os.TempDir(), "golizer_profile.csv")
 	}
 
 	backendName, err := resolveBackend(*backend)
 	if err != nil {
 		log.Fatalf("backend: %v", err)
 	}
+	// the none backend has no screen and never takes the terminal over
+	headless := backendName == "none"
 
 	if *width <= 0 || *height <= 0 {
 		log.Fatalf("invalid dimensions: width=%d height=%d", *width, *height)
 	}
 
-	// FPS always unlimited
+	// the nominal frame rate the app falls back on; only --max-fps caps
+	// the real one
 	targetFPSValue := 0.0
+	if *maxFPS < 0 {
+		log.Fatalf("max-fps must not be negative (got %g)", *maxFPS)
+	}
 
 	if *bufferSize <= 0 {
 		log.Fatalf("buffer-size must be positive (got %d)", *bufferSize)
 	}
+	if err := app.CheckStatusFormat(*statusFormat); err != nil {
+		log.Fatalf("status-format: %v", err)
+	}
+	if !slices.Contains(app.StatusPositions, *statusPos) {
+		log.Fatalf("status-position: %q is not top or bottom", *statusPos)
+	}
 
-	if fd := int(os.Stdout.Fd()); fd >= 0 {
+	if fd := int(os.Stdout.Fd()); fd >= 0 && !headless {
 		if w, h, err := term.GetSize(fd); err == nil {
 			if w > 0 {
 				*width = w
@@ -97,17 +203,26 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	// ensure terminal is restored on any exit (including panic)
-	defer func() {
-		// restore terminal state
-		fmt.Print("\x1b[?25h")   // show cursor
-		fmt.Print("\x1b[?1049l") // exit alternate screen
-		fmt.Print("\x1b[0m")     // reset colors
-	}()
+	// a panic restores the terminal, writes a crash report and exits
+	crashes := crash.NewHandler()
+	if !headless {
+		crashes.Restore = restoreTerminal
+	}
+	crashes.ProfileLog = *profileLog
+	crashes.Dir = *crashDir
+	if crashes.Dir == "" {
+		crashes.Dir = filepath.Dir(configPath)
+	}
+	defer crashes.Recover()
+	// ensure terminal is restored on any exit
+	if !headless {
+		defer restoreTerminal()
+	}
 
-	logger := log.New(os.Stdout, "[golizer] ", log.LstdFlags)
+	crashes.Log = crash.NewLog(crashLogLines)
+	logger := log.New(io.MultiWriter(os.Stdout, crashes.Log), "[golizer] ", log.LstdFlags)
 	if !*debug {
-		logger.SetOutput(os.Stderr)
+		logger.SetOutput(io.MultiWriter(os.Stderr, crashes.Log))
 		logger.SetFlags(0)
 	}
 
@@ -118,8 +233,18 @@ func main() {
 		logger.Printf("render backend -> %s", backendName)
 	}
 
-	needAudio := !*noAudio || *listDevs
+	if *listSinks {
+		for _, name := range sink.Names() {
+			fmt.Println(name)
+		}
+		return
+	}
+
+	needAudio := (!*noAudio && *replayPath == "" && *relayFrom == "") || *listDevs
 	if needAudio {
+		if *pipeWire {
+			audio.ExportPipeWireProps("golizer")
+		}
 		if err := audio.Initialize(); err != nil {
 			logger.Fatalf("failed to initialize PortAudio: %v", err)
 		}
@@ -157,16 +282,30 @@ func main() {
 		logger.Printf("quality auto -> %s (arch=%s cores=%d)", qualityName, runtime.GOARCH, runtime.NumCPU())
 	}
 
+	plugins, err := render.LoadPlugins(pluginDirPath(*pluginDir))
+	if err != nil {
+		logger.Printf("pattern plugins: %v", err)
+	}
+	if len(plugins) > 0 {
+		logger.Printf("pattern plugins -> %s", strings.Join(plugins, ", "))
+	}
+
 	paletteName := resolvePaletteName(*palette, qualityName)
 	patternName := resolvePatternName(*pattern, qualityName)
+	if strings.HasPrefix(patternName, render.ExprPrefix) {
+		if err := render.CheckExpr(patternName); err != nil {
+			logger.Fatalf("pattern: %v", err)
+		}
+	} else if strings.Contains(patternName, "+") {
+		if err := render.CheckLayers(patternName); err != nil {
+			logger.Fatalf("pattern: %v", err)
+		}
+	}
 	colorModeName := strings.ToLower(strings.TrimSpace(*colorMode))
 	if colorModeName == "" {
 		colorModeName = "chromatic"
 	}
 
-	// FPS always unlimited - let each machine run at max
-	targetFPSValue = 0.0 // always unlimited
-
 	if strings.EqualFold(*palette, "auto") || strings.TrimSpace(*palette) == "" {
 		logger.Printf("palette auto -> %s", paletteName)
 	}
@@ -175,9 +314,12 @@ func main() {
 	}
 
 	// load saved config if exists
-	savedConfig := loadSavedConfig()
+	var noiseFloors analyzer.NoiseFloors
+	var envelopes analyzer.Envelopes
+	savedConfig := loadSavedConfig(logger, *kiosk)
+	crashes.Config = func() any { return crashSnapshot(savedConfig) }
 	if savedConfig != nil {
-		logger.Printf("loaded saved config from %s", getConfigPath())
+		logger.Printf("loaded saved config from %s", configPath)
 		// apply saved config only if flags weren't passed
 		if !flagIsPassed("palette") && savedConfig.Palette != "" {
 			paletteName = savedConfig.Palette
@@ -191,10 +333,14 @@ func main() {
 		if !flagIsPassed("noise-floor") && savedConfig.NoiseFloor > 0 {
 			*noiseFloor = savedConfig.NoiseFloor
 		}
+		if !flagIsPassed("noise-floor") {
+			noiseFloors = savedConfig.NoiseFloors
+		}
+		envelopes = savedConfig.Envelopes
 		if !flagIsPassed("buffer-size") && savedConfig.BufferSize > 0 {
 			*bufferSize = savedConfig.BufferSize
 		}
-		// FPS always unlimited - ignore saved FPS
+		// the saved targetFPS is ignored, --max-fps caps frames
 		if !flagIsPassed("quality") && savedConfig.Quality != "" {
 			qualityName = savedConfig.Quality
 		}
@@ -207,6 +353,76 @@ func main() {
 		if !flagIsPassed("status") {
 			*showStatus = savedConfig.ShowStatusBar
 		}
+		for name, chars := range savedConfig.Palettes {
+			if err := render.RegisterPalette(name, chars); err != nil {
+				logger.Printf("config: %v", err)
+			}
+		}
+	}
+	if err := applyBands(settings.Bands, &envelopes, &noiseFloors, *noiseFloor); err != nil {
+		logger.Fatalf("settings: %v", err)
+	}
+	if *paletteChars != "" {
+		if err := render.RegisterPalette("custom", *paletteChars); err != nil {
+			logger.Fatalf("palette-chars: %v", err)
+		}
+		if !flagIsPassed("palette") {
+			paletteName = "custom"
+		}
+	}
+
+	notes, err := parseMIDINotes(*midiNotes)
+	if err != nil {
+		logger.Fatalf("midi-notes: %v", err)
+	}
+
+	var sinks []sink.Config
+	var widgets []app.WidgetConfig
+	if savedConfig != nil {
+		sinks = savedConfig.OutputProfiles[*outputProfile]
+		widgets = savedConfig.Widgets
+	}
+	if sinks == nil && flagIsPassed("output-profile") {
+		logger.Fatalf("output profile %q not found in %s", *outputProfile, configPath)
+	}
+	if len(settings.Outputs) > 0 {
+		sinks = append(slices.Clone(sinks), settingsOutputs(settings.Outputs)...)
+	}
+	if *ledOutput != "" {
+		sinks = append(slices.Clone(sinks), sink.Config{Name: "led", Options: map[string]string{
+			"chip":   *ledOutput,
+			"layout": *ledLayout,
+			"device": *ledDevice,
+		}})
+	}
+
+	var track lyrics.Track
+	if *lyricsPath != "" {
+		track, err = lyrics.Load(*lyricsPath)
+		if err != nil {
+			logger.Fatalf("lyrics: %v", err)
+		}
+		logger.Printf("lyrics: %d lines from %s", len(track), *lyricsPath)
+	}
+
+	if !slices.Contains(render.DynamicResolutionNames(), *dynamicRes) {
+		logger.Fatalf("dynamic-res: unknown mode %q (have %v)", *dynamicRes, render.DynamicResolutionNames())
+	}
+
+	var symmetryEffects []render.EffectConfig
+	if *symmetry != "" {
+		symmetryEffects, err = render.ParseSymmetry(*symmetry)
+		if err != nil {
+			logger.Fatalf("symmetry: %v", err)
+		}
+	}
+
+	analysisName, err := resolveAnalysisMode(*analysisMode, qualityName)
+	if err != nil {
+		logger.Fatalf("analysis: %v", err)
+	}
+	if strings.EqualFold(*analysisMode, "auto") && analysisName != analyzer.ModeFFT {
+		logger.Printf("analysis auto -> %s (quality %s)", analysisName, qualityName)
 	}
 
 	appConfig := app.Config{
@@ -214,30 +430,75 @@ func main() {
 		Width:          *width,
 		Height:         *height,
 		TargetFPS:      targetFPSValue,
+		MaxFPS:         *maxFPS,
 		BufferSize:     *bufferSize,
+		AnalysisMode:   analysisName,
 		DisableAudio:   *noAudio,
 		ShowStatusBar:  *showStatus,
+		StatusFormat:   *statusFormat,
+		StatusPosition: *statusPos,
+		StatusOverlay:  *statusOverlay,
 		Palette:        paletteName,
 		Pattern:        patternName,
 		ColorMode:      colorModeName,
 		UseANSI:        !*noColor,
 		Quality:        qualityName,
+		ThermalHot:     *thermalHot,
+		ThermalCool:    *thermalCool,
 		AutoRandomize:  *autoRandom,
 		RandomInterval: *randomFreq,
+		RandomWeights:  settingsWeights(settings.Randomizer),
+		Transition:     *transition,
 		ProfileLog:     *profileLog,
 		Backend:        backendName,
 		FrameStride:    maxInt(1, *stride),
 		Scale:          clampFloat(*frameScale, 0.25, 4.0),
+		DynamicRes:     *dynamicRes,
+		FrameBudget:    *frameBudget,
 		Fullscreen:     *fullscreen,
 		NoiseFloor:     clampFloat(*noiseFloor, 0.0, 0.5),
+		NoiseFloors:    noiseFloors,
+		Envelopes:      envelopes,
+		GateHysteresis: clampFloat(*gateHyst, 0.0, 0.5),
+		GateHold:       *gateHold,
+		InputGain:      clampFloat(*inputGain, 0.1, 8.0),
+		FrameBlend:     *frameBlend,
+		BeatLookahead:  *beatLookahead,
+		BeatSwapEvery:  *beatSwapEvery,
+		MIDIOut:        strings.TrimSpace(*midiOut),
+		MIDIChannel:    *midiChannel,
+		MIDINotes:      notes,
+		MIDIClock:      *midiClock,
+		GPIOPin:        *gpioPin,
+		GPIOPulse:      *gpioPulse,
+		TapMIDIIn:      *tapMIDIIn,
+		TapMIDINote:    *tapMIDINote,
+		ClockMIDIIn:    *clockMIDIIn,
+		Words:          splitWords(*words),
+		Lyrics:         track,
+		RecordFeatures: *recordPath,
+		RecordCast:     *castPath,
+		ReplayFeatures: *replayPath,
+		RelayListen:    *relayListen,
+		RelayFrom:      *relayFrom,
+		RelayMode:      *relayMode,
+		Sinks:          sinks,
+		Widgets:        widgets,
+		Kiosk:          *kiosk,
+		KioskChord:     *kioskChord,
+		Crash:          crashes,
+		ShaderDir:      shaderDirPath(*shaderDir),
+		LuaDir:         luaDirPath(*luaDir),
+		GIFBuffer:      max(0, *gifBuffer),
+		CaptureDir:     captureDirPath(*captureDir),
+		PresetsPath:    filepath.Join(filepath.Dir(configPath), "presets.json"),
+		WebToken:       *webToken,
 		Log:            logger,
 	}
 
-	// FPS always unlimited - removed quality-based FPS limits
-
 	a, err := app.New(appConfig)
 	if err != nil {
-		logger.Fatalf("failed to create app: %v", err)
+		crashes.Fatalf("failed to create app: %v", err)
 	}
 	defer func() {
 		if err := a.Close(); err != nil {
@@ -248,20 +509,104 @@ func main() {
 	// apply saved parameters if config was loaded
 	if savedConfig != nil {
 		a.SetParams(savedConfig.Params)
+		if err := a.GetRenderer().SetEffects(savedConfig.Effects); err != nil {
+			logger.Printf("config: effects: %v", err)
+		}
+		for name, curve := range savedConfig.ColorCurves {
+			if err := a.GetRenderer().SetColorCurve(name, curve); err != nil {
+				logger.Printf("config: color curves: %v", err)
+			}
+		}
+		if savedConfig.Gradient != nil {
+			if err := a.GetRenderer().SetGradient(savedConfig.Gradient); err != nil {
+				logger.Printf("config: %v", err)
+			}
+		}
+		for name, values := range savedConfig.PatternParams {
+			if err := a.GetRenderer().SetPatternParams(name, values); err != nil {
+				logger.Printf("config: pattern params: %v", err)
+			}
+		}
+		if pl := savedConfig.Playlist; pl != nil {
+			if err := a.SetPlaylist(*pl); err != nil {
+				logger.Printf("config: %v", err)
+			} else if pl.Autoplay && len(pl.Steps) > 0 {
+				a.PlayPlaylist()
+			}
+		}
+	}
+
+	presets, err := settingsPresets(settings.Presets)
+	if err != nil {
+		logger.Fatalf("settings: %v", err)
+	}
+	for _, p := range presets {
+		if err := a.Presets().Put(p); err != nil {
+			logger.Printf("settings: preset %q: %v", p.Name, err)
+		}
+	}
+
+	// an explicit --symmetry wins over the saved effects
+	if symmetryEffects != nil {
+		if err := a.GetRenderer().SetEffects(symmetryEffects); err != nil {
+			logger.Fatalf("symmetry: %v", err)
+		}
 	}
 
-	// start web server automatically (unless disabled)
-	if !*noWeb && *webPort > 0 {
+	if *pipeWire && !*noAudio {
+		go watchPipeWire(ctx, a, logger)
+	}
+	if *oscPort > 0 && *kiosk {
+		// OSC only takes commands and has no login; kiosk mode is read-only
+		logger.Printf("[osc] not listening on udp :%d, kiosk mode is read-only", *oscPort)
+	} else if *oscPort > 0 {
+		go watchOSC(ctx, a, *oscPort, logger)
+	}
+	if *mqttBroker != "" {
+		go watchMQTT(ctx, a, mqttConfig{
+			Broker:   *mqttBroker,
+			Topic:    strings.TrimSuffix(*mqttTopic, "/"),
+			Rate:     *mqttRate,
+			Username: *mqttUser,
+			Password: *mqttPassword,
+			ReadOnly: *kiosk,
+		}, logger)
+	}
+
+	// start web server automatically (unless disabled); the control socket
+	// and gRPC run without it
+	port := *webPort
+	if *noWeb {
+		port = 0
+	}
+	if port > 0 || *controlSocket != "" || *grpcPort > 0 {
 		webServer := web.NewServer(a)
+		webServer.SetKiosk(*kiosk)
+		webServer.SetDebug(*debugHTTP)
+		webServer.SetToken(*webToken)
+		webServer.SetFleetToken(*fleetToken)
+		webServer.SetRateLimit(*webRateLimit)
+		webServer.SetMDNS(*mdns)
+		webServer.SetSocket(*controlSocket)
+		webServer.SetGRPC(*grpcPort)
+		webServer.SetConfigPath(configPath)
+		webCtx, stopWeb := context.WithCancel(ctx)
+		webDone := make(chan struct{})
 		go func() {
-			if err := webServer.Start(*webPort); err != nil {
+			defer close(webDone)
+			if err := webServer.Start(webCtx, port); err != nil {
 				logger.Printf("web server error: %v", err)
 			}
 		}()
+		// quitting from the keyboard doesn't cancel ctx, so stop the panel
+		// on the way out either way
+		defer func() {
+			stopWeb()
+			<-webDone
+		}()
+	}
 
-		// try to setup mDNS automatically
-		go setupMDNS(*webPort, logger)
-
+	if port > 0 {
 		// get local IP for display
 		localIP := getLocalIP()
 		logger.Printf("web control panel:")
@@ -269,7 +614,6 @@ func main() {
 		if localIP != "" {
 			logger.Printf("  network: http://%s:%d", localIP, *webPort)
 		}
-		logger.Printf("  mDNS:   http://golizer.local:%d (if configured)", *webPort)
 
 		// set web panel URL in renderer status bar
 		if *showWebURL {
@@ -288,7 +632,7 @@ func main() {
 			fmt.Print("\n")
 			return
 		}
-		logger.Fatalf("runtime error: %v", err)
+		crashes.Fatalf("runtime error: %v", err)
 	}
 
 	time.Sleep(50 * time.Millisecond)
@@ -343,6 +687,11 @@ func resolvePaletteName(requested string, quality string) string {
 
 func resolvePatternName(requested string, quality string) string {
 	name := strings.ToLower(strings.TrimSpace(requested))
+	if strings.HasPrefix(name, render.ShaderPrefix) || strings.HasPrefix(name, render.LuaPrefix) || strings.HasPrefix(name, render.ExprPrefix) {
+		// shader and script file names and expressions keep their case
+		prefix := name[:strings.Index(name, ":")+1]
+		return prefix + strings.TrimSpace(requested)[len(prefix):]
+	}
 	if name == "" || name == "auto" {
 		switch quality {
 		case "eco":
@@ -388,11 +737,71 @@ func resolveBackend(input string) (string, error) {
 			return "", fmt.Errorf("SDL backend not available in this build (rebuild with -tags sdl)")
 		}
 		return "sdl", nil
+	case "sixel":
+		return "sixel", nil
+	case "drm", "kms":
+		if !render.SupportsDRM() {
+			return "", fmt.Errorf("DRM backend not available in this build (rebuild with -tags drm)")
+		}
+		return "drm", nil
+	case "gl", "gles":
+		if !render.SupportsGL() {
+			return "", fmt.Errorf("GL backend not available in this build (rebuild with -tags gl)")
+		}
+		return "gl", nil
+	case "none", "headless":
+		return "none", nil
 	default:
 		return "", fmt.Errorf("unknown backend %q", input)
 	}
 }
 
+func resolveAnalysisMode(input, quality string) (string, error) {
+	switch value := strings.ToLower(strings.TrimSpace(input)); value {
+	case "", "auto":
+		if quality == "eco" {
+			return analyzer.ModeGoertzel, nil
+		}
+		return analyzer.ModeFFT, nil
+	case analyzer.ModeFFT:
+		return analyzer.ModeFFT, nil
+	case analyzer.ModeCQT, "constant-q":
+		return analyzer.ModeCQT, nil
+	case analyzer.ModeGoertzel, "eco":
+		return analyzer.ModeGoertzel, nil
+	default:
+		return "", fmt.Errorf("unknown analysis mode %q", input)
+	}
+}
+
+// splitWords reads the --words list, dropping empty entries.
+func splitWords(input string) []string {
+	var out []string
+	for _, word := range strings.Split(input, ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			out = append(out, word)
+		}
+	}
+	return out
+}
+
+// parseMIDINotes reads the "kick,snare,hat" note list.
+func parseMIDINotes(value string) ([3]uint8, error) {
+	var notes [3]uint8
+	parts := strings.Split(value, ",")
+	if len(parts) != len(notes) {
+		return notes, fmt.Errorf("want 3 comma separated notes, got %q", value)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 0 || n > 127 {
+			return notes, fmt.Errorf("invalid note %q", part)
+		}
+		notes[i] = uint8(n)
+	}
+	return notes, nil
+}
+
 func clampFloat(v, minVal, maxVal float64) float64 {
 	if v < minVal {
 		return minVal
@@ -410,56 +819,23 @@ func maxInt(a, b int) int {
 	return b
 }
 
-// setupMDNS tries to configure avahi-daemon for golizer.local
-func setupMDNS(port int, logger *log.Logger) {
-	// check if avahi-daemon is installed and running
-	if _, err := exec.LookPath("avahi-daemon"); err != nil {
-		logger.Printf("[web] mDNS: avahi-daemon not found (install: sudo apt install avahi-daemon)")
-		return
-	}
-
-	// check if avahi-daemon is running
-	cmd := exec.Command("systemctl", "is-active", "--quiet", "avahi-daemon")
-	if err := cmd.Run(); err != nil {
-		logger.Printf("[web] mDNS: avahi-daemon not running (start: sudo systemctl start avahi-daemon)")
-		return
-	}
-
-	// check if service file already exists
-	serviceFile := "/etc/avahi/services/golizer.service"
-	if _, err := os.Stat(serviceFile); err == nil {
-		logger.Printf("[web] mDNS: already configured at http://golizer.local:%d", port)
-		return
-	}
-
-	// try to create service file (might need sudo)
-	serviceContent := fmt.Sprintf(`<?xml version="1.0" standalone='no'?>
-<!DOCTYPE service-group SYSTEM "avahi-service.dtd">
-<service-group>
-  <name replace-wildcards="yes">golizer</name>
-  <service>
-    <type>_http._tcp</type>
-    <port>%d</port>
-    <txt-record>path=/</txt-record>
-  </service>
-</service-group>
-`, port)
-
-	// try without sudo first (if user has write access)
-	if err := os.WriteFile(serviceFile, []byte(serviceContent), 0644); err != nil {
-		// try with sudo
-		writeCmd := exec.Command("sh", "-c", fmt.Sprintf("echo '%s' | sudo tee %s > /dev/null", serviceContent, serviceFile))
-		if err := writeCmd.Run(); err != nil {
-			logger.Printf("[web] mDNS: can't configure automatically (run: sudo tee /etc/avahi/services/golizer.service)")
+// watchPipeWire applies golizer.* metadata set on golizer's node with
+// pw-metadata to the running app.
+func watchPipeWire(ctx context.Context, a *app.App, logger *log.Logger) {
+	err := audio.WatchPipeWireMetadata(ctx, "golizer", func(key string, value float64) {
+		switch key {
+		case audio.PipeWireKeyPrefix + "gain":
+			a.SetInputGain(clampFloat(value, 0.1, 8.0))
+		case audio.PipeWireKeyPrefix + "noise-floor":
+			a.SetNoiseFloor(clampFloat(value, 0.0, 0.5))
+		default:
 			return
 		}
+		logger.Printf("[pipewire] %s -> %.2f", key, value)
+	})
+	if err != nil {
+		logger.Printf("[pipewire] metadata watch stopped: %v", err)
 	}
-
-	// reload avahi
-	reloadCmd := exec.Command("sudo", "systemctl", "reload", "avahi-daemon")
-	reloadCmd.Run() // ignore error
-
-	logger.Printf("[web] mDNS: configured at http://golizer.local:%d", port)
 }
 
 // getLocalIP returns the first non-loopback IP address
@@ -479,41 +855,131 @@ func getLocalIP() string {
 	return ""
 }
 
+// crashLogLines is how much of the log a crash report keeps.
+const crashLogLines = 200
+
+// restoreTerminal leaves the alternate screen and shows the cursor again.
+func restoreTerminal() {
+	fmt.Print("\x1b[?25h")   // show cursor
+	fmt.Print("\x1b[?1049l") // exit alternate screen
+	fmt.Print("\x1b[0m")     // reset colors
+}
+
+// crashSnapshot is the config section of a crash report: the value of every
+// flag and the saved config that was loaded.
+func crashSnapshot(saved *savedConfig) any {
+	flags := make(map[string]string)
+	flag.VisitAll(func(f *flag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+	return struct {
+		Flags map[string]string `json:"flags"`
+		Saved *savedConfig      `json:"savedConfig,omitempty"`
+	}{flags, saved}
+}
+
 // saved config type (matches web.SavedConfig)
 type savedConfig struct {
-	Params        params.Parameters `json:"params"`
-	Palette       string            `json:"palette"`
-	Pattern       string            `json:"pattern"`
-	ColorMode     string            `json:"colorMode"`
-	NoiseFloor    float64           `json:"noiseFloor"`
-	BufferSize    int               `json:"bufferSize"`
-	TargetFPS     float64           `json:"targetFPS"`
-	Quality       string            `json:"quality"`
-	Width         int               `json:"width"`
-	Height        int               `json:"height"`
-	ShowStatusBar bool              `json:"showStatusBar"`
+	Version       int                          `json:"version"`
+	Params        params.Parameters            `json:"params"`
+	Palette       string                       `json:"palette"`
+	Pattern       string                       `json:"pattern"`
+	ColorMode     string                       `json:"colorMode"`
+	NoiseFloor    float64                      `json:"noiseFloor"`
+	NoiseFloors   analyzer.NoiseFloors         `json:"noiseFloors"`
+	Envelopes     analyzer.Envelopes           `json:"envelopes"`
+	BufferSize    int                          `json:"bufferSize"`
+	TargetFPS     float64                      `json:"targetFPS"`
+	Quality       string                       `json:"quality"`
+	Width         int                          `json:"width"`
+	Height        int                          `json:"height"`
+	ShowStatusBar bool                         `json:"showStatusBar"`
+	Effects       []render.EffectConfig        `json:"effects,omitempty"`
+	ColorCurves   map[string]render.ColorCurve `json:"colorCurves,omitempty"`
+	Gradient      []render.GradientStop        `json:"gradient,omitempty"`
+	// PatternParams holds the pattern knobs that differ from the defaults.
+	PatternParams map[string]map[string]float64 `json:"patternParams,omitempty"`
+	// Playlist sequences presets, see app.Playlist.
+	Playlist *app.Playlist `json:"playlist,omitempty"`
+	// Palettes maps custom palette names to their characters, lightest first.
+	Palettes map[string]string `json:"palettes,omitempty"`
+	// OutputProfiles maps a profile name to the sinks it enables.
+	OutputProfiles map[string][]sink.Config `json:"outputProfiles,omitempty"`
+	// Widgets pins readouts (clock, stats, now playing) to screen corners.
+	Widgets []app.WidgetConfig `json:"widgets,omitempty"`
 }
 
-func getConfigPath() string {
-	// try to save in same directory as binary
-	if exe, err := os.Executable(); err == nil {
-		exeDir := filepath.Dir(exe)
-		return filepath.Join(exeDir, "golizer-config.json")
+// shaderDirPath defaults the user shader directory to shaders/ next to the
+// saved config.
+func shaderDirPath(dir string) string {
+	if dir = strings.TrimSpace(dir); dir != "" {
+		return dir
 	}
-	// fallback to home directory
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".golizer-config.json")
+	return filepath.Join(filepath.Dir(configPath), "shaders")
 }
 
-func loadSavedConfig() *savedConfig {
-	configPath := getConfigPath()
+// captureDirPath defaults the clip directory to the saved config's.
+func captureDirPath(dir string) string {
+	if dir = strings.TrimSpace(dir); dir != "" {
+		return dir
+	}
+	return filepath.Dir(configPath)
+}
+
+// luaDirPath defaults the Lua pattern directory to golizer/patterns in the
+// user config directory.
+func luaDirPath(dir string) string {
+	if dir = strings.TrimSpace(dir); dir != "" {
+		return dir
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "golizer", "patterns")
+}
+
+// pluginDirPath defaults the pattern plugin directory to plugins/ next to
+// the saved config.
+func pluginDirPath(dir string) string {
+	if dir = strings.TrimSpace(dir); dir != "" {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(configPath), "plugins")
+}
+
+// configPath is the config file this run loads and saves, from --config.
+var configPath string
+
+// loadSavedConfig reads the config file, migrating an older one and saving
+// the result next to a backup; readOnly (kiosk mode) leaves the file alone.
+func loadSavedConfig(logger *log.Logger, readOnly bool) *savedConfig {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil // config file doesn't exist, that's ok
 	}
-	var config savedConfig
-	if err := json.Unmarshal(data, &config); err != nil {
+	migrated, from, err := config.Migrate(data)
+	if err != nil {
+		if migrated == nil {
+			return nil // invalid config, ignore
+		}
+		logger.Printf("config: %v (loading known fields only)", err)
+	} else if from < config.Version && readOnly {
+		logger.Printf("config: %s is v%d, migrated in memory only (read-only in kiosk mode)", configPath, from)
+	} else if from < config.Version {
+		backup := fmt.Sprintf("%s.v%d.bak", configPath, from)
+		if err := os.WriteFile(backup, data, 0644); err != nil {
+			logger.Printf("config: backup before migration failed: %v", err)
+		} else if err := os.WriteFile(configPath, migrated, 0644); err != nil {
+			logger.Printf("config: write migrated file failed: %v", err)
+		} else {
+			logger.Printf("config: migrated %s from v%d to v%d (backup: %s)", configPath, from, config.Version, backup)
+		}
+	}
+	data = migrated
+	var cfg savedConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil // invalid config, ignore
 	}
-	return &config
+	return &cfg
 }
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/app"
+	"github.com/guidoenr/golizer/internal/mqtt"
+)
+
+// mqttRetryMax caps the wait between reconnects to a broker that is gone.
+const mqttRetryMax = 30 * time.Second
+
+// mqttConfig is what the --mqtt-* flags set.
+type mqttConfig struct {
+	Broker   string
+	Topic    string  // prefix of every topic
+	Rate     float64 // features published per second, 0 = off
+	Username string
+	Password string
+	ReadOnly bool // kiosk mode: publish only, take no commands
+}
+
+// mqttFeatures is what goes out on <topic>/features.
+type mqttFeatures struct {
+	Sub          float64 `json:"sub"`
+	Bass         float64 `json:"bass"`
+	LowMid       float64 `json:"lowMid"`
+	Mid          float64 `json:"mid"`
+	HighMid      float64 `json:"highMid"`
+	Treble       float64 `json:"treble"`
+	Overall      float64 `json:"overall"`
+	BeatStrength float64 `json:"beatStrength"`
+	// Beat is true when a beat landed since the last message.
+	Beat  bool    `json:"beat"`
+	Drop  bool    `json:"drop"`
+	Tempo float64 `json:"tempo"`
+	Level float64 `json:"level"`
+}
+
+// mqttStatus is the retained <topic>/status message, sent when it changes.
+type mqttStatus struct {
+	Pattern         string `json:"pattern"`
+	Palette         string `json:"palette"`
+	ColorMode       string `json:"colorMode"`
+	BPM             int    `json:"bpm"`
+	AutoRandomize   bool   `json:"autoRandomize"`
+	PlaylistPlaying bool   `json:"playlistPlaying"`
+}
+
+// mqttPayload reads a command payload as text: a number, on/off or
+// true/false for switches, or a name.
+type mqttPayload string
+
+func (p mqttPayload) Float(i int) (float64, bool) {
+	s := strings.ToLower(strings.TrimSpace(string(p)))
+	switch {
+	case i > 0:
+		return 0, false
+	case s == "on" || s == "true":
+		return 1, true
+	case s == "off" || s == "false":
+		return 0, true
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
+
+func (p mqttPayload) String(i int) (string, bool) {
+	s := strings.TrimSpace(string(p))
+	return s, i == 0 && s != ""
+}
+
+// watchMQTT keeps a broker connection up until ctx is cancelled,
+// reconnecting with a growing delay when it drops.
+func watchMQTT(ctx context.Context, a *app.App, cfg mqttConfig, logger *log.Logger) {
+	retry := time.Second
+	for {
+		start := time.Now()
+		err := runMQTT(ctx, a, cfg, logger)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(start) > mqttRetryMax {
+			retry = time.Second
+		}
+		logger.Printf("[mqtt] %v; retrying in %s", err, retry)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry = min(retry*2, mqttRetryMax)
+	}
+}
+
+// runMQTT serves one connection: commands come in on <topic>/cmd/...
+// unless cfg.ReadOnly, features and status go out.
+func runMQTT(ctx context.Context, a *app.App, cfg mqttConfig, logger *log.Logger) error {
+	host, _ := os.Hostname()
+	availability := cfg.Topic + "/availability"
+	dialCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	client, err := mqtt.Connect(dialCtx, cfg.Broker, mqtt.Options{
+		ClientID: "golizer-" + host,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		Will:     &mqtt.Message{Topic: availability, Payload: []byte("offline"), Retain: true},
+	})
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	logger.Printf("[mqtt] connected to %s", cfg.Broker)
+
+	cmdPrefix := cfg.Topic + "/cmd/"
+	if cfg.ReadOnly {
+		logger.Printf("[mqtt] kiosk mode is read-only, not subscribing to %s#", cmdPrefix)
+	} else if err := client.Subscribe(cmdPrefix + "#"); err != nil {
+		return err
+	}
+	if err := client.Publish(mqtt.Message{Topic: availability, Payload: []byte("online"), Retain: true}); err != nil {
+		return err
+	}
+
+	connCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	go func() {
+		<-connCtx.Done()
+		if ctx.Err() != nil {
+			// a clean disconnect skips the will, so say goodbye ourselves
+			client.Publish(mqtt.Message{Topic: availability, Payload: []byte("offline"), Retain: true})
+		}
+		client.Close()
+	}()
+	go publishMQTT(connCtx, a, client, cfg, logger)
+
+	return client.Serve(func(m mqtt.Message) {
+		path, ok := strings.CutPrefix(strings.ToLower(m.Topic), strings.ToLower(cmdPrefix))
+		if !ok || cfg.ReadOnly {
+			return
+		}
+		if err := applyCommand(a, path, mqttPayload(m.Payload)); err != nil {
+			logger.Printf("[mqtt] %s: %v", m.Topic, err)
+		}
+	})
+}
+
+// publishMQTT sends features at cfg.Rate and the status whenever it
+// changes, checked once a second when features are off.
+func publishMQTT(ctx context.Context, a *app.App, client *mqtt.Client, cfg mqttConfig, logger *log.Logger) {
+	every := time.Second
+	if cfg.Rate > 0 {
+		every = time.Duration(float64(time.Second) / cfg.Rate)
+	}
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	var lastStatus []byte
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if cfg.Rate > 0 {
+			// catch a beat in any frame since the last message, not just the
+			// newest one
+			frames := max(1, int(math.Ceil(a.GetFPS()/cfg.Rate)))
+			f := a.GetFeatures()
+			beat := false
+			for _, h := range a.FeatureHistory(frames) {
+				beat = beat || h.Onset
+			}
+			payload, _ := json.Marshal(mqttFeatures{
+				Sub: f.Sub, Bass: f.Bass, LowMid: f.LowMid, Mid: f.Mid, HighMid: f.HighMid, Treble: f.Treble,
+				Overall: f.Overall, BeatStrength: f.BeatStrength, Beat: beat, Drop: f.IsDrop,
+				Tempo: f.Tempo, Level: f.Level,
+			})
+			if err := client.Publish(mqtt.Message{Topic: cfg.Topic + "/features", Payload: payload}); err != nil {
+				logger.Printf("[mqtt] publish: %v", err)
+				return
+			}
+		}
+
+		renderer := a.GetRenderer()
+		_, playlist := a.Playlist()
+		status, _ := json.Marshal(mqttStatus{
+			Pattern:         renderer.PatternName(),
+			Palette:         renderer.PaletteName(),
+			ColorMode:       renderer.ColorModeName(),
+			BPM:             int(math.Round(a.GetFeatures().Tempo)),
+			AutoRandomize:   a.GetConfig().AutoRandomize(),
+			PlaylistPlaying: playlist.Playing,
+		})
+		if bytes.Equal(status, lastStatus) {
+			continue
+		}
+		if err := client.Publish(mqtt.Message{Topic: cfg.Topic + "/status", Payload: status, Retain: true}); err != nil {
+			logger.Printf("[mqtt] publish: %v", err)
+			return
+		}
+		lastStatus = status
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/guidoenr/golizer/internal/app"
+	"github.com/guidoenr/golizer/internal/osc"
+)
+
+// oscPrefix namespaces the addresses golizer answers to.
+const oscPrefix = "/golizer/"
+
+// watchOSC applies OSC messages from TouchOSC, VCV Rack or a lighting
+// console to the running app until ctx is cancelled.
+func watchOSC(ctx context.Context, a *app.App, port int, logger *log.Logger) {
+	addr := fmt.Sprintf(":%d", port)
+	logger.Printf("[osc] listening on udp %s", addr)
+	err := osc.Listen(ctx, addr, func(msg osc.Message) {
+		if err := applyOSC(a, msg); err != nil {
+			logger.Printf("[osc] %s: %v", msg.Address, err)
+		}
+	}, func(err error) {
+		logger.Printf("[osc] %v", err)
+	})
+	if err != nil {
+		logger.Printf("[osc] listener stopped: %v", err)
+	}
+}
+
+// applyOSC handles one message; addresses outside /golizer/ are ignored so
+// golizer can share a controller layout with other gear.
+func applyOSC(a *app.App, msg osc.Message) error {
+	path, ok := strings.CutPrefix(strings.ToLower(msg.Address), oscPrefix)
+	if !ok {
+		return nil
+	}
+	return applyCommand(a, path, msg)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/app"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// remoteParams maps the params/<name> command to the knobs the web panel
+// has, keyed in lower case.
+var remoteParams = map[string]func(p *params.Parameters, v float64){
+	"frequency":        func(p *params.Parameters, v float64) { p.Frequency = v },
+	"amplitude":        func(p *params.Parameters, v float64) { p.Amplitude = v },
+	"speed":            func(p *params.Parameters, v float64) { p.Speed = v },
+	"brightness":       func(p *params.Parameters, v float64) { p.Brightness = v },
+	"contrast":         func(p *params.Parameters, v float64) { p.Contrast = v },
+	"saturation":       func(p *params.Parameters, v float64) { p.Saturation = v },
+	"beatsensitivity":  func(p *params.Parameters, v float64) { p.BeatSensitivity = v },
+	"subinfluence":     func(p *params.Parameters, v float64) { p.SubInfluence = v },
+	"bassinfluence":    func(p *params.Parameters, v float64) { p.BassInfluence = v },
+	"lowmidinfluence":  func(p *params.Parameters, v float64) { p.LowMidInfluence = v },
+	"midinfluence":     func(p *params.Parameters, v float64) { p.MidInfluence = v },
+	"highmidinfluence": func(p *params.Parameters, v float64) { p.HighMidInfluence = v },
+	"trebleinfluence":  func(p *params.Parameters, v float64) { p.TrebleInfluence = v },
+}
+
+// commandArgs is the value a remote command came with: OSC arguments or an
+// MQTT payload.
+type commandArgs interface {
+	Float(i int) (float64, bool)
+	String(i int) (string, bool)
+}
+
+// applyCommand runs one remote control command, path being the lower case
+// address below the protocol's prefix (pattern, params/speed, ...).
+func applyCommand(a *app.App, path string, msg commandArgs) error {
+	if name, ok := strings.CutPrefix(path, "params/"); ok {
+		set, known := remoteParams[name]
+		if !known {
+			return fmt.Errorf("unknown param %q", name)
+		}
+		v, ok := msg.Float(0)
+		if !ok {
+			return errors.New("want a number")
+		}
+		p := a.GetParams()
+		set(&p, v)
+		a.SetParams(p)
+		return nil
+	}
+
+	switch path {
+	case "pattern", "palette", "colormode":
+		s, ok := msg.String(0)
+		if !ok {
+			return errors.New("want a string")
+		}
+		renderer := a.GetRenderer()
+		palette, pattern, colorMode := renderer.PaletteName(), renderer.PatternName(), renderer.ColorModeName()
+		switch path {
+		case "pattern":
+			pattern = s
+		case "palette":
+			palette = s
+		default:
+			colorMode = s
+		}
+		renderer.Configure(palette, pattern, colorMode, renderer.ColorOnAudio())
+	case "preset":
+		s, ok := msg.String(0)
+		if !ok {
+			return errors.New("want a string")
+		}
+		return a.LoadPreset(s)
+	case "tap":
+		// buttons send 1 on press and 0 on release; only the press taps
+		if v, ok := msg.Float(0); !ok || v > 0 {
+			a.Tap(time.Now())
+		}
+	case "trigger":
+		s, ok := msg.String(0)
+		if !ok {
+			return errors.New("want a string")
+		}
+		return a.Trigger(s, 0)
+	case "autorandomize":
+		v, ok := msg.Float(0)
+		if !ok {
+			return errors.New("want a number")
+		}
+		a.SetAutoRandomize(v >= 0.5)
+	default:
+		return errors.New("unknown address")
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/app"
+	"github.com/guidoenr/golizer/internal/config"
+	"github.com/guidoenr/golizer/internal/params"
+	"github.com/guidoenr/golizer/internal/sink"
+)
+
+// loadSettings reads the settings file: path when --settings gave one,
+// golizer.yaml next to the saved config otherwise, which may be missing.
+func loadSettings(path string) (*config.Settings, error) {
+	if path != "" {
+		return config.LoadSettings(path, false)
+	}
+	return config.LoadSettings(filepath.Join(filepath.Dir(configPath), config.SettingsName), true)
+}
+
+// applyBands lays the settings' bands over the saved or default envelopes
+// and noise floors; floor is the single --noise-floor the bands start from
+// when there are no per-band floors yet.
+func applyBands(bands map[string]config.Band, envelopes *analyzer.Envelopes, floors *analyzer.NoiseFloors, floor float64) error {
+	if len(bands) == 0 {
+		return nil
+	}
+	if envelopes.IsZero() {
+		*envelopes = analyzer.DefaultEnvelopes()
+	}
+	for name, band := range bands {
+		env, floorOf, ok := bandFields(envelopes, floors, name)
+		if !ok {
+			return fmt.Errorf("bands: unknown band %q (sub, bass, lowMid, highMid, mid, treble)", name)
+		}
+		if band.Attack != nil {
+			env.AttackMs = *band.Attack
+		}
+		if band.Release != nil {
+			env.ReleaseMs = *band.Release
+		}
+		if err := envelopes.Set(name, *env); err != nil {
+			return fmt.Errorf("bands: %w", err)
+		}
+		if band.NoiseFloor != nil {
+			if floors.IsZero() {
+				*floors = analyzer.UniformFloors(floor)
+			}
+			*floorOf = clampFloat(*band.NoiseFloor, 0, 0.5)
+		}
+	}
+	return nil
+}
+
+// bandFields returns the envelope and noise floor of the band name.
+func bandFields(envelopes *analyzer.Envelopes, floors *analyzer.NoiseFloors, name string) (*analyzer.Envelope, *float64, bool) {
+	switch name {
+	case "sub":
+		return &envelopes.Sub, &floors.Sub, true
+	case "bass":
+		return &envelopes.Bass, &floors.Bass, true
+	case "lowMid":
+		return &envelopes.LowMid, &floors.LowMid, true
+	case "highMid":
+		return &envelopes.HighMid, &floors.HighMid, true
+	case "mid":
+		return &envelopes.Mid, &floors.Mid, true
+	case "treble":
+		return &envelopes.Treble, &floors.Treble, true
+	}
+	return nil, nil, false
+}
+
+// settingsPresets turns the settings' presets into the app's, each knob
+// starting from its default.
+func settingsPresets(presets []config.Preset) ([]app.Preset, error) {
+	out := make([]app.Preset, 0, len(presets))
+	for _, p := range presets {
+		values := params.Defaults()
+		v := reflect.ValueOf(&values).Elem()
+		for name, value := range p.Params {
+			field, ok := paramField(name)
+			if !ok {
+				return nil, fmt.Errorf("preset %q: unknown param %q", p.Name, name)
+			}
+			if err := params.Check(field, value); err != nil {
+				return nil, fmt.Errorf("preset %q: %w", p.Name, err)
+			}
+			v.FieldByName(field).SetFloat(value)
+		}
+		out = append(out, app.Preset{
+			Name:      p.Name,
+			Params:    values,
+			Palette:   p.Palette,
+			Pattern:   p.Pattern,
+			ColorMode: p.ColorMode,
+		})
+	}
+	return out, nil
+}
+
+// paramField finds the panel knob called name, ignoring case.
+func paramField(name string) (string, bool) {
+	for field := range params.Ranges() {
+		if strings.EqualFold(field, name) {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// settingsOutputs turns the settings' outputs into sink configs.
+func settingsOutputs(outputs []config.Output) []sink.Config {
+	out := make([]sink.Config, 0, len(outputs))
+	for _, o := range outputs {
+		out = append(out, sink.Config{Name: o.Name, Options: o.Options})
+	}
+	return out
+}
+
+// settingsWeights turns the settings' randomizer section into the app's.
+func settingsWeights(w config.Weights) app.RandomWeights {
+	return app.RandomWeights{Patterns: w.Patterns, Palettes: w.Palettes, ColorModes: w.ColorModes}
+}
@@ -7,8 +7,6 @@ APT_PACKAGES=(
 	portaudio19-dev      # audio capture (PortAudio)
 	libsdl2-2.0-0        # SDL2 runtime (for SDL backend)
 	libsdl2-dev          # SDL2 development headers (for building)
-	avahi-daemon         # mDNS/Bonjour for golizer.local access
-	avahi-utils          # avahi utilities
 	build-essential      # gcc, make, etc. (for building)
 	pkg-config           # pkg-config (for finding libraries)
 )
@@ -27,21 +25,6 @@ echo "    packages: ${APT_PACKAGES[*]}"
 "${SUDO}" apt-get update
 "${SUDO}" apt-get install -y "${APT_PACKAGES[@]}"
 
-# enable and start avahi-daemon for mDNS
-if "${SUDO}" systemctl is-enabled avahi-daemon >/dev/null 2>&1; then
-	echo "==> avahi-daemon already enabled"
-else
-	echo "==> Enabling avahi-daemon for mDNS support"
-	"${SUDO}" systemctl enable avahi-daemon
-fi
-
-if "${SUDO}" systemctl is-active avahi-daemon >/dev/null 2>&1; then
-	echo "==> avahi-daemon already running"
-else
-	echo "==> Starting avahi-daemon"
-	"${SUDO}" systemctl start avahi-daemon
-fi
-
 echo ""
 echo "✓ All dependencies installed!"
 echo ""
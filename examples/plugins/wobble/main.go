@@ -0,0 +1,33 @@
+// Command wobble is an example pattern plugin. It only imports the public
+// pattern package, so it builds from a module of its own as well as from the
+// repository root, with the same Go toolchain as the visualizer:
+//
+//	go build -buildmode=plugin -o plugins/wobble.so ./examples/plugins/wobble
+//
+// and start golizer with --pattern wobble.
+package main
+
+import (
+	"math"
+
+	"github.com/guidoenr/golizer/pattern"
+)
+
+// DetailMix is how much fine noise the renderer mixes in, 0-1.
+var DetailMix = 0.1
+
+// Pattern draws a ring that wobbles with the beat. Like the built-in
+// patterns it returns the intensity at x, y (centred on 0); negative values
+// leave the cell black.
+func Pattern(x, y float64, p pattern.Params, t float64) float64 {
+	angle := math.Atan2(y, x)
+	radius := 0.45 + 0.08*math.Sin(angle*5+t*2)*(1+p.BeatDistortion)
+	dist := math.Abs(math.Sqrt(x*x+y*y) - radius)
+	if dist > 0.06 {
+		return -1.0
+	}
+	return (0.06 - dist) * 16.0 * (0.4 + p.Amplitude)
+}
+
+// main is never called; package main is required for -buildmode=plugin.
+func main() {}
@@ -8,9 +8,22 @@ require (
 	github.com/eiannone/keyboard v0.0.0-20220611211555-0d226195f203
 	github.com/gordonklaus/portaudio v0.0.0-20250206071425-98a94950218b
 	github.com/gorilla/websocket v1.5.3
-	github.com/mjibson/go-dsp v0.0.0-20180508042940-11479a337f12
 	github.com/veandco/go-sdl2 v0.4.40
+	golang.org/x/sys v0.38.0
 	golang.org/x/term v0.37.0
 )
 
-require golang.org/x/sys v0.38.0 // indirect
+require (
+	github.com/mattn/go-runewidth v0.0.16
+	github.com/yuin/gopher-lua v1.1.1
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250804133106-a7a43d27e69b
+	google.golang.org/grpc v1.76.0
+	google.golang.org/protobuf v1.36.10
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/rivo/uniseg v0.2.0 // indirect
+	golang.org/x/net v0.42.0 // indirect
+	golang.org/x/text v0.27.0 // indirect
+)
@@ -2,15 +2,16 @@ package analyzer
 
 import (
 	"math"
-
-	"github.com/mjibson/go-dsp/fft"
 )
 
 // Analyzer performs FFT-based spectral analysis to extract audio-reactive features.
 type Analyzer struct {
 	sampleRate float64
 
+	subPeak      float64
 	bassPeak     float64
+	lowMidPeak   float64
+	highMidPeak  float64
 	midPeak      float64
 	treblePeak   float64
 	beatPulse    float64
@@ -18,17 +19,54 @@ type Analyzer struct {
 	bassHistory  []float64
 	energyHist   []float64
 	dropCooldown float64
+	tempo        *tempoTracker
+	envelopes    Envelopes
+	hpss         *hpss
+	spectrum     spectrumGain
+	scope        waveformScope
+	stereo       goniometer
+	harmonicPeak float64
+	percPeak     float64
 
 	historySize int
 
-	buffer []complex128
+	frame  []float64
 	window []float64
+	rfft   *realFFT
+	cqt    *cqtBank
+	probe  *goertzelBank
 }
 
+// Analysis modes selectable through Config.Mode.
+const (
+	// ModeFFT runs a single linear FFT over at most 2048 samples.
+	ModeFFT = "fft"
+	// ModeCQT runs a constant-Q transform with log-spaced bins; low bins use
+	// long windows so kicks on slower material stay separated.
+	ModeCQT = "cqt"
+	// ModeGoertzel probes a handful of frequencies per band with Goertzel
+	// filters instead of a full FFT; the eco-quality fast path.
+	ModeGoertzel = "goertzel"
+)
+
 // Config controls Analyzer behavior.
 type Config struct {
 	SampleRate  float64
 	HistorySize int
+	Mode        string
+	// Envelopes sets per-band attack/release; zero means DefaultEnvelopes.
+	Envelopes Envelopes
+}
+
+// bandLevels is the raw (pre-dynamics) energy of each analysis band.
+type bandLevels struct {
+	low     float64
+	sub     float64
+	bass    float64
+	lowMid  float64
+	highMid float64
+	mid     float64
+	treble  float64
 }
 
 // New creates an Analyzer with sensible defaults mirroring the Rust implementation.
@@ -39,91 +77,208 @@ func New(cfg Config) *Analyzer {
 	if cfg.HistorySize <= 0 {
 		cfg.HistorySize = 60
 	}
-	return &Analyzer{
+	if cfg.Envelopes.IsZero() {
+		cfg.Envelopes = DefaultEnvelopes()
+	}
+	a := &Analyzer{
 		sampleRate:  cfg.SampleRate,
 		bassHistory: make([]float64, 0, cfg.HistorySize/2),
 		energyHist:  make([]float64, 0, cfg.HistorySize),
 		historySize: cfg.HistorySize,
+		tempo:       newTempoTracker(),
+		envelopes:   cfg.Envelopes,
+		hpss:        newHPSS(),
 	}
-}
-
-// Analyze returns audio features for the provided mono samples and frame delta.
-func (a *Analyzer) Analyze(samples []float32, deltaTime float64) Features {
-	if len(samples) == 0 {
-		return Features{}
-	}
-
-	size := nextPow2(min(len(samples), 2048))
-	if size < 256 {
-		size = 256
+	switch cfg.Mode {
+	case ModeCQT:
+		a.cqt = &cqtBank{}
+	case ModeGoertzel:
+		a.probe = &goertzelBank{}
 	}
+	return a
+}
 
-	a.ensureWorkspace(size)
+// Harmonic energy drives slow colour drift, so its peak lets go slowly;
+// percussive energy releases like the bass band.
+var (
+	harmonicEnvelope   = Envelope{AttackMs: 270, ReleaseMs: 400}
+	percussiveEnvelope = Envelope{AttackMs: 270, ReleaseMs: 58}
+)
 
-	buffer := a.buffer[:size]
-	window := a.window[:size]
+// SetEnvelopes replaces the per-band attack/release times. Like Analyze it
+// must not be called concurrently.
+func (a *Analyzer) SetEnvelopes(e Envelopes) {
+	a.envelopes = e
+}
 
-	sampleCount := len(samples)
-	for i := 0; i < size; i++ {
-		if i < sampleCount {
-			buffer[i] = complex(float64(samples[i])*window[i], 0)
-			continue
-		}
-		buffer[i] = 0
-	}
+// SampleRate returns the rate the analyzer was configured for.
+func (a *Analyzer) SampleRate() float64 {
+	return a.sampleRate
+}
 
-	fftRes := fft.FFT(buffer)
+// WantsFullBuffer reports whether callers should pass the whole capture
+// buffer instead of trimming it to a short low-latency window.
+func (a *Analyzer) WantsFullBuffer() bool {
+	return a.cqt != nil
+}
 
-	freqResolution := a.sampleRate / float64(size)
-	bass := a.bandEnergy(fftRes, freqResolution, 20, 250)
-	mid := a.bandEnergy(fftRes, freqResolution, 250, 2000)
-	treble := a.bandEnergy(fftRes, freqResolution, 2000, 8000)
+// Analyze returns audio features for the provided mono samples and frame delta.
+func (a *Analyzer) Analyze(samples []float32, deltaTime float64) Features {
+	return a.AnalyzeStereo(samples, nil, deltaTime)
+}
 
-	a.bassPeak = envelope(a.bassPeak, bass, 0.94, 0.75)
-	a.midPeak = envelope(a.midPeak, mid, 0.94, 0.78)
-	a.treblePeak = envelope(a.treblePeak, treble, 0.94, 0.8)
+// AnalyzeStereo is Analyze for stereo input: samples is the mono mix and
+// side (left-right)/2 of the same frames, for the goniometer. An empty side
+// is mono input.
+func (a *Analyzer) AnalyzeStereo(samples, side []float32, deltaTime float64) Features {
+	if len(samples) == 0 {
+		return Features{}
+	}
 
+	var levels bandLevels
+	switch {
+	case a.cqt != nil:
+		levels = a.cqt.analyze(samples, a.sampleRate)
+		a.hpss.fillFromCQT(a.cqt)
+	case a.probe != nil:
+		levels = a.probe.analyze(samples, a.sampleRate)
+		a.hpss.fillFromProbes(goertzelFreqs, a.probe.mags)
+	default:
+		levels = a.fftBands(samples)
+	}
+	low, sub, bass := levels.low, levels.sub, levels.bass
+	lowMid, highMid, mid, treble := levels.lowMid, levels.highMid, levels.mid, levels.treble
+
+	step := deltaTime
+	if step <= 0 {
+		step = 1.0 / 60
+	}
+	env := a.envelopes
+	a.subPeak = env.Sub.follow(a.subPeak, sub, step)
+	a.bassPeak = env.Bass.follow(a.bassPeak, bass, step)
+	a.lowMidPeak = env.LowMid.follow(a.lowMidPeak, lowMid, step)
+	a.highMidPeak = env.HighMid.follow(a.highMidPeak, highMid, step)
+	a.midPeak = env.Mid.follow(a.midPeak, mid, step)
+	a.treblePeak = env.Treble.follow(a.treblePeak, treble, step)
+
+	subOut := dynamics(sub, a.subPeak)
 	bassOut := dynamics(bass, a.bassPeak)
+	lowMidOut := dynamics(lowMid, a.lowMidPeak)
+	highMidOut := dynamics(highMid, a.highMidPeak)
 	midOut := dynamics(mid, a.midPeak)
 	trebleOut := dynamics(treble, a.treblePeak)
 
-	overall := (bassOut + midOut + trebleOut) / 3.0
+	spectrum := a.spectrum.levels(a.hpss.spectrum, step)
+	harmonic, percussive := a.hpss.separate()
+	a.harmonicPeak = harmonicEnvelope.follow(a.harmonicPeak, harmonic, step)
+	a.percPeak = percussiveEnvelope.follow(a.percPeak, percussive, step)
+	harmonicOut := dynamics(harmonic, a.harmonicPeak)
+	percussiveOut := dynamics(percussive, a.percPeak)
+
+	overall := (math.Max(subOut, bassOut) + midOut + trebleOut) / 3.0
 	a.pushEnergy(overall)
 
 	energyVariance := a.energyVariance()
 
-	bassDiff := bass - a.lastBass
+	bassDiff := low - a.lastBass
 	beatStrength := clamp((bassDiff * 14.0), 0, 1)
 
-	if beatStrength > 0.12 {
+	onset := beatStrength > 0.12
+	if onset {
 		a.beatPulse = 1.0
 	}
+	onset = a.tempo.advance(deltaTime, onset)
 	// slower decay so beat pulse lasts longer
 	a.beatPulse *= 0.88
 	beatStrength = math.Min(1.0, beatStrength+a.beatPulse*0.7)
 
-	a.pushBass(bass)
+	a.pushBass(low)
 	isDrop := false
 	if a.dropCooldown <= 0 {
 		avg := average(a.bassHistory)
-		if avg > 0 && bass > avg*2.0 && bassDiff > 0.1 {
+		if avg > 0 && low > avg*2.0 && bassDiff > 0.1 {
 			isDrop = true
 			a.dropCooldown = 1.0
 		}
 	} else {
 		a.dropCooldown -= deltaTime
 	}
-	a.lastBass = bass
+	a.lastBass = low
 
 	varianceMultiplier := 1.0 + energyVariance*0.65
+	level, peak := inputLevel(samples)
+	goniometer, correlation := a.stereo.plot(samples, side, step)
 
 	return Features{
-		Bass:         math.Min(1.0, bassOut*varianceMultiplier),
-		Mid:          math.Min(1.0, midOut*varianceMultiplier),
-		Treble:       math.Min(1.0, trebleOut*varianceMultiplier),
-		Overall:      math.Min(1.0, overall*varianceMultiplier),
-		BeatStrength: beatStrength,
-		IsDrop:       isDrop,
+		Sub:             math.Min(1.0, subOut*varianceMultiplier),
+		Bass:            math.Min(1.0, bassOut*varianceMultiplier),
+		LowMid:          math.Min(1.0, lowMidOut*varianceMultiplier),
+		HighMid:         math.Min(1.0, highMidOut*varianceMultiplier),
+		Mid:             math.Min(1.0, midOut*varianceMultiplier),
+		Treble:          math.Min(1.0, trebleOut*varianceMultiplier),
+		Overall:         math.Min(1.0, overall*varianceMultiplier),
+		Harmonic:        math.Min(1.0, harmonicOut),
+		Percussive:      math.Min(1.0, percussiveOut*varianceMultiplier),
+		BeatStrength:    beatStrength,
+		IsDrop:          isDrop,
+		Onset:           onset,
+		Tempo:           a.tempo.bpm,
+		TempoConfidence: a.tempo.confidence,
+		Spectrum:        spectrum,
+		Waveform:        a.scope.trace(samples, step),
+		Level:           level,
+		Peak:            peak,
+		Goniometer:      goniometer,
+		Correlation:     correlation,
+	}
+}
+
+// inputLevel returns the RMS and the peak magnitude of samples.
+func inputLevel(samples []float32) (rms, peak float64) {
+	sum := 0.0
+	for _, s := range samples {
+		v := float64(s)
+		sum += v * v
+		peak = math.Max(peak, math.Abs(v))
+	}
+	return math.Min(math.Sqrt(sum/float64(len(samples))), 1), math.Min(peak, 1)
+}
+
+// fftBands measures band energy with a linear FFT over the newest samples.
+func (a *Analyzer) fftBands(samples []float32) bandLevels {
+	size := nextPow2(min(len(samples), 2048))
+	if size < 256 {
+		size = 256
+	}
+
+	a.ensureWorkspace(size)
+
+	frame := a.frame[:size]
+	window := a.window[:size]
+
+	sampleCount := len(samples)
+	for i := 0; i < size; i++ {
+		if i < sampleCount {
+			frame[i] = float64(samples[i]) * window[i]
+			continue
+		}
+		frame[i] = 0
+	}
+
+	fftRes := a.rfft.transform(frame)
+
+	freqResolution := a.sampleRate / float64(size)
+	a.hpss.fillFromFFT(fftRes, freqResolution)
+	return bandLevels{
+		// low spans sub+bass and keeps driving beat/drop detection so kicks
+		// register no matter which side of 60 Hz their fundamental sits on
+		low:     a.bandEnergy(fftRes, freqResolution, 20, 250),
+		sub:     a.bandEnergy(fftRes, freqResolution, 20, 60),
+		bass:    a.bandEnergy(fftRes, freqResolution, 60, 250),
+		lowMid:  a.bandEnergy(fftRes, freqResolution, 250, 800),
+		highMid: a.bandEnergy(fftRes, freqResolution, 800, 2000),
+		mid:     a.bandEnergy(fftRes, freqResolution, 250, 2000),
+		treble:  a.bandEnergy(fftRes, freqResolution, 2000, 8000),
 	}
 }
 
@@ -133,8 +288,8 @@ func (a *Analyzer) bandEnergy(buffer []complex128, resolution float64, minHz, ma
 	}
 	lo := int(math.Floor(minHz / resolution))
 	hi := int(math.Ceil(maxHz/resolution)) + 1
-	if hi > len(buffer)/2 {
-		hi = len(buffer) / 2
+	if hi > len(buffer) {
+		hi = len(buffer)
 	}
 	if lo >= hi {
 		return 0
@@ -185,8 +340,9 @@ func hann(i, size float64) float64 {
 }
 
 func (a *Analyzer) ensureWorkspace(size int) {
-	if len(a.buffer) != size {
-		a.buffer = make([]complex128, size)
+	if len(a.frame) != size {
+		a.frame = make([]float64, size)
+		a.rfft = newRealFFT(size)
 	}
 	if len(a.window) != size {
 		a.window = make([]float64, size)
@@ -3,6 +3,7 @@ package analyzer
 import (
 	"math"
 	"testing"
+	"time"
 )
 
 func TestAverage(t *testing.T) {
@@ -48,3 +49,306 @@ func TestClamp(t *testing.T) {
 		t.Fatalf("expected clamp middle to be unchanged")
 	}
 }
+
+func TestTempoTrackerLocksOnSteadyOnsets(t *testing.T) {
+	tr := newTempoTracker()
+	const frame = 1.0 / 60.0
+	period := 0.5 // 120 BPM
+	next := 0.0
+	for clock := 0.0; clock < 8; clock += frame {
+		onset := clock >= next
+		if onset {
+			next += period
+		}
+		tr.advance(frame, onset)
+	}
+	if math.Abs(tr.bpm-120) > 3 {
+		t.Fatalf("bpm=%f want~120", tr.bpm)
+	}
+	if tr.confidence < 0.8 {
+		t.Fatalf("confidence=%f want>=0.8", tr.confidence)
+	}
+}
+
+func TestFoldIntervalIntoRange(t *testing.T) {
+	if got := foldInterval(0.25); math.Abs(got-0.5) > 1e-9 {
+		t.Fatalf("foldInterval(0.25)=%f want=0.5", got)
+	}
+	if got := foldInterval(2.0); math.Abs(got-1.0) > 1e-9 {
+		t.Fatalf("foldInterval(2.0)=%f want=1.0", got)
+	}
+}
+
+func TestCalibratedFloorsGatePerBand(t *testing.T) {
+	cal := NewCalibrator()
+	for i := 0; i < 120; i++ {
+		cal.Add(Features{Bass: 0.3, Treble: 0.05})
+	}
+	floors := cal.Floors()
+	if floors.Bass < 0.3 || floors.Treble > 0.1 {
+		t.Fatalf("unexpected floors: %+v", floors)
+	}
+
+	gated := GateFeatures(Features{Bass: 0.3, Treble: 0.3}, floors)
+	if gated.Bass != 0 {
+		t.Fatalf("bass=%f want gated to 0", gated.Bass)
+	}
+	if gated.Treble <= 0 {
+		t.Fatalf("treble above its floor should pass, got %f", gated.Treble)
+	}
+}
+
+func TestGateHysteresisHoldsOpenNearFloor(t *testing.T) {
+	g := NewGate(0.05, 100*time.Millisecond)
+	floors := UniformFloors(0.2)
+	const frame = 1.0 / 60.0
+
+	if out := g.Apply(Features{Bass: 0.19}, floors, frame); out.Bass != 0 {
+		t.Fatalf("closed gate should block below floor, got %f", out.Bass)
+	}
+	if out := g.Apply(Features{Bass: 0.25}, floors, frame); out.Bass <= 0 {
+		t.Fatalf("gate should open above floor")
+	}
+	// dipping just under the floor but above floor-hysteresis stays open
+	if out := g.Apply(Features{Bass: 0.18}, floors, frame); out.Bass <= 0 {
+		t.Fatalf("gate closed inside hysteresis band")
+	}
+	// below the close level it fades over the hold time, then shuts
+	held := g.Apply(Features{Bass: 0.1}, floors, frame).Bass
+	if held <= 0 {
+		t.Fatalf("expected hold to keep a fading value")
+	}
+	for i := 0; i < 10; i++ {
+		held = g.Apply(Features{Bass: 0.1}, floors, frame).Bass
+	}
+	if held != 0 {
+		t.Fatalf("gate should be closed after hold, got %f", held)
+	}
+}
+
+func TestCQTSeparatesSubFromBass(t *testing.T) {
+	const rate = 48000.0
+	samples := make([]float32, 8192)
+	for i := range samples {
+		samples[i] = float32(0.5 * math.Sin(2*math.Pi*45*float64(i)/rate))
+	}
+	var bank cqtBank
+	levels := bank.analyze(samples, rate)
+	if levels.sub <= levels.bass || levels.sub <= levels.treble {
+		t.Fatalf("expected 45 Hz to land in sub, got sub %.3f bass %.3f treble %.3f", levels.sub, levels.bass, levels.treble)
+	}
+}
+
+func TestDefaultEnvelopesMatchFrameConstantsAt60FPS(t *testing.T) {
+	env := DefaultEnvelopes()
+	cases := []struct {
+		ms   float64
+		want float64
+	}{
+		{env.Bass.AttackMs, 0.94},
+		{env.Sub.ReleaseMs, 0.72},
+		{env.Bass.ReleaseMs, 0.75},
+		{env.Treble.ReleaseMs, 0.8},
+	}
+	for _, c := range cases {
+		if got := coefficient(c.ms, 1.0/60); math.Abs(got-c.want) > 0.005 {
+			t.Fatalf("%.0fms: expected coefficient %.2f, got %.4f", c.ms, c.want, got)
+		}
+	}
+
+	var custom Envelopes
+	if err := custom.Set("lowMid", Envelope{AttackMs: -5, ReleaseMs: 9000}); err != nil {
+		t.Fatal(err)
+	}
+	if custom.LowMid.AttackMs != 0 || custom.LowMid.ReleaseMs != MaxEnvelopeMs {
+		t.Fatalf("expected clamped envelope, got %+v", custom.LowMid)
+	}
+	if err := custom.Set("bogus", Envelope{}); err == nil {
+		t.Fatal("expected error for unknown band")
+	}
+}
+
+func TestHPSSSplitsSustainedToneFromClick(t *testing.T) {
+	h := newHPSS()
+	// a sustained tone: one band steady across frames
+	var harmonic, percussive float64
+	for i := 0; i < hpssTimeFrames; i++ {
+		for k := range h.spectrum {
+			h.spectrum[k] = 0
+		}
+		h.spectrum[20] = 1
+		harmonic, percussive = h.separate()
+	}
+	if harmonic <= percussive {
+		t.Fatalf("expected tone to be harmonic, got harmonic %.3f percussive %.3f", harmonic, percussive)
+	}
+
+	// a click: every band at once, for a single frame
+	for k := range h.spectrum {
+		h.spectrum[k] = 1
+	}
+	harmonic, percussive = h.separate()
+	if percussive <= harmonic {
+		t.Fatalf("expected click to be percussive, got harmonic %.3f percussive %.3f", harmonic, percussive)
+	}
+}
+
+func TestHistoryReturnsNewestFramesOldestFirst(t *testing.T) {
+	h := NewHistory(4)
+	if got := h.Last(3, nil); len(got) != 0 {
+		t.Fatalf("empty history returned %d frames", len(got))
+	}
+	for i := 1; i <= 6; i++ {
+		h.Push(Features{Overall: float64(i)})
+	}
+	if h.Len() != 4 {
+		t.Fatalf("expected 4 stored frames, got %d", h.Len())
+	}
+
+	got := h.Last(3, nil)
+	want := []float64{4, 5, 6}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d frames, got %d", len(want), len(got))
+	}
+	for i, f := range got {
+		if f.Overall != want[i] {
+			t.Errorf("frame %d = %.0f, want %.0f", i, f.Overall, want[i])
+		}
+	}
+	if all := h.Last(0, got); len(all) != 4 || all[0].Overall != 3 {
+		t.Errorf("Last(0) = %v, want the 4 newest frames starting at 3", all)
+	}
+}
+
+func TestRealFFTMatchesNaiveDFT(t *testing.T) {
+	const n = 64
+	in := make([]float64, n)
+	for i := range in {
+		in[i] = math.Sin(float64(i)*0.7) + 0.3*math.Cos(float64(i)*2.1) + float64(i%5)*0.1
+	}
+	got := newRealFFT(n).transform(in)
+	for k := 0; k < n/2; k++ {
+		var want complex128
+		for i, x := range in {
+			s, c := math.Sincos(-2 * math.Pi * float64(k*i) / n)
+			want += complex(x*c, x*s)
+		}
+		if cmag(got[k]-want) > 1e-9 {
+			t.Fatalf("bin %d = %v, want %v", k, got[k], want)
+		}
+	}
+}
+
+func TestAnalyzeDoesNotAllocate(t *testing.T) {
+	a := New(Config{SampleRate: 48000})
+	samples := make([]float32, 2048)
+	for i := range samples {
+		samples[i] = float32(math.Sin(2 * math.Pi * 110 * float64(i) / 48000))
+	}
+	a.Analyze(samples, 1.0/60) // size the workspace
+	if allocs := testing.AllocsPerRun(50, func() { a.Analyze(samples, 1.0/60) }); allocs != 0 {
+		t.Fatalf("Analyze allocated %.0f times per frame", allocs)
+	}
+}
+
+func TestGoertzelTracksFFTBandLevels(t *testing.T) {
+	const rate = 48000.0
+	samples := make([]float32, 2048)
+	for i := range samples {
+		x := float64(i) / rate
+		samples[i] = float32(0.02*math.Sin(2*math.Pi*100*x) + 0.01*math.Sin(2*math.Pi*3500*x))
+	}
+	var bank goertzelBank
+	probe := bank.analyze(samples, rate)
+	full := New(Config{SampleRate: rate}).fftBands(samples)
+
+	if probe.bass <= probe.lowMid || probe.treble <= probe.highMid {
+		t.Fatalf("tones landed in the wrong bands: %+v", probe)
+	}
+	// both paths report the same magnitude scale, so a tone sitting on a
+	// probe reads about as loud as the fft band holding it
+	if ratio := probe.bass / full.bass; ratio < 0.5 || ratio > 4 {
+		t.Fatalf("bass %.4f vs fft %.4f", probe.bass, full.bass)
+	}
+}
+
+func TestSpectrumPeaksAtTone(t *testing.T) {
+	const rate = 48000.0
+	samples := make([]float32, 2048)
+	for i := range samples {
+		samples[i] = float32(0.3 * math.Sin(2*math.Pi*1000*float64(i)/rate))
+	}
+	feat := New(Config{SampleRate: rate}).Analyze(samples, 1.0/60)
+	loudest := 0
+	for k, v := range feat.Spectrum {
+		if v > feat.Spectrum[loudest] {
+			loudest = k
+		}
+	}
+	if lo, hi := SpectrumBandHz(loudest); lo > 1000*1.1 || hi < 1000/1.1 {
+		t.Fatalf("loudest band %d is %.0f-%.0f Hz", loudest, lo, hi)
+	}
+	if feat.Spectrum[loudest] != 1 || feat.Spectrum[0] > 0.1 {
+		t.Fatalf("tone band %.2f, lowest band %.2f", feat.Spectrum[loudest], feat.Spectrum[0])
+	}
+}
+
+func TestWaveformTriggersOnRisingZeroCrossing(t *testing.T) {
+	const rate = 48000.0
+	traces := make([][WaveformSamples]float64, 0, 3)
+	for _, phase := range []float64{0, 1, 4} {
+		samples := make([]float32, 2048)
+		for i := range samples {
+			samples[i] = float32(0.3 * math.Sin(2*math.Pi*440*float64(i)/rate+phase))
+		}
+		traces = append(traces, New(Config{SampleRate: rate}).Analyze(samples, 1.0/60).Waveform)
+	}
+	for n, w := range traces {
+		if w[0] < 0 || w[0] > 0.3 || w[2] <= w[0] {
+			t.Fatalf("trace %d starts at %.2f, %.2f", n, w[0], w[2])
+		}
+		for j := range w {
+			if math.Abs(w[j]-traces[0][j]) > 0.1 {
+				t.Fatalf("trace %d drifts at %d: %.2f vs %.2f", n, j, w[j], traces[0][j])
+			}
+		}
+	}
+}
+
+func TestLevelIsUnnormalisedRMS(t *testing.T) {
+	samples := make([]float32, 1024)
+	for i := range samples {
+		samples[i] = float32(0.5 * math.Sin(2*math.Pi*float64(i)/64))
+	}
+	feat := New(Config{}).Analyze(samples, 1.0/60)
+	if math.Abs(feat.Level-0.5/math.Sqrt2) > 0.01 || math.Abs(feat.Peak-0.5) > 0.01 {
+		t.Fatalf("level %.3f, peak %.3f", feat.Level, feat.Peak)
+	}
+}
+
+func TestGoniometerCorrelation(t *testing.T) {
+	mid := make([]float32, 1024)
+	for i := range mid {
+		mid[i] = float32(0.5 * math.Sin(float64(i)*0.05))
+	}
+	var g goniometer
+	points, corr := g.plot(mid, nil, 1.0/60)
+	if math.Abs(corr-1) > 1e-9 {
+		t.Fatalf("mono correlation %v", corr)
+	}
+	for _, p := range points {
+		if p[0] != 0 || math.Abs(p[1]) > 1 {
+			t.Fatalf("mono point %v off the vertical", p)
+		}
+	}
+
+	// left and right out of phase: all side, no mid
+	side := mid
+	silent := make([]float32, len(mid))
+	if _, corr := g.plot(silent, side, 1.0/60); math.Abs(corr+1) > 1e-9 {
+		t.Fatalf("out of phase correlation %v", corr)
+	}
+	if _, corr := g.plot(silent, nil, 1.0/60); corr != 0 {
+		t.Fatalf("silence correlation %v", corr)
+	}
+}
@@ -0,0 +1,80 @@
+package analyzer
+
+import "math"
+
+const (
+	// calibrationMargin is added on top of the measured noise so the gate
+	// doesn't flicker right at the ambient level.
+	calibrationMargin  = 0.02
+	maxCalibratedFloor = 0.6
+)
+
+// Calibrator accumulates features captured while the room is quiet and
+// derives per-band noise floors from them.
+type Calibrator struct {
+	count int
+	stats [8]bandStats
+}
+
+type bandStats struct {
+	sum   float64
+	sumSq float64
+	peak  float64
+}
+
+func (b *bandStats) add(v float64) {
+	b.sum += v
+	b.sumSq += v * v
+	if v > b.peak {
+		b.peak = v
+	}
+}
+
+// floor returns mean + 2σ, never above the loudest sample seen.
+func (b *bandStats) floor(count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	n := float64(count)
+	mean := b.sum / n
+	variance := math.Max(b.sumSq/n-mean*mean, 0)
+	level := math.Min(mean+2*math.Sqrt(variance), b.peak)
+	return clampFloat(level+calibrationMargin, 0, maxCalibratedFloor)
+}
+
+// NewCalibrator returns an empty Calibrator.
+func NewCalibrator() *Calibrator {
+	return &Calibrator{}
+}
+
+// Add records one frame of ungated features.
+func (c *Calibrator) Add(f Features) {
+	c.count++
+	c.stats[0].add(f.Sub)
+	c.stats[1].add(f.Bass)
+	c.stats[2].add(f.LowMid)
+	c.stats[3].add(f.HighMid)
+	c.stats[4].add(f.Mid)
+	c.stats[5].add(f.Treble)
+	c.stats[6].add(f.Overall)
+	c.stats[7].add(f.BeatStrength)
+}
+
+// Frames returns how many frames were recorded.
+func (c *Calibrator) Frames() int {
+	return c.count
+}
+
+// Floors returns the measured per-band floors.
+func (c *Calibrator) Floors() NoiseFloors {
+	return NoiseFloors{
+		Sub:     c.stats[0].floor(c.count),
+		Bass:    c.stats[1].floor(c.count),
+		LowMid:  c.stats[2].floor(c.count),
+		HighMid: c.stats[3].floor(c.count),
+		Mid:     c.stats[4].floor(c.count),
+		Treble:  c.stats[5].floor(c.count),
+		Overall: c.stats[6].floor(c.count),
+		Beat:    c.stats[7].floor(c.count),
+	}
+}
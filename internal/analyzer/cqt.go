@@ -0,0 +1,134 @@
+package analyzer
+
+import "math"
+
+const (
+	cqtMinHz         = 20.0
+	cqtMaxHz         = 8000.0
+	cqtBinsPerOctave = 8
+	// cqtMaxWindow caps the lowest bins; at 48 kHz that is ~170 ms, still
+	// enough to resolve 40 Hz from 60 Hz where a 2048 FFT only has 23 Hz bins.
+	cqtMaxWindow = 8192
+	// cqtReference rescales bin magnitudes to what a 2048-point FFT would
+	// report so envelopes and gates behave the same in both modes.
+	cqtReference = 2048.0
+)
+
+// cqtBank is a brute-force constant-Q transform. Every bin correlates the
+// newest N_k samples with a Hann-windowed complex exponential where N_k
+// shrinks with frequency, giving log-spaced bins with constant Q.
+type cqtBank struct {
+	sampleRate float64
+	maxLen     int
+	bins       []cqtBin
+	// mags holds the last magnitude of every bin, in bin order.
+	mags []float64
+}
+
+type cqtBin struct {
+	freq   float64
+	length int
+	cos    []float64
+	sin    []float64
+	scale  float64
+}
+
+func (c *cqtBank) ensure(sampleRate float64, available int) {
+	maxLen := min(available, cqtMaxWindow)
+	if c.sampleRate == sampleRate && c.maxLen == maxLen && len(c.bins) > 0 {
+		return
+	}
+	c.sampleRate = sampleRate
+	c.maxLen = maxLen
+	c.bins = c.bins[:0]
+
+	q := 1.0 / (math.Pow(2, 1.0/cqtBinsPerOctave) - 1)
+	top := math.Min(cqtMaxHz, sampleRate/2)
+	for k := 0; ; k++ {
+		freq := cqtMinHz * math.Pow(2, float64(k)/cqtBinsPerOctave)
+		if freq > top {
+			break
+		}
+		length := int(math.Ceil(q * sampleRate / freq))
+		if length > maxLen {
+			length = maxLen
+		}
+		if length < 16 {
+			length = 16
+		}
+		bin := cqtBin{
+			freq:   freq,
+			length: length,
+			cos:    make([]float64, length),
+			sin:    make([]float64, length),
+			scale:  cqtReference / float64(length),
+		}
+		omega := 2 * math.Pi * freq / sampleRate
+		for n := 0; n < length; n++ {
+			w := hann(float64(n), float64(length))
+			s, co := math.Sincos(omega * float64(n))
+			bin.cos[n] = w * co
+			bin.sin[n] = w * s
+		}
+		c.bins = append(c.bins, bin)
+	}
+	c.mags = make([]float64, len(c.bins))
+}
+
+func (c *cqtBank) analyze(samples []float32, sampleRate float64) bandLevels {
+	c.ensure(sampleRate, len(samples))
+
+	var sums, counts [7]float64
+	end := len(samples)
+	for i := range c.bins {
+		bin := &c.bins[i]
+		start := end - bin.length
+		c.mags[i] = 0
+		if start < 0 {
+			continue
+		}
+		var re, im float64
+		window := samples[start:end]
+		for n, v := range window {
+			x := float64(v)
+			re += x * bin.cos[n]
+			im += x * bin.sin[n]
+		}
+		mag := math.Sqrt(re*re+im*im) * bin.scale
+		c.mags[i] = mag
+
+		for band, r := range bandRanges {
+			if bin.freq >= r[0] && bin.freq < r[1] {
+				sums[band] += mag
+				counts[band]++
+			}
+		}
+	}
+
+	var out [7]float64
+	for band := range out {
+		if counts[band] > 0 {
+			out[band] = math.Min(1.0, sums[band]/counts[band])
+		}
+	}
+	return bandLevels{
+		low:     out[0],
+		sub:     out[1],
+		bass:    out[2],
+		lowMid:  out[3],
+		highMid: out[4],
+		mid:     out[5],
+		treble:  out[6],
+	}
+}
+
+// bandRanges mirrors the Hz limits fftBands uses, in bandLevels order.
+var bandRanges = [7][2]float64{
+	{20, 250},
+	{20, 60},
+	{60, 250},
+	{250, 800},
+	{800, 2000},
+	{250, 2000},
+	{2000, 8000},
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+)
+
+// MaxEnvelopeMs caps attack and release times.
+const MaxEnvelopeMs = 5000.0
+
+// Envelope sets how fast a band's peak tracker follows the signal: attack
+// when the level rises, release when it falls. Longer times give smoother,
+// lazier visuals; 0 follows instantly.
+type Envelope struct {
+	AttackMs  float64 `json:"attackMs"`
+	ReleaseMs float64 `json:"releaseMs"`
+}
+
+// Envelopes holds one Envelope per band.
+type Envelopes struct {
+	Sub     Envelope `json:"sub"`
+	Bass    Envelope `json:"bass"`
+	LowMid  Envelope `json:"lowMid"`
+	HighMid Envelope `json:"highMid"`
+	Mid     Envelope `json:"mid"`
+	Treble  Envelope `json:"treble"`
+}
+
+// DefaultEnvelopes matches the original per-frame constants (0.94 attack,
+// 0.72-0.8 release) at 60 fps.
+func DefaultEnvelopes() Envelopes {
+	return Envelopes{
+		Sub:     Envelope{AttackMs: 270, ReleaseMs: 51},
+		Bass:    Envelope{AttackMs: 270, ReleaseMs: 58},
+		LowMid:  Envelope{AttackMs: 270, ReleaseMs: 64},
+		HighMid: Envelope{AttackMs: 270, ReleaseMs: 71},
+		Mid:     Envelope{AttackMs: 270, ReleaseMs: 67},
+		Treble:  Envelope{AttackMs: 270, ReleaseMs: 75},
+	}
+}
+
+// IsZero reports whether no envelope was configured.
+func (e Envelopes) IsZero() bool {
+	return e == Envelopes{}
+}
+
+// Set updates the envelope of one band by its JSON name (sub, bass, lowMid,
+// highMid, mid, treble). Times are clamped to [0, MaxEnvelopeMs].
+func (e *Envelopes) Set(band string, env Envelope) error {
+	env.AttackMs = clamp(env.AttackMs, 0, MaxEnvelopeMs)
+	env.ReleaseMs = clamp(env.ReleaseMs, 0, MaxEnvelopeMs)
+	switch band {
+	case "sub":
+		e.Sub = env
+	case "bass":
+		e.Bass = env
+	case "lowMid":
+		e.LowMid = env
+	case "highMid":
+		e.HighMid = env
+	case "mid":
+		e.Mid = env
+	case "treble":
+		e.Treble = env
+	default:
+		return fmt.Errorf("unknown band %q", band)
+	}
+	return nil
+}
+
+// coefficient converts a time constant into a per-frame smoothing factor.
+func coefficient(ms, delta float64) float64 {
+	if ms <= 0 {
+		return 0
+	}
+	return math.Exp(-delta * 1000 / ms)
+}
+
+// follow moves a peak tracker towards input using env over delta seconds.
+func (env Envelope) follow(current, input, delta float64) float64 {
+	return envelope(current, input, coefficient(env.AttackMs, delta), coefficient(env.ReleaseMs, delta))
+}
@@ -1,42 +1,116 @@
 package analyzer
 
 // Features describes spectral energy distribution and rhythmic cues extracted from audio.
+// Bands follow a five-band model: Sub (<60 Hz), Bass (60-250 Hz), LowMid
+// (250-800 Hz), HighMid (800-2000 Hz) and Treble (2-8 kHz). Mid covers the
+// combined 250-2000 Hz range for callers that don't care about the split.
 type Features struct {
-	Bass         float64
-	Mid          float64
-	Treble       float64
-	Overall      float64
+	Sub     float64
+	Bass    float64
+	LowMid  float64
+	HighMid float64
+	Mid     float64
+	Treble  float64
+	Overall float64
+	// Harmonic is energy that is steady over time (pads, chords, vocals) and
+	// Percussive energy that is spread across frequency (drums, clicks),
+	// split by median-filter HPSS.
+	Harmonic     float64
+	Percussive   float64
 	BeatStrength float64
 	IsDrop       bool
+	// Onset marks the frame where a new beat was detected; Tempo is the
+	// estimated BPM (0 until enough onsets were seen) and TempoConfidence how
+	// consistently recent onsets agree with it (0-1).
+	Onset           bool
+	Tempo           float64
+	TempoConfidence float64
+	// Spectrum is the level of SpectrumBands log-spaced bands, lowest
+	// first, each 0-1 against its own recent peak.
+	Spectrum [SpectrumBands]float64
+	// Waveform is the newest stretch of input, downsampled to
+	// WaveformSamples points from -1 to 1 and started on a rising zero
+	// crossing so a steady tone holds still.
+	Waveform [WaveformSamples]float64
+	// Level is the RMS and Peak the largest magnitude of the input, as a
+	// share of full scale and without any normalising, for meters.
+	Level float64
+	Peak  float64
+	// Goniometer is the newest stretch of input as GoniometerPoints
+	// (side, mid) pairs from -1 to 1, and Correlation how alike left and
+	// right are, from -1 (out of phase) to 1 (mono). Mono input plots a
+	// vertical line.
+	Goniometer  [GoniometerPoints][2]float64
+	Correlation float64
 }
 
-// GateFeatures applies a simple noise floor so weak signals are ignored.
-func GateFeatures(f Features, floor float64) Features {
-	if floor <= 0 {
-		return f
-	}
-	gate := func(v float64) float64 {
-		if v <= floor {
-			return 0
-		}
-		return clampFloat((v-floor)/(1.0-floor), 0, 1)
+// IsSilent reports whether no band carries energy, ignoring tempo state that
+// persists across breaks.
+func (f Features) IsSilent() bool {
+	return f.Sub == 0 && f.Bass == 0 && f.LowMid == 0 && f.HighMid == 0 &&
+		f.Mid == 0 && f.Treble == 0 && f.Overall == 0 && f.BeatStrength == 0 && !f.IsDrop
+}
+
+// NoiseFloors holds a gate level per band. A zero value disables gating for
+// that band; see Calibrator for measuring them from room noise.
+type NoiseFloors struct {
+	Sub     float64 `json:"sub"`
+	Bass    float64 `json:"bass"`
+	LowMid  float64 `json:"lowMid"`
+	HighMid float64 `json:"highMid"`
+	Mid     float64 `json:"mid"`
+	Treble  float64 `json:"treble"`
+	Overall float64 `json:"overall"`
+	Beat    float64 `json:"beat"`
+}
+
+// UniformFloors applies the same floor to every band, matching the single
+// --noise-floor knob.
+func UniformFloors(floor float64) NoiseFloors {
+	return NoiseFloors{
+		Sub: floor, Bass: floor, LowMid: floor, HighMid: floor,
+		Mid: floor, Treble: floor, Overall: floor, Beat: floor,
 	}
+}
 
-	f.Bass = gate(f.Bass)
-	f.Mid = gate(f.Mid)
-	f.Treble = gate(f.Treble)
-	f.Overall = gate(f.Overall)
-	if f.BeatStrength <= floor {
-		f.BeatStrength = 0
-	} else {
-		f.BeatStrength = clampFloat((f.BeatStrength-floor)/(1.0-floor), 0, 1)
+// IsZero reports whether no band has a floor set.
+func (n NoiseFloors) IsZero() bool {
+	return n == NoiseFloors{}
+}
+
+// GateFeatures applies per-band noise floors so weak signals are ignored.
+func GateFeatures(f Features, floors NoiseFloors) Features {
+	if floors.IsZero() {
+		return f
 	}
-	if f.Overall == 0 && f.Bass == 0 && f.Mid == 0 && f.Treble == 0 {
+
+	f.Sub = gate(f.Sub, floors.Sub)
+	f.Bass = gate(f.Bass, floors.Bass)
+	f.LowMid = gate(f.LowMid, floors.LowMid)
+	f.HighMid = gate(f.HighMid, floors.HighMid)
+	f.Mid = gate(f.Mid, floors.Mid)
+	f.Treble = gate(f.Treble, floors.Treble)
+	f.Overall = gate(f.Overall, floors.Overall)
+	f.BeatStrength = gate(f.BeatStrength, floors.Beat)
+	if f.Overall == 0 && f.Sub == 0 && f.Bass == 0 && f.Mid == 0 && f.Treble == 0 {
 		f.IsDrop = false
+		f.Onset = false
+		f.Harmonic = 0
+		f.Percussive = 0
 	}
 	return f
 }
 
+func gate(v, floor float64) float64 {
+	if floor <= 0 {
+		return v
+	}
+	if v <= floor {
+		return 0
+	}
+	return clampFloat((v-floor)/(1.0-floor), 0, 1)
+}
+
 func clampFloat(v, minVal, maxVal float64) float64 {
 	if v < minVal {
 		return minVal
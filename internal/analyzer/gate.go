@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"math"
+	"time"
+)
+
+// Gate is a stateful noise gate. A band opens once it rises above its floor
+// and only closes after falling `hysteresis` below it; on close the last
+// value fades out over the hold time instead of snapping to black.
+type Gate struct {
+	hysteresis float64
+	hold       float64
+	bands      [8]bandGate
+}
+
+type bandGate struct {
+	open  bool
+	held  float64
+	value float64
+}
+
+// NewGate returns a Gate. With zero hysteresis and hold it behaves like
+// GateFeatures.
+func NewGate(hysteresis float64, hold time.Duration) *Gate {
+	return &Gate{
+		hysteresis: clampFloat(hysteresis, 0, 1),
+		hold:       math.Max(hold.Seconds(), 0),
+	}
+}
+
+// Apply gates f against floors, advancing hold timers by delta seconds.
+func (g *Gate) Apply(f Features, floors NoiseFloors, delta float64) Features {
+	if floors.IsZero() {
+		return f
+	}
+
+	f.Sub = g.bands[0].step(f.Sub, floors.Sub, g.hysteresis, g.hold, delta)
+	f.Bass = g.bands[1].step(f.Bass, floors.Bass, g.hysteresis, g.hold, delta)
+	f.LowMid = g.bands[2].step(f.LowMid, floors.LowMid, g.hysteresis, g.hold, delta)
+	f.HighMid = g.bands[3].step(f.HighMid, floors.HighMid, g.hysteresis, g.hold, delta)
+	f.Mid = g.bands[4].step(f.Mid, floors.Mid, g.hysteresis, g.hold, delta)
+	f.Treble = g.bands[5].step(f.Treble, floors.Treble, g.hysteresis, g.hold, delta)
+	f.Overall = g.bands[6].step(f.Overall, floors.Overall, g.hysteresis, g.hold, delta)
+	f.BeatStrength = g.bands[7].step(f.BeatStrength, floors.Beat, g.hysteresis, g.hold, delta)
+	if f.Overall == 0 && f.Sub == 0 && f.Bass == 0 && f.Mid == 0 && f.Treble == 0 {
+		f.IsDrop = false
+		f.Onset = false
+		f.Harmonic = 0
+		f.Percussive = 0
+		f.Spectrum = [SpectrumBands]float64{}
+		f.Waveform = [WaveformSamples]float64{}
+		f.Level = 0
+		f.Peak = 0
+		f.Goniometer = [GoniometerPoints][2]float64{}
+		f.Correlation = 0
+	}
+	return f
+}
+
+// step runs the closed -> open -> holding -> closed state machine for one band.
+func (b *bandGate) step(v, floor, hysteresis, hold, delta float64) float64 {
+	if floor <= 0 {
+		return v
+	}
+	closeLevel := math.Max(floor-hysteresis, 0)
+
+	if !b.open {
+		if v <= floor {
+			return 0
+		}
+		b.open = true
+	}
+
+	if v > closeLevel {
+		b.held = 0
+		b.value = gate(v, closeLevel)
+		return b.value
+	}
+
+	b.held += delta
+	if b.held >= hold {
+		b.open = false
+		b.held = 0
+		b.value = 0
+		return 0
+	}
+	return b.value * (1 - b.held/hold)
+}
@@ -0,0 +1,89 @@
+package analyzer
+
+import "math"
+
+// goertzelFreqs are the probe frequencies of the eco path: a few per band,
+// log spaced, enough to follow each band's level without a full spectrum.
+var goertzelFreqs = []float64{
+	30, 45, // sub
+	70, 100, 140, 200, // bass
+	300, 450, 650, // low-mid
+	1000, 1400, 1800, // high-mid
+	2500, 3500, 5000, 7000, // treble
+}
+
+// goertzelBank measures band energy with one Goertzel filter per probe
+// frequency over the same Hann-windowed frame the FFT path would use. Sixteen
+// second-order filters cost a fraction of a 2048-point FFT, which is what
+// small boards on eco quality need. Magnitudes match the FFT bin at the same
+// frequency, so envelopes and gates behave the same in both modes.
+type goertzelBank struct {
+	size       int
+	sampleRate float64
+	coeffs     []float64
+	window     []float64
+	// mags holds the last magnitude of every probe, in goertzelFreqs order.
+	mags []float64
+}
+
+func (g *goertzelBank) ensure(sampleRate float64, size int) {
+	if g.size == size && g.sampleRate == sampleRate {
+		return
+	}
+	g.size = size
+	g.sampleRate = sampleRate
+	g.coeffs = make([]float64, len(goertzelFreqs))
+	for i, freq := range goertzelFreqs {
+		g.coeffs[i] = 2 * math.Cos(2*math.Pi*freq/sampleRate)
+	}
+	g.mags = make([]float64, len(goertzelFreqs))
+	g.window = make([]float64, size)
+	for i := range g.window {
+		g.window[i] = hann(float64(i), float64(size))
+	}
+}
+
+func (g *goertzelBank) analyze(samples []float32, sampleRate float64) bandLevels {
+	size := nextPow2(min(len(samples), 2048))
+	if size < 256 {
+		size = 256
+	}
+	g.ensure(sampleRate, size)
+	frame := samples[:min(len(samples), size)]
+
+	var sums, counts [7]float64
+	for i, coeff := range g.coeffs {
+		var s1, s2 float64
+		for n, v := range frame {
+			s0 := float64(v)*g.window[n] + coeff*s1 - s2
+			s2, s1 = s1, s0
+		}
+		power := s1*s1 + s2*s2 - coeff*s1*s2
+		mag := math.Sqrt(math.Max(power, 0))
+		g.mags[i] = mag
+
+		freq := goertzelFreqs[i]
+		for band, r := range bandRanges {
+			if freq >= r[0] && freq < r[1] {
+				sums[band] += mag
+				counts[band]++
+			}
+		}
+	}
+
+	var out [7]float64
+	for band := range out {
+		if counts[band] > 0 {
+			out[band] = math.Min(1.0, sums[band]/counts[band])
+		}
+	}
+	return bandLevels{
+		low:     out[0],
+		sub:     out[1],
+		bass:    out[2],
+		lowMid:  out[3],
+		highMid: out[4],
+		mid:     out[5],
+		treble:  out[6],
+	}
+}
@@ -0,0 +1,63 @@
+package analyzer
+
+// History is a fixed-size ring buffer of recent Features, for visuals that
+// trail over the last few seconds (spectrograms, waveform trails). It is not
+// safe for concurrent use.
+type History struct {
+	frames []Features
+	next   int
+	filled int
+}
+
+// NewHistory returns a history holding up to size frames.
+func NewHistory(size int) *History {
+	if size < 1 {
+		size = 1
+	}
+	return &History{frames: make([]Features, size)}
+}
+
+// Push records the newest frame, overwriting the oldest once full.
+func (h *History) Push(f Features) {
+	h.frames[h.next] = f
+	h.next = (h.next + 1) % len(h.frames)
+	if h.filled < len(h.frames) {
+		h.filled++
+	}
+}
+
+// Len returns how many frames are stored.
+func (h *History) Len() int {
+	if h == nil {
+		return 0
+	}
+	return h.filled
+}
+
+// Cap returns the most frames the history can hold.
+func (h *History) Cap() int {
+	if h == nil {
+		return 0
+	}
+	return len(h.frames)
+}
+
+// Last appends the newest n frames (fewer if not yet recorded) to dst[:0],
+// oldest first, and returns it. n <= 0 means everything stored.
+func (h *History) Last(n int, dst []Features) []Features {
+	dst = dst[:0]
+	if h == nil {
+		return dst
+	}
+	if n <= 0 || n > h.filled {
+		n = h.filled
+	}
+	start := h.next - n
+	if start < 0 {
+		start += len(h.frames)
+	}
+	for i := 0; i < n; i++ {
+		dst = append(dst, h.frames[(start+i)%len(h.frames)])
+	}
+	return dst
+}
@@ -0,0 +1,64 @@
+package analyzer
+
+import "math"
+
+// Hits classifies the transients of one frame into rough drum voices.
+type Hits struct {
+	Kick  bool
+	Snare bool
+	Hat   bool
+}
+
+// Any reports whether at least one voice triggered.
+func (h Hits) Any() bool {
+	return h.Kick || h.Snare || h.Hat
+}
+
+// HitDetector turns band energy jumps into kick/snare/hat triggers. Each
+// voice compares its band against a slow average and has a refractory time
+// so a single hit doesn't retrigger on the following frames.
+type HitDetector struct {
+	voices [3]hitVoice
+}
+
+type hitVoice struct {
+	avg        float64
+	since      float64
+	refractory float64
+	threshold  float64
+}
+
+// NewHitDetector returns a detector tuned for typical club material.
+func NewHitDetector() *HitDetector {
+	return &HitDetector{voices: [3]hitVoice{
+		{refractory: 0.2, threshold: 0.18},  // kick
+		{refractory: 0.15, threshold: 0.15}, // snare
+		{refractory: 0.08, threshold: 0.12}, // hat
+	}}
+}
+
+// Detect returns the hits found in f, delta seconds after the last call.
+func (d *HitDetector) Detect(f Features, delta float64) Hits {
+	low := math.Max(f.Sub, f.Bass)
+	mid := (f.LowMid + f.HighMid) * 0.5
+	return Hits{
+		// the analyzer's onset detector already tracks the low end
+		Kick:  d.voices[0].step(low, delta, f.Onset),
+		Snare: d.voices[1].step(mid, delta, false),
+		Hat:   d.voices[2].step(f.Treble, delta, false),
+	}
+}
+
+func (v *hitVoice) step(level, delta float64, force bool) bool {
+	v.since += delta
+	jump := level - v.avg
+	v.avg = v.avg*0.9 + level*0.1
+	if v.since < v.refractory {
+		return false
+	}
+	if force || (jump > v.threshold && level > 0.2) {
+		v.since = 0
+		return true
+	}
+	return false
+}
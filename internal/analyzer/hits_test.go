@@ -0,0 +1,86 @@
+package analyzer
+
+import "testing"
+
+func TestHitDetector(t *testing.T) {
+	const frame = 1.0 / 60
+	quiet := Features{}
+	for _, tc := range []struct {
+		name   string
+		frames []Features
+		want   []Hits // one per frame
+	}{
+		{
+			name:   "onset forces a kick",
+			frames: []Features{quiet, {Onset: true}},
+			want:   []Hits{{}, {Kick: true}},
+		},
+		{
+			name:   "jumps in each band",
+			frames: []Features{quiet, {Bass: 0.8}, {LowMid: 0.6, HighMid: 0.6}, {Treble: 0.5}},
+			want:   []Hits{{}, {Kick: true}, {Snare: true}, {Hat: true}},
+		},
+		{
+			name:   "sub counts as low end",
+			frames: []Features{quiet, {Sub: 0.8}},
+			want:   []Hits{{}, {Kick: true}},
+		},
+		{
+			name:   "within the refractory time",
+			frames: []Features{quiet, {Treble: 0.6}, {Treble: 0.9}, {Treble: 0.9}},
+			want:   []Hits{{}, {Hat: true}, {}, {}},
+		},
+		{
+			name:   "small jumps and quiet levels are ignored",
+			frames: []Features{quiet, {Treble: 0.1}, {Treble: 0.19}, {Bass: 0.15}},
+			want:   []Hits{{}, {}, {}, {}},
+		},
+		{
+			// the kick's refractory time is 0.2s, 12 frames
+			name:   "refractory time",
+			frames: []Features{quiet, {Onset: true}, {Onset: true}, {Onset: true}},
+			want:   []Hits{{}, {Kick: true}, {}, {}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewHitDetector()
+			// start past every voice's refractory time
+			d.Detect(quiet, 1)
+			for i, f := range tc.frames {
+				if got := d.Detect(f, frame); got != tc.want[i] {
+					t.Fatalf("frame %d: %+v, want %+v", i, got, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHitDetectorSettlesOnSteadyLevels(t *testing.T) {
+	d := NewHitDetector()
+	steady := Features{Bass: 0.7, LowMid: 0.5, HighMid: 0.5, Treble: 0.6}
+	for range 60 {
+		d.Detect(steady, 1.0/60)
+	}
+	for i := range 120 {
+		if hits := d.Detect(steady, 1.0/60); hits.Any() {
+			t.Fatalf("frame %d of a steady level: %+v", i, hits)
+		}
+	}
+}
+
+func TestHitDetectorRetriggersAfterRefractory(t *testing.T) {
+	d := NewHitDetector()
+	d.Detect(Features{}, 1)
+	if !d.Detect(Features{Onset: true}, 0.01).Kick {
+		t.Fatal("first onset missed")
+	}
+	if d.Detect(Features{Onset: true}, 0.1).Kick {
+		t.Fatal("onset 0.1s later retriggered")
+	}
+	if !d.Detect(Features{Onset: true}, 0.15).Kick {
+		t.Fatal("onset 0.25s later missed")
+	}
+	if (Hits{}).Any() || !(Hits{Hat: true}).Any() {
+		t.Fatal("Any")
+	}
+}
@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	hpssBands = 48
+	hpssMinHz = 40.0
+	hpssMaxHz = 8000.0
+	// hpssTimeFrames is the median length across time, ~280 ms at 60 fps.
+	// The window is causal, so sustained notes need a few frames to count
+	// as harmonic.
+	hpssTimeFrames = 17
+	// hpssFreqBands is the median length across frequency.
+	hpssFreqBands = 9
+)
+
+// hpss splits a log-frequency magnitude spectrum into harmonic and
+// percussive energy by median filtering (Fitzgerald, 2010): harmonic content
+// is steady across time, percussive content is broad across frequency. Soft
+// Wiener masks share each band between the two.
+type hpss struct {
+	spectrum []float64
+	history  [][]float64
+	next     int
+	filled   int
+	scratch  []float64
+}
+
+func newHPSS() *hpss {
+	h := &hpss{
+		spectrum: make([]float64, hpssBands),
+		history:  make([][]float64, hpssTimeFrames),
+		scratch:  make([]float64, 0, max(hpssTimeFrames, hpssFreqBands)),
+	}
+	for i := range h.history {
+		h.history[i] = make([]float64, hpssBands)
+	}
+	return h
+}
+
+// hpssBandEdges returns the Hz range of hpss band k.
+func hpssBandEdges(k int) (lo, hi float64) {
+	ratio := hpssMaxHz / hpssMinHz
+	lo = hpssMinHz * math.Pow(ratio, float64(k)/hpssBands)
+	hi = hpssMinHz * math.Pow(ratio, float64(k+1)/hpssBands)
+	return lo, hi
+}
+
+// fillFromFFT averages FFT bin magnitudes (bins 0..n/2-1) into the log
+// bands. Bands narrower than one bin take the nearest bin.
+func (h *hpss) fillFromFFT(buffer []complex128, resolution float64) {
+	limit := len(buffer) - 1
+	for k := range h.spectrum {
+		lo, hi := hpssBandEdges(k)
+		first := int(math.Ceil(lo / resolution))
+		last := int(math.Floor(hi / resolution))
+		if last < first {
+			first = int(math.Round(math.Sqrt(lo*hi) / resolution))
+			last = first
+		}
+		first = min(first, limit)
+		last = min(last, limit)
+		sum := 0.0
+		for i := first; i <= last; i++ {
+			sum += cmag(buffer[i])
+		}
+		h.spectrum[k] = sum / float64(last-first+1)
+	}
+}
+
+// fillFromCQT picks the constant-Q bin closest to each band centre.
+func (h *hpss) fillFromCQT(c *cqtBank) {
+	for k := range h.spectrum {
+		lo, hi := hpssBandEdges(k)
+		centre := math.Sqrt(lo * hi)
+		idx := int(math.Round(math.Log2(centre/cqtMinHz) * cqtBinsPerOctave))
+		if idx < 0 || idx >= len(c.mags) {
+			h.spectrum[k] = 0
+			continue
+		}
+		h.spectrum[k] = c.mags[idx]
+	}
+}
+
+// fillFromProbes gives each band the magnitude of the probe closest to its
+// centre on a log scale. With only a few probes neighbouring bands share
+// values, which still separates steady tones from broadband hits.
+func (h *hpss) fillFromProbes(freqs, mags []float64) {
+	for k := range h.spectrum {
+		lo, hi := hpssBandEdges(k)
+		centre := math.Log(lo*hi) / 2
+		best, bestDist := 0, math.Inf(1)
+		for i, freq := range freqs {
+			if d := math.Abs(math.Log(freq) - centre); d < bestDist {
+				best, bestDist = i, d
+			}
+		}
+		h.spectrum[k] = mags[best]
+	}
+}
+
+// separate pushes the current spectrum into the history and returns the
+// mean harmonic and percussive magnitude per band.
+func (h *hpss) separate() (harmonic, percussive float64) {
+	copy(h.history[h.next], h.spectrum)
+	h.next = (h.next + 1) % len(h.history)
+	if h.filled < len(h.history) {
+		h.filled++
+	}
+
+	half := hpssFreqBands / 2
+	for k, x := range h.spectrum {
+		if x <= 0 {
+			continue
+		}
+		h.scratch = h.scratch[:0]
+		for i := 0; i < h.filled; i++ {
+			h.scratch = append(h.scratch, h.history[i][k])
+		}
+		across := median(h.scratch)
+
+		h.scratch = h.scratch[:0]
+		for j := max(0, k-half); j <= min(len(h.spectrum)-1, k+half); j++ {
+			h.scratch = append(h.scratch, h.spectrum[j])
+		}
+		along := median(h.scratch)
+
+		hh, pp := across*across, along*along
+		if hh+pp == 0 {
+			continue
+		}
+		harmonic += x * hh / (hh + pp)
+		percussive += x * pp / (hh + pp)
+	}
+	n := float64(len(h.spectrum))
+	return harmonic / n, percussive / n
+}
+
+// median sorts values in place.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return values[mid]
+	}
+	return (values[mid-1] + values[mid]) * 0.5
+}
@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"math"
+	"math/bits"
+)
+
+// realFFT is an in-place radix-2 FFT for real input of a fixed power-of-two
+// size. It packs the n real samples into n/2 complex values, transforms
+// those and untangles the result, so it does half the work of a complex FFT
+// and allocates nothing after construction.
+type realFFT struct {
+	n       int
+	z       []complex128 // packed input / complex FFT workspace, n/2
+	out     []complex128 // bins 0..n/2-1
+	twiddle []complex128 // e^(-2πik/(n/2)) for the complex FFT
+	post    []complex128 // e^(-2πik/n) for untangling
+	rev     []int
+}
+
+func newRealFFT(n int) *realFFT {
+	m := n / 2
+	f := &realFFT{
+		n:       n,
+		z:       make([]complex128, m),
+		out:     make([]complex128, m),
+		twiddle: make([]complex128, max(m/2, 1)),
+		post:    make([]complex128, m),
+		rev:     make([]int, m),
+	}
+	for k := range f.twiddle {
+		s, c := math.Sincos(-2 * math.Pi * float64(k) / float64(m))
+		f.twiddle[k] = complex(c, s)
+	}
+	for k := range f.post {
+		s, c := math.Sincos(-2 * math.Pi * float64(k) / float64(n))
+		f.post[k] = complex(c, s)
+	}
+	shift := 64 - bits.TrailingZeros(uint(m))
+	for i := range f.rev {
+		if m > 1 {
+			f.rev[i] = int(bits.Reverse64(uint64(i)) >> shift)
+		}
+	}
+	return f
+}
+
+// transform returns the first n/2 bins of the DFT of in (len n). The result
+// is unnormalised and reused by the next call.
+func (f *realFFT) transform(in []float64) []complex128 {
+	m := len(f.z)
+	for i, j := range f.rev {
+		f.z[j] = complex(in[2*i], in[2*i+1])
+	}
+
+	for size := 2; size <= m; size <<= 1 {
+		half := size / 2
+		step := m / size
+		for start := 0; start < m; start += size {
+			for k := 0; k < half; k++ {
+				w := f.twiddle[k*step]
+				a := f.z[start+k]
+				b := f.z[start+k+half] * w
+				f.z[start+k] = a + b
+				f.z[start+k+half] = a - b
+			}
+		}
+	}
+
+	// X[k] = E[k] + e^(-2πik/n)·O[k], where E and O are the spectra of the
+	// even and odd samples recovered from Z[k] and conj(Z[m-k]).
+	for k := 0; k < m; k++ {
+		zk := f.z[k]
+		zc := f.z[(m-k)%m]
+		zc = complex(real(zc), -imag(zc))
+		even := (zk + zc) * 0.5
+		odd := (zk - zc) * complex(0, -0.5)
+		f.out[k] = even + f.post[k]*odd
+	}
+	return f.out
+}
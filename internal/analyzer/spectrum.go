@@ -0,0 +1,50 @@
+package analyzer
+
+import "math"
+
+// SpectrumBands is the length of Features.Spectrum: log-spaced bands from
+// 40 Hz to 8 kHz, the same ones the harmonic/percussive split reads.
+const SpectrumBands = hpssBands
+
+const (
+	// spectrumReleaseMs is how slowly a band's gain reference lets go, so
+	// bars keep their dynamics but quiet passages still fill the screen.
+	spectrumReleaseMs = 3000
+	// spectrumTilt keeps a band from being scaled up past this share of
+	// the loudest one; without it, hiss in an empty band would read as
+	// loud as the kick.
+	spectrumTilt = 0.3
+	// spectrumFloor is the smallest reference, so silence stays dark.
+	spectrumFloor = 0.005
+)
+
+// spectrumGain scales the raw log spectrum to 0-1 per band. Every band
+// follows its own peak, which evens out the natural fall-off towards the
+// treble the way an equalizer display does.
+type spectrumGain struct {
+	peaks   [SpectrumBands]float64
+	loudest float64
+}
+
+// levels returns raw (SpectrumBands long) relative to the recent peaks.
+func (g *spectrumGain) levels(raw []float64, delta float64) [SpectrumBands]float64 {
+	coef := coefficient(spectrumReleaseMs, delta)
+	frameMax := 0.0
+	for _, v := range raw {
+		frameMax = math.Max(frameMax, v)
+	}
+	g.loudest = math.Max(frameMax, g.loudest*coef)
+
+	var out [SpectrumBands]float64
+	for k, v := range raw {
+		g.peaks[k] = math.Max(v, g.peaks[k]*coef)
+		ref := math.Max(math.Max(g.peaks[k], g.loudest*spectrumTilt), spectrumFloor)
+		out[k] = math.Min(1, v/ref)
+	}
+	return out
+}
+
+// SpectrumBandHz returns the lower and upper edge of Spectrum band k.
+func SpectrumBandHz(k int) (lo, hi float64) {
+	return hpssBandEdges(k)
+}
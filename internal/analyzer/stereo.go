@@ -0,0 +1,76 @@
+package analyzer
+
+import "math"
+
+// GoniometerPoints is the length of Features.Goniometer.
+const GoniometerPoints = 128
+
+const (
+	// goniometerStride is how many input samples lie between two points,
+	// so the points cover about 12 ms at 44.1 kHz.
+	goniometerStride = 4
+	// goniometerReleaseMs is how slowly the plot's gain reference lets go.
+	goniometerReleaseMs = 2000
+	// goniometerFloor is the smallest reference, so silence stays a dot.
+	goniometerFloor = 0.01
+	// correlationFloor is the energy below which the correlation reads 0
+	// instead of the phase of noise.
+	correlationFloor = 1e-8
+)
+
+// goniometer plots the stereo image of the input: side against mid, so a
+// mono signal is a vertical line, a wide one a cloud and an out of phase one
+// a horizontal line.
+type goniometer struct {
+	peak float64
+}
+
+// plot returns the newest points of mid and side (left = mid+side, right =
+// mid-side) scaled to -1..1, and the correlation of left and right from -1
+// (out of phase) to 1 (mono). An empty side is mono input.
+func (g *goniometer) plot(mid, side []float32, delta float64) ([GoniometerPoints][2]float64, float64) {
+	var out [GoniometerPoints][2]float64
+	span := min(len(mid), GoniometerPoints*goniometerStride)
+	if span == 0 {
+		return out, 0
+	}
+	mid = mid[len(mid)-span:]
+	if len(side) >= span {
+		side = side[len(side)-span:]
+	} else {
+		side = nil
+	}
+	sideAt := func(i int) float64 {
+		if side == nil {
+			return 0
+		}
+		return float64(side[i])
+	}
+
+	windowPeak := 0.0
+	var lr, ll, rr float64
+	for i, m := range mid {
+		m, s := float64(m), sideAt(i)
+		windowPeak = math.Max(windowPeak, math.Max(math.Abs(m), math.Abs(s)))
+		l, r := m+s, m-s
+		lr += l * r
+		ll += l * l
+		rr += r * r
+	}
+	correlation := 0.0
+	if ll > correlationFloor && rr > correlationFloor {
+		correlation = clampFloat(lr/math.Sqrt(ll*rr), -1, 1)
+	}
+
+	g.peak = math.Max(windowPeak, g.peak*coefficient(goniometerReleaseMs, delta))
+	scale := 1 / math.Max(g.peak, goniometerFloor)
+	first := span - GoniometerPoints*goniometerStride
+	for j := range out {
+		i := first + j*goniometerStride
+		if i < 0 {
+			continue
+		}
+		out[j] = [2]float64{clampFloat(sideAt(i)*scale, -1, 1), clampFloat(float64(mid[i])*scale, -1, 1)}
+	}
+	return out, correlation
+}
@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	tempoMinBPM      = 60.0
+	tempoMaxBPM      = 180.0
+	tempoMinInterval = 0.25 // seconds between onsets (240 BPM)
+	tempoMaxOnsets   = 16
+)
+
+// tempoTracker estimates BPM from the spacing of detected onsets. Intervals
+// are folded into the 60-180 BPM octave so half/double-time hits agree, and
+// confidence reports how many recent intervals line up with the estimate.
+type tempoTracker struct {
+	clock      float64
+	lastOnset  float64
+	onsets     []float64
+	intervals  []float64
+	bpm        float64
+	confidence float64
+}
+
+func newTempoTracker() *tempoTracker {
+	return &tempoTracker{
+		lastOnset: -1,
+		onsets:    make([]float64, 0, tempoMaxOnsets),
+		intervals: make([]float64, 0, tempoMaxOnsets),
+	}
+}
+
+// advance moves the tracker clock forward and registers an onset when set.
+// It returns true when the onset was accepted (not a retrigger).
+func (t *tempoTracker) advance(delta float64, onset bool) bool {
+	if delta > 0 {
+		t.clock += delta
+	}
+	if !onset {
+		t.decay(delta)
+		return false
+	}
+	if t.lastOnset >= 0 && t.clock-t.lastOnset < tempoMinInterval {
+		return false
+	}
+	t.lastOnset = t.clock
+	t.onsets = append(t.onsets, t.clock)
+	if len(t.onsets) > tempoMaxOnsets {
+		copy(t.onsets, t.onsets[1:])
+		t.onsets = t.onsets[:len(t.onsets)-1]
+	}
+	t.estimate()
+	return true
+}
+
+// decay lowers confidence when onsets stop arriving (breakdowns, silence).
+func (t *tempoTracker) decay(delta float64) {
+	if t.bpm <= 0 || t.lastOnset < 0 || delta <= 0 {
+		return
+	}
+	period := 60.0 / t.bpm
+	if t.clock-t.lastOnset > period*4 {
+		t.confidence *= math.Pow(0.5, delta)
+	}
+}
+
+func (t *tempoTracker) estimate() {
+	if len(t.onsets) < 3 {
+		return
+	}
+	t.intervals = t.intervals[:0]
+	for i := 1; i < len(t.onsets); i++ {
+		t.intervals = append(t.intervals, foldInterval(t.onsets[i]-t.onsets[i-1]))
+	}
+	sorted := append([]float64(nil), t.intervals...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+	if median <= 0 {
+		return
+	}
+
+	matches := 0
+	for _, iv := range t.intervals {
+		if math.Abs(iv-median)/median < 0.08 {
+			matches++
+		}
+	}
+	confidence := float64(matches) / float64(len(t.intervals))
+
+	bpm := 60.0 / median
+	if t.bpm <= 0 || math.Abs(bpm-t.bpm)/t.bpm > 0.15 {
+		// big jump: only follow once the new tempo is well supported
+		if confidence >= 0.5 || t.bpm <= 0 {
+			t.bpm = bpm
+		}
+	} else {
+		t.bpm = t.bpm*0.7 + bpm*0.3
+	}
+	t.confidence = confidence
+}
+
+// foldInterval maps an inter-onset interval into the 60-180 BPM range.
+func foldInterval(iv float64) float64 {
+	if iv <= 0 {
+		return 0
+	}
+	minIv := 60.0 / tempoMaxBPM
+	maxIv := 60.0 / tempoMinBPM
+	for iv < minIv {
+		iv *= 2
+	}
+	for iv > maxIv {
+		iv /= 2
+	}
+	return iv
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import "math"
+
+// WaveformSamples is the length of Features.Waveform.
+const WaveformSamples = 128
+
+const (
+	// waveformSpan is the most input samples the waveform covers, about
+	// 23 ms at 44.1 kHz: two cycles of a 90 Hz kick.
+	waveformSpan = 1024
+	// waveformHysteresis is how far below zero, relative to the window's
+	// peak, the signal must go to arm the trigger, so noise riding on a
+	// crossing doesn't fire it.
+	waveformHysteresis = 0.1
+	// waveformReleaseMs is how slowly the waveform's gain reference lets go.
+	waveformReleaseMs = 2000
+	// waveformFloor is the smallest reference, so silence stays flat.
+	waveformFloor = 0.01
+)
+
+// waveformScope cuts a triggered, downsampled trace out of the input the way
+// a hardware scope does: the trace starts on a rising zero crossing, so a
+// steady tone stands still from frame to frame.
+type waveformScope struct {
+	peak float64
+}
+
+// trace returns the newest triggered window of samples scaled to -1..1.
+// It covers at most half of samples; the older half is where the trigger is
+// searched for. Without a crossing the newest window is shown untriggered.
+func (w *waveformScope) trace(samples []float32, delta float64) [WaveformSamples]float64 {
+	var out [WaveformSamples]float64
+	span := min(len(samples)/2, waveformSpan)
+	if span == 0 {
+		return out
+	}
+
+	latest := len(samples) - span
+	windowPeak := 0.0
+	for _, s := range samples[latest-span:] {
+		windowPeak = math.Max(windowPeak, math.Abs(float64(s)))
+	}
+	start := latest
+	if at, ok := risingCrossing(samples[:latest+1], latest-span, windowPeak*waveformHysteresis); ok {
+		start = at
+	}
+
+	w.peak = math.Max(windowPeak, w.peak*coefficient(waveformReleaseMs, delta))
+	scale := 1 / math.Max(w.peak, waveformFloor)
+	for j := range out {
+		lo := start + j*span/WaveformSamples
+		hi := max(start+(j+1)*span/WaveformSamples, lo+1)
+		sum := 0.0
+		for _, s := range samples[lo:hi] {
+			sum += float64(s)
+		}
+		out[j] = clampFloat(sum/float64(hi-lo)*scale, -1, 1)
+	}
+	return out
+}
+
+// risingCrossing returns the last index at or after from where samples
+// passes zero going up, after first dipping below -arm.
+func risingCrossing(samples []float32, from int, arm float64) (int, bool) {
+	armed := false
+	found, ok := 0, false
+	for i, s := range samples {
+		v := float64(s)
+		if v < -arm {
+			armed = true
+			continue
+		}
+		if armed && v >= 0 {
+			armed = false
+			if i >= from && i > 0 {
+				found, ok = i, true
+			}
+		}
+	}
+	return found, ok
+}
@@ -4,20 +4,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"image"
 	"log"
 	"math/rand"
 	"net"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/eiannone/keyboard"
 	"github.com/guidoenr/golizer/internal/analyzer"
 	"github.com/guidoenr/golizer/internal/audio"
+	"github.com/guidoenr/golizer/internal/clip"
+	"github.com/guidoenr/golizer/internal/crash"
+	"github.com/guidoenr/golizer/internal/lyrics"
+	"github.com/guidoenr/golizer/internal/midi"
 	"github.com/guidoenr/golizer/internal/params"
+	"github.com/guidoenr/golizer/internal/relay"
 	"github.com/guidoenr/golizer/internal/render"
+	"github.com/guidoenr/golizer/internal/sink"
+	"github.com/mattn/go-runewidth"
 	"golang.org/x/term"
 )
 
@@ -27,21 +39,68 @@ type Config struct {
 	Width          int
 	Height         int
 	TargetFPS      float64
+	MaxFPS         float64 // frame rate cap, 0 = as fast as frames render
 	BufferSize     int
+	AnalysisMode   string
 	DisableAudio   bool
 	ShowStatusBar  bool
+	StatusFormat   string // "{fps} {temp} ..." puts the status on one row, "" = a field per row
+	StatusPosition string // StatusTop or StatusBottom
+	StatusOverlay  bool   // draw the status into the SDL window, I toggles it
 	Palette        string
 	Pattern        string
 	ColorMode      string
 	UseANSI        bool
 	Quality        string
+	ThermalHot     float64 // °C at which quality steps down, 0 = never
+	ThermalCool    float64 // °C below which it steps back up
 	AutoRandomize  bool
 	RandomInterval time.Duration
+	RandomWeights  RandomWeights // how often randomize picks each look, unset = evenly
+	Transition     time.Duration // crossfade between patterns, palettes and colour modes, 0 = cut
 	Backend        string
 	FrameStride    int
 	Scale          float64
+	DynamicRes     string        // render.DynResOff, DynResEnergy (SDL) or DynResFrameTime
+	FrameBudget    time.Duration // render time per frame DynResFrameTime keeps to, 0 = 3/4 of the frame interval
 	Fullscreen     bool
 	NoiseFloor     float64
+	NoiseFloors    analyzer.NoiseFloors
+	Envelopes      analyzer.Envelopes // per-band attack/release, zero = defaults
+	GateHysteresis float64
+	GateHold       time.Duration
+	InputGain      float64
+	FrameBlend     time.Duration
+	BeatLookahead  time.Duration
+	BeatSwapEvery  int
+	MIDIOut        string
+	MIDIChannel    int
+	MIDINotes      [3]uint8 // kick, snare, hat
+	MIDIClock      bool
+	GPIOPin        int
+	GPIOPulse      time.Duration
+	TapMIDIIn      string         // rawmidi port whose notes tap the tempo
+	TapMIDINote    int            // note that taps, < 0 = any
+	ClockMIDIIn    string         // rawmidi port whose clock drives the beat, overriding detection
+	Words          []string       // flashed one per beat in big letters
+	Lyrics         lyrics.Track   // timed lines, takes precedence over Words
+	Sinks          []sink.Config  // output sinks of the selected profile
+	Widgets        []WidgetConfig // readouts pinned to the screen corners
+	Crash          *crash.Handler // writes a report when a goroutine panics (optional)
+	ShaderDir      string         // .frag files selectable as "shader:name" (gl backend)
+	LuaDir         string         // .lua files selectable as "lua:name"
+	GIFBuffer      time.Duration  // recent frames kept for GIF export, 0 = off
+	CaptureDir     string         // where the 'g' key saves GIF clips
+	PresetsPath    string         // presets.json holding the named presets, "" = not saved
+	WebToken       string         // web panel token, shown in the status bar's panel link; the relay asks for it too
+	RecordFeatures string         // JSONL file receiving every frame's features
+	RecordCast     string         // asciinema v2 cast receiving the terminal output
+	ReplayFeatures string         // JSONL file replayed instead of live audio
+	RelayListen    string         // serve this instance's audio to followers on addr
+	RelayFrom      string         // follow another instance's relay instead of a sound card
+	RelayMode      string         // relay.ModeFeatures or relay.ModePCM
+	Kiosk          bool
+	KioskChord     string // key sequence that still quits in kiosk mode
 	ProfileLog     string
 	Log            *log.Logger
 }
@@ -51,6 +110,24 @@ type inputEvent int
 const (
 	inputEventRandomize inputEvent = iota
 	inputEventQuit
+	inputEventNextPreset
+	inputEventPrevPreset
+	// inputEventMenu opens or closes the settings menu, the arrow events
+	// move around in it
+	inputEventMenu
+	inputEventMenuUp
+	inputEventMenuDown
+	inputEventMenuLeft
+	inputEventMenuRight
+	inputEventPatternPrev
+	inputEventPatternNext
+	inputEventPalettePrev
+	inputEventPaletteNext
+	inputEventBrighter
+	inputEventDimmer
+	// inputEventPreset recalls the first preset slot; the ones after it
+	// follow up to presetSlots
+	inputEventPreset
 )
 
 // App ties together audio capture, analysis, and rendering.
@@ -59,6 +136,7 @@ type App struct {
 	cfg             Config
 	params          params.Parameters
 	renderer        *render.Renderer
+	captureMu       sync.Mutex // guards capture, shared with the analysis loop
 	capture         *audio.Capture
 	analyzer        *analyzer.Analyzer
 	fake            *fakeGenerator
@@ -75,10 +153,21 @@ type App struct {
 	colorOptions    []string
 	autoRandomize   bool
 	randomInterval  time.Duration
+	locks           RandomLocks
+	presets         *PresetStore
+	lastPreset      string // name of the preset recalled last, for cycling
+	pendingScene    *pendingScene
+	playlist        Playlist
+	playlistState   PlaylistState
 	lastRandom      time.Time
 	sampleBuffer    []float32
+	sideBuffer      []float32
+	analysisOut     chan analyzer.Features
+	liveFeatures    analyzer.Features
+	analysisFailed  atomic.Bool
 	frameBuffer     strings.Builder
 	prevLines       []string
+	cells           cellScreen // what the terminal shows, for cell diffs
 	currentLines    []string
 	profiler        *profiler
 	windowMode      bool
@@ -91,15 +180,59 @@ type App struct {
 	lastSizeCheck   time.Time
 	sizeCheckEvery  time.Duration
 	analysisSamples int
+	audioRestarts   chan audioRestart // to the analysis loop, nil without a sound card
 	tempPath        string
 	tempCheckEvery  time.Duration
 	lastTempSample  time.Time
 	lastTempC       float64
 	hasTemp         bool
 	lastThrottle    string
+	thermal         *thermalGuard // nil when thermal quality stepping is off
+	statusFormat    *statusFormat // nil for the stack of one field per row
+	statusOverlay   atomic.Bool   // the window's status overlay is shown
 	panelURL        string
+	blender         *frameBlender
+	beats           *beatScheduler
+	calibration     *calibration
+	gate            *analyzer.Gate
+	beatOut         *beatOutputs
+	kiosk           *kioskGuard
+	sinks           *sink.Set
+	clips           *clip.Buffer
+	capturing       bool                  // renderer fills Frame.Image every frame
+	stills          chan chan image.Image // snapshot requests for the next frame
+	textFrames      bool                  // frames are terminal rows (ascii backend)
+	headless        bool                  // no screen, frames are only rendered for the outputs (none backend)
+	viewMu          sync.Mutex
+	viewLines       []string
+	viewSeq         uint64
+	viewWanted      atomic.Int64 // unix nanos of the last ViewFrame call
+	words           *wordFlasher
+	widgets         *widgetSet
+	history         *analyzer.History
+	recorder        *featureRecorder
+	cast            *castRecorder
+	replay          *featureReplay
+	relayOut        *relay.Server
+	relayIn         *relay.Client
+	relayCancel     context.CancelFunc
+	tap             tapTempo
+	tapIn           *midi.In
+	clockIn         *midi.In // may be tapIn when both are the same port
+	clock           *midi.Follower
+	clockBeats      int // the clock's beat count at the last frame
+	triggers        triggers
+	menu            settingsMenu
+	paused          bool         // the frame is held, under mu
+	holding         bool         // a frame was kept for the pause
+	held            render.Frame // the last frame and its status, kept up while paused
+	heldStatus      string
 }
 
+// featureHistoryFrames is how many frames of features App keeps for
+// trailing visuals, ~10 s at 60 fps.
+const featureHistoryFrames = 600
+
 // New constructs the application using the provided configuration.
 func New(cfg Config) (*App, error) {
 	if cfg.TargetFPS <= 0 {
@@ -114,6 +247,9 @@ func New(cfg Config) (*App, error) {
 	if cfg.RandomInterval <= 0 {
 		cfg.RandomInterval = 10 * time.Second
 	}
+	if cfg.Envelopes.IsZero() {
+		cfg.Envelopes = analyzer.DefaultEnvelopes()
+	}
 
 	if cfg.Width <= 0 {
 		cfg.Width = 80
@@ -121,25 +257,49 @@ func New(cfg Config) (*App, error) {
 	if cfg.Height <= 0 {
 		cfg.Height = 24
 	}
-	renderHeight := cfg.Height
-	if cfg.ShowStatusBar && renderHeight > 1 {
-		renderHeight--
+	if cfg.StatusPosition == "" {
+		cfg.StatusPosition = StatusTop
+	}
+	if !slices.Contains(StatusPositions, cfg.StatusPosition) {
+		return nil, fmt.Errorf("unknown status position %q (top, bottom)", cfg.StatusPosition)
+	}
+	statusFormat, err := parseStatusFormat(cfg.StatusFormat)
+	if err != nil {
+		return nil, fmt.Errorf("status format: %w", err)
 	}
-
 	var backend render.Backend
 	switch strings.ToLower(strings.TrimSpace(cfg.Backend)) {
 	case "", "ascii", "terminal":
 		backend = render.BackendASCII
 	case "sdl", "window":
 		backend = render.BackendSDL
+	case "sixel":
+		backend = render.BackendSixel
+	case "drm", "kms":
+		backend = render.BackendDRM
+	case "gl", "gles":
+		backend = render.BackendGL
+	case "none", "headless":
+		backend = render.BackendNone
+		// there is no screen to put a status bar on
+		cfg.ShowStatusBar = false
 	default:
 		return nil, fmt.Errorf("unknown render backend %q", cfg.Backend)
 	}
+	renderHeight := cfg.Height
+	if cfg.ShowStatusBar && renderHeight > 1 {
+		renderHeight--
+	}
+	if cfg.RecordCast != "" && backend != render.BackendASCII {
+		return nil, fmt.Errorf("record cast: --record-cast needs the ascii backend")
+	}
 
 	renderer, err := render.NewWithBackend(backend, cfg.Width, renderHeight, cfg.Palette, cfg.Pattern, cfg.ColorMode, cfg.Quality, true, cfg.UseANSI)
 	if err != nil {
 		return nil, err
 	}
+	renderer.SetShaderDir(cfg.ShaderDir)
+	renderer.SetLuaDir(cfg.LuaDir)
 
 	tempPath := strings.TrimSpace(os.Getenv("GOLIZER_TEMP_PATH"))
 	if tempPath == "" {
@@ -157,23 +317,61 @@ func New(cfg Config) (*App, error) {
 		autoRandomize:   cfg.AutoRandomize,
 		randomInterval:  cfg.RandomInterval,
 		rng:             rand.New(rand.NewSource(time.Now().UnixNano())),
-		paletteOptions:  render.PaletteNames(),
-		patternOptions:  render.PatternNames(),
+		paletteOptions:  randomPalettes(),
+		patternOptions:  renderer.PatternNames(),
 		colorOptions:    render.ColorModeNames(),
 		sizeCheckEvery:  250 * time.Millisecond,
 		analysisSamples: selectAnalysisWindow(cfg.BufferSize),
 		tempPath:        tempPath,
 		tempCheckEvery:  5 * time.Second,
+		blender:         newFrameBlender(cfg.FrameBlend),
+		beats:           newBeatScheduler(cfg.BeatLookahead, cfg.BeatSwapEvery),
+		gate:            analyzer.NewGate(cfg.GateHysteresis, cfg.GateHold),
+		history:         analyzer.NewHistory(featureHistoryFrames),
+	}
+	renderer.SetFeatureHistory(app.history)
+	if len(cfg.Lyrics) > 0 {
+		app.words = newWordFlasher(nil, cfg.Lyrics)
+	} else {
+		app.words = newWordFlasher(cfg.Words, nil)
+	}
+	widgets, err := newWidgets(cfg.Widgets)
+	if err != nil {
+		return nil, err
+	}
+	app.widgets = widgets
+	presets, err := LoadPresets(cfg.PresetsPath)
+	if err != nil {
+		return nil, err
 	}
+	app.presets = presets
 	app.lastSizeCheck = time.Now()
 	app.lastRandom = time.Now()
-	app.panelURL = detectPanelURL()
+	app.panelURL = panelLink(cfg.WebToken)
+	app.thermal = newThermalGuard(cfg.ThermalHot, cfg.ThermalCool)
+	app.statusFormat = statusFormat
+	app.statusOverlay.Store(cfg.StatusOverlay)
 	app.windowMode = renderer.IsWindowed()
 	if app.windowMode {
 		app.cfg.ShowStatusBar = false
 		renderer.SetScale(app.frameScale)
 		renderer.SetFullscreen(app.fullscreen)
 	}
+	if backend == render.BackendSixel || backend == render.BackendDRM {
+		renderer.SetScale(cfg.Scale)
+	}
+	budget := cfg.FrameBudget
+	if budget <= 0 {
+		fps := cfg.TargetFPS
+		if cfg.MaxFPS > 0 {
+			fps = cfg.MaxFPS
+		}
+		// presenting the frame needs the rest
+		budget = time.Duration(0.75 * float64(time.Second) / fps)
+	}
+	renderer.SetFrameBudget(budget)
+	renderer.SetDynamicResolution(cfg.DynamicRes)
+	renderer.SetTransition(cfg.Transition)
 	app.frameStride = cfg.FrameStride
 	if app.frameStride <= 0 {
 		app.frameStride = 1
@@ -192,7 +390,30 @@ func New(cfg Config) (*App, error) {
 		app.colorOptions = []string{"chromatic"}
 	}
 
-	if cfg.DisableAudio {
+	if cfg.ReplayFeatures != "" {
+		replay, err := loadFeatureReplay(cfg.ReplayFeatures)
+		if err != nil {
+			return nil, fmt.Errorf("replay features: %w", err)
+		}
+		app.replay = replay
+		app.rng = rand.New(rand.NewSource(replaySeed))
+		app.log.Printf("replaying %d frames from %s", len(replay.frames), cfg.ReplayFeatures)
+	} else if cfg.RelayFrom != "" {
+		relayCtx, cancel := context.WithCancel(context.Background())
+		client, err := relay.Dial(relayCtx, cfg.RelayFrom, cfg.RelayMode, cfg.WebToken, app.log)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("audio relay: %w", err)
+		}
+		app.relayIn = client
+		app.relayCancel = cancel
+		app.deviceLabel = "relay " + cfg.RelayFrom
+		if client.Mode() == relay.ModePCM {
+			// the source already trimmed the window it sends
+			app.analysisSamples = 0
+			app.startAnalyzer(client.SampleRate())
+		}
+	} else if cfg.DisableAudio {
 		app.fake = newFakeGenerator()
 		app.log.Println("audio disabled, using synthetic generator")
 	} else {
@@ -205,10 +426,8 @@ func New(cfg Config) (*App, error) {
 			return nil, fmt.Errorf("audio capture: %w", err)
 		}
 		app.capture = capture
-		app.analyzer = analyzer.New(analyzer.Config{
-			SampleRate:  capture.SampleRate(),
-			HistorySize: 60,
-		})
+		app.audioRestarts = make(chan audioRestart)
+		app.startAnalyzer(capture.SampleRate())
 		if info := capture.Device(); info != nil {
 			app.deviceLabel = info.Name
 			app.log.Printf("audio capture started on \"%s\" @ %.0f Hz", info.Name, capture.SampleRate())
@@ -217,7 +436,75 @@ func New(cfg Config) (*App, error) {
 		}
 	}
 
+	if cfg.RelayListen != "" {
+		if app.analyzer == nil {
+			return nil, fmt.Errorf("audio relay: --relay-listen needs live audio input")
+		}
+		server, err := relay.Listen(cfg.RelayListen, app.analyzer.SampleRate(), cfg.WebToken, app.log)
+		if err != nil {
+			return nil, fmt.Errorf("audio relay: %w", err)
+		}
+		app.relayOut = server
+	}
+
+	kiosk, err := newKioskGuard(cfg.Kiosk, cfg.KioskChord)
+	if err != nil {
+		return nil, fmt.Errorf("kiosk chord: %w", err)
+	}
+	app.kiosk = kiosk
+
+	beatOut, err := newBeatOutputs(cfg, app.log)
+	if err != nil {
+		return nil, fmt.Errorf("beat outputs: %w", err)
+	}
+	app.beatOut = beatOut
+
+	if err := app.openMIDIIn(cfg); err != nil {
+		app.beatOut.Close()
+		return nil, err
+	}
+
+	recorder, err := newFeatureRecorder(cfg.RecordFeatures)
+	if err != nil {
+		app.beatOut.Close()
+		return nil, fmt.Errorf("record features: %w", err)
+	}
+	app.recorder = recorder
+
+	cast, err := newCastRecorder(cfg.RecordCast)
+	if err != nil {
+		app.beatOut.Close()
+		app.recorder.Close()
+		return nil, fmt.Errorf("record cast: %w", err)
+	}
+	app.cast = cast
+
+	sinks, err := sink.OpenAll(cfg.Sinks, app.log)
+	if err != nil {
+		app.beatOut.Close()
+		app.recorder.Close()
+		app.cast.Close()
+		return nil, fmt.Errorf("output sinks: %w", err)
+	}
+	app.sinks = sinks
+	if cfg.GIFBuffer > 0 {
+		app.clips = clip.NewBuffer(cfg.GIFBuffer, clip.DefaultFPS, clip.DefaultMaxWidth)
+	}
+	// the ascii clip is drawn from the text rows, every other backend needs
+	// the pixels
+	if sinks != nil || (app.clips != nil && backend != render.BackendASCII) {
+		app.capturing = true
+		renderer.SetCapture(true)
+	}
+	app.stills = make(chan chan image.Image, 8)
+	app.textFrames = backend == render.BackendASCII
+	app.headless = backend == render.BackendNone
+
 	app.last = time.Now()
+	if app.replay != nil {
+		app.replay.start = app.last
+		app.lastRandom = app.last
+	}
 	if cfg.Pattern != "" {
 		app.params.Pattern = strings.ToLower(cfg.Pattern)
 	}
@@ -230,14 +517,48 @@ func New(cfg Config) (*App, error) {
 	return app, nil
 }
 
+// randomPalettes lists the palettes randomize may pick. Braille and
+// halfblock render several pixels per cell, so they are only used when
+// asked for.
+func randomPalettes() []string {
+	return slices.DeleteFunc(render.PaletteNames(), func(name string) bool { return name == "braille" || name == "halfblock" })
+}
+
+// startAnalyzer sets up the analyzer and the channel the analysis loop
+// publishes on.
+func (a *App) startAnalyzer(sampleRate float64) {
+	a.analysisOut = make(chan analyzer.Features, 1)
+	a.newAnalyzer(sampleRate)
+}
+
+// newAnalyzer replaces the analyzer; only the analysis loop may call it once
+// that runs.
+func (a *App) newAnalyzer(sampleRate float64) {
+	a.analyzer = analyzer.New(analyzer.Config{
+		SampleRate:  sampleRate,
+		HistorySize: 60,
+		Mode:        a.cfg.AnalysisMode,
+		Envelopes:   a.cfg.Envelopes,
+	})
+	if a.analyzer.WantsFullBuffer() {
+		a.analysisSamples = 0
+	}
+}
+
 // Run starts the render loop until context cancellation.
 func (a *App) Run(ctx context.Context) error {
 	frameSeconds := 1.0 / a.cfg.TargetFPS
 	frameDuration := time.Duration(frameSeconds * float64(time.Second))
-	ticker := time.NewTicker(frameDuration)
-	defer ticker.Stop()
+	maxFPS := a.cfg.MaxFPS
+	if maxFPS <= 0 && a.headless {
+		// nothing to draw, frames back to back would only spin
+		maxFPS = a.cfg.TargetFPS
+	}
+	pacer := newFramePacer(maxFPS, time.Now())
+	frames := time.NewTimer(0)
+	defer frames.Stop()
 
-	if !a.windowMode {
+	if a.ownsTerminal() {
 		enterAltScreen()
 		clearScreen()
 		hideCursor()
@@ -253,15 +574,33 @@ func (a *App) Run(ctx context.Context) error {
 	inputCtx, cancelInput := context.WithCancel(ctx)
 	defer cancelInput()
 	a.startInputListener(inputCtx)
+	go a.beatOut.Run(inputCtx)
+	a.widgets.follow(inputCtx, a.log.Printf)
+	a.readMIDIIn()
+	if a.analysisOut != nil {
+		analysisDone := make(chan struct{})
+		go func() {
+			defer close(analysisDone)
+			if a.cfg.Crash != nil {
+				defer a.cfg.Crash.Recover()
+			}
+			a.runAnalysis(inputCtx, frameDuration)
+		}()
+		// the loop must be gone before Close releases the capture
+		defer func() {
+			cancelInput()
+			<-analysisDone
+		}()
+	}
 	a.ensureDimensions()
 	if a.panelURL == "" {
-		a.panelURL = detectPanelURL()
+		a.panelURL = panelLink(a.cfg.WebToken)
 	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			if !a.windowMode {
+			if a.ownsTerminal() {
 				moveCursorHome()
 				// restore terminal state immediately
 				showCursor()
@@ -277,8 +616,26 @@ func (a *App) Run(ctx context.Context) error {
 			switch evt {
 			case inputEventRandomize:
 				a.randomizeVisuals()
+			case inputEventNextPreset:
+				a.cyclePreset(1)
+			case inputEventPrevPreset:
+				a.cyclePreset(-1)
+			case inputEventMenu, inputEventMenuUp, inputEventMenuDown, inputEventMenuLeft, inputEventMenuRight:
+				a.menuInput(evt)
+			case inputEventPatternNext:
+				a.cyclePattern(1)
+			case inputEventPatternPrev:
+				a.cyclePattern(-1)
+			case inputEventPaletteNext:
+				a.cyclePalette(1)
+			case inputEventPalettePrev:
+				a.cyclePalette(-1)
+			case inputEventBrighter:
+				a.nudgeBrightness(1)
+			case inputEventDimmer:
+				a.nudgeBrightness(-1)
 			case inputEventQuit:
-				if !a.windowMode {
+				if a.ownsTerminal() {
 					moveCursorHome()
 					// restore terminal state immediately
 					showCursor()
@@ -286,15 +643,26 @@ func (a *App) Run(ctx context.Context) error {
 					fmt.Print("\x1b[0m")
 				}
 				return nil
+			default:
+				if evt >= inputEventPreset {
+					a.recallPreset(int(evt - inputEventPreset))
+				}
 			}
-		case <-ticker.C:
-			if err := a.step(); err != nil {
+		case <-frames.C:
+			if a.kiosk != nil {
+				a.kioskStep()
+			} else if err := a.step(); err != nil {
 				if errors.Is(err, render.ErrRendererQuit) {
 					return nil
 				}
+				if errors.Is(err, errReplayDone) {
+					a.log.Println(err)
+					return nil
+				}
 				return err
 			}
 			a.maybeAutoRandomize()
+			frames.Reset(pacer.delay(time.Now()))
 		}
 	}
 }
@@ -310,11 +678,41 @@ func (a *App) Close() error {
 			firstErr = err
 		}
 	}
+	a.captureMu.Lock()
 	if a.capture != nil {
 		if err := a.capture.Close(); err != nil && firstErr == nil {
 			firstErr = err
 		}
 	}
+	a.captureMu.Unlock()
+	if err := a.beatOut.Close(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	if err := a.sinks.Close(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	if err := a.recorder.Close(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	if err := a.cast.Close(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	if a.tapIn != nil {
+		if err := a.tapIn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if a.clockIn != nil && a.clockIn != a.tapIn {
+		if err := a.clockIn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := a.relayOut.Close(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	if a.relayCancel != nil {
+		a.relayCancel()
+	}
 	return firstErr
 }
 
@@ -331,41 +729,80 @@ func (a *App) step() error {
 		delta = 1.0 / a.cfg.TargetFPS
 	}
 	a.last = now
+	a.adaptQuality(now)
 
 	var features analyzer.Features
-	if a.capture != nil && a.analyzer != nil {
-		if a.profiler != nil {
-			a.profiler.markSection("capture")
-		}
-		a.sampleBuffer = a.capture.SamplesInto(a.sampleBuffer)
-		samples := a.sampleBuffer
-		if a.analysisSamples > 0 && len(samples) > a.analysisSamples {
-			samples = samples[len(samples)-a.analysisSamples:]
-		}
-		if a.profiler != nil {
-			a.profiler.markSection("analyze")
-		}
-		features = a.analyzer.Analyze(samples, delta)
-		if a.cfg.NoiseFloor > 0 {
-			features = analyzer.GateFeatures(features, a.cfg.NoiseFloor)
+	if a.replay != nil {
+		var ok bool
+		now, delta, features, ok = a.replay.Next()
+		if !ok {
+			return errReplayDone
 		}
+		a.last = now
+	} else if a.relayIn != nil && a.relayIn.Mode() == relay.ModeFeatures {
+		features = a.relayIn.Features()
+	} else if a.analysisOut != nil {
+		features = a.latestFeatures()
 	} else if a.fake != nil {
 		features = a.fake.Next(delta)
 	}
+	if a.replay == nil {
+		a.mu.Lock()
+		features = a.tap.Apply(now, features)
+		a.mu.Unlock()
+		features = a.applyMIDIClock(now, features)
+	}
+	if err := a.recorder.Record(now, delta, features); err != nil {
+		return fmt.Errorf("record features: %w", err)
+	}
 	if a.profiler != nil {
 		a.profiler.markSection("params")
 	}
 
+	// the panel sets params from its own goroutine
+	a.mu.Lock()
 	a.params.ApplyFeatures(features, delta)
 	a.params.UpdateTime(delta)
+	a.beatOut.Process(features, delta)
+	a.beats.Observe(now, features)
+	due := a.beats.Due(now)
+	if due != beatEventNone {
+		a.params.Pulse(1.0)
+	}
+	a.mu.Unlock()
+	// a follower shows the source's looks
+	if due == beatEventSwap && a.relayIn == nil {
+		palette := pickRandom(a.paletteOptions, a.renderer.PaletteName(), a.cfg.RandomWeights.Palettes, a.rng)
+		a.setScene(palette, a.renderer.PatternName(), a.renderer.ColorModeName(), true)
+	}
+	a.stepPlaylist(delta, features.Tempo)
+	a.stepScene()
+
+	a.mu.Lock()
+	if a.triggers.takeKick() {
+		a.params.Drop()
+	}
+	renderParams := a.blender.Push(a.params, delta)
+	renderParams = a.triggers.apply(now, renderParams)
+	text, textLevel := a.words.Step(now, features, delta)
+	a.mu.Unlock()
+	a.renderer.SetText(text, textLevel)
+	if a.widgets != nil {
+		a.renderer.SetWidgets(a.widgets.lines(time.Now(), a.temperature))
+	}
 
 	fps := 1.0 / delta
 
 	// update last features and fps for web server
 	a.mu.Lock()
 	a.lastFeatures = features
+	a.history.Push(features)
 	a.lastFPS = fps
 	a.mu.Unlock()
+	paused := a.Paused()
+	if paused && a.holding {
+		return a.presentHeld(now, fps)
+	}
 	if a.profiler != nil {
 		a.profiler.markSection("render")
 	}
@@ -377,16 +814,45 @@ func (a *App) step() error {
 		a.skipCounter = 0
 	}
 
-	frame := a.renderer.Render(a.params, features, fps)
+	stills := a.pendingStills()
+	if a.headless && len(stills) == 0 && a.sinks == nil && !a.clips.Due(now) {
+		// nothing takes the frame, the analysis is all there is to do
+		a.profiler.endFrame()
+		return nil
+	}
+	if paused && len(stills) == 0 && !a.capturing {
+		// the frame about to be held keeps its pixels, for snapshots
+		a.renderer.SetCapture(true)
+		defer a.renderer.SetCapture(false)
+	}
+	frame := a.renderer.Render(renderParams, features, fps)
+	a.held, a.holding = frame, paused
+	a.deliverStills(stills, frame)
+	a.sinks.Present(sink.Frame{Image: frame.Image, Lines: frame.Lines, Features: features, Time: now})
+	if a.clips.Due(now) {
+		a.clips.Add(now, frame.Image, frame.Lines)
+	}
 	statusText := frame.Status
-	if a.deviceLabel != "" && !a.cfg.DisableAudio {
-		statusText = fmt.Sprintf("%s | mic=%s", statusText, a.deviceLabel)
+	a.mu.RLock()
+	deviceLabel := a.deviceLabel
+	a.mu.RUnlock()
+	if deviceLabel != "" && !a.cfg.DisableAudio {
+		statusText = fmt.Sprintf("%s | mic=%s", statusText, deviceLabel)
 	}
 
 	if frame.Present != nil {
 		if a.profiler != nil {
 			a.profiler.markSection("present")
 		}
+		if a.windowMode {
+			a.renderer.SetStatusOverlay(a.statusOverlayLines(statusText, fps))
+		}
+		if !a.windowMode && !a.cfg.ShowStatusBar {
+			// terminal backends draw the status on the row kept free for it
+			statusText = ""
+		} else if a.statusFormat != nil {
+			statusText = a.statusFormat.line(a.statusEntries(statusText, fps), false)
+		}
 		if err := frame.Present(statusText); err != nil {
 			return err
 		}
@@ -396,13 +862,29 @@ func (a *App) step() error {
 		return nil
 	}
 
+	a.heldStatus = statusText
+	return a.drawText(now, frame.Lines, statusText, fps)
+}
+
+// drawText puts a frame of terminal rows on screen under the status bar and
+// the menu, writing only the rows that changed since the last one.
+func (a *App) drawText(now time.Time, lines []string, statusText string, fps float64) error {
 	a.frameBuffer.Reset()
 
 	a.currentLines = a.currentLines[:0]
-	a.currentLines = append(a.currentLines, frame.Lines...)
+	a.currentLines = append(a.currentLines, lines...)
+	statusRows := 0
 	if a.cfg.ShowStatusBar {
-		a.overlayStatusLines(a.buildStatusLines(statusText, fps))
+		statusLines := a.buildStatusLines(statusText, fps)
+		a.overlayStatusLines(statusLines)
+		if a.cfg.StatusPosition != StatusBottom {
+			statusRows = len(statusLines)
+		}
 	}
+	if a.menu.open.Load() {
+		a.overlayMenu(statusRows)
+	}
+	a.publishView(a.currentLines)
 
 	// ensure previous lines slice has capacity
 	if len(a.prevLines) < len(a.currentLines) {
@@ -417,22 +899,23 @@ func (a *App) step() error {
 		if idx < len(a.prevLines) && a.prevLines[idx] == line {
 			continue
 		}
-		appendCursorMove(&a.frameBuffer, idx+1)
-		a.frameBuffer.WriteString(line)
-		a.frameBuffer.WriteString("\x1b[K")
+		a.cells.drawRow(&a.frameBuffer, idx, a.width, line)
 	}
 
 	if len(a.currentLines) < len(a.prevLines) {
 		for idx := len(a.currentLines); idx < len(a.prevLines); idx++ {
-			appendCursorMove(&a.frameBuffer, idx+1)
-			a.frameBuffer.WriteString("\x1b[K")
+			a.cells.clearRow(&a.frameBuffer, idx)
 		}
 	}
+	a.cells.finish(&a.frameBuffer)
 
 	if a.frameBuffer.Len() > 0 {
 		if _, err := os.Stdout.WriteString(a.frameBuffer.String()); err != nil {
 			return err
 		}
+		if err := a.cast.Output(now, a.width, a.height, a.frameBuffer.String()); err != nil {
+			return fmt.Errorf("record cast: %w", err)
+		}
 	}
 
 	if cap(a.prevLines) < len(a.currentLines) {
@@ -451,7 +934,7 @@ func (a *App) step() error {
 }
 
 func (a *App) ensureDimensions() {
-	if a.windowMode {
+	if a.windowMode || a.headless {
 		return
 	}
 
@@ -489,11 +972,29 @@ func (a *App) ensureDimensions() {
 	a.renderHeight = renderHeight
 	a.renderer.Resize(w, renderHeight)
 	a.prevLines = nil
+	a.cells.reset()
+}
+
+// ownsTerminal reports whether frames are drawn in the terminal, which the
+// app then takes over: the alternate screen, the hidden cursor and the keys.
+func (a *App) ownsTerminal() bool {
+	return !a.windowMode && !a.headless
 }
 
 func (a *App) startInputListener(ctx context.Context) {
+	if a.headless {
+		// Ctrl-C still stops it, through the signal
+		return
+	}
 	if a.windowMode {
-		a.inputEvents = nil
+		// the window's keys arrive while the main loop presents frames, so
+		// nothing may block on the queue there
+		events := make(chan inputEvent, 16)
+		a.inputEvents = events
+		a.renderer.SetKeyHandler(func(k render.WindowKey) bool {
+			char, key := windowKey(k)
+			return a.handleKey(ctx, events, char, key)
+		})
 		return
 	}
 	if err := keyboard.Open(); err != nil {
@@ -528,34 +1029,117 @@ func (a *App) startInputListener(ctx context.Context) {
 				return
 			default:
 			}
-			switch {
-			case key == keyboard.KeyEsc || key == keyboard.KeyCtrlC:
+			if a.handleKey(ctx, events, char, key) {
 				events <- inputEventQuit
 				return
-			case char == 'q' || char == 'Q':
-				events <- inputEventQuit
-				return
-			case char == 'r' || char == 'R':
-				select {
-				case events <- inputEventRandomize:
-				default:
-				}
 			}
 		}
 	}()
 }
 
+// handleKey acts on one key from the terminal or the window, queueing on
+// events what has to happen on the main loop. It reports whether the key
+// quits.
+func (a *App) handleKey(ctx context.Context, events chan<- inputEvent, char rune, key keyboard.Key) bool {
+	switch {
+	case a.kiosk != nil:
+		return a.kiosk.Key(time.Now(), char, key)
+	case key == keyboard.KeyEsc && a.menu.open.Load():
+		keyEvent(events, inputEventMenu)
+	case key == keyboard.KeyEsc || key == keyboard.KeyCtrlC:
+		return true
+	case char == 'q' || char == 'Q':
+		return true
+	case char == 't' || char == 'T':
+		a.Tap(time.Now())
+	case char == 'g' || char == 'G':
+		go func() {
+			path, err := a.SaveGIF(time.Now())
+			if err != nil {
+				a.log.Printf("gif: %v", err)
+				return
+			}
+			a.log.Printf("gif saved to %s", path)
+		}()
+	case char == 's' || char == 'S':
+		go func() {
+			ctx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+			defer cancel()
+			path, err := a.SaveSnapshot(ctx, time.Now())
+			if err != nil {
+				a.log.Printf("snapshot: %v", err)
+				return
+			}
+			a.log.Printf("snapshot saved to %s", path)
+		}()
+	case char == 'p' || char == 'P':
+		a.toggleLock('p')
+	case char == 'c' || char == 'C':
+		a.toggleLock('c')
+	case char == 'l' || char == 'L':
+		a.toggleLock('l')
+	case char == 'f' || char == 'F' || key == keyboard.KeySpace:
+		a.togglePause()
+	case char == 'a' || char == 'A':
+		a.toggleAutoRandomize()
+	case char == 'i' || char == 'I':
+		a.toggleStatusOverlay()
+	case char == ']':
+		keyEvent(events, inputEventPatternNext)
+	case char == '[':
+		keyEvent(events, inputEventPatternPrev)
+	case char == '}':
+		keyEvent(events, inputEventPaletteNext)
+	case char == '{':
+		keyEvent(events, inputEventPalettePrev)
+	case char == '+' || char == '=':
+		keyEvent(events, inputEventBrighter)
+	case char == '-':
+		keyEvent(events, inputEventDimmer)
+	case char == 'm' || char == 'M':
+		keyEvent(events, inputEventMenu)
+	case key == keyboard.KeyArrowUp:
+		keyEvent(events, inputEventMenuUp)
+	case key == keyboard.KeyArrowDown:
+		keyEvent(events, inputEventMenuDown)
+	case key == keyboard.KeyArrowLeft:
+		keyEvent(events, inputEventMenuLeft)
+	case key == keyboard.KeyArrowRight:
+		keyEvent(events, inputEventMenuRight)
+	case char == 'r' || char == 'R':
+		keyEvent(events, inputEventRandomize)
+	case char == 'n' || char == 'N':
+		keyEvent(events, inputEventNextPreset)
+	case char == 'b' || char == 'B':
+		keyEvent(events, inputEventPrevPreset)
+	case char >= '1' && char < '1'+presetSlots:
+		keyEvent(events, inputEventPreset+inputEvent(char-'1'))
+	}
+	return false
+}
+
 func (a *App) randomizeVisuals() {
 	if a.rng == nil {
 		a.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
 	}
-	palette := pickRandom(a.paletteOptions, a.renderer.PaletteName(), a.rng)
-	pattern := pickRandom(a.patternOptions, a.renderer.PatternName(), a.rng)
-	color := pickRandom(a.colorOptions, a.renderer.ColorModeName(), a.rng)
+	locks := a.RandomLocks()
+	if locks.Pattern && locks.Palette && locks.ColorMode {
+		return
+	}
+	palette := a.renderer.PaletteName()
+	if !locks.Palette {
+		palette = pickRandom(a.paletteOptions, palette, a.cfg.RandomWeights.Palettes, a.rng)
+	}
+	pattern := a.renderer.PatternName()
+	if !locks.Pattern {
+		pattern = pickRandom(a.patternOptions, pattern, a.cfg.RandomWeights.Patterns, a.rng)
+	}
+	color := a.renderer.ColorModeName()
+	if !locks.ColorMode {
+		color = pickRandom(a.colorOptions, color, a.cfg.RandomWeights.ColorModes, a.rng)
+	}
 
-	a.renderer.Configure(palette, pattern, color, true)
-	a.params.Pattern = pattern
-	a.params.ColorMode = color
+	a.setScene(palette, pattern, color, true)
 
 	// commented out for now
 	//a.log.Printf("Randomize visuals -> palette=%s pattern=%s color=%s", palette, pattern, color)
@@ -566,9 +1150,13 @@ func (a *App) randomizeVisuals() {
 
 func (a *App) maybeAutoRandomize() {
 	now := time.Now()
+	if a.replay != nil {
+		now = a.last
+	}
 
 	a.mu.Lock()
-	if !a.autoRandomize {
+	if !a.autoRandomize || a.playlistState.Playing || a.relayIn != nil {
+		// a playing playlist or the relay source decides the look
 		a.mu.Unlock()
 		return
 	}
@@ -596,8 +1184,9 @@ func extractIPAndPort(url string) string {
 	return url
 }
 
-func (a *App) buildStatusLines(raw string, fps float64) []string {
-	width := a.width
+// statusEntries are the status fields in the order the stack shows them:
+// the app's own, then what the renderer's status (raw) carries.
+func (a *App) statusEntries(raw string, fps float64) []statusEntry {
 	temp, throttle := a.systemStats()
 
 	panelAddr := extractIPAndPort(a.panelURL)
@@ -607,19 +1196,24 @@ func (a *App) buildStatusLines(raw string, fps float64) []string {
 		{label: "TEMP", value: temp},
 		{label: "THROTTLE", value: throttle},
 		{label: "FPS", value: fmt.Sprintf("%.1f", fps)},
+		{label: "LOCKED", value: a.RandomLocks().String()},
 	}
-
-	parts := strings.Split(raw, "|")
-	if len(parts) > 1 {
-		entries = append(entries, parseKeyValuePart(parts[1])...)
+	if a.Paused() {
+		entries = append(entries, statusEntry{label: "PAUSED", value: "space to resume"})
 	}
-	if len(parts) > 2 {
-		entries = append(entries, parseMetricPart(parts[2])...)
+	return append(entries, parseStatusParts(raw)...)
+}
+
+func (a *App) buildStatusLines(raw string, fps float64) []string {
+	width := a.width
+	entries := a.statusEntries(raw, fps)
+	if a.statusFormat != nil {
+		return []string{padLine(a.statusFormat.line(entries, true), width)}
 	}
 
 	lines := make([]string, 0, len(entries))
 	for _, entry := range entries {
-		if entry.value == "" {
+		if entry.value == "" || entry.label == "MODE" {
 			continue
 		}
 		lines = append(lines, padLine(formatStatusEntry(entry), width))
@@ -627,6 +1221,8 @@ func (a *App) buildStatusLines(raw string, fps float64) []string {
 	return lines
 }
 
+// overlayStatusLines writes the status lines over the top rows of the
+// frame, or the bottom ones with --status-position bottom.
 func (a *App) overlayStatusLines(lines []string) {
 	if len(lines) == 0 || len(a.currentLines) == 0 {
 		return
@@ -635,11 +1231,25 @@ func (a *App) overlayStatusLines(lines []string) {
 	if limit > len(a.currentLines) {
 		limit = len(a.currentLines)
 	}
+	start := 0
+	if a.cfg.StatusPosition == StatusBottom {
+		// down to the row kept free for the status
+		for len(a.currentLines) < a.height {
+			a.currentLines = append(a.currentLines, "")
+		}
+		start = len(a.currentLines) - limit
+	}
 	for i := 0; i < limit; i++ {
-		a.currentLines[i] = padLine(lines[i], a.width)
+		a.currentLines[start+i] = padLine(lines[i], a.width)
 	}
 }
 
+// temperature returns the latest temperature reading in °C.
+func (a *App) temperature() (float64, bool) {
+	a.systemStats()
+	return a.lastTempC, a.hasTemp
+}
+
 func (a *App) systemStats() (string, string) {
 	if a.tempPath != "" {
 		now := time.Now()
@@ -669,14 +1279,49 @@ func (a *App) systemStats() (string, string) {
 	return temp, throttle
 }
 
+// padLine pads or cuts text to width terminal columns. Escape sequences
+// take no room and wide runes (CJK, emoji) take two; a cut never splits
+// one, and resets the colours the cut part left open.
 func padLine(text string, width int) string {
 	if width <= 0 {
 		return text
 	}
-	if len(text) >= width {
-		return text[:width]
+	var b strings.Builder
+	cols, styled := 0, false
+	for i := 0; i < len(text); {
+		if n := escapeLen(text[i:]); n > 0 {
+			b.WriteString(text[i : i+n])
+			styled = true
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		w := runewidth.RuneWidth(r)
+		if cols+w > width {
+			if styled {
+				b.WriteString("\x1b[0m")
+			}
+			return b.String() + strings.Repeat(" ", width-cols)
+		}
+		b.WriteString(text[i : i+size])
+		cols += w
+		i += size
 	}
-	return text + strings.Repeat(" ", width-len(text))
+	return b.String() + strings.Repeat(" ", width-cols)
+}
+
+// escapeLen is the length of the CSI escape sequence text starts with, 0
+// when it doesn't start with one.
+func escapeLen(text string) int {
+	if len(text) < 2 || text[0] != 0x1b || text[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(text); i++ {
+		if text[i] >= 0x40 && text[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return len(text)
 }
 
 func clearScreen() {
@@ -704,21 +1349,36 @@ func exitAltScreen() {
 	fmt.Print("\x1b[?1049l\x1b[0m")
 }
 
-func pickRandom(options []string, current string, rng *rand.Rand) string {
-	if len(options) == 0 {
-		return current
+// pickRandom picks one of options other than current, by weights (see
+// RandomWeights); current stays when nothing else may be picked.
+func pickRandom(options []string, current string, weights map[string]float64, rng *rand.Rand) string {
+	weight := func(name string) float64 {
+		if strings.EqualFold(name, current) {
+			return 0
+		}
+		if w, ok := weights[name]; ok {
+			return max(0, w)
+		}
+		return 1
+	}
+	total := 0.0
+	for _, name := range options {
+		total += weight(name)
 	}
-	if len(options) == 1 {
-		return options[0]
+	if total <= 0 {
+		return current
 	}
-	var choice string
-	for attempts := 0; attempts < 4; attempts++ {
-		choice = options[rng.Intn(len(options))]
-		if !strings.EqualFold(choice, current) {
-			return choice
+	x := rng.Float64() * total
+	choice := current
+	for _, name := range options {
+		if w := weight(name); w > 0 {
+			choice = name
+			if x -= w; x < 0 {
+				break
+			}
 		}
 	}
-	return options[rng.Intn(len(options))]
+	return choice
 }
 
 func appendCursorMove(builder *strings.Builder, row int) {
@@ -823,11 +1483,54 @@ func formatStatusEntry(entry statusEntry) string {
 	return label + " " + value
 }
 
+// parseStatusParts reads the renderer's status: its " | " separated parts
+// are the panel link, the colour mode, key=value pairs, band levels as
+// name value pairs, a script error and the app's mic=device, some of them
+// optional, so each part is told apart by what it holds.
+func parseStatusParts(raw string) []statusEntry {
+	var entries []statusEntry
+	for _, part := range strings.Split(raw, "|") {
+		part = strings.TrimSpace(part)
+		tokens := strings.Fields(part)
+		switch {
+		case part == "" || strings.Contains(part, "://"):
+			// the panel entry shows the link
+		case strings.Contains(tokens[0], "="):
+			entries = append(entries, parseKeyValuePart(part)...)
+		case len(tokens) == 1:
+			entries = append(entries, statusEntry{label: "MODE", value: part})
+		case isMetricPart(tokens):
+			entries = append(entries, parseMetricPart(part)...)
+		default:
+			entries = append(entries, statusEntry{label: "ERROR", value: part})
+		}
+	}
+	return entries
+}
+
+// isMetricPart reports whether tokens are name value pairs with numbers
+// for values.
+func isMetricPart(tokens []string) bool {
+	if len(tokens)%2 != 0 {
+		return false
+	}
+	for i := 1; i < len(tokens); i += 2 {
+		if _, err := strconv.ParseFloat(tokens[i], 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 func parseKeyValuePart(part string) []statusEntry {
 	tokens := strings.Fields(part)
 	entries := make([]statusEntry, 0, len(tokens))
 	for _, token := range tokens {
 		if !strings.Contains(token, "=") {
+			// the rest of a value with spaces, like a device name
+			if n := len(entries); n > 0 {
+				entries[n-1].value += " " + token
+			}
 			continue
 		}
 		segments := strings.SplitN(token, "=", 2)
@@ -857,6 +1560,16 @@ func parseMetricPart(part string) []statusEntry {
 	return entries
 }
 
+// panelLink is the panel's address, carrying the token when the panel needs
+// one so the link works as shown.
+func panelLink(token string) string {
+	link := detectPanelURL()
+	if token != "" {
+		link += "/?token=" + url.QueryEscape(token)
+	}
+	return link
+}
+
 func detectPanelURL() string {
 	if env := strings.TrimSpace(os.Getenv("GOLIZER_PANEL_URL")); env != "" {
 		return env
@@ -914,6 +1627,7 @@ func (a *App) SetParams(p params.Parameters) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a.params = p
+	a.blender.Reset()
 }
 
 // GetRenderer returns the renderer (thread-safe)
@@ -930,6 +1644,14 @@ func (a *App) GetFeatures() analyzer.Features {
 	return a.lastFeatures
 }
 
+// FeatureHistory returns a copy of the newest n analyzed frames, oldest
+// first; n <= 0 returns all that are kept (thread-safe)
+func (a *App) FeatureHistory(n int) []analyzer.Features {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.history.Last(n, nil)
+}
+
 // GetFPS returns last FPS (thread-safe)
 func (a *App) GetFPS() float64 {
 	a.mu.RLock()
@@ -940,6 +1662,8 @@ func (a *App) GetFPS() float64 {
 // ConfigGetter interface for accessing config values (matches web.AppInterface)
 type ConfigGetter interface {
 	NoiseFloor() float64
+	NoiseFloors() analyzer.NoiseFloors
+	Envelopes() analyzer.Envelopes
 	BufferSize() int
 	TargetFPS() float64
 	Quality() string
@@ -961,22 +1685,41 @@ type configWrapper struct {
 	cfg Config
 }
 
-func (c *configWrapper) NoiseFloor() float64           { return c.cfg.NoiseFloor }
-func (c *configWrapper) BufferSize() int               { return c.cfg.BufferSize }
-func (c *configWrapper) TargetFPS() float64            { return c.cfg.TargetFPS }
-func (c *configWrapper) Quality() string               { return c.cfg.Quality }
-func (c *configWrapper) Width() int                    { return c.cfg.Width }
-func (c *configWrapper) Height() int                   { return c.cfg.Height }
-func (c *configWrapper) AutoRandomize() bool           { return c.cfg.AutoRandomize }
-func (c *configWrapper) RandomInterval() time.Duration { return c.cfg.RandomInterval }
-func (c *configWrapper) ShowStatusBar() bool           { return c.cfg.ShowStatusBar }
+func (c *configWrapper) NoiseFloor() float64               { return c.cfg.NoiseFloor }
+func (c *configWrapper) NoiseFloors() analyzer.NoiseFloors { return c.cfg.NoiseFloors }
+func (c *configWrapper) Envelopes() analyzer.Envelopes     { return c.cfg.Envelopes }
+func (c *configWrapper) BufferSize() int                   { return c.cfg.BufferSize }
+func (c *configWrapper) TargetFPS() float64                { return c.cfg.TargetFPS }
+func (c *configWrapper) Quality() string                   { return c.cfg.Quality }
+func (c *configWrapper) Width() int                        { return c.cfg.Width }
+func (c *configWrapper) Height() int                       { return c.cfg.Height }
+func (c *configWrapper) AutoRandomize() bool               { return c.cfg.AutoRandomize }
+func (c *configWrapper) RandomInterval() time.Duration     { return c.cfg.RandomInterval }
+func (c *configWrapper) ShowStatusBar() bool               { return c.cfg.ShowStatusBar }
 
 // SetNoiseFloor updates noise floor (thread-safe)
 func (a *App) SetNoiseFloor(v float64) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	if a.cfg.NoiseFloor != v {
+		// moving the manual floor replaces any calibrated per-band floors
+		a.cfg.NoiseFloors = analyzer.NoiseFloors{}
+	}
 	a.cfg.NoiseFloor = v
-	// noise floor is used during analysis, no need to update analyzer
+}
+
+// SetEnvelope updates the attack/release of one band (thread-safe)
+func (a *App) SetEnvelope(band string, env analyzer.Envelope) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cfg.Envelopes.Set(band, env)
+}
+
+// SetInputGain updates the pre-analysis input gain (thread-safe)
+func (a *App) SetInputGain(v float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cfg.InputGain = v
 }
 
 // SetBufferSize updates buffer size (thread-safe)
@@ -984,7 +1727,7 @@ func (a *App) SetBufferSize(v int) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a.cfg.BufferSize = v
-	// note: buffer size change requires restart to take effect
+	// the open capture keeps its size until RestartAudio
 }
 
 // SetTargetFPS updates target FPS (thread-safe)
@@ -1016,7 +1759,7 @@ func (a *App) SetShowStatusBar(enabled bool) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	if a.windowMode {
+	if a.windowMode || a.headless {
 		enabled = false
 	}
 
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// TestSetParamsDuringStep sets params the way the web panel does while the
+// render loop steps and blends them; run with -race.
+func TestSetParamsDuringStep(t *testing.T) {
+	a, err := New(Config{
+		Backend:      "none",
+		DisableAudio: true,
+		FrameBlend:   100 * time.Millisecond,
+		PresetsPath:  filepath.Join(t.TempDir(), "presets.json"),
+		Log:          log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p := params.Defaults()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			p.Brightness = 1 + float64(i%10)/10
+			a.SetParams(p)
+			a.GetParams()
+		}
+	}()
+	for range 200 {
+		if err := a.step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+}
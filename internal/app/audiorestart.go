@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/guidoenr/golizer/internal/audio"
+)
+
+// AudioInfo describes the open sound card.
+type AudioInfo struct {
+	Device     string  `json:"device"`
+	SampleRate float64 `json:"sampleRate"`
+	BufferSize int     `json:"bufferSize"`
+}
+
+// audioRestart asks the analysis loop, which owns the capture and the
+// analyzer, to reopen the sound card.
+type audioRestart struct {
+	device string
+	reply  chan audioRestartResult
+}
+
+type audioRestartResult struct {
+	info AudioInfo
+	err  error
+}
+
+// RestartAudio reopens the sound card with the current buffer size, on
+// device unless it is empty, and rebuilds the analyzer for it. When the
+// card won't open the previous one is reopened so the show keeps its input
+// (thread-safe).
+func (a *App) RestartAudio(ctx context.Context, device string) (AudioInfo, error) {
+	if a.audioRestarts == nil {
+		return AudioInfo{}, errors.New("audio restart needs a sound card input")
+	}
+	req := audioRestart{device: device, reply: make(chan audioRestartResult, 1)}
+	select {
+	case a.audioRestarts <- req:
+	case <-ctx.Done():
+		return AudioInfo{}, ctx.Err()
+	}
+	select {
+	case res := <-req.reply:
+		return res.info, res.err
+	case <-ctx.Done():
+		return AudioInfo{}, ctx.Err()
+	}
+}
+
+// reopenAudio runs on the analysis loop.
+func (a *App) reopenAudio(device string) (AudioInfo, error) {
+	a.mu.RLock()
+	previous, bufferSize := a.cfg.DeviceName, a.cfg.BufferSize
+	a.mu.RUnlock()
+	if device == "" {
+		device = previous
+	}
+
+	a.captureMu.Lock()
+	defer a.captureMu.Unlock()
+	if a.capture != nil {
+		// most cards only open once, so the old stream goes first
+		_ = a.capture.Close()
+		a.capture = nil
+	}
+	capture, err := audio.NewCapture(audio.Config{DeviceName: device, BufferSize: bufferSize, Channels: 2})
+	if err != nil && device != previous {
+		var fallbackErr error
+		capture, fallbackErr = audio.NewCapture(audio.Config{DeviceName: previous, BufferSize: bufferSize, Channels: 2})
+		if fallbackErr == nil {
+			a.useCapture(capture, previous, bufferSize)
+			return AudioInfo{}, fmt.Errorf("%w (kept the previous device)", err)
+		}
+	}
+	if err != nil {
+		a.log.Printf("audio restart: %v (no input until the next restart)", err)
+		return AudioInfo{}, err
+	}
+	return a.useCapture(capture, device, bufferSize), nil
+}
+
+// useCapture installs a freshly opened capture and a matching analyzer.
+// The caller holds captureMu.
+func (a *App) useCapture(capture *audio.Capture, device string, bufferSize int) AudioInfo {
+	if a.relayOut != nil && capture.SampleRate() != a.analyzer.SampleRate() {
+		a.log.Printf("audio restart: sample rate is now %.0f Hz, pcm relay followers need a restart", capture.SampleRate())
+	}
+	a.capture = capture
+	a.analysisSamples = selectAnalysisWindow(bufferSize)
+	a.newAnalyzer(capture.SampleRate())
+
+	info := AudioInfo{Device: device, SampleRate: capture.SampleRate(), BufferSize: bufferSize}
+	if dev := capture.Device(); dev != nil {
+		info.Device = dev.Name
+	}
+	a.mu.Lock()
+	a.cfg.DeviceName = device
+	a.deviceLabel = info.Device
+	a.mu.Unlock()
+	a.log.Printf("audio capture restarted on \"%s\" @ %.0f Hz, buffer %d", info.Device, info.SampleRate, bufferSize)
+	return info
+}
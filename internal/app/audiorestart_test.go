@@ -0,0 +1,38 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRestartAudio(t *testing.T) {
+	a := &App{}
+	if _, err := a.RestartAudio(context.Background(), ""); err == nil {
+		t.Error("restarted without a sound card input")
+	}
+
+	// the analysis loop's side of the hand-off
+	a.audioRestarts = make(chan audioRestart)
+	go func() {
+		req := <-a.audioRestarts
+		req.reply <- audioRestartResult{info: AudioInfo{Device: req.device, SampleRate: 44100, BufferSize: 256}}
+		req = <-a.audioRestarts
+		req.reply <- audioRestartResult{err: errors.New("no such device")}
+	}()
+	info, err := a.RestartAudio(context.Background(), "hw:1")
+	if err != nil || info != (AudioInfo{Device: "hw:1", SampleRate: 44100, BufferSize: 256}) {
+		t.Errorf("restart: got %+v, %v", info, err)
+	}
+	if _, err := a.RestartAudio(context.Background(), "hw:9"); err == nil || err.Error() != "no such device" {
+		t.Errorf("failed restart: got %v", err)
+	}
+
+	// nobody takes the request: the caller's deadline ends the wait
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := a.RestartAudio(ctx, ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("stuck loop: got %v", err)
+	}
+}
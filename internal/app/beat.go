@@ -0,0 +1,98 @@
+package app
+
+import (
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+type beatEvent int
+
+const (
+	beatEventNone beatEvent = iota
+	beatEventPulse
+	beatEventSwap
+)
+
+// minTempoConfidence is how sure the tempo tracker must be before the
+// scheduler starts predicting beats on its own.
+const minTempoConfidence = 0.45
+
+// beatScheduler predicts the next beat from the tempo estimate and fires
+// visual events `lookahead` early, so that FFT window + render + terminal
+// latency lands the effect on the beat instead of a frame or two behind it.
+type beatScheduler struct {
+	lookahead time.Duration
+	swapEvery int
+	period    time.Duration
+	nextBeat  time.Time
+	count     int
+}
+
+func newBeatScheduler(lookahead time.Duration, swapEvery int) *beatScheduler {
+	if lookahead <= 0 {
+		return nil
+	}
+	if swapEvery < 0 {
+		swapEvery = 0
+	}
+	return &beatScheduler{lookahead: lookahead, swapEvery: swapEvery}
+}
+
+// Observe updates the beat period from the tempo estimate and nudges the
+// predicted phase towards detected onsets.
+func (s *beatScheduler) Observe(now time.Time, feat analyzer.Features) {
+	if s == nil {
+		return
+	}
+	if feat.Tempo <= 0 || feat.TempoConfidence < minTempoConfidence {
+		s.period = 0
+		s.nextBeat = time.Time{}
+		return
+	}
+	s.period = time.Duration(60.0 / feat.Tempo * float64(time.Second))
+	if !feat.Onset {
+		return
+	}
+
+	candidate := now.Add(s.period)
+	if s.nextBeat.IsZero() {
+		s.nextBeat = candidate
+		return
+	}
+	// wrap the phase error into [-period/2, period/2) and correct halfway so
+	// a single late onset doesn't yank the grid around
+	drift := candidate.Sub(s.nextBeat) % s.period
+	if drift >= s.period/2 {
+		drift -= s.period
+	} else if drift < -s.period/2 {
+		drift += s.period
+	}
+	s.nextBeat = s.nextBeat.Add(drift / 2)
+}
+
+// Due returns the event to fire at now, if the next predicted beat is within
+// the lookahead window.
+func (s *beatScheduler) Due(now time.Time) beatEvent {
+	if s == nil || s.period <= 0 || s.nextBeat.IsZero() {
+		return beatEventNone
+	}
+	if now.Before(s.nextBeat.Add(-s.lookahead)) {
+		return beatEventNone
+	}
+	if now.Sub(s.nextBeat) > s.period/2 {
+		// slept through beats (stall, resize): realign instead of firing a burst
+		for !s.nextBeat.After(now) {
+			s.nextBeat = s.nextBeat.Add(s.period)
+		}
+		if now.Before(s.nextBeat.Add(-s.lookahead)) {
+			return beatEventNone
+		}
+	}
+	s.nextBeat = s.nextBeat.Add(s.period)
+	s.count++
+	if s.swapEvery > 0 && s.count%s.swapEvery == 0 {
+		return beatEventSwap
+	}
+	return beatEventPulse
+}
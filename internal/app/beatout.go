@@ -0,0 +1,139 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/gpio"
+	"github.com/guidoenr/golizer/internal/midi"
+)
+
+// beatOutputs forwards drum hits and tempo to external gear: MIDI notes and
+// clock on a rawmidi port and/or a trigger pulse on a GPIO pin.
+type beatOutputs struct {
+	hits    *analyzer.HitDetector
+	out     *midi.Out
+	clock   *midi.Clock
+	channel int
+	notes   [3]uint8
+	pin     *gpio.Pin
+	pulse   time.Duration
+	log     *log.Logger
+	lastErr time.Time
+}
+
+// newBeatOutputs opens the configured ports. It returns nil when neither MIDI
+// nor GPIO output was requested.
+func newBeatOutputs(cfg Config, logger *log.Logger) (*beatOutputs, error) {
+	if cfg.MIDIOut == "" && cfg.GPIOPin <= 0 {
+		return nil, nil
+	}
+	b := &beatOutputs{
+		hits:    analyzer.NewHitDetector(),
+		channel: cfg.MIDIChannel,
+		notes:   cfg.MIDINotes,
+		pulse:   cfg.GPIOPulse,
+		log:     logger,
+	}
+	if b.channel < 1 || b.channel > 16 {
+		b.channel = 10
+	}
+	if b.pulse <= 0 {
+		b.pulse = 10 * time.Millisecond
+	}
+	if cfg.MIDIOut != "" {
+		out, err := midi.OpenOut(cfg.MIDIOut)
+		if err != nil {
+			return nil, err
+		}
+		b.out = out
+		if cfg.MIDIClock {
+			b.clock = midi.NewClock(out)
+		}
+		logger.Printf("midi out -> %s (channel %d)", out.Name(), b.channel)
+	}
+	if cfg.GPIOPin > 0 {
+		pin, err := gpio.Open(cfg.GPIOPin)
+		if err != nil {
+			b.Close()
+			return nil, err
+		}
+		b.pin = pin
+		logger.Printf("gpio pulse -> pin %d (%s)", cfg.GPIOPin, b.pulse)
+	}
+	return b, nil
+}
+
+// Run drives the MIDI clock until ctx is done.
+func (b *beatOutputs) Run(ctx context.Context) {
+	if b == nil || b.clock == nil {
+		return
+	}
+	b.clock.Run(ctx)
+}
+
+// Process classifies the frame's hits and sends them out.
+func (b *beatOutputs) Process(feat analyzer.Features, delta float64) {
+	if b == nil {
+		return
+	}
+	if b.clock != nil {
+		tempo := 0.0
+		if feat.TempoConfidence >= minTempoConfidence {
+			tempo = feat.Tempo
+		}
+		b.clock.SetTempo(tempo)
+	}
+
+	hits := b.hits.Detect(feat, delta)
+	if !hits.Any() {
+		return
+	}
+	if hits.Kick {
+		if b.clock != nil {
+			b.clock.Downbeat()
+		}
+		if b.pin != nil {
+			b.report(b.pin.Pulse(b.pulse))
+		}
+	}
+	if b.out == nil {
+		return
+	}
+	voices := [3]bool{hits.Kick, hits.Snare, hits.Hat}
+	for i, hit := range voices {
+		if hit {
+			b.report(b.out.Note(b.channel, b.notes[i], 100))
+		}
+	}
+}
+
+// report logs write errors at most once every few seconds (unplugged cable).
+func (b *beatOutputs) report(err error) {
+	if err == nil || time.Since(b.lastErr) < 5*time.Second {
+		return
+	}
+	b.lastErr = time.Now()
+	b.log.Printf("beat output: %v", err)
+}
+
+func (b *beatOutputs) Close() error {
+	if b == nil {
+		return nil
+	}
+	var firstErr error
+	if b.out != nil {
+		if err := b.out.Close(); err != nil {
+			firstErr = fmt.Errorf("midi: %w", err)
+		}
+	}
+	if b.pin != nil {
+		if err := b.pin.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("gpio: %w", err)
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/params"
+	"github.com/guidoenr/golizer/internal/render"
+)
+
+// benchWarmup frames are rendered before the clock starts on every combo,
+// so caches built on the first frames of a pattern don't count.
+const benchWarmup = 10
+
+// BenchQualities are the quality presets a benchmark runs by default.
+var BenchQualities = []string{"high", "balanced", "eco"}
+
+// BenchConfig picks what a benchmark renders. Empty lists mean all of them.
+type BenchConfig struct {
+	Width, Height int
+	Backend       render.Backend // ascii or none, nothing is drawn either way
+	Patterns      []string
+	Palettes      []string
+	Qualities     []string
+	Duration      time.Duration // per combo
+}
+
+// BenchResult is how one pattern, palette and quality combo did.
+type BenchResult struct {
+	Pattern, Palette, Quality string
+	Frames                    int
+	FPS                       float64
+	// P50, P95 and P99 are frame time percentiles.
+	P50, P95, P99, Max time.Duration
+}
+
+// Combos returns how many combos cfg runs.
+func (cfg BenchConfig) Combos() int {
+	return len(cfg.Patterns) * len(cfg.Palettes) * len(cfg.Qualities)
+}
+
+// Bench renders the synthetic generator's features off-screen through every
+// combo of cfg for cfg.Duration each, as fast as frames come, and hands each
+// result to report. It stops early when ctx is done.
+func Bench(ctx context.Context, cfg BenchConfig, report func(BenchResult)) error {
+	if cfg.Backend == "" {
+		cfg.Backend = render.BackendASCII
+	}
+	r, err := render.NewWithBackend(cfg.Backend, cfg.Width, cfg.Height, "default", "", "", "", true, true)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	cfg = cfg.WithDefaults()
+	for _, quality := range cfg.Qualities {
+		for _, pattern := range cfg.Patterns {
+			for _, palette := range cfg.Palettes {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				r.SetQuality(quality)
+				r.Configure(palette, pattern, "chromatic", true)
+				result := benchCombo(ctx, r, cfg.Duration)
+				result.Pattern, result.Palette, result.Quality = pattern, palette, quality
+				report(result)
+			}
+		}
+	}
+	return nil
+}
+
+// WithDefaults fills the empty lists of cfg with everything there is.
+func (cfg BenchConfig) WithDefaults() BenchConfig {
+	if len(cfg.Patterns) == 0 {
+		cfg.Patterns = render.PatternNames()
+	}
+	if len(cfg.Palettes) == 0 {
+		cfg.Palettes = render.PaletteNames()
+	}
+	if len(cfg.Qualities) == 0 {
+		cfg.Qualities = BenchQualities
+	}
+	return cfg
+}
+
+// Check reports the first name in cfg that a benchmark can't run.
+func (cfg BenchConfig) Check() error {
+	patterns := render.PatternNames()
+	for _, name := range cfg.Patterns {
+		if !slices.Contains(patterns, name) {
+			return fmt.Errorf("unknown pattern %q", name)
+		}
+	}
+	palettes := render.PaletteNames()
+	for _, name := range cfg.Palettes {
+		if !slices.Contains(palettes, name) {
+			return fmt.Errorf("unknown palette %q", name)
+		}
+	}
+	for _, name := range cfg.Qualities {
+		if !slices.Contains(BenchQualities, name) {
+			return fmt.Errorf("unknown quality %q (high, balanced, eco)", name)
+		}
+	}
+	return nil
+}
+
+// benchCombo renders back to back for d and measures every frame.
+func benchCombo(ctx context.Context, r *render.Renderer, d time.Duration) BenchResult {
+	fake := newFakeGenerator()
+	p := params.Defaults()
+	delta := 1.0 / 60
+	var times []time.Duration
+	var start time.Time
+	for frame := 0; ; frame++ {
+		if frame == benchWarmup {
+			start = time.Now()
+		}
+		if frame > benchWarmup && (time.Since(start) >= d || ctx.Err() != nil) {
+			break
+		}
+		feat := fake.Next(delta)
+		p.ApplyFeatures(feat, delta)
+		p.UpdateTime(delta)
+		began := time.Now()
+		r.Render(p, feat, 1/delta)
+		took := time.Since(began)
+		delta = max(took.Seconds(), 1e-6)
+		if frame >= benchWarmup {
+			times = append(times, took)
+		}
+	}
+	elapsed := time.Since(start)
+	slices.Sort(times)
+	return BenchResult{
+		Frames: len(times),
+		FPS:    float64(len(times)) / elapsed.Seconds(),
+		P50:    percentile(times, 0.50),
+		P95:    percentile(times, 0.95),
+		P99:    percentile(times, 0.99),
+		Max:    times[len(times)-1],
+	}
+}
+
+// percentile returns the q quantile (0-1) of the sorted durations, nearest
+// rank.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
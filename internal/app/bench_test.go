@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+	cases := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0, time.Millisecond},
+		{0.5, 50 * time.Millisecond},
+		{0.95, 95 * time.Millisecond},
+		{0.999, 100 * time.Millisecond},
+		{1, 100 * time.Millisecond},
+	}
+	for _, c := range cases {
+		if got := percentile(sorted, c.q); got != c.want {
+			t.Errorf("q %v: got %v, want %v", c.q, got, c.want)
+		}
+	}
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Errorf("empty: got %v", got)
+	}
+}
+
+func TestBenchConfigCheck(t *testing.T) {
+	cases := []struct {
+		cfg BenchConfig
+		ok  bool
+	}{
+		{BenchConfig{}, true},
+		{BenchConfig{Patterns: []string{"tunnel"}, Palettes: []string{"block"}, Qualities: []string{"eco"}}, true},
+		{BenchConfig{Patterns: []string{"tunel"}}, false},
+		{BenchConfig{Palettes: []string{"neon-ish"}}, false},
+		{BenchConfig{Qualities: []string{"ultra"}}, false},
+	}
+	for _, c := range cases {
+		if err := c.cfg.Check(); (err == nil) != c.ok {
+			t.Errorf("%+v: got %v, want ok %v", c.cfg, err, c.ok)
+		}
+	}
+	if n := (BenchConfig{}).WithDefaults().Combos(); n == 0 {
+		t.Error("defaults run nothing")
+	}
+}
+
+func TestBench(t *testing.T) {
+	cfg := BenchConfig{
+		Width: 40, Height: 12,
+		Patterns:  []string{"tunnel", "ripple"},
+		Palettes:  []string{"block"},
+		Qualities: []string{"eco"},
+		Duration:  20 * time.Millisecond,
+	}
+	var results []BenchResult
+	if err := Bench(context.Background(), cfg, func(r BenchResult) { results = append(results, r) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != cfg.Combos() {
+		t.Fatalf("%d results, want %d", len(results), cfg.Combos())
+	}
+	for i, r := range results {
+		if r.Pattern != cfg.Patterns[i] || r.Palette != "block" || r.Quality != "eco" {
+			t.Errorf("result %d is %s/%s/%s", i, r.Pattern, r.Palette, r.Quality)
+		}
+		if r.Frames == 0 || r.FPS <= 0 || r.P50 > r.P95 || r.P95 > r.P99 || r.P99 > r.Max {
+			t.Errorf("%s: %+v", r.Pattern, r)
+		}
+	}
+
+	// a cancelled run stops before the next combo
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Bench(ctx, cfg, func(BenchResult) { t.Error("ran a combo after cancel") }); err != context.Canceled {
+		t.Errorf("cancelled: got %v", err)
+	}
+}
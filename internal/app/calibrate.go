@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+// calibration is an in-flight noise measurement fed from the analysis loop.
+type calibration struct {
+	calibrator *analyzer.Calibrator
+	until      time.Time
+	done       chan analyzer.NoiseFloors
+}
+
+// Calibrate listens to the room for d, measures per-band noise and installs
+// the result as the active noise floors. The room should be quiet meanwhile.
+func (a *App) Calibrate(ctx context.Context, d time.Duration) (analyzer.NoiseFloors, error) {
+	if a.analyzer == nil {
+		return analyzer.NoiseFloors{}, errors.New("calibration needs live audio input")
+	}
+	if d <= 0 {
+		d = 5 * time.Second
+	}
+
+	a.mu.Lock()
+	if a.calibration != nil {
+		a.mu.Unlock()
+		return analyzer.NoiseFloors{}, errors.New("calibration already running")
+	}
+	session := &calibration{
+		calibrator: analyzer.NewCalibrator(),
+		until:      time.Now().Add(d),
+		done:       make(chan analyzer.NoiseFloors, 1),
+	}
+	a.calibration = session
+	a.mu.Unlock()
+
+	select {
+	case floors := <-session.done:
+		a.log.Printf("noise floors calibrated: %+v", floors)
+		return floors, nil
+	case <-ctx.Done():
+		a.mu.Lock()
+		if a.calibration == session {
+			a.calibration = nil
+		}
+		a.mu.Unlock()
+		return analyzer.NoiseFloors{}, ctx.Err()
+	}
+}
+
+// recordCalibration feeds ungated features into a running calibration and
+// finishes it once the listening window is over.
+func (a *App) recordCalibration(now time.Time, raw analyzer.Features) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	session := a.calibration
+	if session == nil {
+		return
+	}
+	session.calibrator.Add(raw)
+	if now.Before(session.until) {
+		return
+	}
+	floors := session.calibrator.Floors()
+	a.cfg.NoiseFloors = floors
+	a.calibration = nil
+	session.done <- floors
+}
+
+// gateFloors returns the calibrated floors, falling back to the single
+// --noise-floor value when no calibration was stored.
+func (a *App) gateFloors() analyzer.NoiseFloors {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if !a.cfg.NoiseFloors.IsZero() {
+		return a.cfg.NoiseFloors
+	}
+	return analyzer.UniformFloors(a.cfg.NoiseFloor)
+}
+
+// SetNoiseFloors installs per-band noise floors (thread-safe)
+func (a *App) SetNoiseFloors(floors analyzer.NoiseFloors) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cfg.NoiseFloors = floors
+}
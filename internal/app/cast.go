@@ -0,0 +1,92 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// castHeader is the first line of an asciinema v2 cast.
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// castRecorder writes the terminal output of every frame as an asciinema v2
+// cast, so a session replays (asciinema play, the web player) byte for byte
+// as it was drawn.
+type castRecorder struct {
+	file   *os.File
+	buf    *bufio.Writer
+	enc    *json.Encoder
+	start  time.Time
+	width  int
+	height int
+}
+
+func newCastRecorder(path string) (*castRecorder, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(f)
+	return &castRecorder{file: f, buf: buf, enc: json.NewEncoder(buf)}, nil
+}
+
+// Output records data written to the terminal at now. The first call
+// writes the header with the terminal size and clears the screen, as Run
+// does on the real one.
+func (c *castRecorder) Output(now time.Time, width, height int, data string) error {
+	if c == nil || data == "" {
+		return nil
+	}
+	if c.start.IsZero() {
+		c.start = now
+		c.width, c.height = width, height
+		header := castHeader{
+			Version:   2,
+			Width:     width,
+			Height:    height,
+			Timestamp: now.Unix(),
+			Title:     "golizer",
+			Env:       map[string]string{"TERM": os.Getenv("TERM")},
+		}
+		if err := c.enc.Encode(header); err != nil {
+			return err
+		}
+		if err := c.event(now, "o", "\x1b[2J\x1b[H\x1b[?25l"); err != nil {
+			return err
+		}
+	}
+	if width != c.width || height != c.height {
+		c.width, c.height = width, height
+		if err := c.event(now, "r", fmt.Sprintf("%dx%d", width, height)); err != nil {
+			return err
+		}
+	}
+	return c.event(now, "o", data)
+}
+
+func (c *castRecorder) event(now time.Time, kind, data string) error {
+	return c.enc.Encode([]any{now.Sub(c.start).Seconds(), kind, data})
+}
+
+func (c *castRecorder) Close() error {
+	if c == nil {
+		return nil
+	}
+	err := c.buf.Flush()
+	if cerr := c.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
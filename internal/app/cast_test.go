@@ -0,0 +1,95 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCastRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.cast")
+	c, err := newCastRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1700000000, 0)
+	writes := []struct {
+		at            time.Duration
+		width, height int
+		data          string
+	}{
+		{0, 80, 24, "frame one"},
+		{500 * time.Millisecond, 80, 24, ""}, // nothing drawn, nothing recorded
+		{time.Second, 100, 30, "frame two"},
+		{1500 * time.Millisecond, 100, 30, "frame three"},
+	}
+	for _, w := range writes {
+		if err := c.Output(start.Add(w.at), w.width, w.height, w.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	if !sc.Scan() {
+		t.Fatal("empty cast")
+	}
+	var header castHeader
+	if err := json.Unmarshal(sc.Bytes(), &header); err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != 2 || header.Width != 80 || header.Height != 24 || header.Timestamp != start.Unix() {
+		t.Errorf("header: got %+v", header)
+	}
+
+	want := []struct {
+		at         float64
+		kind, data string
+	}{
+		{0, "o", "\x1b[2J\x1b[H\x1b[?25l"},
+		{0, "o", "frame one"},
+		{1, "r", "100x30"},
+		{1, "o", "frame two"},
+		{1.5, "o", "frame three"},
+	}
+	var i int
+	for ; sc.Scan(); i++ {
+		var ev []any
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			t.Fatal(err)
+		}
+		if i >= len(want) {
+			t.Fatalf("extra event %v", ev)
+		}
+		w := want[i]
+		if len(ev) != 3 || ev[0] != w.at || ev[1] != w.kind || ev[2] != w.data {
+			t.Errorf("event %d: got %q, want [%v %q %q]", i, ev, w.at, w.kind, w.data)
+		}
+	}
+	if i != len(want) {
+		t.Errorf("got %d events, want %d", i, len(want))
+	}
+}
+
+func TestCastRecorderDisabled(t *testing.T) {
+	c, err := newCastRecorder("")
+	if c != nil || err != nil {
+		t.Fatalf("got %v, %v, want nil recorder", c, err)
+	}
+	if err := c.Output(time.Now(), 80, 24, "x"); err != nil {
+		t.Errorf("nil Output: %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("nil Close: %v", err)
+	}
+}
@@ -13,8 +13,12 @@ type fakeGenerator struct {
 	phaseBass float64
 	phaseMid  float64
 	phaseHigh float64
+	beatClock float64
 }
 
+// fakeTempo is the steady BPM the synthetic generator reports.
+const fakeTempo = 120.0
+
 func newFakeGenerator() *fakeGenerator {
 	return &fakeGenerator{
 		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
@@ -41,14 +45,56 @@ func (f *fakeGenerator) Next(delta float64) analyzer.Features {
 
 	isDrop := f.rng.Float64() < 0.005
 
-	return analyzer.Features{
-		Bass:         bass,
-		Mid:          mid,
-		Treble:       treble,
-		Overall:      (bass + mid + treble) / 3,
-		BeatStrength: clamp01(beat + f.rng.Float64()*0.1),
-		IsDrop:       isDrop,
+	onset := false
+	f.beatClock += delta
+	if period := 60.0 / fakeTempo; f.beatClock >= period {
+		f.beatClock -= period
+		onset = true
+	}
+
+	feat := analyzer.Features{
+		Sub:             clamp01(bass*0.8 + f.rng.Float64()*0.05),
+		Bass:            bass,
+		LowMid:          clamp01(mid*1.1 - 0.05),
+		HighMid:         clamp01(mid*0.9 + treble*0.1),
+		Mid:             mid,
+		Treble:          treble,
+		Overall:         (bass + mid + treble) / 3,
+		Harmonic:        clamp01(0.3 + 0.25*math.Sin(f.phaseMid*0.25)),
+		Percussive:      clamp01(beat * 0.8),
+		BeatStrength:    clamp01(beat + f.rng.Float64()*0.1),
+		IsDrop:          isDrop,
+		Onset:           onset,
+		Tempo:           fakeTempo,
+		TempoConfidence: 1,
+		Level:           0.05 + 0.25*(bass+mid+treble)/3,
+		Peak:            clamp01(0.1 + 0.5*(bass+mid+treble)/3 + beat*0.2),
+	}
+	// a spectrum with a bump per band, each swaying at its own phase
+	for k := range feat.Spectrum {
+		pos := float64(k) / (analyzer.SpectrumBands - 1)
+		level := bass*math.Exp(-pos*pos*18) +
+			mid*0.8*math.Exp(-(pos-0.5)*(pos-0.5)*20) +
+			treble*0.6*math.Exp(-(pos-0.85)*(pos-0.85)*30)
+		level *= 0.75 + 0.25*math.Sin(f.phaseHigh*1.7+float64(k)*0.9)
+		feat.Spectrum[k] = clamp01(level + f.rng.Float64()*0.05)
+	}
+	// a kick-like low wave with the mids and treble riding on it
+	for j := range feat.Waveform {
+		phase := 2 * math.Pi * float64(j) / analyzer.WaveformSamples
+		v := bass*math.Sin(2*phase) + mid*0.4*math.Sin(7*phase+f.phaseMid) + treble*0.15*math.Sin(23*phase+f.phaseHigh)
+		feat.Waveform[j] = math.Max(-1, math.Min(1, v+(f.rng.Float64()-0.5)*0.05))
+	}
+	// a mostly centred mix: the side is a smaller, phase-shifted copy
+	width := 0.3 + 0.2*math.Sin(f.phaseMid*0.3)
+	for j := range feat.Goniometer {
+		phase := 2 * math.Pi * float64(j) / analyzer.GoniometerPoints
+		m := bass*math.Sin(2*phase) + mid*0.4*math.Sin(7*phase+f.phaseMid)
+		s := width * (bass*math.Sin(2*phase+1.2) + treble*0.5*math.Sin(19*phase+f.phaseHigh))
+		feat.Goniometer[j] = [2]float64{math.Max(-1, math.Min(1, s)), math.Max(-1, math.Min(1, m))}
 	}
+	feat.Correlation = 1 - 2*width*width
+	return feat
 }
 
 func clamp01(v float64) float64 {
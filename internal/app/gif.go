@@ -0,0 +1,48 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrGIFDisabled is returned by WriteGIF when no frame buffer is kept.
+var ErrGIFDisabled = errors.New("gif buffer disabled")
+
+// WriteGIF encodes the last d of rendered frames (the whole buffer when
+// d <= 0) as an animated GIF (thread-safe).
+func (a *App) WriteGIF(w io.Writer, d time.Duration) error {
+	if a.clips == nil {
+		return ErrGIFDisabled
+	}
+	return a.clips.WriteGIF(w, d)
+}
+
+// SaveGIF writes the whole frame buffer to a timestamped file in the
+// capture directory and returns its path.
+func (a *App) SaveGIF(now time.Time) (string, error) {
+	if a.clips == nil {
+		return "", ErrGIFDisabled
+	}
+	dir := a.cfg.CaptureDir
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, now.Format("golizer-20060102-150405.gif"))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := a.clips.WriteGIF(f, 0); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", fmt.Errorf("encode %s: %w", path, err)
+	}
+	return path, f.Close()
+}
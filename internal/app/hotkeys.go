@@ -0,0 +1,80 @@
+package app
+
+import (
+	"math"
+
+	"github.com/eiannone/keyboard"
+	"github.com/guidoenr/golizer/internal/params"
+	"github.com/guidoenr/golizer/internal/render"
+)
+
+// brightnessStep is how far + and - (and the menu) move the brightness.
+const brightnessStep = 0.1
+
+// cyclePattern switches to the pattern dir steps away in the list.
+func (a *App) cyclePattern(dir int) {
+	r := a.renderer
+	r.Configure(r.PaletteName(), cycleName(r.PatternNames(), r.PatternName(), dir), r.ColorModeName(), r.ColorOnAudio())
+}
+
+// cyclePalette switches to the palette dir steps away in the list.
+func (a *App) cyclePalette(dir int) {
+	r := a.renderer
+	r.Configure(cycleName(render.PaletteNames(), r.PaletteName(), dir), r.PatternName(), r.ColorModeName(), r.ColorOnAudio())
+}
+
+// nudgeBrightness moves the brightness a step up (dir 1) or down (dir -1),
+// within its range.
+func (a *App) nudgeBrightness(dir int) {
+	p := a.GetParams()
+	span := params.Ranges()["Brightness"]
+	p.Brightness = math.Max(span.Min, math.Min(span.Max, p.Brightness+float64(dir)*brightnessStep))
+	a.SetParams(p)
+}
+
+// toggleAutoRandomize turns auto-randomize on or off (thread-safe).
+func (a *App) toggleAutoRandomize() {
+	a.mu.Lock()
+	a.autoRandomize = !a.autoRandomize
+	a.cfg.AutoRandomize = a.autoRandomize
+	on := a.autoRandomize
+	a.mu.Unlock()
+	a.log.Printf("auto-randomize: %s", onOff(on))
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
+// windowKey translates a key pressed in the SDL or GL window to what the
+// terminal keyboard reports for it.
+func windowKey(k render.WindowKey) (rune, keyboard.Key) {
+	switch k.Name {
+	case "esc":
+		return 0, keyboard.KeyEsc
+	case "up":
+		return 0, keyboard.KeyArrowUp
+	case "down":
+		return 0, keyboard.KeyArrowDown
+	case "left":
+		return 0, keyboard.KeyArrowLeft
+	case "right":
+		return 0, keyboard.KeyArrowRight
+	}
+	if k.Char == ' ' {
+		return 0, keyboard.KeySpace
+	}
+	return k.Char, 0
+}
+
+// keyEvent queues evt for the main loop, dropping it when the loop is
+// behind.
+func keyEvent(events chan<- inputEvent, evt inputEvent) {
+	select {
+	case events <- evt:
+	default:
+	}
+}
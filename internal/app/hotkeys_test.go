@@ -0,0 +1,133 @@
+package app
+
+import (
+	"context"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/eiannone/keyboard"
+	"github.com/guidoenr/golizer/internal/params"
+	"github.com/guidoenr/golizer/internal/render"
+)
+
+func TestWindowKey(t *testing.T) {
+	cases := []struct {
+		key  render.WindowKey
+		char rune
+		code keyboard.Key
+	}{
+		{render.WindowKey{Name: "esc"}, 0, keyboard.KeyEsc},
+		{render.WindowKey{Name: "left"}, 0, keyboard.KeyArrowLeft},
+		{render.WindowKey{Char: ' '}, 0, keyboard.KeySpace},
+		{render.WindowKey{Char: ']'}, ']', 0},
+		{render.WindowKey{Char: 'R'}, 'R', 0},
+	}
+	for _, c := range cases {
+		if char, code := windowKey(c.key); char != c.char || code != c.code {
+			t.Errorf("%+v: got %q %v, want %q %v", c.key, char, code, c.char, c.code)
+		}
+	}
+}
+
+func TestHandleKey(t *testing.T) {
+	a, err := New(Config{
+		Backend:      "none",
+		DisableAudio: true,
+		PresetsPath:  filepath.Join(t.TempDir(), "presets.json"),
+		Log:          log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	events := make(chan inputEvent, 1)
+	for _, c := range []struct {
+		char rune
+		key  keyboard.Key
+		want inputEvent
+	}{
+		{']', 0, inputEventPatternNext},
+		{'[', 0, inputEventPatternPrev},
+		{'}', 0, inputEventPaletteNext},
+		{'{', 0, inputEventPalettePrev},
+		{'=', 0, inputEventBrighter},
+		{'-', 0, inputEventDimmer},
+		{0, keyboard.KeyArrowDown, inputEventMenuDown},
+		{'3', 0, inputEventPreset + 2},
+	} {
+		if a.handleKey(context.Background(), events, c.char, c.key) {
+			t.Errorf("%q quits", c.char)
+		}
+		if got := <-events; got != c.want {
+			t.Errorf("%q %v: queued %v, want %v", c.char, c.key, got, c.want)
+		}
+	}
+
+	// a full queue drops keys instead of blocking the keyboard
+	a.handleKey(context.Background(), events, ']', 0)
+	a.handleKey(context.Background(), events, '[', 0)
+	if got := <-events; got != inputEventPatternNext || len(events) != 0 {
+		t.Errorf("full queue: got %v and %d more", got, len(events))
+	}
+
+	auto := a.cfg.AutoRandomize
+	a.handleKey(context.Background(), events, 'a', 0)
+	if a.cfg.AutoRandomize == auto {
+		t.Error("a did not toggle auto-randomize")
+	}
+
+	for _, c := range []struct {
+		char rune
+		key  keyboard.Key
+	}{{'q', 0}, {'Q', 0}, {0, keyboard.KeyEsc}, {0, keyboard.KeyCtrlC}} {
+		if !a.handleKey(context.Background(), events, c.char, c.key) {
+			t.Errorf("%q %v does not quit", c.char, c.key)
+		}
+	}
+}
+
+func TestHotkeyActions(t *testing.T) {
+	a, err := New(Config{
+		Backend:      "none",
+		DisableAudio: true,
+		PresetsPath:  filepath.Join(t.TempDir(), "presets.json"),
+		Log:          log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	pattern := a.renderer.PatternName()
+	a.cyclePattern(1)
+	if a.renderer.PatternName() == pattern {
+		t.Error("] kept the pattern")
+	}
+	a.cyclePattern(-1)
+	if a.renderer.PatternName() != pattern {
+		t.Errorf("[ after ] went to %q, want %q", a.renderer.PatternName(), pattern)
+	}
+	palette := a.renderer.PaletteName()
+	a.cyclePalette(-1)
+	a.cyclePalette(1)
+	if a.renderer.PaletteName() != palette {
+		t.Errorf("{ then } went to %q, want %q", a.renderer.PaletteName(), palette)
+	}
+
+	span := params.Ranges()["Brightness"]
+	for range 100 {
+		a.nudgeBrightness(1)
+	}
+	if got := a.GetParams().Brightness; got != span.Max {
+		t.Errorf("brightness %v after many +, want the top %v", got, span.Max)
+	}
+	for range 100 {
+		a.nudgeBrightness(-1)
+	}
+	if got := a.GetParams().Brightness; got != span.Min {
+		t.Errorf("brightness %v after many -, want the bottom %v", got, span.Min)
+	}
+}
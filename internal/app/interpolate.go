@@ -0,0 +1,51 @@
+package app
+
+import (
+	"math"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// frameBlender smooths parameter state between rendered frames so backends
+// that can only present 15-20 fps (LED strips, SSH terminals, frame stride)
+// still see continuous motion instead of the jumps between skipped steps.
+// It isn't safe for concurrent use; the app calls it under its mutex.
+type frameBlender struct {
+	window float64
+	state  params.Parameters
+	primed bool
+}
+
+func newFrameBlender(window time.Duration) *frameBlender {
+	if window <= 0 {
+		return nil
+	}
+	return &frameBlender{window: window.Seconds()}
+}
+
+// Push feeds the latest analysis-side parameters and returns the blended
+// state to render with. Time is never lagged so pattern motion keeps its pace.
+func (b *frameBlender) Push(target params.Parameters, delta float64) params.Parameters {
+	if b == nil {
+		return target
+	}
+	if !b.primed || delta <= 0 {
+		b.state = target
+		b.primed = true
+		return b.state
+	}
+	alpha := 1 - math.Exp(-delta/b.window)
+	b.state = params.Lerp(b.state, target, alpha)
+	b.state.Time = target.Time
+	return b.state
+}
+
+// Reset drops the blended state so the next frame snaps to the target
+// (used after manual parameter edits).
+func (b *frameBlender) Reset() {
+	if b == nil {
+		return
+	}
+	b.primed = false
+}
@@ -0,0 +1,222 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eiannone/keyboard"
+	"github.com/guidoenr/golizer/internal/audio"
+)
+
+const (
+	// DefaultKioskChord is the key sequence that still quits in kiosk mode.
+	DefaultKioskChord = "ctrl+x ctrl+x q"
+
+	kioskChordWindow = 3 * time.Second
+	kioskMinBackoff  = time.Second
+	kioskMaxBackoff  = 30 * time.Second
+)
+
+type chordKey struct {
+	char rune
+	key  keyboard.Key
+}
+
+// parseKioskChord reads a space separated key sequence such as
+// "ctrl+x ctrl+x q". Tokens are single characters, ctrl+<letter> or esc.
+func parseKioskChord(spec string) ([]chordKey, error) {
+	fields := strings.Fields(strings.ToLower(spec))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty chord")
+	}
+	keys := make([]chordKey, 0, len(fields))
+	for _, field := range fields {
+		switch {
+		case field == "esc":
+			keys = append(keys, chordKey{key: keyboard.KeyEsc})
+		case strings.HasPrefix(field, "ctrl+") && len(field) == len("ctrl+")+1:
+			letter := field[len(field)-1]
+			if letter < 'a' || letter > 'z' {
+				return nil, fmt.Errorf("invalid chord key %q", field)
+			}
+			keys = append(keys, chordKey{key: keyboard.KeyCtrlA + keyboard.Key(letter-'a')})
+		case len([]rune(field)) == 1:
+			keys = append(keys, chordKey{char: []rune(field)[0]})
+		default:
+			return nil, fmt.Errorf("invalid chord key %q", field)
+		}
+	}
+	return keys, nil
+}
+
+// kioskGuard keeps an unattended install running: only the secret chord
+// quits, and failing subsystems are restarted with exponential backoff
+// instead of taking the process down.
+type kioskGuard struct {
+	chord     []chordKey
+	pos       int
+	lastKey   time.Time
+	backoff   time.Duration
+	retryAt   time.Time
+	audioDown bool
+	// capture reopening backs off on its own: frames go on without it
+	audioBackoff time.Duration
+	audioRetryAt time.Time
+}
+
+// newKioskGuard returns nil when kiosk mode is off.
+func newKioskGuard(enabled bool, chord string) (*kioskGuard, error) {
+	if !enabled {
+		return nil, nil
+	}
+	if chord == "" {
+		chord = DefaultKioskChord
+	}
+	keys, err := parseKioskChord(chord)
+	if err != nil {
+		return nil, err
+	}
+	return &kioskGuard{chord: keys}, nil
+}
+
+// Key feeds one key press and reports whether the chord was completed.
+func (k *kioskGuard) Key(now time.Time, char rune, key keyboard.Key) bool {
+	if k.pos > 0 && now.Sub(k.lastKey) > kioskChordWindow {
+		k.pos = 0
+	}
+	k.lastKey = now
+	pressed := chordKey{char: char, key: key}
+	if pressed != k.chord[k.pos] {
+		k.pos = 0
+		if pressed != k.chord[0] {
+			return false
+		}
+	}
+	k.pos++
+	if k.pos == len(k.chord) {
+		k.pos = 0
+		return true
+	}
+	return false
+}
+
+// Ready reports whether the app may run a frame, false while backing off
+// after a failure.
+func (k *kioskGuard) Ready(now time.Time) bool {
+	return now.After(k.retryAt)
+}
+
+// Recovered resets the backoff after a clean frame.
+func (k *kioskGuard) Recovered() {
+	k.backoff = 0
+}
+
+// Failed schedules the next retry and returns the wait.
+func (k *kioskGuard) Failed(now time.Time) time.Duration {
+	k.backoff = nextBackoff(k.backoff)
+	k.retryAt = now.Add(k.backoff)
+	return k.backoff
+}
+
+// AudioReady reports whether capture may be reopened now.
+func (k *kioskGuard) AudioReady(now time.Time) bool {
+	return !now.Before(k.audioRetryAt)
+}
+
+// AudioFailed schedules the next capture reopen and returns the wait.
+func (k *kioskGuard) AudioFailed(now time.Time) time.Duration {
+	k.audioBackoff = nextBackoff(k.audioBackoff)
+	k.audioRetryAt = now.Add(k.audioBackoff)
+	return k.audioBackoff
+}
+
+// nextBackoff doubles a wait, from kioskMinBackoff up to kioskMaxBackoff.
+func nextBackoff(d time.Duration) time.Duration {
+	if d == 0 {
+		return kioskMinBackoff
+	}
+	return min(d*2, kioskMaxBackoff)
+}
+
+// kioskStep runs one frame, turning panics into errors and restarting the
+// renderer and audio capture when the frame fails.
+func (a *App) kioskStep() {
+	now := time.Now()
+	if !a.kiosk.Ready(now) {
+		return
+	}
+	if a.analysisFailed.Swap(false) {
+		a.restartCapture()
+	}
+	if a.kiosk.audioDown {
+		a.reopenCapture()
+	}
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return a.step()
+	}()
+	if err == nil {
+		a.kiosk.Recovered()
+		return
+	}
+	if errors.Is(err, errReplayDone) {
+		// exhibits loop the recording instead of quitting
+		a.replay.Rewind(now)
+		return
+	}
+	wait := a.kiosk.Failed(now)
+	a.log.Printf("kiosk: frame failed (%v), restarting subsystems, next try in %s", err, wait)
+	a.restartSubsystems()
+}
+
+func (a *App) restartSubsystems() {
+	if err := a.renderer.Restart(); err != nil {
+		a.log.Printf("kiosk: renderer restart: %v", err)
+	}
+	a.prevLines = nil
+	a.cells.reset()
+	a.restartCapture()
+}
+
+func (a *App) restartCapture() {
+	a.captureMu.Lock()
+	if a.capture == nil {
+		a.captureMu.Unlock()
+		return
+	}
+	_ = a.capture.Close()
+	a.capture = nil
+	a.captureMu.Unlock()
+	a.kiosk.audioDown = true
+	a.reopenCapture()
+}
+
+// reopenCapture tries to open the sound card again, at most as often as
+// the audio backoff allows.
+func (a *App) reopenCapture() {
+	now := time.Now()
+	if !a.kiosk.AudioReady(now) {
+		return
+	}
+	capture, err := audio.NewCapture(audio.Config{
+		DeviceName: a.cfg.DeviceName,
+		BufferSize: a.cfg.BufferSize,
+		Channels:   2,
+	})
+	if err != nil {
+		wait := a.kiosk.AudioFailed(now)
+		a.log.Printf("kiosk: audio restart: %v, next try in %s", err, wait)
+		return
+	}
+	a.captureMu.Lock()
+	a.capture = capture
+	a.captureMu.Unlock()
+	a.kiosk.audioDown = false
+	a.kiosk.audioBackoff = 0
+}
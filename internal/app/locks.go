@@ -0,0 +1,76 @@
+package app
+
+import "strings"
+
+// RandomLocks keeps parts of the look fixed while randomize (the R key,
+// auto-randomize) changes the rest, e.g. a new pattern every few seconds
+// in the palette picked for the night.
+type RandomLocks struct {
+	Pattern   bool `json:"pattern"`
+	Palette   bool `json:"palette"`
+	ColorMode bool `json:"colorMode"`
+}
+
+// String lists the locked parts joined with "+", empty when none are.
+func (l RandomLocks) String() string {
+	var parts []string
+	if l.Pattern {
+		parts = append(parts, "pattern")
+	}
+	if l.Palette {
+		parts = append(parts, "palette")
+	}
+	if l.ColorMode {
+		parts = append(parts, "color")
+	}
+	return strings.Join(parts, "+")
+}
+
+// RandomWeights make randomize pick some patterns, palettes and color
+// modes more often than others: a name weighs 1 unless listed, 2 comes up
+// twice as often and 0 never.
+type RandomWeights struct {
+	Patterns   map[string]float64
+	Palettes   map[string]float64
+	ColorModes map[string]float64
+}
+
+// RandomLocks returns what randomize keeps (thread-safe).
+func (a *App) RandomLocks() RandomLocks {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.locks
+}
+
+// SetRandomLocks sets what randomize keeps (thread-safe).
+func (a *App) SetRandomLocks(l RandomLocks) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.locks = l
+}
+
+// toggleLock flips one lock, picked by the hotkey that toggles it, and logs
+// the new state.
+func (a *App) toggleLock(key rune) {
+	a.mu.Lock()
+	var name string
+	var locked bool
+	switch key {
+	case 'l':
+		a.locks.Pattern = !a.locks.Pattern
+		name, locked = "pattern", a.locks.Pattern
+	case 'p':
+		a.locks.Palette = !a.locks.Palette
+		name, locked = "palette", a.locks.Palette
+	case 'c':
+		a.locks.ColorMode = !a.locks.ColorMode
+		name, locked = "color mode", a.locks.ColorMode
+	}
+	a.mu.Unlock()
+
+	state := "unlocked"
+	if locked {
+		state = "locked"
+	}
+	a.log.Printf("randomize: %s %s", name, state)
+}
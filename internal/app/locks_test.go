@@ -0,0 +1,64 @@
+package app
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+)
+
+func TestRandomLocksString(t *testing.T) {
+	cases := []struct {
+		locks RandomLocks
+		want  string
+	}{
+		{RandomLocks{}, ""},
+		{RandomLocks{Palette: true}, "palette"},
+		{RandomLocks{Pattern: true, ColorMode: true}, "pattern+color"},
+		{RandomLocks{Pattern: true, Palette: true, ColorMode: true}, "pattern+palette+color"},
+	}
+	for _, c := range cases {
+		if got := c.locks.String(); got != c.want {
+			t.Errorf("%+v: got %q, want %q", c.locks, got, c.want)
+		}
+	}
+}
+
+func TestRandomizeKeepsLocked(t *testing.T) {
+	a, err := New(Config{
+		Backend:      "none",
+		DisableAudio: true,
+		PresetsPath:  filepath.Join(t.TempDir(), "presets.json"),
+		Log:          log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	a.toggleLock('p')
+	a.toggleLock('c')
+	if got := a.RandomLocks(); got != (RandomLocks{Palette: true, ColorMode: true}) {
+		t.Fatalf("locks after p, c: got %+v", got)
+	}
+	palette, color := a.renderer.PaletteName(), a.renderer.ColorModeName()
+	for range 10 {
+		pattern := a.renderer.PatternName()
+		a.randomizeVisuals()
+		if a.renderer.PatternName() == pattern {
+			t.Errorf("pattern stayed %q while unlocked", pattern)
+		}
+		if a.renderer.PaletteName() != palette || a.renderer.ColorModeName() != color {
+			t.Fatalf("locked look changed: palette %q color %q, want %q %q",
+				a.renderer.PaletteName(), a.renderer.ColorModeName(), palette, color)
+		}
+	}
+
+	// everything locked: randomize is a no-op
+	a.SetRandomLocks(RandomLocks{Pattern: true, Palette: true, ColorMode: true})
+	pattern := a.renderer.PatternName()
+	a.randomizeVisuals()
+	if a.renderer.PatternName() != pattern {
+		t.Errorf("pattern changed to %q with everything locked", a.renderer.PatternName())
+	}
+}
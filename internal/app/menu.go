@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/render"
+)
+
+const (
+	menuNoiseFloorStep = 0.01
+	menuIntervalStep   = time.Second
+	menuMaxInterval    = time.Hour
+
+	menuHighlight = "\x1b[7m"
+)
+
+// menuItem is one row of the settings menu: what it shows and what left
+// (dir -1) and right (dir 1) do to it.
+type menuItem struct {
+	label  string
+	value  func(a *App) string
+	adjust func(a *App, dir int)
+}
+
+// menuItems are the settings the menu changes, the ones that used to need
+// the web panel.
+var menuItems = []menuItem{
+	{
+		label:  "pattern",
+		value:  func(a *App) string { return a.renderer.PatternName() },
+		adjust: (*App).cyclePattern,
+	},
+	{
+		label:  "palette",
+		value:  func(a *App) string { return a.renderer.PaletteName() },
+		adjust: (*App).cyclePalette,
+	},
+	{
+		label: "color mode",
+		value: func(a *App) string { return a.renderer.ColorModeName() },
+		adjust: func(a *App, dir int) {
+			r := a.renderer
+			r.Configure(r.PaletteName(), r.PatternName(), cycleName(render.ColorModeNames(), r.ColorModeName(), dir), r.ColorOnAudio())
+		},
+	},
+	{
+		label:  "brightness",
+		value:  func(a *App) string { return fmt.Sprintf("%.2f", a.GetParams().Brightness) },
+		adjust: (*App).nudgeBrightness,
+	},
+	{
+		label: "noise floor",
+		value: func(a *App) string {
+			a.mu.RLock()
+			defer a.mu.RUnlock()
+			if !a.cfg.NoiseFloors.IsZero() {
+				return "calibrated"
+			}
+			return fmt.Sprintf("%.2f", a.cfg.NoiseFloor)
+		},
+		adjust: func(a *App, dir int) {
+			a.mu.RLock()
+			v := a.cfg.NoiseFloor
+			a.mu.RUnlock()
+			// rounded, so steps don't collect float error
+			v = math.Round((v+float64(dir)*menuNoiseFloorStep)*100) / 100
+			a.SetNoiseFloor(math.Max(0, math.Min(1, v)))
+		},
+	},
+	{
+		label: "auto-randomize",
+		value: func(a *App) string {
+			a.mu.RLock()
+			defer a.mu.RUnlock()
+			return onOff(a.autoRandomize)
+		},
+		adjust: func(a *App, _ int) { a.toggleAutoRandomize() },
+	},
+	{
+		label: "randomize every",
+		value: func(a *App) string {
+			a.mu.RLock()
+			defer a.mu.RUnlock()
+			return a.randomInterval.String()
+		},
+		adjust: func(a *App, dir int) {
+			a.mu.RLock()
+			v := a.randomInterval.Round(time.Second)
+			a.mu.RUnlock()
+			a.SetRandomInterval(max(menuIntervalStep, min(menuMaxInterval, v+time.Duration(dir)*menuIntervalStep)))
+		},
+	},
+}
+
+// settingsMenu is the overlay the m key opens on the ASCII backend. The
+// keyboard goroutine only reads open, to tell closing the menu from
+// quitting on Esc; the rest belongs to the main loop.
+type settingsMenu struct {
+	open   atomic.Bool
+	cursor int
+}
+
+// cycleName returns the name dir steps away from current in names,
+// wrapping around; the first one when current isn't there.
+func cycleName(names []string, current string, dir int) string {
+	if len(names) == 0 {
+		return current
+	}
+	i := slices.Index(names, current)
+	if i < 0 {
+		return names[0]
+	}
+	return names[((i+dir)%len(names)+len(names))%len(names)]
+}
+
+// menuInput handles one of the menu's input events.
+func (a *App) menuInput(evt inputEvent) {
+	m := &a.menu
+	if evt == inputEventMenu {
+		// the other backends draw their own frames
+		m.open.Store(!m.open.Load() && a.textFrames)
+		return
+	}
+	if !m.open.Load() {
+		return
+	}
+	switch evt {
+	case inputEventMenuUp:
+		m.cursor = (m.cursor + len(menuItems) - 1) % len(menuItems)
+	case inputEventMenuDown:
+		m.cursor = (m.cursor + 1) % len(menuItems)
+	case inputEventMenuLeft:
+		menuItems[m.cursor].adjust(a, -1)
+	case inputEventMenuRight:
+		menuItems[m.cursor].adjust(a, 1)
+	}
+}
+
+// overlayMenu draws the open menu over the middle rows of the frame,
+// below the status lines.
+func (a *App) overlayMenu(statusRows int) {
+	rows := make([]string, 0, len(menuItems)+2)
+	rows = append(rows, padLine(" settings   up/down: select   left/right: change   m: close", a.width))
+	for i, item := range menuItems {
+		marker := " "
+		if i == a.menu.cursor {
+			marker = ">"
+		}
+		row := padLine(fmt.Sprintf(" %s %-16s < %s >", marker, item.label, item.value(a)), a.width)
+		if i == a.menu.cursor {
+			row = menuHighlight + row + "\x1b[0m"
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, padLine("", a.width))
+
+	start := max(statusRows, (len(a.currentLines)-len(rows))/2)
+	for i, row := range rows {
+		if start+i >= len(a.currentLines) {
+			break
+		}
+		a.currentLines[start+i] = row
+	}
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCycleName(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	cases := []struct {
+		current string
+		dir     int
+		want    string
+	}{
+		{"a", 1, "b"},
+		{"c", 1, "a"},
+		{"a", -1, "c"},
+		{"b", -4, "a"},
+		{"gone", 1, "a"},
+	}
+	for _, c := range cases {
+		if got := cycleName(names, c.current, c.dir); got != c.want {
+			t.Errorf("%q %+d: got %q, want %q", c.current, c.dir, got, c.want)
+		}
+	}
+	if got := cycleName(nil, "a", 1); got != "a" {
+		t.Errorf("no names: got %q", got)
+	}
+}
+
+func TestSettingsMenu(t *testing.T) {
+	a, err := New(Config{
+		Backend:      "none",
+		DisableAudio: true,
+		PresetsPath:  filepath.Join(t.TempDir(), "presets.json"),
+		Log:          log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	// pixel backends draw their own frames: no menu
+	a.menuInput(inputEventMenu)
+	if a.menu.open.Load() {
+		t.Fatal("menu opened on a pixel backend")
+	}
+	a.textFrames = true
+	a.menuInput(inputEventMenu)
+	if !a.menu.open.Load() {
+		t.Fatal("menu did not open")
+	}
+
+	item := func(label string) int {
+		for i, it := range menuItems {
+			if it.label == label {
+				return i
+			}
+		}
+		t.Fatalf("no %q item", label)
+		return 0
+	}
+	moveTo := func(i int) {
+		for a.menu.cursor != i {
+			a.menuInput(inputEventMenuDown)
+		}
+	}
+
+	moveTo(item("brightness"))
+	before := a.GetParams().Brightness
+	a.menuInput(inputEventMenuRight)
+	if got := a.GetParams().Brightness; got <= before {
+		t.Errorf("brightness %v after right, was %v", got, before)
+	}
+
+	moveTo(item("noise floor"))
+	a.SetNoiseFloor(0.005)
+	a.menuInput(inputEventMenuLeft)
+	a.menuInput(inputEventMenuLeft)
+	if got := menuItems[a.menu.cursor].value(a); got != "0.00" {
+		t.Errorf("noise floor %s after two steps down, want 0.00", got)
+	}
+
+	// up from the first row wraps to the last
+	moveTo(0)
+	a.menuInput(inputEventMenuUp)
+	if a.menu.cursor != len(menuItems)-1 || menuItems[a.menu.cursor].label != "randomize every" {
+		t.Fatalf("cursor %d after up from the top", a.menu.cursor)
+	}
+	a.SetRandomInterval(1500 * time.Millisecond)
+	a.menuInput(inputEventMenuLeft)
+	if got := menuItems[a.menu.cursor].value(a); got != "1s" {
+		t.Errorf("interval %s, want it held at 1s", got)
+	}
+
+	a.width = 70
+	a.currentLines = make([]string, 20)
+	a.overlayMenu(2)
+	start := (len(a.currentLines) - len(menuItems) - 2) / 2
+	if !strings.Contains(a.currentLines[start], "settings") {
+		t.Errorf("row %d: %q, want the menu title", start, a.currentLines[start])
+	}
+	selected := a.currentLines[start+1+a.menu.cursor]
+	if !strings.HasPrefix(selected, menuHighlight) || !strings.Contains(selected, "> randomize every") {
+		t.Errorf("selected row %q", selected)
+	}
+
+	// closing stops it taking the arrows
+	a.menuInput(inputEventMenu)
+	a.menuInput(inputEventMenuDown)
+	if a.menu.open.Load() || a.menu.cursor != len(menuItems)-1 {
+		t.Errorf("closed menu: open %v cursor %d", a.menu.open.Load(), a.menu.cursor)
+	}
+}
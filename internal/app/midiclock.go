@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/midi"
+)
+
+// applyMIDIClock makes an incoming MIDI clock the master beat source: while
+// it ticks with the transport running, its tempo and beats replace those of
+// the frame, tapped or detected, so the visuals stay locked to the DJ
+// software. Once it stops the beat detector takes over again.
+func (a *App) applyMIDIClock(now time.Time, feat analyzer.Features) analyzer.Features {
+	if a.clock == nil {
+		return feat
+	}
+	beats := a.clock.Beats()
+	onset := beats != a.clockBeats
+	a.clockBeats = beats
+	bpm := a.clock.BPM(now)
+	if bpm <= 0 {
+		return feat
+	}
+	feat.Tempo = bpm
+	feat.TempoConfidence = 1
+	feat.Onset = onset
+	if onset {
+		feat.BeatStrength = max(feat.BeatStrength, tapBeatStrength)
+	}
+	return feat
+}
+
+// MIDIClockTempo returns the tempo of the incoming MIDI clock while it
+// drives the beat clock, else 0 (thread-safe).
+func (a *App) MIDIClockTempo() float64 {
+	if a.clock == nil {
+		return 0
+	}
+	return a.clock.BPM(time.Now())
+}
+
+// readMIDIIn reads the tap and clock ports until they close, sharing one
+// reader when both are the same device.
+func (a *App) readMIDIIn() {
+	notes := func(_ int, note, _ uint8) {
+		if a.cfg.TapMIDINote < 0 || int(note) == a.cfg.TapMIDINote {
+			a.Tap(time.Now())
+		}
+	}
+	realtime := func(status byte) {
+		a.clock.Handle(status, time.Now())
+	}
+	if a.tapIn != nil && a.tapIn == a.clockIn {
+		go a.tapIn.Read(notes, realtime)
+		return
+	}
+	if a.tapIn != nil {
+		go a.tapIn.Notes(notes)
+	}
+	if a.clockIn != nil {
+		go a.clockIn.Read(nil, realtime)
+	}
+}
+
+// openMIDIIn opens the tap and clock input ports of cfg.
+func (a *App) openMIDIIn(cfg Config) error {
+	if cfg.TapMIDIIn != "" {
+		in, err := midi.OpenIn(cfg.TapMIDIIn)
+		if err != nil {
+			return fmt.Errorf("tap midi in: %w", err)
+		}
+		a.tapIn = in
+		a.log.Printf("tap tempo <- %s", in.Name())
+	}
+	if cfg.ClockMIDIIn != "" {
+		a.clock = &midi.Follower{}
+		if cfg.ClockMIDIIn == cfg.TapMIDIIn {
+			a.clockIn = a.tapIn
+		} else {
+			in, err := midi.OpenIn(cfg.ClockMIDIIn)
+			if err != nil {
+				if a.tapIn != nil {
+					a.tapIn.Close()
+				}
+				return fmt.Errorf("midi clock in: %w", err)
+			}
+			a.clockIn = in
+		}
+		a.log.Printf("midi clock <- %s", a.clockIn.Name())
+	}
+	return nil
+}
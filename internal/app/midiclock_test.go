@@ -0,0 +1,48 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/midi"
+)
+
+func TestApplyMIDIClock(t *testing.T) {
+	detected := analyzer.Features{Tempo: 90, TempoConfidence: 0.4, Onset: true, BeatStrength: 0.2}
+	a := &App{}
+	if got := a.applyMIDIClock(time.Now(), detected); got != detected {
+		t.Fatalf("no clock: got %+v", got)
+	}
+
+	a.clock = &midi.Follower{}
+	now := time.Unix(0, 0)
+	if got := a.applyMIDIClock(now, detected); got != detected {
+		t.Fatalf("silent clock: got %+v, want the detected beat", got)
+	}
+
+	// 120 bpm: a tick every 1/48s
+	tick := time.Second / 48
+	var onsets int
+	for i := range 4 * midi.PulsesPerQuarter {
+		now = now.Add(tick)
+		a.clock.Handle(0xF8, now)
+		got := a.applyMIDIClock(now, detected)
+		if i < midi.PulsesPerQuarter {
+			continue
+		}
+		if got.Tempo < 119.9 || got.Tempo > 120.1 || got.TempoConfidence != 1 {
+			t.Fatalf("tick %d: tempo %v confidence %v, want 120 and 1", i, got.Tempo, got.TempoConfidence)
+		}
+		if got.Onset {
+			onsets++
+			if got.BeatStrength < tapBeatStrength {
+				t.Errorf("tick %d: beat strength %v", i, got.BeatStrength)
+			}
+		}
+	}
+	// the clock's beats, not the detector's onsets, on ticks 24, 48 and 72
+	if onsets != 3 {
+		t.Errorf("%d onsets, want 3", onsets)
+	}
+}
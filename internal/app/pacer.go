@@ -0,0 +1,33 @@
+package app
+
+import "time"
+
+// framePacer spaces frames 1/fps apart by sleeping until each frame's
+// deadline. Deadlines advance from the previous deadline rather than from
+// when a frame finished, so the rate doesn't drift; a frame that runs late
+// restarts the schedule instead of rushing the next ones to catch up.
+type framePacer struct {
+	interval time.Duration
+	next     time.Time
+}
+
+// newFramePacer returns nil when fps is 0, frames then run back to back.
+func newFramePacer(fps float64, now time.Time) *framePacer {
+	if fps <= 0 {
+		return nil
+	}
+	return &framePacer{interval: time.Duration(float64(time.Second) / fps), next: now}
+}
+
+// delay returns how long to wait from now until the next frame is due.
+func (p *framePacer) delay(now time.Time) time.Duration {
+	if p == nil {
+		return 0
+	}
+	p.next = p.next.Add(p.interval)
+	if d := p.next.Sub(now); d > 0 {
+		return d
+	}
+	p.next = now
+	return 0
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFramePacer(t *testing.T) {
+	if p := newFramePacer(0, time.Now()); p != nil || p.delay(time.Now()) != 0 {
+		t.Fatal("fps 0 should not pace")
+	}
+
+	start := time.Unix(0, 0)
+	p := newFramePacer(50, start) // 20ms a frame
+	ms := time.Millisecond
+	steps := []struct {
+		now  time.Duration // when the frame finished, from start
+		want time.Duration
+	}{
+		{5 * ms, 15 * ms},
+		// done 6ms into the next frame: its deadline still counts from 20ms
+		{26 * ms, 14 * ms},
+		{59 * ms, 1 * ms},
+		// 10ms past the deadline: no catching up, the schedule starts over
+		{90 * ms, 0},
+		{95 * ms, 15 * ms},
+	}
+	for i, s := range steps {
+		if got := p.delay(start.Add(s.now)); got != s.want {
+			t.Errorf("frame %d done at %v: delay %v, want %v", i, s.now, got, s.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"time"
+
+	"github.com/guidoenr/golizer/internal/clip"
+)
+
+// SetPaused holds the current frame on screen (true) or lets the visuals
+// run again (thread-safe). While paused the audio is still captured and
+// analyzed, so levels and beats stay current for when it resumes.
+func (a *App) SetPaused(on bool) {
+	a.mu.Lock()
+	changed := a.paused != on
+	a.paused = on
+	a.mu.Unlock()
+	if changed {
+		a.log.Printf("pause: %s", onOff(on))
+	}
+}
+
+// Paused reports whether the frame is held (thread-safe).
+func (a *App) Paused() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.paused
+}
+
+// togglePause is the space and f keys.
+func (a *App) togglePause() {
+	a.mu.Lock()
+	a.paused = !a.paused
+	on := a.paused
+	a.mu.Unlock()
+	a.log.Printf("pause: %s", onOff(on))
+}
+
+// presentHeld keeps the held frame up in place of a new one and answers
+// snapshots with it. Text frames are drawn again so the status bar stays
+// live, a window only needs its events handled; the other backends keep
+// what they showed.
+func (a *App) presentHeld(now time.Time, fps float64) error {
+	for {
+		select {
+		case reply := <-a.stills:
+			reply <- clip.Still(a.held.Image, a.held.Lines)
+			continue
+		default:
+		}
+		break
+	}
+	if a.held.Present == nil {
+		return a.drawText(now, a.held.Lines, a.heldStatus, fps)
+	}
+	return a.renderer.PollWindow()
+}
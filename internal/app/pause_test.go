@@ -0,0 +1,63 @@
+package app
+
+import (
+	"bytes"
+	"image"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPauseHoldsTheFrame(t *testing.T) {
+	a, err := New(Config{
+		Backend:      "none",
+		DisableAudio: true,
+		PresetsPath:  filepath.Join(t.TempDir(), "presets.json"),
+		Log:          log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	// snap steps one frame and returns the snapshot taken of it
+	snap := func() []byte {
+		t.Helper()
+		reply := make(chan image.Image, 1)
+		a.stills <- reply
+		time.Sleep(20 * time.Millisecond) // the animation moves on between frames
+		if err := a.step(); err != nil {
+			t.Fatal(err)
+		}
+		img := <-reply
+		if img == nil {
+			t.Fatal("no image")
+		}
+		return img.(*image.RGBA).Pix
+	}
+
+	a.togglePause()
+	if !a.Paused() {
+		t.Fatal("toggle did not pause")
+	}
+	held := snap()
+	start := a.GetParams().Time
+	for range 3 {
+		if got := snap(); !bytes.Equal(got, held) {
+			t.Fatal("paused frame changed")
+		}
+	}
+	if a.GetParams().Time <= start {
+		t.Error("the params stopped with the frame")
+	}
+
+	a.SetPaused(false)
+	if a.Paused() {
+		t.Fatal("still paused")
+	}
+	if got := snap(); bytes.Equal(got, held) {
+		t.Error("frame still held after resuming")
+	}
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+// The analysis loop owns audio capture and the analyzer. It reads and
+// analyses samples on its own goroutine at the target frame rate and hands
+// the newest Features to the render loop through a one-slot channel, so a
+// slow FFT on the Pi never stalls frame presentation.
+
+// runAnalysis analyses audio every interval until ctx is cancelled.
+func (a *App) runAnalysis(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case req := <-a.audioRestarts:
+			info, err := a.reopenAudio(req.device)
+			req.reply <- audioRestartResult{info, err}
+			last = time.Now()
+		case now := <-ticker.C:
+			delta := now.Sub(last).Seconds()
+			last = now
+			a.analyzeOnce(now, delta)
+		}
+	}
+}
+
+func (a *App) analyzeOnce(now time.Time, delta float64) {
+	if a.kiosk != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				a.log.Printf("kiosk: analysis panic: %v", r)
+				a.analysisFailed.Store(true)
+			}
+		}()
+	}
+
+	if !a.readSamples() {
+		return
+	}
+
+	samples, side := a.sampleBuffer, a.sideBuffer
+	if a.analysisSamples > 0 && len(samples) > a.analysisSamples {
+		samples = samples[len(samples)-a.analysisSamples:]
+		if len(side) > a.analysisSamples {
+			side = side[len(side)-a.analysisSamples:]
+		}
+	}
+	a.mu.RLock()
+	gain := a.cfg.InputGain
+	envelopes := a.cfg.Envelopes
+	a.mu.RUnlock()
+	if gain > 0 && gain != 1 {
+		for i := range samples {
+			samples[i] *= float32(gain)
+		}
+		for i := range side {
+			side[i] *= float32(gain)
+		}
+	}
+	a.relayOut.PublishSamples(samples)
+
+	a.analyzer.SetEnvelopes(envelopes)
+	raw := a.analyzer.AnalyzeStereo(samples, side, delta)
+	a.recordCalibration(now, raw)
+	features := a.gate.Apply(raw, a.gateFloors(), delta)
+	a.relayOut.PublishFeatures(features)
+	a.publishFeatures(features)
+}
+
+// readSamples fills sampleBuffer from the sound card or a pcm relay, and
+// sideBuffer with the side signal of a stereo sound card. It reports false
+// while no source is open.
+func (a *App) readSamples() bool {
+	if a.relayIn != nil {
+		a.sampleBuffer = a.relayIn.SamplesInto(a.sampleBuffer)
+		a.sideBuffer = a.sideBuffer[:0]
+		return true
+	}
+	a.captureMu.Lock()
+	defer a.captureMu.Unlock()
+	if a.capture == nil {
+		return false
+	}
+	a.sampleBuffer, a.sideBuffer = a.capture.StereoInto(a.sampleBuffer, a.sideBuffer)
+	return true
+}
+
+// publishFeatures replaces any value the render loop hasn't picked up yet.
+// One-frame events of the dropped value carry over so no beat gets lost.
+func (a *App) publishFeatures(feat analyzer.Features) {
+	select {
+	case old := <-a.analysisOut:
+		feat.Onset = feat.Onset || old.Onset
+		feat.IsDrop = feat.IsDrop || old.IsDrop
+		feat.BeatStrength = max(feat.BeatStrength, old.BeatStrength)
+	default:
+	}
+	a.analysisOut <- feat
+}
+
+// latestFeatures returns the newest published features. Render frames that
+// outpace analysis repeat the last value without its one-frame events.
+func (a *App) latestFeatures() analyzer.Features {
+	select {
+	case feat := <-a.analysisOut:
+		a.liveFeatures = feat
+		return feat
+	default:
+		feat := a.liveFeatures
+		feat.Onset = false
+		feat.IsDrop = false
+		return feat
+	}
+}
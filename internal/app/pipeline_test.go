@@ -0,0 +1,31 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestFeatureHandOff(t *testing.T) {
+	a := &App{analysisOut: make(chan analyzer.Features, 1)}
+
+	// analysis outpaces rendering: the newer frame wins, its beat doesn't
+	// get lost
+	a.publishFeatures(analyzer.Features{Bass: 0.2, Onset: true, BeatStrength: 0.9})
+	a.publishFeatures(analyzer.Features{Bass: 0.4, BeatStrength: 0.1})
+	got := a.latestFeatures()
+	if got.Bass != 0.4 || !got.Onset || got.BeatStrength != 0.9 {
+		t.Errorf("got %+v, want bass 0.4 with the dropped frame's onset and beat", got)
+	}
+
+	// rendering outpaces analysis: the frame repeats without its events
+	a.publishFeatures(analyzer.Features{Bass: 0.6, Onset: true, IsDrop: true})
+	if got := a.latestFeatures(); !got.Onset || !got.IsDrop {
+		t.Errorf("got %+v, want the onset and drop once", got)
+	}
+	for range 2 {
+		if got := a.latestFeatures(); got.Bass != 0.6 || got.Onset || got.IsDrop {
+			t.Errorf("repeated %+v, want bass 0.6 without events", got)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// playlistBeatsPerBar is the bar length steps given in bars count in.
+	playlistBeatsPerBar = 4
+	// playlistFallbackBPM times bar steps while no tempo is known.
+	playlistFallbackBPM = 120.0
+)
+
+// PlaylistStep shows one preset for a number of seconds or of bars.
+type PlaylistStep struct {
+	Preset  string  `json:"preset"`
+	Seconds float64 `json:"seconds,omitempty"`
+	// Bars of four beats at the detected (or tapped) tempo, following it
+	// as it drifts. It takes precedence over Seconds.
+	Bars int `json:"bars,omitempty"`
+}
+
+// Playlist plays its steps in order and starts over after the last one,
+// a light show sequence built from presets. Changing steps crossfade like
+// any other change of look (--transition).
+type Playlist struct {
+	Steps []PlaylistStep `json:"steps"`
+	// Autoplay starts the playlist when the visualizer starts.
+	Autoplay bool `json:"autoplay,omitempty"`
+}
+
+// PlaylistState is where a playlist is at.
+type PlaylistState struct {
+	Playing bool `json:"playing"`
+	Step    int  `json:"step"`
+	// Progress is the share of the current step that has played.
+	Progress float64 `json:"progress"`
+}
+
+// validate checks every step has a preset and a length.
+func (p Playlist) validate() error {
+	for i, step := range p.Steps {
+		if strings.TrimSpace(step.Preset) == "" {
+			return fmt.Errorf("playlist step %d: no preset", i+1)
+		}
+		if step.Bars < 0 || step.Seconds < 0 || (step.Bars == 0 && step.Seconds == 0) {
+			return fmt.Errorf("playlist step %d: needs a positive length in seconds or bars", i+1)
+		}
+	}
+	return nil
+}
+
+// share returns how much of the step dt seconds play at bpm.
+func (s PlaylistStep) share(dt, bpm float64) float64 {
+	if s.Bars > 0 {
+		if bpm <= 0 {
+			bpm = playlistFallbackBPM
+		}
+		return dt * bpm / 60 / float64(s.Bars*playlistBeatsPerBar)
+	}
+	return dt / s.Seconds
+}
+
+// Playlist returns the playlist and where it is at (thread-safe).
+func (a *App) Playlist() (Playlist, PlaylistState) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.playlist, a.playlistState
+}
+
+// SetPlaylist replaces the playlist; a playing one starts over from its
+// first step (thread-safe).
+func (a *App) SetPlaylist(p Playlist) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.playlist = p
+	playing := a.playlistState.Playing && len(p.Steps) > 0
+	a.playlistState = PlaylistState{}
+	a.mu.Unlock()
+	if playing {
+		return a.PlayPlaylist()
+	}
+	return nil
+}
+
+// PlayPlaylist starts the playlist from its first step (thread-safe).
+func (a *App) PlayPlaylist() error {
+	a.mu.Lock()
+	if len(a.playlist.Steps) == 0 {
+		a.mu.Unlock()
+		return errors.New("playlist is empty")
+	}
+	a.playlistState = PlaylistState{Playing: true}
+	first := a.playlist.Steps[0]
+	a.mu.Unlock()
+	a.showStep(first)
+	return nil
+}
+
+// StopPlaylist stops the playlist, leaving the current look on screen
+// (thread-safe).
+func (a *App) StopPlaylist() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.playlistState.Playing = false
+}
+
+// stepPlaylist advances a playing playlist by dt seconds at bpm, moving to
+// the next step once the current one is over.
+func (a *App) stepPlaylist(dt, bpm float64) {
+	a.mu.Lock()
+	state := &a.playlistState
+	if !state.Playing || len(a.playlist.Steps) == 0 {
+		a.mu.Unlock()
+		return
+	}
+	state.Progress += a.playlist.Steps[state.Step].share(dt, bpm)
+	if state.Progress < 1 {
+		a.mu.Unlock()
+		return
+	}
+	state.Step = (state.Step + 1) % len(a.playlist.Steps)
+	state.Progress = 0
+	next := a.playlist.Steps[state.Step]
+	a.mu.Unlock()
+	a.showStep(next)
+}
+
+// showStep switches to the step's preset. A preset deleted since the
+// playlist was made leaves the look as it is for that step.
+func (a *App) showStep(step PlaylistStep) {
+	if err := a.LoadPreset(step.Preset); err != nil {
+		a.log.Printf("playlist: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlaylistValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		step PlaylistStep
+		ok   bool
+	}{
+		{"seconds", PlaylistStep{Preset: "a", Seconds: 10}, true},
+		{"bars", PlaylistStep{Preset: "a", Bars: 8}, true},
+		{"no preset", PlaylistStep{Preset: " ", Seconds: 10}, false},
+		{"no length", PlaylistStep{Preset: "a"}, false},
+		{"negative bars", PlaylistStep{Preset: "a", Bars: -1, Seconds: 10}, false},
+		{"negative seconds", PlaylistStep{Preset: "a", Seconds: -1}, false},
+	}
+	for _, c := range cases {
+		err := Playlist{Steps: []PlaylistStep{c.step}}.validate()
+		if (err == nil) != c.ok {
+			t.Errorf("%q: got %v, want ok %v", c.name, err, c.ok)
+		}
+	}
+}
+
+func TestPlaylistStepShare(t *testing.T) {
+	cases := []struct {
+		step    PlaylistStep
+		dt, bpm float64
+		want    float64
+	}{
+		{PlaylistStep{Seconds: 4}, 1, 128, 0.25},
+		// one bar at 120 bpm is two seconds
+		{PlaylistStep{Bars: 1}, 1, 120, 0.5},
+		{PlaylistStep{Bars: 2, Seconds: 100}, 1, 60, 0.125},
+		// no tempo yet: 120 bpm
+		{PlaylistStep{Bars: 1}, 1, 0, 0.5},
+	}
+	for _, c := range cases {
+		if got := c.step.share(c.dt, c.bpm); got != c.want {
+			t.Errorf("%+v over %vs at %v bpm: got %v, want %v", c.step, c.dt, c.bpm, got, c.want)
+		}
+	}
+}
+
+func TestPlaylistPlays(t *testing.T) {
+	a, err := New(Config{
+		Backend:      "none",
+		DisableAudio: true,
+		PresetsPath:  filepath.Join(t.TempDir(), "presets.json"),
+		Log:          log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	for _, p := range []Preset{{Name: "one", Pattern: "tunnel"}, {Name: "two", Pattern: "ripple"}} {
+		if err := a.presets.Put(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.PlayPlaylist(); err == nil {
+		t.Error("played an empty playlist")
+	}
+	err = a.SetPlaylist(Playlist{Steps: []PlaylistStep{
+		{Preset: "one", Seconds: 2},
+		{Preset: "two", Bars: 1},
+		{Preset: "gone", Seconds: 1},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.PlayPlaylist(); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		dt, bpm float64
+		step    int
+		pattern string
+	}{
+		{1, 0, 0, "tunnel"},
+		{1, 0, 1, "ripple"},
+		{1, 60, 1, "ripple"}, // a quarter of a bar at 60 bpm
+		{3, 60, 2, "ripple"}, // a deleted preset keeps the look
+		{1, 60, 0, "tunnel"}, // and around again
+	}
+	for i, s := range steps {
+		a.stepPlaylist(s.dt, s.bpm)
+		_, state := a.Playlist()
+		if state.Step != s.step || a.renderer.PatternName() != s.pattern {
+			t.Errorf("tick %d: step %d pattern %q, want step %d pattern %q", i, state.Step, a.renderer.PatternName(), s.step, s.pattern)
+		}
+	}
+
+	a.StopPlaylist()
+	a.stepPlaylist(10, 0)
+	if _, state := a.Playlist(); state.Playing || state.Step != 0 {
+		t.Errorf("stopped playlist moved on: %+v", state)
+	}
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// presetSlots is how many presets the number keys recall, 1 to 9.
+const presetSlots = 9
+
+// Preset is a named look: the parameters with the palette, pattern and
+// color mode they were tuned for.
+type Preset struct {
+	Name      string            `json:"name"`
+	Params    params.Parameters `json:"params"`
+	Palette   string            `json:"palette"`
+	Pattern   string            `json:"pattern"`
+	ColorMode string            `json:"colorMode"`
+}
+
+// PresetStore keeps the presets in the order they were first saved, which
+// is also their number key, and writes them to its file on every change.
+type PresetStore struct {
+	mu      sync.Mutex
+	path    string
+	presets []Preset
+}
+
+// LoadPresets reads the store at path; a missing file is an empty store.
+// With an empty path the presets only live until the process exits.
+func LoadPresets(path string) (*PresetStore, error) {
+	s := &PresetStore{path: path}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.presets); err != nil {
+		return nil, fmt.Errorf("presets %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// List returns a copy of the presets in slot order.
+func (s *PresetStore) List() []Preset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.presets)
+}
+
+// Get returns the preset called name, ignoring case.
+func (s *PresetStore) Get(name string) (Preset, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.index(name); i >= 0 {
+		return s.presets[i], true
+	}
+	return Preset{}, false
+}
+
+// Put stores p, replacing the preset of the same name in its slot.
+func (s *PresetStore) Put(p Preset) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return errors.New("preset needs a name")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.index(p.Name); i >= 0 {
+		s.presets[i] = p
+	} else {
+		s.presets = append(s.presets, p)
+	}
+	return s.write()
+}
+
+// Delete removes the preset called name; the ones after it move up a slot.
+func (s *PresetStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(name)
+	if i < 0 {
+		return fmt.Errorf("unknown preset %q", name)
+	}
+	s.presets = slices.Delete(s.presets, i, i+1)
+	return s.write()
+}
+
+func (s *PresetStore) index(name string) int {
+	name = strings.TrimSpace(name)
+	return slices.IndexFunc(s.presets, func(p Preset) bool { return strings.EqualFold(p.Name, name) })
+}
+
+// write replaces the file through a temporary one so a crash mid-write
+// can't lose the presets.
+func (s *PresetStore) write() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.presets, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Presets returns the preset store; the store does its own locking.
+func (a *App) Presets() *PresetStore {
+	return a.presets
+}
+
+// SavePreset stores the current look as name (thread-safe).
+func (a *App) SavePreset(name string) (Preset, error) {
+	p := Preset{
+		Params:    a.GetParams(),
+		Palette:   a.renderer.PaletteName(),
+		Pattern:   a.renderer.PatternName(),
+		ColorMode: a.renderer.ColorModeName(),
+	}
+	p.Name = strings.TrimSpace(name)
+	return p, a.presets.Put(p)
+}
+
+// LoadPreset switches to the preset called name (thread-safe).
+func (a *App) LoadPreset(name string) error {
+	p, ok := a.presets.Get(name)
+	if !ok {
+		return fmt.Errorf("unknown preset %q", name)
+	}
+	a.applyPreset(p)
+	return nil
+}
+
+// recallPreset switches to the preset in slot i, counting from 0; empty
+// slots are ignored.
+func (a *App) recallPreset(i int) {
+	list := a.presets.List()
+	if i < 0 || i >= len(list) {
+		return
+	}
+	a.applyPreset(list[i])
+}
+
+// cyclePreset steps delta presets on from the last one recalled, wrapping
+// around.
+func (a *App) cyclePreset(delta int) {
+	list := a.presets.List()
+	if len(list) == 0 {
+		return
+	}
+	a.mu.RLock()
+	i := slices.IndexFunc(list, func(p Preset) bool { return p.Name == a.lastPreset })
+	a.mu.RUnlock()
+	if i < 0 && delta < 0 {
+		i = 0
+	}
+	a.applyPreset(list[((i+delta)%len(list)+len(list))%len(list)])
+}
+
+func (a *App) applyPreset(p Preset) {
+	tuned := p.Params
+	tuned.Pattern = p.Pattern
+	tuned.ColorMode = p.ColorMode
+	// the animation clock runs on, only the look changes
+	current := a.GetParams()
+	tuned.Time = current.Time
+	tuned.LastEffectTime = current.LastEffectTime
+	a.SetParams(tuned)
+	a.setScene(p.Palette, p.Pattern, p.ColorMode, a.renderer.ColorOnAudio())
+
+	a.mu.Lock()
+	a.lastPreset = p.Name
+	// a recalled look gets a full interval before auto-randomize moves on
+	a.lastRandom = time.Now()
+	a.mu.Unlock()
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+func TestPresetStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sets", "presets.json")
+	s, err := LoadPresets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.List()) != 0 {
+		t.Fatalf("missing file: got %d presets", len(s.List()))
+	}
+	if err := s.Put(Preset{Name: "  "}); err == nil {
+		t.Error("saved a preset without a name")
+	}
+	for _, name := range []string{"warmup", "peak", "outro"} {
+		if err := s.Put(Preset{Name: name, Pattern: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// same name, other case: replaced in its slot
+	if err := s.Put(Preset{Name: " PEAK ", Pattern: "tunnel"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("warmup"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("warmup"); err == nil {
+		t.Error("deleted a missing preset")
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+
+	reloaded, err := LoadPresets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := reloaded.List()
+	if len(list) != 2 || list[0].Name != "PEAK" || list[0].Pattern != "tunnel" || list[1].Name != "outro" {
+		t.Fatalf("reloaded: got %+v", list)
+	}
+	if p, ok := reloaded.Get("Outro"); !ok || p.Pattern != "outro" {
+		t.Errorf("Get(Outro): got %+v %v", p, ok)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPresets(path); err == nil {
+		t.Error("loaded a broken file")
+	}
+}
+
+func TestRecallPreset(t *testing.T) {
+	a, err := New(Config{
+		Backend:      "none",
+		DisableAudio: true,
+		PresetsPath:  filepath.Join(t.TempDir(), "presets.json"),
+		Log:          log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	for _, p := range []struct{ name, pattern string }{{"one", "tunnel"}, {"two", "ripple"}, {"three", "plasma"}} {
+		tuned := params.Defaults()
+		tuned.Brightness = 1.5
+		if err := a.presets.Put(Preset{Name: p.name, Params: tuned, Palette: "block", Pattern: p.pattern, ColorMode: "fire"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.recallPreset(1)
+	if got := a.GetParams(); got.Pattern != "ripple" || got.Brightness != 1.5 || a.renderer.PatternName() != "ripple" {
+		t.Fatalf("slot 2: pattern %q / %q, brightness %v", got.Pattern, a.renderer.PatternName(), got.Brightness)
+	}
+	a.recallPreset(8) // empty slot
+	if a.renderer.PatternName() != "ripple" {
+		t.Errorf("empty slot changed the pattern to %q", a.renderer.PatternName())
+	}
+
+	for _, c := range []struct {
+		delta int
+		want  string
+	}{{1, "plasma"}, {1, "tunnel"}, {-1, "plasma"}, {-2, "tunnel"}} {
+		a.cyclePreset(c.delta)
+		if got := a.renderer.PatternName(); got != c.want {
+			t.Errorf("cycle %+d: got %q, want %q", c.delta, got, c.want)
+		}
+	}
+
+	if err := a.LoadPreset("TWO"); err != nil || a.renderer.PatternName() != "ripple" {
+		t.Errorf("LoadPreset(TWO): %v, pattern %q", err, a.renderer.PatternName())
+	}
+	if err := a.LoadPreset("four"); err == nil {
+		t.Error("loaded an unknown preset")
+	}
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+// errReplayDone ends Run once every recorded frame was shown.
+var errReplayDone = errors.New("feature replay finished")
+
+// replaySeed seeds the app's random source during replay so randomized
+// palettes and patterns come out the same on every run.
+const replaySeed = 1
+
+// featureFrame is one line of a --record-features file.
+type featureFrame struct {
+	T        float64           `json:"t"`  // seconds since the first frame
+	Delta    float64           `json:"dt"` // frame time the app stepped with
+	Features analyzer.Features `json:"features"`
+}
+
+// featureRecorder appends the analyzer output of every frame to a JSONL file.
+type featureRecorder struct {
+	file  *os.File
+	buf   *bufio.Writer
+	enc   *json.Encoder
+	start time.Time
+}
+
+func newFeatureRecorder(path string) (*featureRecorder, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(f)
+	return &featureRecorder{file: f, buf: buf, enc: json.NewEncoder(buf)}, nil
+}
+
+func (r *featureRecorder) Record(now time.Time, delta float64, feat analyzer.Features) error {
+	if r == nil {
+		return nil
+	}
+	if r.start.IsZero() {
+		r.start = now
+	}
+	return r.enc.Encode(featureFrame{T: now.Sub(r.start).Seconds(), Delta: delta, Features: feat})
+}
+
+func (r *featureRecorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	err := r.buf.Flush()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// featureReplay feeds recorded frames back in place of live audio. The app
+// steps with the recorded frame times and a clock derived from them, so
+// everything downstream of the analyzer renders frame for frame the same.
+type featureReplay struct {
+	frames []featureFrame
+	next   int
+	start  time.Time
+}
+
+// loadFeatureReplay reads a --record-features file. A last line without
+// its newline is what a recording that was killed mid-frame ends with; it
+// is dropped, a broken line anywhere else is an error.
+func loadFeatureReplay(path string) (*featureReplay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var frames []featureFrame
+	reader := bufio.NewReader(f)
+	for lineNo := 1; ; lineNo++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, readErr
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			var frame featureFrame
+			if err := json.Unmarshal(line, &frame); err != nil {
+				if readErr == io.EOF {
+					break
+				}
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+			}
+			frames = append(frames, frame)
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("%s: no frames", path)
+	}
+	return &featureReplay{frames: frames}, nil
+}
+
+// Rewind starts the recording over with its clock anchored at now.
+func (r *featureReplay) Rewind(now time.Time) {
+	r.next = 0
+	r.start = now
+}
+
+// Next returns the next frame's clock, frame time and features, or false
+// once the recording is exhausted.
+func (r *featureReplay) Next() (time.Time, float64, analyzer.Features, bool) {
+	if r.next >= len(r.frames) {
+		return time.Time{}, 0, analyzer.Features{}, false
+	}
+	frame := r.frames[r.next]
+	r.next++
+	now := r.start.Add(time.Duration(frame.T * float64(time.Second)))
+	return now, frame.Delta, frame.Features, true
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// writeRecording records frames of a made-up song to path.
+func writeRecording(t *testing.T, path string, frames int) []analyzer.Features {
+	t.Helper()
+	rec, err := newFeatureRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1000, 0)
+	var all []analyzer.Features
+	for i := range frames {
+		x := float64(i)
+		f := analyzer.Features{
+			Bass:         0.5 + 0.5*math.Sin(x/5),
+			Mid:          0.5 + 0.5*math.Cos(x/7),
+			Treble:       float64(i%9) / 9,
+			BeatStrength: float64(i%15) / 15,
+			Onset:        i%15 == 0,
+			IsDrop:       i == frames/2,
+			Tempo:        124,
+		}
+		f.Spectrum[i%len(f.Spectrum)] = 1
+		all = append(all, f)
+		if err := rec.Record(start.Add(time.Duration(i)*time.Second/60), 1.0/60, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return all
+}
+
+// replayOnce runs a headless app over a recording until it ends, recording
+// again to out, and returns the params it ended on.
+func replayOnce(t *testing.T, in, out string) params.Parameters {
+	t.Helper()
+	a, err := New(Config{
+		Backend:        "none",
+		DisableAudio:   true,
+		ReplayFeatures: in,
+		RecordFeatures: out,
+		AutoRandomize:  true,
+		RandomInterval: time.Second,
+		PresetsPath:    filepath.Join(t.TempDir(), "presets.json"),
+		Log:            log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if err := a.step(); errors.Is(err, errReplayDone) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := a.GetParams()
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestFeatureReplayRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	song := filepath.Join(dir, "song.jsonl")
+	want := writeRecording(t, song, 300)
+
+	replay, err := loadFeatureReplay(song)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(5000, 0)
+	replay.Rewind(start)
+	for i, f := range want {
+		now, delta, got, ok := replay.Next()
+		if !ok {
+			t.Fatalf("replay ended after %d of %d frames", i, len(want))
+		}
+		if got != f || delta != 1.0/60 || now.Sub(start).Round(time.Millisecond) != (time.Duration(i)*time.Second/60).Round(time.Millisecond) {
+			t.Fatalf("frame %d: got %v %v %+v", i, now.Sub(start), delta, got)
+		}
+	}
+	if _, _, _, ok := replay.Next(); ok {
+		t.Errorf("replay didn't end")
+	}
+
+	// the same recording steps the app the same way every run
+	first, second := filepath.Join(dir, "first.jsonl"), filepath.Join(dir, "second.jsonl")
+	p1 := replayOnce(t, song, first)
+	p2 := replayOnce(t, song, second)
+	if p1 != p2 {
+		t.Errorf("runs ended on different params:\n%+v\n%+v", p1, p2)
+	}
+	a, _ := os.ReadFile(first)
+	b, _ := os.ReadFile(second)
+	if len(a) == 0 || !bytes.Equal(a, b) {
+		t.Errorf("runs recorded different frames (%d and %d bytes)", len(a), len(b))
+	}
+	rerecorded, err := loadFeatureReplay(first)
+	if err != nil || len(rerecorded.frames) != len(want) {
+		t.Fatalf("re-recorded: %v", err)
+	}
+	for i, frame := range rerecorded.frames {
+		if frame.Features != want[i] {
+			t.Fatalf("re-recorded frame %d differs", i)
+		}
+	}
+}
+
+func TestLoadFeatureReplayTruncated(t *testing.T) {
+	dir := t.TempDir()
+	song := filepath.Join(dir, "song.jsonl")
+	writeRecording(t, song, 10)
+	data, err := os.ReadFile(song)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for _, tc := range []struct {
+		name   string
+		data   []byte
+		frames int // 0 for an error
+	}{
+		{"whole", data, 10},
+		{"killed mid-frame", data[:len(data)-len(lines[9])/2], 9},
+		{"no last newline", data[:len(data)-1], 10},
+		{"broken line inside", bytes.Join([][]byte{lines[0], lines[1][:20], []byte("\n"), lines[2]}, nil), 0},
+		{"empty", nil, 0},
+		{"only a partial frame", lines[0][:20], 0},
+	} {
+		path := filepath.Join(dir, "cut.jsonl")
+		if err := os.WriteFile(path, tc.data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		replay, err := loadFeatureReplay(path)
+		switch {
+		case tc.frames == 0 && err == nil:
+			t.Errorf("%s: loaded %d frames, want an error", tc.name, len(replay.frames))
+		case tc.frames > 0 && err != nil:
+			t.Errorf("%s: %v", tc.name, err)
+		case tc.frames > 0 && len(replay.frames) != tc.frames:
+			t.Errorf("%s: %d frames, want %d", tc.name, len(replay.frames), tc.frames)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"time"
+
+	"github.com/guidoenr/golizer/internal/relay"
+)
+
+// pendingScene is a look change waiting until the relay followers have it.
+type pendingScene struct {
+	scene        relay.Scene
+	colorOnAudio bool
+	at           time.Time
+}
+
+// setScene switches the look. With relay followers the change goes out to
+// them first and shows here once the farthest of them has it, so every
+// screen in the room changes on the same frame.
+func (a *App) setScene(palette, pattern, colorMode string, colorOnAudio bool) {
+	scene := relay.Scene{Palette: palette, Pattern: pattern, ColorMode: colorMode}
+	lead := a.relayOut.PublishScene(scene)
+	if lead <= 0 {
+		a.showScene(scene, colorOnAudio)
+		return
+	}
+	a.mu.Lock()
+	a.pendingScene = &pendingScene{scene: scene, colorOnAudio: colorOnAudio, at: time.Now().Add(lead)}
+	a.mu.Unlock()
+}
+
+func (a *App) showScene(scene relay.Scene, colorOnAudio bool) {
+	a.renderer.Configure(scene.Palette, scene.Pattern, scene.ColorMode, colorOnAudio)
+	a.mu.Lock()
+	a.params.Pattern = scene.Pattern
+	a.params.ColorMode = scene.ColorMode
+	a.mu.Unlock()
+}
+
+// stepScene shows the scene changes that are due: the source's own once
+// its followers have it, a follower's as soon as the source sent it.
+func (a *App) stepScene() {
+	if a.relayIn != nil {
+		if scene, ok := a.relayIn.Scene(); ok {
+			// on down a chain of relays
+			a.relayOut.PublishScene(scene)
+			a.showScene(scene, a.renderer.ColorOnAudio())
+		}
+	}
+	a.mu.Lock()
+	pending := a.pendingScene
+	if pending != nil && time.Now().Before(pending.at) {
+		pending = nil
+	}
+	if pending != nil {
+		a.pendingScene = nil
+	}
+	a.mu.Unlock()
+	if pending != nil {
+		a.showScene(pending.scene, pending.colorOnAudio)
+	}
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/relay"
+)
+
+func TestSetSceneWaitsForFollowers(t *testing.T) {
+	a, err := New(Config{
+		Backend:      "none",
+		DisableAudio: true,
+		PresetsPath:  filepath.Join(t.TempDir(), "presets.json"),
+		Log:          log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	// no followers: right away
+	a.setScene("block", "tunnel", "fire", true)
+	if got := a.renderer.PatternName(); got != "tunnel" || a.GetParams().Pattern != "tunnel" {
+		t.Fatalf("pattern %q, want tunnel", got)
+	}
+
+	// what setScene leaves when the farthest follower is 50ms away
+	a.pendingScene = &pendingScene{scene: relay.Scene{Palette: "block", Pattern: "ripple", ColorMode: "fire"}, colorOnAudio: true, at: time.Now().Add(50 * time.Millisecond)}
+	a.stepScene()
+	if got := a.renderer.PatternName(); got != "tunnel" {
+		t.Errorf("changed to %q before the followers had it", got)
+	}
+	time.Sleep(60 * time.Millisecond)
+	a.stepScene()
+	if got := a.renderer.PatternName(); got != "ripple" || a.pendingScene != nil {
+		t.Errorf("pattern %q, pending %+v; want ripple", got, a.pendingScene)
+	}
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/clip"
+	"github.com/guidoenr/golizer/internal/render"
+)
+
+// snapshotTimeout bounds how long the snapshot key waits for a frame.
+const snapshotTimeout = 2 * time.Second
+
+// errNoFrame is returned when the render loop produced nothing to save.
+var errNoFrame = errors.New("frame has no image")
+
+// Snapshot returns a copy of the next rendered frame (thread-safe). It
+// waits for the render loop, so ctx should carry a deadline.
+func (a *App) Snapshot(ctx context.Context) (image.Image, error) {
+	reply := make(chan image.Image, 1)
+	select {
+	case a.stills <- reply:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	select {
+	case img := <-reply:
+		if img == nil {
+			return nil, errNoFrame
+		}
+		return img, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SaveSnapshot writes the next rendered frame as a timestamped PNG in the
+// capture directory and returns its path.
+func (a *App) SaveSnapshot(ctx context.Context, now time.Time) (string, error) {
+	img, err := a.Snapshot(ctx)
+	if err != nil {
+		return "", err
+	}
+	dir := a.cfg.CaptureDir
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, now.Format("golizer-20060102-150405.png"))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// pendingStills collects the snapshot requests waiting for this frame and
+// turns on pixel capture for it when nothing else keeps it on.
+func (a *App) pendingStills() []chan image.Image {
+	var stills []chan image.Image
+	for {
+		select {
+		case reply := <-a.stills:
+			stills = append(stills, reply)
+			continue
+		default:
+		}
+		break
+	}
+	if len(stills) > 0 && !a.capturing {
+		a.renderer.SetCapture(true)
+	}
+	return stills
+}
+
+// deliverStills answers the requests taken by pendingStills.
+func (a *App) deliverStills(stills []chan image.Image, frame render.Frame) {
+	if len(stills) == 0 {
+		return
+	}
+	if !a.capturing {
+		defer a.renderer.SetCapture(false)
+	}
+	img := clip.Still(frame.Image, frame.Lines)
+	for _, reply := range stills {
+		reply <- img
+	}
+}
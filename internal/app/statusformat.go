@@ -0,0 +1,108 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Status bar placements.
+const (
+	StatusTop    = "top"
+	StatusBottom = "bottom"
+)
+
+// StatusPositions are the valid Config.StatusPosition values.
+var StatusPositions = []string{StatusTop, StatusBottom}
+
+// StatusFields are the fields a status format can show, as {name}. device
+// is mic under its other name.
+var StatusFields = []string{
+	"panel", "temp", "throttle", "fps", "locked", "paused",
+	"mode", "palette", "pattern", "quality", "col", "mic", "device",
+	"bass", "mid", "treble", "beat", "bpm", "error",
+}
+
+// statusFormat is a parsed --status-format: text and {field} references
+// in the order they're written.
+type statusFormat struct {
+	parts []statusPart
+}
+
+type statusPart struct {
+	text  string
+	field string // set for a {field}
+}
+
+// parseStatusFormat reads a status format such as "{fps} fps {pattern}".
+// An empty format is nil, the stack of one field per row.
+func parseStatusFormat(format string) (*statusFormat, error) {
+	if format == "" {
+		return nil, nil
+	}
+	f := &statusFormat{}
+	for rest := format; rest != ""; {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			f.parts = append(f.parts, statusPart{text: rest})
+			break
+		}
+		if open > 0 {
+			f.parts = append(f.parts, statusPart{text: rest[:open]})
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed { in %q", format)
+		}
+		name := strings.ToLower(strings.TrimSpace(rest[open+1 : open+end]))
+		if !slices.Contains(StatusFields, name) {
+			return nil, fmt.Errorf("unknown field {%s} (%s)", name, strings.Join(StatusFields, ", "))
+		}
+		f.parts = append(f.parts, statusPart{field: name})
+		rest = rest[open+end+1:]
+	}
+	return f, nil
+}
+
+// CheckStatusFormat reports what is wrong with a status format.
+func CheckStatusFormat(format string) error {
+	_, err := parseStatusFormat(format)
+	return err
+}
+
+// line fills the format in from entries. With ansi the values take the
+// status value colour and the text in between the label colour.
+func (f *statusFormat) line(entries []statusEntry, ansi bool) string {
+	var b strings.Builder
+	for _, part := range f.parts {
+		if part.field == "" {
+			if ansi {
+				b.WriteString(statusLabelColor)
+			}
+			b.WriteString(part.text)
+			continue
+		}
+		value := statusValue(entries, part.field)
+		if ansi && value != "" {
+			b.WriteString(statusValueColor)
+		}
+		b.WriteString(value)
+	}
+	if ansi {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+// statusValue is the value of the named field, "" when it isn't shown.
+func statusValue(entries []statusEntry, field string) string {
+	if field == "device" {
+		field = "mic"
+	}
+	for _, entry := range entries {
+		if strings.EqualFold(strings.ReplaceAll(entry.label, " ", "_"), field) {
+			return entry.value
+		}
+	}
+	return ""
+}
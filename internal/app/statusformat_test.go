@@ -0,0 +1,81 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseStatusFormat(t *testing.T) {
+	cases := []struct {
+		format string
+		ok     bool
+	}{
+		{"", true},
+		{"{fps} fps | {pattern}", true},
+		{"{ FPS }", true},
+		{"no fields at all", true},
+		{"{fps", false},
+		{"{volume}", false},
+	}
+	for _, c := range cases {
+		if err := CheckStatusFormat(c.format); (err == nil) != c.ok {
+			t.Errorf("%q: got %v, want ok %v", c.format, err, c.ok)
+		}
+	}
+}
+
+func TestStatusFormatLine(t *testing.T) {
+	entries := []statusEntry{
+		{label: "FPS", value: "29.9"},
+		{label: "PATTERN", value: "tunnel"},
+		{label: "MIC", value: "USB Audio"},
+		{label: "LOCKED", value: ""},
+	}
+	f, err := parseStatusFormat("{fps} fps {pattern} on {device}{locked}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.line(entries, false), "29.9 fps tunnel on USB Audio"; got != want {
+		t.Errorf("plain: got %q, want %q", got, want)
+	}
+	want := statusValueColor + "29.9" + statusLabelColor + " fps " + statusValueColor + "tunnel" +
+		statusLabelColor + " on " + statusValueColor + "USB Audio\x1b[0m"
+	if got := f.line(entries, true); got != want {
+		t.Errorf("ansi: got %q, want %q", got, want)
+	}
+}
+
+func TestParseStatusParts(t *testing.T) {
+	raw := "ascii | http://10.0.0.2:8080 | palette=block pattern=tunnel color=fire | col=main output | bass 0.52 mid 0.10 fps 30 | script: line 3: boom"
+	want := []statusEntry{
+		{"MODE", "ascii"},
+		{"PALETTE", "block"},
+		{"PATTERN", "tunnel"},
+		{"COLOR", "fire"},
+		{"COL", "main output"},
+		{"BASS", "0.52"},
+		{"MID", "0.10"},
+		{"ERROR", "script: line 3: boom"},
+	}
+	if got := parseStatusParts(raw); !slices.Equal(got, want) {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestOverlayStatusLinesPosition(t *testing.T) {
+	for _, c := range []struct {
+		position string
+		want     []string
+	}{
+		{StatusTop, []string{"status", "b", "c"}},
+		// a short frame is filled down to the row kept for the status
+		{StatusBottom, []string{"a", "b", "c", "", "status"}},
+	} {
+		a := &App{width: 6, height: 5, cfg: Config{StatusPosition: c.position}}
+		a.currentLines = []string{"a", "b", "c"}
+		a.overlayStatusLines([]string{"status"})
+		if !slices.Equal(a.currentLines, c.want) {
+			t.Errorf("%s: got %q, want %q", c.position, a.currentLines, c.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package app
+
+import "fmt"
+
+// toggleStatusOverlay shows or hides the status drawn into the SDL window
+// (thread-safe).
+func (a *App) toggleStatusOverlay() {
+	on := !a.statusOverlay.Load()
+	a.statusOverlay.Store(on)
+	a.log.Printf("status overlay: %s", onOff(on))
+}
+
+// statusOverlayLines are the lines the window's status overlay shows: the
+// status as the bar would have it, plain, and the track a now-playing
+// widget follows. raw is the renderer's status. nil hides the overlay.
+func (a *App) statusOverlayLines(raw string, fps float64) []string {
+	if !a.statusOverlay.Load() {
+		return nil
+	}
+	entries := a.statusEntries(raw, fps)
+	var lines []string
+	if a.statusFormat != nil {
+		lines = append(lines, a.statusFormat.line(entries, false))
+	} else {
+		for _, entry := range entries {
+			if entry.value == "" || entry.label == "MODE" {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%-10s %s", entry.label, entry.value))
+		}
+	}
+	if track := a.widgets.nowPlaying(); track != "" {
+		lines = append(lines, "♪ "+track)
+	}
+	return lines
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+const (
+	// tapResetGap is the pause after which a tap starts a new sequence.
+	tapResetGap = 2 * time.Second
+	// tapMaxTaps is how many recent taps are averaged.
+	tapMaxTaps = 8
+	// tapMinBPM and tapMaxBPM bound believable tapped tempos.
+	tapMinBPM = 40.0
+	tapMaxBPM = 240.0
+	// tapReleaseConfidence is the tempo confidence at which audio may take
+	// the beat clock back, once it held for tapReleaseAfter since the last tap.
+	tapReleaseConfidence = 0.6
+	tapReleaseAfter      = 8 * time.Second
+	// tapBeatStrength is the beat strength reported on tapped beats.
+	tapBeatStrength = 0.8
+)
+
+// tapTempo is a manual beat clock for rooms where detection struggles (live
+// bands, noisy venues). While locked it replaces the tempo and onsets of
+// every frame with the tapped grid, so beat effects, the beat scheduler and
+// MIDI clock all follow the taps.
+type tapTempo struct {
+	taps           []time.Time
+	period         time.Duration
+	nextBeat       time.Time
+	beatNow        bool
+	confidentSince time.Time
+}
+
+// Tap registers a tap at now and returns the tapped BPM, 0 until two taps
+// fell close enough together. Each tap is treated as a beat.
+func (t *tapTempo) Tap(now time.Time) float64 {
+	if n := len(t.taps); n > 0 && now.Sub(t.taps[n-1]) > tapResetGap {
+		t.taps = t.taps[:0]
+	}
+	t.taps = append(t.taps, now)
+	if len(t.taps) > tapMaxTaps {
+		t.taps = t.taps[len(t.taps)-tapMaxTaps:]
+	}
+	if len(t.taps) < 2 {
+		return t.BPM()
+	}
+	period := t.taps[len(t.taps)-1].Sub(t.taps[0]) / time.Duration(len(t.taps)-1)
+	bpm := 60 / period.Seconds()
+	if bpm < tapMinBPM || bpm > tapMaxBPM {
+		return t.BPM()
+	}
+	t.period = period
+	t.nextBeat = now.Add(period)
+	t.beatNow = true
+	t.confidentSince = time.Time{}
+	return bpm
+}
+
+// BPM returns the tapped tempo, or 0 when not locked.
+func (t *tapTempo) BPM() float64 {
+	if t.period <= 0 {
+		return 0
+	}
+	return 60 / t.period.Seconds()
+}
+
+// Apply overrides feat with the tapped grid while locked, handing the clock
+// back once the audio tempo stayed confident for tapReleaseAfter.
+func (t *tapTempo) Apply(now time.Time, feat analyzer.Features) analyzer.Features {
+	if t.period <= 0 {
+		return feat
+	}
+	if feat.TempoConfidence >= tapReleaseConfidence {
+		if t.confidentSince.IsZero() {
+			t.confidentSince = now
+		}
+		if now.Sub(t.confidentSince) >= tapReleaseAfter {
+			t.period = 0
+			t.taps = t.taps[:0]
+			return feat
+		}
+	} else {
+		t.confidentSince = time.Time{}
+	}
+
+	onset := t.beatNow
+	t.beatNow = false
+	for !now.Before(t.nextBeat) {
+		onset = true
+		t.nextBeat = t.nextBeat.Add(t.period)
+	}
+	feat.Tempo = t.BPM()
+	feat.TempoConfidence = 1
+	feat.Onset = onset
+	if onset {
+		feat.BeatStrength = max(feat.BeatStrength, tapBeatStrength)
+	}
+	return feat
+}
+
+// Tap feeds the tap-tempo clock (thread-safe). It returns the tapped BPM,
+// 0 until enough taps came in.
+func (a *App) Tap(now time.Time) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.tap.Tap(now)
+}
+
+// TapTempo returns the tapped BPM while it drives the beat clock, else 0
+// (thread-safe).
+func (a *App) TapTempo() float64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.tap.BPM()
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestTapTempo(t *testing.T) {
+	var tap tapTempo
+	start := time.Unix(100, 0)
+	beat := 500 * time.Millisecond // 120 BPM
+	if bpm := tap.Tap(start); bpm != 0 {
+		t.Fatalf("one tap: %v BPM", bpm)
+	}
+	var bpm float64
+	for i := 1; i < 4; i++ {
+		bpm = tap.Tap(start.Add(time.Duration(i) * beat))
+	}
+	if math.Abs(bpm-120) > 0.01 {
+		t.Fatalf("tapped %v BPM, want 120", bpm)
+	}
+
+	// the tap itself is a beat, the next one comes a period later
+	last := start.Add(3 * beat)
+	if f := tap.Apply(last, analyzer.Features{Tempo: 90}); !f.Onset || f.Tempo != bpm || f.TempoConfidence != 1 || f.BeatStrength != tapBeatStrength {
+		t.Errorf("on the tap: %+v", f)
+	}
+	if f := tap.Apply(last.Add(beat/2), analyzer.Features{Onset: true}); f.Onset {
+		t.Error("audio onset between tapped beats kept")
+	}
+	if f := tap.Apply(last.Add(beat), analyzer.Features{}); !f.Onset {
+		t.Error("no onset on the next tapped beat")
+	}
+
+	// a tap after a long pause starts over instead of averaging the gap
+	if bpm := tap.Tap(last.Add(time.Minute)); math.Abs(bpm-120) > 0.01 {
+		t.Errorf("lone tap after a pause changed the tempo to %v", bpm)
+	}
+	// out of range tempos are ignored
+	tap.Tap(last.Add(2 * time.Minute))
+	if bpm := tap.Tap(last.Add(2*time.Minute + 100*time.Millisecond)); math.Abs(bpm-120) > 0.01 {
+		t.Errorf("600 BPM tap changed the tempo to %v", bpm)
+	}
+}
+
+func TestTapTempoHandsBack(t *testing.T) {
+	var tap tapTempo
+	now := time.Unix(100, 0)
+	tap.Tap(now)
+	tap.Tap(now.Add(time.Second))
+	confident := analyzer.Features{Tempo: 128, TempoConfidence: 0.9}
+	for i := 0; i <= 8; i++ {
+		now = now.Add(time.Second)
+		if f := tap.Apply(now, confident); f.Tempo != 60 && i < 8 {
+			t.Fatalf("after %ds the audio tempo %v took over", i, f.Tempo)
+		}
+	}
+	if f := tap.Apply(now.Add(time.Second), confident); f.Tempo != 128 || tap.BPM() != 0 {
+		t.Errorf("after %v of confident audio: tempo %v, tapped %v", tapReleaseAfter, f.Tempo, tap.BPM())
+	}
+}
@@ -0,0 +1,298 @@
+package app
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// cellGap is how many unchanged cells are written again rather than
+// jumping the cursor over them; a jump costs about as many bytes.
+const cellGap = 4
+
+// cell attributes, as SGR turns them on.
+const (
+	attrBold = 1 << iota
+	attrDim
+	attrItalic
+	attrUnderline
+	attrBlink
+	attrReverse
+)
+
+// cellStyle is the SGR state a cell is drawn in. Colours are 256-colour
+// indices, -1 for the terminal's default.
+type cellStyle struct {
+	fg, bg int16
+	attrs  uint8
+}
+
+var defaultStyle = cellStyle{fg: -1, bg: -1}
+
+// termCell is one column of the screen. A wide rune takes its cell and
+// the next, which holds ch 0.
+type termCell struct {
+	ch    rune
+	style cellStyle
+}
+
+// cellScreen is what the terminal shows cell by cell, so a changed row
+// only rewrites the cells that changed, with as little SGR as gets the
+// pen from one cell's style to the next. Between frames the pen is reset.
+type cellScreen struct {
+	rows [][]termCell // nil rows are unknown and get written whole
+	next []termCell
+	pen  cellStyle
+}
+
+// reset forgets what the terminal shows, after a clear or a resize.
+func (s *cellScreen) reset() {
+	s.rows = s.rows[:0]
+}
+
+// drawRow writes to b what turns row y of a width columns wide screen
+// into line.
+func (s *cellScreen) drawRow(b *strings.Builder, y, width int, line string) {
+	for len(s.rows) <= y {
+		s.rows = append(s.rows, nil)
+	}
+	next, ok := parseCells(s.next[:0], line)
+	s.next = next
+	if !ok {
+		// escapes or characters the cells can't model: write the row
+		// as is and take nothing for granted about it afterwards
+		appendCursorMove(b, y+1)
+		b.WriteString(line)
+		b.WriteString("\x1b[0m\x1b[K")
+		s.rows[y] = nil
+		return
+	}
+
+	prev := s.rows[y]
+	known := prev != nil
+	cursor := -1
+	for x, c := range next {
+		if c.ch == 0 || known && x < len(prev) && prev[x] == c {
+			continue
+		}
+		if cursor < 0 || x < cursor || x-cursor > cellGap {
+			appendCellMove(b, y+1, x+1)
+		} else {
+			for _, skipped := range next[cursor:x] {
+				s.writeCell(b, skipped)
+			}
+		}
+		s.writeCell(b, c)
+		cursor = x + 1
+		if x+1 < len(next) && next[x+1].ch == 0 {
+			cursor++
+		}
+	}
+	if !known && (width <= 0 || len(next) < width) || known && len(next) < len(prev) {
+		if cursor != len(next) {
+			appendCellMove(b, y+1, len(next)+1)
+		}
+		s.setPen(b, defaultStyle)
+		b.WriteString("\x1b[K")
+	}
+	s.rows[y] = append(prev[:0], next...)
+}
+
+// clearRow blanks row y, a row the frame no longer has.
+func (s *cellScreen) clearRow(b *strings.Builder, y int) {
+	appendCursorMove(b, y+1)
+	s.setPen(b, defaultStyle)
+	b.WriteString("\x1b[K")
+	if y < len(s.rows) {
+		s.rows[y] = s.rows[y][:0]
+	}
+}
+
+// finish puts the pen back to the default at the end of a frame.
+func (s *cellScreen) finish(b *strings.Builder) {
+	s.setPen(b, defaultStyle)
+}
+
+func (s *cellScreen) writeCell(b *strings.Builder, c termCell) {
+	if c.ch == 0 {
+		return // the second half of a wide rune, drawn with the first
+	}
+	s.setPen(b, c.style)
+	b.WriteRune(c.ch)
+}
+
+// setPen writes the shortest SGR that changes the pen to style: the
+// changes alone, or a reset and what style sets.
+func (s *cellScreen) setPen(b *strings.Builder, style cellStyle) {
+	if s.pen == style {
+		return
+	}
+	var diff, full [48]byte
+	d := appendStyleChange(diff[:0], s.pen, style)
+	f := appendStyleChange(append(full[:0], '0'), defaultStyle, style)
+	if len(f) < len(d) {
+		d = f
+	}
+	b.WriteString("\x1b[")
+	b.Write(d)
+	b.WriteByte('m')
+	s.pen = style
+}
+
+// appendStyleChange appends the SGR parameters that turn from into to.
+func appendStyleChange(dst []byte, from, to cellStyle) []byte {
+	param := func(p string) {
+		if len(dst) > 0 {
+			dst = append(dst, ';')
+		}
+		dst = append(dst, p...)
+	}
+	colour := func(prefix, reset string, c int16) {
+		if c < 0 {
+			param(reset)
+			return
+		}
+		param(prefix)
+		dst = strconv.AppendInt(dst, int64(c), 10)
+	}
+	// bold and dim share their off code
+	off := from.attrs &^ to.attrs
+	on := to.attrs &^ from.attrs
+	if off&(attrBold|attrDim) != 0 {
+		param("22")
+		on |= to.attrs & (attrBold | attrDim)
+	}
+	for _, a := range []struct {
+		attr    uint8
+		on, off string
+	}{
+		{attrBold, "1", ""},
+		{attrDim, "2", ""},
+		{attrItalic, "3", "23"},
+		{attrUnderline, "4", "24"},
+		{attrBlink, "5", "25"},
+		{attrReverse, "7", "27"},
+	} {
+		if off&a.attr != 0 && a.off != "" {
+			param(a.off)
+		}
+		if on&a.attr != 0 {
+			param(a.on)
+		}
+	}
+	if from.fg != to.fg {
+		colour("38;5;", "39", to.fg)
+	}
+	if from.bg != to.bg {
+		colour("48;5;", "49", to.bg)
+	}
+	return dst
+}
+
+// parseCells splits line into cells, reusing dst. It fails on escapes
+// other than SGR colours and attributes and on runes that take no column
+// (controls, combining marks), whose place on screen is up to the
+// terminal.
+func parseCells(dst []termCell, line string) ([]termCell, bool) {
+	style := defaultStyle
+	for i := 0; i < len(line); {
+		if line[i] == 0x1b {
+			end := i + 2
+			if end > len(line) || line[i+1] != '[' {
+				return dst, false
+			}
+			for end < len(line) && (line[end] >= '0' && line[end] <= '9' || line[end] == ';') {
+				end++
+			}
+			if end >= len(line) || line[end] != 'm' || !applySGR(&style, line[i+2:end]) {
+				return dst, false
+			}
+			i = end + 1
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		w := runewidth.RuneWidth(r)
+		if r == utf8.RuneError || w == 0 {
+			return dst, false
+		}
+		dst = append(dst, termCell{ch: r, style: style})
+		if w == 2 {
+			dst = append(dst, termCell{style: style})
+		}
+		i += size
+	}
+	return dst, true
+}
+
+// applySGR applies the parameters of one SGR sequence to style.
+func applySGR(style *cellStyle, params string) bool {
+	var codes [16]int
+	n := 0
+	for field := range strings.SplitSeq(params, ";") {
+		if n == len(codes) {
+			return false
+		}
+		if field != "" {
+			code, err := strconv.Atoi(field)
+			if err != nil {
+				return false
+			}
+			codes[n] = code
+		}
+		n++
+	}
+	for i := 0; i < n; i++ {
+		switch c := codes[i]; {
+		case c == 0:
+			*style = defaultStyle
+		case c >= 1 && c <= 5:
+			style.attrs |= 1 << (c - 1)
+		case c == 7:
+			style.attrs |= attrReverse
+		case c == 22:
+			style.attrs &^= attrBold | attrDim
+		case c == 23:
+			style.attrs &^= attrItalic
+		case c == 24:
+			style.attrs &^= attrUnderline
+		case c == 25:
+			style.attrs &^= attrBlink
+		case c == 27:
+			style.attrs &^= attrReverse
+		case c >= 30 && c <= 37:
+			style.fg = int16(c - 30)
+		case c >= 90 && c <= 97:
+			style.fg = int16(c - 90 + 8)
+		case c == 39:
+			style.fg = -1
+		case c >= 40 && c <= 47:
+			style.bg = int16(c - 40)
+		case c >= 100 && c <= 107:
+			style.bg = int16(c - 100 + 8)
+		case c == 49:
+			style.bg = -1
+		case (c == 38 || c == 48) && i+2 < n && codes[i+1] == 5 && codes[i+2] <= 255:
+			// 256-colour indices only
+			if c == 38 {
+				style.fg = int16(codes[i+2])
+			} else {
+				style.bg = int16(codes[i+2])
+			}
+			i += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// appendCellMove moves the cursor to row, col (1-based).
+func appendCellMove(b *strings.Builder, row, col int) {
+	b.WriteString("\x1b[")
+	b.WriteString(strconv.Itoa(row))
+	b.WriteByte(';')
+	b.WriteString(strconv.Itoa(col))
+	b.WriteByte('H')
+}
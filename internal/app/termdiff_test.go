@@ -0,0 +1,257 @@
+package app
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func TestApplySGR(t *testing.T) {
+	bold := cellStyle{fg: 3, bg: -1, attrs: attrBold | attrDim | attrUnderline}
+	for _, tc := range []struct {
+		from   cellStyle
+		params string
+		want   cellStyle
+		ok     bool
+	}{
+		{defaultStyle, "38;5;202", cellStyle{fg: 202, bg: -1}, true},
+		{defaultStyle, "48;5;17", cellStyle{fg: -1, bg: 17}, true},
+		{defaultStyle, "1;38;5;0;48;5;255", cellStyle{fg: 0, bg: 255, attrs: attrBold}, true},
+		{defaultStyle, "31", cellStyle{fg: 1, bg: -1}, true},
+		{defaultStyle, "37", cellStyle{fg: 7, bg: -1}, true},
+		{defaultStyle, "90", cellStyle{fg: 8, bg: -1}, true},
+		{defaultStyle, "97", cellStyle{fg: 15, bg: -1}, true},
+		{defaultStyle, "44;103", cellStyle{fg: -1, bg: 11}, true},
+		{bold, "22", cellStyle{fg: 3, bg: -1, attrs: attrUnderline}, true},
+		{bold, "24;39", cellStyle{fg: -1, bg: -1, attrs: attrBold | attrDim}, true},
+		{bold, "0", defaultStyle, true},
+		{bold, "", defaultStyle, true},
+		{defaultStyle, "2;3;5;7", cellStyle{fg: -1, bg: -1, attrs: attrDim | attrItalic | attrBlink | attrReverse}, true},
+		{defaultStyle, "38;2;1;2;3", defaultStyle, false}, // truecolor
+		{defaultStyle, "38;5;300", defaultStyle, false},
+		{defaultStyle, "38;5", defaultStyle, false},
+		{defaultStyle, "6", defaultStyle, false},
+		{defaultStyle, "1;x", defaultStyle, false},
+		{defaultStyle, strings.Repeat("1;", 16) + "1", defaultStyle, false},
+	} {
+		style := tc.from
+		ok := applySGR(&style, tc.params)
+		if ok != tc.ok || ok && style != tc.want {
+			t.Errorf("%+v + %q: %+v %v, want %+v %v", tc.from, tc.params, style, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestParseCells(t *testing.T) {
+	for _, tc := range []struct {
+		line  string
+		cells int
+		ok    bool
+	}{
+		{"plain", 5, true},
+		{"\x1b[1;31mhi\x1b[0m!", 3, true},
+		{"你好", 4, true},
+		{"\x1b[2Jx", 0, false},                // not SGR
+		{"\x1b]0;title\x07x", 0, false},       // OSC
+		{"\x1b[38;2;1;2;3mx", 0, false},       // truecolor
+		{"e\u0301", 0, false},                 // combining mark
+		{"a\tb", 0, false},                    // control
+		{"x\x1b[", 0, false},                  // cut off
+		{string([]byte{'a', 0xff}), 0, false}, // invalid UTF-8
+	} {
+		cells, ok := parseCells(nil, tc.line)
+		if ok != tc.ok || ok && len(cells) != tc.cells {
+			t.Errorf("%q: %d cells %v, want %d %v", tc.line, len(cells), ok, tc.cells, tc.ok)
+		}
+	}
+
+	cells, _ := parseCells(nil, "\x1b[31m你\x1b[0mx")
+	want := []termCell{
+		{ch: '你', style: cellStyle{fg: 1, bg: -1}},
+		{style: cellStyle{fg: 1, bg: -1}},
+		{ch: 'x', style: defaultStyle},
+	}
+	if len(cells) != len(want) {
+		t.Fatalf("wide rune cells %+v, want %+v", cells, want)
+	}
+	for i := range want {
+		if cells[i] != want[i] {
+			t.Errorf("cell %d: %+v, want %+v", i, cells[i], want[i])
+		}
+	}
+}
+
+// TestDrawRowReplays draws each row's frames in turn, plays what drawRow
+// wrote on a small terminal emulator and checks the screen shows the line.
+func TestDrawRowReplays(t *testing.T) {
+	const width = 20
+	for _, tc := range []struct {
+		name   string
+		frames []string
+	}{
+		{"unchanged cells", []string{"hello world", "hello world", "hellO worlD"}},
+		{"gaps", []string{"abcdefghijklmnop", "Abcdefghijklmnop", "AbCdefghijklmnoP", "abcdefghijklmnop"}},
+		{"wide rune then a change", []string{"你好x", "你好y", "你a好y", "ab你好y", "你好"}},
+		{"wide rune over narrow", []string{"abcdef", "a你def", "a你d好"}},
+		{"shrinking clears the tail", []string{"hello world", "hi", "", "hey"}},
+		{"growing", []string{"hi", "hello world"}},
+		{"styles", []string{
+			"\x1b[1;2mab\x1b[22mc\x1b[0m",
+			"\x1b[1;2mab\x1b[22;31mc\x1b[0m",
+			"\x1b[38;5;202;48;5;17mab\x1b[0mc",
+			"\x1b[7mabc",
+			"abc",
+		}},
+		{"fallback and back", []string{"hello", "\x1b[38;2;9;9;9mhe\x1b[0mllo", "hello", "he\x1b[2Jllo", "help"}},
+		{"full width", []string{strings.Repeat("x", width), strings.Repeat("y", width), "z"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var s cellScreen
+			term := newTestTerm(width, 3)
+			for i, line := range tc.frames {
+				var b strings.Builder
+				s.drawRow(&b, 1, width, line)
+				s.finish(&b)
+				out := b.String()
+				term.play(out)
+				if _, ok := parseCells(nil, line); !ok {
+					continue // written as is; the next frame repaints it
+				}
+				if got, want := term.line(1), screenLine(line, width); got != want {
+					t.Fatalf("frame %d %q: screen shows %q, want %q (wrote %q)", i, line, got, want, out)
+				}
+				if term.pen != defaultStyle {
+					t.Fatalf("frame %d: pen left at %+v", i, term.pen)
+				}
+				if i > 0 && line == tc.frames[i-1] && s.rows[1] != nil && out != "" {
+					t.Fatalf("frame %d unchanged, wrote %q", i, out)
+				}
+			}
+		})
+	}
+}
+
+func TestDrawRowShrinkErasesTail(t *testing.T) {
+	var s cellScreen
+	var b strings.Builder
+	s.drawRow(&b, 0, 20, "hello world")
+	b.Reset()
+	s.drawRow(&b, 0, 20, "hello")
+	if got := b.String(); got != "\x1b[1;6H\x1b[K" {
+		t.Fatalf("shrink wrote %q, want a jump to the new end and an erase", got)
+	}
+}
+
+func TestClearRow(t *testing.T) {
+	var s cellScreen
+	term := newTestTerm(10, 2)
+	var b strings.Builder
+	s.drawRow(&b, 1, 10, "\x1b[31mred")
+	s.clearRow(&b, 1)
+	term.play(b.String())
+	if got := term.line(1); got != strings.Repeat(" ", 10) {
+		t.Fatalf("cleared row shows %q", got)
+	}
+	b.Reset()
+	s.drawRow(&b, 1, 10, "\x1b[31mred")
+	term.play(b.String())
+	if got := term.line(1); got != screenLine("\x1b[31mred", 10) {
+		t.Fatalf("row redrawn after a clear shows %q", got)
+	}
+}
+
+// testTerm is just enough of a terminal to replay what cellScreen writes:
+// cursor moves, SGR, erase to the end of the line and text.
+type testTerm struct {
+	cells [][]termCell
+	y, x  int
+	pen   cellStyle
+}
+
+func newTestTerm(width, height int) *testTerm {
+	t := &testTerm{pen: defaultStyle}
+	for range height {
+		row := make([]termCell, width)
+		for i := range row {
+			row[i] = termCell{ch: ' ', style: defaultStyle}
+		}
+		t.cells = append(t.cells, row)
+	}
+	return t
+}
+
+func (t *testTerm) play(out string) {
+	for i := 0; i < len(out); {
+		if out[i] == 0x1b && i+1 < len(out) && out[i+1] == '[' {
+			end := i + 2
+			for end < len(out) && (out[end] >= '0' && out[end] <= '9' || out[end] == ';') {
+				end++
+			}
+			params := out[i+2 : end]
+			switch out[end] {
+			case 'H':
+				row, col, _ := strings.Cut(params, ";")
+				r, _ := strconv.Atoi(row)
+				c, _ := strconv.Atoi(col)
+				t.y, t.x = max(r, 1)-1, max(c, 1)-1
+			case 'm':
+				if !applySGR(&t.pen, params) {
+					t.pen = cellStyle{fg: -2, bg: -2} // an SGR the cells don't model
+				}
+			case 'K':
+				for x := t.x; x < len(t.cells[t.y]); x++ {
+					t.cells[t.y][x] = termCell{ch: ' ', style: t.pen}
+				}
+			case 'J':
+				// the fallback test's stray erase; the row is redrawn after it
+			}
+			i = end + 1
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(out[i:])
+		i += size
+		w := runewidth.RuneWidth(r)
+		if t.x+w > len(t.cells[t.y]) {
+			continue
+		}
+		t.cells[t.y][t.x] = termCell{ch: r, style: t.pen}
+		if w == 2 {
+			t.cells[t.y][t.x+1] = termCell{style: t.pen}
+		}
+		t.x += w
+	}
+}
+
+// line renders row y as text with the style of every cell spelled out, so
+// a wrong colour fails as surely as a wrong character.
+func (t *testTerm) line(y int) string {
+	return renderCells(t.cells[y])
+}
+
+// screenLine is what a width columns wide row shows once line is drawn;
+// line has to be one parseCells takes.
+func screenLine(line string, width int) string {
+	cells, _ := parseCells(nil, line)
+	for len(cells) < width {
+		cells = append(cells, termCell{ch: ' ', style: defaultStyle})
+	}
+	return renderCells(cells)
+}
+
+func renderCells(cells []termCell) string {
+	var b strings.Builder
+	style := defaultStyle
+	for _, c := range cells {
+		if c.style != style {
+			b.WriteString("{" + strconv.Itoa(int(c.style.fg)) + "," + strconv.Itoa(int(c.style.bg)) + "," + strconv.Itoa(int(c.style.attrs)) + "}")
+			style = c.style
+		}
+		if c.ch != 0 {
+			b.WriteRune(c.ch)
+		}
+	}
+	return b.String()
+}
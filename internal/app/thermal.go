@@ -0,0 +1,99 @@
+package app
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// thermalDwell is the least time between two quality steps, so one step
+// can show in the temperature before the next is taken.
+const thermalDwell = 30 * time.Second
+
+// thermalSteps are the qualities the guard moves between, best first.
+var thermalSteps = []string{"high", "balanced", "eco"}
+
+// thermalGuard steps the render quality down while the SoC runs hot or is
+// throttled, and back up to the quality asked for once it has cooled off.
+type thermalGuard struct {
+	hot, cool float64 // °C
+	base      string  // the quality asked for
+	applied   string  // the quality the guard last saw or set
+	lastStep  time.Time
+}
+
+// newThermalGuard returns nil when hot is 0, the guard is off. A cool at or
+// above hot is moved 10 °C below it.
+func newThermalGuard(hot, cool float64) *thermalGuard {
+	if hot <= 0 {
+		return nil
+	}
+	if cool <= 0 || cool >= hot {
+		cool = hot - 10
+	}
+	return &thermalGuard{hot: hot, cool: cool}
+}
+
+// next returns the quality to switch to, if any. current is the renderer's
+// quality; when it isn't the one the guard set, someone picked it and it
+// becomes the one to return to.
+func (g *thermalGuard) next(now time.Time, current string, temp float64, hasTemp, throttled bool) (string, bool) {
+	if current != g.applied {
+		g.base, g.applied = current, current
+	}
+	if !g.lastStep.IsZero() && now.Sub(g.lastStep) < thermalDwell {
+		return "", false
+	}
+	i := slices.Index(thermalSteps, current)
+	base := slices.Index(thermalSteps, g.base)
+	if i < 0 || base < 0 {
+		return "", false
+	}
+	hot := throttled || hasTemp && temp >= g.hot
+	cool := !throttled && hasTemp && temp <= g.cool
+	switch {
+	case hot && i < len(thermalSteps)-1:
+		i++
+	case cool && i > base:
+		i--
+	default:
+		return "", false
+	}
+	g.applied, g.lastStep = thermalSteps[i], now
+	return g.applied, true
+}
+
+// throttling reports whether a throttle status from readThrottleStatus says
+// the SoC is capped or throttled right now; the WAS flags are history.
+func throttling(status string) bool {
+	for _, flag := range strings.Split(status, ", ") {
+		if flag == "ARM CAPPED" || flag == "THROTTLED" {
+			return true
+		}
+	}
+	return false
+}
+
+// adaptQuality lets the thermal guard step the quality on the temperature
+// and throttle readings.
+func (a *App) adaptQuality(now time.Time) {
+	if a.thermal == nil {
+		return
+	}
+	temp, hasTemp := a.temperature()
+	throttled := throttling(a.lastThrottle)
+	current := a.renderer.QualityName()
+	quality, ok := a.thermal.next(now, current, temp, hasTemp, throttled)
+	if !ok {
+		return
+	}
+	reason := "cooled off"
+	switch {
+	case throttled:
+		reason = "throttled (" + a.lastThrottle + ")"
+	case hasTemp && temp >= a.thermal.hot:
+		reason = "running hot"
+	}
+	a.log.Printf("thermal: %.1f°C, %s, quality %s -> %s", temp, reason, current, quality)
+	a.renderer.SetQuality(quality)
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewThermalGuard(t *testing.T) {
+	if g := newThermalGuard(0, 60); g != nil {
+		t.Errorf("hot 0: got %+v, want off", g)
+	}
+	cases := []struct{ hot, cool, want float64 }{
+		{80, 65, 65},
+		{80, 0, 70},
+		{80, 85, 70},
+	}
+	for _, c := range cases {
+		if g := newThermalGuard(c.hot, c.cool); g.cool != c.want {
+			t.Errorf("hot %v cool %v: got cool %v, want %v", c.hot, c.cool, g.cool, c.want)
+		}
+	}
+}
+
+func TestThermalGuardSteps(t *testing.T) {
+	g := newThermalGuard(80, 70)
+	now := time.Unix(0, 0)
+	current := "high"
+	steps := []struct {
+		after     time.Duration
+		temp      float64
+		hasTemp   bool
+		throttled bool
+		want      string // the quality after the reading
+	}{
+		{0, 75, true, false, "high"},
+		{time.Second, 82, true, false, "balanced"},
+		{10 * time.Second, 90, true, false, "balanced"}, // still in the dwell
+		{thermalDwell, 85, true, false, "eco"},
+		{thermalDwell, 95, true, false, "eco"}, // nothing lower
+		{thermalDwell, 72, true, false, "eco"}, // between the two: hold
+		{thermalDwell, 65, true, false, "balanced"},
+		{thermalDwell, 0, false, true, "eco"},  // throttled without a sensor
+		{thermalDwell, 0, false, false, "eco"}, // no reading to cool off on
+		{thermalDwell, 60, true, false, "balanced"},
+		{thermalDwell, 60, true, false, "high"},
+		{thermalDwell, 60, true, false, "high"}, // never above the one asked for
+	}
+	for i, s := range steps {
+		now = now.Add(s.after)
+		if q, ok := g.next(now, current, s.temp, s.hasTemp, s.throttled); ok {
+			current = q
+		}
+		if current != s.want {
+			t.Fatalf("reading %d (%v°C, throttled %v): quality %s, want %s", i, s.temp, s.throttled, current, s.want)
+		}
+	}
+}
+
+func TestThermalGuardFollowsManualQuality(t *testing.T) {
+	g := newThermalGuard(80, 70)
+	now := time.Unix(0, 0)
+	if q, ok := g.next(now, "high", 85, true, false); !ok || q != "balanced" {
+		t.Fatalf("hot: got %q %v", q, ok)
+	}
+	// someone picks eco by hand: that is what it returns to
+	now = now.Add(thermalDwell)
+	if q, ok := g.next(now, "eco", 50, true, false); ok {
+		t.Errorf("cool at the picked quality: stepped to %q", q)
+	}
+	if g.base != "eco" {
+		t.Errorf("base %q, want eco", g.base)
+	}
+	// qualities the guard doesn't step between are left alone
+	if q, ok := g.next(now.Add(thermalDwell), "custom", 99, true, true); ok {
+		t.Errorf("unknown quality: stepped to %q", q)
+	}
+}
+
+func TestThrottling(t *testing.T) {
+	cases := []struct {
+		status string
+		want   bool
+	}{
+		{"", false},
+		{"ARM CAPPED", true},
+		{"UNDERVOLTAGE, THROTTLED", true},
+		{"WAS THROTTLED, WAS ARM CAPPED", false},
+		{"UNDERVOLTAGE", false},
+	}
+	for _, c := range cases {
+		if got := throttling(c.status); got != c.want {
+			t.Errorf("%q: got %v, want %v", c.status, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+const (
+	// triggerMaxDuration bounds how long one trigger may hold the screen.
+	triggerMaxDuration = 30 * time.Second
+	// strobeHz is how often the strobe flashes.
+	strobeHz = 12
+	// triggerBrightness is how bright flash, strobe and drop go.
+	triggerBrightness = 2.5
+)
+
+// triggerDefaults are the one-shot effects with how long each lasts
+// unless asked otherwise.
+var triggerDefaults = map[string]time.Duration{
+	"flash":    300 * time.Millisecond,
+	"strobe":   1500 * time.Millisecond,
+	"drop":     600 * time.Millisecond,
+	"blackout": 2 * time.Second,
+}
+
+// TriggerNames lists the one-shot effects Trigger knows, sorted.
+func TriggerNames() []string {
+	names := make([]string, 0, len(triggerDefaults))
+	for name := range triggerDefaults {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// activeTrigger is a one-shot effect on screen.
+type activeTrigger struct {
+	name     string
+	start    time.Time
+	duration time.Duration
+}
+
+// triggers are the manual one-shot effects a VJ punctuates moments with.
+// They override the brightness (and, for drop, kick the distortion) of the
+// params that are rendered, on top of whatever the music does; the audio
+// driven params underneath keep running.
+type triggers struct {
+	active []activeTrigger
+	kick   bool // a drop waiting to hit the params
+}
+
+// start adds name for d, the effect's default length when d is 0.
+func (t *triggers) start(name string, d time.Duration, now time.Time) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	def, ok := triggerDefaults[name]
+	if !ok {
+		return fmt.Errorf("unknown trigger %q (want %s)", name, strings.Join(TriggerNames(), ", "))
+	}
+	if d < 0 || d > triggerMaxDuration {
+		return fmt.Errorf("trigger duration must be between 0 and %s", triggerMaxDuration)
+	}
+	if d == 0 {
+		d = def
+	}
+	// the same effect again restarts it
+	t.active = slices.DeleteFunc(t.active, func(a activeTrigger) bool { return a.name == name })
+	t.active = append(t.active, activeTrigger{name: name, start: now, duration: d})
+	if name == "drop" {
+		t.kick = true
+	}
+	return nil
+}
+
+// takeKick reports whether a drop was triggered since the last call.
+func (t *triggers) takeKick() bool {
+	kick := t.kick
+	t.kick = false
+	return kick
+}
+
+// apply overrides p with the effects active at now and forgets the ones
+// that ran out. A blackout wins over everything else.
+func (t *triggers) apply(now time.Time, p params.Parameters) params.Parameters {
+	t.active = slices.DeleteFunc(t.active, func(a activeTrigger) bool { return now.Sub(a.start) >= a.duration })
+	blackout := false
+	for _, a := range t.active {
+		elapsed := now.Sub(a.start)
+		fade := 1 - elapsed.Seconds()/a.duration.Seconds()
+		switch a.name {
+		case "flash", "drop":
+			p.Brightness = math.Max(p.Brightness, triggerBrightness*fade)
+		case "strobe":
+			if int(elapsed.Seconds()*strobeHz*2)%2 == 0 {
+				p.Brightness = triggerBrightness
+			} else {
+				p.Brightness = 0
+			}
+		case "blackout":
+			blackout = true
+		}
+	}
+	if blackout {
+		p.Brightness = 0
+		p.BeatDistortion = 0
+	}
+	return p
+}
+
+// Trigger fires a one-shot effect (flash, strobe, drop or blackout) for d,
+// or for the effect's default length when d is 0 (thread-safe).
+func (a *App) Trigger(name string, d time.Duration) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.triggers.start(name, d, time.Now())
+}
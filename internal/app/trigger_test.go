@@ -0,0 +1,85 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+func TestTriggerStart(t *testing.T) {
+	var tr triggers
+	now := time.Unix(0, 0)
+	cases := []struct {
+		name string
+		d    time.Duration
+		ok   bool
+	}{
+		{"flash", 0, true},
+		{" Strobe ", time.Second, true},
+		{"drop", triggerMaxDuration, true},
+		{"confetti", 0, false},
+		{"blackout", -time.Second, false},
+		{"blackout", triggerMaxDuration + 1, false},
+	}
+	for _, c := range cases {
+		if err := tr.start(c.name, c.d, now); (err == nil) != c.ok {
+			t.Errorf("%q for %v: got %v, want ok %v", c.name, c.d, err, c.ok)
+		}
+	}
+	if len(tr.active) != 3 || tr.active[0].duration != triggerDefaults["flash"] || tr.active[1].name != "strobe" {
+		t.Errorf("active %+v", tr.active)
+	}
+	if !tr.takeKick() || tr.takeKick() {
+		t.Error("drop should kick exactly once")
+	}
+	// again restarts it instead of stacking
+	tr.start("flash", 0, now.Add(time.Second))
+	if len(tr.active) != 3 || tr.active[2].name != "flash" || !tr.active[2].start.Equal(now.Add(time.Second)) {
+		t.Errorf("restarted flash: active %+v", tr.active)
+	}
+}
+
+func TestTriggerApply(t *testing.T) {
+	base := params.Defaults()
+	base.Brightness = 1
+	base.BeatDistortion = 0.5
+	now := time.Unix(0, 0)
+	at := func(d time.Duration) time.Time { return now.Add(d) }
+
+	var tr triggers
+	tr.start("flash", time.Second, now)
+	if got := tr.apply(at(0), base).Brightness; got != triggerBrightness {
+		t.Errorf("flash start: brightness %v", got)
+	}
+	// fading out, but never dimmer than the music
+	if got := tr.apply(at(500*time.Millisecond), base).Brightness; got != triggerBrightness/2 {
+		t.Errorf("flash half way: brightness %v", got)
+	}
+	if got := tr.apply(at(900*time.Millisecond), base).Brightness; got != 1 {
+		t.Errorf("flash tail: brightness %v, want the music's 1", got)
+	}
+	if tr.apply(at(time.Second), base); len(tr.active) != 0 {
+		t.Errorf("flash outlived its duration: %+v", tr.active)
+	}
+
+	tr.start("strobe", time.Second, now)
+	for _, c := range []struct {
+		at   time.Duration
+		want float64
+	}{
+		// 12 Hz: on for the first 1/24s, off for the next
+		{20 * time.Millisecond, triggerBrightness},
+		{60 * time.Millisecond, 0},
+		{100 * time.Millisecond, triggerBrightness},
+	} {
+		if got := tr.apply(at(c.at), base).Brightness; got != c.want {
+			t.Errorf("strobe at %v: brightness %v, want %v", c.at, got, c.want)
+		}
+	}
+
+	tr.start("blackout", time.Second, now)
+	if got := tr.apply(at(0), base); got.Brightness != 0 || got.BeatDistortion != 0 {
+		t.Errorf("blackout over strobe: brightness %v distortion %v", got.Brightness, got.BeatDistortion)
+	}
+}
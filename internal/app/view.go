@@ -0,0 +1,37 @@
+package app
+
+import (
+	"slices"
+	"time"
+)
+
+// viewIdle is how long frames keep being published for remote viewers
+// after the last one asked for a frame.
+const viewIdle = 2 * time.Second
+
+// ViewFrame returns the newest terminal frame, status bar included, and a
+// sequence number that changes with every frame (thread-safe). The rows
+// must not be modified. ok is false when the backend draws pixels instead
+// of text. Frames are only published while someone calls ViewFrame, so
+// there is no cost without remote viewers.
+func (a *App) ViewFrame() (lines []string, seq uint64, ok bool) {
+	a.viewWanted.Store(time.Now().UnixNano())
+	if !a.textFrames {
+		return nil, 0, false
+	}
+	a.viewMu.Lock()
+	defer a.viewMu.Unlock()
+	return a.viewLines, a.viewSeq, true
+}
+
+// publishView hands the frame just drawn to remote viewers, if any.
+func (a *App) publishView(lines []string) {
+	if time.Since(time.Unix(0, a.viewWanted.Load())) > viewIdle {
+		return
+	}
+	lines = slices.Clone(lines)
+	a.viewMu.Lock()
+	a.viewLines = lines
+	a.viewSeq++
+	a.viewMu.Unlock()
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestViewFrame(t *testing.T) {
+	a := &App{textFrames: true}
+
+	// nobody watching: publishing is free and keeps nothing
+	a.publishView([]string{"old"})
+	if a.viewLines != nil || a.viewSeq != 0 {
+		t.Fatalf("published without a viewer: %q seq %d", a.viewLines, a.viewSeq)
+	}
+
+	if lines, seq, ok := a.ViewFrame(); !ok || lines != nil || seq != 0 {
+		t.Fatalf("first ViewFrame: got %q %d %v", lines, seq, ok)
+	}
+	frame := []string{"one", "two"}
+	a.publishView(frame)
+	frame[0] = "reused"
+	lines, seq, ok := a.ViewFrame()
+	if !ok || seq != 1 || !slices.Equal(lines, []string{"one", "two"}) {
+		t.Fatalf("after publish: got %q %d %v", lines, seq, ok)
+	}
+
+	a.textFrames = false
+	if _, _, ok := a.ViewFrame(); ok {
+		t.Error("ViewFrame ok on a pixel backend")
+	}
+}
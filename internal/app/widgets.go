@@ -0,0 +1,148 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/nowplaying"
+	"github.com/guidoenr/golizer/internal/render"
+)
+
+// WidgetConfig places a built-in readout in a corner of the screen.
+type WidgetConfig struct {
+	// Kind is clock, stats (fps and temperature) or nowplaying.
+	Kind string `json:"kind"`
+	// Corner is top-left, top-right, bottom-left or bottom-right.
+	Corner string `json:"corner"`
+	// Format is the clock's Go time layout, 15:04 by default.
+	Format string `json:"format,omitempty"`
+	// Source is where nowplaying reads the track: mpris (the default) or
+	// shairport:<metadata pipe>.
+	Source string `json:"source,omitempty"`
+}
+
+const (
+	widgetClock      = "clock"
+	widgetStats      = "stats"
+	widgetNowPlaying = "nowplaying"
+)
+
+// widgetStatsEvery is how often the stats readout changes, so the fps
+// digits stay readable.
+const widgetStatsEvery = 500 * time.Millisecond
+
+type widget struct {
+	kind   string
+	corner int
+	format string
+	source nowplaying.Source
+	track  atomic.Pointer[string]
+}
+
+// widgetSet keeps the configured widgets and works out their lines.
+type widgetSet struct {
+	widgets []*widget
+	frames  int
+	since   time.Time
+	stats   string
+}
+
+// newWidgets validates cfgs; it returns nil without widgets.
+func newWidgets(cfgs []WidgetConfig) (*widgetSet, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+	set := &widgetSet{}
+	for _, cfg := range cfgs {
+		w := &widget{
+			kind:   strings.ToLower(strings.TrimSpace(cfg.Kind)),
+			corner: render.CornerIndex(cfg.Corner),
+			format: cfg.Format,
+		}
+		if w.corner < 0 {
+			return nil, fmt.Errorf("widget %s: unknown corner %q (want %s)", w.kind, cfg.Corner, strings.Join(render.Corners, ", "))
+		}
+		switch w.kind {
+		case widgetClock:
+			if w.format == "" {
+				w.format = "15:04"
+			}
+		case widgetStats:
+		case widgetNowPlaying:
+			src, err := nowplaying.Open(cfg.Source)
+			if err != nil {
+				return nil, fmt.Errorf("widget %s: %w", w.kind, err)
+			}
+			w.source = src
+		default:
+			return nil, fmt.Errorf("unknown widget %q (want %s, %s or %s)", cfg.Kind, widgetClock, widgetStats, widgetNowPlaying)
+		}
+		set.widgets = append(set.widgets, w)
+	}
+	return set, nil
+}
+
+// follow keeps the now-playing widgets current until ctx is done.
+func (s *widgetSet) follow(ctx context.Context, logf func(string, ...any)) {
+	if s == nil {
+		return
+	}
+	for _, w := range s.widgets {
+		if w.source == nil {
+			continue
+		}
+		go nowplaying.Follow(ctx, w.source, func(track string) { w.track.Store(&track) }, logf)
+	}
+}
+
+// nowPlaying is the track the first now-playing widget that has one
+// follows, "" without.
+func (s *widgetSet) nowPlaying() string {
+	if s == nil {
+		return ""
+	}
+	for _, w := range s.widgets {
+		if track := w.track.Load(); track != nil && *track != "" {
+			return *track
+		}
+	}
+	return ""
+}
+
+// lines returns the text of every corner at now. temp reports the
+// temperature in °C, ok false when there is no sensor.
+func (s *widgetSet) lines(now time.Time, temp func() (float64, bool)) [4][]string {
+	var out [4][]string
+	s.frames++
+	if s.since.IsZero() {
+		s.since = now
+	}
+	if elapsed := now.Sub(s.since); elapsed >= widgetStatsEvery {
+		s.stats = fmt.Sprintf("%.0f FPS", float64(s.frames)/elapsed.Seconds())
+		if c, ok := temp(); ok {
+			s.stats += fmt.Sprintf(" %.0fC", c)
+		}
+		s.frames, s.since = 0, now
+	}
+
+	for _, w := range s.widgets {
+		var line string
+		switch w.kind {
+		case widgetClock:
+			line = now.Format(w.format)
+		case widgetStats:
+			line = s.stats
+		case widgetNowPlaying:
+			if track := w.track.Load(); track != nil {
+				line = *track
+			}
+		}
+		if line != "" {
+			out[w.corner] = append(out[w.corner], line)
+		}
+	}
+	return out
+}
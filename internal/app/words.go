@@ -0,0 +1,109 @@
+package app
+
+import (
+	"math"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/lyrics"
+)
+
+const (
+	// wordFlashDecay is how fast a flashed word fades between beats.
+	wordFlashDecay = 0.35
+	// lyricFloor keeps the current lyric line readable between beats.
+	lyricFloor = 0.3
+)
+
+// wordFlasher picks the text flashed over the visuals: either a word list
+// that advances on every beat, or LRC lines timed from the first sound.
+type wordFlasher struct {
+	words []string
+	next  int
+	track lyrics.Track
+	start time.Time
+	text  string
+	level float64
+}
+
+// newWordFlasher returns nil when there is nothing to show.
+func newWordFlasher(words []string, track lyrics.Track) *wordFlasher {
+	if len(words) == 0 && len(track) == 0 {
+		return nil
+	}
+	return &wordFlasher{words: words, track: track}
+}
+
+// Step advances the flasher by one frame and returns the text to show and
+// its brightness.
+func (w *wordFlasher) Step(now time.Time, feat analyzer.Features, delta float64) (string, float64) {
+	if w == nil {
+		return "", 0
+	}
+	beat := feat.Onset || feat.IsDrop
+	if len(w.track) > 0 {
+		if w.start.IsZero() {
+			if feat.IsSilent() {
+				return "", 0
+			}
+			w.start = now
+		}
+		if line := w.track.At(now.Sub(w.start)); line != w.text {
+			// a new line flashes right away instead of waiting for a beat
+			w.text = line
+			beat = true
+		}
+	} else if beat {
+		w.text = w.words[w.next%len(w.words)]
+		w.next++
+	}
+
+	if beat {
+		w.level = 1
+	} else {
+		w.level *= math.Exp(-delta / wordFlashDecay)
+	}
+	if len(w.track) > 0 && w.text != "" {
+		return w.text, math.Max(w.level, lyricFloor)
+	}
+	return w.text, w.level
+}
+
+// Mode names the active source for the web panel.
+func (w *wordFlasher) Mode() string {
+	switch {
+	case w == nil:
+		return "off"
+	case len(w.track) > 0:
+		return "lrc"
+	default:
+		return "words"
+	}
+}
+
+// SetWords flashes words in turn, one per beat; nil turns the text off
+// (thread-safe).
+func (a *App) SetWords(words []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.words = newWordFlasher(words, nil)
+}
+
+// SetLyrics shows a timed LRC track, starting with the next sound
+// (thread-safe).
+func (a *App) SetLyrics(track lyrics.Track) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.words = newWordFlasher(nil, track)
+}
+
+// Lyrics reports the active text source and the text currently shown
+// (thread-safe).
+func (a *App) Lyrics() (mode, text string) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.words == nil {
+		return "off", ""
+	}
+	return a.words.Mode(), a.words.text
+}
@@ -22,6 +22,9 @@ type Capture struct {
 	mu     sync.RWMutex
 	buffer []float32
 	index  int
+	// side holds (left-right)/2 alongside the mono mix in buffer, so the
+	// stereo image can be rebuilt; nil for mono input.
+	side []float32
 }
 
 // Config controls how a Capture instance is created.
@@ -66,6 +69,9 @@ func NewCapture(cfg Config) (*Capture, error) {
 		channels:   cfg.Channels,
 		device:     device,
 	}
+	if cfg.Channels >= 2 {
+		capture.side = make([]float32, cfg.BufferSize)
+	}
 
 	framesPerBuffer := len(capture.buffer) / cfg.Channels
 	if framesPerBuffer < 64 {
@@ -122,21 +128,37 @@ func (c *Capture) Samples() []float32 {
 func (c *Capture) SamplesInto(dst []float32) []float32 {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+	return readRing(dst, c.buffer, c.index)
+}
+
+// StereoInto is SamplesInto that also copies the side signal,
+// (left-right)/2, of the same frames into side: left is the sample plus
+// side, right the sample minus side. side comes back empty for mono input.
+func (c *Capture) StereoInto(dst, side []float32) ([]float32, []float32) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.side == nil {
+		return readRing(dst, c.buffer, c.index), side[:0]
+	}
+	return readRing(dst, c.buffer, c.index), readRing(side, c.side, c.index)
+}
 
-	size := len(c.buffer)
+// readRing copies ring, oldest first from index, into dst.
+func readRing(dst, ring []float32, index int) []float32 {
+	size := len(ring)
 	if cap(dst) < size {
 		dst = make([]float32, size)
 	} else {
 		dst = dst[:size]
 	}
 
-	if c.index == 0 {
-		copy(dst, c.buffer)
+	if index == 0 {
+		copy(dst, ring)
 		return dst
 	}
 
-	copy(dst, c.buffer[c.index:])
-	copy(dst[size-c.index:], c.buffer[:c.index])
+	copy(dst, ring[index:])
+	copy(dst[size-index:], ring[:index])
 	return dst
 }
 
@@ -146,6 +168,7 @@ func (c *Capture) process(in []float32) {
 
 	if c.channels > 1 {
 		mono := make([]float32, len(in)/c.channels)
+		side := make([]float32, len(mono))
 		for i := range mono {
 			sum := float32(0)
 			base := i * c.channels
@@ -153,38 +176,40 @@ func (c *Capture) process(in []float32) {
 				sum += in[base+ch]
 			}
 			mono[i] = sum / float32(c.channels)
+			side[i] = (in[base] - in[base+1]) / 2
 		}
-		c.mixIntoBuffer(mono)
+		writeRing(c.side, c.index, side)
+		c.index = writeRing(c.buffer, c.index, mono)
 		return
 	}
 
-	c.mixIntoBuffer(in)
+	c.index = writeRing(c.buffer, c.index, in)
 }
 
-func (c *Capture) mixIntoBuffer(in []float32) {
+// writeRing writes in to ring from index on and returns the next index.
+func writeRing(ring []float32, index int, in []float32) int {
 	if len(in) == 0 {
-		return
+		return index
 	}
 
-	if len(in) >= len(c.buffer) {
-		copy(c.buffer, in[len(in)-len(c.buffer):])
-		c.index = 0
-		return
+	if len(in) >= len(ring) {
+		copy(ring, in[len(in)-len(ring):])
+		return 0
 	}
 
-	if c.index+len(in) <= len(c.buffer) {
-		copy(c.buffer[c.index:], in)
-		c.index += len(in)
-		if c.index == len(c.buffer) {
-			c.index = 0
+	if index+len(in) <= len(ring) {
+		copy(ring[index:], in)
+		index += len(in)
+		if index == len(ring) {
+			index = 0
 		}
-		return
+		return index
 	}
 
-	remaining := len(c.buffer) - c.index
-	copy(c.buffer[c.index:], in[:remaining])
-	copy(c.buffer, in[remaining:])
-	c.index = len(in) - remaining
+	remaining := len(ring) - index
+	copy(ring[index:], in[:remaining])
+	copy(ring, in[remaining:])
+	return len(in) - remaining
 }
 
 func findDevice(name string) (*portaudio.DeviceInfo, error) {
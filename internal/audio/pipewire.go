@@ -0,0 +1,132 @@
+package audio
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PipeWireKeyPrefix namespaces the metadata keys golizer reacts to, e.g.
+// `pw-metadata <node-id> golizer.gain 1.5`. They are metadata on golizer's
+// node, set with pw-metadata or a script, not node props.
+const PipeWireKeyPrefix = "golizer."
+
+var pwMetadataLine = regexp.MustCompile(`id:(\d+) key:'([^']*)' value:'([^']*)'`)
+
+// ExportPipeWireProps names the capture stream so it shows up as its own node
+// in Helvum/qpwgraph/pavucontrol instead of a generic "ALSA plug-in". It must
+// run before Initialize; values already set by the user are left alone.
+func ExportPipeWireProps(nodeName string) {
+	if nodeName == "" {
+		nodeName = "golizer"
+	}
+	if os.Getenv("PIPEWIRE_PROPS") == "" {
+		props := fmt.Sprintf("{ node.name=%s node.description=%s media.name=%s media.role=DSP application.name=%s }",
+			nodeName, nodeName, nodeName, nodeName)
+		_ = os.Setenv("PIPEWIRE_PROPS", props)
+	}
+	if os.Getenv("PULSE_PROP") == "" {
+		_ = os.Setenv("PULSE_PROP", fmt.Sprintf("application.name=%s media.role=DSP", nodeName))
+	}
+	if os.Getenv("PIPEWIRE_ALSA") == "" {
+		_ = os.Setenv("PIPEWIRE_ALSA", fmt.Sprintf("{ application.name=%s }", nodeName))
+	}
+}
+
+// WatchPipeWireMetadata follows `pw-metadata -m` and calls fn for every
+// numeric golizer.* key that is set on a node called nodeName; the same keys
+// on other nodes are ignored. It blocks until ctx is cancelled or the
+// monitor exits.
+func WatchPipeWireMetadata(ctx context.Context, nodeName string, fn func(key string, value float64)) error {
+	if _, err := exec.LookPath("pw-metadata"); err != nil {
+		return fmt.Errorf("pw-metadata not found (install pipewire-bin)")
+	}
+
+	cmd := exec.CommandContext(ctx, "pw-metadata", "-m")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("pw-metadata: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("pw-metadata: %w", err)
+	}
+
+	// the node gets a new id whenever capture is reopened, so an id not
+	// seen yet looks the nodes up again
+	var ids []int
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		id, key, value, ok := parsePipeWireMetadata(scanner.Text())
+		if !ok {
+			continue
+		}
+		if !slices.Contains(ids, id) {
+			ids = pipeWireNodeIDs(ctx, nodeName)
+		}
+		if slices.Contains(ids, id) {
+			fn(key, value)
+		}
+	}
+	if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("pw-metadata: %w", err)
+	}
+	return nil
+}
+
+// parsePipeWireMetadata reads one line of `pw-metadata -m`, e.g.
+// "update: id:42 key:'golizer.gain' value:'1.5' ...", into the id of
+// the object the key is set on and the key's numeric value.
+func parsePipeWireMetadata(line string) (int, string, float64, bool) {
+	match := pwMetadataLine.FindStringSubmatch(line)
+	if match == nil || !strings.HasPrefix(match[2], PipeWireKeyPrefix) {
+		return 0, "", 0, false
+	}
+	id, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, "", 0, false
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(match[3]), 64)
+	if err != nil {
+		return 0, "", 0, false
+	}
+	return id, match[2], value, true
+}
+
+// pipeWireNodeIDs returns the ids of the nodes called nodeName, from
+// pw-dump; none when it fails.
+func pipeWireNodeIDs(ctx context.Context, nodeName string) []int {
+	out, err := exec.CommandContext(ctx, "pw-dump").Output()
+	if err != nil {
+		return nil
+	}
+	return parsePipeWireNodes(out, nodeName)
+}
+
+// parsePipeWireNodes picks the ids of the nodes called nodeName out of
+// pw-dump's JSON.
+func parsePipeWireNodes(dump []byte, nodeName string) []int {
+	var objects []struct {
+		ID   int    `json:"id"`
+		Type string `json:"type"`
+		Info struct {
+			Props map[string]any `json:"props"`
+		} `json:"info"`
+	}
+	if json.Unmarshal(dump, &objects) != nil {
+		return nil
+	}
+	var ids []int
+	for _, o := range objects {
+		if o.Type == "PipeWire:Interface:Node" && o.Info.Props["node.name"] == nodeName {
+			ids = append(ids, o.ID)
+		}
+	}
+	return ids
+}
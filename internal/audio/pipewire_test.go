@@ -0,0 +1,45 @@
+package audio
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePipeWireMetadata(t *testing.T) {
+	for _, tc := range []struct {
+		line  string
+		id    int
+		key   string
+		value float64
+		ok    bool
+	}{
+		{"update: id:42 key:'golizer.gain' value:'1.5' type:''", 42, "golizer.gain", 1.5, true},
+		{"update: id:7 key:'golizer.noise-floor' value:' 0.1 ' type:''", 7, "golizer.noise-floor", 0.1, true},
+		{"update: id:0 key:'default.audio.sink' value:'{\"name\":\"x\"}' type:'Spa:String:JSON'", 0, "", 0, false},
+		{"update: id:42 key:'golizer.gain' value:'loud' type:''", 0, "", 0, false},
+		{"update: id:42 key:'golizer.gain' value:'' type:''", 0, "", 0, false},
+		{"key:'golizer.gain' value:'1.5'", 0, "", 0, false}, // no subject
+		{"", 0, "", 0, false},
+	} {
+		id, key, value, ok := parsePipeWireMetadata(tc.line)
+		if ok != tc.ok || ok && (id != tc.id || key != tc.key || value != tc.value) {
+			t.Errorf("%q: %d %q %v %v, want %d %q %v %v", tc.line, id, key, value, ok, tc.id, tc.key, tc.value, tc.ok)
+		}
+	}
+}
+
+func TestParsePipeWireNodes(t *testing.T) {
+	dump := []byte(`[
+		{"id": 30, "type": "PipeWire:Interface:Node", "info": {"props": {"node.name": "alsa_input.usb"}}},
+		{"id": 42, "type": "PipeWire:Interface:Node", "info": {"props": {"node.name": "golizer"}}},
+		{"id": 43, "type": "PipeWire:Interface:Port", "info": {"props": {"node.name": "golizer"}}},
+		{"id": 50, "type": "PipeWire:Interface:Node", "info": {"props": {"node.name": "golizer"}}},
+		{"id": 51, "type": "PipeWire:Interface:Node", "info": null}
+	]`)
+	if got := parsePipeWireNodes(dump, "golizer"); !slices.Equal(got, []int{42, 50}) {
+		t.Errorf("golizer nodes %v, want [42 50]", got)
+	}
+	if got := parsePipeWireNodes([]byte("not json"), "golizer"); got != nil {
+		t.Errorf("bad dump gave %v", got)
+	}
+}
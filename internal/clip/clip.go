@@ -0,0 +1,157 @@
+// Package clip keeps the last few seconds of rendered frames so they can be
+// exported as an animated GIF. Pixel frames are scaled down and quantised to
+// the xterm 256-colour palette as they come in; terminal frames keep their
+// rows and are drawn with a small bitmap font only when a clip is encoded.
+package clip
+
+import (
+	"errors"
+	"image"
+	"image/gif"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFPS is how often frames are sampled into the buffer.
+	DefaultFPS = 15
+	// DefaultMaxWidth bounds the width of pixel frames.
+	DefaultMaxWidth = 480
+)
+
+// ErrEmpty is returned when no frames were buffered yet.
+var ErrEmpty = errors.New("clip: no frames buffered")
+
+type frame struct {
+	at time.Time
+	// lines are terminal rows, rasterised when encoding
+	lines []string
+	pix   *image.Paletted
+}
+
+// Buffer is a ring of recent frames, safe for one writer and concurrent
+// encoders.
+type Buffer struct {
+	mu       sync.Mutex
+	frames   []frame
+	next     int
+	filled   bool
+	interval time.Duration
+	maxWidth int
+	last     time.Time
+}
+
+// NewBuffer keeps length worth of frames sampled at fps. A zero fps or
+// maxWidth takes the default.
+func NewBuffer(length time.Duration, fps, maxWidth int) *Buffer {
+	if fps <= 0 {
+		fps = DefaultFPS
+	}
+	if maxWidth <= 0 {
+		maxWidth = DefaultMaxWidth
+	}
+	n := max(1, int(length.Seconds()*float64(fps)))
+	return &Buffer{
+		frames:   make([]frame, n),
+		interval: time.Second / time.Duration(fps),
+		maxWidth: maxWidth,
+	}
+}
+
+// Due reports whether a frame at now would be sampled, so callers can skip
+// the copy otherwise. A nil Buffer is never due.
+func (b *Buffer) Due(now time.Time) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return now.Sub(b.last) >= b.interval
+}
+
+// Add samples a frame. Terminal rows win over img when both are set; both
+// are copied, so the caller may reuse them.
+func (b *Buffer) Add(now time.Time, img *image.RGBA, lines []string) {
+	if b == nil || (img == nil && len(lines) == 0) {
+		return
+	}
+	f := frame{at: now}
+	if len(lines) > 0 {
+		f.lines = append([]string(nil), lines...)
+	} else {
+		f.pix = quantize(img, b.maxWidth)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.last = now
+	b.frames[b.next] = f
+	b.next = (b.next + 1) % len(b.frames)
+	if b.next == 0 {
+		b.filled = true
+	}
+}
+
+// snapshot returns the frames of the last d (all when d <= 0), oldest first.
+func (b *Buffer) snapshot(d time.Duration) []frame {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []frame
+	if b.filled {
+		out = append(out, b.frames[b.next:]...)
+	}
+	out = append(out, b.frames[:b.next]...)
+	if d > 0 && len(out) > 0 {
+		cut := out[len(out)-1].at.Add(-d)
+		for len(out) > 1 && out[0].at.Before(cut) {
+			out = out[1:]
+		}
+	}
+	return out
+}
+
+// WriteGIF encodes the last d of frames (all buffered when d <= 0) as a
+// looping GIF.
+func (b *Buffer) WriteGIF(w io.Writer, d time.Duration) error {
+	frames := b.snapshot(d)
+	if len(frames) == 0 {
+		return ErrEmpty
+	}
+	anim := &gif.GIF{}
+	for i, f := range frames {
+		img := f.pix
+		if img == nil {
+			img = rasterize(f.lines)
+		}
+		// delays are in hundredths; taking them from the timestamps keeps the
+		// clip at real speed when the render loop fell behind
+		delay := int(b.interval / (10 * time.Millisecond))
+		if i+1 < len(frames) {
+			delay = int(frames[i+1].at.Sub(f.at) / (10 * time.Millisecond))
+		}
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, max(2, delay))
+		anim.Config.Width = max(anim.Config.Width, img.Rect.Dx())
+		anim.Config.Height = max(anim.Config.Height, img.Rect.Dy())
+	}
+	anim.Config.ColorModel = xtermPalette
+	return gif.EncodeAll(w, anim)
+}
+
+// quantize scales img down to at most maxWidth (nearest neighbour) and maps
+// it onto the palette.
+func quantize(img *image.RGBA, maxWidth int) *image.Paletted {
+	srcW, srcH := img.Rect.Dx(), img.Rect.Dy()
+	step := max(1, (srcW+maxWidth-1)/maxWidth)
+	w, h := max(1, srcW/step), max(1, srcH/step)
+	out := image.NewPaletted(image.Rect(0, 0, w, h), xtermPalette)
+	for y := 0; y < h; y++ {
+		row := img.Pix[(y*step)*img.Stride:]
+		dst := out.Pix[y*out.Stride:]
+		for x := 0; x < w; x++ {
+			px := row[x*step*4:]
+			dst[x] = cubeIndex(px[0], px[1], px[2])
+		}
+	}
+	return out
+}
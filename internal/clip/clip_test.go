@@ -0,0 +1,118 @@
+package clip
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"testing"
+	"time"
+)
+
+func TestBufferKeepsLastFrames(t *testing.T) {
+	b := NewBuffer(time.Second, 4, 0)
+	start := time.Unix(0, 0)
+	for i := 0; i < 6; i++ {
+		now := start.Add(time.Duration(i) * 250 * time.Millisecond)
+		if !b.Due(now) {
+			t.Fatalf("frame %d not due", i)
+		}
+		b.Add(now, nil, []string{string(rune('a' + i))})
+	}
+	if b.Due(start.Add(1300 * time.Millisecond)) {
+		t.Fatal("due before the interval passed")
+	}
+
+	frames := b.snapshot(0)
+	if len(frames) != 4 || frames[0].lines[0] != "c" || frames[3].lines[0] != "f" {
+		t.Fatalf("got %d frames starting %q", len(frames), frames[0].lines)
+	}
+	if frames = b.snapshot(500 * time.Millisecond); len(frames) != 3 || frames[0].lines[0] != "d" {
+		t.Fatalf("window kept %d frames", len(frames))
+	}
+}
+
+func TestWriteGIF(t *testing.T) {
+	b := NewBuffer(time.Second, 10, 0)
+	if err := b.WriteGIF(&bytes.Buffer{}, 0); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("empty buffer: %v", err)
+	}
+
+	start := time.Unix(0, 0)
+	b.Add(start, nil, []string{"\x1b[38;5;196m@@\x1b[0m", "  "})
+	b.Add(start.Add(200*time.Millisecond), nil, []string{"..", "##"})
+	img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	b.Add(start.Add(300*time.Millisecond), img, nil)
+
+	var out bytes.Buffer
+	if err := b.WriteGIF(&out, 0); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 3 {
+		t.Fatalf("got %d frames", len(anim.Image))
+	}
+	if want := []int{20, 10, 10}; anim.Delay[0] != want[0] || anim.Delay[1] != want[1] || anim.Delay[2] != want[2] {
+		t.Fatalf("delays %v, want %v", anim.Delay, want)
+	}
+	if anim.Config.Width != 2*cellW || anim.Config.Height != 2*cellH {
+		t.Fatalf("size %dx%d", anim.Config.Width, anim.Config.Height)
+	}
+}
+
+func TestRasterizeColours(t *testing.T) {
+	img := rasterize([]string{"\x1b[38;5;196m\x1b[48;5;21m█\x1b[0m ▄", "⣿"})
+	if b := img.Bounds(); b.Dx() != 3*cellW || b.Dy() != 2*cellH {
+		t.Fatalf("bounds %v", b)
+	}
+	if got := img.ColorIndexAt(2, 2); got != 196 {
+		t.Fatalf("full block drawn in %d", got)
+	}
+	if got := img.ColorIndexAt(cellW+2, 2); got != defaultBg {
+		t.Fatalf("reset cell background %d", got)
+	}
+	if top, bottom := img.ColorIndexAt(2*cellW+2, 1), img.ColorIndexAt(2*cellW+2, cellH-1); top != defaultBg || bottom != defaultFg {
+		t.Fatalf("lower half block: top %d bottom %d", top, bottom)
+	}
+	if got := img.ColorIndexAt(cellW-2, cellH+cellH-2); got != defaultFg {
+		t.Fatalf("braille dot 8 not drawn")
+	}
+}
+
+func TestCubeIndex(t *testing.T) {
+	for _, tc := range []struct {
+		r, g, b uint8
+		want    uint8
+	}{
+		{0, 0, 0, 16},
+		{255, 255, 255, 231},
+		{255, 0, 0, 196},
+		{100, 140, 20, 16 + 36*1 + 6*2 + 0},
+	} {
+		if got := cubeIndex(tc.r, tc.g, tc.b); got != tc.want {
+			t.Errorf("cubeIndex(%d, %d, %d) = %d, want %d", tc.r, tc.g, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestStillCopiesFrame(t *testing.T) {
+	if Still(nil, nil) != nil {
+		t.Fatal("empty frame gave an image")
+	}
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	src.Pix[0] = 200
+	got, ok := Still(src, nil).(*image.RGBA)
+	if !ok || got.Bounds() != src.Bounds() || got.Pix[0] != 200 {
+		t.Fatalf("pixel frame not copied: %T", got)
+	}
+	src.Pix[0] = 0
+	if got.Pix[0] != 200 {
+		t.Fatal("still shares pixels with the frame")
+	}
+	if b := Still(src, []string{"ab"}).Bounds(); b.Dx() != 2*cellW {
+		t.Fatalf("terminal rows not preferred: %v", b)
+	}
+}
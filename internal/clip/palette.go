@@ -0,0 +1,56 @@
+package clip
+
+import (
+	"image/color"
+)
+
+// xtermPalette is the 256-colour palette the ANSI renderer's codes refer
+// to; pixel frames are mapped onto its colour cube so both kinds of frame
+// share one GIF palette.
+var xtermPalette = func() color.Palette {
+	p := make(color.Palette, 0, 256)
+	for _, c := range [16][3]uint8{
+		{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+		{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+		{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+		{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+	} {
+		p = append(p, color.RGBA{c[0], c[1], c[2], 255})
+	}
+	for i := 0; i < 216; i++ {
+		p = append(p, color.RGBA{cubeLevels[i/36], cubeLevels[i/6%6], cubeLevels[i%6], 255})
+	}
+	for i := 0; i < 24; i++ {
+		v := uint8(8 + 10*i)
+		p = append(p, color.RGBA{v, v, v, 255})
+	}
+	return p
+}()
+
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// cubeStep maps a channel value onto the nearest cube level.
+var cubeStep = func() (lut [256]uint8) {
+	for v := range lut {
+		best := 0
+		for i, level := range cubeLevels {
+			if absDiff(v, int(level)) < absDiff(v, int(cubeLevels[best])) {
+				best = i
+			}
+		}
+		lut[v] = uint8(best)
+	}
+	return lut
+}()
+
+func absDiff(a, b int) int {
+	if a < b {
+		return b - a
+	}
+	return a - b
+}
+
+// cubeIndex returns the palette index of the cube colour nearest to r, g, b.
+func cubeIndex(r, g, b uint8) uint8 {
+	return 16 + 36*cubeStep[r] + 6*cubeStep[g] + cubeStep[b]
+}
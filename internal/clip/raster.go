@@ -0,0 +1,190 @@
+package clip
+
+import (
+	"image"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// cellW and cellH are the size of one terminal cell in the GIF, about
+	// the 1:2 shape of a real one.
+	cellW = 6
+	cellH = 12
+	// glyphTop is the first cell row of the 5x7 glyphs.
+	glyphTop = 3
+
+	defaultFg = 7
+	defaultBg = 0
+)
+
+// glyphs holds the characters of the ASCII palettes as 5x7 masks, most
+// significant bit on the left. Block elements and braille are drawn from
+// their code points instead.
+var glyphs = map[rune][7]uint8{
+	'.':  {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b01100},
+	',':  {0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b00100, 0b01000},
+	':':  {0b00000, 0b01100, 0b01100, 0b00000, 0b01100, 0b01100, 0b00000},
+	';':  {0b00000, 0b01100, 0b01100, 0b00000, 0b01100, 0b00100, 0b01000},
+	'\'': {0b01100, 0b00100, 0b01000, 0b00000, 0b00000, 0b00000, 0b00000},
+	'`':  {0b01000, 0b00100, 0b00010, 0b00000, 0b00000, 0b00000, 0b00000},
+	'-':  {0b00000, 0b00000, 0b00000, 0b11111, 0b00000, 0b00000, 0b00000},
+	'=':  {0b00000, 0b00000, 0b11111, 0b00000, 0b11111, 0b00000, 0b00000},
+	'+':  {0b00000, 0b00100, 0b00100, 0b11111, 0b00100, 0b00100, 0b00000},
+	'*':  {0b00000, 0b00100, 0b10101, 0b01110, 0b10101, 0b00100, 0b00000},
+	'|':  {0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100},
+	'/':  {0b00001, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0b10000},
+	'o':  {0b00000, 0b00000, 0b01110, 0b10001, 0b10001, 0b10001, 0b01110},
+	'O':  {0b01110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'x':  {0b00000, 0b00000, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001},
+	'%':  {0b11000, 0b11001, 0b00010, 0b00100, 0b01000, 0b10011, 0b00011},
+	'#':  {0b01010, 0b01010, 0b11111, 0b01010, 0b11111, 0b01010, 0b01010},
+	'@':  {0b01110, 0b10001, 0b10111, 0b10101, 0b10111, 0b10000, 0b01110},
+}
+
+// unknownGlyph stands in for characters the atlas doesn't have.
+var unknownGlyph = [7]uint8{0b00000, 0b01110, 0b01010, 0b01010, 0b01010, 0b01110, 0b00000}
+
+// rasterize draws terminal rows, ANSI 256-colour codes included, into a
+// paletted image.
+func rasterize(lines []string) *image.Paletted {
+	cols := 0
+	for _, line := range lines {
+		cols = max(cols, visibleWidth(line))
+	}
+	img := image.NewPaletted(image.Rect(0, 0, max(1, cols)*cellW, max(1, len(lines))*cellH), xtermPalette)
+	for row, line := range lines {
+		fg, bg := uint8(defaultFg), uint8(defaultBg)
+		col := 0
+		for i := 0; i < len(line); {
+			if line[i] == '\x1b' {
+				n, params, final := parseCSI(line[i:])
+				if final == 'm' {
+					fg, bg = applySGR(params, fg, bg)
+				}
+				i += n
+				continue
+			}
+			ch, size := utf8.DecodeRuneInString(line[i:])
+			i += size
+			drawCell(img, col*cellW, row*cellH, ch, fg, bg)
+			col++
+		}
+	}
+	return img
+}
+
+// visibleWidth counts the cells of line, skipping escape sequences.
+func visibleWidth(line string) int {
+	n := 0
+	for i := 0; i < len(line); {
+		if line[i] == '\x1b' {
+			size, _, _ := parseCSI(line[i:])
+			i += size
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(line[i:])
+		i += size
+		n++
+	}
+	return n
+}
+
+// parseCSI reads the escape sequence at the start of s and returns its
+// length, parameters and final byte. Anything that isn't ESC [ ... only
+// skips the ESC.
+func parseCSI(s string) (int, string, byte) {
+	if len(s) < 2 || s[1] != '[' {
+		return 1, "", 0
+	}
+	for i := 2; i < len(s); i++ {
+		if c := s[i]; c >= 0x40 && c <= 0x7e {
+			return i + 1, s[2:i], c
+		}
+	}
+	return len(s), "", 0
+}
+
+// applySGR handles the colour parts of a select-graphic-rendition sequence.
+func applySGR(params string, fg, bg uint8) (uint8, uint8) {
+	fields := strings.Split(params, ";")
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "", "0":
+			fg, bg = defaultFg, defaultBg
+		case "39":
+			fg = defaultFg
+		case "49":
+			bg = defaultBg
+		case "38", "48":
+			if i+2 < len(fields) && fields[i+1] == "5" {
+				if v, err := strconv.Atoi(fields[i+2]); err == nil && v >= 0 && v < 256 {
+					if fields[i] == "38" {
+						fg = uint8(v)
+					} else {
+						bg = uint8(v)
+					}
+				}
+				i += 2
+			}
+		}
+	}
+	return fg, bg
+}
+
+// drawCell paints one cell: the background, then the character in fg.
+func drawCell(img *image.Paletted, x0, y0 int, ch rune, fg, bg uint8) {
+	for y := 0; y < cellH; y++ {
+		row := img.Pix[(y0+y)*img.Stride+x0:]
+		for x := 0; x < cellW; x++ {
+			c := bg
+			if cellPixel(ch, x, y) {
+				c = fg
+			}
+			row[x] = c
+		}
+	}
+}
+
+// cellPixel reports whether pixel x, y of a cell holding ch is lit.
+func cellPixel(ch rune, x, y int) bool {
+	switch {
+	case ch == ' ':
+		return false
+	case ch == '█':
+		return true
+	case ch == '▀':
+		return y < cellH/2
+	case ch == '▄':
+		return y >= cellH/2
+	case ch == '░':
+		return (x+2*y)%4 == 0
+	case ch == '▒':
+		return (x+y)%2 == 0
+	case ch == '▓':
+		return (x+2*y)%4 != 0
+	case ch >= 0x2800 && ch <= 0x28ff:
+		return braillePixel(int(ch-0x2800), x, y)
+	}
+	mask, ok := glyphs[ch]
+	if !ok {
+		mask = unknownGlyph
+	}
+	gy := y - glyphTop
+	if gy < 0 || gy >= len(mask) || x >= 5 {
+		return false
+	}
+	return mask[gy]&(1<<(4-x)) != 0
+}
+
+// braillePixel draws the 2x4 dots of a braille pattern as 2x2 squares.
+func braillePixel(bits, x, y int) bool {
+	col, row := (x-1)/3, (y-1)/3
+	if x < 1 || y < 1 || (x-1)%3 == 2 || (y-1)%3 == 2 || col > 1 || row > 3 {
+		return false
+	}
+	// dots 1-3 and 4-6 run down the columns, 7 and 8 are the bottom row
+	dot := [4][2]int{{0, 3}, {1, 4}, {2, 5}, {6, 7}}[row][col]
+	return bits&(1<<dot) != 0
+}
@@ -0,0 +1,23 @@
+package clip
+
+import (
+	"image"
+)
+
+// Still copies one rendered frame into an image of its own, for snapshots:
+// terminal rows are drawn with the bitmap font, pixels are kept at full
+// resolution. It returns nil when the frame has neither.
+func Still(img *image.RGBA, lines []string) image.Image {
+	if len(lines) > 0 {
+		return rasterize(lines)
+	}
+	if img == nil {
+		return nil
+	}
+	out := image.NewRGBA(image.Rect(0, 0, img.Rect.Dx(), img.Rect.Dy()))
+	for y := 0; y < out.Rect.Dy(); y++ {
+		src := img.Pix[y*img.Stride : y*img.Stride+out.Rect.Dx()*4]
+		copy(out.Pix[y*out.Stride:], src)
+	}
+	return out
+}
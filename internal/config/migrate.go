@@ -0,0 +1,133 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// Version is the schema version written into every saved config file.
+const Version = 2
+
+// step upgrades a decoded JSON document from version `from` to `from+1`.
+type step struct {
+	from  int
+	name  string
+	apply func(doc map[string]any)
+}
+
+// steps must stay ordered by `from`; append a new entry (and bump Version)
+// whenever a field is renamed, removed or gains a required default.
+var steps = []step{
+	{from: 0, name: "fill missing params with defaults", apply: fillParamDefaults},
+	{from: 1, name: "add five-band influences", apply: fillMissingParams("SubInfluence", "LowMidInfluence", "HighMidInfluence")},
+}
+
+// Migrate upgrades raw config JSON to the current Version. It returns the
+// migrated document and the version it was read as. Files written by a newer
+// build are returned unchanged together with an error so callers can decide
+// whether to continue.
+func Migrate(data []byte) ([]byte, int, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+
+	from := docVersion(doc)
+	if from > Version {
+		return data, from, fmt.Errorf("config version %d is newer than supported version %d", from, Version)
+	}
+	if from == Version {
+		return data, from, nil
+	}
+
+	for _, s := range steps {
+		if s.from < from {
+			continue
+		}
+		s.apply(doc)
+	}
+	doc["version"] = Version
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, from, err
+	}
+	return out, from, nil
+}
+
+func docVersion(doc map[string]any) int {
+	raw, ok := doc["version"]
+	if !ok {
+		return 0
+	}
+	if v, ok := raw.(float64); ok && v > 0 {
+		return int(v)
+	}
+	return 0
+}
+
+// fillParamDefaults adds the knobs older web panels left out because they
+// only posted the handful of sliders they displayed. A saved zero is kept,
+// it may be deliberate (no vignette, no sub influence), except for gamma and
+// glyph sharpness, which those panels zeroed and which can't be 0 (gamma 0
+// blows up the brightness curve, glyph sharpness 0 flattens every palette).
+func fillParamDefaults(doc map[string]any) {
+	current, ok := doc["params"].(map[string]any)
+	if !ok {
+		return
+	}
+	defaults, err := toMap(params.Defaults())
+	if err != nil {
+		return
+	}
+	for key, def := range defaults {
+		if _, isNum := def.(float64); !isNum {
+			continue
+		}
+		value, present := current[key]
+		if !present {
+			current[key] = def
+			continue
+		}
+		if num, ok := value.(float64); ok && num == 0 && zeroedByOldPanels[key] {
+			current[key] = def
+		}
+	}
+}
+
+// zeroedByOldPanels are the params a v0 file can't mean to hold at zero.
+var zeroedByOldPanels = map[string]bool{"Gamma": true, "GlyphSharpness": true}
+
+// fillMissingParams returns a step that sets newly introduced params to their
+// defaults when a file predates them.
+func fillMissingParams(keys ...string) func(doc map[string]any) {
+	return func(doc map[string]any) {
+		current, ok := doc["params"].(map[string]any)
+		if !ok {
+			return
+		}
+		defaults, err := toMap(params.Defaults())
+		if err != nil {
+			return
+		}
+		for _, key := range keys {
+			if _, present := current[key]; !present {
+				current[key] = defaults[key]
+			}
+		}
+	}
+}
+
+func toMap(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
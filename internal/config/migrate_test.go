@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMigrateFillsZeroedParams(t *testing.T) {
+	legacy := []byte(`{"params":{"Frequency":6,"Gamma":0,"GlyphSharpness":0,"Amplitude":0,"Vignette":0,"BassInfluence":0},"palette":"block"}`)
+
+	out, from, err := Migrate(legacy)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if from != 0 {
+		t.Fatalf("from=%d want=0", from)
+	}
+
+	var doc struct {
+		Version int                `json:"version"`
+		Palette string             `json:"palette"`
+		Params  map[string]float64 `json:"params"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if doc.Version != Version {
+		t.Fatalf("version=%d want=%d", doc.Version, Version)
+	}
+	if doc.Palette != "block" {
+		t.Fatalf("palette dropped during migration: %q", doc.Palette)
+	}
+	if doc.Params["Gamma"] != 1.0 {
+		t.Fatalf("gamma=%f want default 1.0", doc.Params["Gamma"])
+	}
+	if doc.Params["GlyphSharpness"] == 0 {
+		t.Fatalf("glyph sharpness left at zero")
+	}
+	if doc.Params["Amplitude"] != 0 {
+		t.Fatalf("amplitude default is zero, got %f", doc.Params["Amplitude"])
+	}
+	// a saved zero is a choice, only missing knobs get defaults
+	for _, key := range []string{"Vignette", "BassInfluence"} {
+		if doc.Params[key] != 0 {
+			t.Fatalf("%s=%f, want the saved 0 kept", key, doc.Params[key])
+		}
+	}
+	if doc.Params["Contrast"] == 0 || doc.Params["MidInfluence"] == 0 {
+		t.Fatalf("missing params not defaulted: %v", doc.Params)
+	}
+}
+
+func TestMigrateRejectsNewerVersion(t *testing.T) {
+	data := []byte(`{"version":99}`)
+	out, from, err := Migrate(data)
+	if err == nil {
+		t.Fatalf("expected error for newer version")
+	}
+	if from != 99 || string(out) != string(data) {
+		t.Fatalf("expected document to be returned untouched")
+	}
+}
+
+func TestMigrateAddsFiveBandInfluences(t *testing.T) {
+	data := []byte(`{"version":1,"params":{"BassInfluence":0.5}}`)
+	out, from, err := Migrate(data)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if from != 1 {
+		t.Fatalf("from=%d want=1", from)
+	}
+	var doc struct {
+		Params map[string]float64 `json:"params"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if doc.Params["SubInfluence"] == 0 || doc.Params["HighMidInfluence"] == 0 {
+		t.Fatalf("expected new influences to be defaulted, got %v", doc.Params)
+	}
+	if doc.Params["BassInfluence"] != 0.5 {
+		t.Fatalf("existing value overwritten: %v", doc.Params["BassInfluence"])
+	}
+}
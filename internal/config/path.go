@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultPath is where the config is saved unless --config says otherwise:
+// golizer/config.json in $XDG_CONFIG_HOME (~/.config when unset; the
+// platform's user config directory off Linux).
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, ".golizer-config.json")
+	}
+	return filepath.Join(dir, "golizer", "config.json")
+}
+
+// legacyPaths are where builds before DefaultPath kept the file: next to
+// the binary, or in the home directory when that couldn't be found.
+func legacyPaths() []string {
+	var paths []string
+	if exe, err := os.Executable(); err == nil {
+		paths = append(paths, filepath.Join(filepath.Dir(exe), "golizer-config.json"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".golizer-config.json"))
+	}
+	return paths
+}
+
+// Path returns the config file to load and save: path when one was given,
+// DefaultPath otherwise. A file an older build left in its old place keeps
+// being used, with the presets next to it, until there is one at
+// DefaultPath.
+func Path(path string) string {
+	if path != "" {
+		return path
+	}
+	def := DefaultPath()
+	if _, err := os.Stat(def); err == nil {
+		return def
+	}
+	for _, old := range legacyPaths() {
+		if _, err := os.Stat(old); err == nil {
+			return old
+		}
+	}
+	return def
+}
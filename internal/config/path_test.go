@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPathPrefersFlag(t *testing.T) {
+	if got := Path("/etc/golizer.json"); got != "/etc/golizer.json" {
+		t.Fatalf("Path = %q, want the given path", got)
+	}
+}
+
+func TestPathDefaultsToXDG(t *testing.T) {
+	home, xdg := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	want := filepath.Join(xdg, "golizer", "config.json")
+	if got := Path(""); got != want {
+		t.Fatalf("Path = %q, want %q", got, want)
+	}
+}
+
+func TestPathKeepsLegacyFile(t *testing.T) {
+	home, xdg := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	legacy := filepath.Join(home, ".golizer-config.json")
+	if err := os.WriteFile(legacy, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := Path(""); got != legacy {
+		t.Fatalf("Path = %q, want the legacy file %q", got, legacy)
+	}
+
+	current := filepath.Join(xdg, "golizer", "config.json")
+	if err := os.MkdirAll(filepath.Dir(current), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(current, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := Path(""); got != current {
+		t.Fatalf("Path = %q, want %q once it exists", got, current)
+	}
+}
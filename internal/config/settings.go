@@ -0,0 +1,182 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SettingsName is the structured config file looked for next to the saved
+// config.
+const SettingsName = "golizer.yaml"
+
+// EnvPrefix starts the environment variable of every flag: GOLIZER_ and the
+// flag name in capitals with dashes as underscores, e.g. GOLIZER_WEB_PORT.
+const EnvPrefix = "GOLIZER_"
+
+// Settings is the structured config file: any command-line flag under its
+// own name, plus sections for what a flag can't express.
+type Settings struct {
+	// Flags holds the flag values by flag name, as they would be typed.
+	Flags map[string]string `yaml:"-"`
+	// Bands tunes the analysis per band (sub, bass, lowMid, highMid, mid,
+	// treble).
+	Bands map[string]Band `yaml:"bands"`
+	// Presets are put into the preset store on every start.
+	Presets []Preset `yaml:"presets"`
+	// Randomizer weighs what randomize picks.
+	Randomizer Weights `yaml:"randomizer"`
+	// Outputs are extra frame outputs, enabled together with the output
+	// profile's.
+	Outputs []Output `yaml:"outputs"`
+}
+
+// Band is one band's envelope and noise floor; the ones left out keep their
+// saved or default value.
+type Band struct {
+	Attack     *float64 `yaml:"attack"`  // ms
+	Release    *float64 `yaml:"release"` // ms
+	NoiseFloor *float64 `yaml:"noise-floor"`
+}
+
+// Preset is a named look. Params are the panel knobs by field name
+// (Brightness, BassInfluence ...), the rest keep their defaults.
+type Preset struct {
+	Name      string             `yaml:"name"`
+	Pattern   string             `yaml:"pattern"`
+	Palette   string             `yaml:"palette"`
+	ColorMode string             `yaml:"color-mode"`
+	Params    map[string]float64 `yaml:"params"`
+}
+
+// Weights make some patterns, palettes and color modes come up more often
+// than others when randomizing: 2 is twice as likely, 0 never. Names left
+// out weigh 1.
+type Weights struct {
+	Patterns   map[string]float64 `yaml:"patterns"`
+	Palettes   map[string]float64 `yaml:"palettes"`
+	ColorModes map[string]float64 `yaml:"color-modes"`
+}
+
+// Output is a frame output by sink name with its options.
+type Output struct {
+	Name    string            `yaml:"name"`
+	Options map[string]string `yaml:"options"`
+}
+
+// sections are the Settings keys that aren't flags.
+var sections = map[string]bool{"bands": true, "presets": true, "randomizer": true, "outputs": true}
+
+// LoadSettings reads the settings file at path. A missing file gives empty
+// settings when optional is set and an error otherwise.
+func LoadSettings(path string, optional bool) (*Settings, error) {
+	data, err := os.ReadFile(path)
+	if optional && errors.Is(err, os.ErrNotExist) {
+		return &Settings{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s, err := ParseSettings(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// ParseSettings decodes a settings document.
+func ParseSettings(data []byte) (*Settings, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	s := &Settings{Flags: map[string]string{}}
+	if len(doc.Content) == 0 {
+		return s, nil // empty file
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: want a mapping of settings", root.Line)
+	}
+	if err := root.Decode(s); err != nil {
+		return nil, err
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if sections[key.Value] {
+			continue
+		}
+		if value.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("line %d: %s takes a single value", key.Line, key.Value)
+		}
+		s.Flags[key.Value] = value.Value
+	}
+	return s, nil
+}
+
+// EnvName is the environment variable that sets the flag name.
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// ApplyEnv sets the flags not given on the command line from their
+// environment variables (see EnvName). Run it before ApplyFile, so the
+// environment wins over the file.
+func ApplyEnv(fs *flag.FlagSet, lookup func(string) (string, bool)) error {
+	var errs []error
+	unset(fs, func(f *flag.Flag) {
+		value, ok := lookup(EnvName(f.Name))
+		if !ok {
+			return
+		}
+		if err := fs.Set(f.Name, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", EnvName(f.Name), err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// ApplyFile sets the flags neither the command line nor the environment
+// gave from the settings. A flag set this way counts as passed, so it wins
+// over the saved config too. skip lists flags the file may not set, the
+// ones that choose the files.
+func (s *Settings) ApplyFile(fs *flag.FlagSet, skip ...string) error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(s.Flags)) {
+		if fs.Lookup(name) == nil {
+			errs = append(errs, fmt.Errorf("unknown setting %q", name))
+		}
+	}
+	for _, name := range skip {
+		if _, ok := s.Flags[name]; ok {
+			errs = append(errs, fmt.Errorf("%s can't be set in the settings file", name))
+		}
+	}
+	unset(fs, func(f *flag.Flag) {
+		value, ok := s.Flags[f.Name]
+		if !ok || slices.Contains(skip, f.Name) {
+			return
+		}
+		if err := fs.Set(f.Name, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.Name, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// unset calls fn for every flag of fs that hasn't been set yet.
+func unset(fs *flag.FlagSet, fn func(*flag.Flag)) {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	fs.VisitAll(func(f *flag.Flag) {
+		if !set[f.Name] {
+			fn(f)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+const sampleSettings = `
+width: 160
+pattern: plasma
+no-web: true
+bands:
+  bass: {attack: 5, noise-floor: 0.1}
+presets:
+  - name: calm
+    pattern: plasma
+    params: {Brightness: 0.8}
+randomizer:
+  patterns: {plasma: 3, flash: 0}
+outputs:
+  - name: led
+    options: {chip: ws2812}
+`
+
+func TestParseSettings(t *testing.T) {
+	s, err := ParseSettings([]byte(sampleSettings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Flags) != 3 || s.Flags["width"] != "160" || s.Flags["no-web"] != "true" {
+		t.Fatalf("flags = %v", s.Flags)
+	}
+	if b := s.Bands["bass"]; b.Attack == nil || *b.Attack != 5 || b.Release != nil || *b.NoiseFloor != 0.1 {
+		t.Fatalf("bass = %+v", b)
+	}
+	if len(s.Presets) != 1 || s.Presets[0].Params["Brightness"] != 0.8 {
+		t.Fatalf("presets = %+v", s.Presets)
+	}
+	if s.Randomizer.Patterns["flash"] != 0 || s.Randomizer.Patterns["plasma"] != 3 {
+		t.Fatalf("randomizer = %+v", s.Randomizer)
+	}
+	if len(s.Outputs) != 1 || s.Outputs[0].Options["chip"] != "ws2812" {
+		t.Fatalf("outputs = %+v", s.Outputs)
+	}
+}
+
+func TestParseSettingsRejectsListsForFlags(t *testing.T) {
+	_, err := ParseSettings([]byte("width: [1, 2]\n"))
+	if err == nil || !strings.Contains(err.Error(), "width") {
+		t.Fatalf("err = %v, want one naming width", err)
+	}
+}
+
+func testFlags() (*flag.FlagSet, *int, *string, *bool) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	width := fs.Int("width", 120, "")
+	pattern := fs.String("pattern", "auto", "")
+	noWeb := fs.Bool("no-web", false, "")
+	fs.String("config", "", "")
+	return fs, width, pattern, noWeb
+}
+
+func TestPrecedence(t *testing.T) {
+	fs, width, pattern, noWeb := testFlags()
+	if err := fs.Parse([]string{"--width", "80"}); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"GOLIZER_WIDTH": "100", "GOLIZER_PATTERN": "tunnel"}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+	if err := ApplyEnv(fs, lookup); err != nil {
+		t.Fatal(err)
+	}
+	s, err := ParseSettings([]byte(sampleSettings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ApplyFile(fs, "config"); err != nil {
+		t.Fatal(err)
+	}
+	if *width != 80 {
+		t.Errorf("width = %d, want the flag's 80", *width)
+	}
+	if *pattern != "tunnel" {
+		t.Errorf("pattern = %q, want the environment's tunnel", *pattern)
+	}
+	if !*noWeb {
+		t.Errorf("no-web = false, want the file's true")
+	}
+}
+
+func TestApplyFileErrors(t *testing.T) {
+	fs, _, _, _ := testFlags()
+	s := &Settings{Flags: map[string]string{"widht": "1", "config": "x.json", "width": "wide"}}
+	err := s.ApplyFile(fs, "config")
+	if err == nil {
+		t.Fatal("want an error")
+	}
+	for _, want := range []string{`unknown setting "widht"`, "config can't be set", "width:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want it to mention %q", err, want)
+		}
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("web-port"); got != "GOLIZER_WEB_PORT" {
+		t.Fatalf("EnvName = %q", got)
+	}
+}
@@ -0,0 +1,285 @@
+// Package crash writes a diagnostics bundle when golizer dies: the last log
+// lines, a config snapshot, the tail of the frame profile, memory stats,
+// system info and a dump of every goroutine, all in one text file that can
+// be attached to a bug report. Headless installs rarely have anyone looking
+// at the terminal when it happens, so the bundle is the only record.
+package crash
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// profileTail is how many profile rows go into a bundle, a few
+	// hundred frames' worth of section timings.
+	profileTail = 400
+	// tailBytes is how much of the profile end is read to find them.
+	tailBytes = 64 << 10
+	// maxStack bounds the goroutine dump.
+	maxStack = 1 << 20
+)
+
+// Log keeps the most recent log lines in memory. Use it as (part of) a
+// logger's output.
+type Log struct {
+	mu      sync.Mutex
+	lines   []string
+	next    int
+	filled  bool
+	partial []byte
+}
+
+// NewLog keeps the last n lines.
+func NewLog(n int) *Log {
+	return &Log{lines: make([]string, max(n, 1))}
+}
+
+// Write splits p into lines; an unterminated tail waits for the next write.
+func (l *Log) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	data := append(l.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		l.lines[l.next] = string(data[:i])
+		l.next = (l.next + 1) % len(l.lines)
+		if l.next == 0 {
+			l.filled = true
+		}
+		data = data[i+1:]
+	}
+	l.partial = append(l.partial[:0], data...)
+	return len(p), nil
+}
+
+// Lines returns the kept lines, oldest first.
+func (l *Log) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []string
+	if l.filled {
+		out = append(out, l.lines[l.next:]...)
+	}
+	out = append(out, l.lines[:l.next]...)
+	if len(l.partial) > 0 {
+		out = append(out, string(l.partial))
+	}
+	return out
+}
+
+// Handler writes bundles. Set the fields before the first failure; Config
+// is called while the program is dying, so it must not take locks the
+// failing code may hold.
+type Handler struct {
+	// Dir receives the bundles; the temp dir is used when it is empty or
+	// not writable.
+	Dir string
+	Log *Log
+	// Config returns a JSON-encodable snapshot of the configuration.
+	Config func() any
+	// ProfileLog is the frame profile CSV whose tail is included.
+	ProfileLog string
+	// Restore puts the terminal back so the bundle path stays readable.
+	Restore func()
+
+	start time.Time
+	once  sync.Once
+}
+
+// NewHandler starts the uptime clock.
+func NewHandler() *Handler {
+	return &Handler{start: time.Now()}
+}
+
+// Recover must be deferred directly. On panic it restores the terminal,
+// writes a bundle, prints its path and exits with status 2.
+func (h *Handler) Recover() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	h.die(2, fmt.Sprintf("panic: %v", r), debug.Stack())
+}
+
+// Fatalf is log.Fatalf with a bundle.
+func (h *Handler) Fatalf(format string, args ...any) {
+	h.die(1, fmt.Sprintf(format, args...), nil)
+}
+
+func (h *Handler) die(code int, reason string, stack []byte) {
+	h.once.Do(func() {
+		if h.Restore != nil {
+			h.Restore()
+		}
+		fmt.Fprintf(os.Stderr, "\n[golizer] %s\n", reason)
+		if stack != nil {
+			os.Stderr.Write(stack)
+		}
+		path, err := h.Write(reason, stack)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[golizer] could not write crash report: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "[golizer] crash report -> %s\n", path)
+		}
+	})
+	os.Exit(code)
+}
+
+// Write saves a bundle for reason and returns its path. stack is the
+// failing goroutine's trace, if known.
+func (h *Handler) Write(reason string, stack []byte) (string, error) {
+	name := fmt.Sprintf("golizer-crash-%s.txt", time.Now().Format("20060102-150405"))
+	var f *os.File
+	var err error
+	for _, dir := range []string{h.Dir, os.TempDir()} {
+		if dir == "" {
+			continue
+		}
+		f, err = os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	w := bufio.NewWriter(f)
+	h.report(w, reason, stack)
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+func (h *Handler) report(w io.Writer, reason string, stack []byte) {
+	fmt.Fprintf(w, "golizer crash report %s\n\n%s\n", time.Now().Format(time.RFC3339), reason)
+	if stack != nil {
+		fmt.Fprintf(w, "\n%s", stack)
+	}
+
+	section(w, "system")
+	writeSystem(w, h.start)
+
+	section(w, "memory")
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	fmt.Fprintf(w, "heap in use   %d KiB\n", mem.HeapInuse/1024)
+	fmt.Fprintf(w, "heap objects  %d\n", mem.HeapObjects)
+	fmt.Fprintf(w, "total alloc   %d KiB\n", mem.TotalAlloc/1024)
+	fmt.Fprintf(w, "sys           %d KiB\n", mem.Sys/1024)
+	fmt.Fprintf(w, "gc runs       %d (last pause %v)\n", mem.NumGC, time.Duration(mem.PauseNs[(mem.NumGC+255)%256]))
+
+	section(w, "config")
+	if h.Config != nil {
+		data, err := json.MarshalIndent(h.Config(), "", "  ")
+		if err != nil {
+			fmt.Fprintf(w, "unavailable: %v\n", err)
+		} else {
+			fmt.Fprintf(w, "%s\n", data)
+		}
+	}
+
+	section(w, "log")
+	if h.Log != nil {
+		for _, line := range h.Log.Lines() {
+			fmt.Fprintln(w, line)
+		}
+	}
+
+	section(w, "profile")
+	if h.ProfileLog != "" {
+		writeTail(w, h.ProfileLog, profileTail)
+	}
+
+	section(w, "goroutines")
+	buf := make([]byte, maxStack)
+	buf = buf[:runtime.Stack(buf, true)]
+	w.Write(buf)
+}
+
+func section(w io.Writer, name string) {
+	fmt.Fprintf(w, "\n== %s\n", name)
+}
+
+func writeSystem(w io.Writer, start time.Time) {
+	host, _ := os.Hostname()
+	fmt.Fprintf(w, "go         %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(w, "module     %s %s\n", info.Main.Path, info.Main.Version)
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" || s.Key == "vcs.modified" {
+				fmt.Fprintf(w, "%-10s %s\n", s.Key, s.Value)
+			}
+		}
+	}
+	fmt.Fprintf(w, "host       %s\n", host)
+	fmt.Fprintf(w, "cpus       %d (GOMAXPROCS %d)\n", runtime.NumCPU(), runtime.GOMAXPROCS(0))
+	fmt.Fprintf(w, "goroutines %d\n", runtime.NumGoroutine())
+	fmt.Fprintf(w, "uptime     %v\n", time.Since(start).Round(time.Second))
+	fmt.Fprintf(w, "args       %q\n", os.Args)
+	// best effort, linux only (the Pi is where this matters)
+	if model := readTrimmed("/proc/device-tree/model"); model != "" {
+		fmt.Fprintf(w, "model      %s\n", model)
+	}
+	if load := readTrimmed("/proc/loadavg"); load != "" {
+		fmt.Fprintf(w, "loadavg    %s\n", load)
+	}
+	if temp := readTrimmed("/sys/class/thermal/thermal_zone0/temp"); temp != "" {
+		fmt.Fprintf(w, "cpu temp   %s m°C\n", temp)
+	}
+}
+
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(data), "\x00"))
+}
+
+// writeTail copies the last n lines of the file at path. The profile grows
+// for as long as golizer runs, so only its end is read.
+func writeTail(w io.Writer, path string, n int) {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(w, "unavailable: %v\n", err)
+		return
+	}
+	defer f.Close()
+	cut := false
+	if info, err := f.Stat(); err == nil && info.Size() > tailBytes {
+		_, err = f.Seek(info.Size()-tailBytes, io.SeekStart)
+		cut = err == nil
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		fmt.Fprintf(w, "unavailable: %v\n", err)
+		return
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if cut && len(lines) > 0 {
+		// the first line starts mid-row
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,69 @@
+package crash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogKeepsLastLines(t *testing.T) {
+	l := NewLog(3)
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(l, "line %d\n", i)
+	}
+	l.Write([]byte("partial"))
+	got := strings.Join(l.Lines(), "|")
+	if want := "line 2|line 3|line 4|partial"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteBundle(t *testing.T) {
+	dir := t.TempDir()
+	profile := filepath.Join(dir, "profile.csv")
+	var rows strings.Builder
+	rows.WriteString("timestamp,section,delta_ms\n")
+	for i := 0; i < profileTail+50; i++ {
+		fmt.Fprintf(&rows, "t,frame_total,%d\n", i)
+	}
+	if err := os.WriteFile(profile, []byte(rows.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler()
+	h.Dir = dir
+	h.Log = NewLog(10)
+	h.ProfileLog = profile
+	h.Config = func() any { return map[string]string{"pattern": "ripple"} }
+	fmt.Fprintln(h.Log, "audio stream opened")
+
+	path, err := h.Write("panic: boom", []byte("goroutine 1 [running]:\n"))
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if filepath.Dir(path) != dir {
+		t.Fatalf("bundle written to %s, want %s", path, dir)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+	for _, want := range []string{
+		"panic: boom",
+		"== system",
+		`"pattern": "ripple"`,
+		"audio stream opened",
+		fmt.Sprintf("t,frame_total,%d\n", profileTail+49),
+		"== goroutines",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+	if strings.Contains(report, "t,frame_total,49\n") {
+		t.Error("report holds more than the profile tail")
+	}
+}
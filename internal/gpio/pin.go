@@ -0,0 +1,86 @@
+// Package gpio drives output pins through the sysfs interface, enough to send
+// trigger pulses to modular gear from a Raspberry Pi header.
+package gpio
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sysfsRoot is where the kernel exposes the pins; tests point it elsewhere.
+var sysfsRoot = "/sys/class/gpio"
+
+// Pin is an exported sysfs GPIO configured as output.
+type Pin struct {
+	mu     sync.Mutex
+	number int
+	value  *os.File
+	timer  *time.Timer
+}
+
+// Open exports the pin (if needed) and configures it as a low output.
+func Open(number int) (*Pin, error) {
+	if number < 0 {
+		return nil, fmt.Errorf("invalid GPIO pin %d", number)
+	}
+	dir := filepath.Join(sysfsRoot, "gpio"+strconv.Itoa(number))
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(filepath.Join(sysfsRoot, "export"), []byte(strconv.Itoa(number)), 0); err != nil {
+			return nil, fmt.Errorf("export GPIO %d: %w", number, err)
+		}
+		// udev needs a moment to fix permissions on the new node
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "direction"), []byte("low"), 0); err != nil {
+		return nil, fmt.Errorf("set GPIO %d direction: %w", number, err)
+	}
+	value, err := os.OpenFile(filepath.Join(dir, "value"), os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("open GPIO %d: %w", number, err)
+	}
+	return &Pin{number: number, value: value}, nil
+}
+
+// Pulse drives the pin high for d, then low again without blocking.
+func (p *Pin) Pulse(d time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.value == nil {
+		return os.ErrClosed
+	}
+	if _, err := p.value.WriteAt([]byte("1"), 0); err != nil {
+		return err
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.timer = time.AfterFunc(d, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.value != nil {
+			_, _ = p.value.WriteAt([]byte("0"), 0)
+		}
+	})
+	return nil
+}
+
+// Close drives the pin low and releases it.
+func (p *Pin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.value == nil {
+		return nil
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	_, _ = p.value.WriteAt([]byte("0"), 0)
+	err := p.value.Close()
+	p.value = nil
+	return err
+}
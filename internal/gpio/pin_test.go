@@ -0,0 +1,81 @@
+package gpio
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSysfs points sysfsRoot at a temp dir with pin 17 already exported.
+func fakeSysfs(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	old := sysfsRoot
+	sysfsRoot = root
+	t.Cleanup(func() { sysfsRoot = old })
+	dir := filepath.Join(root, "gpio17")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"direction", "value"} {
+		if err := os.WriteFile(filepath.Join(dir, f), []byte("in"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestPinPulse(t *testing.T) {
+	dir := fakeSysfs(t)
+	read := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(dir, name))
+		return strings.TrimSpace(string(data))
+	}
+	pin, err := Open(17)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := read("direction"); got != "low" {
+		t.Fatalf("direction %q, want low", got)
+	}
+	if err := pin.Pulse(20 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if got := read("value"); !strings.HasPrefix(got, "1") {
+		t.Fatalf("value %q during the pulse, want 1", got)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if got := read("value"); !strings.HasPrefix(got, "0") {
+		t.Fatalf("value %q after the pulse, want 0", got)
+	}
+
+	// a pulse still high when the pin closes ends low
+	if err := pin.Pulse(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := pin.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := read("value"); !strings.HasPrefix(got, "0") {
+		t.Fatalf("value %q after close, want 0", got)
+	}
+	if err := pin.Pulse(time.Millisecond); err != os.ErrClosed {
+		t.Fatalf("pulse after close: %v", err)
+	}
+}
+
+func TestOpenExportsAndFails(t *testing.T) {
+	root := filepath.Dir(fakeSysfs(t))
+	if _, err := Open(-1); err == nil {
+		t.Error("negative pin opened")
+	}
+	// pin 4 isn't exported: Open asks for it, but no kernel makes the node
+	if _, err := Open(4); err == nil || !strings.Contains(err.Error(), "set GPIO 4 direction") {
+		t.Errorf("unexported pin: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "export")); string(data) != "4" {
+		t.Errorf("export got %q, want 4", data)
+	}
+}
@@ -0,0 +1,107 @@
+// Package lyrics reads timed lyric files in the LRC format.
+package lyrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Line is one lyric line and the time it starts.
+type Line struct {
+	At   time.Duration
+	Text string
+}
+
+// Track is a list of lines sorted by start time.
+type Track []Line
+
+// Load parses the .lrc file at path.
+func Load(path string) (Track, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads LRC lines such as "[01:02.50]text". A line may carry several
+// timestamps; ID tags like [ar:...] are skipped except [offset:+/-ms], which
+// shifts every line (positive values show lyrics earlier).
+func Parse(r io.Reader) (Track, error) {
+	var track Track
+	var offset time.Duration
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		rest := strings.TrimSpace(scanner.Text())
+		var stamps []time.Duration
+		for strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated tag", lineNo)
+			}
+			tag := rest[1:end]
+			rest = strings.TrimSpace(rest[end+1:])
+			if value, ok := strings.CutPrefix(tag, "offset:"); ok {
+				ms, err := strconv.Atoi(strings.TrimSpace(value))
+				if err != nil {
+					return nil, fmt.Errorf("line %d: bad offset %q", lineNo, value)
+				}
+				offset = time.Duration(ms) * time.Millisecond
+				continue
+			}
+			at, ok := parseStamp(tag)
+			if !ok {
+				// ID tag ([ar:], [ti:], ...)
+				continue
+			}
+			stamps = append(stamps, at)
+		}
+		for _, at := range stamps {
+			track = append(track, Line{At: at, Text: rest})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for i := range track {
+		track[i].At = max(0, track[i].At-offset)
+	}
+	sort.SliceStable(track, func(i, j int) bool { return track[i].At < track[j].At })
+	return track, nil
+}
+
+// parseStamp reads mm:ss, mm:ss.xx or mm:ss:xx.
+func parseStamp(tag string) (time.Duration, bool) {
+	minutes, rest, ok := strings.Cut(tag, ":")
+	if !ok {
+		return 0, false
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 {
+		return 0, false
+	}
+	rest = strings.Replace(rest, ":", ".", 1)
+	s, err := strconv.ParseFloat(rest, 64)
+	if err != nil || s < 0 {
+		return 0, false
+	}
+	return time.Duration(m)*time.Minute + time.Duration(s*float64(time.Second)), true
+}
+
+// At returns the line showing at d, or "" before the first line.
+func (t Track) At(d time.Duration) string {
+	i := sort.Search(len(t), func(i int) bool { return t[i].At > d })
+	if i == 0 {
+		return ""
+	}
+	return t[i-1].Text
+}
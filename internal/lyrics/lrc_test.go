@@ -0,0 +1,44 @@
+package lyrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimestampsAndOffset(t *testing.T) {
+	input := `[ar:someone]
+[offset:500]
+[00:01.00][00:10.50]chorus
+[00:05.00]verse
+`
+	track, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(track) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(track))
+	}
+
+	cases := []struct {
+		at   time.Duration
+		want string
+	}{
+		{0, ""},
+		{500 * time.Millisecond, "chorus"},
+		{4 * time.Second, "chorus"},
+		{4600 * time.Millisecond, "verse"},
+		{10 * time.Second, "chorus"},
+	}
+	for _, tc := range cases {
+		if got := track.At(tc.at); got != tc.want {
+			t.Errorf("At(%v) = %q, want %q", tc.at, got, tc.want)
+		}
+	}
+}
+
+func TestParseRejectsUnterminatedTag(t *testing.T) {
+	if _, err := Parse(strings.NewReader("[00:01.00 broken")); err == nil {
+		t.Fatal("expected an error")
+	}
+}
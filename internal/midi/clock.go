@@ -0,0 +1,94 @@
+package midi
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// PulsesPerQuarter is the MIDI clock resolution.
+const PulsesPerQuarter = 24
+
+// Clock emits MIDI timing clock on its own goroutine so tick spacing doesn't
+// depend on the render frame rate.
+type Clock struct {
+	out  *Out
+	bpm  atomic.Uint64 // math.Float64bits
+	sync chan struct{}
+}
+
+// NewClock returns a clock writing to out. It stays silent until SetTempo
+// receives a positive BPM.
+func NewClock(out *Out) *Clock {
+	return &Clock{out: out, sync: make(chan struct{}, 1)}
+}
+
+// SetTempo updates the clock rate; 0 stops the transport.
+func (c *Clock) SetTempo(bpm float64) {
+	c.bpm.Store(math.Float64bits(bpm))
+}
+
+// Downbeat realigns the tick grid to now, e.g. on a detected kick: when the
+// running clock is within a few pulses of a beat, that beat's first tick
+// goes out now and the following ones are spaced from it.
+func (c *Clock) Downbeat() {
+	select {
+	case c.sync <- struct{}{}:
+	default:
+	}
+}
+
+// Run sends clock ticks until ctx is cancelled.
+func (c *Clock) Run(ctx context.Context) {
+	running := false
+	timer := time.NewTimer(time.Millisecond)
+	defer timer.Stop()
+	pulse := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			if running {
+				_ = c.out.Stop()
+			}
+			return
+		case <-c.sync:
+			if !running || !nearBeat(pulse) {
+				continue
+			}
+			// the beat's first tick goes out now
+			pulse = 0
+			timer.Reset(0)
+			continue
+		case <-timer.C:
+		}
+
+		bpm := math.Float64frombits(c.bpm.Load())
+		if bpm <= 0 {
+			if running {
+				_ = c.out.Stop()
+				running = false
+			}
+			timer.Reset(100 * time.Millisecond)
+			continue
+		}
+		if !running {
+			_ = c.out.Start()
+			running = true
+			pulse = 0
+		}
+		_ = c.out.Clock()
+		pulse++
+		interval := time.Duration(float64(time.Minute) / (bpm * PulsesPerQuarter))
+		timer.Reset(interval)
+	}
+}
+
+// nearBeat reports whether pulse, the ticks sent so far, is within a few
+// pulses of a beat boundary. Only then does a downbeat snap the grid, so a
+// stray hit can't shift it by half a beat.
+func nearBeat(pulse int) bool {
+	p := pulse % PulsesPerQuarter
+	return p > PulsesPerQuarter-4 || p < 4
+}
@@ -0,0 +1,74 @@
+package midi
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNearBeat(t *testing.T) {
+	for pulse, want := range map[int]bool{
+		0: true, 3: true, 4: false, 12: false, 20: false, 21: true, 23: true,
+		24: true, 27: true, 28: false, 47: true,
+	} {
+		if got := nearBeat(pulse); got != want {
+			t.Errorf("pulse %d: %v, want %v", pulse, got, want)
+		}
+	}
+}
+
+// runClock runs a clock on a test port until stop is called, which
+// returns everything it wrote.
+func runClock(t *testing.T, bpm float64) (*Clock, string, func() []byte) {
+	out, path := openTestOut(t)
+	clock := NewClock(out)
+	clock.SetTempo(bpm)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		clock.Run(ctx)
+		close(done)
+	}()
+	return clock, path, func() []byte {
+		cancel()
+		<-done
+		data, _ := os.ReadFile(path)
+		return data
+	}
+}
+
+func TestClockTransport(t *testing.T) {
+	clock, _, stop := runClock(t, 0)
+	time.Sleep(20 * time.Millisecond)
+	clock.SetTempo(600) // a tick every 4ms, once the silent clock looks again
+	time.Sleep(150 * time.Millisecond)
+	got := stop()
+	if len(got) < 4 || got[0] != statusStart || got[len(got)-1] != statusStop {
+		t.Fatalf("wrote % x, want start, ticks, stop", got)
+	}
+	if ticks := bytes.Count(got, []byte{statusClock}); ticks != len(got)-2 {
+		t.Fatalf("wrote % x, want only ticks between start and stop", got)
+	}
+}
+
+func TestClockDownbeatTicksNow(t *testing.T) {
+	// 20 bpm: 125ms between ticks, far longer than the test waits
+	clock, path, stop := runClock(t, 20)
+	defer stop()
+	ticks := func() int {
+		data, _ := os.ReadFile(path)
+		return bytes.Count(data, []byte{statusClock})
+	}
+	time.Sleep(30 * time.Millisecond)
+	if n := ticks(); n != 1 {
+		t.Fatalf("%d ticks after the first, want 1", n)
+	}
+	// one tick into the beat is near enough to snap
+	clock.Downbeat()
+	time.Sleep(30 * time.Millisecond)
+	if n := ticks(); n != 2 {
+		t.Fatalf("%d ticks after a downbeat, want the beat's first tick right away", n)
+	}
+}
@@ -0,0 +1,78 @@
+package midi
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// followTicks is how many recent clock ticks the tempo is averaged
+	// over, one beat's worth, which evens out USB jitter.
+	followTicks = PulsesPerQuarter + 1
+	// followTimeout is the gap after which the clock counts as gone; the
+	// slowest believable tempo ticks every 80 ms or so.
+	followTimeout = 500 * time.Millisecond
+)
+
+// Follower tracks an incoming MIDI clock: the tempo it ticks at and the
+// beats it counts while the transport runs. Feed it the realtime bytes of
+// an In with Handle. It is safe for concurrent use.
+type Follower struct {
+	mu      sync.Mutex
+	ticks   []time.Time
+	pulse   int // ticks since the transport started
+	beats   int
+	stopped bool
+}
+
+// Handle takes one realtime byte received at now. A clock that never sends
+// a start counts as running; a stop pauses it until start or continue.
+func (f *Follower) Handle(status byte, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch status {
+	case statusClock:
+		if n := len(f.ticks); n > 0 && now.Sub(f.ticks[n-1]) > followTimeout {
+			f.ticks = f.ticks[:0]
+		}
+		f.ticks = append(f.ticks, now)
+		if len(f.ticks) > followTicks {
+			f.ticks = f.ticks[len(f.ticks)-followTicks:]
+		}
+		if f.stopped {
+			return
+		}
+		// the first tick after a start is the downbeat
+		if f.pulse%PulsesPerQuarter == 0 {
+			f.beats++
+		}
+		f.pulse++
+	case statusStart:
+		f.stopped, f.pulse = false, 0
+	case statusContinue:
+		f.stopped = false
+	case statusStop:
+		f.stopped = true
+	}
+}
+
+// BPM returns the clock's tempo at now, 0 while the transport is stopped,
+// before a beat's worth of ticks came in or once they stopped coming.
+func (f *Follower) BPM(now time.Time) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := len(f.ticks)
+	if f.stopped || n < followTicks || now.Sub(f.ticks[n-1]) > followTimeout {
+		return 0
+	}
+	tick := f.ticks[n-1].Sub(f.ticks[0]).Seconds() / float64(n-1)
+	return 60 / (tick * PulsesPerQuarter)
+}
+
+// Beats returns how many beats the clock counted so far; a change between
+// two calls means a beat fell in between.
+func (f *Follower) Beats() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.beats
+}
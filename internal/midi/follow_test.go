@@ -0,0 +1,64 @@
+package midi
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFollower(t *testing.T) {
+	var f Follower
+	now := time.Unix(0, 0)
+	// 125 bpm: 24 ticks a beat, one every 20ms
+	const tick = 20 * time.Millisecond
+	ticks := func(n int) {
+		for range n {
+			now = now.Add(tick)
+			f.Handle(statusClock, now)
+		}
+	}
+
+	f.Handle(statusStart, now)
+	ticks(PulsesPerQuarter)
+	if bpm := f.BPM(now); bpm != 0 {
+		t.Errorf("tempo %v before a beat's worth of ticks", bpm)
+	}
+	if f.Beats() != 1 {
+		t.Errorf("beats %d after the first beat, want 1", f.Beats())
+	}
+	ticks(PulsesPerQuarter)
+	if bpm := f.BPM(now); math.Abs(bpm-125) > 1e-9 {
+		t.Errorf("tempo %v, want 125", bpm)
+	}
+	if f.Beats() != 2 {
+		t.Errorf("beats %d, want 2", f.Beats())
+	}
+
+	// stop: no tempo, no beats, though the clock keeps ticking
+	f.Handle(statusStop, now)
+	ticks(PulsesPerQuarter * 2)
+	if bpm := f.BPM(now); bpm != 0 || f.Beats() != 2 {
+		t.Errorf("stopped: tempo %v beats %d, want 0 and 2", bpm, f.Beats())
+	}
+	// start counts from the downbeat again
+	f.Handle(statusStart, now)
+	ticks(1)
+	if f.Beats() != 3 || f.BPM(now) == 0 {
+		t.Errorf("restarted: tempo %v beats %d, want 125 and 3", f.BPM(now), f.Beats())
+	}
+
+	// the clock going away drops the tempo
+	if bpm := f.BPM(now.Add(followTimeout + tick)); bpm != 0 {
+		t.Errorf("tempo %v after the clock stopped coming", bpm)
+	}
+	// and a gap restarts the average instead of counting it in
+	now = now.Add(time.Second)
+	ticks(PulsesPerQuarter)
+	if bpm := f.BPM(now); bpm != 0 {
+		t.Errorf("tempo %v right after a gap", bpm)
+	}
+	ticks(1)
+	if bpm := f.BPM(now); math.Abs(bpm-125) > 1e-9 {
+		t.Errorf("tempo %v after the gap, want 125", bpm)
+	}
+}
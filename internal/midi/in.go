@@ -0,0 +1,100 @@
+package midi
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// In is a MIDI input port.
+type In struct {
+	file *os.File
+	name string
+}
+
+// OpenIn opens a rawmidi device for reading. "auto" picks the first device
+// found under /dev/snd.
+func OpenIn(port string) (*In, error) {
+	if port == "" || port == "auto" {
+		ports := Ports()
+		if len(ports) == 0 {
+			return nil, errors.New("no MIDI ports found under /dev/snd")
+		}
+		port = ports[0]
+	}
+	file, err := os.Open(port)
+	if err != nil {
+		return nil, fmt.Errorf("open MIDI port: %w", err)
+	}
+	return &In{file: file, name: port}, nil
+}
+
+// Name returns the device path.
+func (in *In) Name() string {
+	return in.name
+}
+
+// Notes calls fn for every note-on with a non-zero velocity (channel 1-16)
+// until the port is closed or fails. It handles running status and skips
+// everything else, including realtime bytes and sysex.
+func (in *In) Notes(fn func(channel int, note, velocity uint8)) error {
+	return in.Read(fn, nil)
+}
+
+// Read is Notes that also hands realtime bytes (clock, start, stop, ...) to
+// realtime as they arrive. Either callback may be nil.
+func (in *In) Read(notes func(channel int, note, velocity uint8), realtime func(status byte)) error {
+	r := bufio.NewReader(in.file)
+	var status byte
+	var data [2]byte
+	n := 0
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch {
+		case b >= 0xF8:
+			// realtime messages may appear anywhere
+			if realtime != nil {
+				realtime(b)
+			}
+			continue
+		case b >= 0xF0:
+			// system common / sysex: drop running status until the next status
+			status = 0
+			continue
+		case b&0x80 != 0:
+			status, n = b, 0
+			continue
+		}
+		if status == 0 {
+			continue
+		}
+		data[n] = b
+		n++
+		if n < dataBytes(status) {
+			continue
+		}
+		n = 0
+		if status&0xF0 == statusNoteOn && data[1] > 0 && notes != nil {
+			notes(int(status&0x0F)+1, data[0], data[1])
+		}
+	}
+}
+
+// dataBytes returns how many data bytes follow a channel status byte.
+func dataBytes(status byte) int {
+	switch status & 0xF0 {
+	case 0xC0, 0xD0:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Close releases the device, ending Notes.
+func (in *In) Close() error {
+	return in.file.Close()
+}
@@ -0,0 +1,46 @@
+package midi
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestInNotes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "midi")
+	stream := []byte{
+		0x90, 60, 100, // note on, channel 1
+		62, 90, // running status
+		0xF8,  // clock in between
+		64, 0, // velocity 0 is a note off
+		0xC3, 5, // program change: one data byte
+		0x9A, 0xF8, 36, 127, // channel 11, clock inside the message
+		0xF0, 0x7E, 0x01, 0xF7, // sysex
+		40, 40, // data without a status after sysex
+		0x80, 60, 0, // note off
+		0x9A, 38, 1,
+	}
+	if err := os.WriteFile(path, stream, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := &In{file: f, name: path}
+	defer in.Close()
+
+	var got []string
+	err = in.Notes(func(channel int, note, velocity uint8) {
+		got = append(got, fmt.Sprintf("%d/%d/%d", channel, note, velocity))
+	})
+	if err != io.EOF {
+		t.Errorf("ended with %v, want EOF", err)
+	}
+	if want := []string{"1/60/100", "1/62/90", "11/36/127", "11/38/1"}; !slices.Equal(got, want) {
+		t.Errorf("notes %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+// Package midi talks raw MIDI to ALSA rawmidi devices (/dev/snd/midiC*D*),
+// which keeps golizer free of cgo MIDI libraries.
+package midi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	statusNoteOn   = 0x90
+	statusNoteOff  = 0x80
+	statusClock    = 0xF8
+	statusStart    = 0xFA
+	statusContinue = 0xFB
+	statusStop     = 0xFC
+)
+
+// Out is a MIDI output port. It is safe for concurrent use.
+type Out struct {
+	mu   sync.Mutex
+	file *os.File
+	name string
+}
+
+// OpenOut opens a rawmidi device for writing. "auto" picks the first device
+// found under /dev/snd.
+func OpenOut(port string) (*Out, error) {
+	if port == "" || port == "auto" {
+		ports := Ports()
+		if len(ports) == 0 {
+			return nil, errors.New("no MIDI ports found under /dev/snd")
+		}
+		port = ports[0]
+	}
+	file, err := os.OpenFile(port, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("open MIDI port: %w", err)
+	}
+	return &Out{file: file, name: port}, nil
+}
+
+// Ports lists ALSA rawmidi devices.
+func Ports() []string {
+	ports, _ := filepath.Glob("/dev/snd/midiC*D*")
+	sort.Strings(ports)
+	return ports
+}
+
+// Name returns the device path.
+func (o *Out) Name() string {
+	return o.name
+}
+
+// Note sends a note-on immediately followed by its note-off, which is all
+// drum machines and trigger converters need. channel is 1-16.
+func (o *Out) Note(channel int, note, velocity uint8) error {
+	ch := byte((channel - 1) & 0x0F)
+	return o.write(statusNoteOn|ch, note&0x7F, velocity&0x7F, statusNoteOff|ch, note&0x7F, 0)
+}
+
+// Clock sends one MIDI timing clock tick (24 per quarter note).
+func (o *Out) Clock() error { return o.write(statusClock) }
+
+// Start sends a transport start.
+func (o *Out) Start() error { return o.write(statusStart) }
+
+// Stop sends a transport stop.
+func (o *Out) Stop() error { return o.write(statusStop) }
+
+func (o *Out) write(data ...byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file == nil {
+		return os.ErrClosed
+	}
+	_, err := o.file.Write(data)
+	return err
+}
+
+// Close releases the device.
+func (o *Out) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file == nil {
+		return nil
+	}
+	err := o.file.Close()
+	o.file = nil
+	return err
+}
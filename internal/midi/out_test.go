@@ -0,0 +1,61 @@
+package midi
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// openTestOut opens an Out on a plain file standing in for the port.
+func openTestOut(t *testing.T) (*Out, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "midiC0D0")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out, err := OpenOut(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { out.Close() })
+	return out, path
+}
+
+func TestOutMessages(t *testing.T) {
+	out, path := openTestOut(t)
+	if out.Name() != path {
+		t.Errorf("name %q, want %q", out.Name(), path)
+	}
+	for _, send := range []func() error{
+		func() error { return out.Note(10, 36, 127) },
+		func() error { return out.Note(17, 200, 255) }, // out of range bits are masked
+		out.Start,
+		out.Clock,
+		out.Stop,
+	} {
+		if err := send(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, _ := os.ReadFile(path)
+	want := []byte{
+		0x99, 36, 127, 0x89, 36, 0,
+		0x90, 72, 127, 0x80, 72, 0,
+		0xFA, 0xF8, 0xFC,
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("wrote % x, want % x", got, want)
+	}
+
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Clock(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("clock after close: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		t.Errorf("second close: %v", err)
+	}
+}
@@ -0,0 +1,325 @@
+// Package mqtt is a small MQTT 3.1.1 client, enough to publish state to a
+// broker and take commands from it: QoS 0 publish and subscribe, a last
+// will, username and password and keep-alive pings. Brokers like Mosquitto
+// and the one in Home Assistant speak it.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Packet types, shifted into the fixed header's high nibble.
+const (
+	typeConnect     = 1
+	typeConnAck     = 2
+	typePublish     = 3
+	typeSubscribe   = 8
+	typeSubAck      = 9
+	typePingReq     = 12
+	typePingResp    = 13
+	typeDisconnect  = 14
+	maxRemainingLen = 268_435_455
+)
+
+// Options configure a connection.
+type Options struct {
+	ClientID string
+	Username string
+	Password string
+	// KeepAlive is how often the client pings an idle connection, and the
+	// broker drops it after 1.5 times that without a word (default 30s).
+	KeepAlive time.Duration
+	// Will is published by the broker when the connection drops without a
+	// clean Close, e.g. an "offline" availability message.
+	Will *Message
+}
+
+// Message is one published message.
+type Message struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+// Client is a connection to a broker. Publish and Subscribe may be called
+// from any goroutine while Serve reads.
+type Client struct {
+	conn      net.Conn
+	r         *bufio.Reader
+	keepAlive time.Duration
+
+	wmu    sync.Mutex
+	nextID uint16
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Connect dials the broker at addr (host:port) and waits for it to accept.
+func Connect(ctx context.Context, addr string, opts Options) (*Client, error) {
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = 30 * time.Second
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{conn: conn, r: bufio.NewReader(conn), keepAlive: opts.KeepAlive, done: make(chan struct{})}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if err := c.write(typeConnect<<4, connectPacket(opts)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	header, body, err := c.read()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if kind := header >> 4; kind != typeConnAck || len(body) != 2 {
+		conn.Close()
+		return nil, fmt.Errorf("mqtt: expected CONNACK, got packet type %d", kind)
+	}
+	if code := body[1]; code != 0 {
+		conn.Close()
+		return nil, fmt.Errorf("mqtt: connection refused: %s", refusal(code))
+	}
+	conn.SetDeadline(time.Time{})
+	go c.ping()
+	return c, nil
+}
+
+func refusal(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "client id rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad username or password"
+	case 5:
+		return "not authorized"
+	}
+	return fmt.Sprintf("code %d", code)
+}
+
+func connectPacket(opts Options) []byte {
+	flags := byte(0x02) // clean session
+	if opts.Will != nil {
+		flags |= 0x04
+		if opts.Will.Retain {
+			flags |= 0x20
+		}
+	}
+	if opts.Username != "" {
+		flags |= 0x80
+	}
+	if opts.Password != "" {
+		flags |= 0x40
+	}
+	p := appendString(nil, "MQTT")
+	p = append(p, 4, flags) // protocol level 3.1.1
+	p = binary.BigEndian.AppendUint16(p, uint16(min(opts.KeepAlive/time.Second, 65535)))
+	p = appendString(p, opts.ClientID)
+	if opts.Will != nil {
+		p = appendString(p, opts.Will.Topic)
+		p = appendString(p, string(opts.Will.Payload))
+	}
+	if opts.Username != "" {
+		p = appendString(p, opts.Username)
+	}
+	if opts.Password != "" {
+		p = appendString(p, opts.Password)
+	}
+	return p
+}
+
+// Publish sends a QoS 0 message.
+func (c *Client) Publish(m Message) error {
+	header := byte(typePublish << 4)
+	if m.Retain {
+		header |= 0x01
+	}
+	return c.write(header, append(appendString(nil, m.Topic), m.Payload...))
+}
+
+// Subscribe asks for messages on the topic filters (wildcards allowed) at
+// QoS 0; they arrive through Serve.
+func (c *Client) Subscribe(filters ...string) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	c.wmu.Lock()
+	c.nextID++
+	if c.nextID == 0 {
+		c.nextID = 1
+	}
+	p := binary.BigEndian.AppendUint16(nil, c.nextID)
+	c.wmu.Unlock()
+	for _, f := range filters {
+		p = append(appendString(p, f), 0)
+	}
+	return c.write(typeSubscribe<<4|0x02, p)
+}
+
+// Serve reads from the broker, calling fn for every message on a
+// subscribed topic, until the connection fails or is closed. After Close
+// it returns nil.
+func (c *Client) Serve(fn func(Message)) error {
+	for {
+		header, body, err := c.read()
+		if err != nil {
+			select {
+			case <-c.done:
+				return nil
+			default:
+				return err
+			}
+		}
+		switch kind := header >> 4; kind {
+		case typePublish:
+			m, err := parsePublish(header, body)
+			if err != nil {
+				return err
+			}
+			fn(m)
+		case typeSubAck:
+			// the last byte per filter is the granted QoS, 0x80 a refusal
+			if len(body) > 2 && body[len(body)-1] == 0x80 {
+				return errors.New("mqtt: subscription refused")
+			}
+		case typePingResp:
+		default:
+			return fmt.Errorf("mqtt: unexpected packet type %d", kind)
+		}
+	}
+}
+
+// parsePublish reads a PUBLISH packet. Subscriptions are QoS 0, so a
+// higher QoS only shows up from odd brokers; its packet id is skipped
+// and the message not acknowledged.
+func parsePublish(header byte, body []byte) (Message, error) {
+	topic, rest, err := readString(body)
+	if err != nil {
+		return Message{}, err
+	}
+	if header&0x06 != 0 {
+		if len(rest) < 2 {
+			return Message{}, errors.New("mqtt: short publish")
+		}
+		rest = rest[2:]
+	}
+	return Message{Topic: topic, Payload: rest, Retain: header&0x01 != 0}, nil
+}
+
+// Close disconnects cleanly, so the broker doesn't publish the will.
+func (c *Client) Close() error {
+	var err error
+	c.once.Do(func() {
+		close(c.done)
+		c.write(typeDisconnect<<4, nil)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// ping keeps an idle connection alive. Pinging on a timer whether or not
+// anything was sent is simpler than tracking idleness and well within
+// what brokers accept.
+func (c *Client) ping() {
+	t := time.NewTicker(c.keepAlive)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-t.C:
+			if err := c.write(typePingReq<<4, nil); err != nil {
+				c.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) write(header byte, body []byte) error {
+	if len(body) > maxRemainingLen {
+		return errors.New("mqtt: packet too large")
+	}
+	p := append([]byte{header}, remainingLength(len(body))...)
+	p = append(p, body...)
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(c.keepAlive))
+	_, err := c.conn.Write(p)
+	return err
+}
+
+// read returns the next packet's fixed header byte and body.
+func (c *Client) read() (byte, []byte, error) {
+	header, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var n, shift int
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		n |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+		if shift += 7; shift > 21 {
+			return 0, nil, errors.New("mqtt: bad remaining length")
+		}
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// remainingLength encodes n as MQTT's variable length integer.
+func remainingLength(n int) []byte {
+	var p []byte
+	for {
+		b := byte(n % 128)
+		if n /= 128; n > 0 {
+			b |= 0x80
+		}
+		p = append(p, b)
+		if n == 0 {
+			return p
+		}
+	}
+}
+
+func appendString(p []byte, s string) []byte {
+	p = binary.BigEndian.AppendUint16(p, uint16(len(s)))
+	return append(p, s...)
+}
+
+func readString(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, errors.New("mqtt: short string")
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return "", nil, errors.New("mqtt: short string")
+	}
+	return string(b[2 : 2+n]), b[2+n:], nil
+}
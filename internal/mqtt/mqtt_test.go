@@ -0,0 +1,131 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// broker accepts one client on a local port and hands its side of the
+// connection to fn.
+func broker(t *testing.T, fn func(c *Client)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// the broker side reads and writes the same framing
+		fn(&Client{conn: conn, r: bufio.NewReader(conn), keepAlive: time.Second})
+	}()
+	return ln.Addr().String()
+}
+
+func TestRemainingLength(t *testing.T) {
+	cases := map[int][]byte{
+		0:         {0x00},
+		127:       {0x7f},
+		128:       {0x80, 0x01},
+		16383:     {0xff, 0x7f},
+		16384:     {0x80, 0x80, 0x01},
+		268435455: {0xff, 0xff, 0xff, 0x7f},
+	}
+	for n, want := range cases {
+		if got := remainingLength(n); !bytes.Equal(got, want) {
+			t.Errorf("remainingLength(%d) = % x, want % x", n, got, want)
+		}
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	got := make(chan string, 4)
+	addr := broker(t, func(b *Client) {
+		header, body, err := b.read()
+		if err != nil || header>>4 != typeConnect {
+			got <- "bad connect"
+			return
+		}
+		got <- "connect " + string(body)
+		b.write(typeConnAck<<4, []byte{0, 0})
+		for {
+			header, body, err := b.read()
+			if err != nil {
+				return
+			}
+			switch header >> 4 {
+			case typeSubscribe:
+				b.write(typeSubAck<<4, append(body[:2:2], 0))
+				// answer the subscription with a retained message
+				b.write(typePublish<<4|0x01, append(appendString(nil, "golizer/cmd/pattern"), "tunnel"...))
+			case typePublish:
+				m, _ := parsePublish(header, body)
+				got <- "publish " + m.Topic + " " + string(m.Payload)
+			}
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	c, err := Connect(ctx, addr, Options{
+		ClientID: "golizer-test",
+		Username: "user",
+		Password: "secret",
+		Will:     &Message{Topic: "golizer/availability", Payload: []byte("offline"), Retain: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if connect := <-got; !strings.Contains(connect, "golizer-test") || !strings.Contains(connect, "offline") || !strings.Contains(connect, "secret") {
+		t.Fatalf("connect packet missing fields: %q", connect)
+	}
+	if err := c.Subscribe("golizer/cmd/#"); err != nil {
+		t.Fatal(err)
+	}
+	msgs := make(chan Message, 1)
+	served := make(chan error, 1)
+	go func() { served <- c.Serve(func(m Message) { msgs <- m }) }()
+
+	select {
+	case m := <-msgs:
+		if m.Topic != "golizer/cmd/pattern" || string(m.Payload) != "tunnel" || !m.Retain {
+			t.Fatalf("got %+v", m)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message delivered")
+	}
+
+	if err := c.Publish(Message{Topic: "golizer/features", Payload: []byte(`{"bass":0.5}`)}); err != nil {
+		t.Fatal(err)
+	}
+	if p := <-got; p != `publish golizer/features {"bass":0.5}` {
+		t.Fatalf("broker got %q", p)
+	}
+
+	c.Close()
+	if err := <-served; err != nil {
+		t.Fatalf("Serve after Close = %v, want nil", err)
+	}
+}
+
+func TestConnectRefused(t *testing.T) {
+	addr := broker(t, func(b *Client) {
+		b.read()
+		b.write(typeConnAck<<4, []byte{0, 4})
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err := Connect(ctx, addr, Options{ClientID: "x"})
+	if err == nil || !strings.Contains(err.Error(), "bad username or password") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package nowplaying
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// mpris follows the active MPRIS player (spotify, vlc, browsers, ...)
+// through playerctl, which streams a line per metadata change.
+type mpris struct{}
+
+func (mpris) Name() string { return "mpris" }
+
+func (mpris) Run(ctx context.Context, update func(string)) error {
+	cmd := exec.CommandContext(ctx, "playerctl", "metadata", "--follow", "--format", "{{artist}}\t{{title}}")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start playerctl: %w", err)
+	}
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		artist, title, _ := strings.Cut(scanner.Text(), "\t")
+		update(track(artist, title))
+	}
+	return cmd.Wait()
+}
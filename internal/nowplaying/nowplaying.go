@@ -0,0 +1,74 @@
+// Package nowplaying follows the track a media player on the same machine
+// is playing, for the now-playing widget.
+package nowplaying
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Source reports the playing track as "artist - title", or "" while
+// nothing plays.
+type Source interface {
+	// Run calls update with every change until ctx is done or the source
+	// fails.
+	Run(ctx context.Context, update func(string)) error
+	// Name describes the source for logs.
+	Name() string
+}
+
+// retryDelay is how long Follow waits before reopening a failed source.
+const retryDelay = 5 * time.Second
+
+// Open returns the source for spec: "mpris" (or empty) asks playerctl about
+// the active MPRIS player; "shairport:<path>" reads a shairport-sync
+// metadata pipe.
+func Open(spec string) (Source, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "" || spec == "mpris":
+		return mpris{}, nil
+	case strings.HasPrefix(spec, "shairport:"):
+		path := strings.TrimSpace(strings.TrimPrefix(spec, "shairport:"))
+		if path == "" {
+			return nil, fmt.Errorf("now playing: shairport needs a pipe path")
+		}
+		return shairport{path: path}, nil
+	}
+	return nil, fmt.Errorf("now playing: unknown source %q (want mpris or shairport:<path>)", spec)
+}
+
+// Follow runs src until ctx is done, reopening it after failures. logf
+// reports the first failure of each streak.
+func Follow(ctx context.Context, src Source, update func(string), logf func(string, ...any)) {
+	failing := false
+	for ctx.Err() == nil {
+		err := src.Run(ctx, update)
+		if ctx.Err() != nil {
+			return
+		}
+		update("")
+		if err != nil && !failing {
+			logf("now playing (%s): %v", src.Name(), err)
+		}
+		failing = err != nil
+		select {
+		case <-ctx.Done():
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+// track joins artist and title, leaving out whichever is missing.
+func track(artist, title string) string {
+	artist, title = strings.TrimSpace(artist), strings.TrimSpace(title)
+	switch {
+	case artist == "":
+		return title
+	case title == "":
+		return artist
+	}
+	return artist + " - " + title
+}
@@ -0,0 +1,64 @@
+package nowplaying
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func shairportFixture(items ...[3]string) string {
+	var b strings.Builder
+	for _, it := range items {
+		fmt.Fprintf(&b, "<item><type>%s</type><code>%s</code><length>%d</length>",
+			hex.EncodeToString([]byte(it[0])), hex.EncodeToString([]byte(it[1])), len(it[2]))
+		if it[2] != "" {
+			fmt.Fprintf(&b, "\n<data encoding=\"base64\">\n%s</data>", base64.StdEncoding.EncodeToString([]byte(it[2])))
+		}
+		b.WriteString("</item>\n")
+	}
+	return b.String()
+}
+
+func TestReadShairport(t *testing.T) {
+	stream := shairportFixture(
+		[3]string{"ssnc", "mdst", ""},
+		[3]string{"core", "asar", "Bonobo"},
+		[3]string{"core", "minm", "Kerala"},
+		[3]string{"core", "asal", "Migration"},
+		[3]string{"ssnc", "mden", ""},
+		[3]string{"ssnc", "pend", ""},
+		[3]string{"core", "minm", "Untitled"},
+		[3]string{"ssnc", "mden", ""},
+	)
+	var got []string
+	err := readShairport(strings.NewReader(stream), func(s string) { got = append(got, s) })
+	if !errors.Is(err, io.EOF) {
+		t.Fatalf("err %v", err)
+	}
+	if want := []string{"Bonobo - Kerala", "", "Untitled"}; !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	for spec, want := range map[string]string{
+		"":                              "mpris",
+		"mpris":                         "mpris",
+		"shairport:/tmp/shairport-meta": "shairport /tmp/shairport-meta",
+	} {
+		src, err := Open(spec)
+		if err != nil || src.Name() != want {
+			t.Errorf("%q: %v, %v", spec, src, err)
+		}
+	}
+	for _, spec := range []string{"shairport:", "spotify"} {
+		if _, err := Open(spec); err == nil {
+			t.Errorf("%q accepted", spec)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package nowplaying
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// shairport reads the metadata pipe of shairport-sync (AirPlay), enabled
+// with `metadata = { enabled = "yes"; pipe_name = "..." }` in its config.
+type shairport struct {
+	path string
+}
+
+func (s shairport) Name() string { return "shairport " + s.path }
+
+func (s shairport) Run(ctx context.Context, update func(string)) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { f.Close() })
+	defer stop()
+	defer f.Close()
+	err = readShairport(f, update)
+	if errors.Is(err, io.EOF) {
+		// the writer went away; Follow reopens the pipe
+		return nil
+	}
+	return err
+}
+
+// shairportItem is one <item> of the metadata stream. Type and code are
+// four-character codes written as hex.
+type shairportItem struct {
+	Type string `xml:"type"`
+	Code string `xml:"code"`
+	Data string `xml:"data"`
+}
+
+// readShairport decodes items from r until it fails, calling update with
+// the track at the end of each metadata bundle and "" when playback ends.
+func readShairport(r io.Reader, update func(string)) error {
+	dec := xml.NewDecoder(r)
+	var artist, title string
+	for {
+		var item shairportItem
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		kind, _ := hex.DecodeString(strings.TrimSpace(item.Type))
+		code, _ := hex.DecodeString(strings.TrimSpace(item.Code))
+		switch string(kind) + "/" + string(code) {
+		case "core/asar":
+			artist = shairportText(item.Data)
+		case "core/minm":
+			title = shairportText(item.Data)
+		case "ssnc/mden":
+			update(track(artist, title))
+		case "ssnc/pend":
+			artist, title = "", ""
+			update("")
+		}
+	}
+}
+
+func shairportText(data string) string {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
@@ -0,0 +1,212 @@
+// Package osc receives Open Sound Control 1.0 messages over UDP, the
+// protocol TouchOSC, VCV Rack and lighting consoles speak. It only reads:
+// messages and (nested) bundles with int, float, string, blob, bool and
+// the 64-bit argument types; bundle time tags are ignored and messages run
+// as they arrive.
+package osc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+)
+
+// maxPacket is the largest datagram read, well over what controllers send.
+const maxPacket = 64 << 10
+
+// Message is one OSC message. Args hold int32, int64, float32, float64,
+// string, []byte or bool values, in the order of the type tags.
+type Message struct {
+	Address string
+	Args    []any
+}
+
+// Float returns argument i as a float64; ints and bools convert.
+func (m Message) Float(i int) (float64, bool) {
+	if i >= len(m.Args) {
+		return 0, false
+	}
+	switch v := m.Args[i].(type) {
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// String returns argument i if it is a string.
+func (m Message) String(i int) (string, bool) {
+	if i >= len(m.Args) {
+		return "", false
+	}
+	s, ok := m.Args[i].(string)
+	return s, ok
+}
+
+// Parse decodes a packet, flattening bundles into their messages in order.
+func Parse(packet []byte) ([]Message, error) {
+	if bytes.HasPrefix(packet, []byte("#bundle\x00")) {
+		return parseBundle(packet)
+	}
+	msg, err := parseMessage(packet)
+	if err != nil {
+		return nil, err
+	}
+	return []Message{msg}, nil
+}
+
+func parseBundle(packet []byte) ([]Message, error) {
+	// "#bundle\0" and the 8 byte time tag
+	if len(packet) < 16 {
+		return nil, errors.New("osc: short bundle")
+	}
+	rest := packet[16:]
+	var msgs []Message
+	for len(rest) > 0 {
+		if len(rest) < 4 {
+			return nil, errors.New("osc: short bundle element")
+		}
+		size := binary.BigEndian.Uint32(rest)
+		if size%4 != 0 || uint64(size) > uint64(len(rest)-4) {
+			return nil, fmt.Errorf("osc: bad bundle element size %d", size)
+		}
+		inner, err := Parse(rest[4 : 4+size])
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, inner...)
+		rest = rest[4+size:]
+	}
+	return msgs, nil
+}
+
+func parseMessage(packet []byte) (Message, error) {
+	address, rest, err := readString(packet)
+	if err != nil {
+		return Message{}, err
+	}
+	if len(address) == 0 || address[0] != '/' {
+		return Message{}, fmt.Errorf("osc: bad address %q", address)
+	}
+	msg := Message{Address: address}
+	if len(rest) == 0 {
+		// very old senders leave out the type tags of an empty message
+		return msg, nil
+	}
+	tags, rest, err := readString(rest)
+	if err != nil {
+		return Message{}, err
+	}
+	if len(tags) == 0 || tags[0] != ',' {
+		return Message{}, fmt.Errorf("osc %s: bad type tags %q", address, tags)
+	}
+	for _, tag := range tags[1:] {
+		var arg any
+		switch tag {
+		case 'i', 'f':
+			if len(rest) < 4 {
+				return Message{}, fmt.Errorf("osc %s: short argument", address)
+			}
+			bits := binary.BigEndian.Uint32(rest)
+			arg, rest = int32(bits), rest[4:]
+			if tag == 'f' {
+				arg = math.Float32frombits(bits)
+			}
+		case 'h', 'd':
+			if len(rest) < 8 {
+				return Message{}, fmt.Errorf("osc %s: short argument", address)
+			}
+			bits := binary.BigEndian.Uint64(rest)
+			arg, rest = int64(bits), rest[8:]
+			if tag == 'd' {
+				arg = math.Float64frombits(bits)
+			}
+		case 's', 'S':
+			arg, rest, err = readString(rest)
+			if err != nil {
+				return Message{}, err
+			}
+		case 'b':
+			if len(rest) < 4 {
+				return Message{}, fmt.Errorf("osc %s: short blob", address)
+			}
+			size := int(binary.BigEndian.Uint32(rest))
+			padded := 4 + (size+3)/4*4
+			if padded > len(rest) {
+				return Message{}, fmt.Errorf("osc %s: short blob", address)
+			}
+			// the packet buffer is reused for the next datagram
+			arg, rest = bytes.Clone(rest[4:4+size]), rest[padded:]
+		case 'T':
+			arg = true
+		case 'F':
+			arg = false
+		case 'N', 'I':
+			// nil and impulse carry no data; an impulse reads as true
+			arg = tag == 'I'
+		default:
+			return Message{}, fmt.Errorf("osc %s: unsupported type tag %q", address, tag)
+		}
+		msg.Args = append(msg.Args, arg)
+	}
+	return msg, nil
+}
+
+// readString reads a NUL terminated string padded to 4 bytes.
+func readString(b []byte) (string, []byte, error) {
+	end := bytes.IndexByte(b, 0)
+	if end < 0 {
+		return "", nil, errors.New("osc: unterminated string")
+	}
+	next := (end + 4) / 4 * 4
+	return string(b[:end]), b[min(next, len(b)):], nil
+}
+
+// Listen receives packets on the UDP address addr and calls fn for every
+// message in them until ctx is cancelled. Packets that don't parse are
+// passed to bad, which may be nil.
+func Listen(ctx context.Context, addr string, fn func(Message), bad func(error)) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buf := make([]byte, maxPacket)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		msgs, err := Parse(buf[:n])
+		if err != nil {
+			if bad != nil {
+				bad(err)
+			}
+			continue
+		}
+		for _, msg := range msgs {
+			fn(msg)
+		}
+	}
+}
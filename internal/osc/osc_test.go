@@ -0,0 +1,130 @@
+package osc
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"net"
+	"testing"
+	"time"
+)
+
+// pad appends s NUL terminated and padded to 4 bytes.
+func pad(b []byte, s string) []byte {
+	b = append(b, s...)
+	return append(b, make([]byte, 4-len(s)%4)...)
+}
+
+func message(address, tags string, args ...[]byte) []byte {
+	b := pad(pad(nil, address), tags)
+	for _, arg := range args {
+		b = append(b, arg...)
+	}
+	return b
+}
+
+func be32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+func TestParseMessage(t *testing.T) {
+	packet := message("/golizer/params/brightness", ",fisT",
+		be32(math.Float32bits(0.75)), be32(3), pad(nil, "fire"))
+	msgs, err := Parse(packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || msgs[0].Address != "/golizer/params/brightness" || len(msgs[0].Args) != 4 {
+		t.Fatalf("parsed %+v", msgs)
+	}
+	msg := msgs[0]
+	if v, ok := msg.Float(0); !ok || v != 0.75 {
+		t.Errorf("float arg %v %v", v, ok)
+	}
+	if v, ok := msg.Float(1); !ok || v != 3 {
+		t.Errorf("int arg %v %v", v, ok)
+	}
+	if s, ok := msg.String(2); !ok || s != "fire" {
+		t.Errorf("string arg %q %v", s, ok)
+	}
+	if v, ok := msg.Float(3); !ok || v != 1 {
+		t.Errorf("bool arg %v %v", v, ok)
+	}
+	if _, ok := msg.Float(4); ok {
+		t.Error("missing arg read")
+	}
+}
+
+func TestParseBundle(t *testing.T) {
+	a := message("/a", ",i", be32(1))
+	b := message("/b", ",")
+	inner := pad(nil, "#bundle")
+	inner = append(inner, make([]byte, 8)...)
+	inner = append(append(inner, be32(uint32(len(b)))...), b...)
+
+	packet := pad(nil, "#bundle")
+	packet = append(packet, make([]byte, 8)...)
+	packet = append(append(packet, be32(uint32(len(a)))...), a...)
+	packet = append(append(packet, be32(uint32(len(inner)))...), inner...)
+
+	msgs, err := Parse(packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 || msgs[0].Address != "/a" || msgs[1].Address != "/b" {
+		t.Fatalf("parsed %+v", msgs)
+	}
+}
+
+func TestParseRejectsBrokenPackets(t *testing.T) {
+	for name, packet := range map[string][]byte{
+		"no address":   pad(nil, "nope"),
+		"unterminated": []byte("/abc"),
+		"short arg":    message("/a", ",f", []byte{1, 2}),
+		"unknown tag":  message("/a", ",x"),
+		"bad element":  append(append(pad(nil, "#bundle"), make([]byte, 8)...), be32(64)...),
+	} {
+		if _, err := Parse(packet); err == nil {
+			t.Errorf("%s parsed", name)
+		}
+	}
+}
+
+func TestListen(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	addr := conn.LocalAddr().String()
+	conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got := make(chan Message, 1)
+	done := make(chan error, 1)
+	go func() { done <- Listen(ctx, addr, func(m Message) { got <- m }, nil) }()
+
+	out, err := net.Dial("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	deadline := time.After(2 * time.Second)
+	for {
+		out.Write(message("/golizer/tap", ","))
+		select {
+		case msg := <-got:
+			if msg.Address != "/golizer/tap" {
+				t.Fatalf("got %+v", msg)
+			}
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("listen: %v", err)
+			}
+			return
+		case err := <-done:
+			t.Fatalf("listen: %v", err)
+		case <-deadline:
+			t.Fatal("no message")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
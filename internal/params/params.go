@@ -1,6 +1,7 @@
 package params
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/guidoenr/golizer/internal/analyzer"
@@ -24,8 +25,11 @@ type Parameters struct {
 	VignetteSoftness float64
 	GlyphSharpness   float64
 	BeatSensitivity  float64
+	SubInfluence     float64
 	BassInfluence    float64
+	LowMidInfluence  float64
 	MidInfluence     float64
+	HighMidInfluence float64
 	TrebleInfluence  float64
 	BeatDistortion   float64
 	BeatZoom         float64
@@ -55,8 +59,11 @@ func Defaults() Parameters {
 		VignetteSoftness: 0.55,
 		GlyphSharpness:   1.0,
 		BeatSensitivity:  1.2,
+		SubInfluence:     0.6,
 		BassInfluence:    0.85,
+		LowMidInfluence:  0.3,
 		MidInfluence:     0.25,
+		HighMidInfluence: 0.2,
 		TrebleInfluence:  0.15,
 		BeatDistortion:   0.0,
 		BeatZoom:         0.0,
@@ -67,6 +74,50 @@ func Defaults() Parameters {
 	}
 }
 
+// Range is the span a knob may be set to from outside, bounds included.
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// ranges are the spans the web panel's sliders offer.
+var ranges = map[string]Range{
+	"Frequency":        {1, 20},
+	"Amplitude":        {0, 3},
+	"Speed":            {0, 1},
+	"Brightness":       {0, 3},
+	"Contrast":         {0, 2},
+	"Saturation":       {0, 2},
+	"BeatSensitivity":  {0.5, 3},
+	"SubInfluence":     {0, 2},
+	"BassInfluence":    {0, 2},
+	"LowMidInfluence":  {0, 2},
+	"MidInfluence":     {0, 2},
+	"HighMidInfluence": {0, 2},
+	"TrebleInfluence":  {0, 2},
+}
+
+// Ranges returns the knobs remote control may set, keyed by field name.
+func Ranges() map[string]Range {
+	out := make(map[string]Range, len(ranges))
+	for name, r := range ranges {
+		out[name] = r
+	}
+	return out
+}
+
+// Check reports whether v is in the range of the knob name.
+func Check(name string, v float64) error {
+	r, ok := ranges[name]
+	if !ok {
+		return fmt.Errorf("unknown param %q", name)
+	}
+	if math.IsNaN(v) || v < r.Min || v > r.Max {
+		return fmt.Errorf("%s must be between %g and %g", name, r.Min, r.Max)
+	}
+	return nil
+}
+
 // UpdateTime advances the internal timer based on frame delta.
 func (p *Parameters) UpdateTime(delta float64) {
 	p.Time += delta * p.Speed
@@ -74,7 +125,7 @@ func (p *Parameters) UpdateTime(delta float64) {
 
 // ApplyFeatures updates parameters based on analyzed audio features.
 func (p *Parameters) ApplyFeatures(feat analyzer.Features, delta float64) {
-	if feat == (analyzer.Features{}) {
+	if feat.IsSilent() {
 		p.applySilenceDecay(delta)
 		return
 	}
@@ -88,23 +139,24 @@ func (p *Parameters) ApplyFeatures(feat analyzer.Features, delta float64) {
 	p.DistortAmplitude = lerp(p.DistortAmplitude, 0.4+feat.Bass*0.9, 0.82)
 	p.NoiseScale = lerp(p.NoiseScale, 0.004+feat.Bass*0.003, 0.7)
 
-	p.Frequency = lerp(p.Frequency, 6.0*(1.0+feat.Bass*0.6+feat.Mid*p.MidInfluence), 0.72)
+	p.Frequency = lerp(p.Frequency, 6.0*(1.0+feat.Bass*0.6+feat.Mid*p.MidInfluence+feat.LowMid*p.LowMidInfluence*0.4), 0.72)
+
+	// sub-bass pumps the zoom so a rumble reads differently from a kick
+	p.Scale = lerp(p.Scale, 1.0-clamp(feat.Sub*p.SubInfluence*0.25, 0, 0.4), 0.5)
 
 	baseSpeed := 0.08 + energy*0.8
 	trebleBoost := 1.0 + feat.Treble*p.TrebleInfluence
 	targetSpeed := baseSpeed * trebleBoost
 	p.Speed = lerp(p.Speed, targetSpeed, 0.72)
 
-	p.ColorShift = math.Mod(p.ColorShift+feat.Bass*0.3+feat.Treble*0.15, 2*math.Pi)
+	// harmonic content (pads, chords) keeps the hue drifting between hits
+	p.ColorShift = math.Mod(p.ColorShift+feat.Bass*0.3+feat.Treble*0.15+feat.HighMid*p.HighMidInfluence*0.2+feat.Harmonic*0.08, 2*math.Pi)
 	p.Gamma = lerp(p.Gamma, 0.9+feat.Bass*0.3, 0.3)
-	p.Vignette = lerp(p.Vignette, 0.25+feat.BeatStrength*0.15, 0.2)
-	p.GlyphSharpness = lerp(p.GlyphSharpness, 0.9+feat.BeatStrength*0.5, 0.35)
+	p.Vignette = lerp(p.Vignette, 0.25+feat.BeatStrength*0.15+feat.Sub*p.SubInfluence*0.1, 0.2)
+	p.GlyphSharpness = lerp(p.GlyphSharpness, 0.9+feat.BeatStrength*0.5+feat.HighMid*p.HighMidInfluence*0.3, 0.35)
 
 	if feat.IsDrop {
-		p.LastEffectTime = p.Time
-		p.BeatDistortion = 1.5
-		p.BeatZoom = 1.2
-		p.DistortAmplitude = 1.0
+		p.Drop()
 	} else {
 		threshold := 0.16 / maxFloat(0.1, p.BeatSensitivity)
 		if feat.BeatStrength > threshold {
@@ -117,7 +169,9 @@ func (p *Parameters) ApplyFeatures(feat analyzer.Features, delta float64) {
 	// fast attack, slow decay for brightness
 	bassBrightness := feat.Bass * 1.2
 	beatBoost := feat.BeatStrength * 0.8
-	targetBrightness := clamp(feat.Overall*0.8+bassBrightness+beatBoost, 0, 2.5)
+	// percussive energy flashes on any drum hit, not just kicks
+	percussiveFlash := feat.Percussive * 0.5
+	targetBrightness := clamp(feat.Overall*0.8+bassBrightness+beatBoost+percussiveFlash, 0, 2.5)
 	if targetBrightness > p.Brightness {
 		p.Brightness = lerp(p.Brightness, targetBrightness, 0.92)
 	} else {
@@ -138,6 +192,24 @@ func (p *Parameters) ApplyFeatures(feat analyzer.Features, delta float64) {
 	}
 }
 
+// Pulse fires a beat accent (zoom + distortion kick) outside the audio path,
+// e.g. when the beat scheduler lands a predicted beat.
+func (p *Parameters) Pulse(strength float64) {
+	strength = clamp(strength, 0, 1.5)
+	p.LastEffectTime = p.Time
+	p.BeatDistortion = maxFloat(p.BeatDistortion, strength)
+	p.BeatZoom = maxFloat(p.BeatZoom, 0.8*strength)
+}
+
+// Drop hits the params like a detected drop: the strongest zoom and
+// distortion kick.
+func (p *Parameters) Drop() {
+	p.LastEffectTime = p.Time
+	p.BeatDistortion = 1.5
+	p.BeatZoom = 1.2
+	p.DistortAmplitude = 1.0
+}
+
 func (p *Parameters) applySilenceDecay(delta float64) {
 	// slower decay so visuals last longer
 	fastDecay := math.Pow(0.85, delta*60)
@@ -157,6 +229,43 @@ func (p *Parameters) applySilenceDecay(delta float64) {
 	p.NoiseScale = lerp(p.NoiseScale, 0.006, 0.3)
 	p.GlyphSharpness = lerp(p.GlyphSharpness, 1.0, 0.25)
 	p.Vignette = lerp(p.Vignette, 0.25, 0.3)
+	p.Scale = lerp(p.Scale, 1.0, 0.3)
+}
+
+// Lerp blends two parameter states, moving factor of the way from a to b.
+// Continuous knobs are interpolated, ColorShift follows the shortest arc and
+// discrete fields (pattern, color mode, timers) are taken from b.
+func Lerp(a, b Parameters, factor float64) Parameters {
+	factor = clamp(factor, 0, 1)
+	out := b
+	out.Frequency = lerp(a.Frequency, b.Frequency, factor)
+	out.Amplitude = lerp(a.Amplitude, b.Amplitude, factor)
+	out.Speed = lerp(a.Speed, b.Speed, factor)
+	out.Scale = lerp(a.Scale, b.Scale, factor)
+	out.ColorShift = lerpAngle(a.ColorShift, b.ColorShift, factor)
+	out.Brightness = lerp(a.Brightness, b.Brightness, factor)
+	out.Contrast = lerp(a.Contrast, b.Contrast, factor)
+	out.Saturation = lerp(a.Saturation, b.Saturation, factor)
+	out.Gamma = lerp(a.Gamma, b.Gamma, factor)
+	out.Vignette = lerp(a.Vignette, b.Vignette, factor)
+	out.VignetteSoftness = lerp(a.VignetteSoftness, b.VignetteSoftness, factor)
+	out.GlyphSharpness = lerp(a.GlyphSharpness, b.GlyphSharpness, factor)
+	out.BeatDistortion = lerp(a.BeatDistortion, b.BeatDistortion, factor)
+	out.BeatZoom = lerp(a.BeatZoom, b.BeatZoom, factor)
+	out.DistortAmplitude = lerp(a.DistortAmplitude, b.DistortAmplitude, factor)
+	out.NoiseStrength = lerp(a.NoiseStrength, b.NoiseStrength, factor)
+	out.NoiseScale = lerp(a.NoiseScale, b.NoiseScale, factor)
+	return out
+}
+
+func lerpAngle(current, target, factor float64) float64 {
+	diff := math.Mod(target-current, 2*math.Pi)
+	if diff > math.Pi {
+		diff -= 2 * math.Pi
+	} else if diff < -math.Pi {
+		diff += 2 * math.Pi
+	}
+	return math.Mod(current+diff*factor+2*math.Pi, 2*math.Pi)
 }
 
 func lerp(current, target, factor float64) float64 {
@@ -1,6 +1,7 @@
 package params
 
 import (
+	"math"
 	"testing"
 
 	"github.com/guidoenr/golizer/internal/analyzer"
@@ -19,3 +20,49 @@ func TestUpdateTimeAdvances(t *testing.T) {
 		t.Fatalf("expected time to advance, got %f", p.Time)
 	}
 }
+
+func TestLerpBlendsContinuousFields(t *testing.T) {
+	a := Defaults()
+	b := Defaults()
+	a.Brightness = 0
+	b.Brightness = 1
+	b.Pattern = "spiral"
+	b.Time = 3
+
+	got := Lerp(a, b, 0.5)
+	if got.Brightness != 0.5 {
+		t.Fatalf("brightness=%f want=0.5", got.Brightness)
+	}
+	if got.Pattern != "spiral" || got.Time != 3 {
+		t.Fatalf("expected discrete fields from target, got pattern=%q time=%f", got.Pattern, got.Time)
+	}
+}
+
+func TestLerpColorShiftWrapsShortestArc(t *testing.T) {
+	a := Defaults()
+	b := Defaults()
+	a.ColorShift = 2*math.Pi - 0.1
+	b.ColorShift = 0.1
+
+	got := Lerp(a, b, 0.5)
+	if got.ColorShift > 0.01 && got.ColorShift < 2*math.Pi-0.01 {
+		t.Fatalf("expected shift near wrap point, got %f", got.ColorShift)
+	}
+}
+
+func TestCheckRanges(t *testing.T) {
+	for name, r := range Ranges() {
+		if err := Check(name, r.Min); err != nil {
+			t.Fatalf("%s: min rejected: %v", name, err)
+		}
+		if err := Check(name, r.Max+1); err == nil {
+			t.Fatalf("%s: %g accepted", name, r.Max+1)
+		}
+	}
+	if err := Check("Speed", math.NaN()); err == nil {
+		t.Fatal("NaN accepted")
+	}
+	if err := Check("Time", 1); err == nil {
+		t.Fatal("unknown param accepted")
+	}
+}
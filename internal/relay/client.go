@@ -0,0 +1,266 @@
+package relay
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+// Client follows a relay server, reconnecting with backoff when the source
+// goes away. While disconnected it reports silence.
+type Client struct {
+	addr  string
+	mode  string
+	token string
+	log   *log.Logger
+
+	mu         sync.Mutex
+	sampleRate float64
+	feat       analyzer.Features
+	fresh      bool
+	last       analyzer.Features
+	samples    []float32
+	scene      *Scene
+}
+
+// Dial connects to the relay server at addr in mode and keeps following it
+// until ctx is done; token is the server's, empty for none. The first
+// connection is made before Dial returns so pcm followers know the
+// source's sample rate.
+func Dial(ctx context.Context, addr, mode, token string, logger *log.Logger) (*Client, error) {
+	if mode != ModeFeatures && mode != ModePCM {
+		return nil, fmt.Errorf("unknown relay mode %q", mode)
+	}
+	if strings.ContainsAny(token, " \r\n") {
+		return nil, fmt.Errorf("relay token can't hold spaces or line breaks")
+	}
+	c := &Client{addr: addr, mode: mode, token: token, log: logger}
+	conn, r, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	logger.Printf("relay: following %s (%s, %.0f Hz)", addr, mode, c.SampleRate())
+	go c.run(ctx, conn, r)
+	return c, nil
+}
+
+func (c *Client) connect() (net.Conn, *bufio.Reader, error) {
+	conn, err := net.DialTimeout("tcp", c.addr, dialTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+	hello := c.mode
+	if c.token != "" {
+		hello += " " + c.token
+	}
+	if _, err := fmt.Fprintf(conn, "%s\n", hello); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	r := bufio.NewReader(conn)
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	var hdr header
+	if err := json.Unmarshal(line, &hdr); err != nil || hdr.Mode != c.mode {
+		conn.Close()
+		return nil, nil, fmt.Errorf("relay handshake: %s", line)
+	}
+	conn.SetDeadline(time.Time{})
+	c.mu.Lock()
+	if c.sampleRate != 0 && c.sampleRate != hdr.SampleRate {
+		c.log.Printf("relay: source sample rate changed to %.0f Hz", hdr.SampleRate)
+	}
+	c.sampleRate = hdr.SampleRate
+	c.mu.Unlock()
+	return conn, r, nil
+}
+
+func (c *Client) run(ctx context.Context, conn net.Conn, r *bufio.Reader) {
+	backoff := time.Second
+	for {
+		stop := context.AfterFunc(ctx, func() { conn.Close() })
+		err := c.read(conn, r)
+		stop()
+		conn.Close()
+		c.reset()
+		if ctx.Err() != nil {
+			return
+		}
+		c.log.Printf("relay: lost %s (%v), reconnecting", c.addr, err)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			conn, r, err = c.connect()
+			if err == nil {
+				backoff = time.Second
+				c.log.Printf("relay: reconnected to %s", c.addr)
+				break
+			}
+			backoff = min(backoff*2, 30*time.Second)
+		}
+	}
+}
+
+func (c *Client) read(conn net.Conn, r *bufio.Reader) error {
+	if c.mode == ModeFeatures {
+		for {
+			line, err := r.ReadBytes('\n')
+			if err != nil {
+				return err
+			}
+			if line[0] == '!' {
+				if err := c.control(conn, line[1:]); err != nil {
+					return err
+				}
+				continue
+			}
+			var feat analyzer.Features
+			if err := json.Unmarshal(line, &feat); err != nil {
+				return err
+			}
+			c.pushFeatures(feat)
+		}
+	}
+	var size [4]byte
+	var buf []byte
+	for {
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			return err
+		}
+		if binary.LittleEndian.Uint32(size[:]) == controlMarker {
+			line, err := r.ReadBytes('\n')
+			if err != nil {
+				return err
+			}
+			if err := c.control(conn, line); err != nil {
+				return err
+			}
+			continue
+		}
+		n := int(binary.LittleEndian.Uint32(size[:]))
+		if n > maxFrameSamples {
+			return fmt.Errorf("pcm frame of %d samples", n)
+		}
+		if cap(buf) < 4*n {
+			buf = make([]byte, 4*n)
+		}
+		buf = buf[:4*n]
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return err
+		}
+		c.mu.Lock()
+		c.samples = c.samples[:0]
+		for i := 0; i < n; i++ {
+			c.samples = append(c.samples, math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:])))
+		}
+		c.mu.Unlock()
+	}
+}
+
+// control answers a ping or keeps a scene change for the render loop.
+func (c *Client) control(conn net.Conn, line []byte) error {
+	var msg control
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return err
+	}
+	if msg.Ping != 0 {
+		if _, err := fmt.Fprintf(conn, "pong %d\n", msg.Ping); err != nil {
+			return err
+		}
+	}
+	if msg.Scene != nil {
+		c.mu.Lock()
+		c.scene = msg.Scene
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// pushFeatures keeps the newest frame. One-frame events of a frame the
+// render loop never saw carry over so no beat gets lost.
+func (c *Client) pushFeatures(feat analyzer.Features) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fresh {
+		feat.Onset = feat.Onset || c.feat.Onset
+		feat.IsDrop = feat.IsDrop || c.feat.IsDrop
+		feat.BeatStrength = max(feat.BeatStrength, c.feat.BeatStrength)
+	}
+	c.feat = feat
+	c.fresh = true
+}
+
+// reset drops to silence after a disconnect.
+func (c *Client) reset() {
+	c.mu.Lock()
+	c.feat, c.last, c.fresh = analyzer.Features{}, analyzer.Features{}, false
+	clear(c.samples)
+	c.mu.Unlock()
+}
+
+// Features returns the newest relayed features. Calls between two relayed
+// frames repeat the last value without its one-frame events.
+func (c *Client) Features() analyzer.Features {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fresh {
+		c.fresh = false
+		c.last = c.feat
+		return c.feat
+	}
+	feat := c.last
+	feat.Onset = false
+	feat.IsDrop = false
+	return feat
+}
+
+// Scene returns the source's newest scene change once, and false until
+// the next one.
+func (c *Client) Scene() (Scene, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.scene == nil {
+		return Scene{}, false
+	}
+	scene := *c.scene
+	c.scene = nil
+	return scene, true
+}
+
+// SamplesInto copies the newest relayed pcm window into dst.
+func (c *Client) SamplesInto(dst []float32) []float32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append(dst[:0], c.samples...)
+}
+
+// SampleRate returns the source's sample rate.
+func (c *Client) SampleRate() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sampleRate
+}
+
+// Mode returns ModeFeatures or ModePCM.
+func (c *Client) Mode() string {
+	return c.mode
+}
@@ -0,0 +1,48 @@
+// Package relay shares one instance's audio with others over TCP, so a
+// single machine needs the sound card while many render. The source runs a
+// Server; followers connect with a Client and ask for either the analysed
+// Features (tiny, every follower shows the same beats) or the raw PCM
+// window (each follower runs its own analysis, e.g. with another --analysis
+// mode).
+//
+// Wire format: the client sends its mode on one line ("features" or "pcm"),
+// followed by a space and the token when the server has one. The server
+// answers with a JSON header line, then streams JSON Features lines or,
+// for pcm, frames of a little-endian uint32 sample count followed by that
+// many float32 samples. Between them come control lines, JSON after a '!'
+// or, for pcm, after a count of 0xffffffff: pings, which the client
+// answers with "pong <ping>\n" so the server knows each follower's round
+// trip, and scene changes, which nearer followers get later so that every
+// follower has them at the same time.
+package relay
+
+import (
+	"time"
+)
+
+// Relay modes.
+const (
+	ModeFeatures = "features"
+	ModePCM      = "pcm"
+)
+
+// ModeNames lists the accepted modes.
+func ModeNames() []string {
+	return []string{ModeFeatures, ModePCM}
+}
+
+// header is the server's first line to every client.
+type header struct {
+	Mode       string  `json:"mode"`
+	SampleRate float64 `json:"sampleRate"`
+}
+
+const (
+	// dialTimeout bounds connecting and the handshake.
+	dialTimeout = 5 * time.Second
+	// maxFrameSamples rejects corrupt pcm frames before allocating.
+	maxFrameSamples = 1 << 16
+	// clientQueue is how many messages a slow follower may fall behind
+	// before it is disconnected.
+	clientQueue = 8
+)
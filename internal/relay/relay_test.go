@@ -0,0 +1,245 @@
+package relay
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestRelayStreamsFeaturesAndPCM(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	server, err := Listen("127.0.0.1:0", 48000, "s3cret", logger)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	features, err := Dial(ctx, server.Addr().String(), ModeFeatures, "s3cret", logger)
+	if err != nil {
+		t.Fatalf("dial features: %v", err)
+	}
+	pcm, err := Dial(ctx, server.Addr().String(), ModePCM, "s3cret", logger)
+	if err != nil {
+		t.Fatalf("dial pcm: %v", err)
+	}
+	if pcm.SampleRate() != 48000 {
+		t.Fatalf("sample rate %.0f, want 48000", pcm.SampleRate())
+	}
+
+	waitFor(t, func() bool {
+		// followers register right after the handshake
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.clients) == 2
+	})
+
+	server.PublishFeatures(analyzer.Features{Bass: 0.5, Onset: true})
+	server.PublishFeatures(analyzer.Features{Bass: 0.7})
+	server.PublishSamples([]float32{0.25, -0.5, 1})
+
+	var got analyzer.Features
+	waitFor(t, func() bool {
+		features.mu.Lock()
+		defer features.mu.Unlock()
+		return features.feat.Bass == 0.7
+	})
+	got = features.Features()
+	if !got.Onset {
+		t.Error("onset of the skipped frame was lost")
+	}
+	if again := features.Features(); again.Onset || again.Bass != 0.7 {
+		t.Errorf("repeated frame = %+v, want bass 0.7 without onset", again)
+	}
+
+	var samples []float32
+	waitFor(t, func() bool {
+		samples = pcm.SamplesInto(samples)
+		return len(samples) == 3
+	})
+	if samples[0] != 0.25 || samples[1] != -0.5 || samples[2] != 1 {
+		t.Errorf("samples = %v", samples)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRelayDropsSlowFollower(t *testing.T) {
+	server, err := Listen("127.0.0.1:0", 48000, "", log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer server.Close()
+
+	// a follower that shakes hands and then never reads
+	conn, err := net.Dial("tcp", server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "%s\n", ModePCM)
+	if _, err := bufio.NewReader(conn).ReadBytes('\n'); err != nil {
+		t.Fatalf("handshake: %v", err)
+	}
+	waitFor(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.clients) == 1
+	})
+
+	// the socket buffers take a few MB before the queue starts to fill
+	window := make([]float32, 1<<14)
+	for i := 0; ; i++ {
+		if i == 2000 {
+			t.Fatal("slow follower still connected")
+		}
+		server.PublishSamples(window)
+		server.mu.Lock()
+		n := len(server.clients)
+		server.mu.Unlock()
+		if n == 0 {
+			break
+		}
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.Copy(io.Discard, conn); err != nil {
+		t.Errorf("connection not closed: %v", err)
+	}
+}
+
+func TestRelayToken(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	server, err := Listen("127.0.0.1:0", 48000, "s3cret", logger)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, token := range []string{"", "wrong", "s3cret2"} {
+		if _, err := Dial(ctx, server.Addr().String(), ModeFeatures, token, logger); err == nil {
+			t.Errorf("token %q accepted", token)
+		}
+	}
+	if _, err := Dial(ctx, server.Addr().String(), ModeFeatures, "s3 cret", logger); err == nil {
+		t.Error("token with a space accepted")
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.clients) != 0 {
+		t.Errorf("%d followers without the token", len(server.clients))
+	}
+}
+
+func TestRelayMeasuresRoundTrips(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	server, err := Listen("127.0.0.1:0", 48000, "", logger)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, mode := range ModeNames() {
+		if _, err := Dial(ctx, server.Addr().String(), mode, "", logger); err != nil {
+			t.Fatalf("dial %s: %v", mode, err)
+		}
+	}
+	waitFor(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.clients) == 2
+	})
+	server.sendPings()
+	waitFor(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		for c := range server.clients {
+			if c.rtt <= 0 || c.rtt > time.Second {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestRelaySceneReachesFollowersTogether(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	server, err := Listen("127.0.0.1:0", 48000, "", logger)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	near, err := Dial(ctx, server.Addr().String(), ModeFeatures, "", logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.clients) == 1
+	})
+	far, err := Dial(ctx, server.Addr().String(), ModePCM, "", logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.clients) == 2
+	})
+	// pretend the pcm follower is across a slow link
+	server.mu.Lock()
+	for c := range server.clients {
+		if c.mode == ModePCM {
+			c.rtt = 300 * time.Millisecond
+		} else {
+			c.rtt = 20 * time.Millisecond
+		}
+	}
+	server.mu.Unlock()
+
+	scene := Scene{Palette: "block", Pattern: "tunnel", ColorMode: "fire"}
+	start := time.Now()
+	if lead := server.PublishScene(scene); lead != 150*time.Millisecond {
+		t.Errorf("lead %v, want 150ms", lead)
+	}
+	arrived := map[*Client]time.Duration{}
+	waitFor(t, func() bool {
+		for _, c := range []*Client{near, far} {
+			if got, ok := c.Scene(); ok {
+				if got != scene {
+					t.Fatalf("scene %+v, want %+v", got, scene)
+				}
+				arrived[c] = time.Since(start)
+			}
+		}
+		return len(arrived) == 2
+	})
+	// the near one is held back by the difference of the one-way trips
+	if arrived[near] < 140*time.Millisecond || arrived[far] > 100*time.Millisecond {
+		t.Errorf("near follower got it after %v, far after %v", arrived[near], arrived[far])
+	}
+	if _, ok := near.Scene(); ok {
+		t.Error("scene reported twice")
+	}
+}
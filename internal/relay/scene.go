@@ -0,0 +1,133 @@
+package relay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// pingInterval is how often the server measures each follower's round
+	// trip.
+	pingInterval = time.Second
+	// maxSceneLead bounds how long a scene change waits for the slowest
+	// follower; one further away changes late rather than holding every
+	// screen back.
+	maxSceneLead = 500 * time.Millisecond
+	// controlMarker takes the place of a pcm frame's sample count before a
+	// control line. It is far above maxFrameSamples, so no frame has it.
+	controlMarker = 0xffffffff
+)
+
+// Scene is the look the source switched to.
+type Scene struct {
+	Palette   string `json:"palette"`
+	Pattern   string `json:"pattern"`
+	ColorMode string `json:"colorMode"`
+}
+
+// control is a message between the data frames: a ping the follower
+// answers with "pong <ping>\n", or a scene change to show right away.
+type control struct {
+	Ping  int64  `json:"ping,omitempty"`
+	Scene *Scene `json:"scene,omitempty"`
+}
+
+// controlFrame encodes msg for a follower in mode: after a '!' in the
+// features stream, after controlMarker in the pcm stream.
+func controlFrame(mode string, msg control) []byte {
+	data, _ := json.Marshal(msg)
+	var frame []byte
+	if mode == ModePCM {
+		frame = binary.LittleEndian.AppendUint32(frame, controlMarker)
+	} else {
+		frame = append(frame, '!')
+	}
+	frame = append(frame, data...)
+	return append(frame, '\n')
+}
+
+// PublishScene sends a scene change to every follower, timed so that all
+// of them show it at once: each gets it as much later as it is closer than
+// the slowest, by half its measured round trip. The returned lead is when
+// the slowest has it, for the source to change its own screen then.
+func (s *Server) PublishScene(scene Scene) time.Duration {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lead time.Duration
+	for c := range s.clients {
+		lead = max(lead, min(c.rtt/2, maxSceneLead))
+	}
+	for c := range s.clients {
+		msg := controlFrame(c.mode, control{Scene: &scene})
+		delay := lead - min(c.rtt/2, maxSceneLead)
+		if delay <= 0 {
+			s.sendLocked(c, msg)
+			continue
+		}
+		time.AfterFunc(delay, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if _, ok := s.clients[c]; ok {
+				s.sendLocked(c, msg)
+			}
+		})
+	}
+	return lead
+}
+
+// ping queues a ping for every follower until the server is closed. The
+// pings wait in the same queue as the frames, so a follower's round trip
+// includes what it is behind.
+func (s *Server) ping() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.sendPings()
+		}
+	}
+}
+
+func (s *Server) sendPings() {
+	sent := time.Since(s.started).Nanoseconds()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		s.sendLocked(c, controlFrame(c.mode, control{Ping: sent}))
+	}
+}
+
+// readPongs reads c's answers to the pings until the connection ends.
+func (s *Server) readPongs(c *serverClient, r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		sent, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(line), "pong "), 10, 64)
+		if err != nil || sent <= 0 {
+			continue
+		}
+		rtt := time.Since(s.started) - time.Duration(sent)
+		if rtt < 0 {
+			continue
+		}
+		s.mu.Lock()
+		if c.rtt == 0 {
+			c.rtt = rtt
+		} else {
+			c.rtt = (3*c.rtt + rtt) / 4
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,213 @@
+package relay
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+// Server streams the source's audio to connected followers.
+type Server struct {
+	ln         net.Listener
+	log        *log.Logger
+	sampleRate float64
+	token      string
+	started    time.Time
+	done       chan struct{}
+
+	mu      sync.Mutex
+	clients map[*serverClient]struct{}
+}
+
+type serverClient struct {
+	conn net.Conn
+	mode string
+	out  chan []byte
+	// rtt is the smoothed round trip of the pings, 0 until the first
+	// pong; guarded by the server's mu.
+	rtt time.Duration
+}
+
+// Listen starts a relay server on addr (e.g. ":9091"). sampleRate is sent
+// to pcm followers so their analyzers match the source. With a token,
+// followers have to send the same one in their handshake.
+func Listen(addr string, sampleRate float64, token string, logger *log.Logger) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		ln:         ln,
+		log:        logger,
+		sampleRate: sampleRate,
+		token:      token,
+		started:    time.Now(),
+		done:       make(chan struct{}),
+		clients:    map[*serverClient]struct{}{},
+	}
+	go s.accept()
+	go s.ping()
+	logger.Printf("audio relay listening on %s", ln.Addr())
+	return s, nil
+}
+
+// Addr returns the listening address.
+func (s *Server) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+func (s *Server) accept() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handshake(conn)
+	}
+}
+
+func (s *Server) handshake(conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(dialTimeout))
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return
+	}
+	mode, token, _ := strings.Cut(strings.TrimSpace(line), " ")
+	if s.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		s.log.Printf("relay: follower %s sent a wrong token", conn.RemoteAddr())
+		fmt.Fprintf(conn, "unauthorized\n")
+		conn.Close()
+		return
+	}
+	if mode != ModeFeatures && mode != ModePCM {
+		fmt.Fprintf(conn, "unknown mode %q\n", mode)
+		conn.Close()
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	hdr, _ := json.Marshal(header{Mode: mode, SampleRate: s.sampleRate})
+	if _, err := conn.Write(append(hdr, '\n')); err != nil {
+		conn.Close()
+		return
+	}
+
+	c := &serverClient{conn: conn, mode: mode, out: make(chan []byte, clientQueue)}
+	s.mu.Lock()
+	s.clients[c] = struct{}{}
+	s.mu.Unlock()
+	s.log.Printf("relay: %s follower %s connected", mode, conn.RemoteAddr())
+	go s.readPongs(c, r)
+
+	for msg := range c.out {
+		if _, err := conn.Write(msg); err != nil {
+			break
+		}
+	}
+	s.drop(c)
+	s.log.Printf("relay: follower %s gone", conn.RemoteAddr())
+}
+
+func (s *Server) drop(c *serverClient) {
+	s.mu.Lock()
+	s.removeLocked(c)
+	s.mu.Unlock()
+	c.conn.Close()
+}
+
+// removeLocked forgets c and ends its writer; s.mu must be held.
+func (s *Server) removeLocked(c *serverClient) {
+	if _, ok := s.clients[c]; ok {
+		delete(s.clients, c)
+		close(c.out)
+	}
+}
+
+// PublishFeatures sends the frame's features to feature followers.
+func (s *Server) PublishFeatures(feat analyzer.Features) {
+	if s == nil {
+		return
+	}
+	msg, err := json.Marshal(feat)
+	if err != nil {
+		return
+	}
+	s.broadcast(ModeFeatures, append(msg, '\n'))
+}
+
+// PublishSamples sends the analysis window to pcm followers.
+func (s *Server) PublishSamples(samples []float32) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	waiting := false
+	for c := range s.clients {
+		waiting = waiting || c.mode == ModePCM
+	}
+	s.mu.Unlock()
+	if !waiting {
+		return
+	}
+	msg := make([]byte, 4+4*len(samples))
+	binary.LittleEndian.PutUint32(msg, uint32(len(samples)))
+	for i, v := range samples {
+		binary.LittleEndian.PutUint32(msg[4+4*i:], math.Float32bits(v))
+	}
+	s.broadcast(ModePCM, msg)
+}
+
+// broadcast queues msg for every follower in mode. A follower whose queue
+// is full is disconnected rather than stalling the analysis loop: it would
+// only show ever older frames, and it reconnects to the current one.
+func (s *Server) broadcast(mode string, msg []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.clients {
+		if c.mode == mode {
+			s.sendLocked(c, msg)
+		}
+	}
+}
+
+// sendLocked queues msg for c, disconnecting it when its queue is full;
+// s.mu must be held.
+func (s *Server) sendLocked(c *serverClient, msg []byte) {
+	select {
+	case c.out <- msg:
+	default:
+		s.log.Printf("relay: follower %s fell %d messages behind, dropping it", c.conn.RemoteAddr(), clientQueue)
+		s.removeLocked(c)
+		c.conn.Close()
+	}
+}
+
+// Close stops listening and disconnects every follower.
+func (s *Server) Close() error {
+	if s == nil {
+		return nil
+	}
+	err := s.ln.Close()
+	s.mu.Lock()
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	for c := range s.clients {
+		s.removeLocked(c)
+	}
+	s.mu.Unlock()
+	return err
+}
@@ -0,0 +1,86 @@
+package render
+
+import (
+	"math"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+const (
+	// barsMinSlot is the fewest cells a bar takes, gap included.
+	barsMinSlot = 3
+	// barsFall is how fast a bar drops, in screen heights per second.
+	barsFall = 1.8
+	// barsCapHold is how long a peak cap stays up before it falls.
+	barsCapHold = 0.4
+	// barsCapFall is how fast a cap drops once the hold is over.
+	barsCapFall = 0.7
+)
+
+// barsPattern is a log-frequency bar equalizer with peak-hold caps. Each
+// bar covers neighbouring Spectrum bands and is shaded from dim at the
+// bottom to bright at the top.
+type barsPattern struct {
+	levels []float64
+	caps   []float64
+	holds  []float64
+
+	gridW, gridH int
+	slot         int // cells per bar, the last one is the gap
+	offset       int // empty cells left of the first bar
+}
+
+func (b *barsPattern) prepare(feat analyzer.Features, dt float64, gridW, gridH int) {
+	b.gridW, b.gridH = gridW, gridH
+	n := min(max(gridW/barsMinSlot, 1), analyzer.SpectrumBands)
+	b.slot = max(gridW/n, 1)
+	b.offset = (gridW - n*b.slot) / 2
+	if len(b.levels) != n {
+		b.levels = make([]float64, n)
+		b.caps = make([]float64, n)
+		b.holds = make([]float64, n)
+	}
+
+	for i := range b.levels {
+		lo := i * analyzer.SpectrumBands / n
+		hi := max((i+1)*analyzer.SpectrumBands/n, lo+1)
+		target := 0.0
+		for _, v := range feat.Spectrum[lo:hi] {
+			target = math.Max(target, v)
+		}
+		b.levels[i] = math.Max(target, b.levels[i]-barsFall*dt)
+
+		if b.levels[i] >= b.caps[i] {
+			b.caps[i] = b.levels[i]
+			b.holds[i] = barsCapHold
+			continue
+		}
+		// the cap falls for the part of dt past its hold
+		fall := dt - b.holds[i]
+		b.holds[i] = math.Max(b.holds[i]-dt, 0)
+		if fall > 0 {
+			b.caps[i] = math.Max(b.levels[i], b.caps[i]-barsCapFall*fall)
+		}
+	}
+}
+
+func (b *barsPattern) eval(x, y float64) float64 {
+	if b.gridW == 0 || b.gridH == 0 {
+		return -1
+	}
+	col := gridCell(x, b.gridW) - b.offset
+	if col < 0 || col >= len(b.levels)*b.slot || (b.slot > 1 && col%b.slot == b.slot-1) {
+		return -1
+	}
+	bar := col / b.slot
+	row := b.gridH - 1 - gridCell(y, b.gridH)
+
+	capRow := min(int(b.caps[bar]*float64(b.gridH)), b.gridH-1)
+	if row == capRow && b.caps[bar] > 0.02 {
+		return 1
+	}
+	if row >= int(b.levels[bar]*float64(b.gridH)+0.5) {
+		return -1
+	}
+	return 0.1 + 0.8*float64(row)/float64(b.gridH)
+}
@@ -0,0 +1,51 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestBarsPeakCapsHoldThenFall(t *testing.T) {
+	var b barsPattern
+	var loud analyzer.Features
+	for k := range loud.Spectrum {
+		loud.Spectrum[k] = 0.8
+	}
+	b.prepare(loud, 1.0/60, 30, 20)
+	if len(b.levels) != 10 || b.slot != 3 || b.offset != 0 {
+		t.Fatalf("%d bars of %d cells at %d", len(b.levels), b.slot, b.offset)
+	}
+
+	// silence: the bar drops at once, the cap waits before following
+	b.prepare(analyzer.Features{}, 0.2, 30, 20)
+	if b.levels[0] >= 0.8 || b.caps[0] != 0.8 {
+		t.Fatalf("after 0.2s level %v cap %v", b.levels[0], b.caps[0])
+	}
+	b.prepare(analyzer.Features{}, 0.3, 30, 20)
+	if b.caps[0] >= 0.8 || b.caps[0] < b.levels[0] {
+		t.Fatalf("cap not falling after the hold: %v (level %v)", b.caps[0], b.levels[0])
+	}
+}
+
+func TestBarsLayout(t *testing.T) {
+	var b barsPattern
+	var feat analyzer.Features
+	feat.Spectrum[0] = 0.5
+	b.prepare(feat, 1.0/60, 10, 10)
+	// 3 bars of 3 cells, centred with one spare cell; the first bar takes
+	// the lowest bands
+	at := func(col, row int) float64 { return b.eval(float64(col)/10, float64(row)/10) }
+	if at(0, 9) <= 0 || at(1, 9) <= 0 {
+		t.Fatal("first bar not drawn")
+	}
+	if at(2, 9) != -1 {
+		t.Fatal("no gap after the first bar")
+	}
+	if at(0, 5) <= 0 || at(0, 4) != 1 {
+		t.Fatalf("bar top %v, cap %v", at(0, 5), at(0, 4))
+	}
+	if at(0, 0) != -1 || at(4, 9) != -1 || at(9, 9) != -1 {
+		t.Fatal("lit cells above the bar, in a silent bar or in the margin")
+	}
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+const (
+	// brailleCols and brailleRows are the dots packed into one cell.
+	brailleCols = 2
+	brailleRows = 4
+	brailleBase = 0x2800
+)
+
+// brailleBits maps a dot at [row][col] to its bit in the U+2800 block.
+var brailleBits = [brailleRows][brailleCols]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// brailleDither holds ordered-dither thresholds per dot so brightness shows
+// up as dot density instead of a hard edge.
+var brailleDither = [brailleRows][brailleCols]float64{
+	{0.5 / 8, 4.5 / 8},
+	{6.5 / 8, 2.5 / 8},
+	{1.5 / 8, 5.5 / 8},
+	{7.5 / 8, 3.5 / 8},
+}
+
+// brailleRow renders terminal row y by evaluating a 2x4 dot grid per cell
+// (the pattern runs at gridW x 4*height) and packing lit dots into braille
+// characters. Each cell takes the colour of its brightest dot.
+func (r *Renderer) brailleRow(builder *rowWriter, y, width, gridW int, xCoords, yCoords []float64, scale float64, p params.Parameters, ctx frameParams, feat analyzer.Features, activation float64, capture []uint8, useANSI bool) {
+	builder.Reset()
+	lastColor := -1
+	for x := 0; x < width; x++ {
+		var bits rune
+		var best pixelResult
+		bestV := -1.0
+		for dy := 0; dy < brailleRows; dy++ {
+			gy := y*brailleRows + dy
+			vy := yCoords[gy] * scale
+			for dx := 0; dx < brailleCols; dx++ {
+				gx := x*brailleCols + dx
+				idx := gy*gridW + gx
+				res := r.evaluatePixel(xCoords[gx]*scale, vy, p, ctx, feat, activation, nil, nil, idx)
+				if res.glyphValue > brailleDither[dy][dx] {
+					bits |= brailleBits[dy][dx]
+				}
+				if res.v > bestV {
+					best, bestV = res, res.v
+				}
+				if capture != nil {
+					writePixel(capture[idx*4:idx*4+4:idx*4+4], res)
+				}
+			}
+		}
+		if useANSI {
+			if fg := hsvToANSI(best.h, best.s, best.v); fg != lastColor {
+				builder.WriteString(colorCode(fg))
+				lastColor = fg
+			}
+		}
+		if bits == 0 {
+			builder.WriteByte(' ')
+		} else {
+			builder.WriteRune(brailleBase + bits)
+		}
+	}
+	if useANSI {
+		builder.WriteString(resetANSI)
+	}
+}
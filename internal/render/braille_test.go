@@ -0,0 +1,40 @@
+package render
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestBraillePacksDotGridIntoCells(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "braille", "ripple", "chromatic", "high", true, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	r.SetCapture(true)
+	p, feat := snapshotScene()
+	frame := r.Render(p, feat, 60)
+
+	if len(frame.Lines) != snapshotHeight {
+		t.Fatalf("got %d lines, want %d", len(frame.Lines), snapshotHeight)
+	}
+	dots := 0
+	for y, line := range frame.Lines {
+		if n := utf8.RuneCountInString(line); n != snapshotWidth {
+			t.Fatalf("line %d has %d cells, want %d", y, n, snapshotWidth)
+		}
+		for _, ch := range line {
+			if ch != ' ' && (ch < brailleBase || ch > brailleBase+0xFF) {
+				t.Fatalf("line %d holds non-braille rune %q", y, ch)
+			}
+			if ch > brailleBase {
+				dots++
+			}
+		}
+	}
+	if dots == 0 {
+		t.Fatal("no dots lit")
+	}
+	if b := frame.Image.Rect; b.Dx() != snapshotWidth*brailleCols || b.Dy() != snapshotHeight*brailleRows {
+		t.Fatalf("capture is %v, want the %dx%d dot grid", b, snapshotWidth*brailleCols, snapshotHeight*brailleRows)
+	}
+}
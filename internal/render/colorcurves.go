@@ -0,0 +1,133 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ColorCurve shapes how a colour mode turns pattern values into HSV. Hue is
+// BaseHue + HueSpan*value + ShiftSpan*colorShift, with value and colorShift
+// both in 0-1. Saturation moves from SatMin to SatMax with brightness (fire)
+// or the saturation param (other modes). Value moves from ValueMin to
+// ValueMax with brightness, plus ValueDetail*value so bright pattern areas
+// stand out.
+type ColorCurve struct {
+	BaseHue     float64 `json:"baseHue"`
+	HueSpan     float64 `json:"hueSpan"`
+	ShiftSpan   float64 `json:"shiftSpan"`
+	SatMin      float64 `json:"satMin"`
+	SatMax      float64 `json:"satMax"`
+	ValueMin    float64 `json:"valueMin"`
+	ValueMax    float64 `json:"valueMax"`
+	ValueDetail float64 `json:"valueDetail"`
+}
+
+// defaultColorCurves are the built-in looks of each colour mode.
+var defaultColorCurves = map[colorMode]ColorCurve{
+	colorModeChromatic: {BaseHue: 0, HueSpan: 0.35, ShiftSpan: 1, SatMin: 0.85, SatMax: 1, ValueMin: 0, ValueMax: 0.95, ValueDetail: 0.15},
+	colorModeFire:      {BaseHue: 0.02, HueSpan: 0.08, ShiftSpan: 0.1, SatMin: 0.7, SatMax: 0.95, ValueMin: 0.35, ValueMax: 1.15, ValueDetail: 0.2},
+	colorModeAurora:    {BaseHue: 0.45, HueSpan: 0.25, ShiftSpan: 0.3, SatMin: 0.45, SatMax: 0.9, ValueMin: 0.28, ValueMax: 1.13, ValueDetail: 0.12},
+	colorModeMono:      {BaseHue: 0, HueSpan: 0, ShiftSpan: 1, SatMin: 0, SatMax: 0, ValueMin: 0, ValueMax: 1, ValueDetail: 0},
+}
+
+// DefaultColorCurves returns the built-in curve of every colour mode.
+func DefaultColorCurves() map[string]ColorCurve {
+	out := make(map[string]ColorCurve, len(defaultColorCurves))
+	for mode, curve := range defaultColorCurves {
+		out[string(mode)] = curve
+	}
+	return out
+}
+
+// validate rejects values that can't produce a colour.
+func (c ColorCurve) validate() error {
+	for _, v := range []float64{c.BaseHue, c.HueSpan, c.ShiftSpan, c.SatMin, c.SatMax, c.ValueMin, c.ValueMax, c.ValueDetail} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("curve values must be finite")
+		}
+	}
+	if c.SatMin < 0 || c.SatMax < 0 || c.SatMin > 1 || c.SatMax > 1 {
+		return fmt.Errorf("satMin and satMax must be within 0-1")
+	}
+	if c.ValueMin < 0 || c.ValueMax < 0 || c.ValueMin > 2 || c.ValueMax > 2 {
+		return fmt.Errorf("valueMin and valueMax must be within 0-2")
+	}
+	return nil
+}
+
+// lookupColorMode is parseColorMode without the chromatic fallback, for
+// the modes that have a curve.
+func lookupColorMode(name string) (colorMode, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	mode := parseColorMode(name)
+	if mode == colorModeChromatic && name != string(colorModeChromatic) {
+		return "", false
+	}
+	_, ok := defaultColorCurves[mode]
+	return mode, ok
+}
+
+// ColorCurves returns the curve of every colour mode, keyed by mode name.
+func (r *Renderer) ColorCurves() map[string]ColorCurve {
+	r.effectsMu.Lock()
+	defer r.effectsMu.Unlock()
+	out := DefaultColorCurves()
+	for mode, curve := range r.curves {
+		out[string(mode)] = curve
+	}
+	return out
+}
+
+// SetColorCurve replaces the curve of the named colour mode. It takes effect
+// on the next frame.
+func (r *Renderer) SetColorCurve(name string, curve ColorCurve) error {
+	mode, ok := lookupColorMode(name)
+	if !ok {
+		return fmt.Errorf("unknown color mode %q", name)
+	}
+	if err := curve.validate(); err != nil {
+		return fmt.Errorf("color mode %s: %w", mode, err)
+	}
+	r.effectsMu.Lock()
+	defer r.effectsMu.Unlock()
+	if r.curves == nil {
+		r.curves = make(map[colorMode]ColorCurve)
+	}
+	r.curves[mode] = curve
+	return nil
+}
+
+// ResetColorCurve restores the built-in curve of the named colour mode.
+func (r *Renderer) ResetColorCurve(name string) error {
+	mode, ok := lookupColorMode(name)
+	if !ok {
+		return fmt.Errorf("unknown color mode %q", name)
+	}
+	r.effectsMu.Lock()
+	defer r.effectsMu.Unlock()
+	delete(r.curves, mode)
+	return nil
+}
+
+// compileCurve picks the active mode's curve and gradient once per frame so
+// the pixel loop doesn't take the lock.
+func (r *Renderer) compileCurve() {
+	r.effectsMu.Lock()
+	defer r.effectsMu.Unlock()
+	curve, ok := r.curves[r.colorMode]
+	if !ok {
+		curve = defaultColorCurves[r.colorMode]
+	}
+	r.curve = curve
+	if r.fade.recolor {
+		r.fade.curve, ok = r.curves[r.fade.color]
+		if !ok {
+			r.fade.curve = defaultColorCurves[r.fade.color]
+		}
+	}
+	if r.gradientTable == nil {
+		r.gradientTable, _, _ = buildGradient(defaultGradient)
+	}
+	r.gradient = r.gradientTable
+}
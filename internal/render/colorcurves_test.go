@@ -0,0 +1,48 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestColorCurveShiftsHue(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "ripple", "fire", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	p, _ := snapshotScene()
+	r.compileCurve()
+	h, _, _ := r.colorFromMode(0, 0.5, p, analyzer.Features{}, 1)
+
+	curve := r.ColorCurves()["fire"]
+	curve.BaseHue += 0.1
+	if err := r.SetColorCurve("fire", curve); err != nil {
+		t.Fatalf("set curve: %v", err)
+	}
+	r.compileCurve()
+	shifted, _, _ := r.colorFromMode(0, 0.5, p, analyzer.Features{}, 1)
+	if diff := shifted - h; diff < 0.099 || diff > 0.101 {
+		t.Fatalf("hue moved by %v, want 0.1", diff)
+	}
+
+	if err := r.ResetColorCurve("fire"); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if r.ColorCurves()["fire"] != DefaultColorCurves()["fire"] {
+		t.Fatal("reset kept the custom curve")
+	}
+}
+
+func TestColorCurveRejectsBadInput(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "ripple", "fire", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	if err := r.SetColorCurve("sepia", ColorCurve{}); err == nil {
+		t.Error("unknown mode accepted")
+	}
+	if err := r.SetColorCurve("aurora", ColorCurve{SatMax: 3}); err == nil {
+		t.Error("out of range saturation accepted")
+	}
+}
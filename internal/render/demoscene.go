@@ -0,0 +1,72 @@
+package render
+
+import (
+	"math"
+
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// The classic demoscene effects: sums of sines, interfering waves, 2d
+// metaballs and copper bars, cheap enough for the eco profile.
+
+// plasma sums three sine fields, one of them circular around a wandering
+// centre, into the flowing blobs of 90s intros. It fills the screen, so
+// the level lifts it out of black with the music; the bass deepens the
+// contrast.
+func patternPlasma(x, y float64, p params.Parameters, t float64) float64 {
+	v := math.Sin(x*4 + t*1.3)
+	v += math.Sin(4*(x*math.Sin(t*0.5)+y*math.Cos(t*0.33)) + t)
+	cx, cy := x+0.5*math.Sin(t*0.4), y+0.5*math.Cos(t*0.6)
+	v += math.Sin(math.Sqrt(100*(cx*cx+cy*cy)+1)*0.6 + t)
+	v /= 3
+	level := clamp01(0.25*p.Amplitude + 0.3*p.BeatDistortion)
+	return v*(0.6+0.4*bassLevel(p)) - 1 + 1.2*level
+}
+
+// interference overlaps the rings of two drifting wave sources and keeps
+// only the crests where they add up (sparse - only the moire lines).
+func patternInterference(x, y float64, p params.Parameters, t float64) float64 {
+	d1 := math.Hypot(x-0.6*math.Sin(t*0.7), y-0.4*math.Cos(t*0.9))
+	d2 := math.Hypot(x+0.6*math.Sin(t*0.5+1), y+0.4*math.Cos(t*0.8))
+	s := 0.5 * (math.Sin(d1*12-t*3) + math.Sin(d2*12-t*3))
+	if s < 0.5 {
+		return -1.0
+	}
+	return (s - 0.5) * 4 * p.Amplitude
+}
+
+// blobs are flat 2d metaballs: five charges whose summed field is cut at
+// 1, drawn with a bright rim and a dim core. They swell with the bass and
+// puff up on beats.
+func patternBlobs(x, y float64, p params.Parameters, t float64) float64 {
+	radius := 0.12 + 0.08*bassLevel(p) + 0.04*p.BeatDistortion
+	field := 0.0
+	for i := range 5 {
+		f := float64(i)
+		dx := x - 0.7*math.Sin(t*0.6+f*1.9)
+		dy := y - 0.5*math.Cos(t*0.8+f*2.7)
+		field += radius * radius / (dx*dx + dy*dy + 1e-6)
+	}
+	if field < 1 {
+		return -1.0
+	}
+	return math.Max(1-(field-1)*1.5, -0.6)
+}
+
+// copper bars are horizontal bands bouncing on sines, each shaded from a
+// bright middle to dark edges like the amiga's copper list. Beats fatten
+// the bars.
+func patternCopper(x, y float64, p params.Parameters, t float64) float64 {
+	half := 0.08 + 0.04*p.BeatDistortion
+	best := 0.0
+	for i := range 5 {
+		centre := 0.7 * math.Sin(t*1.3+float64(i)*0.6)
+		if d := math.Abs(y - centre); d < half {
+			best = math.Max(best, math.Cos(d/half*math.Pi/2))
+		}
+	}
+	if best == 0 {
+		return -1.0
+	}
+	return best*2 - 1 + 0.25*p.BeatDistortion
+}
@@ -0,0 +1,32 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+func TestEmptyPatternIsPlasma(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "", "chromatic", "high", true, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	if r.PatternName() != "plasma" {
+		t.Fatalf("empty pattern fell back to %q", r.PatternName())
+	}
+}
+
+func TestBlobsMerge(t *testing.T) {
+	p := params.Defaults()
+	// at t=0 the first two blobs sit at (0, 0.5) and (0.7*sin(1.9), 0.5*cos(1.9))
+	if patternBlobs(0, 0.5, p, 0) <= -1 {
+		t.Fatal("blob centre is dark")
+	}
+	if patternBlobs(0, -0.9, p, 0) != -1 {
+		t.Fatal("empty space is lit")
+	}
+	// the core of a blob is dimmer than its rim
+	if patternBlobs(0, 0.5, p, 0) >= patternBlobs(0, 0.5-0.11, p, 0) {
+		t.Fatal("core isn't dimmer than the rim")
+	}
+}
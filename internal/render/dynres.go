@@ -0,0 +1,179 @@
+package render
+
+import (
+	"math"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+// Dynamic resolution modes. Energy is SDL only, frame time also thins out
+// the ASCII grid.
+const (
+	DynResOff       = "off"
+	DynResEnergy    = "energy"
+	DynResFrameTime = "frametime"
+)
+
+// DynamicResolutionNames lists the accepted --dynamic-res values.
+func DynamicResolutionNames() []string {
+	return []string{DynResOff, DynResEnergy, DynResFrameTime}
+}
+
+const (
+	// dynResMaxFactor is the coarsest extra downsampling quiet passages get.
+	dynResMaxFactor = 3
+	// dynResMargin keeps the level from bouncing across a threshold.
+	dynResMargin = 0.05
+	// dynResHold is how long the level must stay low before detail drops.
+	// Rising energy restores detail right away.
+	dynResHold = 1.5
+	// dynResSmoothing is the time constant of the energy follower (seconds).
+	dynResSmoothing = 0.4
+)
+
+// dynResThresholds are the energy levels below which the factor steps up to
+// 2 and 3.
+var dynResThresholds = [dynResMaxFactor - 1]float64{0.35, 0.15}
+
+// energyResolution trades pixels for headroom: quiet passages are mostly dark
+// anyway, so they render at a coarser internal resolution and loud sections
+// get every pixel back.
+type energyResolution struct {
+	level  float64
+	factor int
+	low    float64 // seconds the level has asked for a coarser factor
+}
+
+func newEnergyResolution(mode string) *energyResolution {
+	if mode != DynResEnergy {
+		return nil
+	}
+	return &energyResolution{factor: 1}
+}
+
+// Update follows the frame's energy and returns the extra downsampling
+// factor (1 = full resolution).
+func (e *energyResolution) Update(feat analyzer.Features, delta float64) int {
+	if e == nil {
+		return 1
+	}
+	energy := math.Max(feat.Overall, feat.BeatStrength)
+	alpha := 1 - math.Exp(-delta/dynResSmoothing)
+	e.level += (energy - e.level) * alpha
+
+	target := 1
+	for i, threshold := range dynResThresholds {
+		limit := threshold
+		if e.factor > i+1 {
+			// already this coarse: need more energy to climb back
+			limit += dynResMargin
+		} else {
+			limit -= dynResMargin
+		}
+		if e.level < limit {
+			target = i + 2
+		}
+	}
+
+	switch {
+	case target < e.factor:
+		e.factor = target
+		e.low = 0
+	case target > e.factor:
+		e.low += delta
+		if e.low >= dynResHold {
+			e.factor++
+			e.low = 0
+		}
+	default:
+		e.low = 0
+	}
+	return e.factor
+}
+
+const (
+	// frameResMaxFactor is the coarsest block size slow frames get.
+	frameResMaxFactor = 4
+	// frameResSmoothing is the time constant of the render time follower
+	// (seconds).
+	frameResSmoothing = 0.3
+	// frameResOverHold is how long rendering must run over budget before
+	// detail drops, frameResUnderHold how long it must fit the budget at
+	// the finer factor before detail comes back.
+	frameResOverHold  = 0.5
+	frameResUnderHold = 2.0
+	// frameResHeadroom is the share of the budget the finer factor must be
+	// expected to fit in before going back to it.
+	frameResHeadroom = 0.8
+)
+
+// frameTimeResolution trades detail for frame rate: while rendering takes
+// longer than the budget, cells or pixels are evaluated in bigger blocks,
+// and detail comes back once the finer factor would fit again.
+type frameTimeResolution struct {
+	budget float64 // seconds one Render may take
+	avg    float64 // smoothed render time, seconds
+	factor int
+	over   float64 // seconds avg has been over budget
+	under  float64 // seconds the finer factor has been expected to fit
+}
+
+func newFrameTimeResolution(mode string, budget time.Duration) *frameTimeResolution {
+	if mode != DynResFrameTime || budget <= 0 {
+		return nil
+	}
+	return &frameTimeResolution{budget: budget.Seconds(), factor: 1}
+}
+
+// Factor returns the block size to render the next frame at (1 = every
+// cell).
+func (f *frameTimeResolution) Factor() int {
+	if f == nil {
+		return 1
+	}
+	return f.factor
+}
+
+// Observe follows how long the last Render took; delta is the time since
+// the previous frame.
+func (f *frameTimeResolution) Observe(took, delta float64) {
+	if f == nil {
+		return
+	}
+	alpha := 1 - math.Exp(-delta/frameResSmoothing)
+	f.avg += (took - f.avg) * alpha
+
+	// the work shrinks with the square of the factor
+	finer := f.avg
+	if f.factor > 1 {
+		ratio := float64(f.factor) / float64(f.factor-1)
+		finer = f.avg * ratio * ratio
+	}
+	switch {
+	case f.avg > f.budget && f.factor < frameResMaxFactor:
+		f.under = 0
+		f.over += delta
+		if f.over >= frameResOverHold {
+			f.step(1)
+		}
+	case f.factor > 1 && finer < f.budget*frameResHeadroom:
+		f.over = 0
+		f.under += delta
+		if f.under >= frameResUnderHold {
+			f.step(-1)
+		}
+	default:
+		f.over, f.under = 0, 0
+	}
+}
+
+// step moves the factor by dir and rescales the follower to the expected
+// render time at the new factor, so it doesn't step again on stale times.
+func (f *frameTimeResolution) step(dir int) {
+	next := f.factor + dir
+	ratio := float64(f.factor) / float64(next)
+	f.avg *= ratio * ratio
+	f.factor = next
+	f.over, f.under = 0, 0
+}
@@ -0,0 +1,117 @@
+package render
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestEnergyResolutionHoldsBeforeDroppingDetail(t *testing.T) {
+	e := newEnergyResolution(DynResEnergy)
+	const delta = 1.0 / 60
+
+	loud := analyzer.Features{Overall: 0.8}
+	for i := 0; i < 120; i++ {
+		if got := e.Update(loud, delta); got != 1 {
+			t.Fatalf("loud frame %d: factor %d, want 1", i, got)
+		}
+	}
+
+	quiet := analyzer.Features{Overall: 0.02}
+	if got := e.Update(quiet, delta); got != 1 {
+		t.Fatalf("first quiet frame dropped detail immediately (factor %d)", got)
+	}
+	for i := 0; i < 10*60; i++ {
+		e.Update(quiet, delta)
+	}
+	if e.factor != dynResMaxFactor {
+		t.Fatalf("after 10 s of quiet factor is %d, want %d", e.factor, dynResMaxFactor)
+	}
+
+	// a level sitting just above a threshold must not flip back
+	e.level = dynResThresholds[1] + dynResMargin/2
+	if got := e.Update(analyzer.Features{Overall: e.level}, delta); got != dynResMaxFactor {
+		t.Fatalf("hysteresis: factor %d inside the margin, want %d", got, dynResMaxFactor)
+	}
+
+	for i := 0; i < 60; i++ {
+		e.Update(loud, delta)
+	}
+	if e.factor != 1 {
+		t.Fatalf("loud passage left factor at %d, want 1", e.factor)
+	}
+}
+
+func TestEnergyResolutionOffIsNil(t *testing.T) {
+	if e := newEnergyResolution(DynResOff); e != nil {
+		t.Fatal("off mode should not allocate a controller")
+	}
+	var e *energyResolution
+	if got := e.Update(analyzer.Features{}, 1.0/60); got != 1 {
+		t.Fatalf("nil controller returned %d, want 1", got)
+	}
+}
+
+func TestFrameTimeResolutionFollowsRenderTime(t *testing.T) {
+	budget := 10 * time.Millisecond
+	f := newFrameTimeResolution(DynResFrameTime, budget)
+	const delta = 1.0 / 60
+
+	// a brief spike must not cost detail
+	for i := 0; i < 10; i++ {
+		f.Observe(0.030, delta)
+	}
+	for i := 0; i < 120; i++ {
+		f.Observe(0.004, delta)
+	}
+	if f.Factor() != 1 {
+		t.Fatalf("spike coarsened to factor %d", f.Factor())
+	}
+
+	// slow frames step down one factor per hold, work shrinking with it
+	cost := 0.030 // seconds at full detail
+	for i := 0; i < 5*60; i++ {
+		k := float64(f.Factor())
+		f.Observe(cost/(k*k), delta)
+	}
+	if got := f.Factor(); got != 2 {
+		t.Fatalf("factor %d after slow frames, want 2 (the first that fits)", got)
+	}
+
+	// headroom returns: back to full detail
+	cost = 0.005
+	for i := 0; i < 5*60; i++ {
+		k := float64(f.Factor())
+		f.Observe(cost/(k*k), delta)
+	}
+	if got := f.Factor(); got != 1 {
+		t.Fatalf("factor %d once frames are cheap again, want 1", got)
+	}
+}
+
+func TestFrameTimeResolutionThinsASCIIGrid(t *testing.T) {
+	r, err := New(12, 6, "default", "plasma", "chromatic", "high", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetFrameBudget(time.Millisecond)
+	r.SetDynamicResolution(DynResFrameTime)
+	r.frameRes.factor = 3
+	p, feat := snapshotScene()
+	lines := r.Render(p, feat, 60).Lines
+	for y, line := range lines {
+		row := []rune(line)
+		if len(row) != 12 {
+			t.Fatalf("row %d has %d cells, want 12", y, len(row))
+		}
+		for x := range row {
+			if row[x] != row[x-x%3] {
+				t.Fatalf("cell %d,%d differs from its block", x, y)
+			}
+		}
+		if line != lines[y-y%3] {
+			t.Fatalf("row %d differs from its block's first row", y)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// effectKind says where in evaluatePixel a stage runs.
+type effectKind int
+
+const (
+	// effectWarp stages remap pattern coordinates before the pattern is sampled.
+	effectWarp effectKind = iota
+	// effectShade stages adjust the per-pixel brightness after tone mapping.
+	effectShade
+)
+
+type effectParam struct {
+	name string
+	def  float64
+	min  float64
+	max  float64
+}
+
+type effectEntry struct {
+	kind    effectKind
+	enabled bool
+	params  []effectParam
+	// history stages read last frame's brightness (trails, glow)
+	history bool
+	warp    func(x, y float64, v []float64) (float64, float64)
+	shade   func(px pixelState, v []float64) float64
+}
+
+var effectRegistry = map[string]effectEntry{
+	"mirror": {
+		kind: effectWarp,
+		params: []effectParam{
+			{"x", 1, 0, 1},
+			{"y", 0, 0, 1},
+		},
+		warp: effectMirror,
+	},
+	"kaleidoscope": {
+		kind: effectWarp,
+		params: []effectParam{
+			{"segments", 6, 2, 16},
+			{"rotation", 0, 0, 2 * math.Pi},
+		},
+		warp: effectKaleidoscope,
+	},
+	"vignette": {
+		kind:    effectShade,
+		enabled: true,
+		params:  []effectParam{{"amount", 1, 0, 2}},
+		shade:   effectVignette,
+	},
+	"bloom": {
+		kind:    effectShade,
+		history: true,
+		params: []effectParam{
+			{"threshold", 0.6, 0, 0.95},
+			{"strength", 0.5, 0, 2},
+		},
+		shade: effectBloom,
+	},
+	"persistence": {
+		kind:    effectShade,
+		history: true,
+		params:  []effectParam{{"decay", 0.85, 0, 0.98}},
+		shade:   effectPersistence,
+	},
+	"crt": {
+		kind: effectShade,
+		params: []effectParam{
+			{"scanlines", 0.35, 0, 1},
+			{"flicker", 0.03, 0, 0.3},
+		},
+		shade: effectCRT,
+	},
+}
+
+// defaultEffectOrder is the pipeline order until the user rearranges it.
+var defaultEffectOrder = []string{"mirror", "kaleidoscope", "vignette", "bloom", "persistence", "crt"}
+
+// EffectConfig is the user-facing state of one post effect stage.
+type EffectConfig struct {
+	Name    string             `json:"name"`
+	Enabled bool               `json:"enabled"`
+	Params  map[string]float64 `json:"params,omitempty"`
+}
+
+// EffectNames returns the post effects in their default pipeline order.
+func EffectNames() []string {
+	out := make([]string, len(defaultEffectOrder))
+	copy(out, defaultEffectOrder)
+	return out
+}
+
+// EffectLimits returns the min/max of every effect param, keyed by effect.
+func EffectLimits() map[string]map[string][2]float64 {
+	out := make(map[string]map[string][2]float64, len(effectRegistry))
+	for name, entry := range effectRegistry {
+		limits := make(map[string][2]float64, len(entry.params))
+		for _, param := range entry.params {
+			limits[param.name] = [2]float64{param.min, param.max}
+		}
+		out[name] = limits
+	}
+	return out
+}
+
+// effectStage is one configured entry of the pipeline. values follow the
+// order of entry.params and are replaced, never mutated, so a compiled chain
+// can keep reading them while the web panel edits the stage.
+type effectStage struct {
+	name    string
+	entry   effectEntry
+	enabled bool
+	values  []float64
+}
+
+// effectChain is the per-frame snapshot of enabled stages.
+type effectChain struct {
+	warps   []effectStage
+	shades  []effectStage
+	history bool
+}
+
+// pixelState is what shade stages see for the pixel being evaluated. It is
+// passed by value so the per-pixel call doesn't allocate.
+type pixelState struct {
+	vx, vy float64
+	x, y   int
+	// step is the distance between evaluated cells (SDL downsampling)
+	step         int
+	brightness   float64
+	time         float64
+	vignette     float64
+	vignetteSoft float64
+	hist         *frameHistory
+}
+
+// frameHistory double-buffers brightness so stages can look at last frame.
+type frameHistory struct {
+	width  int
+	height int
+	prev   []float64
+	cur    []float64
+}
+
+func (h *frameHistory) begin(width, height int) {
+	size := width * height
+	if h.width != width || h.height != height || len(h.cur) != size {
+		h.width = width
+		h.height = height
+		h.prev = make([]float64, size)
+		h.cur = make([]float64, size)
+		return
+	}
+	h.prev, h.cur = h.cur, h.prev
+}
+
+func (h *frameHistory) prevAt(x, y int) float64 {
+	if len(h.prev) == 0 {
+		return 0
+	}
+	x = clampInt(x, 0, h.width-1)
+	y = clampInt(y, 0, h.height-1)
+	return h.prev[y*h.width+x]
+}
+
+func newEffectStages() []effectStage {
+	stages := make([]effectStage, 0, len(defaultEffectOrder))
+	for _, name := range defaultEffectOrder {
+		entry := effectRegistry[name]
+		values := make([]float64, len(entry.params))
+		for i, param := range entry.params {
+			values[i] = param.def
+		}
+		stages = append(stages, effectStage{name: name, entry: entry, enabled: entry.enabled, values: values})
+	}
+	return stages
+}
+
+// Effects returns the post effect pipeline in execution order.
+func (r *Renderer) Effects() []EffectConfig {
+	r.effectsMu.Lock()
+	defer r.effectsMu.Unlock()
+	out := make([]EffectConfig, 0, len(r.effects))
+	for _, stage := range r.effects {
+		cfg := EffectConfig{Name: stage.name, Enabled: stage.enabled, Params: make(map[string]float64, len(stage.values))}
+		for i, param := range stage.entry.params {
+			cfg.Params[param.name] = stage.values[i]
+		}
+		out = append(out, cfg)
+	}
+	return out
+}
+
+// SetEffects updates the listed stages and moves them, in the given order, to
+// the front of the pipeline. Stages not mentioned keep their settings and
+// relative order. Params missing from a config keep their current value.
+func (r *Renderer) SetEffects(configs []EffectConfig) error {
+	r.effectsMu.Lock()
+	defer r.effectsMu.Unlock()
+
+	byName := make(map[string]int, len(r.effects))
+	for i, stage := range r.effects {
+		byName[stage.name] = i
+	}
+
+	ordered := make([]effectStage, 0, len(r.effects))
+	used := make(map[string]bool, len(configs))
+	for _, cfg := range configs {
+		name := strings.ToLower(strings.TrimSpace(cfg.Name))
+		idx, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("unknown effect %q", cfg.Name)
+		}
+		if used[name] {
+			continue
+		}
+		used[name] = true
+
+		stage := r.effects[idx]
+		stage.enabled = cfg.Enabled
+		values := make([]float64, len(stage.values))
+		copy(values, stage.values)
+		for key, value := range cfg.Params {
+			found := false
+			for i, param := range stage.entry.params {
+				if param.name == key {
+					values[i] = clampFloat(value, param.min, param.max)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("effect %s has no param %q", name, key)
+			}
+		}
+		stage.values = values
+		ordered = append(ordered, stage)
+	}
+	for _, stage := range r.effects {
+		if !used[stage.name] {
+			ordered = append(ordered, stage)
+		}
+	}
+	r.effects = ordered
+	return nil
+}
+
+// compileEffects snapshots the enabled stages for the frame about to render.
+func (r *Renderer) compileEffects() {
+	r.effectsMu.Lock()
+	defer r.effectsMu.Unlock()
+	chain := &r.chain
+	chain.warps = chain.warps[:0]
+	chain.shades = chain.shades[:0]
+	chain.history = false
+	for _, stage := range r.effects {
+		if !stage.enabled {
+			continue
+		}
+		switch stage.entry.kind {
+		case effectWarp:
+			chain.warps = append(chain.warps, stage)
+		case effectShade:
+			chain.shades = append(chain.shades, stage)
+		}
+		if stage.entry.history {
+			chain.history = true
+		}
+	}
+}
+
+func (c *effectChain) warp(x, y float64) (float64, float64) {
+	for i := range c.warps {
+		x, y = c.warps[i].entry.warp(x, y, c.warps[i].values)
+	}
+	return x, y
+}
+
+func (c *effectChain) shade(px pixelState, idx int) float64 {
+	for i := range c.shades {
+		px.brightness = c.shades[i].entry.shade(px, c.shades[i].values)
+	}
+	if c.history && idx >= 0 && idx < len(px.hist.cur) {
+		px.hist.cur[idx] = px.brightness
+	}
+	return px.brightness
+}
+
+// mirror folds the left half onto the right (x) and the top onto the bottom
+// (y); a param of 0.5 or more turns its axis on.
+func effectMirror(x, y float64, v []float64) (float64, float64) {
+	if v[0] >= 0.5 {
+		x = math.Abs(x)
+	}
+	if v[1] >= 0.5 {
+		y = math.Abs(y)
+	}
+	return x, y
+}
+
+func effectKaleidoscope(x, y float64, v []float64) (float64, float64) {
+	segments := math.Max(2, math.Round(v[0]))
+	wedge := 2 * math.Pi / segments
+	radius := math.Hypot(x, y)
+	angle := math.Mod(math.Atan2(y, x)+v[1], wedge)
+	if angle < 0 {
+		angle += wedge
+	}
+	if angle > wedge/2 {
+		angle = wedge - angle
+	}
+	return radius * math.Cos(angle), radius * math.Sin(angle)
+}
+
+// vignette darkens the edges for depth; strength follows params.Vignette.
+func effectVignette(px pixelState, v []float64) float64 {
+	if px.vignette <= 0 {
+		return px.brightness
+	}
+	dist := math.Min(1.0, math.Hypot(px.vx, px.vy)*2.0)
+	vig := clamp01(1.0 - px.vignette*v[0]*math.Pow(dist, 1.2))
+	return px.brightness * lerp(1.0, vig, 1.0-px.vignetteSoft)
+}
+
+// bloom spreads last frame's highlights into neighbouring cells.
+func effectBloom(px pixelState, v []float64) float64 {
+	h := px.hist
+	d := px.step
+	glow := (h.prevAt(px.x-d, px.y) + h.prevAt(px.x+d, px.y) +
+		h.prevAt(px.x, px.y-d) + h.prevAt(px.x, px.y+d)) * 0.25
+	threshold := v[0]
+	if glow <= threshold {
+		return px.brightness
+	}
+	excess := (glow - threshold) / (1.0 - threshold)
+	return clamp01(px.brightness + excess*v[1])
+}
+
+// persistence keeps a fading trail of previous frames.
+func effectPersistence(px pixelState, v []float64) float64 {
+	return math.Max(px.brightness, px.hist.prevAt(px.x, px.y)*v[0])
+}
+
+// crt dims every other row and adds a slight mains-hum flicker.
+func effectCRT(px pixelState, v []float64) float64 {
+	b := px.brightness
+	if (px.y/px.step)%2 == 1 {
+		b *= 1.0 - v[0]
+	}
+	b *= 1.0 - v[1]*(0.5+0.5*math.Sin(px.time*60.0))
+	return b
+}
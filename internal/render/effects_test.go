@@ -0,0 +1,70 @@
+package render
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func effectOrder(r *Renderer) []string {
+	var names []string
+	for _, cfg := range r.Effects() {
+		names = append(names, cfg.Name)
+	}
+	return names
+}
+
+func TestSetEffectsOrder(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "ripple", "fire", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	if got := effectOrder(r); !slices.Equal(got, EffectNames()) {
+		t.Fatalf("default order %q, want %q", got, EffectNames())
+	}
+	err = r.SetEffects([]EffectConfig{
+		{Name: "crt", Enabled: true, Params: map[string]float64{"flicker": 5}},
+		{Name: " Mirror ", Enabled: true},
+		{Name: "crt", Enabled: false}, // only the first mention counts
+	})
+	if err != nil {
+		t.Fatalf("set effects: %v", err)
+	}
+	want := []string{"crt", "mirror", "kaleidoscope", "vignette", "bloom", "persistence"}
+	if got := effectOrder(r); !slices.Equal(got, want) {
+		t.Errorf("order %q, want %q", got, want)
+	}
+	crt := r.Effects()[0]
+	if !crt.Enabled || crt.Params["flicker"] != 0.3 || crt.Params["scanlines"] != 0.35 {
+		t.Errorf("crt %+v, want enabled, flicker clamped to 0.3 and scanlines kept", crt)
+	}
+
+	r.compileEffects()
+	var shades []string
+	for _, stage := range r.chain.shades {
+		shades = append(shades, stage.name)
+	}
+	if want := []string{"crt", "vignette"}; !slices.Equal(shades, want) || len(r.chain.warps) != 1 {
+		t.Errorf("compiled shades %q and %d warps, want %q and mirror", shades, len(r.chain.warps), want)
+	}
+}
+
+func TestSetEffectsAllOrNothing(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "ripple", "fire", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	before := r.Effects()
+	for _, configs := range [][]EffectConfig{
+		{{Name: "bloom", Enabled: true, Params: map[string]float64{"strength": 2}}, {Name: "sparkle", Enabled: true}},
+		{{Name: "crt", Enabled: true}, {Name: "mirror", Params: map[string]float64{"z": 1}}},
+		{{Name: ""}},
+	} {
+		if err := r.SetEffects(configs); err == nil {
+			t.Errorf("%+v: no error", configs)
+		}
+		if got := r.Effects(); !reflect.DeepEqual(got, before) {
+			t.Errorf("%+v: a failed update changed the pipeline to %+v", configs, got)
+		}
+	}
+}
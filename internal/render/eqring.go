@@ -0,0 +1,92 @@
+package render
+
+import (
+	"math"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+const (
+	// eqringRadius is the base circle's radius and eqringReach how far a
+	// band at full level sticks out past it, in half screen heights.
+	eqringRadius = 0.38
+	eqringReach  = 0.55
+	// eqringLine is the thickness of the base circle.
+	eqringLine = 0.04
+	// eqringFall is how fast a band drops, in levels per second.
+	eqringFall = 1.8
+	// eqringTurn is how far the ring turns on every beat, in radians.
+	eqringTurn = math.Pi / 8
+	// eqringBeat is the beat length assumed while no tempo is known.
+	eqringBeat = 0.5
+)
+
+// eqringPattern wraps the spectrum around a circle: bass at the top, the
+// treble meeting at the bottom, mirrored left and right so the outline has
+// no seam. Every band pushes the outline out by its level, and the ring
+// turns a notch on every beat, easing over the beat so it moves in time.
+type eqringPattern struct {
+	levels [analyzer.SpectrumBands]float64
+	beats  float64 // beats counted so far
+	left   float64 // share of the current beat's turn still to go
+	turn   float64 // the frame's rotation
+	aspect int
+
+	gridW, gridH int
+}
+
+func (e *eqringPattern) setAspect(aspect int) {
+	e.aspect = aspect
+}
+
+func (e *eqringPattern) prepare(feat analyzer.Features, dt float64, gridW, gridH int) {
+	e.gridW, e.gridH = gridW, gridH
+	for i, v := range feat.Spectrum {
+		e.levels[i] = math.Max(v, e.levels[i]-eqringFall*dt)
+	}
+
+	if feat.Onset {
+		// a beat cut short by the next one skips the rest of its turn
+		e.beats++
+		e.left = 1
+	}
+	beat := eqringBeat
+	if feat.Tempo > 0 {
+		beat = 60 / feat.Tempo
+	}
+	e.left = math.Max(e.left-dt/beat, 0)
+	e.turn = math.Mod(e.beats-smoothstep(e.left), 2*math.Pi/eqringTurn) * eqringTurn
+}
+
+// reach returns the outline's radius at angle a, measured clockwise from
+// the top, blending neighbouring bands.
+func (e *eqringPattern) reach(a float64) float64 {
+	a = math.Abs(math.Remainder(a, 2*math.Pi)) / math.Pi // 0 at the top, 1 at the bottom
+	pos := a * float64(analyzer.SpectrumBands-1)
+	lo := int(pos)
+	hi := min(lo+1, analyzer.SpectrumBands-1)
+	level := lerp(e.levels[lo], e.levels[hi], pos-float64(lo))
+	return eqringRadius + level*eqringReach
+}
+
+func (e *eqringPattern) eval(x, y float64) float64 {
+	if e.gridW == 0 || e.gridH == 0 {
+		return -1
+	}
+	// half screen heights from the centre, square cells
+	dx := (x - 0.5) * 2 * float64(e.gridW) / float64(max(e.aspect, 1)*e.gridH)
+	dy := (y - 0.5) * 2
+	r := math.Hypot(dx, dy)
+	if r < eqringRadius-eqringLine {
+		return -1
+	}
+	if r <= eqringRadius {
+		return 0.2
+	}
+	tip := e.reach(math.Atan2(dx, -dy) - e.turn)
+	if r > tip {
+		return -1
+	}
+	// brighter towards the tip
+	return -0.3 + 1.3*(r-eqringRadius)/math.Max(tip-eqringRadius, 1e-6)
+}
@@ -0,0 +1,51 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestEqringOutline(t *testing.T) {
+	var e eqringPattern
+	e.setAspect(2)
+	var feat analyzer.Features
+	feat.Spectrum[0] = 1
+	e.prepare(feat, 1.0/60, 80, 40)
+
+	if got := e.reach(0); math.Abs(got-(eqringRadius+eqringReach)) > 1e-9 {
+		t.Fatalf("bass reaches %v at the top", got)
+	}
+	if e.reach(math.Pi) != eqringRadius {
+		t.Fatalf("silent treble reaches %v at the bottom", e.reach(math.Pi))
+	}
+	if math.Abs(e.reach(0.7)-e.reach(-0.7)) > 1e-9 {
+		t.Fatal("outline isn't mirrored")
+	}
+
+	// the bass spike sticks out above the centre, the hole stays dark
+	top := 0.5 - (eqringRadius+eqringReach/2)/2
+	if e.eval(0.5, top) <= -1 {
+		t.Fatal("bass spike is dark")
+	}
+	if e.eval(0.5, 0.5) != -1 {
+		t.Fatal("centre is lit")
+	}
+}
+
+func TestEqringTurnsOnBeats(t *testing.T) {
+	var e eqringPattern
+	e.prepare(analyzer.Features{Onset: true, Tempo: 120}, 0, 80, 40)
+	if e.turn != 0 {
+		t.Fatalf("turned %v on the beat", e.turn)
+	}
+	e.prepare(analyzer.Features{Tempo: 120}, 0.25, 80, 40)
+	if math.Abs(e.turn-eqringTurn/2) > 1e-9 {
+		t.Fatalf("turned %v half a beat in", e.turn)
+	}
+	e.prepare(analyzer.Features{Tempo: 120}, 0.5, 80, 40)
+	if math.Abs(e.turn-eqringTurn) > 1e-9 {
+		t.Fatalf("turned %v after the beat", e.turn)
+	}
+}
@@ -0,0 +1,462 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// ExprPrefix marks a pattern given as an expression, e.g.
+// "expr:sin(r*8 - t*3) * bass". The expression is parsed once and evaluated
+// for every cell; negative results stay black like in the built-in patterns.
+const ExprPrefix = "expr:"
+
+// isExprPattern reports whether name is an expression pattern.
+func isExprPattern(name string) bool {
+	return strings.HasPrefix(name, ExprPrefix)
+}
+
+// CheckExpr reports whether an "expr:" pattern parses, so a typo can be
+// caught before the renderer falls back to the default pattern.
+func CheckExpr(pattern string) error {
+	_, err := compileExpr(strings.TrimPrefix(pattern, ExprPrefix))
+	return err
+}
+
+// exprEnv holds the variables of one evaluation.
+type exprEnv struct {
+	x, y, r, angle, t       float64
+	bass, mid, treble, beat float64
+	sub, energy, drop       float64
+}
+
+// exprVars maps variable names to their slot in exprEnv.
+var exprVars = map[string]exprFunc{
+	"x":      func(e exprEnv) float64 { return e.x },
+	"y":      func(e exprEnv) float64 { return e.y },
+	"r":      func(e exprEnv) float64 { return e.r },
+	"angle":  func(e exprEnv) float64 { return e.angle },
+	"t":      func(e exprEnv) float64 { return e.t },
+	"bass":   func(e exprEnv) float64 { return e.bass },
+	"mid":    func(e exprEnv) float64 { return e.mid },
+	"treble": func(e exprEnv) float64 { return e.treble },
+	"beat":   func(e exprEnv) float64 { return e.beat },
+	"sub":    func(e exprEnv) float64 { return e.sub },
+	"energy": func(e exprEnv) float64 { return e.energy },
+	"drop":   func(e exprEnv) float64 { return e.drop },
+}
+
+var exprConsts = map[string]float64{
+	"pi":  math.Pi,
+	"tau": 2 * math.Pi,
+	"e":   math.E,
+}
+
+// exprFunc1, exprFunc2 and exprFunc3 are the callable functions by
+// argument count. Separate signatures keep the calls from allocating.
+var exprFunc1 = map[string]func(float64) float64{
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+	"asin":  math.Asin,
+	"acos":  math.Acos,
+	"atan":  math.Atan,
+	"abs":   math.Abs,
+	"sqrt":  math.Sqrt,
+	"exp":   math.Exp,
+	"log":   math.Log,
+	"floor": math.Floor,
+	"ceil":  math.Ceil,
+	"fract": func(v float64) float64 { return v - math.Floor(v) },
+	"sign":  sign,
+}
+
+var exprFunc2 = map[string]func(a, b float64) float64{
+	"atan2": math.Atan2,
+	"pow":   math.Pow,
+	"mod":   floorMod,
+	"min":   math.Min,
+	"max":   math.Max,
+	"step":  step,
+	"noise": func(x, y float64) float64 { return valueNoise2(x, y)*2 - 1 },
+}
+
+var exprFunc3 = map[string]func(a, b, c float64) float64{
+	"clamp": func(v, lo, hi float64) float64 { return math.Max(lo, math.Min(hi, v)) },
+	"mix":   func(a, b, t float64) float64 { return a + (b-a)*t },
+}
+
+// exprArity returns how many arguments the function name takes, 0 if there
+// is no such function.
+func exprArity(name string) int {
+	if _, ok := exprFunc1[name]; ok {
+		return 1
+	}
+	if _, ok := exprFunc2[name]; ok {
+		return 2
+	}
+	if _, ok := exprFunc3[name]; ok {
+		return 3
+	}
+	return 0
+}
+
+func sign(v float64) float64 {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// floorMod is GLSL's mod: the result has the sign of b, so repeating
+// patterns don't flip at 0.
+func floorMod(a, b float64) float64 {
+	return a - b*math.Floor(a/b)
+}
+
+// step is 0 below edge and 1 from it on, as in GLSL.
+func step(edge, v float64) float64 {
+	if v < edge {
+		return 0
+	}
+	return 1
+}
+
+// exprNode is a parsed expression.
+type exprNode struct {
+	kind  byte // 'n' number, 'v' variable, 'c' call, '~' negation, else the operator
+	value float64
+	name  string
+	args  []*exprNode
+}
+
+// exprFunc is a compiled expression. The environment is passed by value so
+// evaluating a cell doesn't allocate.
+type exprFunc func(e exprEnv) float64
+
+// configureExpr selects the expression pattern name, reporting whether it
+// compiled.
+func (r *Renderer) configureExpr(name string) bool {
+	src := strings.TrimSpace(name[len(ExprPrefix):])
+	fn, err := r.exprPattern(src)
+	if err != nil {
+		return false
+	}
+	r.pattern = fn
+	r.patternName = ExprPrefix + src
+	r.detailMix = 0
+	return true
+}
+
+// exprPattern returns the pattern function of an expression. The audio
+// variables come from the features of the frame being rendered.
+func (r *Renderer) exprPattern(src string) (patternFunc, error) {
+	eval, err := compileExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	return func(x, y float64, p params.Parameters, t float64) float64 {
+		feat := &r.frameFeatures
+		env := exprEnv{
+			x: x, y: y, r: math.Sqrt(x*x + y*y), angle: math.Atan2(y, x), t: t,
+			bass: feat.Bass, mid: feat.Mid, treble: feat.Treble, beat: feat.BeatStrength,
+			sub: feat.Sub, energy: feat.Overall, drop: boolFloat(feat.IsDrop),
+		}
+		v := eval(env)
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return -1
+		}
+		return v
+	}, nil
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Limits on an expression. The parser and the compiled closures recurse
+// once per nesting level, and patterns come in over the network, so a
+// formula is kept well inside what the stack takes.
+const (
+	exprMaxLen   = 4096 // bytes of source
+	exprMaxDepth = 64   // nested parentheses, calls, signs and powers
+)
+
+// compileExpr parses src and turns it into nested closures.
+func compileExpr(src string) (exprFunc, error) {
+	if len(src) > exprMaxLen {
+		return nil, fmt.Errorf("expr: longer than %d characters", exprMaxLen)
+	}
+	p := &exprParser{src: src}
+	p.next()
+	node, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != 0 {
+		return nil, p.errorf("unexpected %q", p.text)
+	}
+	return node.compile(), nil
+}
+
+// exprParser is a recursive descent parser over a one-token lookahead.
+type exprParser struct {
+	src   string
+	pos   int
+	tok   byte // 0 at the end, 'n' number, 'i' identifier, else the character
+	text  string
+	at    int
+	num   float64
+	err   error
+	depth int // parseUnary calls under way
+}
+
+func (p *exprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("expr: %s at column %d", fmt.Sprintf(format, args...), p.at+1)
+}
+
+func (p *exprParser) next() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+	p.at = p.pos
+	if p.pos >= len(p.src) {
+		p.tok, p.text = 0, ""
+		return
+	}
+	c := p.src[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		end := p.pos
+		for end < len(p.src) && (isDigit(p.src[end]) || p.src[end] == '.' ||
+			(p.src[end] == 'e' || p.src[end] == 'E') && end+1 < len(p.src) && (isDigit(p.src[end+1]) || p.src[end+1] == '-')) {
+			if p.src[end] == 'e' || p.src[end] == 'E' {
+				end++
+			}
+			end++
+		}
+		p.tok, p.text = 'n', p.src[p.pos:end]
+		p.num, p.err = strconv.ParseFloat(p.text, 64)
+		p.pos = end
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		end := p.pos
+		for end < len(p.src) && (p.src[end] == '_' || isDigit(p.src[end]) ||
+			p.src[end] >= 'a' && p.src[end] <= 'z' || p.src[end] >= 'A' && p.src[end] <= 'Z') {
+			end++
+		}
+		p.tok, p.text = 'i', strings.ToLower(p.src[p.pos:end])
+		p.pos = end
+	default:
+		p.tok, p.text = c, string(c)
+		p.pos++
+	}
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// parseSum handles + and -.
+func (p *exprParser) parseSum() (*exprNode, error) {
+	left, err := p.parseProduct()
+	for err == nil && (p.tok == '+' || p.tok == '-') {
+		op := p.tok
+		p.next()
+		var right *exprNode
+		if right, err = p.parseProduct(); err == nil {
+			left = &exprNode{kind: op, args: []*exprNode{left, right}}
+		}
+	}
+	return left, err
+}
+
+// parseProduct handles *, / and %.
+func (p *exprParser) parseProduct() (*exprNode, error) {
+	left, err := p.parseUnary()
+	for err == nil && (p.tok == '*' || p.tok == '/' || p.tok == '%') {
+		op := p.tok
+		p.next()
+		var right *exprNode
+		if right, err = p.parseUnary(); err == nil {
+			left = &exprNode{kind: op, args: []*exprNode{left, right}}
+		}
+	}
+	return left, err
+}
+
+// parseUnary handles a leading sign; it binds looser than ^, so -x^2 is
+// -(x^2).
+func (p *exprParser) parseUnary() (*exprNode, error) {
+	if p.depth == exprMaxDepth {
+		return nil, p.errorf("nested deeper than %d", exprMaxDepth)
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+	switch p.tok {
+	case '-':
+		p.next()
+		arg, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &exprNode{kind: '~', args: []*exprNode{arg}}, nil
+	case '+':
+		p.next()
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+// parsePower handles the right-associative ^.
+func (p *exprParser) parsePower() (*exprNode, error) {
+	base, err := p.parsePrimary()
+	if err != nil || p.tok != '^' {
+		return base, err
+	}
+	p.next()
+	exp, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &exprNode{kind: '^', args: []*exprNode{base, exp}}, nil
+}
+
+func (p *exprParser) parsePrimary() (*exprNode, error) {
+	switch p.tok {
+	case 'n':
+		if p.err != nil {
+			return nil, p.errorf("bad number %q", p.text)
+		}
+		node := &exprNode{kind: 'n', value: p.num}
+		p.next()
+		return node, nil
+	case 'i':
+		name := p.text
+		at := p.at
+		p.next()
+		if p.tok != '(' {
+			if v, ok := exprConsts[name]; ok {
+				return &exprNode{kind: 'n', value: v}, nil
+			}
+			if _, ok := exprVars[name]; ok {
+				return &exprNode{kind: 'v', name: name}, nil
+			}
+			p.at = at
+			return nil, p.errorf("unknown variable %q", name)
+		}
+		arity := exprArity(name)
+		if arity == 0 {
+			p.at = at
+			return nil, p.errorf("unknown function %q", name)
+		}
+		p.next()
+		node := &exprNode{kind: 'c', name: name}
+		for p.tok != ')' {
+			if len(node.args) > 0 {
+				if p.tok != ',' {
+					return nil, p.errorf("expected , or ) in %s()", name)
+				}
+				p.next()
+			}
+			arg, err := p.parseSum()
+			if err != nil {
+				return nil, err
+			}
+			node.args = append(node.args, arg)
+		}
+		if len(node.args) != arity {
+			p.at = at
+			return nil, p.errorf("%s takes %d arguments, got %d", name, arity, len(node.args))
+		}
+		p.next()
+		return node, nil
+	case '(':
+		p.next()
+		node, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok != ')' {
+			return nil, p.errorf("expected )")
+		}
+		p.next()
+		return node, nil
+	case 0:
+		return nil, p.errorf("unexpected end")
+	}
+	return nil, p.errorf("unexpected %q", p.text)
+}
+
+// compile turns the tree into closures, folding constant subtrees.
+func (n *exprNode) compile() exprFunc {
+	f := n.build()
+	if n.kind != 'n' && n.constant() {
+		v := f(exprEnv{})
+		return func(exprEnv) float64 { return v }
+	}
+	return f
+}
+
+// constant reports whether n doesn't depend on any variable.
+func (n *exprNode) constant() bool {
+	if n.kind == 'v' {
+		return false
+	}
+	for _, arg := range n.args {
+		if !arg.constant() {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *exprNode) build() exprFunc {
+	switch n.kind {
+	case 'n':
+		v := n.value
+		return func(exprEnv) float64 { return v }
+	case 'v':
+		return exprVars[n.name]
+	case '~':
+		a := n.args[0].compile()
+		return func(e exprEnv) float64 { return -a(e) }
+	case 'c':
+		args := make([]exprFunc, len(n.args))
+		for i, arg := range n.args {
+			args[i] = arg.compile()
+		}
+		switch len(args) {
+		case 1:
+			fn, a := exprFunc1[n.name], args[0]
+			return func(e exprEnv) float64 { return fn(a(e)) }
+		case 2:
+			fn, a, b := exprFunc2[n.name], args[0], args[1]
+			return func(e exprEnv) float64 { return fn(a(e), b(e)) }
+		default:
+			fn, a, b, c := exprFunc3[n.name], args[0], args[1], args[2]
+			return func(e exprEnv) float64 { return fn(a(e), b(e), c(e)) }
+		}
+	}
+	a, b := n.args[0].compile(), n.args[1].compile()
+	switch n.kind {
+	case '+':
+		return func(e exprEnv) float64 { return a(e) + b(e) }
+	case '-':
+		return func(e exprEnv) float64 { return a(e) - b(e) }
+	case '*':
+		return func(e exprEnv) float64 { return a(e) * b(e) }
+	case '/':
+		return func(e exprEnv) float64 { return a(e) / b(e) }
+	case '%':
+		return func(e exprEnv) float64 { return floorMod(a(e), b(e)) }
+	default:
+		return func(e exprEnv) float64 { return math.Pow(a(e), b(e)) }
+	}
+}
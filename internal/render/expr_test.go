@@ -0,0 +1,114 @@
+package render
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+func TestExprEval(t *testing.T) {
+	env := exprEnv{x: 0.5, y: -1, r: 2, t: 3, bass: 0.25, beat: 1}
+	for _, tc := range []struct {
+		src  string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"-2^2", -4},
+		{"2^3^2", 512},
+		{"2^-1", 0.5},
+		{"7 % 3", 1},
+		{"-1 % 3", 2},
+		{"r * bass + t", 3.5},
+		{"X + Y", -0.5},
+		{"max(x, y) + min(x, y)", -0.5},
+		{"clamp(t, 0, 1) * beat", 1},
+		{"mix(0, 10, bass)", 2.5},
+		{"step(0.5, x) + sign(y)", 0},
+		{"sin(pi / 2) + cos(0)", 2},
+		{"fract(2.75) + floor(2.75)", 2.75},
+		{"1e-1 * 10 + .5", 1.5},
+		{strings.Repeat("(", 63) + "t" + strings.Repeat(")", 63), 3},
+	} {
+		eval, err := compileExpr(tc.src)
+		if err != nil {
+			t.Errorf("%s: %v", tc.src, err)
+			continue
+		}
+		if got := eval(env); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", tc.src, got, tc.want)
+		}
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	for _, tc := range []struct {
+		src, want string
+	}{
+		{"", "unexpected end at column 1"},
+		{"1 +", "unexpected end at column 4"},
+		{"sin(r", "expected , or ) in sin() at column 6"},
+		{"(r * 2", "expected ) at column 7"},
+		{"r $ 2", `unexpected "$" at column 3`},
+		{"2 * foo", `unknown variable "foo" at column 5`},
+		{"bar(r)", `unknown function "bar" at column 1`},
+		{"min(r)", "min takes 2 arguments, got 1 at column 1"},
+		{"r 2", `unexpected "2" at column 3`},
+		{strings.Repeat("(", 64) + "x" + strings.Repeat(")", 64), "nested deeper than 64 at column 65"},
+		{strings.Repeat("-", 100) + "x", "nested deeper than 64 at column 65"},
+		{strings.Repeat("x^", 100) + "x", "nested deeper than 64 at column 129"},
+		{strings.Repeat("(", 5_000_000) + "x" + strings.Repeat(")", 5_000_000), "longer than 4096 characters"},
+	} {
+		err := CheckExpr(ExprPrefix + tc.src)
+		if err == nil || !strings.HasSuffix(err.Error(), tc.want) {
+			t.Errorf("%q: got %v, want %q", tc.src, err, tc.want)
+		}
+	}
+}
+
+func TestExprFoldsConstants(t *testing.T) {
+	node := &exprNode{kind: '*', args: []*exprNode{
+		{kind: 'c', name: "sqrt", args: []*exprNode{{kind: 'n', value: 16}}},
+		{kind: 'n', value: 2},
+	}}
+	if !node.constant() {
+		t.Fatal("constant tree not detected")
+	}
+	if got := node.compile()(exprEnv{}); got != 8 {
+		t.Fatalf("got %v", got)
+	}
+	node.args[1] = &exprNode{kind: 'v', name: "t"}
+	if node.constant() {
+		t.Fatal("variable treated as constant")
+	}
+}
+
+func TestExprPattern(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", `expr:sin(R*8 - t*3) * bass`, "chromatic", "high", true, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	if r.PatternName() != "expr:sin(R*8 - t*3) * bass" {
+		t.Fatalf("pattern %q", r.PatternName())
+	}
+	r.frameFeatures = analyzer.Features{Bass: 0.5}
+	if got, want := r.pattern(0.1, 0, params.Parameters{}, 0), math.Sin(0.8)*0.5; math.Abs(got-want) > 1e-9 {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got := r.pattern(0, 0, params.Parameters{}, 0); got != 0 {
+		t.Fatalf("origin %v", got)
+	}
+
+	r.Configure("default", "expr:log(x)", "chromatic", true)
+	if got := r.pattern(-1, 0, params.Parameters{}, 0); got != -1 {
+		t.Fatalf("NaN not blacked out: %v", got)
+	}
+
+	r.Configure("default", "expr:sin(", "chromatic", true)
+	if r.PatternName() != "ripple" {
+		t.Fatalf("broken expression selected %q", r.PatternName())
+	}
+}
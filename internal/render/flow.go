@@ -0,0 +1,168 @@
+package render
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+const (
+	// flowDensity is how many particles ride the field per grid cell, up to
+	// flowMaxParticles.
+	flowDensity      = 0.02
+	flowMaxParticles = 2500
+	// flowFreq is the field's feature count per screen height.
+	flowFreq = 2.5
+	// flowSpeed is how far a particle travels per second when it's quiet,
+	// in screen heights; energy and beats speed it up.
+	flowSpeed = 0.12
+	// flowDrift is how fast the field itself changes.
+	flowDrift = 0.08
+	// flowFade is how fast the streaks behind the particles fade per second.
+	flowFade = 2.5
+	// flowLife is the range of a particle's lifetime in seconds before it
+	// respawns somewhere else, so the field doesn't drain into sinks.
+	flowLifeMin, flowLifeMax = 2.0, 6.0
+	// flowWarmup is how much simulated time a reset runs ahead, so the
+	// pattern starts with streaks instead of dots.
+	flowWarmup = 1.5
+	// flowGlowFloor is the streak brightness below which a cell is black.
+	flowGlowFloor = 0.04
+)
+
+type flowParticle struct {
+	x, y float64 // in screen heights, x runs to the width in heights
+	life float64
+}
+
+// flowPattern advects particles through a curl noise field and draws their
+// fading streaks. Curl noise has no sources or sinks, so the particles swirl
+// around each other in smooth eddies. NoiseStrength folds a finer octave
+// into the field and beats kick the particles faster and stir it harder.
+type flowPattern struct {
+	particles []flowParticle
+	glow      []float64
+	rng       *rand.Rand
+	time      float64
+	kick      float64 // beat impulse, decaying
+	noise     float64 // NoiseStrength of the frame
+	aspect    int
+
+	gridW, gridH int
+	width        float64 // grid width in screen heights
+}
+
+func (f *flowPattern) setAspect(aspect int) {
+	if aspect != f.aspect {
+		f.aspect, f.gridW = aspect, 0
+	}
+}
+
+func (f *flowPattern) setParams(p params.Parameters) {
+	f.noise = p.NoiseStrength
+}
+
+func (f *flowPattern) prepare(feat analyzer.Features, dt float64, gridW, gridH int) {
+	if gridW != f.gridW || gridH != f.gridH {
+		f.reset(gridW, gridH)
+	}
+	if feat.Onset {
+		f.kick = math.Max(f.kick, clamp01(feat.BeatStrength))
+	}
+	f.step(dt, clamp01(feat.Overall))
+}
+
+// reset scatters particles over a gridW x gridH grid and runs the field for
+// flowWarmup seconds. The seed is fixed so a resize looks the same.
+func (f *flowPattern) reset(gridW, gridH int) {
+	f.gridW, f.gridH = gridW, gridH
+	f.width = float64(gridW) / float64(max(f.aspect, 1)*max(gridH, 1))
+	f.glow = make([]float64, gridW*gridH)
+	f.rng = rand.New(rand.NewSource(1))
+	n := min(int(float64(gridW*gridH)*flowDensity), flowMaxParticles)
+	f.particles = make([]flowParticle, n)
+	for i := range f.particles {
+		f.spawn(&f.particles[i])
+		f.particles[i].life *= f.rng.Float64()
+	}
+	for t := 0.0; t < flowWarmup; t += 1.0 / 30 {
+		f.step(1.0/30, 0)
+	}
+}
+
+func (f *flowPattern) spawn(p *flowParticle) {
+	p.x = f.rng.Float64() * f.width
+	p.y = f.rng.Float64()
+	p.life = flowLifeMin + f.rng.Float64()*(flowLifeMax-flowLifeMin)
+}
+
+// step moves every particle dt seconds along the field, stamping its path.
+func (f *flowPattern) step(dt, energy float64) {
+	if len(f.glow) == 0 {
+		return
+	}
+	f.time += dt
+	f.kick *= math.Exp(-dt * 4)
+	fade := math.Exp(-dt * flowFade * (1 + energy))
+	for i := range f.glow {
+		f.glow[i] *= fade
+	}
+
+	turbulence := clamp01(f.noise + f.kick)
+	speed := flowSpeed * (1 + 2*energy + 3*f.kick)
+	for i := range f.particles {
+		p := &f.particles[i]
+		p.life -= dt
+		vx, vy := f.velocity(p.x, p.y, turbulence)
+		x, y := p.x+vx*speed*dt, p.y+vy*speed*dt
+		if p.life <= 0 || x < 0 || x >= f.width || y < 0 || y >= 1 {
+			f.spawn(p)
+			continue
+		}
+		f.stamp(p.x, p.y, x, y)
+		p.x, p.y = x, y
+	}
+}
+
+// velocity returns the curl of the noise potential at x, y, about unit
+// length on average.
+func (f *flowPattern) velocity(x, y, turbulence float64) (float64, float64) {
+	const eps = 0.01
+	dx := (f.potential(x+eps, y, turbulence) - f.potential(x-eps, y, turbulence)) / (2 * eps)
+	dy := (f.potential(x, y+eps, turbulence) - f.potential(x, y-eps, turbulence)) / (2 * eps)
+	return dy / flowFreq, -dx / flowFreq
+}
+
+func (f *flowPattern) potential(x, y, turbulence float64) float64 {
+	t := f.time * flowDrift
+	v := valueNoise2(x*flowFreq+t, y*flowFreq-t*0.7)
+	if turbulence > 0 {
+		v += turbulence * 0.6 * valueNoise2(x*flowFreq*2.7-t*1.3+17, y*flowFreq*2.7+t+5)
+	}
+	return v * 2
+}
+
+// stamp lights the cells on the way from x0, y0 to x1, y1.
+func (f *flowPattern) stamp(x0, y0, x1, y1 float64) {
+	cellW, cellH := float64(f.gridW)/f.width, float64(f.gridH)
+	cx0, cy0, cx1, cy1 := x0*cellW, y0*cellH, x1*cellW, y1*cellH
+	steps := int(math.Max(math.Abs(cx1-cx0), math.Abs(cy1-cy0))) + 1
+	for s := 1; s <= steps; s++ {
+		t := float64(s) / float64(steps)
+		cx := clampInt(int(lerp(cx0, cx1, t)), 0, f.gridW-1)
+		cy := clampInt(int(lerp(cy0, cy1, t)), 0, f.gridH-1)
+		f.glow[cy*f.gridW+cx] = 1
+	}
+}
+
+func (f *flowPattern) eval(x, y float64) float64 {
+	if len(f.glow) == 0 {
+		return -1
+	}
+	if g := f.glow[gridCell(y, f.gridH)*f.gridW+gridCell(x, f.gridW)]; g > flowGlowFloor {
+		return g*2 - 1
+	}
+	return -1
+}
@@ -0,0 +1,53 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestFlowFieldIsDivergenceFree(t *testing.T) {
+	var f flowPattern
+	f.time = 3
+	const h = 1e-3
+	for _, pt := range [][2]float64{{0.2, 0.3}, {0.7, 0.55}, {1.1, 0.9}} {
+		x, y := pt[0], pt[1]
+		vx1, _ := f.velocity(x+h, y, 0.8)
+		vx0, _ := f.velocity(x-h, y, 0.8)
+		_, vy1 := f.velocity(x, y+h, 0.8)
+		_, vy0 := f.velocity(x, y-h, 0.8)
+		div := (vx1-vx0)/(2*h) + (vy1-vy0)/(2*h)
+		if math.Abs(div) > 0.5 {
+			t.Errorf("divergence %v at %v", div, pt)
+		}
+	}
+}
+
+func TestFlowStreaksAndBeats(t *testing.T) {
+	var f flowPattern
+	f.setAspect(2)
+	f.prepare(analyzer.Features{}, 1.0/60, 120, 40)
+	if len(f.particles) != 96 {
+		t.Fatalf("%d particles", len(f.particles))
+	}
+	lit := 0
+	for _, g := range f.glow {
+		if g > flowGlowFloor {
+			lit++
+		}
+	}
+	if lit < len(f.particles)*3 {
+		t.Fatalf("only %d cells lit after the warmup", lit)
+	}
+	for _, p := range f.particles {
+		if p.x < 0 || p.x >= f.width || p.y < 0 || p.y >= 1 {
+			t.Fatalf("particle outside the grid at %v, %v", p.x, p.y)
+		}
+	}
+
+	f.prepare(analyzer.Features{Onset: true, BeatStrength: 1}, 0, 120, 40)
+	if f.kick != 1 {
+		t.Fatalf("beat kick %v", f.kick)
+	}
+}
@@ -0,0 +1,578 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// The gl backend runs evaluatePixel as a GLSL ES 1.00 fragment shader. The
+// per-frame values (frameParams, the params patterns read, colour curve and
+// audio activation) are uniforms; each combination of pattern and effect
+// stages is compiled into its own program. The shader hash differs from
+// hash2, so noise warp and scatter look alike but not identical.
+
+// glVertexShader draws one full-screen quad.
+const glVertexShader = `attribute vec2 a_pos;
+void main() {
+	gl_Position = vec4(a_pos, 0.0, 1.0);
+}
+`
+
+// glFragmentHeader holds the uniforms and helpers every pattern shares.
+const glFragmentHeader = `#ifdef GL_FRAGMENT_PRECISION_HIGH
+precision highp float;
+#else
+precision mediump float;
+#endif
+
+uniform vec2 u_resolution;
+uniform float u_time;
+uniform float u_zoom;
+uniform vec2 u_rot;
+uniform float u_noiseScale;
+uniform float u_warp;
+uniform float u_swirl;
+uniform float u_amplitude;
+uniform float u_invGamma;
+uniform float u_invContrast;
+uniform float u_brightness;
+uniform float u_vignette;
+uniform float u_vignetteSoft;
+uniform float u_quality;
+uniform float u_octaves;
+uniform float u_beat;
+uniform float u_amp;
+uniform float u_scale;
+uniform float u_shift;
+uniform float u_saturation;
+uniform float u_activation;
+uniform float u_colorOnAudio;
+uniform float u_drop;
+uniform float u_colorMode;
+uniform vec3 u_curveHue;
+uniform vec2 u_curveSat;
+uniform vec3 u_curveValue;
+uniform vec4 u_pattern;
+
+const float PI = 3.14159265358979;
+
+// fmodGo is Go's math.Mod: the result keeps the sign of a.
+float fmodGo(float a, float b) {
+	float q = a / b;
+	return a - b * (q < 0.0 ? ceil(q) : floor(q));
+}
+
+float hash2(float x, float y) {
+	return fract(sin(dot(vec2(x, y), vec2(127.1, 311.7))) * 43758.5453);
+}
+
+float valueNoise2(vec2 v) {
+	vec2 i = floor(v);
+	vec2 f = v - i;
+	vec2 s = f * f * (3.0 - 2.0 * f);
+	float n00 = hash2(i.x, i.y);
+	float n10 = hash2(i.x + 1.0, i.y);
+	float n01 = hash2(i.x, i.y + 1.0);
+	float n11 = hash2(i.x + 1.0, i.y + 1.0);
+	return mix(mix(n00, n10, s.x), mix(n01, n11, s.x), s.y);
+}
+
+float fractalNoise(vec2 v) {
+	float amp = 0.5;
+	float freq = 1.0;
+	float total = 0.0;
+	float sumAmp = 0.0;
+	for (int i = 0; i < 4; i++) {
+		if (float(i) >= u_octaves) {
+			break;
+		}
+		total += valueNoise2(v * freq) * amp;
+		sumAmp += amp;
+		amp *= 0.5;
+		freq *= 2.0;
+	}
+	return (total / sumAmp) * 2.0 - 1.0;
+}
+
+vec3 hsvToRGB(float h, float s, float v) {
+	if (s <= 0.0) {
+		return vec3(v);
+	}
+	h = fract(h);
+	float hh = h * 6.0;
+	float i = floor(hh);
+	float f = hh - i;
+	float p = v * (1.0 - s);
+	float q = v * (1.0 - s * f);
+	float t = v * (1.0 - s * (1.0 - f));
+	if (i < 1.0) return vec3(v, t, p);
+	if (i < 2.0) return vec3(q, v, p);
+	if (i < 3.0) return vec3(p, v, t);
+	if (i < 4.0) return vec3(p, q, v);
+	if (i < 5.0) return vec3(t, p, v);
+	return vec3(v, p, q);
+}
+`
+
+// glFragmentMain is evaluatePixel and colorFromMode after the pattern.
+const glFragmentMain = `
+void main() {
+	vec2 v = vec2(gl_FragCoord.x / u_resolution.x - 0.5, 0.5 - gl_FragCoord.y / u_resolution.y) * u_scale;
+	vec2 b = v * u_zoom;
+	vec2 rot = vec2(b.x * u_rot.y - b.y * u_rot.x, b.x * u_rot.x + b.y * u_rot.y);
+
+	float radius = length(rot);
+	float angle = atan(rot.y, rot.x);
+	if (u_swirl != 0.0) {
+		float atten = exp(-radius * 1.6);
+		angle += u_swirl * atten * sin(u_time * 1.5 + radius * 2.3);
+		radius += u_swirl * 0.12 * sin(u_time * 1.15 + angle * 1.4);
+	}
+	vec2 d = radius * vec2(cos(angle), sin(angle));
+	if (u_warp > 0.0) {
+		d += fractalNoise(vec2(v.x + u_time * 0.15, v.y - u_time * 0.12) / u_noiseScale) * u_warp;
+	}
+	// @warps
+
+	float combined = clamp(pattern(d), -1.0, 1.0);
+	float bright = clamp((combined * u_amplitude + 1.0) * 0.5, 0.0, 1.0);
+	if (u_quality > 1.5) {
+		bright = bright * (0.7 + bright * 0.3);
+	} else {
+		bright = pow(pow(bright, u_invGamma), u_invContrast);
+	}
+	bright = clamp(bright * u_brightness, 0.0, 1.0);
+	if (u_colorOnAudio > 0.5) {
+		bright = clamp(bright * u_activation, 0.0, 1.0);
+	}
+	// @shades
+	bright = clamp(bright, 0.0, 1.0);
+
+	float baseNorm = clamp((combined + 1.0) * 0.5, 0.0, 1.0);
+	float hue = u_curveHue.x + baseNorm * u_curveHue.y + u_shift * u_curveHue.z;
+	float val = clamp(u_curveValue.x + bright * (u_curveValue.y - u_curveValue.x) + baseNorm * u_curveValue.z, 0.0, 1.0);
+	float h;
+	float s;
+	if (u_colorMode < 0.5) {
+		// chromatic: neon colors only
+		float hueBase = fract(hue);
+		h = hueBase < 0.5 ? hueBase * 0.6 : 0.5 + (hueBase - 0.5) * 0.7;
+		s = clamp(u_curveSat.x + u_saturation * (u_curveSat.y - u_curveSat.x), 0.0, 1.0);
+	} else if (u_colorMode < 1.5) {
+		// fire
+		h = clamp(hue, 0.0, 1.0);
+		s = clamp(u_curveSat.x + bright * (u_curveSat.y - u_curveSat.x), 0.0, 1.0);
+	} else {
+		// aurora, mono
+		h = clamp(hue, 0.0, 1.0);
+		s = clamp(u_curveSat.x + u_saturation * (u_curveSat.y - u_curveSat.x), 0.0, 1.0);
+	}
+	if (u_colorOnAudio > 0.5) {
+		float activation = u_drop > 0.5 ? clamp(u_activation + 0.2, 0.0, 1.0) : u_activation;
+		s = clamp(0.75 + activation * 0.25, 0.0, 1.0);
+		val = clamp(val * activation, 0.0, 1.0);
+		if (val < 0.01) {
+			val = 0.0;
+		}
+	}
+	gl_FragColor = vec4(hsvToRGB(h, s, val), 1.0);
+}
+`
+
+// glPatterns ports the patterns to GLSL. Each body is a
+// "float pattern(vec2 q)" reading u_time, u_beat (BeatDistortion), u_amp
+// (Amplitude) and u_pattern (the pattern's params, in order). Patterns
+// without a port render on the CPU.
+var glPatterns = map[string]string{
+	"plasma": `
+	float v = sin(q.x * 4.0 + u_time * 1.3);
+	v += sin(4.0 * (q.x * sin(u_time * 0.5) + q.y * cos(u_time * 0.33)) + u_time);
+	vec2 c = q + vec2(0.5 * sin(u_time * 0.4), 0.5 * cos(u_time * 0.6));
+	v += sin(sqrt(100.0 * dot(c, c) + 1.0) * 0.6 + u_time);
+	v /= 3.0;
+	float level = clamp(0.25 * u_amp + 0.3 * u_beat, 0.0, 1.0);
+	return v * (0.6 + 0.4 * clamp((u_amp - 1.0) / 1.5, 0.0, 1.0)) - 1.0 + 1.2 * level;`,
+	"interference": `
+	float d1 = length(q - vec2(0.6 * sin(u_time * 0.7), 0.4 * cos(u_time * 0.9)));
+	float d2 = length(q + vec2(0.6 * sin(u_time * 0.5 + 1.0), 0.4 * cos(u_time * 0.8)));
+	float s = 0.5 * (sin(d1 * 12.0 - u_time * 3.0) + sin(d2 * 12.0 - u_time * 3.0));
+	if (s < 0.5) return -1.0;
+	return (s - 0.5) * 4.0 * u_amp;`,
+	"blobs": `
+	float radius = 0.12 + 0.08 * clamp((u_amp - 1.0) / 1.5, 0.0, 1.0) + 0.04 * u_beat;
+	float field = 0.0;
+	for (int i = 0; i < 5; i++) {
+		float f = float(i);
+		vec2 d = q - vec2(0.7 * sin(u_time * 0.6 + f * 1.9), 0.5 * cos(u_time * 0.8 + f * 2.7));
+		field += radius * radius / (dot(d, d) + 1e-6);
+	}
+	if (field < 1.0) return -1.0;
+	return max(1.0 - (field - 1.0) * 1.5, -0.6);`,
+	"copper": `
+	float width = 0.08 + 0.04 * u_beat;
+	float best = 0.0;
+	for (int i = 0; i < 5; i++) {
+		float d = abs(q.y - 0.7 * sin(u_time * 1.3 + float(i) * 0.6));
+		if (d < width) best = max(best, cos(d / width * PI / 2.0));
+	}
+	if (best == 0.0) return -1.0;
+	return best * 2.0 - 1.0 + 0.25 * u_beat;`,
+	"tunnel3d": `
+	float ripple = 0.05 + 0.15 * u_beat;
+	vec3 ro = vec3(0.0, 0.0, u_time * 2.0);
+	vec3 rd = normalize(vec3(q * 1.4, 1.0));
+	float steps = 48.0 - 16.0 * u_quality;
+	float dist = 0.0;
+	float cost = 1.0;
+	for (int i = 0; i < 48; i++) {
+		if (float(i) >= steps) break;
+		vec3 p = ro + rd * dist;
+		float d = 1.0 - length(p.xy) + ripple * sin(atan(p.y, p.x) * 6.0 + p.z * 1.5);
+		if (d < 0.002 * dist + 0.005) {
+			cost = float(i) / steps;
+			break;
+		}
+		dist += d * 0.7;
+		if (dist > 20.0) return -1.0;
+	}
+	if (fract(ro.z + rd.z * dist) > 0.2) return -1.0;
+	return clamp(exp(-dist * 0.15) * (1.0 - 0.85 * cost), 0.0, 1.0) * 2.0 - 1.0;`,
+	"metaballs": `
+	float radius = 0.35 + 0.25 * clamp((u_amp - 1.0) / 1.5, 0.0, 1.0) + 0.1 * u_beat;
+	vec3 balls[4];
+	for (int i = 0; i < 4; i++) {
+		float f = float(i);
+		balls[i] = vec3(sin(u_time * 0.7 + f * 1.7) * 0.8, cos(u_time * 0.9 + f * 2.3) * 0.6, sin(u_time * 0.5 + f * 1.1) * 0.6);
+	}
+	vec3 ro = vec3(0.0, 0.0, -3.0);
+	vec3 rd = normalize(vec3(q * 1.2, 1.0));
+	float steps = 48.0 - 16.0 * u_quality;
+	float dist = 0.0;
+	float cost = 1.0;
+	for (int i = 0; i < 48; i++) {
+		if (float(i) >= steps) break;
+		vec3 p = ro + rd * dist;
+		float d = length(balls[0] - p) - radius;
+		for (int j = 1; j < 4; j++) {
+			float b = length(balls[j] - p) - radius;
+			float h = clamp(0.5 + 0.5 * (b - d) / 0.5, 0.0, 1.0);
+			d = mix(b, d, h) - 0.5 * h * (1.0 - h);
+		}
+		d += 0.04 * u_beat * sin(p.x * 8.0 + u_time * 3.0) * sin(p.y * 8.0) * sin(p.z * 8.0);
+		if (d < 0.002 * dist + 0.005) {
+			cost = float(i) / steps;
+			break;
+		}
+		dist += d * 0.7;
+		if (dist > 20.0) return -1.0;
+	}
+	return clamp(exp(-(dist - 2.0) * 0.15) * (1.0 - 0.85 * cost), 0.0, 1.0) * 2.0 - 1.0;`,
+	"torus": `
+	float tube = 0.3 + 0.1 * clamp((u_amp - 1.0) / 1.5, 0.0, 1.0);
+	float ripple = 0.06 * u_beat;
+	float sx = sin(u_time * 0.7);
+	float cx = cos(u_time * 0.7);
+	float sy = sin(u_time * 0.5);
+	float cy = cos(u_time * 0.5);
+	vec3 ro = vec3(0.0, 0.0, -3.0);
+	vec3 rd = normalize(vec3(q * 1.2, 1.0));
+	float steps = 48.0 - 16.0 * u_quality;
+	float dist = 0.0;
+	float cost = 1.0;
+	for (int i = 0; i < 48; i++) {
+		if (float(i) >= steps) break;
+		vec3 p = ro + rd * dist;
+		p = vec3(p.x, p.y * cx - p.z * sx, p.y * sx + p.z * cx);
+		p = vec3(p.x * cy + p.z * sy, p.y, -p.x * sy + p.z * cy);
+		float d = length(vec2(length(p.xz) - 0.9, p.y)) - tube + ripple * sin(atan(p.z, p.x) * 8.0 + u_time * 4.0);
+		if (d < 0.002 * dist + 0.005) {
+			cost = float(i) / steps;
+			break;
+		}
+		dist += d * 0.7;
+		if (dist > 20.0) return -1.0;
+	}
+	return clamp(exp(-(dist - 2.0) * 0.15) * (1.0 - 0.85 * cost), 0.0, 1.0) * 2.0 - 1.0;`,
+	"flash": `
+	float r = length(q);
+	if (r > 0.3) return -1.0;
+	float intensity = (0.3 - r) * 3.0 + u_beat * 2.0;
+	return intensity > 0.8 ? intensity : -1.0;`,
+	"spark": `
+	float rv = fract(atan(q.y, q.x) * floor(u_pattern.x + 0.5) / (2.0 * PI) + u_time * 2.0);
+	if (rv < 0.15 || rv > 0.85) {
+		float r = length(q);
+		if (r < 1.2) return u_beat * 3.0 * (1.2 - r);
+	}
+	return -1.0;`,
+	"scatter": `
+	float noise = hash2(floor(q.x * 5.0 + u_time), floor(q.y * 5.0 + u_time * 0.8));
+	float threshold = 0.95 - u_amp * 0.1;
+	return noise > threshold ? (noise - threshold) * 20.0 : -1.0;`,
+	"beam": `
+	float beamPos = (fract(u_time * 0.3) - 0.5) * 1.6;
+	float dist = abs(q.x - beamPos);
+	return dist < 0.08 ? (0.08 - dist) * 12.0 * u_amp : -1.0;`,
+	"ripple": `
+	float ripple = fract(length(q) * u_pattern.x - u_time * u_pattern.y);
+	if (ripple < 0.1 || ripple > 0.9) return min(ripple, 1.0 - ripple) * 20.0 * u_amp;
+	return -1.0;`,
+	"tunnel": `
+	float r = length(q);
+	if (r < 0.1) return -1.0;
+	float tunnel = fract(1.0 / r - u_time * 2.0);
+	if (tunnel < 0.1) {
+		float angleSnap = floor(atan(q.y, q.x) * 8.0 / (2.0 * PI));
+		if (fmodGo(angleSnap, 2.0) < 1.0) return tunnel * 10.0 * (0.5 + u_beat);
+	}
+	return -1.0;`,
+	"neurons": `
+	vec2 n[3];
+	n[0] = vec2(sin(u_time * 0.3), cos(u_time * 0.4));
+	n[1] = vec2(sin(u_time * 0.5 + 2.0), cos(u_time * 0.3 - 1.0));
+	n[2] = vec2(sin(u_time * 0.4 - 1.5), cos(u_time * 0.6 + 0.5));
+	for (int i = 0; i < 3; i++) {
+		float dist = distance(q, n[i]);
+		if (dist < 0.12) return (0.12 - dist) * 8.0 * u_amp;
+	}
+	for (int i = 0; i < 3; i++) {
+		for (int j = 0; j < 3; j++) {
+			if (j <= i) continue;
+			vec2 dd = n[j] - n[i];
+			float t = dot(q - n[i], dd) / dot(dd, dd);
+			if (t >= 0.0 && t <= 1.0) {
+				float dist = distance(q, n[i] + t * dd);
+				if (dist < 0.03) return (0.03 - dist) * 15.0 * u_beat * 2.0;
+			}
+		}
+	}
+	return -1.0;`,
+	"fractal": `
+	float r = length(q);
+	float branchAngle = fmodGo(atan(q.y, q.x) * 5.0 + u_time, 2.0 * PI);
+	if (branchAngle > PI) branchAngle = 2.0 * PI - branchAngle;
+	float scale = sin(r * 4.0 - u_time * 2.0);
+	if (branchAngle < 0.2 && scale > 0.5 && r < 1.2) return (0.2 - branchAngle) * 15.0 * (scale - 0.5) * (0.5 + u_amp);
+	return -1.0;`,
+	"laser": `
+	float dist = fract(q.x + q.y * 0.5 + u_time);
+	if (dist > 0.5) dist = 1.0 - dist;
+	return dist < 0.04 ? (0.04 - dist) * 25.0 * (0.5 + u_beat * 2.0) : -1.0;`,
+	"orbit": `
+	float r = length(q);
+	float val = fract(atan(q.y, q.x) * 2.0 + r * 4.0 - u_time * 2.0);
+	return abs(r - 0.5) < 0.15 && val > 0.85 ? u_amp * 5.0 : -1.0;`,
+	"explosion": `
+	float val = fract(length(q) * 4.0 - u_time * 3.0);
+	if (val < 0.15 || val > 0.85) return min(val, 1.0 - val) * 20.0 * (0.3 + u_beat * 3.0);
+	return -1.0;`,
+	"rings": `
+	float rings = sin(length(q) * u_pattern.x - u_time * u_pattern.y);
+	return rings > 0.7 ? (rings - 0.7) * 10.0 * u_amp : -1.0;`,
+	"zigzag": `
+	float dist = abs(q.x - sin(q.y * 5.0 + u_time * 2.0) * 0.3);
+	return dist < 0.06 ? (0.06 - dist) * 16.0 * (0.5 + u_beat * 2.0) : -1.0;`,
+	"cross": `
+	float angle = atan(q.y, q.x) + u_time;
+	angle = angle - floor(angle / (PI / 2.0)) * (PI / 2.0);
+	if (abs(angle) < 0.1 || abs(angle - PI / 2.0) < 0.1) {
+		float r = length(q);
+		if (r < 1.0) return (1.0 - r) * u_amp * 3.0;
+	}
+	return -1.0;`,
+	"spiral": `
+	float val = fract(atan(q.y, q.x) * floor(u_pattern.x + 0.5) - length(q) * u_pattern.y + u_time * 3.0);
+	return val < 0.12 ? val * 25.0 * u_amp : -1.0;`,
+	"star": `
+	float starAngle = fmodGo((atan(q.y, q.x) + u_time) * floor(u_pattern.x + 0.5), 2.0 * PI);
+	if (starAngle > PI) starAngle = 2.0 * PI - starAngle;
+	if (starAngle < 0.3) {
+		float r = length(q);
+		if (r < 1.2 && r > 0.2) return (0.3 - starAngle) * 10.0 * (0.5 + u_beat * 2.0);
+	}
+	return -1.0;`,
+}
+
+// glEffects ports the effect stages that don't look at earlier frames.
+// Warp stages move d, shade stages change bright; u_fx holds the stage's
+// params in order.
+var glEffects = map[string]string{
+	"mirror": `
+	if (u_fx.x >= 0.5) d.x = abs(d.x);
+	if (u_fx.y >= 0.5) d.y = abs(d.y);`,
+	"kaleidoscope": `
+	{
+		float wedge = 2.0 * PI / max(2.0, floor(u_fx.x + 0.5));
+		float a = fmodGo(atan(d.y, d.x) + u_fx.y, wedge);
+		if (a < 0.0) a += wedge;
+		if (a > wedge / 2.0) a = wedge - a;
+		d = length(d) * vec2(cos(a), sin(a));
+	}`,
+	"vignette": `
+	if (u_vignette > 0.0) {
+		float vig = clamp(1.0 - u_vignette * u_fx.x * pow(min(1.0, length(v) * 2.0), 1.2), 0.0, 1.0);
+		bright *= mix(1.0, vig, 1.0 - u_vignetteSoft);
+	}`,
+	"crt": `
+	if (mod(floor(u_resolution.y - gl_FragCoord.y), 2.0) > 0.5) bright *= 1.0 - u_fx.x;
+	bright *= 1.0 - u_fx.y * (0.5 + 0.5 * sin(u_time * 60.0));`,
+}
+
+// glFragmentSource returns the fragment shader for pattern with the given
+// effect stages, or false when the pattern or a stage has no GLSL port.
+// Stage i reads its params from u_fx<i>.
+func glFragmentSource(pattern string, warps, shades []string) (string, bool) {
+	body, ok := glPatterns[pattern]
+	if !ok {
+		return "", false
+	}
+	var b strings.Builder
+	b.WriteString(glFragmentHeader)
+	for i := range len(warps) + len(shades) {
+		fmt.Fprintf(&b, "uniform vec4 u_fx%d;\n", i)
+	}
+	b.WriteString("\nfloat pattern(vec2 q) {")
+	b.WriteString(body)
+	b.WriteString("\n}\n")
+	main := glFragmentMain
+	for _, part := range []struct {
+		marker string
+		stages []string
+		first  int
+	}{{"\t// @warps\n", warps, 0}, {"\t// @shades\n", shades, len(warps)}} {
+		var code strings.Builder
+		for i, name := range part.stages {
+			snippet, ok := glEffects[name]
+			if !ok {
+				return "", false
+			}
+			code.WriteString(strings.ReplaceAll(snippet, "u_fx", fmt.Sprintf("u_fx%d", part.first+i)))
+			code.WriteString("\n")
+		}
+		main = strings.Replace(main, part.marker, code.String(), 1)
+	}
+	b.WriteString(main)
+	return b.String(), true
+}
+
+// glUniforms is one frame's uniform values; the field names follow the
+// shader's u_ names.
+type glUniforms struct {
+	time, zoom, sinRot, cosRot float32
+	noiseScale, warp, swirl    float32
+	amplitude                  float32
+	invGamma, invContrast      float32
+	brightness                 float32
+	vignette, vignetteSoft     float32
+	quality, octaves           float32
+	beat, amp, scale           float32
+	shift, saturation          float32
+	activation, colorOnAudio   float32
+	drop, colorMode            float32
+	curveHue                   [3]float32
+	curveSat                   [2]float32
+	curveValue                 [3]float32
+	// fx holds each effect stage's params, warps first
+	fx [][4]float32
+	// pattern holds the pattern's params
+	pattern [4]float32
+}
+
+// glShader returns the program key and stage names of the current frame:
+// the pattern plus the enabled warp and shade stages. ok is false when the
+// frame needs the CPU path, for a pattern or stage without a port (bloom and
+// persistence read the previous frame), the gradient colour mode or while
+// the big-text overlay, the text overlay, a widget or a crossfade is on.
+func (r *Renderer) glShader() (key string, warps, shades []string, ok bool) {
+	if _, found := glPatterns[r.patternName]; !found {
+		return "", nil, nil, false
+	}
+	if r.colorMode == colorModeGradient {
+		return "", nil, nil, false
+	}
+	if r.text.text != "" && r.text.level > 0 || r.overlay.on || r.widgets.any || r.fade.on {
+		return "", nil, nil, false
+	}
+	names := func(stages []effectStage) []string {
+		out := make([]string, len(stages))
+		for i, stage := range stages {
+			out[i] = stage.name
+		}
+		return out
+	}
+	warps, shades = names(r.chain.warps), names(r.chain.shades)
+	for _, name := range append(warps[:len(warps):len(warps)], shades...) {
+		if _, found := glEffects[name]; !found {
+			return "", nil, nil, false
+		}
+	}
+	key = r.patternName + "|" + strings.Join(warps, ",") + "|" + strings.Join(shades, ",")
+	return key, warps, shades, true
+}
+
+// glUniformValues mirrors the scaling evaluatePixel applies per quality so
+// the shader only reads finished values.
+func (r *Renderer) glUniformValues(p params.Parameters, feat analyzer.Features, ctx frameParams, activation, scale float64) glUniforms {
+	swirl, warp := ctx.swirlStrength, ctx.warpStrength
+	quality := 0.0
+	switch ctx.quality {
+	case qualityEco:
+		swirl *= 0.55
+		warp *= 0.35
+		quality = 2
+	case qualityBalanced:
+		swirl *= 0.85
+		warp *= 0.7
+		quality = 1
+	}
+	shift := math.Mod(p.ColorShift/(2*math.Pi), 1.0)
+	if shift < 0 {
+		shift += 1.0
+	}
+	mode := 0.0
+	switch r.colorMode {
+	case colorModeFire:
+		mode = 1
+	case colorModeAurora, colorModeMono:
+		mode = 2
+	}
+	onAudio, drop := 0.0, 0.0
+	if r.colorOnAudio {
+		onAudio = 1
+	}
+	if feat.IsDrop {
+		drop = 1
+	}
+	c := r.curve
+	u := glUniforms{
+		time: float32(ctx.time), zoom: float32(ctx.zoom),
+		sinRot: float32(ctx.sinRot), cosRot: float32(ctx.cosRot),
+		noiseScale: float32(ctx.noiseScale), warp: float32(warp), swirl: float32(swirl),
+		amplitude: float32(ctx.amplitude), invGamma: float32(ctx.invGamma),
+		invContrast: float32(ctx.invContrast), brightness: float32(ctx.brightnessScale),
+		quality: float32(quality), octaves: float32(noiseOctaves.Load()),
+		beat: float32(p.BeatDistortion), amp: float32(p.Amplitude), scale: float32(scale),
+		shift: float32(shift), saturation: float32(p.Saturation),
+		activation: float32(activation), colorOnAudio: float32(onAudio),
+		drop: float32(drop), colorMode: float32(mode),
+		curveHue:   [3]float32{float32(c.BaseHue), float32(c.HueSpan), float32(c.ShiftSpan)},
+		curveSat:   [2]float32{float32(c.SatMin), float32(c.SatMax)},
+		curveValue: [3]float32{float32(c.ValueMin), float32(c.ValueMax), float32(c.ValueDetail)},
+		vignette:   float32(ctx.vignette), vignetteSoft: float32(ctx.vignetteSoft),
+	}
+	for _, stage := range append(r.chain.warps[:len(r.chain.warps):len(r.chain.warps)], r.chain.shades...) {
+		var v [4]float32
+		for i := 0; i < len(stage.values) && i < len(v); i++ {
+			v[i] = float32(stage.values[i])
+		}
+		u.fx = append(u.fx, v)
+	}
+	for i := 0; i < len(r.patternArgs) && i < len(u.pattern); i++ {
+		u.pattern[i] = float32(r.patternArgs[i])
+	}
+	return u
+}
@@ -0,0 +1,105 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGLShaderCoversEveryPattern(t *testing.T) {
+	for _, name := range PatternNames() {
+		if screenPatterns[name] != nil {
+			// drawn from per-frame state on the CPU
+			continue
+		}
+		src, ok := glFragmentSource(name, nil, nil)
+		if !ok {
+			t.Errorf("pattern %s has no GLSL port", name)
+			continue
+		}
+		if !strings.Contains(src, "float pattern(vec2 q)") || !strings.Contains(src, "gl_FragColor") {
+			t.Errorf("pattern %s: incomplete shader", name)
+		}
+	}
+	if _, ok := glFragmentSource("nope", nil, nil); ok {
+		t.Error("unknown pattern produced a shader")
+	}
+}
+
+func TestGLShaderEffectStages(t *testing.T) {
+	src, ok := glFragmentSource("ripple", []string{"kaleidoscope"}, []string{"vignette", "crt"})
+	if !ok {
+		t.Fatal("no shader for ripple with effects")
+	}
+	for _, want := range []string{"uniform vec4 u_fx0;", "uniform vec4 u_fx2;", "u_fx1.x", "u_fx2.y"} {
+		if !strings.Contains(src, want) {
+			t.Errorf("shader lacks %q", want)
+		}
+	}
+	if strings.Contains(src, "@warps") || strings.Contains(src, "@shades") {
+		t.Error("stage markers left in shader")
+	}
+	if _, ok := glFragmentSource("ripple", nil, []string{"bloom"}); ok {
+		t.Error("bloom has no port but produced a shader")
+	}
+}
+
+func TestGLShaderFallsBackToCPU(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "ripple", "chromatic", "high", true, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	r.compileEffects()
+	key, _, shades, ok := r.glShader()
+	if !ok {
+		t.Fatal("default effects should run in the shader")
+	}
+	if len(shades) != 1 || shades[0] != "vignette" || key != "ripple||vignette" {
+		t.Fatalf("key %q shades %v", key, shades)
+	}
+
+	if err := r.SetEffects([]EffectConfig{{Name: "bloom", Enabled: true}}); err != nil {
+		t.Fatal(err)
+	}
+	r.compileEffects()
+	if _, _, _, ok := r.glShader(); ok {
+		t.Error("bloom needs the previous frame but the shader was used")
+	}
+
+	r.SetEffects([]EffectConfig{{Name: "bloom"}})
+	r.compileEffects()
+	r.SetText("hi", 1)
+	if _, _, _, ok := r.glShader(); ok {
+		t.Error("text overlay on but the shader was used")
+	}
+}
+
+func TestGLUniformValues(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "ripple", "fire", "eco", true, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	r.SetEffects([]EffectConfig{{Name: "crt", Enabled: true, Params: map[string]float64{"scanlines": 0.5}}})
+	r.compileEffects()
+	r.compileCurve()
+	p, feat := snapshotScene()
+	ctx := r.buildFrameParams(p, p.Time)
+	u := r.glUniformValues(p, feat, ctx, 0.5, 1)
+
+	if u.quality != 2 || u.colorMode != 1 || u.colorOnAudio != 1 {
+		t.Fatalf("quality %v mode %v onAudio %v", u.quality, u.colorMode, u.colorOnAudio)
+	}
+	if want := float32(ctx.swirlStrength * 0.55); u.swirl != want {
+		t.Fatalf("swirl %v, want eco-scaled %v", u.swirl, want)
+	}
+	if u.shift < 0 || u.shift >= 1 {
+		t.Fatalf("shift %v outside 0-1", u.shift)
+	}
+	fire := defaultColorCurves[colorModeFire]
+	if u.curveSat != [2]float32{float32(fire.SatMin), float32(fire.SatMax)} {
+		t.Fatalf("curve sat %v", u.curveSat)
+	}
+	// SetEffects moved crt ahead of vignette, which is on by default
+	if len(u.fx) != 2 || u.fx[0][0] != 0.5 || u.fx[1][0] != 1 {
+		t.Fatalf("fx %v", u.fx)
+	}
+}
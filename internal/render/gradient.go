@@ -0,0 +1,175 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GradientStop is one colour of the gradient colour mode: Color as "#rrggbb"
+// (or "#rgb") at Pos, 0 for the darkest pixels and 1 for the brightest.
+type GradientStop struct {
+	Color string  `json:"color"`
+	Pos   float64 `json:"pos"`
+}
+
+// gradientSteps is the resolution of the precomputed gradient.
+const gradientSteps = 256
+
+// gradientMaxStops bounds the stops of a gradient.
+const gradientMaxStops = 16
+
+// defaultGradient is a night-club sunset, black through violet and pink to
+// warm white.
+var defaultGradient = []GradientStop{
+	{Color: "#000000", Pos: 0},
+	{Color: "#3b0764", Pos: 0.3},
+	{Color: "#db2777", Pos: 0.65},
+	{Color: "#fde68a", Pos: 1},
+}
+
+// gradientTable holds the gradient as HSV, sampled evenly over brightness.
+type gradientTable [gradientSteps][3]float64
+
+// DefaultGradient returns the built-in gradient.
+func DefaultGradient() []GradientStop {
+	return slices.Clone(defaultGradient)
+}
+
+// Gradient returns the stops of the gradient colour mode, sorted by
+// position.
+func (r *Renderer) Gradient() []GradientStop {
+	r.effectsMu.Lock()
+	defer r.effectsMu.Unlock()
+	if r.gradientStops == nil {
+		return DefaultGradient()
+	}
+	return slices.Clone(r.gradientStops)
+}
+
+// CustomGradient reports whether the gradient differs from the default.
+func (r *Renderer) CustomGradient() bool {
+	r.effectsMu.Lock()
+	defer r.effectsMu.Unlock()
+	return r.gradientStops != nil
+}
+
+// SetGradient replaces the stops of the gradient colour mode. It takes
+// effect on the next frame; nil restores the default.
+func (r *Renderer) SetGradient(stops []GradientStop) error {
+	custom := stops != nil
+	if !custom {
+		stops = defaultGradient
+	}
+	table, sorted, err := buildGradient(stops)
+	if err != nil {
+		return fmt.Errorf("gradient: %w", err)
+	}
+	r.effectsMu.Lock()
+	defer r.effectsMu.Unlock()
+	r.gradientStops = nil
+	if custom {
+		r.gradientStops = sorted
+	}
+	r.gradientTable = table
+	return nil
+}
+
+// buildGradient validates the stops and samples them. Brightness below the
+// first stop takes its colour, above the last one likewise.
+func buildGradient(stops []GradientStop) (*gradientTable, []GradientStop, error) {
+	if len(stops) < 2 || len(stops) > gradientMaxStops {
+		return nil, nil, fmt.Errorf("needs 2-%d stops, got %d", gradientMaxStops, len(stops))
+	}
+	sorted := slices.Clone(stops)
+	slices.SortStableFunc(sorted, func(a, b GradientStop) int {
+		switch {
+		case a.Pos < b.Pos:
+			return -1
+		case a.Pos > b.Pos:
+			return 1
+		}
+		return 0
+	})
+	rgb := make([][3]float64, len(sorted))
+	for i, stop := range sorted {
+		if math.IsNaN(stop.Pos) || stop.Pos < 0 || stop.Pos > 1 {
+			return nil, nil, fmt.Errorf("stop %s: pos must be within 0-1", stop.Color)
+		}
+		c, err := parseHexColor(stop.Color)
+		if err != nil {
+			return nil, nil, err
+		}
+		rgb[i] = c
+		sorted[i].Color = strings.ToLower(strings.TrimSpace(stop.Color))
+	}
+
+	table := new(gradientTable)
+	next := 1
+	for i := range table {
+		pos := float64(i) / (gradientSteps - 1)
+		for next < len(sorted)-1 && pos > sorted[next].Pos {
+			next++
+		}
+		a, b := sorted[next-1], sorted[next]
+		t := 0.0
+		if b.Pos > a.Pos {
+			t = clamp01((pos - a.Pos) / (b.Pos - a.Pos))
+		} else if pos > b.Pos {
+			t = 1
+		}
+		ca, cb := rgb[next-1], rgb[next]
+		h, s, v := rgbToHSV(lerp(ca[0], cb[0], t), lerp(ca[1], cb[1], t), lerp(ca[2], cb[2], t))
+		table[i] = [3]float64{h, s, v}
+	}
+	return table, sorted, nil
+}
+
+// parseHexColor reads "#rrggbb" or "#rgb" into 0-1 channels.
+func parseHexColor(s string) ([3]float64, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return [3]float64{}, fmt.Errorf("color %q is not #rrggbb", s)
+	}
+	return [3]float64{float64(v>>16) / 255, float64(v>>8&0xff) / 255, float64(v&0xff) / 255}, nil
+}
+
+// rgbToHSV is the inverse of hsvToRGB.
+func rgbToHSV(r, g, b float64) (float64, float64, float64) {
+	hi := math.Max(r, math.Max(g, b))
+	lo := math.Min(r, math.Min(g, b))
+	delta := hi - lo
+	if hi <= 0 {
+		return 0, 0, 0
+	}
+	s := delta / hi
+	if delta == 0 {
+		return 0, s, hi
+	}
+	var h float64
+	switch hi {
+	case r:
+		h = (g - b) / delta
+		if h < 0 {
+			h += 6
+		}
+	case g:
+		h = (b-r)/delta + 2
+	default:
+		h = (r-g)/delta + 4
+	}
+	return h / 6, s, hi
+}
+
+// gradientColor returns the gradient's colour at brightness, from the table
+// compileCurve picked for this frame.
+func (r *Renderer) gradientColor(brightness float64) (float64, float64, float64) {
+	c := r.gradient[int(clamp01(brightness)*(gradientSteps-1)+0.5)]
+	return c[0], c[1], c[2]
+}
@@ -0,0 +1,80 @@
+package render
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestRGBToHSVRoundTrip(t *testing.T) {
+	for _, c := range [][3]float64{{1, 0, 0}, {0.2, 0.6, 0.4}, {0.1, 0.1, 0.9}, {0.5, 0.5, 0.5}, {0, 0, 0}, {1, 0.9, 0.1}} {
+		r, g, b := hsvToRGB(rgbToHSV(c[0], c[1], c[2]))
+		if math.Abs(r-c[0]) > 1e-9 || math.Abs(g-c[1]) > 1e-9 || math.Abs(b-c[2]) > 1e-9 {
+			t.Errorf("%v came back as %v %v %v", c, r, g, b)
+		}
+	}
+}
+
+func TestGradientInterpolatesStops(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "ripple", "gradient", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	if r.ColorModeName() != "gradient" {
+		t.Fatalf("mode %q", r.ColorModeName())
+	}
+	// out of order on purpose; the stops are sorted by position
+	if err := r.SetGradient([]GradientStop{{Color: "#0000FF", Pos: 1}, {Color: "#000", Pos: 0.5}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Gradient(); got[0].Color != "#000" || got[1].Color != "#0000ff" || !r.CustomGradient() {
+		t.Fatalf("stops %+v", got)
+	}
+	r.compileCurve()
+	p, _ := snapshotScene()
+	for _, tc := range []struct {
+		brightness, h, v float64
+	}{
+		{0, 0, 0},   // below the first stop
+		{0.5, 0, 0}, // at the first stop
+		{0.75, 2.0 / 3, 0.5},
+		{1, 2.0 / 3, 1},
+	} {
+		h, s, v := r.colorFromMode(0, tc.brightness, p, analyzer.Features{}, 1)
+		if tc.v > 0 && (math.Abs(h-tc.h) > 1e-9 || s != 1) || math.Abs(v-tc.v) > 0.01 {
+			t.Errorf("brightness %v: got %v %v %v", tc.brightness, h, s, v)
+		}
+	}
+
+	if err := r.SetGradient(nil); err != nil || r.CustomGradient() || len(r.Gradient()) != len(defaultGradient) {
+		t.Fatalf("reset: %v, %+v", err, r.Gradient())
+	}
+}
+
+func TestGradientRejectsBadStops(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "ripple", "gradient", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	for _, tc := range []struct {
+		stops []GradientStop
+		want  string
+	}{
+		{[]GradientStop{{Color: "#fff", Pos: 0}}, "needs 2-16 stops"},
+		{[]GradientStop{{Color: "#fff", Pos: 0}, {Color: "red", Pos: 1}}, `color "red"`},
+		{[]GradientStop{{Color: "#fff", Pos: 0}, {Color: "#12345g", Pos: 1}}, `color "#12345g"`},
+		{[]GradientStop{{Color: "#fff", Pos: 0}, {Color: "#000", Pos: 1.5}}, "pos must be within 0-1"},
+	} {
+		if err := r.SetGradient(tc.stops); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%+v: got %v, want %q", tc.stops, err, tc.want)
+		}
+	}
+	if r.CustomGradient() {
+		t.Error("rejected gradient was kept")
+	}
+	if err := r.SetColorCurve("gradient", ColorCurve{}); err == nil {
+		t.Error("curve accepted for the gradient mode")
+	}
+}
@@ -0,0 +1,65 @@
+package render
+
+import (
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+const (
+	// halfBlockRows is the pixels stacked in one cell.
+	halfBlockRows = 2
+	upperHalf     = '▀'
+	lowerHalf     = '▄'
+	fullBlock     = '█'
+	// halfBlockLit is the glyph value a pixel needs to show without colour.
+	halfBlockLit = 0.5
+)
+
+// halfBlockRow renders terminal row y as two stacked pixels per cell (the
+// pattern runs at width x 2*height): the upper pixel is the foreground of
+// '▀' and the lower one its background. Without colour each pixel is just
+// on or off.
+func (r *Renderer) halfBlockRow(builder *rowWriter, y, width, gridW int, xCoords, yCoords []float64, scale float64, p params.Parameters, ctx frameParams, feat analyzer.Features, activation float64, capture []uint8, useANSI bool) {
+	builder.Reset()
+	lastFg, lastBg := -1, -1
+	topY := y * halfBlockRows
+	vyTop := yCoords[topY] * scale
+	vyBottom := yCoords[topY+1] * scale
+	for x := 0; x < width; x++ {
+		vx := xCoords[x] * scale
+		topIdx := topY*gridW + x
+		bottomIdx := topIdx + gridW
+		top := r.evaluatePixel(vx, vyTop, p, ctx, feat, activation, nil, nil, topIdx)
+		bottom := r.evaluatePixel(vx, vyBottom, p, ctx, feat, activation, nil, nil, bottomIdx)
+		if capture != nil {
+			writePixel(capture[topIdx*4:topIdx*4+4:topIdx*4+4], top)
+			writePixel(capture[bottomIdx*4:bottomIdx*4+4:bottomIdx*4+4], bottom)
+		}
+
+		if useANSI {
+			if fg := hsvToANSI(top.h, top.s, top.v); fg != lastFg {
+				builder.WriteString(colorCode(fg))
+				lastFg = fg
+			}
+			if bg := hsvToANSI(bottom.h, bottom.s, bottom.v); bg != lastBg {
+				builder.WriteString(backgroundCode(bg))
+				lastBg = bg
+			}
+			builder.WriteRune(upperHalf)
+			continue
+		}
+		switch upper, lower := top.glyphValue >= halfBlockLit, bottom.glyphValue >= halfBlockLit; {
+		case upper && lower:
+			builder.WriteRune(fullBlock)
+		case upper:
+			builder.WriteRune(upperHalf)
+		case lower:
+			builder.WriteRune(lowerHalf)
+		default:
+			builder.WriteByte(' ')
+		}
+	}
+	if useANSI {
+		builder.WriteString(resetANSI)
+	}
+}
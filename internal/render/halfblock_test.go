@@ -0,0 +1,51 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestHalfBlockStacksTwoPixelsPerCell(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "halfblock", "ripple", "chromatic", "high", true, true)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	r.SetCapture(true)
+	p, feat := snapshotScene()
+	frame := r.Render(p, feat, 60)
+
+	if len(frame.Lines) != snapshotHeight {
+		t.Fatalf("got %d lines, want %d", len(frame.Lines), snapshotHeight)
+	}
+	for y, line := range frame.Lines {
+		if n := strings.Count(line, string(upperHalf)); n != snapshotWidth {
+			t.Fatalf("line %d has %d half blocks, want %d", y, n, snapshotWidth)
+		}
+	}
+	if !strings.Contains(frame.Lines[0], "\x1b[48;5;") {
+		t.Fatal("no background colours set")
+	}
+	if b := frame.Image.Rect; b.Dx() != snapshotWidth || b.Dy() != snapshotHeight*halfBlockRows {
+		t.Fatalf("capture is %v, want %dx%d pixels", b, snapshotWidth, snapshotHeight*halfBlockRows)
+	}
+}
+
+func TestHalfBlockWithoutColour(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "halfblock", "ripple", "chromatic", "high", true, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	p, feat := snapshotScene()
+	frame := r.Render(p, feat, 60)
+	for y, line := range frame.Lines {
+		if n := utf8.RuneCountInString(line); n != snapshotWidth {
+			t.Fatalf("line %d has %d cells, want %d", y, n, snapshotWidth)
+		}
+		if strings.ContainsFunc(line, func(ch rune) bool {
+			return ch != ' ' && ch != upperHalf && ch != lowerHalf && ch != fullBlock
+		}) {
+			t.Fatalf("line %d holds runes other than blocks: %q", y, line)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// BlendModes are the ways a layer of a layered pattern combines with the
+// layers below it.
+var BlendModes = []string{"add", "max", "multiply", "screen"}
+
+type blendMode int
+
+const (
+	blendAdd blendMode = iota
+	blendMax
+	blendMultiply
+	blendScreen
+)
+
+// maxPatternLayers bounds the patterns of a layered pattern.
+const maxPatternLayers = 4
+
+// patternLayer is one pattern of a layered pattern like "ripple+spark".
+type patternLayer struct {
+	name    string
+	fn      patternFunc
+	screen  screenPattern // set for screen-space patterns, which skip distort
+	mode    blendMode
+	opacity float64
+}
+
+// isLayeredPattern reports whether key stacks patterns. Expressions keep
+// their '+' to themselves.
+func isLayeredPattern(key string) bool {
+	return strings.Contains(key, "+") && !isExprPattern(key) && !isLuaPattern(key) && !isShaderPattern(key)
+}
+
+// CheckLayers parses a layered pattern such as "ripple+spark:screen@0.5":
+// built-in, plugin or screen patterns joined by '+', each after the first
+// with an optional blend mode (add by default) and every one with an
+// optional opacity from 0 to 1.
+func CheckLayers(name string) error {
+	_, err := parseLayers(strings.ToLower(strings.TrimSpace(name)))
+	return err
+}
+
+func parseLayers(key string) ([]patternLayer, error) {
+	parts := strings.Split(key, "+")
+	if len(parts) > maxPatternLayers {
+		return nil, fmt.Errorf("layers: %d patterns, the limit is %d", len(parts), maxPatternLayers)
+	}
+	layers := make([]patternLayer, 0, len(parts))
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		spec, opacityText, hasOpacity := strings.Cut(part, "@")
+		name, modeText, hasMode := strings.Cut(spec, ":")
+		layer := patternLayer{name: strings.TrimSpace(name), opacity: 1}
+
+		if hasOpacity {
+			v, err := strconv.ParseFloat(strings.TrimSpace(opacityText), 64)
+			if err != nil || v < 0 || v > 1 {
+				return nil, fmt.Errorf("layers: %s: opacity %q must be within 0-1", layer.name, opacityText)
+			}
+			layer.opacity = v
+		}
+		if hasMode {
+			if i == 0 {
+				return nil, fmt.Errorf("layers: %s: the first layer has no blend mode", layer.name)
+			}
+			mode := slices.Index(BlendModes, strings.TrimSpace(modeText))
+			if mode < 0 {
+				return nil, fmt.Errorf("layers: %s: unknown blend mode %q (want %s)", layer.name, modeText, strings.Join(BlendModes, ", "))
+			}
+			layer.mode = blendMode(mode)
+		}
+
+		if newScreen, ok := screenPatterns[layer.name]; ok {
+			screen := newScreen()
+			layer.screen = screen
+			layer.fn = func(x, y float64, _ params.Parameters, _ float64) float64 { return screen.eval(x, y) }
+		} else if entry, ok := patternRegistry[layer.name]; ok {
+			layer.fn = entry.fn
+		} else if layer.name == "" {
+			return nil, fmt.Errorf("layers: empty pattern in %q", key)
+		} else {
+			return nil, fmt.Errorf("layers: unknown pattern %q (lua, shader and expr patterns can't be layered)", layer.name)
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// layersName is the canonical name of layers, defaults left out.
+func layersName(layers []patternLayer) string {
+	var b strings.Builder
+	for i, l := range layers {
+		if i > 0 {
+			b.WriteByte('+')
+		}
+		b.WriteString(l.name)
+		if l.mode != blendAdd {
+			b.WriteString(":" + BlendModes[l.mode])
+		}
+		if l.opacity != 1 {
+			b.WriteString("@" + strconv.FormatFloat(l.opacity, 'g', -1, 64))
+		}
+	}
+	return b.String()
+}
+
+// configureLayers selects the layered pattern key, reporting whether it
+// parsed. Screen layers keep their state while the same stack stays on.
+func (r *Renderer) configureLayers(key string) bool {
+	layers, err := parseLayers(key)
+	if err != nil {
+		return false
+	}
+	name := layersName(layers)
+	if r.patternName != name || r.layers == nil {
+		for i := range layers {
+			if entry, ok := patternRegistry[layers[i].name]; ok {
+				layers[i].fn, _ = r.bindPattern(layers[i].name, entry)
+			}
+		}
+		r.layers, r.screenAt = layers, time.Time{}
+	}
+	r.pattern = r.layers[0].fn
+	r.patternName = name
+	r.detailMix = 0
+	return true
+}
+
+// evalLayers evaluates every one of layers at vx, vy and blends them bottom up, in
+// brightness terms (0-1) so the modes behave like their image-editor
+// namesakes.
+func (r *Renderer) evalLayers(layers []patternLayer, vx, vy float64, p params.Parameters, ctx frameParams) float64 {
+	var dx, dy float64
+	distorted := false
+	out := 0.0
+	for i := range layers {
+		l := &layers[i]
+		var v float64
+		if l.screen != nil {
+			v = l.screen.eval(vx/ctx.scale+0.5, vy/ctx.scale+0.5)
+		} else {
+			if !distorted {
+				dx, dy = r.distort(vx, vy, ctx)
+				distorted = true
+			}
+			v = l.fn(dx, dy, p, ctx.time)
+		}
+		v = (clampFloat(v, -1, 1) + 1) * 0.5
+		if i == 0 {
+			out = v * l.opacity
+			continue
+		}
+		out = blend(l.mode, out, v, l.opacity)
+	}
+	return out*2 - 1
+}
+
+// blend puts b at opacity over a, both 0-1.
+func blend(mode blendMode, a, b, opacity float64) float64 {
+	switch mode {
+	case blendMax:
+		return math.Max(a, b*opacity)
+	case blendMultiply:
+		return a * (1 - opacity*(1-b))
+	case blendScreen:
+		return 1 - (1-a)*(1-b*opacity)
+	}
+	return math.Min(a+b*opacity, 1)
+}
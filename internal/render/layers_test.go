@@ -0,0 +1,77 @@
+package render
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseLayers(t *testing.T) {
+	layers, err := parseLayers("ripple@1+spark:screen@0.50+ bars ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := layersName(layers); got != "ripple+spark:screen@0.5+bars" {
+		t.Fatalf("name %q", got)
+	}
+	if layers[2].screen == nil || layers[1].screen != nil {
+		t.Fatal("screen layer not set up")
+	}
+
+	for _, tc := range []struct{ key, want string }{
+		{"ripple+nope", `unknown pattern "nope"`},
+		{"ripple+lua:foo", `unknown blend mode "foo"`},
+		{"ripple:max+spark", "the first layer has no blend mode"},
+		{"ripple+spark@2", `opacity "2"`},
+		{"ripple++spark", "empty pattern"},
+		{"a+b+c+d+e", "the limit is 4"},
+	} {
+		if err := CheckLayers(tc.key); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%q: got %v, want %q", tc.key, err, tc.want)
+		}
+	}
+}
+
+func TestBlendModes(t *testing.T) {
+	for _, tc := range []struct {
+		mode              blendMode
+		a, b, opacity, is float64
+	}{
+		{blendAdd, 0.5, 0.75, 1, 1},
+		{blendAdd, 0.5, 0.4, 0.5, 0.7},
+		{blendMax, 0.5, 0.75, 1, 0.75},
+		{blendMax, 0.5, 0.75, 0.5, 0.5},
+		{blendMultiply, 0.5, 0.5, 1, 0.25},
+		{blendMultiply, 0.5, 0, 0, 0.5},
+		{blendScreen, 0.5, 0.5, 1, 0.75},
+	} {
+		if got := blend(tc.mode, tc.a, tc.b, tc.opacity); math.Abs(got-tc.is) > 1e-9 {
+			t.Errorf("%s(%v, %v @%v) = %v, want %v", BlendModes[tc.mode], tc.a, tc.b, tc.opacity, got, tc.is)
+		}
+	}
+}
+
+func TestConfigureLayers(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "Ripple+Flash:MAX", "chromatic", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	if r.PatternName() != "ripple+flash:max" || len(r.layers) != 2 {
+		t.Fatalf("pattern %q, %d layers", r.PatternName(), len(r.layers))
+	}
+	p, _ := snapshotScene()
+	ctx := r.buildFrameParams(p, p.Time)
+	for _, pt := range [][2]float64{{0, 0}, {0.1, -0.05}, {0.8, 0.4}} {
+		dx, dy := r.distort(pt[0], pt[1], ctx)
+		want := math.Max(patternRegistry["ripple"].fn(dx, dy, p, ctx.time), patternFlash(dx, dy, p, ctx.time))
+		if got := r.evalLayers(r.layers, pt[0], pt[1], p, ctx); math.Abs(got-clampFloat(want, -1, 1)) > 1e-9 {
+			t.Errorf("at %v: %v, want %v", pt, got, want)
+		}
+	}
+
+	// a stack that doesn't parse falls back to ripple
+	r.Configure("default", "ripple+nope", "chromatic", false)
+	if r.PatternName() != "ripple" || r.layers != nil {
+		t.Fatalf("fallback %q", r.PatternName())
+	}
+}
@@ -0,0 +1,137 @@
+package render
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+const (
+	// lifeRate is how many generations run per second when it's quiet;
+	// energy speeds it up to lifeRate*(1+lifeRateBoost).
+	lifeRate      = 8.0
+	lifeRateBoost = 2.0
+	// lifeSoup is the share of cells alive after a reset.
+	lifeSoup = 0.25
+	// lifeMutation is the chance per cell and generation that a cell flips
+	// at full energy.
+	lifeMutation = 0.002
+	// lifeFade is how fast a dead cell's afterglow fades per second when
+	// it's quiet; energy clears the trails faster.
+	lifeFade = 2.0
+	// lifeSeedRadius is the size of the patch a beat seeds, as a share of
+	// the grid height.
+	lifeSeedRadius = 0.08
+)
+
+// lifePattern is Conway's game of life on the render grid, wrapping at the
+// edges. Beats seed patches of new cells where they land, the overall
+// energy speeds up generations and mutations, and cells that die leave a
+// fading trail.
+type lifePattern struct {
+	alive []bool
+	next  []bool
+	glow  []float64 // afterglow of dead cells, 1 when they just died
+	due   float64   // generations owed to the elapsed time
+	rng   *rand.Rand
+
+	gridW, gridH int
+}
+
+func (l *lifePattern) prepare(feat analyzer.Features, dt float64, gridW, gridH int) {
+	if gridW != l.gridW || gridH != l.gridH {
+		l.reset(gridW, gridH)
+	}
+	energy := clamp01(feat.Overall)
+	if feat.Onset {
+		l.seed(feat.BeatStrength)
+	}
+
+	fade := math.Exp(-dt * lifeFade * (1 + 2*energy))
+	for i := range l.glow {
+		l.glow[i] *= fade
+	}
+	l.due += dt * lifeRate * (1 + lifeRateBoost*energy)
+	for ; l.due >= 1; l.due-- {
+		l.generation(energy * lifeMutation)
+	}
+}
+
+// reset fills a gridW x gridH grid with a random soup. The seed is fixed
+// so a resize or a restart looks the same.
+func (l *lifePattern) reset(gridW, gridH int) {
+	l.gridW, l.gridH = gridW, gridH
+	n := gridW * gridH
+	l.alive, l.next, l.glow = make([]bool, n), make([]bool, n), make([]float64, n)
+	l.rng = rand.New(rand.NewSource(1))
+	for i := range l.alive {
+		l.alive[i] = l.rng.Float64() < lifeSoup
+	}
+}
+
+// seed drops a random patch of live cells somewhere on the grid, larger
+// for stronger beats.
+func (l *lifePattern) seed(strength float64) {
+	if l.gridW == 0 || l.gridH == 0 {
+		return
+	}
+	radius := max(int(float64(l.gridH)*lifeSeedRadius*(0.5+clamp01(strength))), 2)
+	cx, cy := l.rng.Intn(l.gridW), l.rng.Intn(l.gridH)
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if dx*dx+dy*dy > radius*radius || l.rng.Float64() >= 0.5 {
+				continue
+			}
+			l.alive[l.index(cx+dx, cy+dy)] = true
+		}
+	}
+}
+
+// generation advances the grid once, flipping each cell with chance
+// mutation afterwards.
+func (l *lifePattern) generation(mutation float64) {
+	w, h := l.gridW, l.gridH
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			n := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if (dx != 0 || dy != 0) && l.alive[l.index(x+dx, y+dy)] {
+						n++
+					}
+				}
+			}
+			i := y*w + x
+			l.next[i] = n == 3 || n == 2 && l.alive[i]
+			if mutation > 0 && l.rng.Float64() < mutation {
+				l.next[i] = !l.next[i]
+			}
+			if l.alive[i] && !l.next[i] {
+				l.glow[i] = 1
+			}
+		}
+	}
+	l.alive, l.next = l.next, l.alive
+}
+
+// index returns the cell at x, y, wrapping around the edges.
+func (l *lifePattern) index(x, y int) int {
+	x = (x%l.gridW + l.gridW) % l.gridW
+	y = (y%l.gridH + l.gridH) % l.gridH
+	return y*l.gridW + x
+}
+
+func (l *lifePattern) eval(x, y float64) float64 {
+	if l.gridW == 0 || l.gridH == 0 {
+		return -1
+	}
+	i := gridCell(y, l.gridH)*l.gridW + gridCell(x, l.gridW)
+	if l.alive[i] {
+		return 1
+	}
+	if l.glow[i] > 0.05 {
+		return l.glow[i]*1.2 - 1
+	}
+	return -1
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestLifeBlinker(t *testing.T) {
+	var l lifePattern
+	l.reset(5, 5)
+	clear(l.alive)
+	for x := 1; x <= 3; x++ {
+		l.alive[l.index(x, 2)] = true
+	}
+
+	// one generation's worth of quiet time
+	l.prepare(analyzer.Features{}, 1.0/lifeRate, 5, 5)
+	for y := 1; y <= 3; y++ {
+		if !l.alive[l.index(2, y)] {
+			t.Fatalf("blinker didn't turn upright at 2,%d", y)
+		}
+	}
+	if l.alive[l.index(1, 2)] || l.glow[l.index(1, 2)] != 1 {
+		t.Fatal("the dead end cell has no afterglow")
+	}
+	if got := l.eval(0.2, 0.4); got <= -1 || got >= 1 {
+		t.Fatalf("afterglow evaluates to %v", got)
+	}
+	if got := l.eval(0.4, 0.2); got != 1 {
+		t.Fatalf("live cell evaluates to %v", got)
+	}
+}
+
+func TestLifeBeatSeeds(t *testing.T) {
+	var l lifePattern
+	l.reset(60, 40)
+	clear(l.alive)
+	l.prepare(analyzer.Features{Onset: true, BeatStrength: 1}, 0, 60, 40)
+	n := 0
+	for _, alive := range l.alive {
+		if alive {
+			n++
+		}
+	}
+	if n < 10 {
+		t.Fatalf("a beat seeded %d cells", n)
+	}
+
+	// a new size starts over with a fresh soup
+	l.prepare(analyzer.Features{}, 0, 30, 20)
+	if len(l.alive) != 600 {
+		t.Fatalf("grid has %d cells after resizing", len(l.alive))
+	}
+}
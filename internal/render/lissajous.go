@@ -0,0 +1,105 @@
+package render
+
+import (
+	"math"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+const (
+	// lissajousPersistence is the time constant of the trace's afterglow,
+	// like the phosphor of an analogue goniometer.
+	lissajousPersistence = 0.12
+	// lissajousGlowFloor is the afterglow below which a cell is black.
+	lissajousGlowFloor = 0.03
+	// lissajousMeterRows is the grid height per row of the correlation
+	// meter.
+	lissajousMeterRows = 16
+)
+
+// lissajousPattern is a goniometer: the input's side plotted against its
+// mid in a square, so a mono mix stands as a vertical line, a wide one
+// spreads into a cloud and out of phase content lies down. Below it a
+// meter shows the correlation of left and right, from -1 on the left to 1
+// on the right.
+type lissajousPattern struct {
+	glow   []float64
+	aspect int
+
+	gridW, gridH int
+	// the square, in grid cells
+	left, top, size, width int
+	// the meter rows and how far from its centre the bar reaches
+	meterTop, meterRows int
+	corrLo, corrHi      int
+}
+
+func (l *lissajousPattern) setAspect(aspect int) {
+	if aspect != l.aspect {
+		l.aspect, l.gridW = aspect, 0
+	}
+}
+
+func (l *lissajousPattern) prepare(feat analyzer.Features, dt float64, gridW, gridH int) {
+	if gridW != l.gridW || gridH != l.gridH || len(l.glow) != gridW*gridH {
+		l.layout(gridW, gridH)
+	}
+	if l.size < 2 {
+		return
+	}
+
+	fade := math.Exp(-dt / lissajousPersistence)
+	for i := range l.glow {
+		l.glow[i] *= fade
+	}
+	cell := func(p [2]float64) (float64, float64) {
+		return float64(l.left) + (p[0]+1)*0.5*float64(l.width-1),
+			float64(l.top) + (1-p[1])*0.5*float64(l.size-1)
+	}
+	x0, y0 := cell(feat.Goniometer[0])
+	for _, p := range feat.Goniometer[1:] {
+		x1, y1 := cell(p)
+		steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0))) + 1
+		for s := 0; s <= steps; s++ {
+			f := float64(s) / float64(steps)
+			x, y := int(math.Round(lerp(x0, x1, f))), int(math.Round(lerp(y0, y1, f)))
+			l.glow[y*l.gridW+x] = 1
+		}
+		x0, y0 = x1, y1
+	}
+
+	mid := l.left + l.width/2
+	at := l.left + int(math.Round((clampFloat(feat.Correlation, -1, 1)+1)*0.5*float64(l.width-1)))
+	l.corrLo, l.corrHi = min(mid, at), max(mid, at)
+}
+
+// layout fits the square and the meter below it into the grid.
+func (l *lissajousPattern) layout(gridW, gridH int) {
+	l.gridW, l.gridH = gridW, gridH
+	l.glow = make([]float64, gridW*gridH)
+	aspect := max(l.aspect, 1)
+	l.meterRows = max(gridH/lissajousMeterRows, 1)
+	plotH := gridH - 2*l.meterRows
+	l.size = min(plotH, gridW/aspect)
+	l.width = l.size * aspect
+	l.left = (gridW - l.width) / 2
+	l.top = (plotH - l.size) / 2
+	l.meterTop = gridH - l.meterRows
+}
+
+func (l *lissajousPattern) eval(x, y float64) float64 {
+	if l.size < 2 {
+		return -1
+	}
+	cx, cy := gridCell(x, l.gridW), gridCell(y, l.gridH)
+	if cy >= l.meterTop {
+		if cx < l.corrLo || cx > l.corrHi {
+			return -1
+		}
+		return 1
+	}
+	if g := l.glow[cy*l.gridW+cx]; g > lissajousGlowFloor {
+		return g*2 - 1
+	}
+	return -1
+}
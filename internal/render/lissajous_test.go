@@ -0,0 +1,40 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestLissajousMonoIsVertical(t *testing.T) {
+	var l lissajousPattern
+	l.setAspect(2)
+	var feat analyzer.Features
+	for j := range feat.Goniometer {
+		feat.Goniometer[j] = [2]float64{0, float64(j)/(analyzer.GoniometerPoints-1)*2 - 1}
+	}
+	feat.Correlation = 1
+	l.prepare(feat, 1.0/60, 80, 32)
+
+	// 32 rows leave a 28 row square, 56 cells wide, over two meter rows
+	if l.size != 28 || l.width != 56 || l.left != 12 || l.meterTop != 30 {
+		t.Fatalf("layout size %d width %d left %d meter %d", l.size, l.width, l.left, l.meterTop)
+	}
+	center := l.left + l.width/2
+	for y := l.top; y < l.top+l.size; y++ {
+		for x := 0; x < 80; x++ {
+			if l.glow[y*80+x] > lissajousGlowFloor && x != center {
+				t.Fatalf("cell %d,%d lit off the centre line", x, y)
+			}
+		}
+	}
+	if l.corrLo != center || l.corrHi != l.left+l.width-1 {
+		t.Fatalf("correlation bar %d-%d", l.corrLo, l.corrHi)
+	}
+
+	// the trace fades once the input stops
+	l.prepare(analyzer.Features{}, 1, 80, 32)
+	if g := l.glow[(l.top)*80+center]; g > lissajousGlowFloor {
+		t.Fatalf("glow %v after a second", g)
+	}
+}
@@ -0,0 +1,327 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// LuaPrefix marks a pattern name that refers to a Lua script, "lua:name"
+// loading name.lua from the Lua pattern directory. Scripts define
+//
+//	function pattern(x, y, t) ... end
+//
+// returning the intensity at x, y like the built-in patterns, and read the
+// frame's values from the params and features tables.
+const LuaPrefix = "lua:"
+
+const (
+	// luaExt is the extension of Lua pattern files.
+	luaExt = ".lua"
+	// luaReloadEvery is how often the script's modification time is checked.
+	luaReloadEvery = 250 * time.Millisecond
+	// luaMaxStates bounds the interpreters kept for the render workers.
+	luaMaxStates = 8
+)
+
+// isLuaPattern reports whether name selects a Lua script.
+func isLuaPattern(name string) bool {
+	return strings.HasPrefix(name, LuaPrefix)
+}
+
+// LuaNames lists the Lua patterns in dir as pattern names, sorted. A missing
+// directory has none.
+func LuaNames(dir string) []string {
+	return patternFiles(dir, luaExt, LuaPrefix)
+}
+
+// SetLuaDir sets where "lua:" patterns are loaded from.
+func (r *Renderer) SetLuaDir(dir string) {
+	r.luaDir = dir
+}
+
+// LuaDir returns the Lua pattern directory.
+func (r *Renderer) LuaDir() string { return r.luaDir }
+
+// luaPattern runs one script. Every render worker needs an interpreter of
+// its own, so compiled scripts are instantiated per worker and pooled. The
+// file is reloaded when it changes; an edit that fails to compile keeps the
+// last good version running.
+type luaPattern struct {
+	path    string
+	proto   *lua.FunctionProto
+	gen     int // bumped on reload, states of an older gen are dropped
+	mod     time.Time
+	checked time.Time
+	states  chan *luaState
+
+	// per frame, written by prepare before the workers start
+	frame uint64
+	p     params.Parameters
+	feat  analyzer.Features
+
+	loadErr error // the file is missing or doesn't compile
+	// failedGen is gen+1 once the script failed to start, so the workers
+	// don't retry it for every pixel
+	failedGen atomic.Int64
+	errMu     sync.Mutex
+	startErr  error // why the failed gen didn't start
+	runErr    error // the script failed during this frame
+}
+
+// luaState is one interpreter with the script loaded.
+type luaState struct {
+	L        *lua.LState
+	gen      int
+	frame    uint64
+	fn       *lua.LFunction
+	params   *lua.LTable
+	features *lua.LTable
+}
+
+func newLuaPattern(path string) *luaPattern {
+	return &luaPattern{path: path, states: make(chan *luaState, luaMaxStates)}
+}
+
+// prepareLua loads or reloads the current Lua pattern and hands it the
+// frame's values. It runs before the workers start.
+func (r *Renderer) prepareLua(now time.Time, p params.Parameters, feat analyzer.Features) {
+	if !isLuaPattern(r.patternName) {
+		if r.lua != nil {
+			r.lua.close()
+			r.lua = nil
+		}
+		return
+	}
+	path, err := patternPath(r.luaDir, LuaPrefix, luaExt, r.patternName)
+	if r.lua == nil || r.lua.path != path {
+		if r.lua != nil {
+			r.lua.close()
+		}
+		r.lua = newLuaPattern(path)
+	}
+	if err != nil {
+		r.lua.loadErr = err
+		return
+	}
+	r.lua.prepare(now, p, feat)
+}
+
+func (lp *luaPattern) prepare(now time.Time, p params.Parameters, feat analyzer.Features) {
+	lp.frame++
+	lp.p, lp.feat = p, feat
+	lp.setErr(nil)
+	if now.Sub(lp.checked) < luaReloadEvery {
+		return
+	}
+	lp.checked = now
+	info, err := os.Stat(lp.path)
+	if err != nil {
+		lp.loadErr = err
+		return
+	}
+	if info.ModTime().Equal(lp.mod) {
+		return
+	}
+	lp.mod = info.ModTime()
+	proto, err := compileLua(lp.path)
+	if err != nil {
+		lp.loadErr = err
+		return
+	}
+	lp.proto = proto
+	lp.gen++
+	lp.loadErr = nil
+}
+
+// compileLua parses and compiles a script file.
+func compileLua(path string) (*lua.FunctionProto, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	chunk, err := parse.Parse(bytes.NewReader(data), path)
+	if err != nil {
+		return nil, err
+	}
+	return lua.Compile(chunk, path)
+}
+
+// eval is the pattern function of a Lua pattern. It is called from the
+// render workers.
+func (lp *luaPattern) eval(x, y float64, _ params.Parameters, t float64) float64 {
+	if lp == nil || lp.proto == nil || lp.failedGen.Load() == int64(lp.gen)+1 {
+		return -1
+	}
+	s := lp.get()
+	if s == nil {
+		return -1
+	}
+	defer lp.put(s)
+	if s.frame != lp.frame {
+		s.frame = lp.frame
+		setLuaParams(s.params, lp.p)
+		setLuaFeatures(s.features, lp.feat)
+	}
+	err := s.L.CallByParam(lua.P{Fn: s.fn, NRet: 1, Protect: true}, lua.LNumber(x), lua.LNumber(y), lua.LNumber(t))
+	if err != nil {
+		lp.setErr(err)
+		return -1
+	}
+	ret := s.L.Get(-1)
+	s.L.Pop(1)
+	if v, ok := ret.(lua.LNumber); ok {
+		return float64(v)
+	}
+	return -1
+}
+
+// get takes an interpreter of the current script from the pool, or
+// starts one.
+func (lp *luaPattern) get() *luaState {
+	for {
+		select {
+		case s := <-lp.states:
+			if s.gen == lp.gen {
+				return s
+			}
+			s.L.Close()
+			continue
+		default:
+		}
+		break
+	}
+	s, err := lp.newState()
+	if err != nil {
+		lp.errMu.Lock()
+		lp.startErr = err
+		lp.errMu.Unlock()
+		lp.failedGen.Store(int64(lp.gen) + 1)
+		return nil
+	}
+	return s
+}
+
+func (lp *luaPattern) put(s *luaState) {
+	select {
+	case lp.states <- s:
+	default:
+		s.L.Close()
+	}
+}
+
+// newState runs the script in a fresh interpreter with the safe standard
+// libraries (no io or os) and looks up its pattern function.
+func (lp *luaPattern) newState() (*luaState, error) {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	s := &luaState{L: L, gen: lp.gen, params: L.NewTable(), features: L.NewTable()}
+	L.SetGlobal("params", s.params)
+	L.SetGlobal("features", s.features)
+	setLuaParams(s.params, lp.p)
+	setLuaFeatures(s.features, lp.feat)
+	s.frame = lp.frame
+
+	L.Push(L.NewFunctionFromProto(lp.proto))
+	if err := L.PCall(0, 0, nil); err != nil {
+		L.Close()
+		return nil, err
+	}
+	fn, ok := L.GetGlobal("pattern").(*lua.LFunction)
+	if !ok {
+		L.Close()
+		return nil, fmt.Errorf("%s: no pattern(x, y, t) function", lp.path)
+	}
+	s.fn = fn
+	return s, nil
+}
+
+func setLuaParams(t *lua.LTable, p params.Parameters) {
+	t.RawSetString("time", lua.LNumber(p.Time))
+	t.RawSetString("frequency", lua.LNumber(p.Frequency))
+	t.RawSetString("amplitude", lua.LNumber(p.Amplitude))
+	t.RawSetString("speed", lua.LNumber(p.Speed))
+	t.RawSetString("scale", lua.LNumber(p.Scale))
+	t.RawSetString("color_shift", lua.LNumber(p.ColorShift))
+	t.RawSetString("brightness", lua.LNumber(p.Brightness))
+	t.RawSetString("beat_distortion", lua.LNumber(p.BeatDistortion))
+	t.RawSetString("beat_zoom", lua.LNumber(p.BeatZoom))
+	t.RawSetString("noise_strength", lua.LNumber(p.NoiseStrength))
+}
+
+func setLuaFeatures(t *lua.LTable, f analyzer.Features) {
+	t.RawSetString("sub", lua.LNumber(f.Sub))
+	t.RawSetString("bass", lua.LNumber(f.Bass))
+	t.RawSetString("low_mid", lua.LNumber(f.LowMid))
+	t.RawSetString("mid", lua.LNumber(f.Mid))
+	t.RawSetString("high_mid", lua.LNumber(f.HighMid))
+	t.RawSetString("treble", lua.LNumber(f.Treble))
+	t.RawSetString("energy", lua.LNumber(f.Overall))
+	t.RawSetString("beat", lua.LNumber(f.BeatStrength))
+	t.RawSetString("drop", lua.LBool(f.IsDrop))
+	t.RawSetString("onset", lua.LBool(f.Onset))
+	t.RawSetString("tempo", lua.LNumber(f.Tempo))
+}
+
+// setErr records a script error of the current frame; the first one wins.
+func (lp *luaPattern) setErr(err error) {
+	lp.errMu.Lock()
+	if err == nil || lp.runErr == nil {
+		lp.runErr = err
+	}
+	lp.errMu.Unlock()
+}
+
+// Err returns why the script doesn't load or start, or else its first
+// error of the last frame, flattened to one line. Call it between frames.
+func (lp *luaPattern) Err() error {
+	if lp == nil {
+		return nil
+	}
+	err := lp.loadErr
+	if err == nil {
+		lp.errMu.Lock()
+		if lp.failedGen.Load() == int64(lp.gen)+1 {
+			err = lp.startErr
+		} else {
+			err = lp.runErr
+		}
+		lp.errMu.Unlock()
+	}
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(strings.Fields(err.Error()), " "))
+}
+
+func (lp *luaPattern) close() {
+	for {
+		select {
+		case s := <-lp.states:
+			s.L.Close()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+func writeLua(t *testing.T, path, src string, mod time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLuaPatternReadsFrameValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sum.lua")
+	start := time.Now()
+	writeLua(t, path, "function pattern(x, y, t) return x + y + t + features.bass + params.amplitude end", start)
+
+	lp := newLuaPattern(path)
+	lp.prepare(start, params.Parameters{Amplitude: 0.25}, analyzer.Features{Bass: 0.5})
+	if got := lp.eval(1, 2, params.Parameters{}, 3); got != 6.75 {
+		t.Fatalf("got %v, want 6.75 (err %v)", got, lp.Err())
+	}
+	lp.prepare(start, params.Parameters{}, analyzer.Features{Bass: 1})
+	if got := lp.eval(0, 0, params.Parameters{}, 0); got != 1 {
+		t.Fatalf("features not refreshed: got %v", got)
+	}
+}
+
+func TestLuaPatternHotReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reload.lua")
+	start := time.Now()
+	writeLua(t, path, "function pattern(x, y, t) return 1 end", start.Add(-time.Hour))
+
+	lp := newLuaPattern(path)
+	lp.prepare(start, params.Parameters{}, analyzer.Features{})
+	if got := lp.eval(0, 0, params.Parameters{}, 0); got != 1 {
+		t.Fatalf("got %v", got)
+	}
+
+	writeLua(t, path, "function pattern(x, y, t) return 2 end", start)
+	lp.prepare(start.Add(time.Second), params.Parameters{}, analyzer.Features{})
+	if got := lp.eval(0, 0, params.Parameters{}, 0); got != 2 {
+		t.Fatalf("edit not picked up: got %v", got)
+	}
+
+	writeLua(t, path, "function pattern(x, y, t) return end end", start.Add(time.Minute))
+	lp.prepare(start.Add(2*time.Second), params.Parameters{}, analyzer.Features{})
+	if lp.Err() == nil {
+		t.Fatal("syntax error not reported")
+	}
+	if got := lp.eval(0, 0, params.Parameters{}, 0); got != 2 {
+		t.Fatalf("broken edit replaced the last good script: got %v", got)
+	}
+}
+
+func TestLuaPatternErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeLua(t, filepath.Join(dir, "nofn.lua"), "x = 1", time.Now())
+	writeLua(t, filepath.Join(dir, "boom.lua"), "function pattern(x, y, t) return nil + 1 end", time.Now())
+
+	r, err := New(snapshotWidth, snapshotHeight, "default", "lua:nofn", "chromatic", "high", true, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	r.SetLuaDir(dir)
+	if r.PatternName() != "lua:nofn" {
+		t.Fatalf("pattern %q", r.PatternName())
+	}
+	frame := r.Render(params.Defaults(), analyzer.Features{}, 60)
+	if !strings.Contains(frame.Status, "no pattern(x, y, t) function") {
+		t.Fatalf("status %q", frame.Status)
+	}
+
+	r.Configure("default", "lua:boom", "chromatic", true)
+	frame = r.Render(params.Defaults(), analyzer.Features{}, 60)
+	if !strings.Contains(frame.Status, "boom.lua") {
+		t.Fatalf("runtime error not in status %q", frame.Status)
+	}
+
+	if got := r.PatternNames(); got[len(got)-1] != "lua:nofn" || got[len(got)-2] != "lua:boom" {
+		t.Fatalf("lua patterns not offered: %v", got)
+	}
+}
@@ -0,0 +1,23 @@
+package render
+
+import (
+	"image"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// renderNone draws the frame into an image and nowhere else: the none
+// backend has no screen, its frames only feed the outputs.
+func (r *Renderer) renderNone(p params.Parameters, feat analyzer.Features, fps float64, ctx frameParams, activation float64, xCoords, yCoords []float64, scale float64) Frame {
+	gridW, gridH := len(xCoords), len(yCoords)
+	if r.captureImg == nil || r.captureImg.Rect.Dx() != gridW || r.captureImg.Rect.Dy() != gridH {
+		r.captureImg = image.NewRGBA(image.Rect(0, 0, gridW, gridH))
+	}
+	r.fillImage(r.captureImg, p, feat, ctx, activation, xCoords, yCoords, scale)
+	return Frame{
+		Status:  r.buildStatus(feat, fps),
+		Image:   r.captureImg,
+		Present: func(string) error { return nil },
+	}
+}
@@ -0,0 +1,30 @@
+package render
+
+import "testing"
+
+func TestNoneBackendRendersImageOnly(t *testing.T) {
+	r, err := NewWithBackend(BackendNone, 24, 12, "default", "plasma", "chromatic", "balanced", true, true)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	p, feat := snapshotScene()
+	frame := r.Render(p, feat, 60)
+	if frame.Image == nil {
+		t.Fatal("expected an image without SetCapture")
+	}
+	if got := frame.Image.Rect.Size(); got.X != 24 || got.Y != 12 {
+		t.Fatalf("image size = %v, want 24x12", got)
+	}
+	if frame.Lines != nil {
+		t.Fatalf("expected no text rows, got %d", len(frame.Lines))
+	}
+	if frame.Present == nil {
+		t.Fatal("expected a Present that draws nothing")
+	}
+	if err := frame.Present("status"); err != nil {
+		t.Fatalf("present: %v", err)
+	}
+	if r.IsWindowed() {
+		t.Fatal("none backend reports a window")
+	}
+}
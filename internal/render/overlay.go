@@ -0,0 +1,213 @@
+package render
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Overlay is text composited over the visuals: a track title, a message or
+// a countdown. It stays until replaced or cleared, or for Duration seconds.
+type Overlay struct {
+	Text string `json:"text"`
+	// Color is "#rrggbb"; empty takes a colour from the colour mode.
+	Color string `json:"color,omitempty"`
+	// Blink is how often the text blinks per second, 0 for steady.
+	Blink float64 `json:"blink,omitempty"`
+	// Position is top, center or bottom (the default).
+	Position string `json:"position,omitempty"`
+	// Countdown counts down from this many seconds. The time left replaces
+	// {countdown} in Text, or follows it without the placeholder.
+	Countdown float64 `json:"countdown,omitempty"`
+	// Duration hides the overlay after this many seconds, 0 keeps it.
+	Duration float64 `json:"duration,omitempty"`
+}
+
+// OverlayPositions are the valid Overlay positions.
+var OverlayPositions = []string{"top", "center", "bottom"}
+
+const (
+	// overlayMaxText bounds the text of an overlay, in runes.
+	overlayMaxText = 200
+	// overlayMaxBlink is the fastest blink rate, in blinks per second.
+	overlayMaxBlink = 10
+	// overlayMaxSeconds bounds Countdown and Duration.
+	overlayMaxSeconds = 24 * 60 * 60
+	// countdownPlaceholder is where the time left goes in the text.
+	countdownPlaceholder = "{COUNTDOWN}"
+)
+
+// overlayLayer draws the Overlay. The config is set from other goroutines
+// under mu; the rest is frame state owned by Render.
+type overlayLayer struct {
+	mu    sync.Mutex
+	cfg   Overlay
+	since time.Time
+	color [3]float64 // cfg.Color as HSV
+
+	on     bool
+	custom bool
+	hsv    [3]float64
+	hue    float64
+	mask   []bool
+	width  int
+	height int
+	built  string
+}
+
+// SetOverlay shows o over the visuals from the next frame, replacing the
+// previous overlay. An overlay without text or countdown clears it.
+func (r *Renderer) SetOverlay(o Overlay) error {
+	o.Text = strings.TrimSpace(o.Text)
+	o.Color = strings.ToLower(strings.TrimSpace(o.Color))
+	o.Position = strings.ToLower(strings.TrimSpace(o.Position))
+	if o.Text == "" && o.Countdown == 0 {
+		r.ClearOverlay()
+		return nil
+	}
+	if n := len([]rune(o.Text)); n > overlayMaxText {
+		return fmt.Errorf("overlay: text is %d characters, the limit is %d", n, overlayMaxText)
+	}
+	if o.Position == "" {
+		o.Position = "bottom"
+	}
+	if !slices.Contains(OverlayPositions, o.Position) {
+		return fmt.Errorf("overlay: unknown position %q (want %s)", o.Position, strings.Join(OverlayPositions, ", "))
+	}
+	var hsv [3]float64
+	if o.Color != "" {
+		rgb, err := parseHexColor(o.Color)
+		if err != nil {
+			return fmt.Errorf("overlay: %w", err)
+		}
+		hsv[0], hsv[1], hsv[2] = rgbToHSV(rgb[0], rgb[1], rgb[2])
+	}
+	for _, v := range []struct {
+		name     string
+		val, max float64
+	}{
+		{"blink", o.Blink, overlayMaxBlink},
+		{"countdown", o.Countdown, overlayMaxSeconds},
+		{"duration", o.Duration, overlayMaxSeconds},
+	} {
+		if math.IsNaN(v.val) || v.val < 0 || v.val > v.max {
+			return fmt.Errorf("overlay: %s must be within 0-%g", v.name, v.max)
+		}
+	}
+
+	l := &r.overlay
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cfg = o
+	l.since = r.now()
+	l.color = hsv
+	return nil
+}
+
+// ClearOverlay hides the overlay.
+func (r *Renderer) ClearOverlay() {
+	l := &r.overlay
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cfg = Overlay{}
+}
+
+// Overlay returns the overlay on screen with Countdown and Duration counted
+// down to what is left, or the zero Overlay when there is none.
+func (r *Renderer) Overlay() Overlay {
+	l := &r.overlay
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	o, ok := l.current(time.Now())
+	if !ok {
+		return Overlay{}
+	}
+	elapsed := time.Since(l.since).Seconds()
+	if o.Countdown > 0 {
+		o.Countdown = math.Max(o.Countdown-elapsed, 0)
+	}
+	if o.Duration > 0 {
+		o.Duration = math.Max(o.Duration-elapsed, 0)
+	}
+	return o
+}
+
+// current returns the config, dropping it once its duration ran out. The
+// caller holds mu.
+func (l *overlayLayer) current(now time.Time) (Overlay, bool) {
+	if l.cfg.Text == "" && l.cfg.Countdown == 0 {
+		return Overlay{}, false
+	}
+	if l.cfg.Duration > 0 && now.Sub(l.since).Seconds() >= l.cfg.Duration {
+		l.cfg = Overlay{}
+		return Overlay{}, false
+	}
+	return l.cfg, true
+}
+
+// prepare works out the overlay for the frame at now and rebuilds the mask
+// when its text, position or the frame size changed.
+func (l *overlayLayer) prepare(now time.Time, width, height, aspect int) {
+	l.mu.Lock()
+	o, ok := l.current(now)
+	since, hsv := l.since, l.color
+	l.mu.Unlock()
+
+	elapsed := now.Sub(since).Seconds()
+	l.on = ok && (o.Blink <= 0 || math.Mod(elapsed*o.Blink, 1) < 0.5)
+	if !l.on {
+		return
+	}
+	text := overlayText(o, elapsed)
+	l.custom, l.hsv = o.Color != "", hsv
+
+	key := o.Position + "|" + text
+	if l.built == key && l.width == width && l.height == height {
+		return
+	}
+	h := fnv.New32a()
+	h.Write([]byte(o.Text))
+	l.hue = float64(h.Sum32()%2000)/1000.0 - 1.0
+	l.built, l.width, l.height = key, width, height
+	if len(l.mask) != width*height {
+		l.mask = make([]bool, width*height)
+	} else {
+		clear(l.mask)
+	}
+	band := max(height/4, min(glyphHeight+1, height))
+	switch o.Position {
+	case "top":
+		layoutTextIn(l.mask, width, height, aspect, text, 0, band)
+	case "center":
+		layoutTextIn(l.mask, width, height, aspect, text, 0, height)
+	default:
+		layoutTextIn(l.mask, width, height, aspect, text, height-band, band)
+	}
+}
+
+// covers reports whether cell idx is lit by the overlay this frame.
+func (l *overlayLayer) covers(idx int) bool {
+	return l.on && idx >= 0 && idx < len(l.mask) && l.mask[idx]
+}
+
+// overlayText is the text of o elapsed seconds after it was set, in the
+// capitals the font has.
+func overlayText(o Overlay, elapsed float64) string {
+	text := foldGlyphs.Replace(strings.ToUpper(o.Text))
+	if o.Countdown <= 0 {
+		return text
+	}
+	left := int(math.Ceil(math.Max(o.Countdown-elapsed, 0)))
+	clock := fmt.Sprintf("%d:%02d", left/60, left%60)
+	if left >= 3600 {
+		clock = fmt.Sprintf("%d:%02d:%02d", left/3600, left/60%60, left%60)
+	}
+	if strings.Contains(text, countdownPlaceholder) {
+		return strings.ReplaceAll(text, countdownPlaceholder, clock)
+	}
+	return strings.TrimSpace(text + " " + clock)
+}
@@ -0,0 +1,83 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOverlayText(t *testing.T) {
+	for _, tc := range []struct {
+		o       Overlay
+		elapsed float64
+		want    string
+	}{
+		{Overlay{Text: "Canción"}, 5, "CANCION"},
+		{Overlay{Text: "I ❤️ U 🎶⭐"}, 0, "I ♥ U ♪★"},
+		{Overlay{Text: "drop in {countdown}!", Countdown: 90}, 0.5, "DROP IN 1:30!"},
+		{Overlay{Text: "doors", Countdown: 3700}, 0, "DOORS 1:01:40"},
+		{Overlay{Countdown: 10}, 12, "0:00"},
+	} {
+		if got := overlayText(tc.o, tc.elapsed); got != tc.want {
+			t.Errorf("%+v after %vs: %q, want %q", tc.o, tc.elapsed, got, tc.want)
+		}
+	}
+}
+
+func TestOverlayPositionBlinkAndExpiry(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "ripple", "chromatic", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	const w, h = 80, 40
+	if err := r.SetOverlay(Overlay{Text: "hi", Color: "#f00", Blink: 1, Position: "top", Duration: 2}); err != nil {
+		t.Fatal(err)
+	}
+	since := r.overlay.since
+	r.overlay.prepare(since, w, h, 1)
+	lit := func() (top, bottom bool) {
+		for idx, on := range r.overlay.mask {
+			top = top || on && idx/w < h/4
+			bottom = bottom || on && idx/w >= h/4
+		}
+		return top && r.overlay.on, bottom
+	}
+	if top, bottom := lit(); !top || bottom {
+		t.Fatalf("top %v, bottom %v", top, bottom)
+	}
+	if !r.overlay.custom || r.overlay.hsv != [3]float64{0, 1, 1} {
+		t.Fatalf("colour %v", r.overlay.hsv)
+	}
+	r.overlay.prepare(since.Add(1600*time.Millisecond), w, h, 1)
+	if r.overlay.on {
+		t.Fatal("visible in the off half of a blink")
+	}
+	r.overlay.prepare(since.Add(2*time.Second), w, h, 1)
+	if r.overlay.on || r.Overlay().Text != "" {
+		t.Fatal("still shown after its duration")
+	}
+}
+
+func TestSetOverlayRejectsBadInput(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "ripple", "chromatic", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	for _, tc := range []struct {
+		o    Overlay
+		want string
+	}{
+		{Overlay{Text: "x", Position: "left"}, `unknown position "left"`},
+		{Overlay{Text: "x", Color: "red"}, `color "red"`},
+		{Overlay{Text: "x", Blink: 11}, "blink must be within 0-10"},
+		{Overlay{Text: "x", Countdown: -1}, "countdown must be within"},
+		{Overlay{Text: strings.Repeat("x", 201)}, "the limit is 200"},
+	} {
+		if err := r.SetOverlay(tc.o); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%+v: got %v, want %q", tc.o, err, tc.want)
+		}
+	}
+	if err := r.SetOverlay(Overlay{Text: "  "}); err != nil || r.Overlay() != (Overlay{}) {
+		t.Fatalf("blank text: %v, %+v", err, r.Overlay())
+	}
+}
@@ -1,5 +1,14 @@
 package render
 
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
+	"strings"
+	"unicode"
+)
+
 var (
 	defaultPalette = []rune(" .,:;ox%#@")
 	boxPalette     = []rune(" .o*O@")
@@ -9,10 +18,21 @@ var (
 	minimalPalette = []rune(" .o*@")
 	blockPalette   = []rune(" ░▒▓█")
 	bubblePalette  = []rune(" .oO@")
+	// braillePalette only matters outside the ASCII backend; there braille
+	// packs 2x4 dots into every cell instead.
+	braillePalette = []rune(" ⠁⠃⠇⡇⣇⣧⣷⣿")
+	// halfBlockPalette likewise stands in for the two-pixel cells.
+	halfBlockPalette = []rune(" ▄█")
 )
 
+// customPalettes holds the palettes added with RegisterPalette.
+var customPalettes = map[string][]rune{}
+
 // Palette returns characters used for brightness mapping.
 func Palette(name string) []rune {
+	if chars, ok := customPalettes[name]; ok {
+		return chars
+	}
 	switch name {
 	case "box":
 		return boxPalette
@@ -28,12 +48,109 @@ func Palette(name string) []rune {
 		return blockPalette
 	case "bubble":
 		return bubblePalette
+	case "braille":
+		return braillePalette
+	case "halfblock":
+		return halfBlockPalette
 	default:
 		return defaultPalette
 	}
 }
 
-// PaletteNames returns all palette identifiers.
+// PaletteNames returns all palette identifiers, the custom ones sorted
+// after the built-in ones.
 func PaletteNames() []string {
-	return []string{"default", "box", "lines", "spark", "retro", "minimal", "block", "bubble"}
+	names := builtinPaletteNames()
+	custom := make([]string, 0, len(customPalettes))
+	for name := range customPalettes {
+		custom = append(custom, name)
+	}
+	slices.Sort(custom)
+	return append(names, custom...)
+}
+
+func builtinPaletteNames() []string {
+	return []string{"default", "box", "lines", "spark", "retro", "minimal", "block", "bubble", "braille", "halfblock"}
+}
+
+// RegisterPalette adds a palette of chars, lightest first, under name.
+// Built-in palettes can't be replaced; registering a custom name again
+// updates it. Call it before creating renderers: the palettes are not
+// guarded.
+func RegisterPalette(name, chars string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "auto" || strings.ContainsAny(name, ": ") {
+		return fmt.Errorf("invalid palette name %q", name)
+	}
+	if slices.Contains(builtinPaletteNames(), name) {
+		return fmt.Errorf("palette %q is built in", name)
+	}
+	if err := CheckPalette(chars); err != nil {
+		return fmt.Errorf("palette %q: %w", name, err)
+	}
+	customPalettes[name] = []rune(chars)
+	return nil
+}
+
+// CheckPalette reports whether chars work as a brightness ramp: at least two
+// distinct printable characters going from light to dark. Only glyphs whose
+// coverage is known (space, shades, block elements and braille) can be
+// ordered; letters and punctuation depend on the font and are taken as given.
+func CheckPalette(chars string) error {
+	runes := []rune(chars)
+	if len(runes) < 2 {
+		return errors.New("needs at least two characters")
+	}
+	seen := make(map[rune]bool, len(runes))
+	darkest, darkestAt := 0.0, -1
+	for i, c := range runes {
+		if !unicode.IsPrint(c) {
+			return fmt.Errorf("character %d (%U) is not printable", i+1, c)
+		}
+		if seen[c] {
+			return fmt.Errorf("%q appears twice", c)
+		}
+		seen[c] = true
+		if c == ' ' && i > 0 {
+			return errors.New("space must come first, it is the lightest glyph")
+		}
+		if darkest == 1 {
+			return fmt.Errorf("%q follows the full glyph %q", c, runes[darkestAt])
+		}
+		coverage, known := glyphCoverage(c)
+		if !known {
+			continue
+		}
+		if coverage < darkest {
+			return fmt.Errorf("%q is lighter than %q before it", c, runes[darkestAt])
+		}
+		darkest, darkestAt = coverage, i
+	}
+	return nil
+}
+
+// glyphCoverage returns the share of a cell c fills, for the glyphs where
+// that doesn't depend on the font.
+func glyphCoverage(c rune) (float64, bool) {
+	switch {
+	case c == ' ':
+		return 0, true
+	case c == '░':
+		return 0.25, true
+	case c == '▒':
+		return 0.5, true
+	case c == '▓':
+		return 0.75, true
+	case c >= '▁' && c <= '█':
+		// lower eighths up to the full block
+		return float64(c-'▀') / 8, true
+	case c >= '▉' && c <= '▏':
+		// left seven eighths down to one
+		return float64('▐'-c) / 8, true
+	case c == '▀' || c == '▌' || c == '▐':
+		return 0.5, true
+	case c >= '⠀' && c <= '⣿':
+		return float64(bits.OnesCount(uint(c-'⠀'))) / 8, true
+	}
+	return 0, false
 }
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinPalettesPassCheck(t *testing.T) {
+	for _, name := range builtinPaletteNames() {
+		if err := CheckPalette(string(Palette(name))); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestCheckPalette(t *testing.T) {
+	for _, tc := range []struct {
+		chars, want string
+	}{
+		{" ▁▂▃▄▅▆▇█", ""},
+		{" ▏▎▍▌▋▊▉█", ""},
+		{" ⠂⠆⠖⠶⡶⣶⣾⣿", ""},
+		{".:ab", ""},
+		{"#", "at least two"},
+		{" ..", "'.' appears twice"},
+		{".\x01", "not printable"},
+		{". ", "space must come first"},
+		{" ▓▒", "'▒' is lighter than '▓'"},
+		{" ⣷⠁", "'⠁' is lighter than '⣷'"},
+		{" █@", "'@' follows the full glyph '█'"},
+	} {
+		err := CheckPalette(tc.chars)
+		if tc.want == "" && err != nil {
+			t.Errorf("%q: %v", tc.chars, err)
+		}
+		if tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)) {
+			t.Errorf("%q: got %v, want %q", tc.chars, err, tc.want)
+		}
+	}
+}
+
+func TestRegisterPalette(t *testing.T) {
+	t.Cleanup(func() { delete(customPalettes, "shade") })
+
+	if err := RegisterPalette(" Shade ", " ░▒▓█"); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(Palette("shade")); got != " ░▒▓█" {
+		t.Fatalf("palette %q", got)
+	}
+	if names := PaletteNames(); names[len(names)-1] != "shade" || !slices.Contains(names, "default") {
+		t.Fatalf("names %v", names)
+	}
+	if err := RegisterPalette("block", " #"); err == nil {
+		t.Error("built-in palette replaced")
+	}
+	if err := RegisterPalette("a b", " #"); err == nil {
+		t.Error("name with a space accepted")
+	}
+	if err := RegisterPalette("bad", "#"); err == nil || !strings.Contains(err.Error(), `palette "bad"`) {
+		t.Errorf("got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PatternParam is one knob of a built-in pattern with its current value.
+type PatternParam struct {
+	Name    string  `json:"name"`
+	Value   float64 `json:"value"`
+	Default float64 `json:"default"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+}
+
+func paramDefaults(params []effectParam) []float64 {
+	values := make([]float64, len(params))
+	for i, param := range params {
+		values[i] = param.def
+	}
+	return values
+}
+
+// patternValues returns the knob values of the pattern name, the defaults
+// until they are set.
+func (r *Renderer) patternValues(name string, entry patternEntry) []float64 {
+	r.effectsMu.Lock()
+	defer r.effectsMu.Unlock()
+	if values, ok := r.patternKnobs[name]; ok {
+		return values
+	}
+	return paramDefaults(entry.params)
+}
+
+// bindPattern returns the function of the registry pattern name with its
+// knobs applied, and their values.
+func (r *Renderer) bindPattern(name string, entry patternEntry) (patternFunc, []float64) {
+	if entry.bind == nil {
+		return entry.fn, nil
+	}
+	values := r.patternValues(name, entry)
+	return entry.bind(values), values
+}
+
+// PatternParams returns the knobs of the built-in pattern name in order,
+// none for a pattern without any.
+func (r *Renderer) PatternParams(name string) ([]PatternParam, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	entry, ok := patternRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown pattern %q", name)
+	}
+	values := r.patternValues(name, entry)
+	out := make([]PatternParam, len(entry.params))
+	for i, param := range entry.params {
+		out[i] = PatternParam{Name: param.name, Value: values[i], Default: param.def, Min: param.min, Max: param.max}
+	}
+	return out, nil
+}
+
+// SetPatternParams updates the listed knobs of the built-in pattern name,
+// clamped to their limits, and applies them right away if it is on screen.
+// Knobs missing from values keep their current value.
+func (r *Renderer) SetPatternParams(name string, values map[string]float64) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	entry, ok := patternRegistry[name]
+	if !ok {
+		return fmt.Errorf("unknown pattern %q", name)
+	}
+	if entry.bind == nil {
+		return fmt.Errorf("pattern %s has no params", name)
+	}
+	next := append([]float64(nil), r.patternValues(name, entry)...)
+	for key, value := range values {
+		found := false
+		for i, param := range entry.params {
+			if param.name == key {
+				next[i] = clampFloat(value, param.min, param.max)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("pattern %s has no param %q", name, key)
+		}
+	}
+
+	r.effectsMu.Lock()
+	if r.patternKnobs == nil {
+		r.patternKnobs = make(map[string][]float64)
+	}
+	r.patternKnobs[name] = next
+	r.effectsMu.Unlock()
+
+	fn := entry.bind(next)
+	if r.patternName == name {
+		r.pattern, r.patternArgs = fn, next
+	}
+	for i := range r.layers {
+		if r.layers[i].name == name {
+			r.layers[i].fn = fn
+		}
+	}
+	return nil
+}
+
+// TunedPatterns returns the knobs that differ from their defaults, keyed
+// by pattern, for saving.
+func (r *Renderer) TunedPatterns() map[string]map[string]float64 {
+	r.effectsMu.Lock()
+	defer r.effectsMu.Unlock()
+	out := make(map[string]map[string]float64)
+	for name, values := range r.patternKnobs {
+		for i, param := range patternRegistry[name].params {
+			if values[i] == param.def {
+				continue
+			}
+			if out[name] == nil {
+				out[name] = make(map[string]float64)
+			}
+			out[name][param.name] = values[i]
+		}
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package render
+
+import (
+	"testing"
+)
+
+func TestPatternParams(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "rings", "chromatic", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	knobs, err := r.PatternParams("rings")
+	if err != nil || len(knobs) != 2 || knobs[0].Name != "frequency" || knobs[0].Value != 8 {
+		t.Fatalf("rings params %+v, %v", knobs, err)
+	}
+	if knobs, _ := r.PatternParams("flash"); len(knobs) != 0 {
+		t.Fatalf("flash has params %+v", knobs)
+	}
+
+	p, _ := snapshotScene()
+	before := r.pattern(0.571, 0, p, 1)
+	if err := r.SetPatternParams("rings", map[string]float64{"frequency": 100}); err != nil {
+		t.Fatal(err)
+	}
+	knobs, _ = r.PatternParams("rings")
+	if knobs[0].Value != 24 || knobs[1].Value != 3 {
+		t.Fatalf("after set %+v", knobs)
+	}
+	if r.pattern(0.571, 0, p, 1) == before || r.patternArgs[0] != 24 {
+		t.Error("the pattern on screen kept its old frequency")
+	}
+	if tuned := r.TunedPatterns(); len(tuned) != 1 || tuned["rings"]["frequency"] != 24 || len(tuned["rings"]) != 1 {
+		t.Fatalf("tuned %v", tuned)
+	}
+
+	// switching away and back keeps the tuning
+	r.Configure("default", "flash", "chromatic", false)
+	r.Configure("default", "rings", "chromatic", false)
+	if r.patternArgs[0] != 24 {
+		t.Fatalf("args %v after switching back", r.patternArgs)
+	}
+
+	if err := r.SetPatternParams("rings", map[string]float64{"nope": 1}); err == nil {
+		t.Error("unknown param accepted")
+	}
+	if err := r.SetPatternParams("flash", nil); err == nil {
+		t.Error("flash accepted params")
+	}
+	if _, err := r.PatternParams("nope"); err == nil {
+		t.Error("unknown pattern accepted")
+	}
+}
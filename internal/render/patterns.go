@@ -13,39 +13,60 @@ type patternFunc func(x, y float64, p params.Parameters, t float64) float64
 type patternEntry struct {
 	fn        patternFunc
 	detailMix float64
+	// params are the pattern's own knobs. bind returns the pattern with
+	// their values, in the order of params; fn is bind with the defaults.
+	params []effectParam
+	bind   func(v []float64) patternFunc
 }
 
 var patternRegistry = map[string]patternEntry{
-	"flash":     {patternFlash, 0.0},
-	"spark":     {patternSpark, 0.1},
-	"scatter":   {patternScatter, 0.0},
-	"beam":      {patternBeam, 0.0},
-	"ripple":    {patternRipple, 0.1},
-	"laser":     {patternLaser, 0.0},
-	"orbit":     {patternOrbit, 0.0},
-	"explosion": {patternExplosion, 0.1},
-	"rings":     {patternRings, 0.0},
-	"zigzag":    {patternZigzag, 0.0},
-	"cross":     {patternCross, 0.0},
-	"spiral":    {patternSpiral, 0.1},
-	"star":      {patternStar, 0.0},
-	"tunnel":    {patternTunnel, 0.1},
-	"neurons":   {patternNeurons, 0.0},
-	"fractal":   {patternFractal, 0.1},
+	"flash":     {fn: patternFlash, detailMix: 0.0},
+	"spark":     {bind: bindSpark, detailMix: 0.1, params: []effectParam{{"rays", 16, 4, 48}}},
+	"scatter":   {fn: patternScatter, detailMix: 0.0},
+	"beam":      {fn: patternBeam, detailMix: 0.0},
+	"ripple":    {bind: bindRipple, detailMix: 0.1, params: []effectParam{{"frequency", 3, 1, 12}, {"speed", 3, 0, 10}}},
+	"laser":     {fn: patternLaser, detailMix: 0.0},
+	"orbit":     {fn: patternOrbit, detailMix: 0.0},
+	"explosion": {fn: patternExplosion, detailMix: 0.1},
+	"rings":     {bind: bindRings, detailMix: 0.0, params: []effectParam{{"frequency", 8, 2, 24}, {"speed", 3, 0, 10}}},
+	"zigzag":    {fn: patternZigzag, detailMix: 0.0},
+	"cross":     {fn: patternCross, detailMix: 0.0},
+	"spiral":    {bind: bindSpiral, detailMix: 0.1, params: []effectParam{{"arms", 3, 1, 8}, {"twist", 8, 0, 20}}},
+	"star":      {bind: bindStar, detailMix: 0.0, params: []effectParam{{"points", 8, 3, 16}}},
+	"tunnel":    {fn: patternTunnel, detailMix: 0.1},
+	"neurons":   {fn: patternNeurons, detailMix: 0.0},
+	"fractal":   {fn: patternFractal, detailMix: 0.1},
+	"tunnel3d":  {fn: patternTunnel3D, detailMix: 0.0},
+	"metaballs": {fn: patternMetaballs, detailMix: 0.0},
+	"torus":     {fn: patternTorus, detailMix: 0.0},
+	// demoscene classics
+	"plasma":       {fn: patternPlasma, detailMix: 0.1},
+	"interference": {fn: patternInterference, detailMix: 0.0},
+	"blobs":        {fn: patternBlobs, detailMix: 0.0},
+	"copper":       {fn: patternCopper, detailMix: 0.0},
 }
 
 var noiseOctaves atomic.Int32
 
 func init() {
 	noiseOctaves.Store(4)
+	for name, entry := range patternRegistry {
+		if entry.bind != nil {
+			entry.fn = entry.bind(paramDefaults(entry.params))
+			patternRegistry[name] = entry
+		}
+	}
 }
 
 // PatternNames returns the available pattern identifiers.
 func PatternNames() []string {
-	names := make([]string, 0, len(patternRegistry))
+	names := make([]string, 0, len(patternRegistry)+len(screenPatterns))
 	for name := range patternRegistry {
 		names = append(names, name)
 	}
+	for name := range screenPatterns {
+		names = append(names, name)
+	}
 	sort.Strings(names)
 	return names
 }
@@ -66,17 +87,20 @@ func patternFlash(x, y float64, p params.Parameters, t float64) float64 {
 }
 
 // sparks exploding from center (sparse - only the rays)
-func patternSpark(x, y float64, p params.Parameters, t float64) float64 {
-	angle := math.Atan2(y, x)
-	rays := angle*2.5 + t*2.0
-	rayVal := rays - math.Floor(rays)
-	if rayVal < 0.15 || rayVal > 0.85 {
-		r := math.Sqrt(x*x + y*y)
-		if r < 1.2 {
-			return p.BeatDistortion * 3.0 * (1.2 - r)
+func bindSpark(v []float64) patternFunc {
+	perRadian := math.Round(v[0]) / (2 * math.Pi)
+	return func(x, y float64, p params.Parameters, t float64) float64 {
+		angle := math.Atan2(y, x)
+		rays := angle*perRadian + t*2.0
+		rayVal := rays - math.Floor(rays)
+		if rayVal < 0.15 || rayVal > 0.85 {
+			r := math.Sqrt(x*x + y*y)
+			if r < 1.2 {
+				return p.BeatDistortion * 3.0 * (1.2 - r)
+			}
 		}
+		return -1.0
 	}
-	return -1.0
 }
 
 // scattered particles (sparse - only dots)
@@ -107,15 +131,18 @@ func patternBeam(x, y float64, p params.Parameters, t float64) float64 {
 }
 
 // ripples from center (sparse - only the ring edges)
-func patternRipple(x, y float64, p params.Parameters, t float64) float64 {
-	r := math.Sqrt(x*x + y*y)
-	wave := r*3.0 - t*3.0
-	ripple := wave - math.Floor(wave)
-	if ripple < 0.1 || ripple > 0.9 {
-		dist := math.Min(ripple, 1.0-ripple)
-		return dist * 20.0 * p.Amplitude
+func bindRipple(v []float64) patternFunc {
+	frequency, speed := v[0], v[1]
+	return func(x, y float64, p params.Parameters, t float64) float64 {
+		r := math.Sqrt(x*x + y*y)
+		wave := r*frequency - t*speed
+		ripple := wave - math.Floor(wave)
+		if ripple < 0.1 || ripple > 0.9 {
+			dist := math.Min(ripple, 1.0-ripple)
+			return dist * 20.0 * p.Amplitude
+		}
+		return -1.0
 	}
-	return -1.0
 }
 
 // NEW: tunnel perspective effect (sparse - only the tunnel edges)
@@ -240,13 +267,16 @@ func patternExplosion(x, y float64, p params.Parameters, t float64) float64 {
 }
 
 // NEW: concentric rings pulsing (sparse)
-func patternRings(x, y float64, p params.Parameters, t float64) float64 {
-	r := math.Sqrt(x*x + y*y)
-	rings := math.Sin(r*8.0 - t*3.0)
-	if rings > 0.7 {
-		return (rings - 0.7) * 10.0 * p.Amplitude
+func bindRings(v []float64) patternFunc {
+	frequency, speed := v[0], v[1]
+	return func(x, y float64, p params.Parameters, t float64) float64 {
+		r := math.Sqrt(x*x + y*y)
+		rings := math.Sin(r*frequency - t*speed)
+		if rings > 0.7 {
+			return (rings - 0.7) * 10.0 * p.Amplitude
+		}
+		return -1.0
 	}
-	return -1.0
 }
 
 // NEW: zigzag lightning effect (sparse)
@@ -273,32 +303,37 @@ func patternCross(x, y float64, p params.Parameters, t float64) float64 {
 }
 
 // NEW: spiral arms (sparse)
-func patternSpiral(x, y float64, p params.Parameters, t float64) float64 {
-	r := math.Sqrt(x*x + y*y)
-	angle := math.Atan2(y, x)
-	spiral := angle*3.0 - r*8.0 + t*3.0
-	val := spiral - math.Floor(spiral)
-	if val < 0.12 {
-		return val * 25.0 * p.Amplitude
+func bindSpiral(v []float64) patternFunc {
+	arms, twist := math.Round(v[0]), v[1]
+	return func(x, y float64, p params.Parameters, t float64) float64 {
+		r := math.Sqrt(x*x + y*y)
+		angle := math.Atan2(y, x)
+		spiral := angle*arms - r*twist + t*3.0
+		val := spiral - math.Floor(spiral)
+		if val < 0.12 {
+			return val * 25.0 * p.Amplitude
+		}
+		return -1.0
 	}
-	return -1.0
 }
 
 // NEW: star burst (sparse - only the star rays)
-func patternStar(x, y float64, p params.Parameters, t float64) float64 {
-	angle := math.Atan2(y, x) + t
-	points := 8.0
-	starAngle := math.Mod(angle*points, 2.0*math.Pi)
-	if starAngle > math.Pi {
-		starAngle = 2.0*math.Pi - starAngle
-	}
-	if starAngle < 0.3 {
-		r := math.Sqrt(x*x + y*y)
-		if r < 1.2 && r > 0.2 {
-			return (0.3 - starAngle) * 10.0 * (0.5 + p.BeatDistortion*2.0)
+func bindStar(v []float64) patternFunc {
+	points := math.Round(v[0])
+	return func(x, y float64, p params.Parameters, t float64) float64 {
+		angle := math.Atan2(y, x) + t
+		starAngle := math.Mod(angle*points, 2.0*math.Pi)
+		if starAngle > math.Pi {
+			starAngle = 2.0*math.Pi - starAngle
 		}
+		if starAngle < 0.3 {
+			r := math.Sqrt(x*x + y*y)
+			if r < 1.2 && r > 0.2 {
+				return (0.3 - starAngle) * 10.0 * (0.5 + p.BeatDistortion*2.0)
+			}
+		}
+		return -1.0
 	}
-	return -1.0
 }
 
 func fractalNoise(x, y float64) float64 {
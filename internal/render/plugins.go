@@ -0,0 +1,111 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"plugin"
+	"strings"
+
+	"github.com/guidoenr/golizer/internal/params"
+	"github.com/guidoenr/golizer/pattern"
+)
+
+// pluginExt is the extension of pattern plugins.
+const pluginExt = ".so"
+
+// LoadPlugins opens every .so in dir and registers its Pattern function
+// under the file name, so "wobble.so" adds the pattern "wobble". What a
+// plugin exports is described in package pattern. Plugins that fail to
+// load are skipped and reported in the joined error; the names of the ones
+// that loaded are returned. A missing directory has none. Call it before
+// creating renderers: the registry is not guarded.
+func LoadPlugins(dir string) ([]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var loaded []string
+	var errs []error
+	for _, entry := range entries {
+		file := entry.Name()
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(file), pluginExt) {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSuffix(file, filepath.Ext(file)))
+		if err := loadPlugin(filepath.Join(dir, file), name); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			continue
+		}
+		loaded = append(loaded, name)
+	}
+	return loaded, errors.Join(errs...)
+}
+
+func loadPlugin(path, name string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return err
+	}
+	sym, err := p.Lookup("Pattern")
+	if err != nil {
+		return err
+	}
+	detailMix := 0.0
+	if v, err := p.Lookup("DetailMix"); err == nil {
+		mix, ok := v.(*float64)
+		if !ok {
+			return fmt.Errorf("DetailMix is %T, want float64", v)
+		}
+		detailMix = *mix
+	}
+	return registerPattern(name, sym, detailMix)
+}
+
+// registerPattern adds a plugin's pattern function to the registry.
+func registerPattern(name string, sym any, detailMix float64) error {
+	plug, ok := sym.(func(x, y float64, p pattern.Params, t float64) float64)
+	if !ok {
+		return fmt.Errorf("Pattern is %T, want func(x, y float64, p pattern.Params, t float64) float64", sym)
+	}
+	if name == "" || strings.ContainsAny(name, ": +@") {
+		return fmt.Errorf("invalid pattern name %q", name)
+	}
+	if _, taken := patternRegistry[name]; taken || screenPatterns[name] != nil {
+		return fmt.Errorf("pattern %q already exists", name)
+	}
+	fn := func(x, y float64, p params.Parameters, t float64) float64 {
+		v := plug(x, y, pluginParams(p), t)
+		// a division by zero in a plugin would otherwise reach the
+		// colour maths and the frame buffer
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0
+		}
+		return v
+	}
+	patternRegistry[name] = patternEntry{fn: fn, detailMix: clamp01(detailMix)}
+	return nil
+}
+
+// pluginParams copies the values plugins see out of p.
+func pluginParams(p params.Parameters) pattern.Params {
+	return pattern.Params{
+		Time:             p.Time,
+		Frequency:        p.Frequency,
+		Amplitude:        p.Amplitude,
+		Speed:            p.Speed,
+		Scale:            p.Scale,
+		ColorShift:       p.ColorShift,
+		BeatDistortion:   p.BeatDistortion,
+		BeatZoom:         p.BeatZoom,
+		DistortAmplitude: p.DistortAmplitude,
+	}
+}
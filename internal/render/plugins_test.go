@@ -0,0 +1,60 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/params"
+	"github.com/guidoenr/golizer/pattern"
+)
+
+func TestRegisterPattern(t *testing.T) {
+	fn := func(x, y float64, p pattern.Params, t float64) float64 { return x + p.Amplitude + p.BeatDistortion }
+	t.Cleanup(func() { delete(patternRegistry, "plugtest") })
+
+	if err := registerPattern("plugtest", fn, 2); err != nil {
+		t.Fatal(err)
+	}
+	entry := patternRegistry["plugtest"]
+	if got := entry.fn(0.5, 0, params.Parameters{Amplitude: 1, BeatDistortion: 2}, 0); got != 3.5 || entry.detailMix != 1 {
+		t.Fatalf("registered %+v, drew %v", entry, got)
+	}
+	t.Cleanup(func() { delete(patternRegistry, "plugdiv") })
+	div := func(x, y float64, p pattern.Params, t float64) float64 { return x / y }
+	if err := registerPattern("plugdiv", div, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, xy := range [][2]float64{{1, 0}, {-1, 0}, {0, 0}} {
+		if got := patternRegistry["plugdiv"].fn(xy[0], xy[1], params.Parameters{}, 0); got != 0 {
+			t.Errorf("%v/%v drew %v, want 0", xy[0], xy[1], got)
+		}
+	}
+	if err := registerPattern("ripple", fn, 0); err == nil {
+		t.Error("built-in pattern replaced")
+	}
+	if err := registerPattern("shader:x", fn, 0); err == nil {
+		t.Error("shader name accepted")
+	}
+	if err := registerPattern("other", func(x, y float64) float64 { return 0 }, 0); err == nil {
+		t.Error("wrong signature accepted")
+	}
+	// the internal parameters are no longer part of the plugin contract
+	if err := registerPattern("other", func(x, y float64, p params.Parameters, t float64) float64 { return 0 }, 0); err == nil {
+		t.Error("internal params signature accepted")
+	}
+}
+
+func TestLoadPluginsReportsBadFiles(t *testing.T) {
+	if names, err := LoadPlugins(filepath.Join(t.TempDir(), "missing")); names != nil || err != nil {
+		t.Fatalf("missing dir: %v, %v", names, err)
+	}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "broken.so"), []byte("not a plugin"), 0o644)
+	os.WriteFile(filepath.Join(dir, "readme.txt"), nil, 0o644)
+	names, err := LoadPlugins(dir)
+	if len(names) != 0 || err == nil || !strings.Contains(err.Error(), "broken.so") || strings.Contains(err.Error(), "readme") {
+		t.Fatalf("got %v, %v", names, err)
+	}
+}
@@ -0,0 +1,63 @@
+package render
+
+import "sync"
+
+// renderPool is the renderer's long-lived row workers, one per GOMAXPROCS.
+// Every frame is cut into one band of rows per worker and handed to them
+// through r.job, so no goroutine is started per frame.
+type renderPool struct {
+	bands chan rowBand
+	done  sync.WaitGroup
+}
+
+// rowBand is one worker's share of a frame: rows start to end of r.job,
+// built in writer when the job is text rows.
+type rowBand struct {
+	start, end int
+	writer     *rowWriter
+}
+
+// bands returns how many bands a frame of rows is cut into.
+func (r *Renderer) bands(rows int) int {
+	return max(min(r.workerCount, rows), 1)
+}
+
+// runRows renders rows 0 to rows of r.job on the pool, one band per worker,
+// and waits for them. writers holds one writer per band for text rows.
+func (r *Renderer) runRows(rows int, writers []rowWriter) {
+	if r.pool == nil {
+		r.pool = &renderPool{bands: make(chan rowBand, r.workerCount)}
+		for i := 0; i < r.workerCount; i++ {
+			go r.poolWorker(r.pool)
+		}
+	}
+	per := (rows + r.bands(rows) - 1) / r.bands(rows)
+	for i, start := 0, 0; start < rows; i, start = i+1, start+per {
+		band := rowBand{start: start, end: min(start+per, rows)}
+		if writers != nil {
+			band.writer = &writers[i]
+		}
+		r.pool.done.Add(1)
+		r.pool.bands <- band
+	}
+	r.pool.done.Wait()
+}
+
+func (r *Renderer) poolWorker(pool *renderPool) {
+	for band := range pool.bands {
+		if r.job.image != nil {
+			r.imageRows(band.start, band.end)
+		} else {
+			r.asciiRows(band.writer, band.start, band.end)
+		}
+		pool.done.Done()
+	}
+}
+
+// stopPool ends the workers; a later frame starts them again.
+func (r *Renderer) stopPool() {
+	if r.pool != nil {
+		close(r.pool.bands)
+		r.pool = nil
+	}
+}
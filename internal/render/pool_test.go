@@ -0,0 +1,51 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPoolMatchesSingleWorker(t *testing.T) {
+	p, feat := snapshotScene()
+	render := func(backend Backend, workers int) Frame {
+		r, err := NewWithBackend(backend, 37, 23, "default", "plasma", "chromatic", "high", true, true)
+		if err != nil {
+			t.Fatalf("new renderer: %v", err)
+		}
+		t.Cleanup(func() { r.Close() })
+		r.workerCount = workers
+		r.SetCapture(true)
+		return r.Render(p, feat, 60)
+	}
+	for _, backend := range []Backend{BackendASCII, BackendNone} {
+		one, many := render(backend, 1), render(backend, 5)
+		if !bytes.Equal(one.Image.Pix, many.Image.Pix) {
+			t.Fatalf("%s: pixels differ between 1 and 5 workers", backend)
+		}
+		for y := range one.Lines {
+			if one.Lines[y] != many.Lines[y] {
+				t.Fatalf("%s: row %d differs between 1 and 5 workers", backend, y)
+			}
+		}
+	}
+}
+
+func TestPoolRestartsAfterClose(t *testing.T) {
+	r, err := NewWithBackend(BackendNone, 16, 8, "default", "plasma", "chromatic", "high", true, true)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	p, feat := snapshotScene()
+	r.Render(p, feat, 60)
+	if r.pool == nil {
+		t.Fatal("expected the first frame to start the pool")
+	}
+	r.Close()
+	if r.pool != nil {
+		t.Fatal("expected Close to stop the pool")
+	}
+	if frame := r.Render(p, feat, 60); frame.Image == nil {
+		t.Fatal("expected a frame after Close")
+	}
+	r.Close()
+}
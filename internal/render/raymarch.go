@@ -0,0 +1,176 @@
+package render
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// The 3D patterns sphere-trace a small scene per pixel. The camera rides
+// the pattern time, which runs faster the more bass there is, and beats
+// ripple the surfaces. Their GLSL ports in glPatterns follow the same
+// scenes and constants.
+
+// raymarchSteps bounds the steps of a ray, following the quality preset.
+var raymarchSteps atomic.Int32
+
+func init() {
+	raymarchSteps.Store(48)
+}
+
+const (
+	// raymarchFar is where a ray gives up and the pixel stays black.
+	raymarchFar = 20.0
+	// raymarchStride shortens every step, as the displaced distance fields
+	// aren't exact and full steps would overshoot thin ripples.
+	raymarchStride = 0.7
+)
+
+func setRaymarchProfile(mode qualityMode) {
+	switch mode {
+	case qualityEco:
+		raymarchSteps.Store(16)
+	case qualityBalanced:
+		raymarchSteps.Store(32)
+	default:
+		raymarchSteps.Store(48)
+	}
+}
+
+type vec3 struct{ x, y, z float64 }
+
+func (a vec3) add(b vec3) vec3           { return vec3{a.x + b.x, a.y + b.y, a.z + b.z} }
+func (a vec3) sub(b vec3) vec3           { return vec3{a.x - b.x, a.y - b.y, a.z - b.z} }
+func (a vec3) scale(s float64) vec3      { return vec3{a.x * s, a.y * s, a.z * s} }
+func (a vec3) length() float64           { return math.Sqrt(a.x*a.x + a.y*a.y + a.z*a.z) }
+func (a vec3) normalize() vec3           { return a.scale(1 / a.length()) }
+func (a vec3) rotateX(s, c float64) vec3 { return vec3{a.x, a.y*c - a.z*s, a.y*s + a.z*c} }
+func (a vec3) rotateY(s, c float64) vec3 { return vec3{a.x*c + a.z*s, a.y, -a.x*s + a.z*c} }
+
+// scene is a signed distance field.
+type scene interface {
+	dist(p vec3) float64
+}
+
+// march traces the ray from ro along rd through s. It returns where the
+// ray stopped, how far it went and its cost: the share of the step budget
+// it used, 1 when the budget ran out near a surface. ok is false when the
+// ray left the scene.
+func march[S scene](s S, ro, rd vec3) (hit vec3, dist, cost float64, ok bool) {
+	steps := int(raymarchSteps.Load())
+	for i := 0; i < steps; i++ {
+		hit = ro.add(rd.scale(dist))
+		d := s.dist(hit)
+		if d < 0.002*dist+0.005 {
+			return hit, dist, float64(i) / float64(steps), true
+		}
+		dist += d * raymarchStride
+		if dist > raymarchFar {
+			return hit, dist, 1, false
+		}
+	}
+	return hit, dist, 1, true
+}
+
+// shadeHit is the brightness (0-1) of a surface dist away that took cost
+// of the step budget to reach: fog darkens far surfaces and expensive rays
+// outline edges and creases, with no normals needed.
+func shadeHit(dist, cost float64) float64 {
+	return clamp01(math.Exp(-dist*0.15) * (1 - 0.85*cost))
+}
+
+// rayDir is the camera ray through the pattern point x, y.
+func rayDir(x, y, fov float64) vec3 {
+	return vec3{x * fov, y * fov, 1}.normalize()
+}
+
+// bassLevel recovers the bass (0-1) from the amplitude it drives.
+func bassLevel(p params.Parameters) float64 {
+	return clamp01((p.Amplitude - 1) / 1.5)
+}
+
+// smin is a smooth minimum, blending shapes within k of each other.
+func smin(a, b, k float64) float64 {
+	h := clamp01(0.5 + 0.5*(b-a)/k)
+	return lerp(b, a, h) - k*h*(1-h)
+}
+
+type tunnelScene struct{ ripple float64 }
+
+func (s tunnelScene) dist(p vec3) float64 {
+	return 1 - math.Sqrt(p.x*p.x+p.y*p.y) + s.ripple*math.Sin(math.Atan2(p.y, p.x)*6+p.z*1.5)
+}
+
+// flying down a rippled pipe with a bright ring every unit
+func patternTunnel3D(x, y float64, p params.Parameters, t float64) float64 {
+	s := tunnelScene{ripple: 0.05 + 0.15*p.BeatDistortion}
+	hit, dist, cost, ok := march(s, vec3{0, 0, t * 2}, rayDir(x, y, 1.4))
+	if !ok || hit.z-math.Floor(hit.z) > 0.2 {
+		return -1.0
+	}
+	return shadeHit(dist, cost)*2 - 1
+}
+
+type metaballScene struct {
+	balls  [4]vec3
+	radius float64
+	wobble float64
+	t      float64
+}
+
+func (s metaballScene) dist(p vec3) float64 {
+	d := s.balls[0].sub(p).length() - s.radius
+	for _, c := range s.balls[1:] {
+		d = smin(d, c.sub(p).length()-s.radius, 0.5)
+	}
+	return d + s.wobble*math.Sin(p.x*8+s.t*3)*math.Sin(p.y*8)*math.Sin(p.z*8)
+}
+
+// blobs orbiting and melting into each other, swelling with the bass
+func patternMetaballs(x, y float64, p params.Parameters, t float64) float64 {
+	s := metaballScene{
+		radius: 0.35 + 0.25*bassLevel(p) + 0.1*p.BeatDistortion,
+		wobble: 0.04 * p.BeatDistortion,
+		t:      t,
+	}
+	for i := range s.balls {
+		f := float64(i)
+		s.balls[i] = vec3{math.Sin(t*0.7+f*1.7) * 0.8, math.Cos(t*0.9+f*2.3) * 0.6, math.Sin(t*0.5+f*1.1) * 0.6}
+	}
+	_, dist, cost, ok := march(s, vec3{0, 0, -3}, rayDir(x, y, 1.2))
+	if !ok {
+		return -1.0
+	}
+	return shadeHit(dist-2, cost)*2 - 1
+}
+
+type torusScene struct {
+	sinX, cosX float64
+	sinY, cosY float64
+	tube       float64
+	ripple     float64
+	t          float64
+}
+
+func (s torusScene) dist(p vec3) float64 {
+	p = p.rotateX(s.sinX, s.cosX).rotateY(s.sinY, s.cosY)
+	ring := math.Sqrt(p.x*p.x+p.z*p.z) - 0.9
+	return math.Sqrt(ring*ring+p.y*p.y) - s.tube + s.ripple*math.Sin(math.Atan2(p.z, p.x)*8+s.t*4)
+}
+
+// a tumbling ring whose tube thickens with the bass
+func patternTorus(x, y float64, p params.Parameters, t float64) float64 {
+	s := torusScene{
+		tube:   0.3 + 0.1*bassLevel(p),
+		ripple: 0.06 * p.BeatDistortion,
+		t:      t,
+	}
+	s.sinX, s.cosX = math.Sincos(t * 0.7)
+	s.sinY, s.cosY = math.Sincos(t * 0.5)
+	_, dist, cost, ok := march(s, vec3{0, 0, -3}, rayDir(x, y, 1.2))
+	if !ok {
+		return -1.0
+	}
+	return shadeHit(dist-2, cost)*2 - 1
+}
@@ -0,0 +1,36 @@
+package render
+
+import (
+	"math"
+	"testing"
+)
+
+type sphereScene struct{ radius float64 }
+
+func (s sphereScene) dist(p vec3) float64 {
+	return p.sub(vec3{0, 0, 3}).length() - s.radius
+}
+
+func TestMarchFindsSurface(t *testing.T) {
+	defer setRaymarchProfile(qualityHigh)
+	setRaymarchProfile(qualityHigh)
+	hit, dist, cost, ok := march(sphereScene{1}, vec3{}, vec3{0, 0, 1})
+	if !ok || math.Abs(dist-2) > 0.02 || math.Abs(hit.z-2) > 0.02 {
+		t.Fatalf("hit %v at %v, ok %v", hit, dist, ok)
+	}
+	if cost <= 0 || cost >= 1 {
+		t.Errorf("cost %v", cost)
+	}
+	if _, _, _, ok := march(sphereScene{1}, vec3{}, vec3{0, 1, 0}); ok {
+		t.Error("ray away from the sphere hit")
+	}
+
+	// a grazing ray runs out of steps sooner on eco
+	graze := vec3{0.3, 0, 1}.normalize()
+	_, _, high, _ := march(sphereScene{1}, vec3{}, graze)
+	setRaymarchProfile(qualityEco)
+	_, _, eco, _ := march(sphereScene{1}, vec3{}, graze)
+	if eco <= high {
+		t.Errorf("eco cost %v, high %v", eco, high)
+	}
+}
@@ -3,12 +3,14 @@ package render
 import (
 	"errors"
 	"fmt"
+	"image"
 	"math"
 	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/guidoenr/golizer/internal/analyzer"
 	"github.com/guidoenr/golizer/internal/params"
@@ -22,6 +24,7 @@ const (
 	colorModeFire      colorMode = "fire"
 	colorModeAurora    colorMode = "aurora"
 	colorModeMono      colorMode = "mono"
+	colorModeGradient  colorMode = "gradient"
 
 	qualityHigh     qualityMode = "high"
 	qualityBalanced qualityMode = "balanced"
@@ -33,6 +36,11 @@ type Backend string
 const (
 	BackendASCII Backend = "ascii"
 	BackendSDL   Backend = "sdl"
+	BackendSixel Backend = "sixel"
+	BackendDRM   Backend = "drm"
+	BackendGL    Backend = "gl"
+	// BackendNone renders off-screen, for the outputs only.
+	BackendNone Backend = "none"
 )
 
 type backendMode int
@@ -40,6 +48,10 @@ type backendMode int
 const (
 	backendASCII backendMode = iota
 	backendSDL
+	backendSixel
+	backendDRM
+	backendGL
+	backendNone
 )
 
 var ErrRendererQuit = errors.New("render: quit")
@@ -49,6 +61,7 @@ var colorModeNames = []string{
 	string(colorModeFire),
 	string(colorModeAurora),
 	string(colorModeMono),
+	string(colorModeGradient),
 }
 
 var qualityModeNames = []string{
@@ -58,14 +71,7 @@ var qualityModeNames = []string{
 }
 
 func determineWorkerCount() int {
-	workers := runtime.GOMAXPROCS(0) / 2
-	if workers < 1 {
-		workers = 1
-	}
-	if workers > 4 {
-		workers = 4
-	}
-	return workers
+	return max(runtime.GOMAXPROCS(0), 1)
 }
 
 // ColorModeNames returns the supported color modes.
@@ -92,6 +98,8 @@ func parseColorMode(name string) colorMode {
 		return colorModeAurora
 	case "mono", "monochrome", "bw", "gray":
 		return colorModeMono
+	case "gradient":
+		return colorModeGradient
 	default:
 		return colorModeChromatic
 	}
@@ -126,31 +134,81 @@ type Renderer struct {
 	useANSI       bool
 	xCoords       []float64
 	yCoords       []float64
-	statusBuilder strings.Builder
+	statusBuilder rowWriter
+	status        string // the last status, reused while it doesn't change
 	sdl           *sdlState
+	sixel         *sixelState
+	drm           *drmState
+	gl            *glState
+	shaderDir     string
+	luaDir        string
+	lua           *luaPattern
+	frameFeatures analyzer.Features // features of the frame being rendered, for expr patterns
+	screen        screenPattern     // set while a screen-space pattern is selected
+	layers        []patternLayer    // set while a layered pattern is selected
+	screenAt      time.Time
 	scale         float64
 	downsample    int
 	fullscreen    bool
 	webPanelURL   string
 	showWebURL    bool
 	workerCount   int
+	keyHandler    func(WindowKey) bool
+	effectsMu     sync.Mutex
+	effects       []effectStage
+	patternKnobs  map[string][]float64 // tuned pattern params by pattern, under effectsMu
+	patternArgs   []float64            // the current pattern's params
+	curves        map[colorMode]ColorCurve
+	curve         ColorCurve
+	gradientStops []GradientStop // nil for the default
+	gradientTable *gradientTable // sampled gradientStops, under effectsMu
+	gradient      *gradientTable // this frame's, picked by compileCurve
+	chain         effectChain
+	history       frameHistory
+	capture       bool
+	captureImg    *image.RGBA
+	text          textOverlay
+	overlay       overlayLayer
+	widgets       widgetLayer
+	statusPanel   []string // the SDL window's status overlay, see SetStatusOverlay
+	fade          transition
+	features      *analyzer.History
+	featureBuf    []analyzer.Features
+	dynRes        *energyResolution
+	dynResMode    string
+	frameRes      *frameTimeResolution // nil unless --dynamic-res frametime
+	frameBudget   time.Duration
+	braille       bool
+	halfBlock     bool
+	gridWidth     int
+	clock         func() time.Time // nil for the wall clock; golden tests script it
+	lines         []string         // the last frame's rows, reused by the next
+	rowWriters    []rowWriter      // one per render worker
+	job           frameJob         // the frame the pool workers render
+	pool          *renderPool      // started by the first frame, stopped by Close
 }
 
-// Frame contains the rendered ASCII lines and optional status text.
+// Frame contains the rendered ASCII lines and optional status text. Image
+// holds the frame's colours when capture is enabled; it and Lines are
+// reused by the next Render call.
 type Frame struct {
 	Lines   []string
 	Status  string
+	Image   *image.RGBA
 	Present func(status string) error
 }
 
 var (
 	resetANSI       = "\x1b[0m"
 	precomputedANSI [256]string
+	// precomputedANSIBg holds the matching background codes.
+	precomputedANSIBg [256]string
 )
 
 func init() {
 	for i := range precomputedANSI {
 		precomputedANSI[i] = "\x1b[38;5;" + strconv.Itoa(i) + "m"
+		precomputedANSIBg[i] = "\x1b[48;5;" + strconv.Itoa(i) + "m"
 	}
 }
 
@@ -166,7 +224,7 @@ func NewWithBackend(backend Backend, width, height int, paletteName, patternName
 	}
 
 	switch backend {
-	case BackendSDL, BackendASCII, Backend("auto"):
+	case BackendSDL, BackendSixel, BackendDRM, BackendGL, BackendASCII, BackendNone, Backend("auto"):
 	default:
 		return nil, fmt.Errorf("unknown render backend %q", backend)
 	}
@@ -177,13 +235,27 @@ func NewWithBackend(backend Backend, width, height int, paletteName, patternName
 		scale:       1.0,
 		downsample:  1,
 		workerCount: determineWorkerCount(),
+		effects:     newEffectStages(),
 	}
 
-	if backend == BackendSDL {
+	switch backend {
+	case BackendSDL:
 		if err := r.initSDL(width, height); err != nil {
 			return nil, err
 		}
-	} else {
+	case BackendSixel:
+		r.initSixel()
+	case BackendDRM:
+		if err := r.initDRM(); err != nil {
+			return nil, err
+		}
+	case BackendGL:
+		if err := r.initGL(width, height); err != nil {
+			return nil, err
+		}
+	case BackendNone:
+		r.mode = backendNone
+	default:
 		r.mode = backendASCII
 		r.useANSI = useANSI
 	}
@@ -198,29 +270,64 @@ func NewWithBackend(backend Backend, width, height int, paletteName, patternName
 
 // Configure updates palette, pattern and color behaviour dynamically.
 func (r *Renderer) Configure(paletteName, patternName, colorModeName string, colorOnAudio bool) {
+	old := r.currentLook()
 	if paletteName == "" {
 		paletteName = "default"
 	}
 	r.palette = Palette(paletteName)
 	r.paletteName = paletteName
+	r.braille = paletteName == "braille"
+	r.halfBlock = paletteName == "halfblock"
 
 	key := strings.ToLower(patternName)
 	if key == "" {
 		key = "plasma"
 	}
-	if entry, ok := patternRegistry[key]; ok {
-		r.pattern = entry.fn
+	if isShaderPattern(key) && r.mode == backendGL {
+		// the shader draws everything; nothing is evaluated per pixel
+		r.pattern = func(x, y float64, p params.Parameters, t float64) float64 { return -1 }
+		r.patternName = ShaderPrefix + strings.TrimSpace(patternName[len(ShaderPrefix):])
+		r.detailMix = 0
+	} else if isLuaPattern(key) {
+		// prepareLua loads the script at the next frame
+		r.pattern = func(x, y float64, p params.Parameters, t float64) float64 { return r.lua.eval(x, y, p, t) }
+		r.patternName = LuaPrefix + strings.TrimSpace(patternName[len(LuaPrefix):])
+		r.detailMix = 0
+	} else if isExprPattern(key) && r.configureExpr(patternName) {
+		// an expression that doesn't compile falls back to ripple
+	} else if isLayeredPattern(key) && r.configureLayers(key) {
+		// so does a stack that doesn't parse
+	} else if newScreen, ok := screenPatterns[key]; ok {
+		if r.screen == nil || r.patternName != key {
+			r.screen, r.screenAt = newScreen(), time.Time{}
+		}
+		screen := r.screen
+		r.pattern = func(x, y float64, p params.Parameters, t float64) float64 { return screen.eval(x, y) }
+		r.patternName = key
+		r.detailMix = 0
+	} else if entry, ok := patternRegistry[key]; ok {
+		r.pattern, r.patternArgs = r.bindPattern(key, entry)
 		r.patternName = key
 		r.detailMix = entry.detailMix
 	} else {
 		def := patternRegistry["ripple"]
-		r.pattern = def.fn
+		r.pattern, r.patternArgs = r.bindPattern("ripple", def)
 		r.patternName = "ripple"
 		r.detailMix = def.detailMix
 	}
+	if _, ok := patternRegistry[r.patternName]; !ok {
+		r.patternArgs = nil
+	}
+	if _, ok := screenPatterns[r.patternName]; !ok {
+		r.screen = nil
+	}
+	if !isLayeredPattern(r.patternName) {
+		r.layers = nil
+	}
 
 	r.colorMode = parseColorMode(colorModeName)
 	r.colorOnAudio = colorOnAudio
+	r.beginTransition(old)
 }
 
 // SetScale adjusts the internal pixel downsampling factor (SDL only).
@@ -266,6 +373,10 @@ func (r *Renderer) SetShowWebPanelURL(show bool) {
 
 // Resize updates the framebuffer dimensions.
 func (r *Renderer) Resize(width, height int) {
+	if r.mode == backendDRM {
+		// the display mode fixes the size
+		return
+	}
 	changed := false
 	if width > 0 {
 		if r.width != width {
@@ -282,8 +393,16 @@ func (r *Renderer) Resize(width, height int) {
 	if changed {
 		r.xCoords = nil
 		r.yCoords = nil
-		if r.mode == backendSDL {
+		switch r.mode {
+		case backendSDL:
 			r.resizeSDL()
+		case backendGL:
+			r.resizeGL()
+		case backendSixel:
+			// the font may have changed along with the window
+			if w, h := cellPixelSize(); w > 0 && h > 0 {
+				r.sixel.cellW, r.sixel.cellH = w, h
+			}
 		}
 	}
 }
@@ -308,6 +427,32 @@ func (r *Renderer) SetQuality(name string) {
 	mode := parseQualityMode(name)
 	r.quality = mode
 	setNoiseProfile(r.quality)
+	setRaymarchProfile(r.quality)
+}
+
+// SetDynamicResolution picks how the renderer adapts its resolution:
+// DynResEnergy coarsens quiet SDL frames, DynResFrameTime coarsens SDL and
+// ASCII frames that take longer than the frame budget. Unknown names turn it
+// off.
+func (r *Renderer) SetDynamicResolution(mode string) {
+	r.dynResMode = mode
+	r.dynRes = newEnergyResolution(mode)
+	r.frameRes = newFrameTimeResolution(mode, r.frameBudget)
+}
+
+// SetFrameBudget sets how long rendering one frame may take before the
+// frametime dynamic resolution coarsens it.
+func (r *Renderer) SetFrameBudget(d time.Duration) {
+	r.frameBudget = d
+	r.frameRes = newFrameTimeResolution(r.dynResMode, d)
+}
+
+// now is the time frames animate by.
+func (r *Renderer) now() time.Time {
+	if r.clock != nil {
+		return r.clock()
+	}
+	return time.Now()
 }
 
 // Render generates a frame based on parameters and features.
@@ -316,7 +461,21 @@ func (r *Renderer) Render(p params.Parameters, feat analyzer.Features, fps float
 		return Frame{}
 	}
 
+	if r.frameRes != nil {
+		began := time.Now()
+		defer func() {
+			delta := 1.0 / 60
+			if fps > 0 {
+				delta = 1 / fps
+			}
+			r.frameRes.Observe(time.Since(began).Seconds(), delta)
+		}()
+	}
 	activation := r.audioActivation(feat)
+	now := r.now()
+	r.prepareLua(now, p, feat)
+	r.fade.prepare(now)
+	r.frameFeatures = feat
 
 	timeFactor := p.Time
 	scale := p.Scale
@@ -328,9 +487,45 @@ func (r *Renderer) Render(p params.Parameters, feat analyzer.Features, fps float
 
 	width := r.width
 	height := r.height
+	// terminal cells are about twice as tall as wide
+	textAspect := 2
+	braille := r.braille && r.mode == backendASCII
+	halfBlock := r.halfBlock && r.mode == backendASCII
+	gridW, gridH := width, height
+	if braille {
+		// square dots: the pattern, effects and text run on the dot grid
+		gridW, gridH = width*brailleCols, height*brailleRows
+		textAspect = 1
+	}
+	if halfBlock {
+		// two stacked pixels per cell are close to square
+		gridH = height * halfBlockRows
+		textAspect = 1
+	}
+	if r.mode == backendSDL || r.mode == backendGL || r.mode == backendNone {
+		textAspect = 1
+	}
+	if r.mode == backendSixel {
+		gridW, gridH = r.sixelGrid(width, height)
+		textAspect = 1
+	}
+	if r.mode == backendDRM {
+		gridW, gridH = r.drmGrid()
+		textAspect = 1
+	}
+	r.gridWidth = gridW
+	r.prepareScreen(now, p, feat, gridW, gridH, textAspect)
+	r.compileEffects()
+	r.compileCurve()
+	if r.chain.history {
+		r.history.begin(gridW, gridH)
+	}
+	r.text.prepare(gridW, gridH, textAspect)
+	r.overlay.prepare(now, gridW, gridH, textAspect)
+	r.widgets.prepare(gridW, gridH)
 	useANSI := r.useANSI
 
-	r.ensureCoordinateCache(width, height)
+	r.ensureCoordinateCache(gridW, gridH)
 	xCoords := r.xCoords
 	yCoords := r.yCoords
 
@@ -345,75 +540,174 @@ func (r *Renderer) Render(p params.Parameters, feat analyzer.Features, fps float
 	if r.mode == backendSDL {
 		return r.renderSDL(p, feat, fps, frameCtx, activation, xCoords, yCoords, scale, noiseWarp, noiseDetail)
 	}
-
-	lines := make([]string, r.height)
-
-	numWorkers := r.workerCount
-	if numWorkers < 1 {
-		numWorkers = 1
+	if r.mode == backendSixel {
+		return r.renderSixel(p, feat, fps, frameCtx, activation, xCoords, yCoords, scale)
 	}
-	if numWorkers > height {
-		numWorkers = height
+	if r.mode == backendDRM {
+		return r.renderDRM(p, feat, fps, frameCtx, activation, xCoords, yCoords, scale)
+	}
+	if r.mode == backendGL {
+		return r.renderGL(p, feat, fps, frameCtx, activation, xCoords, yCoords, scale)
+	}
+	if r.mode == backendNone {
+		return r.renderNone(p, feat, fps, frameCtx, activation, xCoords, yCoords, scale)
 	}
 
-	rowsPerWorker := (height + numWorkers - 1) / numWorkers
-	var wg sync.WaitGroup
-
-	for w := 0; w < numWorkers; w++ {
-		start := w * rowsPerWorker
-		if start >= height {
-			break
-		}
-		end := start + rowsPerWorker
-		if end > height {
-			end = height
+	var capture []uint8
+	if r.capture {
+		if r.captureImg == nil || r.captureImg.Rect.Dx() != gridW || r.captureImg.Rect.Dy() != gridH {
+			r.captureImg = image.NewRGBA(image.Rect(0, 0, gridW, gridH))
 		}
+		capture = r.captureImg.Pix
+	}
 
-		wg.Add(1)
-		go func(start, end int) {
-			defer wg.Done()
-			var builder strings.Builder
-			builder.Grow(width * 8)
-			for y := start; y < end; y++ {
-				builder.Reset()
-				lastColor := -1
-				vy := yCoords[y] * scale
-				for x := 0; x < width; x++ {
-					vx := xCoords[x] * scale
-					index := y*width + x
-					char, fg := r.samplePixel(vx, vy, p, frameCtx, feat, activation, noiseWarp, noiseDetail, index)
-					if useANSI && fg != lastColor {
-						builder.WriteString(colorCode(fg))
-						lastColor = fg
-					}
-					builder.WriteRune(char)
-				}
-				if useANSI {
-					builder.WriteString(resetANSI)
-				}
-				lines[y] = builder.String()
-			}
-		}(start, end)
+	// slow frames evaluate one cell per block and repeat it; the rows
+	// inside a block are copied from its first one below
+	block := 1
+	if !braille && !halfBlock {
+		block = r.frameRes.Factor()
 	}
 
-	wg.Wait()
+	lines, writers := r.frameRows(height, r.bands(height))
+	r.job = frameJob{
+		p: p, feat: feat, ctx: frameCtx, activation: activation,
+		xCoords: xCoords, yCoords: yCoords, scale: scale,
+		width: width, gridW: gridW, block: block,
+		braille: braille, halfBlock: halfBlock, useANSI: useANSI,
+		capture: capture, lines: lines,
+	}
+	r.runRows(height, writers)
+	if block > 1 {
+		rowBytes := gridW * 4
+		for y := 0; y < height; y++ {
+			first := y - y%block
+			if first == y {
+				continue
+			}
+			lines[y] = lines[first]
+			if capture != nil {
+				copy(capture[y*rowBytes:(y+1)*rowBytes], capture[first*rowBytes:(first+1)*rowBytes])
+			}
+		}
+	}
 
 	status := r.buildStatus(feat, fps)
 
-	return Frame{
+	frame := Frame{
 		Lines:  lines,
 		Status: status,
 	}
+	if r.capture {
+		frame.Image = r.captureImg
+	}
+	return frame
+}
+
+// frameJob is what the pool workers need of the frame being rendered. It
+// lives on the Renderer so handing it to them doesn't allocate.
+type frameJob struct {
+	p                   params.Parameters
+	feat                analyzer.Features
+	ctx                 frameParams
+	activation          float64
+	xCoords, yCoords    []float64
+	scale               float64
+	width, gridW, block int
+	braille, halfBlock  bool
+	useANSI             bool
+	capture             []uint8
+	lines               []string
+	image               []uint8 // set when fillImage runs the job, nil for text rows
+}
+
+// asciiRows renders rows start to end of r.job into its lines.
+func (r *Renderer) asciiRows(builder *rowWriter, start, end int) {
+	f := &r.job
+	lines, capture, width, block := f.lines, f.capture, f.width, f.block
+	for y := start; y < end; y++ {
+		if f.braille {
+			r.brailleRow(builder, y, width, f.gridW, f.xCoords, f.yCoords, f.scale, f.p, f.ctx, f.feat, f.activation, capture, f.useANSI)
+			lines[y] = builder.String(lines[y])
+			continue
+		}
+		if f.halfBlock {
+			r.halfBlockRow(builder, y, width, f.gridW, f.xCoords, f.yCoords, f.scale, f.p, f.ctx, f.feat, f.activation, capture, f.useANSI)
+			lines[y] = builder.String(lines[y])
+			continue
+		}
+		if y%block != 0 {
+			continue
+		}
+		builder.Reset()
+		lastColor := -1
+		vy := f.yCoords[y] * f.scale
+		for x := 0; x < width; x += block {
+			vx := f.xCoords[x] * f.scale
+			index := y*width + x
+			char, fg, res := r.samplePixel(vx, vy, f.p, f.ctx, f.feat, f.activation, nil, nil, index)
+			span := min(block, width-x)
+			if capture != nil {
+				for i := index; i < index+span; i++ {
+					writePixel(capture[i*4:i*4+4:i*4+4], res)
+				}
+			}
+			if f.useANSI && fg != lastColor {
+				builder.WriteString(colorCode(fg))
+				lastColor = fg
+			}
+			for i := 0; i < span; i++ {
+				builder.WriteRune(char)
+			}
+		}
+		if f.useANSI {
+			builder.WriteString(resetANSI)
+		}
+		lines[y] = builder.String(lines[y])
+	}
 }
 
-func (r *Renderer) samplePixel(vx, vy float64, p params.Parameters, ctx frameParams, feat analyzer.Features, activation float64, noiseWarp, noiseDetail []float64, idx int) (rune, int) {
+func (r *Renderer) samplePixel(vx, vy float64, p params.Parameters, ctx frameParams, feat analyzer.Features, activation float64, noiseWarp, noiseDetail []float64, idx int) (rune, int, pixelResult) {
 	res := r.evaluatePixel(vx, vy, p, ctx, feat, activation, noiseWarp, noiseDetail, idx)
 	index := clampInt(int(res.glyphValue*float64(len(r.palette)-1)+0.5), 0, len(r.palette)-1)
 	colorIndex := 15
 	if r.useANSI {
 		colorIndex = hsvToANSI(res.h, res.s, res.v)
 	}
-	return r.palette[index], colorIndex
+	return r.fade.fadeGlyph(r.palette[index], res.glyphValue, idx), colorIndex, res
+}
+
+// writePixel stores res as an opaque RGBA pixel in px (len 4).
+func writePixel(px []uint8, res pixelResult) {
+	cr, cg, cb := hsvToRGB(res.h, res.s, res.v)
+	px[0] = byte(clampFloat(cr*255, 0, 255))
+	px[1] = byte(clampFloat(cg*255, 0, 255))
+	px[2] = byte(clampFloat(cb*255, 0, 255))
+	px[3] = 255
+}
+
+// fillImage evaluates every pixel of the xCoords x yCoords grid into img
+// (same size), split across the pool workers. Pixel backends use it.
+func (r *Renderer) fillImage(img *image.RGBA, p params.Parameters, feat analyzer.Features, ctx frameParams, activation float64, xCoords, yCoords []float64, scale float64) {
+	r.job = frameJob{
+		p: p, feat: feat, ctx: ctx, activation: activation,
+		xCoords: xCoords, yCoords: yCoords, scale: scale,
+		gridW: len(xCoords), image: img.Pix,
+	}
+	r.runRows(len(yCoords), nil)
+}
+
+// imageRows evaluates rows start to end of r.job into its image.
+func (r *Renderer) imageRows(start, end int) {
+	f := &r.job
+	pix, gridW := f.image, f.gridW
+	for gy := start; gy < end; gy++ {
+		vy := f.yCoords[gy] * f.scale
+		for gx := 0; gx < gridW; gx++ {
+			idx := gy*gridW + gx
+			res := r.evaluatePixel(f.xCoords[gx]*f.scale, vy, f.p, f.ctx, f.feat, f.activation, nil, nil, idx)
+			writePixel(pix[idx*4:idx*4+4:idx*4+4], res)
+		}
+	}
 }
 
 type pixelResult struct {
@@ -424,6 +718,82 @@ type pixelResult struct {
 }
 
 func (r *Renderer) evaluatePixel(vx, vy float64, p params.Parameters, ctx frameParams, feat analyzer.Features, activation float64, noiseWarp, noiseDetail []float64, idx int) pixelResult {
+	src := patternSource{fn: r.pattern, screen: r.screen, layers: r.layers}
+	patternValue := r.fadeValue(r.evalPattern(&src, vx, vy, p, ctx), vx, vy, p, ctx)
+	combined := clampFloat(patternValue, -1.0, 1.0)
+
+	// gamma and contrast for better dynamic range
+	brightness := (combined*ctx.amplitude + 1.0) * 0.5
+	brightness = clamp01(brightness)
+	switch ctx.quality {
+	case qualityEco:
+		brightness = brightness * (0.7 + brightness*0.3)
+	default:
+		brightness = math.Pow(brightness, ctx.invGamma)
+		brightness = math.Pow(brightness, ctx.invContrast)
+	}
+	brightness = clamp01(brightness * ctx.brightnessScale)
+
+	if r.colorOnAudio {
+		brightness = clamp01(brightness * activation)
+	}
+
+	// post effects (vignette, bloom, trails, ...) in pipeline order
+	if len(r.chain.shades) > 0 {
+		brightness = r.chain.shade(pixelState{
+			vx:           vx,
+			vy:           vy,
+			x:            idx % r.gridWidth,
+			y:            idx / r.gridWidth,
+			step:         max(r.downsample, 1),
+			brightness:   brightness,
+			time:         ctx.time,
+			vignette:     ctx.vignette,
+			vignetteSoft: ctx.vignetteSoft,
+			hist:         &r.history,
+		}, idx)
+	}
+
+	brightness = clamp01(brightness)
+
+	colorBase := combined
+	if r.text.covers(idx) {
+		brightness = math.Max(brightness, r.text.level)
+		colorBase = r.text.hue
+	}
+	overlaid := r.overlay.covers(idx)
+	if overlaid {
+		brightness = 1
+		colorBase = r.overlay.hue
+	}
+
+	// glyph sharpness for better contrast
+	var glyphValue float64
+	if ctx.quality == qualityEco {
+		glyphValue = brightness
+	} else {
+		glyphValue = math.Pow(brightness, ctx.glyphSharpness)
+	}
+	h, s, v := r.colorFromMode(colorBase, brightness, p, feat, activation)
+	h, s, v = r.fadeColor(h, s, v, colorBase, brightness, p, feat, activation)
+	if overlaid && r.overlay.custom {
+		h, s, v = r.overlay.hsv[0], r.overlay.hsv[1], r.overlay.hsv[2]
+	}
+	if r.widgets.covers(idx) {
+		glyphValue, h, s, v = 1, 0, 0, 1
+	}
+
+	return pixelResult{
+		glyphValue: glyphValue,
+		h:          h,
+		s:          s,
+		v:          v,
+	}
+}
+
+// distort applies the zoom, rotation, swirl and warps of the frame to a
+// pattern coordinate.
+func (r *Renderer) distort(vx, vy float64, ctx frameParams) (float64, float64) {
 	// apply zoom and rotation for organic movement
 	baseX := vx * ctx.zoom
 	baseY := vy * ctx.zoom
@@ -463,50 +833,7 @@ func (r *Renderer) evaluatePixel(vx, vy float64, p params.Parameters, ctx frameP
 		distortedX += warp * strength
 		distortedY += warp * strength
 	}
-
-	patternValue := r.pattern(distortedX, distortedY, p, ctx.time)
-	combined := clampFloat(patternValue, -1.0, 1.0)
-
-	// gamma and contrast for better dynamic range
-	brightness := (combined*ctx.amplitude + 1.0) * 0.5
-	brightness = clamp01(brightness)
-	switch ctx.quality {
-	case qualityEco:
-		brightness = brightness * (0.7 + brightness*0.3)
-	default:
-		brightness = math.Pow(brightness, ctx.invGamma)
-		brightness = math.Pow(brightness, ctx.invContrast)
-	}
-	brightness = clamp01(brightness * ctx.brightnessScale)
-
-	if r.colorOnAudio {
-		brightness = clamp01(brightness * activation)
-	}
-
-	// vignette for depth
-	if ctx.vignette > 0 {
-		dist := math.Min(1.0, math.Hypot(vx, vy)*2.0)
-		vig := clamp01(1.0 - ctx.vignette*math.Pow(dist, 1.2))
-		brightness *= lerp(1.0, vig, 1.0-ctx.vignetteSoft)
-	}
-
-	brightness = clamp01(brightness)
-
-	// glyph sharpness for better contrast
-	var glyphValue float64
-	if ctx.quality == qualityEco {
-		glyphValue = brightness
-	} else {
-		glyphValue = math.Pow(brightness, ctx.glyphSharpness)
-	}
-	h, s, v := r.colorFromMode(combined, brightness, p, feat, activation)
-
-	return pixelResult{
-		glyphValue: glyphValue,
-		h:          h,
-		s:          s,
-		v:          v,
-	}
+	return r.chain.warp(distortedX, distortedY)
 }
 
 type frameParams struct {
@@ -525,6 +852,7 @@ type frameParams struct {
 	vignetteSoft    float64
 	glyphSharpness  float64
 	swirlStrength   float64
+	scale           float64
 	quality         qualityMode
 }
 
@@ -540,6 +868,10 @@ func (r *Renderer) buildFrameParams(p params.Parameters, time float64) framePara
 	invContrast := 1.0 / math.Max(0.2, p.Contrast)
 	vignetteSoft := clamp01(p.VignetteSoftness)
 	swirlStrength := p.DistortAmplitude * (0.5 + p.BeatDistortion*0.5)
+	scale := p.Scale
+	if scale <= 0 {
+		scale = 1
+	}
 
 	switch r.quality {
 	case qualityEco:
@@ -569,50 +901,59 @@ func (r *Renderer) buildFrameParams(p params.Parameters, time float64) framePara
 		vignetteSoft:    vignetteSoft,
 		glyphSharpness:  math.Max(0.2, p.GlyphSharpness),
 		swirlStrength:   swirlStrength,
+		scale:           scale,
 		quality:         r.quality,
 	}
 }
 
 func (r *Renderer) colorFromMode(base, brightness float64, p params.Parameters, feat analyzer.Features, activation float64) (float64, float64, float64) {
+	return r.colorIn(r.colorMode, r.curve, base, brightness, p, feat, activation)
+}
+
+// colorIn is colorFromMode for the colour mode mode with curve c.
+func (r *Renderer) colorIn(mode colorMode, c ColorCurve, base, brightness float64, p params.Parameters, feat analyzer.Features, activation float64) (float64, float64, float64) {
 	baseNorm := clamp01((base + 1.0) * 0.5)
 	shift := math.Mod(p.ColorShift/(2*math.Pi), 1.0)
 	if shift < 0 {
 		shift += 1.0
 	}
 
-	var h, s, v float64
-	switch r.colorMode {
+	hue := c.BaseHue + baseNorm*c.HueSpan + shift*c.ShiftSpan
+	v := clamp01(c.ValueMin + brightness*(c.ValueMax-c.ValueMin) + baseNorm*c.ValueDetail)
+	var h, s float64
+	switch mode {
 	case colorModeFire:
-		h = clamp01(0.02 + baseNorm*0.08 + shift*0.1)
-		s = clamp01(0.7 + brightness*0.25)
-		v = clamp01(0.35 + brightness*0.8 + baseNorm*0.2)
-	case colorModeAurora:
-		h = clamp01(0.45 + baseNorm*0.25 + shift*0.3)
-		s = clamp01(0.45 + p.Saturation*0.45)
-		v = clamp01(0.28 + brightness*0.85 + baseNorm*0.12)
-	case colorModeMono:
-		h = shift
-		s = 0.0
-		v = clamp01(brightness)
+		h = clamp01(hue)
+		s = clamp01(c.SatMin + brightness*(c.SatMax-c.SatMin))
+	case colorModeAurora, colorModeMono:
+		h = clamp01(hue)
+		s = clamp01(c.SatMin + p.Saturation*(c.SatMax-c.SatMin))
+	case colorModeGradient:
+		// the stops set the colours, only brightness picks among them
+		h, s, v = r.gradientColor(brightness)
 	default:
 		// neon colors only (red, cyan, blue, violet, pink)
-		hueBase := math.Mod(shift+baseNorm*0.35, 1.0)
-		h = hueBase
+		hueBase := math.Mod(hue, 1.0)
+		if hueBase < 0 {
+			hueBase += 1.0
+		}
 		if hueBase < 0.5 {
 			h = hueBase * 0.6
 		} else {
 			h = 0.5 + (hueBase-0.5)*0.7
 		}
-		s = clamp01(0.85 + p.Saturation*0.15) // high saturation for neon
-		v = clamp01(brightness*0.95 + baseNorm*0.15)
+		s = clamp01(c.SatMin + p.Saturation*(c.SatMax-c.SatMin)) // high saturation for neon
 	}
 
 	if r.colorOnAudio {
 		if feat.IsDrop {
 			activation = clamp01(activation + 0.2)
 		}
-		// always keep saturation high (neon), only adjust brightness
-		s = clamp01(0.75 + activation*0.25) // min 75% saturation
+		// always keep saturation high (neon), only adjust brightness;
+		// a gradient keeps its own colours
+		if mode != colorModeGradient {
+			s = clamp01(0.75 + activation*0.25) // min 75% saturation
+		}
 		v = clamp01(v * activation)
 		if v < 0.01 {
 			v = 0.0 // full black
@@ -631,6 +972,10 @@ func colorCode(index int) string {
 	return precomputedANSI[index]
 }
 
+func backgroundCode(index int) string {
+	return precomputedANSIBg[clampInt(index, 0, len(precomputedANSIBg)-1)]
+}
+
 func hsvToANSI(h, s, v float64) int {
 	r, g, b := hsvToRGB(h, s, v)
 	return rgbToANSI(r, g, b)
@@ -756,7 +1101,6 @@ func (r *Renderer) ensureCoordinateCache(width, height int) {
 func (r *Renderer) buildStatus(feat analyzer.Features, fps float64) string {
 	builder := &r.statusBuilder
 	builder.Reset()
-	builder.Grow(256)
 
 	// show web panel URL at the start if enabled
 	if r.showWebURL && r.webPanelURL != "" {
@@ -782,9 +1126,18 @@ func (r *Renderer) buildStatus(feat analyzer.Features, fps float64) string {
 	appendFloat(builder, feat.Treble, 2)
 	builder.WriteString(" beat ")
 	appendFloat(builder, feat.BeatStrength, 2)
+	if feat.Tempo > 0 {
+		builder.WriteString(" bpm ")
+		appendFloat(builder, feat.Tempo, 0)
+	}
 	builder.WriteString(" fps ")
 	appendFloat(builder, fps, 1)
-	return builder.String()
+	if err := r.lua.Err(); err != nil {
+		builder.WriteString(" | ")
+		builder.WriteString(err.Error())
+	}
+	r.status = builder.String(r.status)
+	return r.status
 }
 
 func colorModeLabel(mode colorMode) string {
@@ -795,27 +1148,72 @@ func colorModeLabel(mode colorMode) string {
 		return "AURORA"
 	case colorModeMono:
 		return "MONO"
+	case colorModeGradient:
+		return "GRADIENT"
 	default:
 		return "CHROMATIC"
 	}
 }
 
-func appendFloat(builder *strings.Builder, value float64, precision int) {
-	var buf [32]byte
-	b := strconv.AppendFloat(buf[:0], value, 'f', precision, 64)
-	builder.Write(b)
+func appendFloat(builder *rowWriter, value float64, precision int) {
+	builder.buf = strconv.AppendFloat(builder.buf, value, 'f', precision, 64)
 }
 
 func (r *Renderer) IsWindowed() bool {
-	if r.mode != backendSDL {
-		return false
+	switch r.mode {
+	case backendSDL:
+		return r.windowedSDL()
+	case backendGL:
+		return r.gl != nil
+	}
+	return false
+}
+
+// SetFeatureHistory hands the renderer the app's ring of recent features so
+// trailing visuals can look back over the last few seconds.
+func (r *Renderer) SetFeatureHistory(h *analyzer.History) {
+	r.features = h
+}
+
+// FeatureHistory returns up to the newest n frames of features, oldest
+// first. The slice is reused by the next call.
+func (r *Renderer) FeatureHistory(n int) []analyzer.Features {
+	r.featureBuf = r.features.Last(n, r.featureBuf)
+	return r.featureBuf
+}
+
+// SetCapture makes Render also fill Frame.Image, for output sinks that want
+// pixels rather than terminal rows.
+func (r *Renderer) SetCapture(enabled bool) {
+	r.capture = enabled
+	if !enabled {
+		r.captureImg = nil
 	}
-	return r.windowedSDL()
+}
+
+// Restart tears down and re-opens the output backend, keeping pattern,
+// palette and effect settings. The terminal backend has nothing to reopen.
+func (r *Renderer) Restart() error {
+	switch r.mode {
+	case backendSDL:
+		_ = r.closeSDL()
+		return r.initSDL(r.width, r.height)
+	case backendGL:
+		_ = r.closeGL()
+		return r.initGL(r.width, r.height)
+	}
+	return nil
 }
 
 func (r *Renderer) Close() error {
-	if r.mode == backendSDL {
+	r.stopPool()
+	switch r.mode {
+	case backendSDL:
 		return r.closeSDL()
+	case backendDRM:
+		return r.closeDRM()
+	case backendGL:
+		return r.closeGL()
 	}
 	return nil
 }
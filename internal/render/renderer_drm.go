@@ -0,0 +1,442 @@
+//go:build drm && linux
+
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+	"golang.org/x/sys/unix"
+)
+
+// The kernel mode setting uapi (drm.h, drm_mode.h). Only the calls needed to
+// light one output with two dumb buffers are covered; libdrm is not needed.
+const (
+	drmIoctlModeGetResources = 0xA0
+	drmIoctlModeGetCrtc      = 0xA1
+	drmIoctlModeSetCrtc      = 0xA2
+	drmIoctlModeGetEncoder   = 0xA6
+	drmIoctlModeGetConnector = 0xA7
+	drmIoctlModeAddFB        = 0xAE
+	drmIoctlModeRmFB         = 0xAF
+	drmIoctlModePageFlip     = 0xB0
+	drmIoctlModeCreateDumb   = 0xB2
+	drmIoctlModeMapDumb      = 0xB3
+	drmIoctlModeDestroyDumb  = 0xB4
+
+	drmModeConnected     = 1
+	drmModeTypePreferred = 1 << 3
+	drmModePageFlipEvent = 0x01
+
+	// drmDot is how many screen pixels wide one rendered pixel is at
+	// scale 1; full HD at every pixel is more than a Pi can evaluate.
+	drmDot = 4
+)
+
+type drmModeCardRes struct {
+	FbIDPtr, CrtcIDPtr, ConnectorIDPtr, EncoderIDPtr     uint64
+	CountFbs, CountCrtcs, CountConnectors, CountEncoders uint32
+	MinWidth, MaxWidth, MinHeight, MaxHeight             uint32
+}
+
+type drmModeInfo struct {
+	Clock                                         uint32
+	Hdisplay, HsyncStart, HsyncEnd, Htotal, Hskew uint16
+	Vdisplay, VsyncStart, VsyncEnd, Vtotal, Vscan uint16
+	Vrefresh, Flags, Type                         uint32
+	Name                                          [32]byte
+}
+
+type drmModeGetConnector struct {
+	EncodersPtr, ModesPtr, PropsPtr, PropValuesPtr   uint64
+	CountModes, CountProps, CountEncoders            uint32
+	EncoderID, ConnectorID, ConnectorType, TypeID    uint32
+	Connection, MmWidth, MmHeight, Subpixel, Padding uint32
+}
+
+type drmModeGetEncoder struct {
+	EncoderID, EncoderType, CrtcID, PossibleCrtcs, PossibleClones uint32
+}
+
+type drmModeCrtc struct {
+	SetConnectorsPtr                                      uint64
+	CountConnectors, CrtcID, FbID, X, Y, GammaSize, Valid uint32
+	Mode                                                  drmModeInfo
+}
+
+type drmModeCreateDumb struct {
+	Height, Width, Bpp, Flags, Handle, Pitch uint32
+	Size                                     uint64
+}
+
+type drmModeMapDumb struct {
+	Handle, Pad uint32
+	Offset      uint64
+}
+
+type drmModeFBCmd struct {
+	FbID, Width, Height, Pitch, Bpp, Depth, Handle uint32
+}
+
+type drmModePageFlip struct {
+	CrtcID, FbID, Flags, Reserved uint32
+	UserData                      uint64
+}
+
+// drmBuffer is one scan-out buffer mapped into our memory.
+type drmBuffer struct {
+	handle uint32
+	fb     uint32
+	pitch  int
+	mem    []byte
+}
+
+type drmState struct {
+	file      *os.File
+	connector uint32
+	crtc      uint32
+	mode      drmModeInfo
+	saved     drmModeCrtc
+	buffers   [2]drmBuffer
+	back      int
+	started   bool
+	flipping  bool
+	img       *image.RGBA
+	dot       int
+	event     []byte
+}
+
+// drmIoctl issues DRM ioctl nr with arg (a pointer to one of the structs
+// above), retrying on EINTR.
+func drmIoctl(fd uintptr, nr uintptr, arg unsafe.Pointer, size uintptr) error {
+	// _IOWR('d', nr, size) on the generic ioctl layout (arm, arm64, x86)
+	req := 3<<30 | size<<16 | 'd'<<8 | nr
+	for {
+		_, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, req, uintptr(arg))
+		if errno == unix.EINTR || errno == unix.EAGAIN {
+			continue
+		}
+		if errno != 0 {
+			return errno
+		}
+		return nil
+	}
+}
+
+func ioctlArg[T any](fd uintptr, nr uintptr, arg *T) error {
+	return drmIoctl(fd, nr, unsafe.Pointer(arg), unsafe.Sizeof(*arg))
+}
+
+// ptrOf passes a slice to the kernel; callers keep the slice alive until the
+// ioctl returns.
+func ptrOf[T any](s []T) uint64 {
+	if len(s) == 0 {
+		return 0
+	}
+	return uint64(uintptr(unsafe.Pointer(&s[0])))
+}
+
+// initDRM takes over the first card with a connected display (or
+// GOLIZER_DRM_DEVICE) at its preferred mode. The renderer's size becomes
+// the mode's.
+func (r *Renderer) initDRM() error {
+	paths := []string{strings.TrimSpace(os.Getenv("GOLIZER_DRM_DEVICE"))}
+	if paths[0] == "" {
+		paths, _ = filepath.Glob("/dev/dri/card*")
+		sort.Strings(paths)
+	}
+	var errs []error
+	for _, path := range paths {
+		state, err := openDRM(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		r.drm = state
+		r.mode = backendDRM
+		r.useANSI = false
+		r.width = int(state.mode.Hdisplay)
+		r.height = int(state.mode.Vdisplay)
+		return nil
+	}
+	if len(errs) == 0 {
+		return errors.New("drm: no /dev/dri/card* devices")
+	}
+	return fmt.Errorf("drm: no usable display: %w", errors.Join(errs...))
+}
+
+func openDRM(path string) (*drmState, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	s := &drmState{file: f, event: make([]byte, 1024)}
+	if err := s.pickOutput(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	s.saved.CrtcID = s.crtc
+	_ = ioctlArg(s.fd(), drmIoctlModeGetCrtc, &s.saved)
+	for i := range s.buffers {
+		if err := s.createBuffer(&s.buffers[i]); err != nil {
+			s.close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *drmState) fd() uintptr { return s.file.Fd() }
+
+// pickOutput finds a connected connector, its preferred mode and a CRTC
+// that can drive it.
+func (s *drmState) pickOutput() error {
+	var res drmModeCardRes
+	if err := ioctlArg(s.fd(), drmIoctlModeGetResources, &res); err != nil {
+		return fmt.Errorf("get resources (not a modesetting device?): %w", err)
+	}
+	crtcs := make([]uint32, res.CountCrtcs)
+	connectors := make([]uint32, res.CountConnectors)
+	res = drmModeCardRes{
+		CrtcIDPtr:       ptrOf(crtcs),
+		ConnectorIDPtr:  ptrOf(connectors),
+		CountCrtcs:      uint32(len(crtcs)),
+		CountConnectors: uint32(len(connectors)),
+	}
+	if err := ioctlArg(s.fd(), drmIoctlModeGetResources, &res); err != nil {
+		return fmt.Errorf("get resources: %w", err)
+	}
+
+	for _, id := range connectors {
+		conn := drmModeGetConnector{ConnectorID: id}
+		if err := ioctlArg(s.fd(), drmIoctlModeGetConnector, &conn); err != nil {
+			continue
+		}
+		if conn.Connection != drmModeConnected || conn.CountModes == 0 {
+			continue
+		}
+		modes := make([]drmModeInfo, conn.CountModes)
+		encoders := make([]uint32, conn.CountEncoders)
+		conn = drmModeGetConnector{
+			ConnectorID:   id,
+			ModesPtr:      ptrOf(modes),
+			EncodersPtr:   ptrOf(encoders),
+			CountModes:    uint32(len(modes)),
+			CountEncoders: uint32(len(encoders)),
+		}
+		if err := ioctlArg(s.fd(), drmIoctlModeGetConnector, &conn); err != nil {
+			continue
+		}
+		modes = modes[:min(int(conn.CountModes), len(modes))]
+		encoders = encoders[:min(int(conn.CountEncoders), len(encoders))]
+		if len(modes) == 0 {
+			continue
+		}
+		crtc := s.findCrtc(conn.EncoderID, encoders, crtcs)
+		if crtc == 0 {
+			continue
+		}
+		s.connector = id
+		s.crtc = crtc
+		s.mode = modes[0]
+		for _, m := range modes {
+			if m.Type&drmModeTypePreferred != 0 {
+				s.mode = m
+				break
+			}
+		}
+		return nil
+	}
+	return errors.New("no connected display")
+}
+
+// findCrtc prefers the CRTC already driving the connector, else the first
+// one any of its encoders can use.
+func (s *drmState) findCrtc(current uint32, encoders, crtcs []uint32) uint32 {
+	if current != 0 {
+		enc := drmModeGetEncoder{EncoderID: current}
+		if ioctlArg(s.fd(), drmIoctlModeGetEncoder, &enc) == nil && enc.CrtcID != 0 {
+			return enc.CrtcID
+		}
+	}
+	for _, id := range encoders {
+		enc := drmModeGetEncoder{EncoderID: id}
+		if ioctlArg(s.fd(), drmIoctlModeGetEncoder, &enc) != nil {
+			continue
+		}
+		for i, crtc := range crtcs {
+			if enc.PossibleCrtcs&(1<<i) != 0 {
+				return crtc
+			}
+		}
+	}
+	return 0
+}
+
+func (s *drmState) createBuffer(b *drmBuffer) error {
+	create := drmModeCreateDumb{Width: uint32(s.mode.Hdisplay), Height: uint32(s.mode.Vdisplay), Bpp: 32}
+	if err := ioctlArg(s.fd(), drmIoctlModeCreateDumb, &create); err != nil {
+		return fmt.Errorf("create dumb buffer: %w", err)
+	}
+	b.handle = create.Handle
+	b.pitch = int(create.Pitch)
+
+	fb := drmModeFBCmd{Width: create.Width, Height: create.Height, Pitch: create.Pitch, Bpp: 32, Depth: 24, Handle: create.Handle}
+	if err := ioctlArg(s.fd(), drmIoctlModeAddFB, &fb); err != nil {
+		return fmt.Errorf("add framebuffer: %w", err)
+	}
+	b.fb = fb.FbID
+
+	mapping := drmModeMapDumb{Handle: create.Handle}
+	if err := ioctlArg(s.fd(), drmIoctlModeMapDumb, &mapping); err != nil {
+		return fmt.Errorf("map dumb buffer: %w", err)
+	}
+	mem, err := unix.Mmap(int(s.fd()), int64(mapping.Offset), int(create.Size), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		return fmt.Errorf("mmap: %w", err)
+	}
+	b.mem = mem
+	clear(b.mem)
+	return nil
+}
+
+// drmGrid returns the rendered pixel grid for the display mode.
+func (r *Renderer) drmGrid() (gridW, gridH int) {
+	s := r.drm
+	s.dot = max(1, int(math.Round(drmDot/r.scale)))
+	return (r.width + s.dot - 1) / s.dot, (r.height + s.dot - 1) / s.dot
+}
+
+func (r *Renderer) renderDRM(p params.Parameters, feat analyzer.Features, fps float64, ctx frameParams, activation float64, xCoords, yCoords []float64, scale float64) Frame {
+	s := r.drm
+	gridW, gridH := len(xCoords), len(yCoords)
+	if s.img == nil || s.img.Rect.Dx() != gridW || s.img.Rect.Dy() != gridH {
+		s.img = image.NewRGBA(image.Rect(0, 0, gridW, gridH))
+	}
+	r.fillImage(s.img, p, feat, ctx, activation, xCoords, yCoords, scale)
+
+	frame := Frame{
+		Status: r.buildStatus(feat, fps),
+		Present: func(string) error {
+			return s.present(r.width, r.height)
+		},
+	}
+	if r.capture {
+		frame.Image = s.img
+	}
+	return frame
+}
+
+// present waits for the previous flip, scales the frame into the back
+// buffer and queues it for the next vblank.
+func (s *drmState) present(width, height int) error {
+	if s.flipping {
+		// the flip-complete event; the buffer it released is ours again
+		if _, err := s.file.Read(s.event); err != nil {
+			return fmt.Errorf("drm: wait for flip: %w", err)
+		}
+		s.flipping = false
+	}
+
+	buf := &s.buffers[s.back]
+	drmScale(buf.mem, buf.pitch, s.img, s.dot, width, height)
+
+	if !s.started {
+		conn := s.connector
+		set := drmModeCrtc{
+			SetConnectorsPtr: uint64(uintptr(unsafe.Pointer(&conn))),
+			CountConnectors:  1,
+			CrtcID:           s.crtc,
+			FbID:             buf.fb,
+			Valid:            1,
+			Mode:             s.mode,
+		}
+		err := ioctlArg(s.fd(), drmIoctlModeSetCrtc, &set)
+		runtime.KeepAlive(&conn)
+		if err != nil {
+			return fmt.Errorf("drm: set mode (is X or another DRM master running?): %w", err)
+		}
+		s.started = true
+	} else {
+		flip := drmModePageFlip{CrtcID: s.crtc, FbID: buf.fb, Flags: drmModePageFlipEvent}
+		if err := ioctlArg(s.fd(), drmIoctlModePageFlip, &flip); err != nil {
+			return fmt.Errorf("drm: page flip: %w", err)
+		}
+		s.flipping = true
+	}
+	s.back = 1 - s.back
+	return nil
+}
+
+// close restores whatever was on screen before and frees the buffers.
+func (s *drmState) close() {
+	if s.flipping {
+		_, _ = s.file.Read(s.event)
+	}
+	if s.started && s.saved.FbID != 0 {
+		conn := s.connector
+		s.saved.SetConnectorsPtr = uint64(uintptr(unsafe.Pointer(&conn)))
+		s.saved.CountConnectors = 1
+		_ = ioctlArg(s.fd(), drmIoctlModeSetCrtc, &s.saved)
+		runtime.KeepAlive(&conn)
+	}
+	for i := range s.buffers {
+		b := &s.buffers[i]
+		if b.mem != nil {
+			_ = unix.Munmap(b.mem)
+		}
+		if b.fb != 0 {
+			fb := b.fb
+			_ = ioctlArg(s.fd(), drmIoctlModeRmFB, &fb)
+		}
+		if b.handle != 0 {
+			destroy := b.handle
+			_ = ioctlArg(s.fd(), drmIoctlModeDestroyDumb, &destroy)
+		}
+	}
+	s.file.Close()
+}
+
+func (r *Renderer) closeDRM() error {
+	if r.drm == nil {
+		return nil
+	}
+	r.drm.close()
+	r.drm = nil
+	return nil
+}
+
+func SupportsDRM() bool { return true }
+
+// drmScale blows img up by dot into an XRGB8888 buffer of width x height
+// pixels with the given pitch, clamping at the image edges.
+func drmScale(mem []byte, pitch int, img *image.RGBA, dot, width, height int) {
+	gridW := img.Rect.Dx()
+	for y := 0; y < height; y++ {
+		row := mem[y*pitch : y*pitch+width*4]
+		if y%dot != 0 {
+			// same source row as the line above
+			copy(row, mem[(y-1)*pitch:])
+			continue
+		}
+		src := img.Pix[min(y/dot, img.Rect.Dy()-1)*img.Stride:]
+		for x := 0; x < width; x++ {
+			px := src[min(x/dot, gridW-1)*4:]
+			// XRGB8888, little endian
+			row[x*4+0] = px[2]
+			row[x*4+1] = px[1]
+			row[x*4+2] = px[0]
+			row[x*4+3] = 0xff
+		}
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !drm || !linux
+
+package render
+
+import (
+	"errors"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+type drmState struct{}
+
+func (r *Renderer) initDRM() error {
+	return errors.New("DRM backend not enabled; rebuild with -tags drm (linux only)")
+}
+
+func (r *Renderer) drmGrid() (int, int) { return r.width, r.height }
+
+func (r *Renderer) renderDRM(p params.Parameters, feat analyzer.Features, fps float64, ctx frameParams, activation float64, xCoords, yCoords []float64, scale float64) Frame {
+	return Frame{
+		Status: "DRM backend unavailable (build without -tags drm)",
+		Present: func(string) error {
+			return ErrRendererQuit
+		},
+	}
+}
+
+func (r *Renderer) closeDRM() error { return nil }
+
+func SupportsDRM() bool { return false }
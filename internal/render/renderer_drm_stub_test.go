@@ -0,0 +1,18 @@
+//go:build !drm || !linux
+
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDRMNeedsBuildTag(t *testing.T) {
+	if SupportsDRM() {
+		t.Fatal("SupportsDRM() = true without the drm tag")
+	}
+	_, err := NewWithBackend(BackendDRM, 80, 24, "", "", "", "", false, false)
+	if err == nil || !strings.Contains(err.Error(), "-tags drm") {
+		t.Fatalf("got %v, want a hint to rebuild with -tags drm", err)
+	}
+}
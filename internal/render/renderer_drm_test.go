@@ -0,0 +1,60 @@
+//go:build drm && linux
+
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDRMGrid(t *testing.T) {
+	cases := []struct {
+		scale       float64
+		dot, gw, gh int
+	}{
+		{1, 4, 480, 270},
+		{0.5, 8, 240, 135},
+		{2, 2, 960, 540},
+		{8, 1, 1920, 1080},
+	}
+	for _, c := range cases {
+		r := &Renderer{width: 1920, height: 1080, scale: c.scale, drm: &drmState{}}
+		gw, gh := r.drmGrid()
+		if r.drm.dot != c.dot || gw != c.gw || gh != c.gh {
+			t.Errorf("scale %v: got dot %d grid %dx%d, want dot %d grid %dx%d", c.scale, r.drm.dot, gw, gh, c.dot, c.gw, c.gh)
+		}
+	}
+}
+
+func TestDRMScale(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.SetRGBA(0, 0, color.RGBA{R: 10, G: 20, B: 30, A: 255})
+	img.SetRGBA(1, 0, color.RGBA{R: 40, A: 255})
+	img.SetRGBA(0, 1, color.RGBA{G: 50, A: 255})
+	img.SetRGBA(1, 1, color.RGBA{B: 60, A: 255})
+
+	// 5x5 screen at dot 2: the last row and column run past the image and
+	// repeat its edge; the pitch leaves padding that must not be touched
+	const width, height, pitch = 5, 5, 24
+	mem := make([]byte, pitch*height)
+	for i := range mem {
+		mem[i] = 0xaa
+	}
+	drmScale(mem, pitch, img, 2, width, height)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := img.RGBAAt(min(x/2, 1), min(y/2, 1))
+			got := mem[y*pitch+x*4 : y*pitch+x*4+4]
+			if want := []byte{c.B, c.G, c.R, 0xff}; string(got) != string(want) {
+				t.Errorf("(%d,%d): got % x, want % x", x, y, got, want)
+			}
+		}
+		for i := width * 4; i < pitch; i++ {
+			if mem[y*pitch+i] != 0xaa {
+				t.Fatalf("row %d: padding byte %d overwritten", y, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,459 @@
+//go:build gl
+
+package render
+
+/*
+#cgo LDFLAGS: -lGLESv2
+#include <stdlib.h>
+#include <GLES2/gl2.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// glBlitShader draws the CPU fallback image.
+const glBlitShader = `precision mediump float;
+uniform sampler2D u_image;
+uniform vec2 u_resolution;
+void main() {
+	vec2 uv = gl_FragCoord.xy / u_resolution;
+	gl_FragColor = texture2D(u_image, vec2(uv.x, 1.0 - uv.y));
+}
+`
+
+var glQuad = [...]float32{-1, -1, 1, -1, -1, 1, 1, 1}
+
+// glFxNames are the effect stage uniforms; there are at most as many stages
+// as effects.
+var glFxNames = func() []string {
+	out := make([]string, len(defaultEffectOrder))
+	for i := range out {
+		out[i] = fmt.Sprintf("u_fx%d", i)
+	}
+	return out
+}()
+
+type glState struct {
+	initialized bool
+	window      *sdl.Window
+	context     sdl.GLContext
+	quad        C.GLuint
+	texture     C.GLuint
+	blit        *glProgram
+	// programs caches one program per glShader key; nil marks a shader
+	// that failed to build, whose frames then render on the CPU
+	programs map[string]*glProgram
+	// user is the loaded "shader:" file, rebuilt when it changes on disk
+	user        *glProgram
+	userPath    string
+	userMod     time.Time
+	userErr     error
+	img         *image.RGBA
+	readback    *image.RGBA
+	windowTitle string
+	err         error
+}
+
+type glProgram struct {
+	id       C.GLuint
+	uniforms map[string]C.GLint
+}
+
+func (r *Renderer) initGL(width, height int) error {
+	if err := sdl.InitSubSystem(sdl.INIT_VIDEO); err != nil {
+		return err
+	}
+	r.gl = &glState{initialized: true, programs: make(map[string]*glProgram)}
+	r.mode = backendGL
+	r.useANSI = false
+	return nil
+}
+
+// ensureGLResources opens the window and GLES 2.0 context on first use. The
+// context is bound to the calling OS thread, so the goroutine that renders
+// is locked to it; Render and Present must run on that goroutine.
+func (r *Renderer) ensureGLResources() error {
+	state := r.gl
+	if state == nil {
+		return fmt.Errorf("GL backend not initialized")
+	}
+	if state.window != nil {
+		return nil
+	}
+	_ = sdl.GLSetAttribute(sdl.GL_CONTEXT_PROFILE_MASK, sdl.GL_CONTEXT_PROFILE_ES)
+	_ = sdl.GLSetAttribute(sdl.GL_CONTEXT_MAJOR_VERSION, 2)
+	_ = sdl.GLSetAttribute(sdl.GL_CONTEXT_MINOR_VERSION, 0)
+	_ = sdl.GLSetAttribute(sdl.GL_DOUBLEBUFFER, 1)
+
+	flags := uint32(sdl.WINDOW_SHOWN | sdl.WINDOW_OPENGL | sdl.WINDOW_RESIZABLE)
+	if r.fullscreen {
+		flags |= sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
+	window, err := sdl.CreateWindow("golizer", sdl.WINDOWPOS_CENTERED, sdl.WINDOWPOS_CENTERED,
+		int32(r.width), int32(r.height), flags)
+	if err != nil {
+		return err
+	}
+	runtime.LockOSThread()
+	context, err := window.GLCreateContext()
+	if err != nil {
+		window.Destroy()
+		runtime.UnlockOSThread()
+		return err
+	}
+	state.window = window
+	state.context = context
+	_ = sdl.GLSetSwapInterval(1)
+
+	C.glGenBuffers(1, &state.quad)
+	C.glBindBuffer(C.GL_ARRAY_BUFFER, state.quad)
+	C.glBufferData(C.GL_ARRAY_BUFFER, C.GLsizeiptr(len(glQuad)*4), unsafe.Pointer(&glQuad[0]), C.GL_STATIC_DRAW)
+
+	C.glGenTextures(1, &state.texture)
+	C.glBindTexture(C.GL_TEXTURE_2D, state.texture)
+	C.glTexParameteri(C.GL_TEXTURE_2D, C.GL_TEXTURE_MIN_FILTER, C.GL_LINEAR)
+	C.glTexParameteri(C.GL_TEXTURE_2D, C.GL_TEXTURE_MAG_FILTER, C.GL_LINEAR)
+	C.glTexParameteri(C.GL_TEXTURE_2D, C.GL_TEXTURE_WRAP_S, C.GL_CLAMP_TO_EDGE)
+	C.glTexParameteri(C.GL_TEXTURE_2D, C.GL_TEXTURE_WRAP_T, C.GL_CLAMP_TO_EDGE)
+
+	blit, err := compileGLProgram(glBlitShader)
+	if err != nil {
+		return err
+	}
+	state.blit = blit
+	return nil
+}
+
+// program returns the program for a glShader key, building it on first use.
+func (s *glState) program(key string, pattern string, warps, shades []string) *glProgram {
+	if prog, ok := s.programs[key]; ok {
+		return prog
+	}
+	src, _ := glFragmentSource(pattern, warps, shades)
+	prog, err := compileGLProgram(src)
+	if err != nil {
+		s.err = fmt.Errorf("%s shader: %w", pattern, err)
+	}
+	s.programs[key] = prog
+	return prog
+}
+
+// userProgram returns the program of a "shader:" pattern, reloading the file
+// when its modification time changes so shaders can be edited live. An edit
+// that fails to build keeps the last good program of the same file.
+func (r *Renderer) userProgram() (*glProgram, error) {
+	state := r.gl
+	path, err := r.shaderPath(r.patternName)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if path == state.userPath && info.ModTime().Equal(state.userMod) {
+		return state.user, state.userErr
+	}
+	if path != state.userPath && state.user != nil {
+		C.glDeleteProgram(state.user.id)
+		state.user = nil
+	}
+	state.userPath, state.userMod = path, info.ModTime()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state.user, err
+	}
+	prog, err := compileGLProgram(userShaderSource(string(data)))
+	if err != nil {
+		return state.user, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if state.user != nil {
+		C.glDeleteProgram(state.user.id)
+	}
+	state.user = prog
+	return prog, nil
+}
+
+func compileGLProgram(fragment string) (*glProgram, error) {
+	vs, err := compileGLShader(C.GL_VERTEX_SHADER, glVertexShader)
+	if err != nil {
+		return nil, err
+	}
+	defer C.glDeleteShader(vs)
+	fs, err := compileGLShader(C.GL_FRAGMENT_SHADER, fragment)
+	if err != nil {
+		return nil, err
+	}
+	defer C.glDeleteShader(fs)
+
+	id := C.glCreateProgram()
+	C.glAttachShader(id, vs)
+	C.glAttachShader(id, fs)
+	name := C.CString("a_pos")
+	defer C.free(unsafe.Pointer(name))
+	C.glBindAttribLocation(id, 0, name)
+	C.glLinkProgram(id)
+	var ok C.GLint
+	C.glGetProgramiv(id, C.GL_LINK_STATUS, &ok)
+	if ok == 0 {
+		var buf [1024]C.GLchar
+		C.glGetProgramInfoLog(id, C.GLsizei(len(buf)), nil, &buf[0])
+		C.glDeleteProgram(id)
+		return nil, fmt.Errorf("link: %s", glInfoLog(buf[:]))
+	}
+	return &glProgram{id: id, uniforms: make(map[string]C.GLint)}, nil
+}
+
+// loc looks up a uniform location once per program.
+func (p *glProgram) loc(name string) C.GLint {
+	if l, ok := p.uniforms[name]; ok {
+		return l
+	}
+	cname := C.CString(name)
+	l := C.glGetUniformLocation(p.id, cname)
+	C.free(unsafe.Pointer(cname))
+	p.uniforms[name] = l
+	return l
+}
+
+func compileGLShader(kind C.GLenum, source string) (C.GLuint, error) {
+	shader := C.glCreateShader(kind)
+	src := C.CString(source)
+	defer C.free(unsafe.Pointer(src))
+	C.glShaderSource(shader, 1, (**C.GLchar)(unsafe.Pointer(&src)), nil)
+	C.glCompileShader(shader)
+	var ok C.GLint
+	C.glGetShaderiv(shader, C.GL_COMPILE_STATUS, &ok)
+	if ok == 0 {
+		var buf [1024]C.GLchar
+		C.glGetShaderInfoLog(shader, C.GLsizei(len(buf)), nil, &buf[0])
+		C.glDeleteShader(shader)
+		return 0, fmt.Errorf("compile: %s", glInfoLog(buf[:]))
+	}
+	return shader, nil
+}
+
+// glInfoLog flattens a compiler log onto one line for the status text.
+func glInfoLog(buf []C.GLchar) string {
+	log := strings.TrimSpace(C.GoString((*C.char)(unsafe.Pointer(&buf[0]))))
+	return strings.Join(strings.Fields(strings.ReplaceAll(log, "\n", "; ")), " ")
+}
+
+func (p *glProgram) set1(name string, v float32) {
+	C.glUniform1f(p.loc(name), C.GLfloat(v))
+}
+
+func (p *glProgram) set2(name string, x, y float32) {
+	C.glUniform2f(p.loc(name), C.GLfloat(x), C.GLfloat(y))
+}
+
+func (p *glProgram) set3(name string, x, y, z float32) {
+	C.glUniform3f(p.loc(name), C.GLfloat(x), C.GLfloat(y), C.GLfloat(z))
+}
+
+func (p *glProgram) apply(u glUniforms, w, h int) {
+	p.set2("u_resolution", float32(w), float32(h))
+	p.set1("u_time", u.time)
+	p.set1("u_zoom", u.zoom)
+	p.set2("u_rot", u.sinRot, u.cosRot)
+	p.set1("u_noiseScale", u.noiseScale)
+	p.set1("u_warp", u.warp)
+	p.set1("u_swirl", u.swirl)
+	p.set1("u_amplitude", u.amplitude)
+	p.set1("u_invGamma", u.invGamma)
+	p.set1("u_invContrast", u.invContrast)
+	p.set1("u_brightness", u.brightness)
+	p.set1("u_vignette", u.vignette)
+	p.set1("u_vignetteSoft", u.vignetteSoft)
+	p.set1("u_quality", u.quality)
+	p.set1("u_octaves", u.octaves)
+	p.set1("u_beat", u.beat)
+	p.set1("u_amp", u.amp)
+	p.set1("u_scale", u.scale)
+	p.set1("u_shift", u.shift)
+	p.set1("u_saturation", u.saturation)
+	p.set1("u_activation", u.activation)
+	p.set1("u_colorOnAudio", u.colorOnAudio)
+	p.set1("u_drop", u.drop)
+	p.set1("u_colorMode", u.colorMode)
+	p.set3("u_curveHue", u.curveHue[0], u.curveHue[1], u.curveHue[2])
+	p.set2("u_curveSat", u.curveSat[0], u.curveSat[1])
+	p.set3("u_curveValue", u.curveValue[0], u.curveValue[1], u.curveValue[2])
+	for i, v := range u.fx {
+		C.glUniform4f(p.loc(glFxNames[i]), C.GLfloat(v[0]), C.GLfloat(v[1]), C.GLfloat(v[2]), C.GLfloat(v[3]))
+	}
+	C.glUniform4f(p.loc("u_pattern"), C.GLfloat(u.pattern[0]), C.GLfloat(u.pattern[1]), C.GLfloat(u.pattern[2]), C.GLfloat(u.pattern[3]))
+}
+
+func drawGLQuad() {
+	C.glDrawArrays(C.GL_TRIANGLE_STRIP, 0, 4)
+}
+
+func (p *glProgram) applyUser(u glUserUniforms, w, h int) {
+	p.set2("u_resolution", float32(w), float32(h))
+	p.set1("u_time", u.time)
+	p.set1("u_bass", u.bass)
+	p.set1("u_mid", u.mid)
+	p.set1("u_treble", u.treble)
+	p.set1("u_energy", u.energy)
+	p.set1("u_beat", u.beat)
+	p.set1("u_drop", u.drop)
+	p.set1("u_colorShift", u.colorShift)
+}
+
+// renderGL draws the frame at the window's drawable size with the pattern's
+// shader, or evaluates it on the CPU at the grid size and uploads it as a
+// texture when glShader says the shader can't.
+func (r *Renderer) renderGL(p params.Parameters, feat analyzer.Features, fps float64, ctx frameParams, activation float64, xCoords, yCoords []float64, scale float64) Frame {
+	if err := r.ensureGLResources(); err != nil {
+		return Frame{
+			Status: fmt.Sprintf("GL init error: %v", err),
+			Present: func(string) error {
+				return err
+			},
+		}
+	}
+	state := r.gl
+	w, h := state.window.GLGetDrawableSize()
+	drawW, drawH := int(w), int(h)
+	C.glViewport(0, 0, C.GLsizei(drawW), C.GLsizei(drawH))
+	C.glBindBuffer(C.GL_ARRAY_BUFFER, state.quad)
+	C.glEnableVertexAttribArray(0)
+	C.glVertexAttribPointer(0, 2, C.GL_FLOAT, C.GL_FALSE, 0, nil)
+
+	var prog *glProgram
+	if key, warps, shades, ok := r.glShader(); ok {
+		prog = state.program(key, r.patternName, warps, shades)
+	}
+	var img *image.RGBA
+	switch {
+	case isShaderPattern(r.patternName):
+		user, err := r.userProgram()
+		state.userErr = err
+		if user == nil {
+			C.glClearColor(0, 0, 0, 1)
+			C.glClear(C.GL_COLOR_BUFFER_BIT)
+			break
+		}
+		C.glUseProgram(user.id)
+		user.applyUser(glUserUniformValues(p, feat), drawW, drawH)
+		drawGLQuad()
+	case prog != nil:
+		C.glUseProgram(prog.id)
+		prog.apply(r.glUniformValues(p, feat, ctx, activation, scale), drawW, drawH)
+		drawGLQuad()
+	default:
+		gridW, gridH := len(xCoords), len(yCoords)
+		if state.img == nil || state.img.Rect.Dx() != gridW || state.img.Rect.Dy() != gridH {
+			state.img = image.NewRGBA(image.Rect(0, 0, gridW, gridH))
+		}
+		r.fillImage(state.img, p, feat, ctx, activation, xCoords, yCoords, scale)
+		img = state.img
+		C.glActiveTexture(C.GL_TEXTURE0)
+		C.glBindTexture(C.GL_TEXTURE_2D, state.texture)
+		C.glTexImage2D(C.GL_TEXTURE_2D, 0, C.GL_RGBA, C.GLsizei(gridW), C.GLsizei(gridH), 0,
+			C.GL_RGBA, C.GL_UNSIGNED_BYTE, unsafe.Pointer(&img.Pix[0]))
+		C.glUseProgram(state.blit.id)
+		C.glUniform1i(state.blit.loc("u_image"), 0)
+		state.blit.set2("u_resolution", float32(drawW), float32(drawH))
+		drawGLQuad()
+	}
+
+	if r.capture && img == nil {
+		img = state.readPixels(drawW, drawH)
+	}
+
+	status := r.buildStatus(feat, fps)
+	if isShaderPattern(r.patternName) && state.userErr != nil {
+		status = fmt.Sprintf("%s | %v", status, state.userErr)
+	} else if state.err != nil {
+		status = fmt.Sprintf("%s | %v", status, state.err)
+	}
+	frame := Frame{
+		Status: status,
+		Present: func(status string) error {
+			if status != "" && status != state.windowTitle {
+				state.window.SetTitle(status)
+				state.windowTitle = status
+			}
+			state.window.GLSwap()
+			return r.pollWindowEvents()
+		},
+	}
+	if r.capture {
+		frame.Image = img
+	}
+	return frame
+}
+
+// readPixels copies the back buffer for capture sinks, flipping it to the
+// top-down row order image.RGBA uses.
+func (s *glState) readPixels(w, h int) *image.RGBA {
+	if s.readback == nil || s.readback.Rect.Dx() != w || s.readback.Rect.Dy() != h {
+		s.readback = image.NewRGBA(image.Rect(0, 0, w, h))
+	}
+	pix := s.readback.Pix
+	C.glReadPixels(0, 0, C.GLsizei(w), C.GLsizei(h), C.GL_RGBA, C.GL_UNSIGNED_BYTE, unsafe.Pointer(&pix[0]))
+	stride := s.readback.Stride
+	row := make([]byte, stride)
+	for top, bottom := 0, h-1; top < bottom; top, bottom = top+1, bottom-1 {
+		a := pix[top*stride : (top+1)*stride]
+		b := pix[bottom*stride : (bottom+1)*stride]
+		copy(row, a)
+		copy(a, b)
+		copy(b, row)
+	}
+	return s.readback
+}
+
+func (r *Renderer) resizeGL() {
+	if r.gl == nil || r.gl.window == nil || r.fullscreen {
+		return
+	}
+	r.gl.window.SetSize(int32(r.width), int32(r.height))
+}
+
+func (r *Renderer) closeGL() error {
+	state := r.gl
+	if state == nil {
+		return nil
+	}
+	if state.window != nil {
+		for _, prog := range state.programs {
+			if prog != nil {
+				C.glDeleteProgram(prog.id)
+			}
+		}
+		if state.blit != nil {
+			C.glDeleteProgram(state.blit.id)
+		}
+		if state.user != nil {
+			C.glDeleteProgram(state.user.id)
+		}
+		C.glDeleteTextures(1, &state.texture)
+		C.glDeleteBuffers(1, &state.quad)
+		sdl.GLDeleteContext(state.context)
+		state.window.Destroy()
+		runtime.UnlockOSThread()
+	}
+	if state.initialized {
+		sdl.QuitSubSystem(sdl.INIT_VIDEO)
+	}
+	r.gl = nil
+	return nil
+}
+
+func SupportsGL() bool { return true }
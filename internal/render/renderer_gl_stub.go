@@ -0,0 +1,31 @@
+//go:build !gl
+
+package render
+
+import (
+	"errors"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+type glState struct{}
+
+func (r *Renderer) initGL(width, height int) error {
+	return errors.New("GL backend not enabled; rebuild with -tags gl")
+}
+
+func (r *Renderer) renderGL(p params.Parameters, feat analyzer.Features, fps float64, ctx frameParams, activation float64, xCoords, yCoords []float64, scale float64) Frame {
+	return Frame{
+		Status: "GL backend unavailable (build without -tags gl)",
+		Present: func(string) error {
+			return ErrRendererQuit
+		},
+	}
+}
+
+func (r *Renderer) resizeGL() {}
+
+func (r *Renderer) closeGL() error { return nil }
+
+func SupportsGL() bool { return false }
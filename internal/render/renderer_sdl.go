@@ -4,6 +4,7 @@ package render
 
 import (
 	"fmt"
+	"image"
 	"math"
 	"runtime"
 	"unsafe"
@@ -159,6 +160,10 @@ func (r *Renderer) renderSDL(p params.Parameters, feat analyzer.Features, fps fl
 	if downsample < 1 {
 		downsample = 1
 	}
+	if fps > 0 {
+		downsample = min(downsample*r.dynRes.Update(feat, 1.0/fps), 8)
+	}
+	downsample = min(downsample*r.frameRes.Factor(), 8)
 
 	for y := 0; y < height; y += downsample {
 		sampleY := y + downsample/2
@@ -201,13 +206,20 @@ func (r *Renderer) renderSDL(p params.Parameters, feat analyzer.Features, fps fl
 
 	status := r.buildStatus(feat, fps)
 
+	var img *image.RGBA
+	if r.capture {
+		img = &image.RGBA{Pix: state.pixelBuffer, Stride: pitch, Rect: image.Rect(0, 0, width, height)}
+	}
+
 	return Frame{
 		Status: status,
+		Image:  img,
 		Present: func(status string) error {
 			if status != "" && status != state.windowTitle && state.window != nil {
 				state.window.SetTitle(status)
 				state.windowTitle = status
 			}
+			drawStatusPanel(state.pixelBuffer, state.pitch, width, height, r.statusPanel)
 			var pixels unsafe.Pointer
 			if len(state.pixelBuffer) > 0 {
 				pixels = unsafe.Pointer(&state.pixelBuffer[0])
@@ -222,13 +234,7 @@ func (r *Renderer) renderSDL(p params.Parameters, feat analyzer.Features, fps fl
 				return err
 			}
 			state.renderer.Present()
-			for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
-				switch event.(type) {
-				case *sdl.QuitEvent:
-					return ErrRendererQuit
-				}
-			}
-			return nil
+			return r.pollWindowEvents()
 		},
 	}
 }
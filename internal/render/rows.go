@@ -0,0 +1,41 @@
+package render
+
+import "unicode/utf8"
+
+// rowWriter builds one terminal row in a buffer that keeps its capacity
+// from frame to frame, so rendering rows allocates nothing but the rows
+// that changed.
+type rowWriter struct {
+	buf []byte
+}
+
+func (w *rowWriter) Reset()               { w.buf = w.buf[:0] }
+func (w *rowWriter) WriteString(s string) { w.buf = append(w.buf, s...) }
+func (w *rowWriter) WriteRune(r rune)     { w.buf = utf8.AppendRune(w.buf, r) }
+
+// WriteByte always returns nil, like strings.Builder's.
+func (w *rowWriter) WriteByte(b byte) error {
+	w.buf = append(w.buf, b)
+	return nil
+}
+
+// String returns the row, prev itself when the row hasn't changed since
+// the frame that produced it.
+func (w *rowWriter) String(prev string) string {
+	if string(w.buf) == prev {
+		return prev
+	}
+	return string(w.buf)
+}
+
+// frameRows returns the renderer's row slice at height rows, reused across
+// frames, and a writer per worker.
+func (r *Renderer) frameRows(height, workers int) ([]string, []rowWriter) {
+	if len(r.lines) != height {
+		r.lines = make([]string, height)
+	}
+	if len(r.rowWriters) < workers {
+		r.rowWriters = append(r.rowWriters, make([]rowWriter, workers-len(r.rowWriters))...)
+	}
+	return r.lines, r.rowWriters[:workers]
+}
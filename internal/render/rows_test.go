@@ -0,0 +1,45 @@
+package render
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestRowWriterKeepsUnchangedRows(t *testing.T) {
+	var w rowWriter
+	w.WriteString("ab")
+	w.WriteRune('█')
+	prev := w.String("")
+	if prev != "ab█" {
+		t.Fatalf("row = %q", prev)
+	}
+	w.Reset()
+	w.WriteString("ab█")
+	if got := w.String(prev); unsafe.StringData(got) != unsafe.StringData(prev) {
+		t.Fatal("an unchanged row was copied")
+	}
+	w.Reset()
+	w.WriteByte('x')
+	if got := w.String(prev); got != "x" {
+		t.Fatalf("changed row = %q, want x", got)
+	}
+}
+
+func TestRenderSteadyFrameAllocations(t *testing.T) {
+	for _, mode := range []string{"plain", "braille", "halfblock"} {
+		r, err := New(60, 20, "default", "plasma", "chromatic", "high", true, true)
+		if err != nil {
+			t.Fatalf("new renderer: %v", err)
+		}
+		r.braille = mode == "braille"
+		r.halfBlock = mode == "halfblock"
+		clock := time.Unix(0, 0)
+		r.clock = func() time.Time { return clock }
+		p, feat := snapshotScene()
+		r.Render(p, feat, 60) // size the rows and writers
+		if allocs := testing.AllocsPerRun(20, func() { r.Render(p, feat, 60) }); allocs != 0 {
+			t.Fatalf("%s: Render allocated %.0f times per frame", mode, allocs)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package render
+
+import (
+	"math"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+const (
+	// scopeHeight is the share of the screen height a full-scale trace
+	// spans.
+	scopeHeight = 0.8
+	// scopeJoin is the intensity of the cells that join the trace across
+	// steep steps between columns.
+	scopeJoin = 0.6
+)
+
+// scopePattern is an oscilloscope: the triggered Waveform drawn as a line
+// across the screen, steep edges joined up so the trace stays unbroken.
+type scopePattern struct {
+	rows   []int // the trace's row in each column
+	lo, hi []int // rows the column covers to meet its neighbours
+
+	gridW, gridH int
+}
+
+func (s *scopePattern) prepare(feat analyzer.Features, dt float64, gridW, gridH int) {
+	s.gridW, s.gridH = gridW, gridH
+	if len(s.rows) != gridW {
+		s.rows = make([]int, gridW)
+		s.lo = make([]int, gridW)
+		s.hi = make([]int, gridW)
+	}
+
+	for col := range s.rows {
+		pos := 0.0
+		if gridW > 1 {
+			pos = float64(col) / float64(gridW-1) * (analyzer.WaveformSamples - 1)
+		}
+		i := min(int(pos), analyzer.WaveformSamples-2)
+		v := lerp(feat.Waveform[i], feat.Waveform[i+1], pos-float64(i))
+		s.rows[col] = clampInt(int(math.Round((0.5-v*scopeHeight/2)*float64(gridH-1))), 0, gridH-1)
+	}
+	// each column reaches halfway to its neighbours' rows
+	for col, row := range s.rows {
+		prev, next := s.rows[max(col-1, 0)], s.rows[min(col+1, gridW-1)]
+		s.lo[col] = min(row, (row+prev)/2, (row+next)/2)
+		s.hi[col] = max(row, (row+prev)/2, (row+next)/2)
+	}
+}
+
+func (s *scopePattern) eval(x, y float64) float64 {
+	if s.gridW == 0 || s.gridH == 0 {
+		return -1
+	}
+	col := gridCell(x, s.gridW)
+	row := gridCell(y, s.gridH)
+	switch {
+	case row == s.rows[col]:
+		return 1
+	case row >= s.lo[col] && row <= s.hi[col]:
+		return scopeJoin
+	}
+	return -1
+}
@@ -0,0 +1,44 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestScopeTraceFollowsWaveform(t *testing.T) {
+	var s scopePattern
+	var feat analyzer.Features
+	// a square wave: high for the first half, low for the second
+	for j := range feat.Waveform {
+		feat.Waveform[j] = 1
+		if j >= analyzer.WaveformSamples/2 {
+			feat.Waveform[j] = -1
+		}
+	}
+	s.prepare(feat, 1.0/60, 20, 11)
+	at := func(col, row int) float64 { return s.eval(float64(col)/20, float64(row)/11) }
+
+	if at(0, 1) != 1 || at(19, 9) != 1 {
+		t.Fatalf("trace rows %v", s.rows)
+	}
+	if at(0, 5) != -1 || at(19, 1) != -1 {
+		t.Fatal("lit cells off the trace")
+	}
+	// the falling edge is joined from top to bottom
+	for row := 1; row <= 9; row++ {
+		if at(9, row) < 0 && at(10, row) < 0 {
+			t.Fatalf("gap in the edge at row %d (rows %v)", row, s.rows)
+		}
+	}
+}
+
+func TestScopeSilenceIsCentreLine(t *testing.T) {
+	var s scopePattern
+	s.prepare(analyzer.Features{}, 1.0/60, 8, 9)
+	for col := range 8 {
+		if s.eval(float64(col)/8, 4.0/9) != 1 || s.eval(float64(col)/8, 3.0/9) != -1 {
+			t.Fatalf("column %d: rows %v", col, s.rows)
+		}
+	}
+}
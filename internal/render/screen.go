@@ -0,0 +1,97 @@
+package render
+
+import (
+	"math"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// screenPattern draws the audio itself (bars, scopes) or a simulation fed
+// by it (life, flow, starfield) instead of a field. It keeps state across
+// frames and is evaluated in screen space: x and y run from 0 at the top
+// left to 1, without zoom, rotation or warps.
+type screenPattern interface {
+	// prepare advances the state by dt seconds to the frame's features on
+	// a gridW x gridH grid. It runs before the workers start.
+	prepare(feat analyzer.Features, dt float64, gridW, gridH int)
+	// eval returns the intensity at x, y like a pattern function. It is
+	// called from the render workers.
+	eval(x, y float64) float64
+}
+
+// screenPatterns are the screen-space patterns by name.
+var screenPatterns = map[string]func() screenPattern{
+	"bars":        func() screenPattern { return &barsPattern{} },
+	"eqring":      func() screenPattern { return &eqringPattern{} },
+	"flow":        func() screenPattern { return &flowPattern{} },
+	"life":        func() screenPattern { return &lifePattern{} },
+	"lissajous":   func() screenPattern { return &lissajousPattern{} },
+	"scope":       func() screenPattern { return &scopePattern{} },
+	"spectrogram": func() screenPattern { return &spectrogramPattern{} },
+	"starfield":   func() screenPattern { return &starfieldPattern{} },
+	"vu":          func() screenPattern { return &vuPattern{} },
+}
+
+// roundPattern is a screen pattern with shapes that must keep their
+// proportions, like the goniometer's square.
+type roundPattern interface {
+	// setAspect tells it how many times taller than wide a grid cell is.
+	// It is called before prepare.
+	setAspect(aspect int)
+}
+
+// tunedPattern is a screen pattern that follows the frame's parameters as
+// well as its features.
+type tunedPattern interface {
+	// setParams hands it the frame's parameters. It is called before
+	// prepare.
+	setParams(p params.Parameters)
+}
+
+// maxScreenStep caps the time a screen pattern advances by in one frame,
+// so a stall doesn't drop every bar at once.
+const maxScreenStep = 0.1
+
+// prepareScreen hands the frame to the current screen pattern, or to the
+// screen patterns among the layers, and to the ones fading out.
+func (r *Renderer) prepareScreen(now time.Time, p params.Parameters, feat analyzer.Features, gridW, gridH, aspect int) {
+	from := r.fade.from
+	if r.screen == nil && r.layers == nil && (from == nil || from.screen == nil && from.layers == nil) {
+		return
+	}
+	dt := 1.0 / 60
+	if !r.screenAt.IsZero() {
+		dt = math.Min(now.Sub(r.screenAt).Seconds(), maxScreenStep)
+	}
+	r.screenAt = now
+	sources := []patternSource{{screen: r.screen, layers: r.layers}}
+	if from != nil {
+		sources = append(sources, *from)
+	}
+	prepare := func(s screenPattern) {
+		if s == nil {
+			return
+		}
+		if round, ok := s.(roundPattern); ok {
+			round.setAspect(aspect)
+		}
+		if tuned, ok := s.(tunedPattern); ok {
+			tuned.setParams(p)
+		}
+		s.prepare(feat, dt, gridW, gridH)
+	}
+	for _, src := range sources {
+		prepare(src.screen)
+		for _, layer := range src.layers {
+			prepare(layer.screen)
+		}
+	}
+}
+
+// gridCell returns the cell a screen coordinate falls on, for a grid n
+// cells across.
+func gridCell(v float64, n int) int {
+	return clampInt(int(math.Round(v*float64(n))), 0, n-1)
+}
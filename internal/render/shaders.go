@@ -0,0 +1,165 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// ShaderPrefix marks a pattern name that refers to a user fragment shader,
+// "shader:name" loading name.frag from the shader directory. Only the gl
+// backend can run them.
+const ShaderPrefix = "shader:"
+
+// shaderExt is the extension of user shader files.
+const shaderExt = ".frag"
+
+// glShaderPrelude is put in front of user shaders. It declares the uniform
+// interface and Shadertoy-style aliases, so a file holding just a
+// mainImage function works unchanged.
+const glShaderPrelude = `#ifdef GL_FRAGMENT_PRECISION_HIGH
+precision highp float;
+#else
+precision mediump float;
+#endif
+
+uniform vec2 u_resolution;  // drawable size in pixels
+uniform float u_time;       // seconds, scaled by the speed param
+uniform float u_bass;       // band levels, 0-1
+uniform float u_mid;
+uniform float u_treble;
+uniform float u_energy;     // overall level, 0-1
+uniform float u_beat;       // beat strength, 0-1, jumps on a beat and decays
+uniform float u_drop;       // 1 during a detected drop
+uniform float u_colorShift; // hue offset of the colour shift param, 0-1
+
+#define iResolution vec3(u_resolution, 1.0)
+#define iTime u_time
+#line 1
+`
+
+// glMainImage calls a Shadertoy mainImage when the file has no main.
+const glMainImage = `
+void main() {
+	vec4 color = vec4(0.0, 0.0, 0.0, 1.0);
+	mainImage(color, gl_FragCoord.xy);
+	gl_FragColor = vec4(color.rgb, 1.0);
+}
+`
+
+// isShaderPattern reports whether name selects a user shader.
+func isShaderPattern(name string) bool {
+	return strings.HasPrefix(name, ShaderPrefix)
+}
+
+// ShaderNames lists the user shaders in dir as pattern names, sorted. A
+// missing directory has none.
+func ShaderNames(dir string) []string {
+	return patternFiles(dir, shaderExt, ShaderPrefix)
+}
+
+// patternFiles lists the files with extension ext in dir as pattern names
+// with prefix, sorted.
+func patternFiles(dir, ext, prefix string) []string {
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(name), ext) {
+			continue
+		}
+		names = append(names, prefix+strings.TrimSuffix(name, filepath.Ext(name)))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetShaderDir sets where "shader:" patterns are loaded from.
+func (r *Renderer) SetShaderDir(dir string) {
+	r.shaderDir = dir
+}
+
+// ShaderDir returns the user shader directory.
+func (r *Renderer) ShaderDir() string { return r.shaderDir }
+
+// PatternNames returns the patterns this renderer can show: the built-in
+// ones and the Lua patterns, plus the user shaders on the gl backend.
+func (r *Renderer) PatternNames() []string {
+	names := append(PatternNames(), LuaNames(r.luaDir)...)
+	if r.mode == backendGL {
+		names = append(names, ShaderNames(r.shaderDir)...)
+	}
+	return names
+}
+
+// shaderPath returns the file behind a "shader:" pattern. The name may not
+// leave the shader directory.
+func (r *Renderer) shaderPath(pattern string) (string, error) {
+	return patternPath(r.shaderDir, ShaderPrefix, shaderExt, pattern)
+}
+
+// patternPath returns the file in dir behind a pattern name with prefix.
+func patternPath(dir, prefix, ext, pattern string) (string, error) {
+	name := strings.TrimPrefix(pattern, prefix)
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid pattern file name %q", name)
+	}
+	if dir == "" {
+		return "", fmt.Errorf("no %s directory set", strings.TrimSuffix(prefix, ":"))
+	}
+	return filepath.Join(dir, name+ext), nil
+}
+
+var hasMain = regexp.MustCompile(`\bvoid\s+main\s*\(`)
+
+// userShaderSource wraps a user shader file in the prelude.
+func userShaderSource(src string) string {
+	var b strings.Builder
+	b.WriteString(glShaderPrelude)
+	b.WriteString(src)
+	if !hasMain.MatchString(src) && strings.Contains(src, "mainImage") {
+		b.WriteString(glMainImage)
+	}
+	return b.String()
+}
+
+// glUserUniforms is one frame's values for the user shader interface.
+type glUserUniforms struct {
+	time, bass, mid, treble float32
+	energy, beat, drop      float32
+	colorShift              float32
+}
+
+func glUserUniformValues(p params.Parameters, feat analyzer.Features) glUserUniforms {
+	shift := math.Mod(p.ColorShift/(2*math.Pi), 1.0)
+	if shift < 0 {
+		shift += 1
+	}
+	drop := float32(0)
+	if feat.IsDrop {
+		drop = 1
+	}
+	return glUserUniforms{
+		time:       float32(p.Time),
+		bass:       float32(clamp01(feat.Bass)),
+		mid:        float32(clamp01(feat.Mid)),
+		treble:     float32(clamp01(feat.Treble)),
+		energy:     float32(clamp01(feat.Overall)),
+		beat:       float32(clamp01(feat.BeatStrength)),
+		drop:       drop,
+		colorShift: float32(shift),
+	}
+}
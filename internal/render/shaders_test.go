@@ -0,0 +1,69 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShaderNamesListsFragFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"waves.frag", "Bloom.FRAG", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("void main() {}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "old.frag"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"shader:Bloom", "shader:waves"}
+	if got := ShaderNames(dir); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got := ShaderNames(filepath.Join(dir, "missing")); got != nil {
+		t.Fatalf("missing dir listed %v", got)
+	}
+}
+
+func TestShaderPathStaysInDir(t *testing.T) {
+	r := &Renderer{shaderDir: "/srv/shaders"}
+	path, err := r.shaderPath("shader:waves")
+	if err != nil || path != filepath.Join("/srv/shaders", "waves.frag") {
+		t.Fatalf("got %q, %v", path, err)
+	}
+	for _, bad := range []string{"shader:", "shader:../secret", "shader:a/b", "shader:.."} {
+		if _, err := r.shaderPath(bad); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func TestUserShaderSourceWrapsMainImage(t *testing.T) {
+	toy := userShaderSource("void mainImage(out vec4 c, in vec2 p) { c = vec4(iTime); }")
+	if !strings.HasPrefix(toy, glShaderPrelude) || !strings.Contains(toy, "mainImage(color, gl_FragCoord.xy)") {
+		t.Fatal("mainImage shader not wrapped")
+	}
+	plain := userShaderSource("void main() { gl_FragColor = vec4(u_bass); }")
+	if strings.Contains(plain, "mainImage") {
+		t.Fatal("shader with main got a second one")
+	}
+}
+
+func TestShaderPatternNeedsGL(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "shader:waves", "chromatic", "high", true, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	if r.PatternName() != "ripple" {
+		t.Fatalf("ascii renderer took pattern %q", r.PatternName())
+	}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "waves.frag"), nil, 0o644)
+	r.SetShaderDir(dir)
+	if got := r.PatternNames(); len(got) != len(PatternNames()) {
+		t.Fatalf("ascii renderer offers shaders: %v", got)
+	}
+}
@@ -0,0 +1,238 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+const (
+	// sixelDot is how many device pixels wide one rendered pixel is at
+	// scale 1; --scale 2 halves it for finer (and slower) output.
+	sixelDot = 4
+	// sixelCellW and sixelCellH stand in when the terminal doesn't report
+	// its cell size in pixels.
+	sixelCellW = 8
+	sixelCellH = 16
+	// sixelLevels is the steps per channel of the 6x6x6 colour cube the
+	// frame is quantised to.
+	sixelLevels = 6
+	sixelColors = sixelLevels * sixelLevels * sixelLevels
+	sixelBand   = 6
+)
+
+// sixelState is the pixel buffer and encoder of the sixel backend.
+type sixelState struct {
+	img          *image.RGBA
+	cellW, cellH int
+	dot          int
+	enc          sixelEncoder
+}
+
+// initSixel reads the terminal's cell size; the frame is sized from it.
+func (r *Renderer) initSixel() {
+	r.mode = backendSixel
+	r.sixel = &sixelState{}
+	r.sixel.cellW, r.sixel.cellH = cellPixelSize()
+	if r.sixel.cellW <= 0 || r.sixel.cellH <= 0 {
+		r.sixel.cellW, r.sixel.cellH = sixelCellW, sixelCellH
+	}
+}
+
+// sixelGrid returns the rendered pixel grid for a width x height cell area.
+// Its height in device pixels is a multiple of the six-pixel sixel band so
+// the image never spills past the rows it was given.
+func (r *Renderer) sixelGrid(width, height int) (gridW, gridH int) {
+	s := r.sixel
+	s.dot = max(1, int(math.Round(sixelDot/r.scale)))
+	gridW = max(1, width*s.cellW/s.dot)
+	gridH = max(1, height*s.cellH/s.dot)
+	for gridH > 1 && gridH*s.dot%sixelBand != 0 {
+		gridH--
+	}
+	return gridW, gridH
+}
+
+// renderSixel evaluates every pixel of the grid and returns a frame whose
+// Present draws it at the top left of the terminal, with the status text on
+// the row below.
+func (r *Renderer) renderSixel(p params.Parameters, feat analyzer.Features, fps float64, ctx frameParams, activation float64, xCoords, yCoords []float64, scale float64) Frame {
+	s := r.sixel
+	gridW, gridH := len(xCoords), len(yCoords)
+	if s.img == nil || s.img.Rect.Dx() != gridW || s.img.Rect.Dy() != gridH {
+		s.img = image.NewRGBA(image.Rect(0, 0, gridW, gridH))
+	}
+	r.fillImage(s.img, p, feat, ctx, activation, xCoords, yCoords, scale)
+
+	frame := Frame{
+		Status: r.buildStatus(feat, fps),
+		Present: func(status string) error {
+			out := s.enc.begin()
+			out.WriteString("\x1b[H")
+			s.enc.encode(s.img, s.dot)
+			if status != "" {
+				out.WriteString("\x1b[")
+				out.WriteString(strconv.Itoa(r.height + 1))
+				out.WriteString(";1H")
+				out.WriteString(truncateRunes(status, r.width))
+				out.WriteString("\x1b[K")
+			}
+			_, err := os.Stdout.Write(out.Bytes())
+			return err
+		},
+	}
+	if r.capture {
+		frame.Image = s.img
+	}
+	return frame
+}
+
+func truncateRunes(text string, limit int) string {
+	n := 0
+	for i := range text {
+		if n == limit {
+			return text[:i]
+		}
+		n++
+	}
+	return text
+}
+
+// sixelEncoder turns an RGBA image into a DCS sixel sequence using the
+// 216-colour cube as its palette. Rendered pixels are scaled up by dot:
+// horizontally through run-length repeats, vertically by repeating rows.
+type sixelEncoder struct {
+	out     bytes.Buffer
+	indices []uint8
+	used    [sixelColors]bool
+	inBand  [sixelColors]bool
+	bandSet []uint8
+}
+
+// begin resets the output buffer and returns it.
+func (e *sixelEncoder) begin() *bytes.Buffer {
+	e.out.Reset()
+	return &e.out
+}
+
+// encode appends the sixel sequence for img to the output buffer.
+func (e *sixelEncoder) encode(img *image.RGBA, dot int) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	if cap(e.indices) < w*h {
+		e.indices = make([]uint8, w*h)
+	}
+	e.indices = e.indices[:w*h]
+	clear(e.used[:])
+	for i := range e.indices {
+		px := img.Pix[i*4 : i*4+3 : i*4+3]
+		c := sixelIndex(px[0], px[1], px[2])
+		e.indices[i] = c
+		e.used[c] = true
+	}
+
+	out := &e.out
+	devW, devH := w*dot, h*dot
+	// DCS q with P2=1: zero bits leave the pixel alone; every pixel is
+	// painted by some colour anyway
+	out.WriteString("\x1bP0;1q\"1;1;")
+	out.WriteString(strconv.Itoa(devW))
+	out.WriteByte(';')
+	out.WriteString(strconv.Itoa(devH))
+	for c, ok := range e.used {
+		if !ok {
+			continue
+		}
+		r, g, b := sixelCubeRGB(uint8(c))
+		out.WriteByte('#')
+		out.WriteString(strconv.Itoa(c))
+		out.WriteString(";2;")
+		out.WriteString(strconv.Itoa(r))
+		out.WriteByte(';')
+		out.WriteString(strconv.Itoa(g))
+		out.WriteByte(';')
+		out.WriteString(strconv.Itoa(b))
+	}
+
+	var rows [sixelBand]int
+	for top := 0; top < devH; top += sixelBand {
+		bandRows := min(sixelBand, devH-top)
+		for k := 0; k < bandRows; k++ {
+			rows[k] = (top + k) / dot * w
+		}
+		e.bandSet = e.bandSet[:0]
+		for k := 0; k < bandRows; k++ {
+			if k > 0 && rows[k] == rows[k-1] {
+				continue
+			}
+			for _, c := range e.indices[rows[k] : rows[k]+w] {
+				if !e.inBand[c] {
+					e.inBand[c] = true
+					e.bandSet = append(e.bandSet, c)
+				}
+			}
+		}
+		for i, c := range e.bandSet {
+			e.inBand[c] = false
+			if i > 0 {
+				out.WriteByte('$')
+			}
+			out.WriteByte('#')
+			out.WriteString(strconv.Itoa(int(c)))
+			var run byte
+			count := 0
+			for x := 0; x < w; x++ {
+				var bits byte
+				for k := 0; k < bandRows; k++ {
+					if e.indices[rows[k]+x] == c {
+						bits |= 1 << k
+					}
+				}
+				ch := '?' + bits
+				if ch != run && count > 0 {
+					writeSixelRun(out, run, count)
+					count = 0
+				}
+				run = ch
+				count += dot
+			}
+			if run != '?' {
+				writeSixelRun(out, run, count)
+			}
+		}
+		if top+sixelBand < devH {
+			out.WriteByte('-')
+		}
+	}
+	out.WriteString("\x1b\\")
+}
+
+func writeSixelRun(out *bytes.Buffer, ch byte, count int) {
+	if count > 3 {
+		out.WriteByte('!')
+		out.WriteString(strconv.Itoa(count))
+		out.WriteByte(ch)
+		return
+	}
+	for ; count > 0; count-- {
+		out.WriteByte(ch)
+	}
+}
+
+// sixelIndex maps a colour onto the nearest cube entry.
+func sixelIndex(r, g, b uint8) uint8 {
+	q := func(v uint8) int { return (int(v)*(sixelLevels-1) + 127) / 255 }
+	return uint8(q(r)*sixelLevels*sixelLevels + q(g)*sixelLevels + q(b))
+}
+
+// sixelCubeRGB returns cube entry c as 0-100 percentages, the unit sixel
+// colour definitions use.
+func sixelCubeRGB(c uint8) (int, int, int) {
+	level := func(v int) int { return (v*100 + (sixelLevels-1)/2) / (sixelLevels - 1) }
+	n := int(c)
+	return level(n / (sixelLevels * sixelLevels)), level(n / sixelLevels % sixelLevels), level(n % sixelLevels)
+}
@@ -0,0 +1,7 @@
+//go:build !unix
+
+package render
+
+// cellPixelSize is unknown off unix; the sixel backend falls back to a
+// typical 8x16 cell.
+func cellPixelSize() (int, int) { return 0, 0 }
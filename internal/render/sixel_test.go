@@ -0,0 +1,68 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSixelEncodeScalesAndRunLengths(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			c := color.RGBA{A: 255}
+			if x < 2 {
+				c.R = 255
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	var enc sixelEncoder
+	enc.begin()
+	enc.encode(img, 2)
+	out := enc.out.String()
+
+	if !strings.HasPrefix(out, "\x1bP0;1q\"1;1;8;6") || !strings.HasSuffix(out, "\x1b\\") {
+		t.Fatalf("bad framing: %q", out)
+	}
+	red, black := sixelIndex(255, 0, 0), sixelIndex(0, 0, 0)
+	// one 6-row band: red fills the left 4 device columns, black the right 4
+	for _, want := range []string{
+		"#" + strconv.Itoa(int(red)) + ";2;100;0;0",
+		"#" + strconv.Itoa(int(black)) + ";2;0;0;0",
+		"#" + strconv.Itoa(int(red)) + "!4~",
+		"#" + strconv.Itoa(int(black)) + "!4?!4~",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in %q", want, out)
+		}
+	}
+	if strings.Contains(out, "-") {
+		t.Errorf("single band must not advance to a new band: %q", out)
+	}
+}
+
+func TestSixelRenderFillsGrid(t *testing.T) {
+	r, err := NewWithBackend(BackendSixel, snapshotWidth, snapshotHeight, "default", "ripple", "chromatic", "high", true, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	r.sixel.cellW, r.sixel.cellH = sixelCellW, sixelCellH
+	r.SetCapture(true)
+	p, feat := snapshotScene()
+	frame := r.Render(p, feat, 60)
+	if frame.Present == nil || frame.Image == nil {
+		t.Fatal("sixel frame has no presenter or image")
+	}
+	b := frame.Image.Rect
+	if b.Dx() != snapshotWidth*sixelCellW/sixelDot || b.Dy()*sixelDot%sixelBand != 0 || b.Dy()*sixelDot > snapshotHeight*sixelCellH {
+		t.Fatalf("grid %v does not fit %dx%d cells", b, snapshotWidth, snapshotHeight)
+	}
+	r.sixel.enc.begin()
+	r.sixel.enc.encode(frame.Image, r.sixel.dot)
+	if bands := strings.Count(r.sixel.enc.out.String(), "-") + 1; bands != b.Dy()*sixelDot/sixelBand {
+		t.Fatalf("got %d bands, want %d", bands, b.Dy()*sixelDot/sixelBand)
+	}
+}
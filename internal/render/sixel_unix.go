@@ -0,0 +1,19 @@
+//go:build unix
+
+package render
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// cellPixelSize asks the terminal how many pixels one cell covers. Zero
+// means it didn't say.
+func cellPixelSize() (int, int) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return 0, 0
+	}
+	return int(ws.Xpixel) / int(ws.Col), int(ws.Ypixel) / int(ws.Row)
+}
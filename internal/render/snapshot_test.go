@@ -0,0 +1,440 @@
+package render
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+var updateSnapshots = flag.Bool("update", false, "rewrite golden snapshots in testdata/snapshots")
+
+const (
+	snapshotWidth  = 64
+	snapshotHeight = 32
+	// snapshotDiffLimit is how many cells/pixels (1%) may differ, so FMA
+	// contraction on arm64 doesn't fail goldens recorded on amd64.
+	snapshotDiffLimit = snapshotWidth * snapshotHeight / 100
+	// snapshotChannelSlack is the per-channel RGBA difference still counted
+	// as a match.
+	snapshotChannelSlack = 3
+
+	snapshotSeed   = 7
+	snapshotFrames = 45
+	snapshotStep   = time.Second / 30
+)
+
+// snapshotCheckpoints are the frames of the script kept in the scripted
+// snapshots: a quiet start, the drop and the end.
+var snapshotCheckpoints = []int{15, 30, snapshotFrames}
+
+// snapshotScene is the fixed input every pattern is rendered with.
+func snapshotScene() (params.Parameters, analyzer.Features) {
+	p := params.Defaults()
+	p.Time = 1.75
+	feat := analyzer.Features{
+		Sub:          0.55,
+		Bass:         0.7,
+		LowMid:       0.4,
+		HighMid:      0.35,
+		Mid:          0.45,
+		Treble:       0.3,
+		Overall:      0.5,
+		BeatStrength: 0.6,
+		Level:        0.3,
+		Peak:         0.7,
+	}
+	for k := range feat.Spectrum {
+		pos := float64(k) / analyzer.SpectrumBands
+		feat.Spectrum[k] = 0.2 + 0.7*math.Exp(-pos*4) + 0.1*math.Sin(float64(k))
+	}
+	for j := range feat.Waveform {
+		phase := 2 * math.Pi * float64(j) / analyzer.WaveformSamples
+		feat.Waveform[j] = 0.7*math.Sin(2*phase) + 0.25*math.Sin(9*phase)
+	}
+	for j := range feat.Goniometer {
+		phase := 2 * math.Pi * float64(j) / analyzer.GoniometerPoints
+		feat.Goniometer[j] = [2]float64{0.4 * math.Sin(3*phase+1), 0.8 * math.Sin(2*phase)}
+	}
+	feat.Correlation = 0.6
+	p.ApplyFeatures(feat, 1.0/60)
+	return p, feat
+}
+
+// renderSnapshot renders pattern to plain ASCII and to an RGBA image built
+// from the same per-pixel evaluation the SDL backend uses.
+func renderSnapshot(t *testing.T, pattern string) ([]string, *image.RGBA) {
+	t.Helper()
+	r, err := New(snapshotWidth, snapshotHeight, "default", pattern, "chromatic", "high", true, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	p, feat := snapshotScene()
+	frame := r.Render(p, feat, 60)
+
+	ctx := r.buildFrameParams(p, p.Time)
+	activation := r.audioActivation(feat)
+	img := image.NewRGBA(image.Rect(0, 0, snapshotWidth, snapshotHeight))
+	for y := 0; y < snapshotHeight; y++ {
+		for x := 0; x < snapshotWidth; x++ {
+			res := r.evaluatePixel(r.xCoords[x], r.yCoords[y], p, ctx, feat, activation, nil, nil, y*snapshotWidth+x)
+			cr, cg, cb := hsvToRGB(res.h, res.s, res.v)
+			img.SetRGBA(x, y, color.RGBA{R: channel(cr), G: channel(cg), B: channel(cb), A: 255})
+		}
+	}
+	return frame.Lines, img
+}
+
+func channel(v float64) uint8 {
+	return uint8(math.Round(clamp01(v) * 255))
+}
+
+// snapshotScript is the fixed run of features every scripted snapshot
+// gets: a 120 BPM kick with seeded jitter, a swell in the mids and a drop halfway.
+func snapshotScript() []analyzer.Features {
+	rng := rand.New(rand.NewSource(snapshotSeed))
+	script := make([]analyzer.Features, snapshotFrames)
+	for i := range script {
+		t := float64(i) * snapshotStep.Seconds()
+		kick := math.Pow(math.Max(0, math.Cos(2*math.Pi*2*t)), 4)
+		swell := float64(i) / snapshotFrames
+		jitter := func(scale float64) float64 { return (rng.Float64() - 0.5) * scale }
+		feat := analyzer.Features{
+			Sub:          clamp01(0.3 + 0.6*kick + jitter(0.05)),
+			Bass:         clamp01(0.35 + 0.6*kick + jitter(0.05)),
+			LowMid:       clamp01(0.2 + 0.4*swell + jitter(0.1)),
+			Mid:          clamp01(0.25 + 0.5*swell + jitter(0.1)),
+			HighMid:      clamp01(0.2 + 0.3*swell + jitter(0.1)),
+			Treble:       clamp01(0.15 + 0.3*rng.Float64()),
+			BeatStrength: kick,
+			Onset:        i%15 == 0,
+			IsDrop:       i == 30,
+			Tempo:        120,
+			Level:        0.2 + 0.3*kick,
+			Peak:         0.4 + 0.5*kick,
+			Correlation:  0.5,
+		}
+		feat.Overall = (feat.Bass + feat.Mid + feat.Treble) / 3
+		for k := range feat.Spectrum {
+			pos := float64(k) / analyzer.SpectrumBands
+			feat.Spectrum[k] = clamp01(0.1 + 0.8*kick*math.Exp(-pos*5) + 0.3*swell*math.Sin(pos*math.Pi) + jitter(0.05))
+		}
+		for j := range feat.Waveform {
+			phase := 2 * math.Pi * float64(j) / analyzer.WaveformSamples
+			feat.Waveform[j] = (0.3+0.6*kick)*math.Sin(3*phase+t) + jitter(0.1)
+		}
+		for j := range feat.Goniometer {
+			phase := 2 * math.Pi * float64(j) / analyzer.GoniometerPoints
+			feat.Goniometer[j] = [2]float64{0.5 * math.Sin(2*phase+t), 0.5 * math.Cos(3*phase)}
+		}
+		script[i] = feat
+	}
+	return script
+}
+
+// scriptGrid is one rendered frame as glyphs and their 256-colour indices.
+type scriptGrid struct {
+	glyphs [][]rune
+	colors [][]int
+}
+
+// renderScript plays the script through pattern in colorMode on a clock that
+// only moves with the script, and returns the checkpoint frames.
+func renderScript(t *testing.T, pattern, colorMode string) []scriptGrid {
+	t.Helper()
+	r, err := New(snapshotWidth, snapshotHeight, "default", pattern, colorMode, "high", true, true)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	clock := time.Unix(0, 0)
+	r.clock = func() time.Time { return clock }
+	p := params.Defaults()
+	dt := snapshotStep.Seconds()
+	var grids []scriptGrid
+	for i, feat := range snapshotScript() {
+		clock = clock.Add(snapshotStep)
+		p.ApplyFeatures(feat, dt)
+		p.UpdateTime(dt)
+		frame := r.Render(p, feat, 1/dt)
+		for _, n := range snapshotCheckpoints {
+			if i+1 == n {
+				grids = append(grids, parseScriptRows(t, frame.Lines))
+			}
+		}
+	}
+	return grids
+}
+
+// parseScriptRows splits ANSI rows into cells. Only foreground colour codes
+// and resets are expected.
+func parseScriptRows(t *testing.T, lines []string) scriptGrid {
+	t.Helper()
+	var g scriptGrid
+	for _, line := range lines {
+		var glyphs []rune
+		var colors []int
+		color := -1
+		for len(line) > 0 {
+			if strings.HasPrefix(line, "\x1b[") {
+				end := strings.IndexByte(line, 'm')
+				if end < 0 {
+					t.Fatalf("unterminated escape in %q", line)
+				}
+				code := line[2:end]
+				line = line[end+1:]
+				switch {
+				case code == "0":
+					color = -1
+				case strings.HasPrefix(code, "38;5;"):
+					n, err := strconv.Atoi(code[len("38;5;"):])
+					if err != nil {
+						t.Fatalf("bad colour code %q", code)
+					}
+					color = n
+				default:
+					t.Fatalf("unexpected escape %q", code)
+				}
+				continue
+			}
+			r := []rune(line)[0]
+			line = line[len(string(r)):]
+			glyphs = append(glyphs, r)
+			colors = append(colors, color)
+		}
+		g.glyphs = append(g.glyphs, glyphs)
+		g.colors = append(g.colors, colors)
+	}
+	return g
+}
+
+// encodeScript writes each checkpoint as its glyph rows followed by its
+// colour rows, two hex digits a cell.
+func encodeScript(grids []scriptGrid) string {
+	var b strings.Builder
+	for i, g := range grids {
+		fmt.Fprintf(&b, "# frame %d\n", snapshotCheckpoints[i])
+		for _, row := range g.glyphs {
+			b.WriteString(string(row))
+			b.WriteByte('\n')
+		}
+		for _, row := range g.colors {
+			for _, c := range row {
+				fmt.Fprintf(&b, "%02x", c&0xff)
+			}
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// decodeScript reads what encodeScript wrote.
+func decodeScript(t *testing.T, path string) []scriptGrid {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("missing golden (run with -update): %v", err)
+	}
+	rows := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	var grids []scriptGrid
+	for len(rows) > 0 {
+		if !strings.HasPrefix(rows[0], "# frame ") || len(rows) < 1+2*snapshotHeight {
+			t.Fatalf("%s: malformed golden, rerun with -update", path)
+		}
+		var g scriptGrid
+		for _, row := range rows[1 : 1+snapshotHeight] {
+			g.glyphs = append(g.glyphs, []rune(row))
+		}
+		for _, row := range rows[1+snapshotHeight : 1+2*snapshotHeight] {
+			colors := make([]int, 0, len(row)/2)
+			for x := 0; x+2 <= len(row); x += 2 {
+				n, err := strconv.ParseUint(row[x:x+2], 16, 8)
+				if err != nil {
+					t.Fatalf("%s: bad colour %q", path, row[x:x+2])
+				}
+				colors = append(colors, int(n))
+			}
+			g.colors = append(g.colors, colors)
+		}
+		grids = append(grids, g)
+		rows = rows[1+2*snapshotHeight:]
+	}
+	return grids
+}
+
+// compareScript counts the cells whose glyph or colour changed per
+// checkpoint and fails past the limit.
+func compareScript(t *testing.T, path string, got []scriptGrid) {
+	t.Helper()
+	want := decodeScript(t, path)
+	if len(want) != len(got) {
+		t.Fatalf("%s: expected %d frames, got %d", path, len(want), len(got))
+	}
+	for i := range got {
+		glyphDiff, colorDiff := 0, 0
+		for y := range got[i].glyphs {
+			if len(got[i].glyphs[y]) != len(want[i].glyphs[y]) || len(got[i].colors[y]) != len(want[i].colors[y]) {
+				t.Fatalf("%s: frame %d row %d changed width", path, snapshotCheckpoints[i], y)
+			}
+			for x := range got[i].glyphs[y] {
+				if got[i].glyphs[y][x] != want[i].glyphs[y][x] {
+					glyphDiff++
+				}
+				if got[i].colors[y][x]&0xff != want[i].colors[y][x] {
+					colorDiff++
+				}
+			}
+		}
+		if glyphDiff > snapshotDiffLimit || colorDiff > snapshotDiffLimit {
+			t.Fatalf("%s: frame %d has %d glyphs and %d colours changed (limit %d); rerun with -update if intended\n%s",
+				path, snapshotCheckpoints[i], glyphDiff, colorDiff, snapshotDiffLimit, encodeScript(got[i:i+1]))
+		}
+	}
+}
+
+// TestPatternSnapshots checks every pattern against two goldens: the still
+// scene in ASCII and RGBA, and the features script at its checkpoints in
+// glyphs and colours. Every colour mode gets the script on plasma too.
+func TestPatternSnapshots(t *testing.T) {
+	dir := filepath.Join("testdata", "snapshots")
+	if *updateSnapshots {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, pattern := range PatternNames() {
+		t.Run(pattern, func(t *testing.T) {
+			lines, img := renderSnapshot(t, pattern)
+			asciiPath := filepath.Join(dir, pattern+".txt")
+			rgbaPath := filepath.Join(dir, pattern+".png")
+
+			if *updateSnapshots {
+				writeGolden(t, asciiPath, []byte(strings.Join(lines, "\n")+"\n"))
+				var buf bytes.Buffer
+				if err := png.Encode(&buf, img); err != nil {
+					t.Fatal(err)
+				}
+				writeGolden(t, rgbaPath, buf.Bytes())
+				return
+			}
+
+			compareASCII(t, asciiPath, lines)
+			compareRGBA(t, rgbaPath, img)
+		})
+		t.Run(pattern+"-script", func(t *testing.T) {
+			checkScript(t, filepath.Join(dir, pattern+".script.txt"), pattern, "chromatic")
+		})
+	}
+	for _, mode := range ColorModeNames() {
+		t.Run("color-"+mode, func(t *testing.T) {
+			checkScript(t, filepath.Join(dir, "color-"+mode+".script.txt"), "plasma", mode)
+		})
+	}
+}
+
+// checkScript plays the script through pattern in colorMode and compares
+// the checkpoints with the golden at path, or rewrites it with -update.
+func checkScript(t *testing.T, path, pattern, colorMode string) {
+	t.Helper()
+	got := renderScript(t, pattern, colorMode)
+	if *updateSnapshots {
+		writeGolden(t, path, []byte(encodeScript(got)))
+		return
+	}
+	compareScript(t, path, got)
+}
+
+func writeGolden(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func compareASCII(t *testing.T, path string, lines []string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("missing golden (run with -update): %v", err)
+	}
+	want := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(want) != len(lines) {
+		t.Fatalf("%s: expected %d rows, got %d", path, len(want), len(lines))
+	}
+	diff := 0
+	for y := range lines {
+		got, exp := []rune(lines[y]), []rune(want[y])
+		if len(got) != len(exp) {
+			t.Fatalf("%s: row %d has %d cells, expected %d", path, y, len(got), len(exp))
+		}
+		for x := range got {
+			if got[x] != exp[x] {
+				diff++
+			}
+		}
+	}
+	if diff > snapshotDiffLimit {
+		t.Fatalf("%s: %d cells changed (limit %d); rerun with -update if intended\n%s", path, diff, snapshotDiffLimit, strings.Join(lines, "\n"))
+	}
+}
+
+func compareRGBA(t *testing.T, path string, img *image.RGBA) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("missing golden (run with -update): %v", err)
+	}
+	defer f.Close()
+	decoded, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Fatalf("%s: expected bounds %v, got %v", path, decoded.Bounds(), img.Bounds())
+	}
+	diff := 0
+	for y := 0; y < snapshotHeight; y++ {
+		for x := 0; x < snapshotWidth; x++ {
+			want := color.RGBAModel.Convert(decoded.At(x, y)).(color.RGBA)
+			got := img.RGBAAt(x, y)
+			if !channelsClose(got, want) {
+				diff++
+			}
+		}
+	}
+	if diff > snapshotDiffLimit {
+		t.Fatalf("%s: %d pixels changed (limit %d); rerun with -update if intended", path, diff, snapshotDiffLimit)
+	}
+}
+
+func channelsClose(a, b color.RGBA) bool {
+	near := func(x, y uint8) bool {
+		d := int(x) - int(y)
+		return d >= -snapshotChannelSlack && d <= snapshotChannelSlack
+	}
+	return near(a.R, b.R) && near(a.G, b.G) && near(a.B, b.B) && a.A == b.A
+}
+
+// TestSnapshotScriptRepeats renders the script twice, the property the
+// scripted snapshots rely on.
+func TestSnapshotScriptRepeats(t *testing.T) {
+	for _, pattern := range []string{"starfield", "life", "flow", "plasma"} {
+		a := encodeScript(renderScript(t, pattern, "chromatic"))
+		b := encodeScript(renderScript(t, pattern, "chromatic"))
+		if a != b {
+			t.Fatalf("%s renders differently on the same script", pattern)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"math"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+const (
+	// spectrogramSpan is how many seconds of history fill the screen.
+	spectrogramSpan = 6.0
+	// spectrogramFloor is the level below which a cell stays empty.
+	spectrogramFloor = 0.05
+)
+
+// spectrogramPattern is a waterfall: one row of Spectrum per time step,
+// bass on the left, the newest row on top and older ones scrolling down.
+type spectrogramPattern struct {
+	history []float64 // gridH rows of gridW levels, a ring starting at head
+	head    int
+	pending []float64 // loudest levels since the last row was pushed
+	elapsed float64
+
+	gridW, gridH int
+}
+
+func (s *spectrogramPattern) prepare(feat analyzer.Features, dt float64, gridW, gridH int) {
+	if gridW != s.gridW || gridH != s.gridH {
+		s.gridW, s.gridH = gridW, gridH
+		s.history = make([]float64, gridW*gridH)
+		s.pending = make([]float64, gridW)
+		s.head = 0
+		// the first frame lands on screen right away
+		s.elapsed = spectrogramSpan / float64(max(gridH, 1))
+	}
+	if gridW == 0 || gridH == 0 {
+		return
+	}
+
+	for col := range s.pending {
+		s.pending[col] = math.Max(s.pending[col], spectrumColumn(&feat.Spectrum, col, gridW))
+	}
+	// a row per step keeps the scroll speed independent of the frame rate;
+	// quick frames pool their peaks into one row so no transient is lost
+	step := spectrogramSpan / float64(gridH)
+	s.elapsed += dt
+	if s.elapsed < step {
+		return
+	}
+	for ; s.elapsed >= step; s.elapsed -= step {
+		s.head = (s.head + gridH - 1) % gridH
+		copy(s.history[s.head*gridW:(s.head+1)*gridW], s.pending)
+	}
+	clear(s.pending)
+}
+
+func (s *spectrogramPattern) eval(x, y float64) float64 {
+	if s.gridW == 0 || s.gridH == 0 {
+		return -1
+	}
+	col := gridCell(x, s.gridW)
+	row := (s.head + gridCell(y, s.gridH)) % s.gridH
+	v := s.history[row*s.gridW+col]
+	if v < spectrogramFloor {
+		return -1
+	}
+	return v
+}
+
+// spectrumColumn returns the level of column col of a gridW wide spectrum:
+// interpolated between bands when columns outnumber them, else the loudest
+// band the column covers.
+func spectrumColumn(spectrum *[analyzer.SpectrumBands]float64, col, gridW int) float64 {
+	const n = analyzer.SpectrumBands
+	if gridW >= n {
+		pos := 0.0
+		if gridW > 1 {
+			pos = float64(col) / float64(gridW-1) * (n - 1)
+		}
+		i := min(int(pos), n-2)
+		return lerp(spectrum[i], spectrum[i+1], pos-float64(i))
+	}
+	lo := col * n / gridW
+	hi := max((col+1)*n/gridW, lo+1)
+	level := 0.0
+	for _, v := range spectrum[lo:hi] {
+		level = math.Max(level, v)
+	}
+	return level
+}
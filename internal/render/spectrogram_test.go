@@ -0,0 +1,48 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestSpectrogramScrollsDown(t *testing.T) {
+	var s spectrogramPattern
+	var bass, treble analyzer.Features
+	bass.Spectrum[0] = 0.9
+	treble.Spectrum[analyzer.SpectrumBands-1] = 0.7
+	const w, h = 12, 6
+	step := spectrogramSpan / h
+	at := func(col, row int) float64 { return s.eval(float64(col)/w, float64(row)/h) }
+
+	s.prepare(bass, 1.0/60, w, h)
+	if at(0, 0) != 0.9 || at(w-1, 0) != -1 || at(0, 1) != -1 {
+		t.Fatalf("first row not drawn on top: %v %v %v", at(0, 0), at(w-1, 0), at(0, 1))
+	}
+	// frames quicker than a row pool their peaks
+	s.prepare(treble, step/2, w, h)
+	if at(w-1, 0) != -1 {
+		t.Fatal("row pushed before its time")
+	}
+	s.prepare(analyzer.Features{}, step/2, w, h)
+	if at(w-1, 0) != 0.7 || at(0, 0) != -1 || at(0, 1) != 0.9 {
+		t.Fatalf("rows %v %v %v", at(w-1, 0), at(0, 0), at(0, 1))
+	}
+	// a long frame pushes several rows; the oldest falls off the bottom
+	s.prepare(analyzer.Features{}, step*(h-1), w, h)
+	if at(0, h-1) != -1 || at(w-1, h-1) != 0.7 {
+		t.Fatalf("bottom row %v %v", at(0, h-1), at(w-1, h-1))
+	}
+}
+
+func TestSpectrumColumnCoversEveryBand(t *testing.T) {
+	var spectrum [analyzer.SpectrumBands]float64
+	spectrum[analyzer.SpectrumBands-1] = 1
+	if spectrumColumn(&spectrum, 9, 10) != 1 || spectrumColumn(&spectrum, 99, 100) != 1 {
+		t.Fatal("top band missing from the last column")
+	}
+	spectrum[1] = 0.5
+	if spectrumColumn(&spectrum, 0, 10) != 0.5 {
+		t.Fatal("narrow grid drops bands")
+	}
+}
@@ -0,0 +1,150 @@
+package render
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+const (
+	// starDensity is the most stars per grid cell, up to starMax; energy
+	// decides how many of them are out, from starMinShare of them up.
+	starDensity  = 0.06
+	starMax      = 1200
+	starMinShare = 0.3
+	// starSpeed is how much depth a star covers per second at
+	// starReferenceBPM; the warp speed scales with the detected tempo.
+	starSpeed        = 0.6
+	starReferenceBPM = 120.0
+	// starNear is the depth at which a star passes the viewer and respawns.
+	starNear = 0.05
+	// starTrail is how many seconds of travel a star's streak shows.
+	starTrail = 0.08
+	// starEase is the time constant of the density following the energy.
+	starEase = 0.15
+)
+
+type star struct {
+	x, y, z float64
+}
+
+// starfieldPattern flies through a field of stars streaming out of the
+// centre. The warp speed follows the tempo, so the stars rush by faster on
+// faster tracks, beats give them a short boost, and the number of stars
+// swells and thins with the overall energy.
+type starfieldPattern struct {
+	stars   []star
+	bright  []float64 // the frame's streaks
+	rng     *rand.Rand
+	density float64 // share of stars out, eased towards the energy
+	kick    float64 // beat boost, decaying
+	aspect  int
+
+	gridW, gridH int
+	spread       float64 // half the grid width in half heights
+}
+
+func (s *starfieldPattern) setAspect(aspect int) {
+	if aspect != s.aspect {
+		s.aspect, s.gridW = aspect, 0
+	}
+}
+
+func (s *starfieldPattern) prepare(feat analyzer.Features, dt float64, gridW, gridH int) {
+	if gridW != s.gridW || gridH != s.gridH {
+		s.reset(gridW, gridH)
+	}
+	clear(s.bright)
+	if len(s.stars) == 0 {
+		return
+	}
+
+	s.density += (clamp01(feat.Overall) - s.density) * (1 - math.Exp(-dt/starEase))
+	if feat.Onset {
+		s.kick = math.Max(s.kick, clamp01(feat.BeatStrength))
+	}
+	s.kick *= math.Exp(-dt * 5)
+	speed := starWarp(feat.Tempo) * (1 + 2*s.kick)
+
+	out := int(float64(len(s.stars)) * (starMinShare + (1-starMinShare)*s.density))
+	for i := range s.stars {
+		st := &s.stars[i]
+		st.z -= speed * dt
+		if st.z <= starNear {
+			s.spawn(st, 1)
+		}
+		if i >= out {
+			continue
+		}
+		tail := math.Min(st.z+speed*starTrail, 1)
+		if !s.streak(st, tail) {
+			s.spawn(st, 1)
+		}
+	}
+}
+
+// starWarp is the depth travelled per second at bpm, at the reference
+// speed while no tempo is known.
+func starWarp(bpm float64) float64 {
+	if bpm <= 0 {
+		bpm = starReferenceBPM
+	}
+	return starSpeed * bpm / starReferenceBPM
+}
+
+// reset scatters the stars through the depth of a gridW x gridH grid. The
+// seed is fixed so a resize looks the same.
+func (s *starfieldPattern) reset(gridW, gridH int) {
+	s.gridW, s.gridH = gridW, gridH
+	s.spread = float64(gridW) / float64(max(s.aspect, 1)*max(gridH, 1))
+	s.bright = make([]float64, gridW*gridH)
+	s.rng = rand.New(rand.NewSource(1))
+	s.stars = make([]star, min(int(float64(gridW*gridH)*starDensity), starMax))
+	for i := range s.stars {
+		s.spawn(&s.stars[i], starNear+s.rng.Float64()*(1-starNear))
+	}
+}
+
+func (s *starfieldPattern) spawn(st *star, z float64) {
+	st.x = (s.rng.Float64()*2 - 1) * s.spread
+	st.y = s.rng.Float64()*2 - 1
+	st.z = z
+}
+
+// project returns the cell a point at x, y and depth z lands on.
+func (s *starfieldPattern) project(x, y, z float64) (float64, float64) {
+	half := float64(s.gridH) / 2
+	return float64(s.gridW)/2 + x/z*half*float64(max(s.aspect, 1)), half + y/z*half
+}
+
+// streak draws a star from depth tail to its current depth, brighter the
+// closer it is. It reports false once the star has left the screen.
+func (s *starfieldPattern) streak(st *star, tail float64) bool {
+	x1, y1 := s.project(st.x, st.y, st.z)
+	if x1 < 0 || x1 >= float64(s.gridW) || y1 < 0 || y1 >= float64(s.gridH) {
+		return false
+	}
+	x0, y0 := s.project(st.x, st.y, tail)
+	level := 0.3 + 0.7*(1-st.z)
+	steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0))) + 1
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		cx := clampInt(int(lerp(x0, x1, t)), 0, s.gridW-1)
+		cy := clampInt(int(lerp(y0, y1, t)), 0, s.gridH-1)
+		idx := cy*s.gridW + cx
+		// the tail fades towards where the star was
+		s.bright[idx] = math.Max(s.bright[idx], level*(0.4+0.6*t))
+	}
+	return true
+}
+
+func (s *starfieldPattern) eval(x, y float64) float64 {
+	if len(s.bright) == 0 {
+		return -1
+	}
+	if b := s.bright[gridCell(y, s.gridH)*s.gridW+gridCell(x, s.gridW)]; b > 0 {
+		return b*2 - 1
+	}
+	return -1
+}
@@ -0,0 +1,51 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestStarfieldWarpFollowsTempo(t *testing.T) {
+	if starWarp(0) != starWarp(starReferenceBPM) {
+		t.Error("no tempo doesn't fly at the reference speed")
+	}
+	if math.Abs(starWarp(174)/starWarp(87)-2) > 1e-9 {
+		t.Error("twice the tempo isn't twice the warp")
+	}
+
+	var s starfieldPattern
+	s.setAspect(2)
+	s.prepare(analyzer.Features{}, 0, 120, 40)
+	before := s.stars[0]
+	s.prepare(analyzer.Features{Tempo: 140}, 0.01, 120, 40)
+	if got, want := before.z-s.stars[0].z, starWarp(140)*0.01; math.Abs(got-want) > 1e-9 {
+		t.Fatalf("star moved %v closer, want %v", got, want)
+	}
+	x0, y0 := s.project(before.x, before.y, before.z)
+	x1, y1 := s.project(s.stars[0].x, s.stars[0].y, s.stars[0].z)
+	if math.Hypot(x1-60, y1-20) <= math.Hypot(x0-60, y0-20) {
+		t.Error("star didn't move away from the centre")
+	}
+}
+
+func TestStarfieldDensityFollowsEnergy(t *testing.T) {
+	lit := func(s *starfieldPattern) int {
+		n := 0
+		for _, b := range s.bright {
+			if b > 0 {
+				n++
+			}
+		}
+		return n
+	}
+	var quiet, loud starfieldPattern
+	for range 60 {
+		quiet.prepare(analyzer.Features{}, 1.0/60, 120, 40)
+		loud.prepare(analyzer.Features{Overall: 1}, 1.0/60, 120, 40)
+	}
+	if lit(&loud) < 2*lit(&quiet) {
+		t.Fatalf("loud lit %d cells, quiet %d", lit(&loud), lit(&quiet))
+	}
+}
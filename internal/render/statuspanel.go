@@ -0,0 +1,86 @@
+package render
+
+import "strings"
+
+const (
+	// statusPanelRows is the window height per step of the panel's text
+	// scale: one font dot per pixel up to it, two up to twice it, ...
+	statusPanelRows = 360
+	// statusPanelDim is how much of the visuals shows through the panel.
+	statusPanelDim = 0.35
+)
+
+// SetStatusOverlay sets the lines drawn in the top left corner of the
+// window, where a fullscreen window hides the title the status otherwise
+// goes into. No lines hide it. The SDL window draws it; like SetText it is
+// called from the render loop between frames.
+func (r *Renderer) SetStatusOverlay(lines []string) {
+	r.statusPanel = r.statusPanel[:0]
+	for _, line := range lines {
+		line = foldGlyphs.Replace(strings.ToUpper(strings.TrimSpace(line)))
+		if line != "" {
+			r.statusPanel = append(r.statusPanel, line)
+		}
+	}
+}
+
+// drawStatusPanel stamps lines in white over a dimmed box in the top left
+// corner of the RGBA pixels pix, width x height with stride bytes a row.
+// Lines too long for the window are cut.
+func drawStatusPanel(pix []byte, stride, width, height int, lines []string) {
+	if len(lines) == 0 || width <= 0 || height <= 0 {
+		return
+	}
+	scale := max(height/statusPanelRows, 1)
+	pad := 2 * scale
+	advance, lineStep := glyphAdvance*scale, lineAdvance*scale
+	fit := max((width-2*pad)/advance, 0)
+	cols := 0
+	runes := make([][]rune, 0, len(lines))
+	for _, line := range lines {
+		r := []rune(line)
+		r = r[:min(len(r), fit)]
+		cols = max(cols, len(r))
+		runes = append(runes, r)
+	}
+	if cols == 0 {
+		return
+	}
+	boxW := min(cols*advance-scale+2*pad, width)
+	boxH := min(len(runes)*lineStep-2*scale+2*pad, height)
+	for y := 0; y < boxH; y++ {
+		row := pix[y*stride : y*stride+boxW*4]
+		for i := 0; i < len(row); i += 4 {
+			row[i] = byte(float64(row[i]) * statusPanelDim)
+			row[i+1] = byte(float64(row[i+1]) * statusPanelDim)
+			row[i+2] = byte(float64(row[i+2]) * statusPanelDim)
+		}
+	}
+	for li, line := range runes {
+		y0 := pad + li*lineStep
+		for ci, ch := range line {
+			glyph, ok := font5x7[ch]
+			if !ok {
+				continue
+			}
+			x0 := pad + ci*advance
+			for gy, bits := range glyph {
+				for gx := 0; gx < glyphWidth; gx++ {
+					if bits&(1<<(glyphWidth-1-gx)) != 0 {
+						fillPixels(pix, stride, width, height, x0+gx*scale, y0+gy*scale, scale)
+					}
+				}
+			}
+		}
+	}
+}
+
+// fillPixels paints a size x size square of pix white.
+func fillPixels(pix []byte, stride, width, height, x0, y0, size int) {
+	for y := y0; y < min(y0+size, height); y++ {
+		for x := x0; x < min(x0+size, width); x++ {
+			i := y*stride + x*4
+			pix[i], pix[i+1], pix[i+2], pix[i+3] = 255, 255, 255, 255
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package render
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStatusPanel(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "ripple", "chromatic", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	r.SetStatusOverlay([]string{"fps 60", "  ", "♪ Canción ❤️"})
+	if want := []string{"FPS 60", "♪ CANCION ♥"}; !slices.Equal(r.statusPanel, want) {
+		t.Fatalf("lines %q, want %q", r.statusPanel, want)
+	}
+
+	const w, h = 200, 100
+	pix := make([]byte, w*h*4)
+	for i := range pix {
+		pix[i] = 200
+	}
+	drawStatusPanel(pix, w*4, w, h, r.statusPanel)
+	at := func(x, y int) byte { return pix[y*w*4+x*4] }
+	if at(0, 0) != 70 {
+		t.Errorf("panel corner %d, want dimmed to 70", at(0, 0))
+	}
+	if at(w-1, h-1) != 200 {
+		t.Errorf("outside the panel %d, want untouched", at(w-1, h-1))
+	}
+	// the F of FPS: its top bar starts at the padding
+	if at(2, 2) != 255 || at(6, 2) != 255 {
+		t.Error("first glyph not drawn")
+	}
+
+	// a window too narrow for the text cuts it rather than writing past rows
+	small := make([]byte, 10*4*4)
+	drawStatusPanel(small, 10*4, 10, 4, r.statusPanel)
+	if small[2*10*4+2*4] != 255 {
+		t.Error("cut glyph not drawn")
+	}
+
+	r.SetStatusOverlay(nil)
+	if len(r.statusPanel) != 0 {
+		t.Errorf("overlay not cleared: %q", r.statusPanel)
+	}
+}
@@ -0,0 +1,67 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// symmetryStages are the warp stages a symmetry spec drives.
+var symmetryStages = []string{"mirror", "kaleidoscope"}
+
+// ParseSymmetry turns a --symmetry spec into effect configs for the mirror
+// and kaleidoscope stages. A spec is "off" or modifiers joined by '+':
+// mirror-x, mirror-y, mirror-xy and kaleid:N with N segments, applied in the
+// order given, e.g. "mirror-y+kaleid:6". Stages the spec doesn't name come
+// back disabled, so applying the result replaces any earlier symmetry.
+func ParseSymmetry(spec string) ([]EffectConfig, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" {
+		return nil, fmt.Errorf("empty symmetry")
+	}
+	var out []EffectConfig
+	used := make(map[string]bool, len(symmetryStages))
+	if spec != "off" && spec != "none" {
+		for _, part := range strings.Split(spec, "+") {
+			cfg, err := parseSymmetryPart(strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+			if used[cfg.Name] {
+				return nil, fmt.Errorf("symmetry %q uses %s twice", spec, cfg.Name)
+			}
+			used[cfg.Name] = true
+			out = append(out, cfg)
+		}
+	}
+	for _, name := range symmetryStages {
+		if !used[name] {
+			out = append(out, EffectConfig{Name: name})
+		}
+	}
+	return out, nil
+}
+
+func parseSymmetryPart(part string) (EffectConfig, error) {
+	switch part {
+	case "mirror-x":
+		return EffectConfig{Name: "mirror", Enabled: true, Params: map[string]float64{"x": 1, "y": 0}}, nil
+	case "mirror-y":
+		return EffectConfig{Name: "mirror", Enabled: true, Params: map[string]float64{"x": 0, "y": 1}}, nil
+	case "mirror", "mirror-xy":
+		return EffectConfig{Name: "mirror", Enabled: true, Params: map[string]float64{"x": 1, "y": 1}}, nil
+	}
+	name, count, ok := strings.Cut(part, ":")
+	if name != "kaleid" && name != "kaleidoscope" {
+		return EffectConfig{}, fmt.Errorf("unknown symmetry %q (want mirror-x, mirror-y, mirror-xy or kaleid:N)", part)
+	}
+	if !ok {
+		return EffectConfig{Name: "kaleidoscope", Enabled: true}, nil
+	}
+	n, err := strconv.Atoi(count)
+	limits := EffectLimits()["kaleidoscope"]["segments"]
+	if err != nil || float64(n) < limits[0] || float64(n) > limits[1] {
+		return EffectConfig{}, fmt.Errorf("kaleid needs %v to %v segments, got %q", limits[0], limits[1], count)
+	}
+	return EffectConfig{Name: "kaleidoscope", Enabled: true, Params: map[string]float64{"segments": float64(n)}}, nil
+}
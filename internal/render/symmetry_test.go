@@ -0,0 +1,63 @@
+package render
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseSymmetry(t *testing.T) {
+	cfgs, err := ParseSymmetry("mirror-y+kaleid:8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfgs) != 2 || cfgs[0].Name != "mirror" || cfgs[0].Params["y"] != 1 || cfgs[0].Params["x"] != 0 {
+		t.Fatalf("configs %+v", cfgs)
+	}
+	if !cfgs[1].Enabled || cfgs[1].Params["segments"] != 8 {
+		t.Fatalf("kaleidoscope %+v", cfgs[1])
+	}
+
+	cfgs, err = ParseSymmetry("off")
+	if err != nil || len(cfgs) != 2 || cfgs[0].Enabled || cfgs[1].Enabled {
+		t.Fatalf("off gave %+v, %v", cfgs, err)
+	}
+
+	for _, bad := range []string{"", "kaleid:1", "kaleid:x", "spin", "mirror-x+mirror-y"} {
+		if _, err := ParseSymmetry(bad); err == nil {
+			t.Errorf("%q parsed", bad)
+		}
+	}
+}
+
+func TestSymmetryFoldsCoordinates(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "ripple", "fire", "eco", true, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	same := func(ax, ay, bx, by float64) bool {
+		ax, ay = r.chain.warp(ax, ay)
+		bx, by = r.chain.warp(bx, by)
+		return math.Abs(ax-bx) < 1e-9 && math.Abs(ay-by) < 1e-9
+	}
+
+	cfgs, _ := ParseSymmetry("mirror-x")
+	if err := r.SetEffects(cfgs); err != nil {
+		t.Fatal(err)
+	}
+	r.compileEffects()
+	if !same(0.3, 0.2, -0.3, 0.2) || same(0.3, 0.2, 0.3, -0.2) {
+		t.Error("mirror-x didn't fold left onto right only")
+	}
+
+	cfgs, _ = ParseSymmetry("kaleid:6")
+	r.SetEffects(cfgs)
+	r.compileEffects()
+	turn := math.Pi / 3
+	x, y := 0.4*math.Cos(0.2), 0.4*math.Sin(0.2)
+	if !same(x, y, 0.4*math.Cos(0.2+turn), 0.4*math.Sin(0.2+turn)) {
+		t.Error("kaleid:6 differs a sixth of a turn apart")
+	}
+	if len(r.chain.warps) != 1 {
+		t.Errorf("kaleid:6 left %d warps on", len(r.chain.warps))
+	}
+}
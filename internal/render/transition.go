@@ -0,0 +1,165 @@
+package render
+
+import (
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+// patternSource is what evaluates a pattern, kept for the outgoing one of a
+// crossfade.
+type patternSource struct {
+	fn     patternFunc
+	screen screenPattern
+	layers []patternLayer
+}
+
+// transition crossfades from the pattern, glyph palette and colour mode
+// before a Configure call to the new ones. Configure arms it, Render times
+// it.
+type transition struct {
+	duration time.Duration
+	start    time.Time // zero until the first frame after the change
+	on       bool
+	mix      float64 // how far the frame is into the new look, 0-1
+
+	from    *patternSource // nil when the pattern didn't change
+	palette []rune         // nil when the glyphs didn't change
+	recolor bool
+	color   colorMode
+	curve   ColorCurve // curve of color, picked by compileCurve
+}
+
+// SetTransition sets how long changes of pattern, palette and colour mode
+// crossfade; 0, the default, cuts straight to the new look.
+func (r *Renderer) SetTransition(d time.Duration) {
+	r.fade.duration = max(d, 0)
+	if d <= 0 {
+		r.fade.stop()
+	}
+}
+
+// look is the part of the renderer a transition fades out of.
+type look struct {
+	pattern     patternSource
+	patternName string
+	palette     []rune
+	paletteName string
+	color       colorMode
+}
+
+func (r *Renderer) currentLook() look {
+	return look{
+		pattern:     patternSource{fn: r.pattern, screen: r.screen, layers: r.layers},
+		patternName: r.patternName,
+		palette:     r.palette,
+		paletteName: r.paletteName,
+		color:       r.colorMode,
+	}
+}
+
+// beginTransition starts fading from old to the renderer's look. Lua and
+// shader patterns cut, as there is one script or program at a time, and so
+// do glyph changes into or out of braille and half blocks, which draw cells
+// differently.
+func (r *Renderer) beginTransition(old look) {
+	t := &r.fade
+	if t.duration <= 0 || old.patternName == "" {
+		return
+	}
+	patternChanged := old.patternName != r.patternName &&
+		!isLuaPattern(old.patternName) && !isShaderPattern(old.patternName) &&
+		!isLuaPattern(r.patternName) && !isShaderPattern(r.patternName)
+	paletteChanged := old.paletteName != r.paletteName && !cellPalette(old.paletteName) && !cellPalette(r.paletteName)
+	colorChanged := old.color != r.colorMode
+	if !patternChanged && !paletteChanged && !colorChanged {
+		return
+	}
+
+	// a change during a fade cuts that fade short
+	t.stop()
+	if patternChanged {
+		from := old.pattern
+		t.from = &from
+	}
+	if paletteChanged {
+		t.palette = old.palette
+	}
+	t.recolor, t.color = colorChanged, old.color
+	t.on, t.mix, t.start = true, 0, time.Time{}
+}
+
+// cellPalette reports whether a palette draws several pixels per cell.
+func cellPalette(name string) bool {
+	return name == "braille" || name == "halfblock"
+}
+
+// stop ends the fade, dropping the outgoing state.
+func (t *transition) stop() {
+	t.on, t.mix = false, 1
+	t.from, t.palette, t.recolor = nil, nil, false
+}
+
+// prepare works out the mix of the frame at now, eased so the fade starts
+// and lands softly.
+func (t *transition) prepare(now time.Time) {
+	if !t.on {
+		return
+	}
+	if t.start.IsZero() {
+		t.start = now
+	}
+	progress := now.Sub(t.start).Seconds() / t.duration.Seconds()
+	if progress >= 1 {
+		t.stop()
+		return
+	}
+	t.mix = smoothstep(clamp01(progress))
+}
+
+// evalPattern returns the value of src at vx, vy: screen patterns draw
+// upright from the top left, the rest through the frame's distortion.
+func (r *Renderer) evalPattern(src *patternSource, vx, vy float64, p params.Parameters, ctx frameParams) float64 {
+	switch {
+	case src.layers != nil:
+		return r.evalLayers(src.layers, vx, vy, p, ctx)
+	case src.screen != nil:
+		// screen patterns draw upright, 0-1 from the top left
+		return src.screen.eval(vx/ctx.scale+0.5, vy/ctx.scale+0.5)
+	}
+	distortedX, distortedY := r.distort(vx, vy, ctx)
+	return src.fn(distortedX, distortedY, p, ctx.time)
+}
+
+// fadeGlyph picks between the outgoing and the current glyph of cell idx,
+// dissolving into the new palette as the fade goes on.
+func (t *transition) fadeGlyph(glyph rune, glyphValue float64, idx int) rune {
+	if !t.on || t.palette == nil || hash2(float64(idx), 7) < t.mix {
+		return glyph
+	}
+	return t.palette[clampInt(int(glyphValue*float64(len(t.palette)-1)+0.5), 0, len(t.palette)-1)]
+}
+
+// fadeColor mixes the colour the outgoing colour mode gives a pixel into
+// h, s, v, in RGB so hues don't sweep around the wheel.
+func (r *Renderer) fadeColor(h, s, v, base, brightness float64, p params.Parameters, feat analyzer.Features, activation float64) (float64, float64, float64) {
+	t := &r.fade
+	if !t.on || !t.recolor {
+		return h, s, v
+	}
+	oh, os, ov := r.colorIn(t.color, t.curve, base, brightness, p, feat, activation)
+	r0, g0, b0 := hsvToRGB(oh, os, ov)
+	r1, g1, b1 := hsvToRGB(h, s, v)
+	return rgbToHSV(lerp(r0, r1, t.mix), lerp(g0, g1, t.mix), lerp(b0, b1, t.mix))
+}
+
+// fadeValue mixes the outgoing pattern's value at vx, vy into value.
+func (r *Renderer) fadeValue(value, vx, vy float64, p params.Parameters, ctx frameParams) float64 {
+	t := &r.fade
+	if !t.on || t.from == nil {
+		return value
+	}
+	old := clampFloat(r.evalPattern(t.from, vx, vy, p, ctx), -1, 1)
+	return lerp(old, clampFloat(value, -1, 1), t.mix)
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/params"
+)
+
+func TestTransitionCrossfadesPatterns(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "flash", "chromatic", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	r.SetTransition(time.Second)
+	r.Configure("box", "bars", "fire", false)
+	if !r.fade.on || r.fade.from == nil || r.fade.palette == nil || !r.fade.recolor {
+		t.Fatalf("fade not armed: %+v", r.fade)
+	}
+
+	p := params.Defaults()
+	ctx := r.buildFrameParams(p, 0)
+	old := r.evalPattern(&patternSource{fn: patternRegistry["flash"].fn}, 0.1, 0.2, p, ctx)
+	start := time.Now()
+	for _, tc := range []struct {
+		at  time.Duration
+		mix float64
+	}{
+		{0, 0},
+		{500 * time.Millisecond, 0.5},
+		{time.Second, 1},
+	} {
+		r.fade.prepare(start.Add(tc.at))
+		if math.Abs(r.fade.mix-tc.mix) > 1e-9 {
+			t.Errorf("at %v mix %v, want %v", tc.at, r.fade.mix, tc.mix)
+		}
+		if tc.mix == 0 {
+			if got := r.fadeValue(0.7, 0.1, 0.2, p, ctx); math.Abs(got-old) > 1e-9 {
+				t.Errorf("first frame %v, want the old pattern's %v", got, old)
+			}
+		}
+	}
+	if r.fade.on || r.fade.from != nil {
+		t.Error("fade still on after its duration")
+	}
+	if got := r.fadeValue(0.7, 0.1, 0.2, p, ctx); got != 0.7 {
+		t.Errorf("after the fade %v, want the new pattern's", got)
+	}
+}
+
+func TestTransitionCuts(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "flash", "chromatic", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	r.Configure("default", "ripple", "chromatic", false)
+	if r.fade.on {
+		t.Fatal("faded without a transition set")
+	}
+
+	r.SetTransition(time.Second)
+	r.Configure("default", "ripple", "chromatic", false)
+	if r.fade.on {
+		t.Error("faded without a change")
+	}
+	r.Configure("default", "lua:missing", "chromatic", false)
+	if r.fade.on {
+		t.Error("faded into a lua pattern")
+	}
+	r.Configure("braille", "ripple", "chromatic", false)
+	if r.fade.palette != nil {
+		t.Error("dissolved glyphs into braille")
+	}
+}
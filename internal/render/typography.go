@@ -0,0 +1,217 @@
+package render
+
+import (
+	"hash/fnv"
+	"math"
+	"strings"
+)
+
+// font5x7 is a classic 5x7 dot-matrix font. Each row is a 5-bit mask, most
+// significant bit on the left.
+var font5x7 = map[rune][7]uint8{
+	'A':  {0b01110, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'B':  {0b11110, 0b10001, 0b10001, 0b11110, 0b10001, 0b10001, 0b11110},
+	'C':  {0b01110, 0b10001, 0b10000, 0b10000, 0b10000, 0b10001, 0b01110},
+	'D':  {0b11110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b11110},
+	'E':  {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b11111},
+	'F':  {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b10000},
+	'G':  {0b01110, 0b10001, 0b10000, 0b10111, 0b10001, 0b10001, 0b01111},
+	'H':  {0b10001, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'I':  {0b01110, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'J':  {0b00111, 0b00010, 0b00010, 0b00010, 0b00010, 0b10010, 0b01100},
+	'K':  {0b10001, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010, 0b10001},
+	'L':  {0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b11111},
+	'M':  {0b10001, 0b11011, 0b10101, 0b10101, 0b10001, 0b10001, 0b10001},
+	'N':  {0b10001, 0b10001, 0b11001, 0b10101, 0b10011, 0b10001, 0b10001},
+	'O':  {0b01110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'P':  {0b11110, 0b10001, 0b10001, 0b11110, 0b10000, 0b10000, 0b10000},
+	'Q':  {0b01110, 0b10001, 0b10001, 0b10001, 0b10101, 0b10010, 0b01101},
+	'R':  {0b11110, 0b10001, 0b10001, 0b11110, 0b10100, 0b10010, 0b10001},
+	'S':  {0b01111, 0b10000, 0b10000, 0b01110, 0b00001, 0b00001, 0b11110},
+	'T':  {0b11111, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100},
+	'U':  {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'V':  {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100},
+	'W':  {0b10001, 0b10001, 0b10001, 0b10101, 0b10101, 0b10101, 0b01010},
+	'X':  {0b10001, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001, 0b10001},
+	'Y':  {0b10001, 0b10001, 0b10001, 0b01010, 0b00100, 0b00100, 0b00100},
+	'Z':  {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0b11111},
+	'Ñ':  {0b01010, 0b10101, 0b10001, 0b11001, 0b10101, 0b10011, 0b10001},
+	'0':  {0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	'1':  {0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'2':  {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	'3':  {0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	'4':  {0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	'5':  {0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	'6':  {0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	'7':  {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	'8':  {0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	'9':  {0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+	'!':  {0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00000, 0b00100},
+	'?':  {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b00000, 0b00100},
+	'.':  {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b01100},
+	',':  {0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b00100, 0b01000},
+	'\'': {0b01100, 0b00100, 0b01000, 0b00000, 0b00000, 0b00000, 0b00000},
+	'-':  {0b00000, 0b00000, 0b00000, 0b11111, 0b00000, 0b00000, 0b00000},
+	':':  {0b00000, 0b01100, 0b01100, 0b00000, 0b01100, 0b01100, 0b00000},
+	'#':  {0b01010, 0b01010, 0b11111, 0b01010, 0b11111, 0b01010, 0b01010},
+	'&':  {0b01100, 0b10010, 0b10100, 0b01000, 0b10101, 0b10010, 0b01101},
+	'♥':  {0b00000, 0b01010, 0b11111, 0b11111, 0b01110, 0b00100, 0b00000},
+	'★':  {0b00100, 0b00100, 0b11111, 0b01110, 0b01110, 0b11011, 0b10001},
+	'♪':  {0b00110, 0b00101, 0b00100, 0b00100, 0b01100, 0b11100, 0b11000},
+	'/':  {0b00001, 0b00010, 0b00010, 0b00100, 0b01000, 0b01000, 0b10000},
+	'(':  {0b00010, 0b00100, 0b01000, 0b01000, 0b01000, 0b00100, 0b00010},
+	')':  {0b01000, 0b00100, 0b00010, 0b00010, 0b00010, 0b00100, 0b01000},
+	'%':  {0b11000, 0b11001, 0b00010, 0b00100, 0b01000, 0b10011, 0b00011},
+	'+':  {0b00000, 0b00100, 0b00100, 0b11111, 0b00100, 0b00100, 0b00000},
+	'=':  {0b00000, 0b00000, 0b11111, 0b00000, 0b11111, 0b00000, 0b00000},
+	'°':  {0b01100, 0b10010, 0b10010, 0b01100, 0b00000, 0b00000, 0b00000},
+}
+
+// foldGlyphs maps accented letters and common emoji onto the glyphs the
+// font has, and drops the emoji joiners and variation selectors that would
+// otherwise take a blank place each.
+var foldGlyphs = strings.NewReplacer(
+	"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U", "Ü", "U",
+	"À", "A", "È", "E", "Ì", "I", "Ò", "O", "Ù", "U", "Ç", "C",
+	"¡", "!", "¿", "?",
+	"\u200d", "", "\ufe0e", "", "\ufe0f", "",
+	"❤", "♥", "♡", "♥", "💖", "♥", "💗", "♥", "💓", "♥", "💕", "♥",
+	"🧡", "♥", "💛", "♥", "💚", "♥", "💙", "♥", "💜", "♥", "🖤", "♥", "🤍", "♥",
+	"⭐", "★", "🌟", "★", "✨", "★", "☆", "★",
+	"🎵", "♪", "🎶", "♪", "♫", "♪", "♬", "♪",
+)
+
+const (
+	glyphWidth   = 5
+	glyphHeight  = 7
+	glyphAdvance = glyphWidth + 1
+	lineAdvance  = glyphHeight + 2
+)
+
+// textOverlay is big dot-matrix text stamped over the pattern. The mask is
+// rebuilt only when the text or the frame size changes.
+type textOverlay struct {
+	text   string
+	level  float64
+	hue    float64
+	mask   []bool
+	width  int
+	height int
+	built  string
+}
+
+// SetText flashes text in large letters over the visuals at level (0-1).
+// Each distinct text gets its own colour from the active colour mode. An
+// empty text or level 0 hides the overlay.
+func (r *Renderer) SetText(text string, level float64) {
+	text = foldGlyphs.Replace(strings.ToUpper(strings.TrimSpace(text)))
+	if text != r.text.text {
+		r.text.text = text
+		h := fnv.New32a()
+		h.Write([]byte(text))
+		r.text.hue = float64(h.Sum32()%2000)/1000.0 - 1.0
+	}
+	r.text.level = clamp01(level)
+}
+
+// prepare rebuilds the mask when the text or frame size changed.
+func (t *textOverlay) prepare(width, height int, aspect int) {
+	if t.text == "" || t.level <= 0 {
+		return
+	}
+	if t.built == t.text && t.width == width && t.height == height {
+		return
+	}
+	t.built, t.width, t.height = t.text, width, height
+	if len(t.mask) != width*height {
+		t.mask = make([]bool, width*height)
+	} else {
+		clear(t.mask)
+	}
+	layoutText(t.mask, width, height, aspect, t.text)
+}
+
+// covers reports whether cell idx is lit by the text.
+func (t *textOverlay) covers(idx int) bool {
+	return t.text != "" && t.level > 0 && idx >= 0 && idx < len(t.mask) && t.mask[idx]
+}
+
+// layoutText word-wraps text to fit, picks the largest integer scale that
+// fits the frame and stamps the glyphs centred into mask. aspect widens dots
+// horizontally to make up for tall terminal cells.
+func layoutText(mask []bool, width, height, aspect int, text string) {
+	layoutTextIn(mask, width, height, aspect, text, 0, height)
+}
+
+// layoutTextIn is layoutText within the band of rows from top, band tall.
+func layoutTextIn(mask []bool, width, height, aspect int, text string, top, band int) {
+	lines := wrapText(text, max(1, width/(glyphAdvance*aspect)))
+	longest := 0
+	for _, line := range lines {
+		longest = max(longest, len([]rune(line)))
+	}
+	if longest == 0 {
+		return
+	}
+	blockW := longest*glyphAdvance - 1
+	blockH := len(lines)*lineAdvance - 2
+	scale := int(math.Min(float64(width)/float64(blockW*aspect), float64(band)*0.8/float64(blockH)))
+	scale = max(scale, 1)
+	sx, sy := scale*aspect, scale
+
+	top += (band - blockH*sy) / 2
+	for li, line := range lines {
+		runes := []rune(line)
+		left := (width - (len(runes)*glyphAdvance-1)*sx) / 2
+		stampLine(mask, width, height, runes, left, top+li*lineAdvance*sy, sx, sy)
+	}
+}
+
+// stampLine draws runes with the top left of the first glyph at x0, y0,
+// each font dot sx by sy cells.
+func stampLine(mask []bool, width, height int, runes []rune, x0, y0, sx, sy int) {
+	for ci, ch := range runes {
+		rows, ok := font5x7[ch]
+		if !ok {
+			continue
+		}
+		x := x0 + ci*glyphAdvance*sx
+		for gy, bits := range rows {
+			for gx := 0; gx < glyphWidth; gx++ {
+				if bits&(1<<(glyphWidth-1-gx)) == 0 {
+					continue
+				}
+				fillCell(mask, width, height, x+gx*sx, y0+gy*sy, sx, sy)
+			}
+		}
+	}
+}
+
+func fillCell(mask []bool, width, height, x0, y0, w, h int) {
+	for y := max(y0, 0); y < min(y0+h, height); y++ {
+		for x := max(x0, 0); x < min(x0+w, width); x++ {
+			mask[y*width+x] = true
+		}
+	}
+}
+
+// wrapText breaks text into lines of at most limit runes at word boundaries.
+func wrapText(text string, limit int) []string {
+	var lines []string
+	var current []rune
+	for _, word := range strings.Fields(text) {
+		w := []rune(word)
+		if len(current) > 0 && len(current)+1+len(w) > limit {
+			lines = append(lines, string(current))
+			current = current[:0]
+		}
+		if len(current) > 0 {
+			current = append(current, ' ')
+		}
+		current = append(current, w...)
+	}
+	if len(current) > 0 {
+		lines = append(lines, string(current))
+	}
+	return lines
+}
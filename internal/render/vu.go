@@ -0,0 +1,134 @@
+package render
+
+import (
+	"math"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+// vuTau is the time constant of the meters, so they reach 99% of a steady
+// level in 300 ms like a VU needle.
+var vuTau = 0.3 / math.Log(100)
+
+const (
+	// vuRange is the dB below full scale where the level meter starts.
+	vuRange = 48.0
+	// vuPeakHold is how long a peak marker stays before it falls.
+	vuPeakHold = 1.5
+	// vuPeakFall is how fast a marker falls once the hold is over, in
+	// meter lengths per second.
+	vuPeakFall = 0.4
+	// vuMeters is the level meter and one per band below it.
+	vuMeters = 6
+	// vuLevelWeight is how many band meters' height the level meter takes.
+	vuLevelWeight = 3
+)
+
+// vuMeter is the state of one meter bar, in 0-1 of its length.
+type vuMeter struct {
+	fill float64
+	peak float64
+	hold float64
+}
+
+// vuPattern is a monitoring display: a wide meter for the input level on a
+// dB scale with 300 ms ballistics and a peak-hold marker, and below it one
+// thinner meter per band.
+type vuPattern struct {
+	meters [vuMeters]vuMeter
+	level  float64 // the integrated RMS the level meter shows
+	rows   []int   // the meter on each row, -1 for gaps
+
+	gridW, gridH int
+}
+
+func (v *vuPattern) prepare(feat analyzer.Features, dt float64, gridW, gridH int) {
+	if gridW != v.gridW || gridH != v.gridH {
+		v.gridW, v.gridH = gridW, gridH
+		v.rows = vuLayout(gridH)
+	}
+
+	k := 1 - math.Exp(-dt/vuTau)
+	v.level += (feat.Level - v.level) * k
+	v.meters[0].step(vuPosition(v.level), vuPosition(feat.Peak), dt)
+	for i, band := range [vuMeters - 1]float64{feat.Sub, feat.Bass, feat.LowMid, feat.HighMid, feat.Treble} {
+		m := &v.meters[i+1]
+		m.step(m.fill+(band-m.fill)*k, band, dt)
+	}
+}
+
+// step sets the meter to fill and raises or lets the marker fall towards
+// peak.
+func (m *vuMeter) step(fill, peak float64, dt float64) {
+	m.fill = clamp01(fill)
+	peak = math.Max(clamp01(peak), m.fill)
+	if peak >= m.peak {
+		m.peak = peak
+		m.hold = vuPeakHold
+		return
+	}
+	fall := dt - m.hold
+	m.hold = math.Max(m.hold-dt, 0)
+	if fall > 0 {
+		m.peak = math.Max(peak, m.peak-vuPeakFall*fall)
+	}
+}
+
+func (v *vuPattern) eval(x, y float64) float64 {
+	if v.gridW < 3 || v.gridH == 0 {
+		return -1
+	}
+	meter := v.rows[gridCell(y, v.gridH)]
+	col := gridCell(x, v.gridW) - 1
+	length := v.gridW - 2
+	if meter < 0 || col < 0 || col >= length {
+		return -1
+	}
+	m := v.meters[meter]
+	if col == min(int(m.peak*float64(length)), length-1) && m.peak > 0.02 {
+		return 1
+	}
+	if col >= int(m.fill*float64(length)+0.5) {
+		return -1
+	}
+	return 0.2 + 0.7*float64(col)/float64(length)
+}
+
+// vuPosition maps a linear level to the meter, -vuRange dB to full scale.
+func vuPosition(level float64) float64 {
+	if level <= 0 {
+		return 0
+	}
+	return clamp01(1 + 20*math.Log10(level)/vuRange)
+}
+
+// vuLayout assigns the rows of a gridH tall grid to meters, the level meter
+// vuLevelWeight times as tall as a band meter and a blank row between
+// meters. Grids too short for that show the level meter alone.
+func vuLayout(gridH int) []int {
+	rows := make([]int, gridH)
+	for i := range rows {
+		rows[i] = -1
+	}
+	units := vuLevelWeight + vuMeters - 1
+	unit := (gridH - (vuMeters - 1)) / units
+	if unit == 0 {
+		for i := range rows {
+			rows[i] = 0
+		}
+		return rows
+	}
+	row := (gridH - unit*units - (vuMeters - 1)) / 2
+	for meter := range vuMeters {
+		height := unit
+		if meter == 0 {
+			height = unit * vuLevelWeight
+		}
+		for range height {
+			rows[row] = meter
+			row++
+		}
+		row++
+	}
+	return rows
+}
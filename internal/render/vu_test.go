@@ -0,0 +1,50 @@
+package render
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestVULayout(t *testing.T) {
+	// 8 units of 2 rows plus 5 gaps leave a spare row above and below
+	want := []int{-1, 0, 0, 0, 0, 0, 0, -1, 1, 1, -1, 2, 2, -1, 3, 3, -1, 4, 4, -1, 5, 5, -1}
+	if got := vuLayout(23); !slices.Equal(got, want) {
+		t.Fatalf("layout %v", got)
+	}
+	if got := vuLayout(8); slices.Contains(got, 1) || slices.Contains(got, -1) {
+		t.Fatalf("short grid %v", got)
+	}
+}
+
+func TestVUBallistics(t *testing.T) {
+	var v vuPattern
+	feat := analyzer.Features{Level: 0.5, Peak: 0.9, Bass: 0.8}
+	for range 18 { // 300 ms at 60 fps
+		v.prepare(feat, 1.0/60, 40, 23)
+	}
+	if got := v.level / feat.Level; got < 0.98 || got > 1 {
+		t.Fatalf("level at %.3f of the input after 300 ms", got)
+	}
+	if math.Abs(v.meters[2].fill-0.8) > 0.02 {
+		t.Fatalf("bass meter %.3f", v.meters[2].fill)
+	}
+	if peak := v.meters[0].peak; peak != vuPosition(0.9) {
+		t.Fatalf("peak %.3f", peak)
+	}
+
+	// the marker holds through silence, then falls
+	v.prepare(analyzer.Features{}, 1, 40, 23)
+	if v.meters[0].peak != vuPosition(0.9) || v.meters[0].fill > 0.5 {
+		t.Fatalf("after 1s: peak %.3f fill %.3f", v.meters[0].peak, v.meters[0].fill)
+	}
+	v.prepare(analyzer.Features{}, 1, 40, 23)
+	if v.meters[0].peak >= vuPosition(0.9) {
+		t.Fatal("peak still held after 2s")
+	}
+	if v.eval(0, 0.1) != -1 {
+		t.Fatal("margin lit")
+	}
+}
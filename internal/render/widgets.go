@@ -0,0 +1,101 @@
+package render
+
+import (
+	"slices"
+	"strings"
+)
+
+// Corners are the places a widget can sit, in the order SetWidgets takes
+// their lines.
+var Corners = []string{"top-left", "top-right", "bottom-left", "bottom-right"}
+
+// CornerIndex returns the position of corner in Corners, or -1.
+func CornerIndex(corner string) int {
+	return slices.Index(Corners, strings.ToLower(strings.TrimSpace(corner)))
+}
+
+// widgetsScaleRows is the grid height per step of the widget text scale, so
+// readouts grow with pixel backends but stay one dot per cell in a
+// terminal.
+const widgetsScaleRows = glyphHeight * 12
+
+// widgetLayer draws small readouts (clock, fps, now playing) in the screen
+// corners, in white over everything else.
+type widgetLayer struct {
+	lines  [4][]string
+	mask   []bool
+	width  int
+	height int
+	built  [4][]string
+	any    bool
+}
+
+// SetWidgets sets the lines shown in each corner, indexed like Corners.
+// Like SetText it is called from the render loop between frames.
+func (r *Renderer) SetWidgets(lines [4][]string) {
+	w := &r.widgets
+	w.any = false
+	for i, corner := range lines {
+		w.lines[i] = w.lines[i][:0]
+		for _, line := range corner {
+			line = foldGlyphs.Replace(strings.ToUpper(strings.TrimSpace(line)))
+			if line != "" {
+				w.lines[i] = append(w.lines[i], line)
+				w.any = true
+			}
+		}
+	}
+}
+
+// prepare rebuilds the mask when a line or the frame size changed. Unlike
+// the big text, widget dots stay one cell wide in a terminal, so a readout
+// fits in a corner at the cost of looking narrow.
+func (w *widgetLayer) prepare(width, height int) {
+	if !w.any {
+		return
+	}
+	same := w.width == width && w.height == height
+	for i := range w.lines {
+		same = same && slices.Equal(w.lines[i], w.built[i])
+	}
+	if same {
+		return
+	}
+	w.width, w.height = width, height
+	for i := range w.lines {
+		w.built[i] = append(w.built[i][:0], w.lines[i]...)
+	}
+	if len(w.mask) != width*height {
+		w.mask = make([]bool, width*height)
+	} else {
+		clear(w.mask)
+	}
+
+	scale := max(height/widgetsScaleRows, 1)
+	sx, sy := scale, scale
+	limit := max((width-2*sx)/(glyphAdvance*sx), 1)
+	for corner, lines := range w.lines {
+		right, bottom := corner%2 == 1, corner >= 2
+		y := sy
+		if bottom {
+			y = height - sy - (len(lines)*lineAdvance-2)*sy
+		}
+		for _, line := range lines {
+			runes := []rune(line)
+			if len(runes) > limit {
+				runes = append(runes[:limit-1], '.')
+			}
+			x := sx
+			if right {
+				x = width - sx - (len(runes)*glyphAdvance-1)*sx
+			}
+			stampLine(w.mask, width, height, runes, x, y, sx, sy)
+			y += lineAdvance * sy
+		}
+	}
+}
+
+// covers reports whether cell idx is lit by a widget.
+func (w *widgetLayer) covers(idx int) bool {
+	return w.any && idx >= 0 && idx < len(w.mask) && w.mask[idx]
+}
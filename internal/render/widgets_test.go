@@ -0,0 +1,51 @@
+package render
+
+import "testing"
+
+func TestWidgetsSitInTheirCorners(t *testing.T) {
+	r, err := New(snapshotWidth, snapshotHeight, "default", "ripple", "chromatic", "high", false, false)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	const w, h = 80, 30
+	var lines [4][]string
+	lines[CornerIndex("top-right")] = []string{"12:34"}
+	lines[CornerIndex("Bottom-Left")] = []string{"60 fps", ""}
+	r.SetWidgets(lines)
+	r.widgets.prepare(w, h)
+
+	var top, bottom [2]int // lit cells left and right of the middle
+	for idx, on := range r.widgets.mask {
+		if !on {
+			continue
+		}
+		x, y := idx%w, idx/w
+		switch {
+		case y < 8:
+			top[x*2/w]++
+		case y >= h-8:
+			bottom[x*2/w]++
+		default:
+			t.Fatalf("widget dot at %d,%d", x, y)
+		}
+	}
+	if top[0] != 0 || top[1] == 0 || bottom[0] == 0 || bottom[1] != 0 {
+		t.Fatalf("top %v, bottom %v", top, bottom)
+	}
+	// the clock ends one cell from the right edge, on the bar of the 4
+	if !r.widgets.covers(5*w+w-2) || r.widgets.covers(5*w+w-1) {
+		t.Fatal("clock not right-aligned")
+	}
+
+	r.SetWidgets([4][]string{})
+	r.widgets.prepare(w, h)
+	if r.widgets.covers(5*w + w - 2) {
+		t.Fatal("cleared widgets still drawn")
+	}
+}
+
+func TestCornerIndex(t *testing.T) {
+	if CornerIndex(" TOP-LEFT ") != 0 || CornerIndex("bottom-right") != 3 || CornerIndex("middle") != -1 {
+		t.Fatal("corner lookup")
+	}
+}
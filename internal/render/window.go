@@ -0,0 +1,26 @@
+package render
+
+// WindowKey is a key pressed in the SDL or GL window: a typed character in
+// Char, or one of the keys without one in Name ("esc", "up", "down", "left",
+// "right").
+type WindowKey struct {
+	Char rune
+	Name string
+}
+
+// SetKeyHandler has the windowed backends pass key presses to fn while they
+// poll the window; fn reports whether the key quits, which makes the frame's
+// Present return ErrRendererQuit. It runs on the goroutine presenting frames.
+func (r *Renderer) SetKeyHandler(fn func(WindowKey) bool) {
+	r.keyHandler = fn
+}
+
+// PollWindow handles the window's pending events without drawing, for
+// frames that are held rather than rendered. It returns ErrRendererQuit
+// once the window was closed, and nil off the windowed backends.
+func (r *Renderer) PollWindow() error {
+	if !r.IsWindowed() {
+		return nil
+	}
+	return r.pollWindowEvents()
+}
@@ -0,0 +1,43 @@
+//go:build sdl || gl
+
+package render
+
+import "github.com/veandco/go-sdl2/sdl"
+
+// windowKeyNames names the keys the handler gets that don't type text.
+var windowKeyNames = map[sdl.Keycode]string{
+	sdl.K_ESCAPE: "esc",
+	sdl.K_UP:     "up",
+	sdl.K_DOWN:   "down",
+	sdl.K_LEFT:   "left",
+	sdl.K_RIGHT:  "right",
+}
+
+// pollWindowEvents drains SDL's event queue, handing key presses to the key
+// handler.
+func (r *Renderer) pollWindowEvents() error {
+	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+		switch e := event.(type) {
+		case *sdl.QuitEvent:
+			return ErrRendererQuit
+		case *sdl.KeyboardEvent:
+			name, ok := windowKeyNames[e.Keysym.Sym]
+			if !ok || e.Type != sdl.KEYDOWN || r.keyHandler == nil {
+				continue
+			}
+			if r.keyHandler(WindowKey{Name: name}) {
+				return ErrRendererQuit
+			}
+		case *sdl.TextInputEvent:
+			if r.keyHandler == nil {
+				continue
+			}
+			for _, char := range e.GetText() {
+				if r.keyHandler(WindowKey{Char: char}) {
+					return ErrRendererQuit
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+//go:build !sdl && !gl
+
+package render
+
+func (r *Renderer) pollWindowEvents() error { return nil }
@@ -0,0 +1,368 @@
+package sink
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register("artnet", func() OutputSink { return &dmx{protocol: protocolArtNet} })
+	Register("sacn", func() OutputSink { return &dmx{protocol: protocolSACN} })
+}
+
+const (
+	protocolArtNet = "artnet"
+	protocolSACN   = "sacn"
+
+	artNetPort = 6454
+	sacnPort   = 5568
+	// dmxSlots is the size of a DMX universe.
+	dmxSlots = 512
+	// colorBlack is the level below which a pixel doesn't count towards a
+	// zone's dominant colour.
+	colorBlack = 24
+)
+
+// dmx drives lighting fixtures over Art-Net or sACN (E1.31). The frame is
+// cut into a grid of zones, one fixture each, and every zone's colour is
+// sent in the fixture's channel layout. Fixtures fill a universe and carry
+// on in the next one; none is split between two. Options:
+//
+//   - host: where to send, default broadcast for Art-Net and the universe's
+//     multicast group for sACN
+//   - universe: the first universe (default 0 for Art-Net, 1 for sACN)
+//   - zones: the grid, columns x rows, read left to right and top to
+//     bottom (default 8x1)
+//   - channels: one letter per fixture channel: r, g, b, w (white, the
+//     common part of r, g and b) and d (a dimmer, always full), default rgb
+//   - start: the first DMX channel, 1-512 (default 1)
+//   - sample: average (default) or dominant, the most common bright colour
+//     of the zone
+//   - fps: the most universes sent per second (default 40)
+type dmx struct {
+	protocol string
+	conns    []net.Conn // one per universe, sACN multicast groups differ
+	universe int
+	cols     int
+	rows     int
+	channels string
+	start    int
+	dominant bool
+	every    time.Duration
+	cid      [16]byte
+
+	last   time.Time
+	seq    byte
+	colors [][3]byte
+	bins   map[uint16]*colorBin
+	frames chan [][]byte
+
+	mu  sync.Mutex
+	err error
+}
+
+type colorBin struct {
+	count   int
+	r, g, b int
+}
+
+func (d *dmx) Init(cfg Config) error {
+	var err error
+	// Art-Net port-addresses are 15 bits; E1.31 universes run 1-63999
+	first, last := 0, 32767
+	if d.protocol == protocolSACN {
+		first, last = 1, 63999
+	}
+	if d.universe, err = strconv.Atoi(cfg.Option("universe", strconv.Itoa(first))); err != nil || d.universe < first || d.universe > last {
+		return fmt.Errorf("bad universe %q (%d-%d)", cfg.Option("universe", ""), first, last)
+	}
+	if _, err := fmt.Sscanf(cfg.Option("zones", "8x1"), "%dx%d", &d.cols, &d.rows); err != nil || d.cols < 1 || d.rows < 1 {
+		return fmt.Errorf("bad zones %q (want e.g. 8x1)", cfg.Option("zones", ""))
+	}
+	d.channels = strings.ToLower(cfg.Option("channels", "rgb"))
+	if d.channels == "" || strings.Trim(d.channels, "rgbwd") != "" || len(d.channels) > dmxSlots {
+		return fmt.Errorf("bad channels %q (letters r, g, b, w, d)", d.channels)
+	}
+	if d.start, err = strconv.Atoi(cfg.Option("start", "1")); err != nil || d.start < 1 || d.start+len(d.channels)-1 > dmxSlots {
+		return fmt.Errorf("bad start channel %q", cfg.Option("start", ""))
+	}
+	switch cfg.Option("sample", "average") {
+	case "average":
+	case "dominant":
+		d.dominant = true
+		d.bins = map[uint16]*colorBin{}
+	default:
+		return fmt.Errorf("bad sample %q (average or dominant)", cfg.Option("sample", ""))
+	}
+	fps, err := strconv.ParseFloat(cfg.Option("fps", "40"), 64)
+	if err != nil || fps <= 0 {
+		return fmt.Errorf("bad fps %q", cfg.Option("fps", ""))
+	}
+	d.every = time.Duration(float64(time.Second) / fps)
+	d.colors = make([][3]byte, d.cols*d.rows)
+	if d.universe+d.universes()-1 > last {
+		return fmt.Errorf("%d zones run past universe %d", len(d.colors), last)
+	}
+	rand.Read(d.cid[:])
+
+	for i := range d.universes() {
+		conn, err := d.dial(cfg.Option("host", ""), d.universe+i)
+		if err != nil {
+			d.Close()
+			return err
+		}
+		d.conns = append(d.conns, conn)
+	}
+	d.frames = make(chan [][]byte, 1)
+	go d.run()
+	return nil
+}
+
+// perUniverse is how many fixtures fit in one universe.
+func (d *dmx) perUniverse() int {
+	return (dmxSlots - d.start + 1) / len(d.channels)
+}
+
+// universes is how many universes the zones take up.
+func (d *dmx) universes() int {
+	per := d.perUniverse()
+	return (len(d.colors) + per - 1) / per
+}
+
+func (d *dmx) dial(host string, universe int) (net.Conn, error) {
+	port := artNetPort
+	if d.protocol == protocolSACN {
+		port = sacnPort
+		if host == "" {
+			host = fmt.Sprintf("239.255.%d.%d", universe>>8, universe&0xff)
+		}
+	} else if host == "" {
+		host = "255.255.255.255"
+	}
+	addr, err := net.ResolveUDPAddr("udp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, err
+	}
+	// a connected socket can't send to broadcast on every platform, so
+	// the destination rides along with each write
+	return &udpTo{UDPConn: conn, to: addr}, nil
+}
+
+// udpTo writes every datagram to a fixed destination.
+type udpTo struct {
+	*net.UDPConn
+	to *net.UDPAddr
+}
+
+func (u *udpTo) Write(b []byte) (int, error) {
+	return u.WriteToUDP(b, u.to)
+}
+
+func (d *dmx) run() {
+	for packets := range d.frames {
+		for i, packet := range packets {
+			if _, err := d.conns[i].Write(packet); err != nil {
+				d.fail(err)
+			}
+		}
+	}
+	for _, conn := range d.conns {
+		conn.Close()
+	}
+}
+
+func (d *dmx) fail(err error) {
+	d.mu.Lock()
+	d.err = err
+	d.mu.Unlock()
+}
+
+func (d *dmx) Present(frame Frame) error {
+	d.mu.Lock()
+	err := d.err
+	d.err = nil
+	d.mu.Unlock()
+	if frame.Image == nil || frame.Time.Sub(d.last) < d.every || len(d.frames) == cap(d.frames) {
+		return err
+	}
+	d.last = frame.Time
+
+	d.sample(frame.Image)
+	d.seq++
+	if d.seq == 0 {
+		// Art-Net reserves 0 for "no sequencing"
+		d.seq = 1
+	}
+	per := d.perUniverse()
+	packets := make([][]byte, d.universes())
+	for i := range packets {
+		data := make([]byte, dmxSlots)
+		zones := d.colors[i*per : min((i+1)*per, len(d.colors))]
+		for z, c := range zones {
+			d.fixture(data[d.start-1+z*len(d.channels):], c)
+		}
+		if d.protocol == protocolSACN {
+			packets[i] = sacnPacket(d.cid, d.universe+i, d.seq, data)
+		} else {
+			packets[i] = artNetPacket(d.universe+i, d.seq, data)
+		}
+	}
+	select {
+	case d.frames <- packets:
+	default:
+	}
+	return err
+}
+
+// fixture writes colour c in the fixture's channel layout.
+func (d *dmx) fixture(data []byte, c [3]byte) {
+	for i, ch := range []byte(d.channels) {
+		switch ch {
+		case 'r':
+			data[i] = c[0]
+		case 'g':
+			data[i] = c[1]
+		case 'b':
+			data[i] = c[2]
+		case 'w':
+			data[i] = min(c[0], c[1], c[2])
+		case 'd':
+			data[i] = 255
+		}
+	}
+}
+
+// sample works out every zone's colour.
+func (d *dmx) sample(img *image.RGBA) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	for zy := range d.rows {
+		y0, y1 := zy*h/d.rows, (zy+1)*h/d.rows
+		for zx := range d.cols {
+			x0, x1 := zx*w/d.cols, (zx+1)*w/d.cols
+			if d.dominant {
+				d.colors[zy*d.cols+zx] = dominantColor(d.bins, img, x0, y0, x1, y1)
+			} else {
+				d.colors[zy*d.cols+zx] = averageColor(img, x0, y0, x1, y1)
+			}
+		}
+	}
+}
+
+func averageColor(img *image.RGBA, x0, y0, x1, y1 int) [3]byte {
+	var r, g, b, n int
+	for y := y0; y < y1; y++ {
+		row := img.Pix[y*img.Stride:]
+		for x := x0; x < x1; x++ {
+			r += int(row[x*4])
+			g += int(row[x*4+1])
+			b += int(row[x*4+2])
+			n++
+		}
+	}
+	if n == 0 {
+		return [3]byte{}
+	}
+	return [3]byte{byte(r / n), byte(g / n), byte(b / n)}
+}
+
+// dominantColor returns the mean of the most common bright colour in the
+// zone, binned at 4 bits per channel, or black when the zone is dark. Music
+// visuals are mostly black, so an average would wash every fixture out.
+// bins is scratch space kept between calls.
+func dominantColor(bins map[uint16]*colorBin, img *image.RGBA, x0, y0, x1, y1 int) [3]byte {
+	clear(bins)
+	var best *colorBin
+	for y := y0; y < y1; y++ {
+		row := img.Pix[y*img.Stride:]
+		for x := x0; x < x1; x++ {
+			r, g, b := row[x*4], row[x*4+1], row[x*4+2]
+			if max(r, g, b) < colorBlack {
+				continue
+			}
+			key := uint16(r>>4)<<8 | uint16(g>>4)<<4 | uint16(b>>4)
+			bin := bins[key]
+			if bin == nil {
+				bin = &colorBin{}
+				bins[key] = bin
+			}
+			bin.count++
+			bin.r += int(r)
+			bin.g += int(g)
+			bin.b += int(b)
+			if best == nil || bin.count > best.count {
+				best = bin
+			}
+		}
+	}
+	if best == nil {
+		return [3]byte{}
+	}
+	return [3]byte{byte(best.r / best.count), byte(best.g / best.count), byte(best.b / best.count)}
+}
+
+// artNetPacket builds an ArtDmx packet carrying one universe.
+func artNetPacket(universe int, seq byte, data []byte) []byte {
+	p := make([]byte, 18, 18+len(data))
+	copy(p, "Art-Net\x00")
+	binary.LittleEndian.PutUint16(p[8:], 0x5000) // OpDmx
+	binary.BigEndian.PutUint16(p[10:], 14)       // protocol version
+	p[12] = seq
+	p[13] = 0 // physical port
+	binary.LittleEndian.PutUint16(p[14:], uint16(universe&0x7fff))
+	binary.BigEndian.PutUint16(p[16:], uint16(len(data)))
+	return append(p, data...)
+}
+
+// sacnPacket builds an E1.31 data packet carrying one universe at the
+// default priority.
+func sacnPacket(cid [16]byte, universe int, seq byte, data []byte) []byte {
+	n := 126 + len(data)
+	p := make([]byte, 126, n)
+	// root layer
+	binary.BigEndian.PutUint16(p[0:], 0x0010) // preamble size
+	copy(p[4:], "ASC-E1.17\x00\x00\x00")
+	binary.BigEndian.PutUint16(p[16:], 0x7000|uint16(n-16))
+	binary.BigEndian.PutUint32(p[18:], 0x00000004) // VECTOR_ROOT_E131_DATA
+	copy(p[22:], cid[:])
+	// framing layer
+	binary.BigEndian.PutUint16(p[38:], 0x7000|uint16(n-38))
+	binary.BigEndian.PutUint32(p[40:], 0x00000002) // VECTOR_E131_DATA_PACKET
+	copy(p[44:108], "golizer")
+	p[108] = 100 // priority
+	p[111] = seq
+	binary.BigEndian.PutUint16(p[113:], uint16(universe))
+	// dmp layer
+	binary.BigEndian.PutUint16(p[115:], 0x7000|uint16(n-115))
+	p[117] = 0x02                               // VECTOR_DMP_SET_PROPERTY
+	p[118] = 0xa1                               // address and data type
+	binary.BigEndian.PutUint16(p[121:], 0x0001) // address increment
+	binary.BigEndian.PutUint16(p[123:], uint16(1+len(data)))
+	p[125] = 0 // DMX start code
+	return append(p, data...)
+}
+
+// Close stops the sender; the universes keep their last values, as
+// fixtures expect.
+func (d *dmx) Close() error {
+	if d.frames != nil {
+		close(d.frames)
+	} else {
+		for _, conn := range d.conns {
+			conn.Close()
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
@@ -0,0 +1,173 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestArtNetPacket(t *testing.T) {
+	data := []byte{1, 2, 3, 4}
+	p := artNetPacket(0x8123, 7, data)
+	want := []byte{
+		'A', 'r', 't', '-', 'N', 'e', 't', 0,
+		0x00, 0x50, // OpDmx, little endian
+		0, 14, // protocol version
+		7, 0, // sequence, physical port
+		0x23, 0x01, // port-address, little endian and 15 bits
+		0, 4, // length
+		1, 2, 3, 4,
+	}
+	if !bytes.Equal(p, want) {
+		t.Errorf("got % x\nwant % x", p, want)
+	}
+}
+
+func TestSACNPacket(t *testing.T) {
+	var cid [16]byte
+	for i := range cid {
+		cid[i] = byte(0xa0 + i)
+	}
+	data := make([]byte, dmxSlots)
+	data[0], data[dmxSlots-1] = 0x11, 0x22
+	p := sacnPacket(cid, 0x1234, 9, data)
+	if len(p) != 638 {
+		t.Fatalf("length %d, want 638", len(p))
+	}
+	u16 := func(i int) uint16 { return binary.BigEndian.Uint16(p[i:]) }
+	u32 := func(i int) uint32 { return binary.BigEndian.Uint32(p[i:]) }
+	for _, tc := range []struct {
+		name      string
+		got, want uint32
+	}{
+		{"preamble", uint32(u16(0)), 0x0010},
+		{"postamble", uint32(u16(2)), 0},
+		{"root flags and length", uint32(u16(16)), 0x7000 | 622},
+		{"root vector", u32(18), 4},
+		{"framing flags and length", uint32(u16(38)), 0x7000 | 600},
+		{"framing vector", u32(40), 2},
+		{"priority", uint32(p[108]), 100},
+		{"sequence", uint32(p[111]), 9},
+		{"options", uint32(p[112]), 0},
+		{"universe", uint32(u16(113)), 0x1234},
+		{"dmp flags and length", uint32(u16(115)), 0x7000 | 523},
+		{"dmp vector", uint32(p[117]), 2},
+		{"address and data type", uint32(p[118]), 0xa1},
+		{"first address", uint32(u16(119)), 0},
+		{"address increment", uint32(u16(121)), 1},
+		{"property count", uint32(u16(123)), 513},
+		{"start code", uint32(p[125]), 0},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s: got %#x, want %#x", tc.name, tc.got, tc.want)
+		}
+	}
+	if !bytes.Equal(p[4:16], []byte("ASC-E1.17\x00\x00\x00")) {
+		t.Errorf("packet identifier %q", p[4:16])
+	}
+	if !bytes.Equal(p[22:38], cid[:]) {
+		t.Errorf("cid % x", p[22:38])
+	}
+	if name := string(bytes.TrimRight(p[44:108], "\x00")); name != "golizer" {
+		t.Errorf("source name %q", name)
+	}
+	if p[126] != 0x11 || p[637] != 0x22 {
+		t.Errorf("slots start %#x end %#x", p[126], p[637])
+	}
+}
+
+func TestDMXFixture(t *testing.T) {
+	d := &dmx{channels: "drgbwg"}
+	data := make([]byte, 6)
+	d.fixture(data, [3]byte{200, 100, 50})
+	if want := []byte{255, 200, 100, 50, 50, 100}; !bytes.Equal(data, want) {
+		t.Errorf("got %v, want %v", data, want)
+	}
+}
+
+// TestDMXPresentSplitsUniverses lays 3 zones of 200 channels from channel
+// 10: two fit in the first universe and the third starts the next one
+// rather than straddling them.
+func TestDMXPresentSplitsUniverses(t *testing.T) {
+	d := &dmx{
+		protocol: protocolArtNet,
+		universe: 5,
+		cols:     3,
+		rows:     1,
+		channels: "rgb" + string(bytes.Repeat([]byte("d"), 197)),
+		start:    10,
+		colors:   make([][3]byte, 3),
+		frames:   make(chan [][]byte, 1),
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 6, 2))
+	for x := range 6 {
+		for y := range 2 {
+			img.Set(x, y, color.RGBA{byte(10 * (x/2 + 1)), 0, 0, 255})
+		}
+	}
+	if err := d.Present(Frame{Image: img, Time: time.Unix(1, 0)}); err != nil {
+		t.Fatalf("present: %v", err)
+	}
+	packets := <-d.frames
+	if len(packets) != 2 {
+		t.Fatalf("%d universes, want 2", len(packets))
+	}
+	for i, tc := range []struct {
+		packet   int
+		universe int
+		channel  int // 1-based
+		red      byte
+	}{
+		{0, 5, 10, 10},
+		{0, 5, 210, 20},
+		{1, 6, 10, 30},
+	} {
+		p := packets[tc.packet]
+		if u := int(binary.LittleEndian.Uint16(p[14:])); u != tc.universe {
+			t.Errorf("zone %d: universe %d, want %d", i, u, tc.universe)
+		}
+		if got := p[18+tc.channel-1]; got != tc.red {
+			t.Errorf("zone %d: channel %d is %d, want %d", i, tc.channel, got, tc.red)
+		}
+	}
+	if p := packets[1]; p[18+210-1] != 0 || p[12] != 1 {
+		t.Errorf("second universe: channel 210 %d, sequence %d", p[18+210-1], p[12])
+	}
+
+	// frames closer together than fps allows are skipped
+	d.every = time.Second
+	if d.Present(Frame{Image: img, Time: time.Unix(1, 0).Add(time.Millisecond)}); len(d.frames) != 0 {
+		t.Error("sent a frame inside the fps limit")
+	}
+}
+
+func TestZoneColors(t *testing.T) {
+	full := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	// a dark zone with a few bright red pixels and one green one
+	for x := range 8 {
+		for y := range 4 {
+			full.Set(x, y, color.RGBA{4, 4, 4, 255})
+		}
+	}
+	full.Set(2, 1, color.RGBA{240, 0, 0, 255})
+	full.Set(3, 1, color.RGBA{250, 0, 0, 255})
+	full.Set(2, 2, color.RGBA{0, 200, 0, 255})
+	// a sub-image, so rows are shorter than the stride
+	img := full.SubImage(image.Rect(2, 1, 4, 3)).(*image.RGBA)
+
+	if got, want := averageColor(img, 0, 0, 2, 2), [3]byte{123, 51, 1}; got != want {
+		t.Errorf("average %v, want %v", got, want)
+	}
+	if got, want := dominantColor(map[uint16]*colorBin{}, img, 0, 0, 2, 2), [3]byte{245, 0, 0}; got != want {
+		t.Errorf("dominant %v, want %v", got, want)
+	}
+	if got := dominantColor(map[uint16]*colorBin{}, full, 4, 0, 8, 4); got != [3]byte{} {
+		t.Errorf("dark zone %v, want black", got)
+	}
+	if got := averageColor(img, 1, 1, 1, 1); got != [3]byte{} {
+		t.Errorf("empty zone %v, want black", got)
+	}
+}
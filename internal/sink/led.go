@@ -0,0 +1,287 @@
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+func init() {
+	Register("led", func() OutputSink { return &led{} })
+}
+
+const (
+	chipWS2812 = "ws2812"
+	chipAPA102 = "apa102"
+
+	// ws2812SPIHz clocks three SPI bits per LED bit, 1.25µs each.
+	ws2812SPIHz = 2_400_000
+	// ws2812Reset is the low time (over 280µs at 2.4MHz) that latches a
+	// WS2812B strip.
+	ws2812Reset = 90
+)
+
+// ws2812Bits spreads every byte over 3 SPI bytes: a 1 is sent as 110 and a
+// 0 as 100, which at 2.4MHz gives the strip's high and low pulse widths.
+var ws2812Bits = func() (t [256][3]byte) {
+	for v := range 256 {
+		var bits uint32
+		for i := 7; i >= 0; i-- {
+			bits <<= 3
+			if v>>i&1 == 1 {
+				bits |= 0b110
+			} else {
+				bits |= 0b100
+			}
+		}
+		t[v] = [3]byte{byte(bits >> 16), byte(bits >> 8), byte(bits)}
+	}
+	return t
+}()
+
+// LEDLayout describes how the pixels are wired, read from the layout file.
+// A strip sets Length and shows the frame's columns left to right; a matrix
+// sets Width and Height and shows the frame scaled down to it.
+type LEDLayout struct {
+	Length int `json:"length,omitempty"`
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Serpentine matrices run every other row backwards, the usual zig-zag
+	// of panels made from one strip.
+	Serpentine bool `json:"serpentine,omitempty"`
+	// Origin is the corner the first pixel sits in: top-left (the default),
+	// top-right, bottom-left or bottom-right.
+	Origin string `json:"origin,omitempty"`
+	// Brightness scales every pixel, 0-1 (default 1); a dense matrix at full
+	// white draws more than most Pi supplies give.
+	Brightness *float64 `json:"brightness,omitempty"`
+	// Order is the colour order on the wire, grb for WS2812 and bgr for
+	// APA102 unless set.
+	Order string `json:"order,omitempty"`
+}
+
+// LoadLEDLayout reads and checks a layout file.
+func LoadLEDLayout(path string) (LEDLayout, error) {
+	var l LEDLayout
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return l, err
+	}
+	if err := json.Unmarshal(data, &l); err != nil {
+		return l, fmt.Errorf("layout %s: %w", path, err)
+	}
+	if l.Length > 0 {
+		if l.Width > 0 || l.Height > 0 {
+			return l, fmt.Errorf("layout %s: set length for a strip or width and height for a matrix, not both", path)
+		}
+		l.Width, l.Height = l.Length, 1
+	}
+	if l.Width <= 0 || l.Height <= 0 {
+		return l, fmt.Errorf("layout %s: needs a length or a width and height", path)
+	}
+	switch l.Origin {
+	case "", "top-left", "top-right", "bottom-left", "bottom-right":
+	default:
+		return l, fmt.Errorf("layout %s: bad origin %q", path, l.Origin)
+	}
+	if l.Brightness != nil && (*l.Brightness < 0 || *l.Brightness > 1) {
+		return l, fmt.Errorf("layout %s: brightness must be 0-1", path)
+	}
+	if l.Order != "" && (len(l.Order) != 3 || strings.Trim(l.Order, "rgb") != "" ||
+		!strings.Contains(l.Order, "r") || !strings.Contains(l.Order, "g") || !strings.Contains(l.Order, "b")) {
+		return l, fmt.Errorf("layout %s: bad order %q", path, l.Order)
+	}
+	return l, nil
+}
+
+// index returns the wire position of the pixel at column x, row y.
+func (l LEDLayout) index(x, y int) int {
+	if strings.HasPrefix(l.Origin, "bottom") {
+		y = l.Height - 1 - y
+	}
+	if strings.HasSuffix(l.Origin, "right") {
+		x = l.Width - 1 - x
+	}
+	if l.Serpentine && y%2 == 1 {
+		x = l.Width - 1 - x
+	}
+	return y*l.Width + x
+}
+
+// led drives a WS2812 or APA102 strip or matrix from the Pi's SPI port
+// (MOSI, plus SCLK for APA102). Options:
+//
+//   - chip: ws2812 (the default) or apa102
+//   - device: the spidev node (default /dev/spidev0.0)
+//   - layout: the layout file, see LEDLayout
+//   - speed: the APA102 clock in Hz (default 8000000); WS2812 timing fixes
+//     it at 2.4MHz
+//   - fps: the most frames sent per second (default 60)
+type led struct {
+	chip   string
+	layout LEDLayout
+	order  [3]int // which of r, g, b goes out first, second and third
+	scale  int    // brightness, 0-256
+	every  time.Duration
+	dev    *os.File
+	last   time.Time
+	frames chan []byte
+
+	mu  sync.Mutex
+	err error
+}
+
+func (l *led) Init(cfg Config) error {
+	l.chip = strings.ToLower(cfg.Option("chip", chipWS2812))
+	speed := ws2812SPIHz
+	switch l.chip {
+	case chipWS2812:
+	case chipAPA102:
+		var err error
+		if speed, err = strconv.Atoi(cfg.Option("speed", "8000000")); err != nil || speed <= 0 {
+			return fmt.Errorf("bad speed %q", cfg.Option("speed", ""))
+		}
+	default:
+		return fmt.Errorf("unknown chip %q (ws2812 or apa102)", l.chip)
+	}
+	path := cfg.Option("layout", "")
+	if path == "" {
+		return errors.New("needs a layout file")
+	}
+	var err error
+	if l.layout, err = LoadLEDLayout(path); err != nil {
+		return err
+	}
+	order := l.layout.Order
+	if order == "" {
+		order = "grb"
+		if l.chip == chipAPA102 {
+			order = "bgr"
+		}
+	}
+	for i, c := range order {
+		l.order[i] = strings.IndexRune("rgb", c)
+	}
+	l.scale = 256
+	if l.layout.Brightness != nil {
+		l.scale = int(*l.layout.Brightness * 256)
+	}
+	fps, err := strconv.ParseFloat(cfg.Option("fps", "60"), 64)
+	if err != nil || fps <= 0 {
+		return fmt.Errorf("bad fps %q", cfg.Option("fps", ""))
+	}
+	l.every = time.Duration(float64(time.Second) / fps)
+
+	device := cfg.Option("device", "/dev/spidev0.0")
+	if l.dev, err = os.OpenFile(device, os.O_WRONLY, 0); err != nil {
+		return err
+	}
+	if err := setSPISpeed(l.dev, speed); err != nil {
+		l.dev.Close()
+		return fmt.Errorf("%s: %w", device, err)
+	}
+	l.frames = make(chan []byte, 1)
+	go l.run()
+	return nil
+}
+
+func (l *led) run() {
+	for buf := range l.frames {
+		if _, err := l.dev.Write(buf); err != nil {
+			if errors.Is(err, syscall.EMSGSIZE) {
+				err = fmt.Errorf("%w: %d bytes is over spidev's buffer, raise spidev.bufsiz", err, len(buf))
+			}
+			l.mu.Lock()
+			l.err = err
+			l.mu.Unlock()
+		}
+	}
+	l.dev.Close()
+}
+
+func (l *led) Present(frame Frame) error {
+	l.mu.Lock()
+	err := l.err
+	l.err = nil
+	l.mu.Unlock()
+	if frame.Image == nil || frame.Time.Sub(l.last) < l.every || len(l.frames) == cap(l.frames) {
+		return err
+	}
+	l.last = frame.Time
+
+	pixels := l.sample(frame.Image)
+	var buf []byte
+	if l.chip == chipAPA102 {
+		buf = l.apa102(pixels)
+	} else {
+		buf = l.ws2812(pixels)
+	}
+	select {
+	case l.frames <- buf:
+	default:
+	}
+	return err
+}
+
+// sample scales the frame down to the layout, averaging the pixels under
+// every LED, and returns the colours in wire order.
+func (l *led) sample(img *image.RGBA) [][3]byte {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	lw, lh := l.layout.Width, l.layout.Height
+	pixels := make([][3]byte, lw*lh)
+	for y := range lh {
+		y0, y1 := y*h/lh, max((y+1)*h/lh, y*h/lh+1)
+		for x := range lw {
+			x0, x1 := x*w/lw, max((x+1)*w/lw, x*w/lw+1)
+			c := averageColor(img, x0, y0, min(x1, w), min(y1, h))
+			for i := range c {
+				c[i] = byte(int(c[i]) * l.scale >> 8)
+			}
+			pixels[l.layout.index(x, y)] = c
+		}
+	}
+	return pixels
+}
+
+// ws2812 encodes the pixels as SPI bits followed by the latch.
+func (l *led) ws2812(pixels [][3]byte) []byte {
+	buf := make([]byte, 0, len(pixels)*9+ws2812Reset)
+	for _, c := range pixels {
+		for _, i := range l.order {
+			buf = append(buf, ws2812Bits[c[i]][:]...)
+		}
+	}
+	return append(buf, make([]byte, ws2812Reset)...)
+}
+
+// apa102 frames the pixels with a zero start frame and enough trailing
+// clock edges to push the data through the last LED.
+func (l *led) apa102(pixels [][3]byte) []byte {
+	end := max(4, (len(pixels)+15)/16)
+	buf := make([]byte, 4, 4+len(pixels)*4+end)
+	for _, c := range pixels {
+		// full global brightness; the layout's brightness scales the colour
+		buf = append(buf, 0xff, c[l.order[0]], c[l.order[1]], c[l.order[2]])
+	}
+	for range end {
+		buf = append(buf, 0xff)
+	}
+	return buf
+}
+
+// Close stops the sender. The LEDs keep their last colours.
+func (l *led) Close() error {
+	if l.frames != nil {
+		close(l.frames)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
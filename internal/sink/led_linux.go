@@ -0,0 +1,15 @@
+package sink
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// spiIocWrMaxSpeedHz is SPI_IOC_WR_MAX_SPEED_HZ from linux/spi/spidev.h.
+const spiIocWrMaxSpeedHz = 0x40046b04
+
+// setSPISpeed sets the clock every write to the spidev node runs at.
+func setSPISpeed(dev *os.File, hz int) error {
+	return unix.IoctlSetPointerInt(int(dev.Fd()), spiIocWrMaxSpeedHz, hz)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package sink
+
+import (
+	"errors"
+	"os"
+)
+
+// setSPISpeed fails off linux, which is the only place spidev exists.
+func setSPISpeed(*os.File, int) error {
+	return errors.New("spi needs linux")
+}
@@ -0,0 +1,145 @@
+package sink
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWS2812Bits(t *testing.T) {
+	for _, tc := range []struct {
+		v    byte
+		want [3]byte
+	}{
+		// 100 100 100 100 100 100 100 100
+		{0x00, [3]byte{0b10010010, 0b01001001, 0b00100100}},
+		// 110 110 110 110 110 110 110 110
+		{0xff, [3]byte{0b11011011, 0b01101101, 0b10110110}},
+		// 110 100 100 100 100 100 100 110
+		{0x81, [3]byte{0b11010010, 0b01001001, 0b00100110}},
+	} {
+		if got := ws2812Bits[tc.v]; got != tc.want {
+			t.Errorf("%#02x: got %08b, want %08b", tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestLEDFraming(t *testing.T) {
+	pixels := [][3]byte{{1, 2, 3}, {0xff, 0, 0x81}}
+
+	ws := &led{order: [3]int{1, 0, 2}} // grb
+	buf := ws.ws2812(pixels)
+	if len(buf) != 2*9+ws2812Reset {
+		t.Fatalf("ws2812: %d bytes, want %d", len(buf), 2*9+ws2812Reset)
+	}
+	var want []byte
+	for _, v := range []byte{2, 1, 3, 0, 0xff, 0x81} {
+		want = append(want, ws2812Bits[v][:]...)
+	}
+	if !bytes.Equal(buf[:18], want) {
+		t.Errorf("ws2812 bits % x, want % x", buf[:18], want)
+	}
+	if !bytes.Equal(buf[18:], make([]byte, ws2812Reset)) {
+		t.Error("ws2812 latch is not all low")
+	}
+
+	apa := &led{order: [3]int{2, 1, 0}} // bgr
+	want = []byte{
+		0, 0, 0, 0, // start frame
+		0xff, 3, 2, 1,
+		0xff, 0x81, 0, 0xff,
+		0xff, 0xff, 0xff, 0xff, // end frame
+	}
+	if got := apa.apa102(pixels); !bytes.Equal(got, want) {
+		t.Errorf("apa102 % x, want % x", got, want)
+	}
+	// the end frame needs half a clock edge per LED
+	if n := len(apa.apa102(make([][3]byte, 100))); n != 4+400+7 {
+		t.Errorf("apa102 100 LEDs: %d bytes, want %d", n, 4+400+7)
+	}
+}
+
+func TestLEDLayoutIndex(t *testing.T) {
+	for _, tc := range []struct {
+		layout LEDLayout
+		want   []int // wire position of every pixel, row by row
+	}{
+		{LEDLayout{Width: 3, Height: 2}, []int{0, 1, 2, 3, 4, 5}},
+		{LEDLayout{Width: 3, Height: 2, Serpentine: true}, []int{0, 1, 2, 5, 4, 3}},
+		{LEDLayout{Width: 3, Height: 2, Origin: "top-right"}, []int{2, 1, 0, 5, 4, 3}},
+		{LEDLayout{Width: 3, Height: 2, Origin: "bottom-left"}, []int{3, 4, 5, 0, 1, 2}},
+		{LEDLayout{Width: 3, Height: 2, Origin: "bottom-right", Serpentine: true}, []int{3, 4, 5, 2, 1, 0}},
+	} {
+		var got []int
+		for y := range tc.layout.Height {
+			for x := range tc.layout.Width {
+				got = append(got, tc.layout.index(x, y))
+			}
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%+v: got %v, want %v", tc.layout, got, tc.want)
+		}
+	}
+}
+
+func TestLEDSample(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := range 4 {
+		for y := range 2 {
+			img.Set(x, y, color.RGBA{byte(100 * (x / 2)), byte(200 * y), 0, 255})
+		}
+	}
+	l := &led{layout: LEDLayout{Width: 2, Height: 2, Serpentine: true}, scale: 128}
+	want := [][3]byte{{0, 0, 0}, {50, 0, 0}, {50, 100, 0}, {0, 100, 0}}
+	got := l.sample(img)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("LED %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	// a strip longer than the frame is wide repeats columns instead of
+	// leaving LEDs dark
+	l = &led{layout: LEDLayout{Width: 8, Height: 1}, scale: 256}
+	got = l.sample(img)
+	if got[7] != [3]byte{100, 100, 0} {
+		t.Errorf("last LED of a long strip: %v", got[7])
+	}
+}
+
+func TestLoadLEDLayout(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		json string
+		want string // error, or "" to load
+	}{
+		{`{"length": 30}`, ""},
+		{`{"width": 16, "height": 16, "serpentine": true, "origin": "bottom-right", "brightness": 0.5, "order": "rbg"}`, ""},
+		{`{"length": 30, "width": 4}`, "not both"},
+		{`{"width": 4}`, "needs a length or a width and height"},
+		{`{"length": 3, "origin": "middle"}`, `bad origin "middle"`},
+		{`{"length": 3, "brightness": 1.5}`, "brightness must be 0-1"},
+		{`{"length": 3, "order": "rrb"}`, `bad order "rrb"`},
+		{`{"length": 3, "order": "rgbw"}`, `bad order "rgbw"`},
+		{`{"length": "3"}`, "cannot unmarshal"},
+	} {
+		path := filepath.Join(dir, "layout.json")
+		if err := os.WriteFile(path, []byte(tc.json), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		l, err := LoadLEDLayout(path)
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("%s: %v", tc.json, err)
+		case tc.want == "" && l.Width*l.Height == 0:
+			t.Errorf("%s: loaded as %+v", tc.json, l)
+		case tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)):
+			t.Errorf("%s: got %v, want %q", tc.json, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register("hue", func() OutputSink { return &hue{} })
+	Register("homeassistant", func() OutputSink { return &homeAssistant{} })
+}
+
+// lightTimeout bounds one request to a bridge or Home Assistant.
+const lightTimeout = 2 * time.Second
+
+// lightUpdate is one change of a light group: the frame's dominant colour,
+// shown at full brightness on a beat flash and faded to over fade otherwise.
+type lightUpdate struct {
+	color [3]byte
+	flash bool
+	fade  time.Duration
+}
+
+// lightSync turns frames into rate-limited light updates for smart bulbs,
+// which take a handful of commands a second rather than a frame stream.
+// Updates go out at most every 1/rate seconds, flashing when a beat landed
+// since the last one. Common options:
+//
+//   - rate: the most updates sent per second
+//   - flash: whether beats flash the lights (default true)
+type lightSync struct {
+	every  time.Duration
+	flash  bool
+	send   func(lightUpdate) error
+	client http.Client
+
+	last    time.Time
+	beat    bool
+	bins    map[uint16]*colorBin
+	updates chan lightUpdate
+
+	mu  sync.Mutex
+	err error
+}
+
+func (s *lightSync) start(cfg Config, defaultRate float64, send func(lightUpdate) error) error {
+	rate, err := strconv.ParseFloat(cfg.Option("rate", strconv.FormatFloat(defaultRate, 'f', -1, 64)), 64)
+	if err != nil || rate <= 0 {
+		return fmt.Errorf("bad rate %q", cfg.Option("rate", ""))
+	}
+	if s.flash, err = strconv.ParseBool(cfg.Option("flash", "true")); err != nil {
+		return fmt.Errorf("bad flash %q", cfg.Option("flash", ""))
+	}
+	s.every = time.Duration(float64(time.Second) / rate)
+	s.send = send
+	s.client.Timeout = lightTimeout
+	s.bins = map[uint16]*colorBin{}
+	s.updates = make(chan lightUpdate, 1)
+	go s.run()
+	return nil
+}
+
+func (s *lightSync) run() {
+	for u := range s.updates {
+		if err := s.send(u); err != nil {
+			s.mu.Lock()
+			s.err = err
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *lightSync) Present(frame Frame) error {
+	s.mu.Lock()
+	err := s.err
+	s.err = nil
+	s.mu.Unlock()
+	s.beat = s.beat || (s.flash && frame.Features.Onset)
+	if frame.Image == nil || frame.Time.Sub(s.last) < s.every || len(s.updates) == cap(s.updates) {
+		return err
+	}
+	s.last = frame.Time
+
+	r := frame.Image.Rect
+	u := lightUpdate{
+		color: dominantColor(s.bins, frame.Image, 0, 0, r.Dx(), r.Dy()),
+		flash: s.beat,
+		fade:  s.every,
+	}
+	s.beat = false
+	select {
+	case s.updates <- u:
+	default:
+	}
+	return err
+}
+
+// Close stops the sender; the lights keep their last colour.
+func (s *lightSync) Close() error {
+	if s.updates != nil {
+		close(s.updates)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// do sends a JSON request and fails on a non-2xx answer. The response body
+// is returned for APIs that report errors inside a 200.
+func (s *lightSync) do(method, url string, header http.Header, body any) ([]byte, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	reply, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+	return reply, nil
+}
+
+// level is the brightness of c, its largest channel, from 0 to 1.
+func level(c [3]byte) float64 {
+	return float64(max(c[0], c[1], c[2])) / 255
+}
+
+// hue drives a Philips Hue light, room or entertainment area through the
+// bridge's local REST API. Entertainment streaming needs DTLS, which the
+// standard library doesn't speak, so areas are driven like any group:
+// smoothly, but at the bridge's rate limit. Options:
+//
+//   - bridge: the bridge address
+//   - user: an authorised bridge username (app key)
+//   - group or light: the id to drive
+//   - rate: default 1 for a group and 10 for a light, the bridge's limits
+type hue struct {
+	lightSync
+	url string
+}
+
+func (h *hue) Init(cfg Config) error {
+	bridge, user := cfg.Option("bridge", ""), cfg.Option("user", "")
+	if bridge == "" || user == "" {
+		return errors.New("needs a bridge and a user")
+	}
+	group, light := cfg.Option("group", ""), cfg.Option("light", "")
+	base := "http://" + bridge + "/api/" + user
+	rate := 1.0
+	switch {
+	case group != "" && light != "":
+		return errors.New("set a group or a light, not both")
+	case group != "":
+		h.url = base + "/groups/" + group + "/action"
+	case light != "":
+		h.url = base + "/lights/" + light + "/state"
+		rate = 10
+	default:
+		return errors.New("needs a group or a light")
+	}
+	return h.start(cfg, rate, h.update)
+}
+
+func (h *hue) update(u lightUpdate) error {
+	x, y := hueXY(u.color)
+	body := map[string]any{
+		"on": true,
+		"xy": [2]float64{x, y},
+		// bri 0 is not off but the dimmest step; keep the lights on so
+		// dark passages don't wait for bulbs to power up
+		"bri":            max(1, int(math.Round(level(u.color)*254))),
+		"transitiontime": int(u.fade / (100 * time.Millisecond)),
+	}
+	if u.flash {
+		body["bri"], body["transitiontime"] = 254, 0
+	}
+	reply, err := h.do(http.MethodPut, h.url, http.Header{}, body)
+	if err != nil {
+		return err
+	}
+	// the bridge answers 200 with a list of successes and errors
+	var results []struct {
+		Error *struct {
+			Description string `json:"description"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(reply, &results) == nil {
+		for _, r := range results {
+			if r.Error != nil {
+				return errors.New(r.Error.Description)
+			}
+		}
+	}
+	return nil
+}
+
+// hueXY converts an sRGB colour to the CIE xy the bridge takes, through
+// Philips' wide gamut conversion.
+func hueXY(c [3]byte) (float64, float64) {
+	lin := func(v byte) float64 {
+		f := float64(v) / 255
+		if f > 0.04045 {
+			return math.Pow((f+0.055)/1.055, 2.4)
+		}
+		return f / 12.92
+	}
+	r, g, b := lin(c[0]), lin(c[1]), lin(c[2])
+	X := r*0.664511 + g*0.154324 + b*0.162028
+	Y := r*0.283881 + g*0.668433 + b*0.047685
+	Z := r*0.000088 + g*0.072310 + b*0.986039
+	sum := X + Y + Z
+	if sum == 0 {
+		// black has no hue; D65 white keeps the dimmed light neutral
+		return 0.3127, 0.3290
+	}
+	return math.Round(X/sum*10000) / 10000, math.Round(Y/sum*10000) / 10000
+}
+
+// homeAssistant drives light entities through Home Assistant's REST API,
+// whatever brand they are. Options:
+//
+//   - url: Home Assistant's address (default http://homeassistant.local:8123)
+//   - token: a long-lived access token
+//   - entities: comma separated light entity ids
+//   - rate: default 4
+type homeAssistant struct {
+	lightSync
+	url      string
+	header   http.Header
+	entities []string
+}
+
+func (h *homeAssistant) Init(cfg Config) error {
+	token := cfg.Option("token", "")
+	if token == "" {
+		return errors.New("needs a token")
+	}
+	for _, e := range strings.Split(cfg.Option("entities", ""), ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			h.entities = append(h.entities, e)
+		}
+	}
+	if len(h.entities) == 0 {
+		return errors.New("needs entities")
+	}
+	h.url = strings.TrimSuffix(cfg.Option("url", "http://homeassistant.local:8123"), "/") + "/api/services/light/turn_on"
+	h.header = http.Header{"Authorization": {"Bearer " + token}}
+	return h.start(cfg, 4, h.update)
+}
+
+func (h *homeAssistant) update(u lightUpdate) error {
+	// the colour goes at full value and its brightness separately, so dark
+	// frames dim the light instead of muddying the colour
+	rgb := [3]int{255, 255, 255}
+	if peak := max(u.color[0], u.color[1], u.color[2]); peak > 0 {
+		for i, v := range u.color {
+			rgb[i] = int(v) * 255 / int(peak)
+		}
+	}
+	body := map[string]any{
+		"entity_id":  h.entities,
+		"rgb_color":  rgb,
+		"brightness": max(1, int(math.Round(level(u.color)*255))),
+		"transition": u.fade.Seconds(),
+	}
+	if u.flash {
+		body["brightness"], body["transition"] = 255, 0
+	}
+	_, err := h.do(http.MethodPost, h.url, h.header.Clone(), body)
+	return err
+}
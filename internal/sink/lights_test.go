@@ -0,0 +1,169 @@
+package sink
+
+import (
+	"encoding/json"
+	"image"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestHueXY(t *testing.T) {
+	for _, tc := range []struct {
+		c    [3]byte
+		x, y float64
+	}{
+		{[3]byte{0, 0, 0}, 0.3127, 0.3290},
+		{[3]byte{255, 0, 0}, 0.7006, 0.2993},
+		{[3]byte{0, 255, 0}, 0.1724, 0.7468},
+		{[3]byte{0, 0, 255}, 0.1355, 0.0399},
+	} {
+		if x, y := hueXY(tc.c); x != tc.x || y != tc.y {
+			t.Errorf("%v: got %v, %v, want %v, %v", tc.c, x, y, tc.x, tc.y)
+		}
+	}
+}
+
+// TestLightSyncFlash checks a beat between two updates isn't lost to the
+// rate limit: the next update flashes.
+func TestLightSyncFlash(t *testing.T) {
+	s := &lightSync{
+		every:   time.Second,
+		flash:   true,
+		bins:    map[uint16]*colorBin{},
+		updates: make(chan lightUpdate, 1),
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+	start := time.Unix(100, 0)
+	s.Present(Frame{Image: img, Time: start})
+	if u := <-s.updates; u.flash || u.color != [3]byte{200, 200, 200} || u.fade != time.Second {
+		t.Errorf("first update %+v", u)
+	}
+	s.Present(Frame{Image: img, Time: start.Add(time.Second / 2), Features: analyzer.Features{Onset: true}})
+	if len(s.updates) != 0 {
+		t.Fatal("update inside the rate limit")
+	}
+	s.Present(Frame{Image: img, Time: start.Add(time.Second)})
+	if u := <-s.updates; !u.flash {
+		t.Error("beat between updates didn't flash")
+	}
+	s.Present(Frame{Image: img, Time: start.Add(2 * time.Second)})
+	if u := <-s.updates; u.flash {
+		t.Error("flash carried over to the next update")
+	}
+}
+
+func TestHueUpdate(t *testing.T) {
+	var body map[string]any
+	var path string
+	reply := `[{"success": {}}]`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.Method + " " + r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		body = nil
+		json.Unmarshal(data, &body)
+		io.WriteString(w, reply)
+	}))
+	defer srv.Close()
+
+	h := &hue{url: srv.URL + "/api/key/groups/3/action"}
+	if err := h.update(lightUpdate{color: [3]byte{0, 0, 0}, fade: 500 * time.Millisecond}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if path != "PUT /api/key/groups/3/action" {
+		t.Errorf("sent %s", path)
+	}
+	// black dims to the lowest step instead of turning the lights off
+	if body["on"] != true || body["bri"] != 1.0 || body["transitiontime"] != 5.0 {
+		t.Errorf("dark update sent %v", body)
+	}
+	h.update(lightUpdate{color: [3]byte{10, 20, 30}, flash: true, fade: time.Second})
+	if body["bri"] != 254.0 || body["transitiontime"] != 0.0 {
+		t.Errorf("flash sent %v", body)
+	}
+
+	reply = `[{"error": {"description": "resource, /groups/3, not available"}}]`
+	if err := h.update(lightUpdate{}); err == nil || !strings.Contains(err.Error(), "not available") {
+		t.Errorf("bridge error in a 200: got %v", err)
+	}
+}
+
+func TestHomeAssistantUpdate(t *testing.T) {
+	var body map[string]any
+	var auth string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		body = nil
+		json.Unmarshal(data, &body)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	h := &homeAssistant{}
+	if err := h.Init(Config{Options: map[string]string{"url": srv.URL + "/", "token": "secret", "entities": " light.a, ,light.b"}}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	defer h.Close()
+	if h.url != srv.URL+"/api/services/light/turn_on" {
+		t.Errorf("url %q", h.url)
+	}
+	if err := h.update(lightUpdate{color: [3]byte{100, 50, 0}, fade: 250 * time.Millisecond}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("authorization %q", auth)
+	}
+	got, _ := json.Marshal(body)
+	// the colour goes out at full value, its level as the brightness
+	want := `{"brightness":100,"entity_id":["light.a","light.b"],"rgb_color":[255,127,0],"transition":0.25}`
+	if string(got) != want {
+		t.Errorf("sent %s, want %s", got, want)
+	}
+
+	status = http.StatusUnauthorized
+	if err := h.update(lightUpdate{}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("401: got %v", err)
+	}
+}
+
+func TestHueInit(t *testing.T) {
+	cases := []struct {
+		options map[string]string
+		url     string // empty when Init fails
+	}{
+		{map[string]string{"bridge": "10.0.0.2", "user": "key", "group": "3"}, "http://10.0.0.2/api/key/groups/3/action"},
+		{map[string]string{"bridge": "10.0.0.2", "user": "key", "light": "7"}, "http://10.0.0.2/api/key/lights/7/state"},
+		{map[string]string{"bridge": "10.0.0.2", "user": "key", "group": "3", "light": "7"}, ""},
+		{map[string]string{"bridge": "10.0.0.2", "user": "key"}, ""},
+		{map[string]string{"bridge": "10.0.0.2", "group": "3"}, ""},
+	}
+	for _, c := range cases {
+		h := &hue{}
+		err := h.Init(Config{Options: c.options})
+		if c.url == "" {
+			if err == nil {
+				h.Close()
+				t.Errorf("%v: Init succeeded", c.options)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", c.options, err)
+			continue
+		}
+		if h.url != c.url {
+			t.Errorf("%v: url %q, want %q", c.options, h.url, c.url)
+		}
+		h.Close()
+	}
+}
@@ -0,0 +1,93 @@
+package sink
+
+import (
+	"errors"
+	"os"
+	"sync"
+)
+
+func init() {
+	Register("rgbpipe", func() OutputSink { return &rgbPipe{} })
+}
+
+// rgbPipe writes raw rgb24 frames to a file or FIFO, e.g. for
+//
+//	ffmpeg -f rawvideo -pix_fmt rgb24 -s 120x39 -i /tmp/golizer.rgb ...
+//
+// Writes happen on their own goroutine; frames are dropped while the reader
+// is behind. Options: path (required).
+type rgbPipe struct {
+	frames chan []byte
+
+	mu  sync.Mutex
+	err error
+}
+
+func (p *rgbPipe) Init(cfg Config) error {
+	path := cfg.Option("path", "")
+	if path == "" {
+		return errors.New("missing option path")
+	}
+	p.frames = make(chan []byte, 1)
+	go p.run(path)
+	return nil
+}
+
+func (p *rgbPipe) run(path string) {
+	// opening a FIFO blocks until a reader shows up
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		p.fail(err)
+		for range p.frames {
+		}
+		return
+	}
+	defer f.Close()
+	for data := range p.frames {
+		if _, err := f.Write(data); err != nil {
+			p.fail(err)
+		}
+	}
+}
+
+func (p *rgbPipe) fail(err error) {
+	p.mu.Lock()
+	p.err = err
+	p.mu.Unlock()
+}
+
+func (p *rgbPipe) Present(frame Frame) error {
+	p.mu.Lock()
+	err := p.err
+	p.mu.Unlock()
+	if err != nil || frame.Image == nil {
+		return err
+	}
+	if len(p.frames) == cap(p.frames) {
+		return nil
+	}
+
+	img := frame.Image
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	data := make([]byte, 0, w*h*3)
+	for y := 0; y < h; y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+w*4]
+		for x := 0; x < len(row); x += 4 {
+			data = append(data, row[x], row[x+1], row[x+2])
+		}
+	}
+	select {
+	case p.frames <- data:
+	default:
+	}
+	return nil
+}
+
+// Close stops the writer without waiting for it: a FIFO nobody opened
+// would otherwise block shutdown.
+func (p *rgbPipe) Close() error {
+	close(p.frames)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
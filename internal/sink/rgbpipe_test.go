@@ -0,0 +1,38 @@
+package sink
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+// TestRGBPipeStride hands Present a sub-image, whose rows are shorter than
+// its stride, and checks only the visible pixels go out.
+func TestRGBPipeStride(t *testing.T) {
+	full := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	for i := range full.Pix {
+		full.Pix[i] = byte(i)
+	}
+	img := full.SubImage(image.Rect(1, 1, 3, 3)).(*image.RGBA)
+
+	p := &rgbPipe{frames: make(chan []byte, 1)}
+	if err := p.Present(Frame{Image: img}); err != nil {
+		t.Fatalf("present: %v", err)
+	}
+	var want []byte
+	for y := 1; y < 3; y++ {
+		for x := 1; x < 3; x++ {
+			i := full.PixOffset(x, y)
+			want = append(want, full.Pix[i], full.Pix[i+1], full.Pix[i+2])
+		}
+	}
+	if got := <-p.frames; !bytes.Equal(got, want) {
+		t.Errorf("wrote % x, want % x", got, want)
+	}
+
+	// a reader that is behind drops frames rather than blocking
+	p.frames <- nil
+	if err := p.Present(Frame{Image: img}); err != nil || len(p.frames) != 1 {
+		t.Errorf("full pipe: %v, %d queued", err, len(p.frames))
+	}
+}
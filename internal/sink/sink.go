@@ -0,0 +1,162 @@
+// Package sink lets extra outputs (LED drivers, video walls, recorders)
+// receive every rendered frame without touching the renderer. A sink
+// registers a factory under a name from an init function, the same way
+// database/sql drivers do, and is enabled by listing it in an output profile
+// of the saved config:
+//
+//	"outputProfiles": {
+//	  "default": [{"name": "rgbpipe", "options": {"path": "/tmp/golizer.rgb"}}]
+//	}
+//
+// Out-of-tree sinks only need a blank import in cmd/visualizer.
+package sink
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+// Frame is one rendered frame. Image and Lines are reused by the renderer,
+// so sinks must copy anything they keep after Present returns.
+type Frame struct {
+	// Image holds the frame's colours at render resolution.
+	Image *image.RGBA
+	// Lines holds the terminal rows (ASCII backend only, with ANSI colour
+	// codes when colour is enabled).
+	Lines    []string
+	Features analyzer.Features
+	Time     time.Time
+}
+
+// OutputSink receives rendered frames. Present runs on the render loop and
+// should hand slow work (network, serial) to its own goroutine.
+type OutputSink interface {
+	Init(cfg Config) error
+	Present(frame Frame) error
+	Close() error
+}
+
+// Config enables one sink inside an output profile.
+type Config struct {
+	Name    string            `json:"name"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// Option returns the named option or fallback when it is unset.
+func (c Config) Option(key, fallback string) string {
+	if v, ok := c.Options[key]; ok && v != "" {
+		return v
+	}
+	return fallback
+}
+
+// Factory creates an unopened sink.
+type Factory func() OutputSink
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes a sink available by name. It panics when the name is taken
+// or factory is nil, since both are programming errors.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if factory == nil {
+		panic("sink: Register factory is nil for " + name)
+	}
+	if _, dup := registry[name]; dup {
+		panic("sink: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// Names lists the registered sinks.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open creates and initialises the sink described by cfg.
+func Open(cfg Config) (OutputSink, error) {
+	registryMu.RLock()
+	factory, ok := registry[cfg.Name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown sink %q (have %v)", cfg.Name, Names())
+	}
+	s := factory()
+	if err := s.Init(cfg); err != nil {
+		return nil, fmt.Errorf("sink %s: %w", cfg.Name, err)
+	}
+	return s, nil
+}
+
+// Set fans frames out to every sink of a profile.
+type Set struct {
+	sinks   []OutputSink
+	names   []string
+	log     *log.Logger
+	lastErr time.Time
+}
+
+// OpenAll opens every configured sink. It returns nil when cfgs is empty;
+// a nil *Set is valid and does nothing.
+func OpenAll(cfgs []Config, logger *log.Logger) (*Set, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+	set := &Set{log: logger}
+	for _, cfg := range cfgs {
+		s, err := Open(cfg)
+		if err != nil {
+			set.Close()
+			return nil, err
+		}
+		set.sinks = append(set.sinks, s)
+		set.names = append(set.names, cfg.Name)
+		logger.Printf("output sink -> %s", cfg.Name)
+	}
+	return set, nil
+}
+
+// Present hands frame to every sink. Errors are logged at most once every
+// few seconds so a dead device doesn't flood the log.
+func (s *Set) Present(frame Frame) {
+	if s == nil {
+		return
+	}
+	for i, sk := range s.sinks {
+		if err := sk.Present(frame); err != nil && time.Since(s.lastErr) > 5*time.Second {
+			s.lastErr = time.Now()
+			s.log.Printf("output sink %s: %v", s.names[i], err)
+		}
+	}
+}
+
+// Close closes every sink and returns the first error.
+func (s *Set) Close() error {
+	if s == nil {
+		return nil
+	}
+	var firstErr error
+	for i, sk := range s.sinks {
+		if err := sk.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("sink %s: %w", s.names[i], err)
+		}
+	}
+	return firstErr
+}
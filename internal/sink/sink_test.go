@@ -0,0 +1,55 @@
+package sink
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegisterPanics(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		factory Factory
+		want    string
+	}{
+		{"rgbpipe", func() OutputSink { return &rgbPipe{} }, "called twice for rgbpipe"},
+		{"nothing", nil, "factory is nil for nothing"},
+	} {
+		func() {
+			defer func() {
+				msg, _ := recover().(string)
+				if !strings.Contains(msg, tc.want) {
+					t.Errorf("%q: panicked with %q, want %q", tc.name, msg, tc.want)
+				}
+			}()
+			Register(tc.name, tc.factory)
+		}()
+	}
+	if _, err := Open(Config{Name: "nothing"}); err == nil {
+		t.Error("nil factory got registered")
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	for _, tc := range []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{Name: "nope"}, `unknown sink "nope" (have [`},
+		{Config{Name: "rgbpipe"}, "sink rgbpipe: missing option path"},
+		{Config{Name: "artnet", Options: map[string]string{"zones": "0x1"}}, `sink artnet: bad zones "0x1"`},
+		{Config{Name: "sacn", Options: map[string]string{"universe": "0"}}, `sink sacn: bad universe "0" (1-63999)`},
+		{Config{Name: "led", Options: map[string]string{"chip": "ws2811"}}, `sink led: unknown chip "ws2811"`},
+		{Config{Name: "hue", Options: map[string]string{"bridge": "b", "user": "u"}}, "sink hue: needs a group or a light"},
+		{Config{Name: "homeassistant", Options: map[string]string{"token": "t"}}, "sink homeassistant: needs entities"},
+	} {
+		s, err := Open(tc.cfg)
+		if err == nil {
+			s.Close()
+			t.Errorf("%q: opened, want %q", tc.cfg.Name, tc.want)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), tc.want) {
+			t.Errorf("%q: got %q, want %q", tc.cfg.Name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package web
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	dnsTypeANY    = 255
+	dnsCacheFlush = 0x8000 // class bit: this record replaces cached ones
+	dnsQU         = 0x8000 // question class bit: answer by unicast
+
+	// mdnsTTL is what the records are cached for; legacy unicast
+	// answers get at most mdnsLegacyTTL (RFC 6762 section 6.7).
+	mdnsTTL       = 120
+	mdnsLegacyTTL = 10
+	// mdnsProbeWait is how long a probe listens for a claim on the names.
+	mdnsProbeWait = 750 * time.Millisecond
+	mdnsMaxProbes = 10
+
+	mdnsServiceType = "_http._tcp.local"
+	mdnsServiceEnum = "_services._dns-sd._udp.local"
+)
+
+// SetMDNS advertises the panel on the link as golizer.local with its own
+// responder, no avahi needed. Call before Start.
+func (s *Server) SetMDNS(enabled bool) {
+	s.mdns = enabled
+}
+
+// mdnsResponder answers for one _http._tcp instance and its host name.
+type mdnsResponder struct {
+	base     string // name before conflicts, e.g. "golizer"
+	instance string // e.g. "golizer" or "golizer #2"
+	host     string // e.g. "golizer.local" or "golizer-2.local"
+	port     int
+	text     []string
+	ips      func() []net.IP
+}
+
+func newMDNSResponder(base string, port int) *mdnsResponder {
+	m := &mdnsResponder{
+		base: base,
+		port: port,
+		text: []string{"path=/", "app=golizer"},
+		ips:  localIPv4s,
+	}
+	m.rename(1)
+	return m
+}
+
+// rename picks the n-th names, the way avahi does on a conflict.
+func (m *mdnsResponder) rename(n int) {
+	m.instance, m.host = m.base, m.base+".local"
+	if n > 1 {
+		m.instance = fmt.Sprintf("%s #%d", m.base, n)
+		m.host = fmt.Sprintf("%s-%d.local", m.base, n)
+	}
+}
+
+func (m *mdnsResponder) instanceName() string {
+	return m.instance + "." + mdnsServiceType
+}
+
+// localIPv4s lists the addresses the host name stands for.
+func localIPv4s() []net.IP {
+	var ips []net.IP
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+			ips = append(ips, ipnet.IP.To4())
+		}
+	}
+	return ips
+}
+
+// dnsRR is a resource record to send, its data already encoded.
+type dnsRR struct {
+	name  string
+	rtype uint16
+	flush bool // unique record
+	ttl   uint32
+	data  []byte
+}
+
+func (m *mdnsResponder) ptrRecord() dnsRR {
+	return dnsRR{name: mdnsServiceType, rtype: dnsTypePTR, ttl: mdnsTTL, data: appendDNSName(nil, m.instanceName())}
+}
+
+func (m *mdnsResponder) srvRecord() dnsRR {
+	data := make([]byte, 6) // priority and weight 0
+	binary.BigEndian.PutUint16(data[4:], uint16(m.port))
+	return dnsRR{name: m.instanceName(), rtype: dnsTypeSRV, flush: true, ttl: mdnsTTL, data: appendDNSName(data, m.host)}
+}
+
+func (m *mdnsResponder) txtRecord() dnsRR {
+	var data []byte
+	for _, kv := range m.text {
+		data = append(data, byte(len(kv)))
+		data = append(data, kv...)
+	}
+	return dnsRR{name: m.instanceName(), rtype: dnsTypeTXT, flush: true, ttl: mdnsTTL, data: data}
+}
+
+func (m *mdnsResponder) aRecords() []dnsRR {
+	var out []dnsRR
+	for _, ip := range m.ips() {
+		out = append(out, dnsRR{name: m.host, rtype: dnsTypeA, flush: true, ttl: mdnsTTL, data: ip.To4()})
+	}
+	return out
+}
+
+// all is every record, for announcements and goodbyes.
+func (m *mdnsResponder) all() []dnsRR {
+	return append([]dnsRR{m.ptrRecord(), m.srvRecord(), m.txtRecord()}, m.aRecords()...)
+}
+
+// answer returns the records for one question and the additional ones a
+// resolver will ask for next.
+func (m *mdnsResponder) answer(q dnsQuestion) (answers, extra []dnsRR) {
+	wants := func(rtype uint16) bool { return q.qtype == rtype || q.qtype == dnsTypeANY }
+	switch {
+	case strings.EqualFold(q.name, mdnsServiceEnum) && wants(dnsTypePTR):
+		answers = append(answers, dnsRR{name: mdnsServiceEnum, rtype: dnsTypePTR, ttl: mdnsTTL, data: appendDNSName(nil, mdnsServiceType)})
+	case strings.EqualFold(q.name, mdnsServiceType) && wants(dnsTypePTR):
+		answers = append(answers, m.ptrRecord())
+		extra = append([]dnsRR{m.srvRecord(), m.txtRecord()}, m.aRecords()...)
+	case strings.EqualFold(q.name, m.instanceName()):
+		if wants(dnsTypeSRV) {
+			answers = append(answers, m.srvRecord())
+			extra = m.aRecords()
+		}
+		if wants(dnsTypeTXT) {
+			answers = append(answers, m.txtRecord())
+		}
+	case strings.EqualFold(q.name, m.host) && wants(dnsTypeA):
+		answers = m.aRecords()
+	}
+	return answers, extra
+}
+
+// dnsQuestion is one question of a query.
+type dnsQuestion struct {
+	name    string
+	qtype   uint16
+	unicast bool // QU bit
+}
+
+// parseDNSQuery returns the id and questions of a standard query.
+func parseDNSQuery(msg []byte) (uint16, []dnsQuestion, error) {
+	if len(msg) < 12 {
+		return 0, nil, errShortDNS
+	}
+	if msg[2]&0xf8 != 0 {
+		return 0, nil, errors.New("mdns: not a standard query")
+	}
+	var questions []dnsQuestion
+	off := 12
+	for range int(binary.BigEndian.Uint16(msg[4:])) {
+		name, next, err := readDNSName(msg, off)
+		if err != nil {
+			return 0, nil, err
+		}
+		if next+4 > len(msg) {
+			return 0, nil, errShortDNS
+		}
+		questions = append(questions, dnsQuestion{
+			name:    name,
+			qtype:   binary.BigEndian.Uint16(msg[next:]),
+			unicast: binary.BigEndian.Uint16(msg[next+2:])&dnsQU != 0,
+		})
+		off = next + 4
+	}
+	return binary.BigEndian.Uint16(msg), questions, nil
+}
+
+// dnsResponse builds an authoritative answer. Legacy unicast replies echo
+// the questions and must not set the cache flush bit.
+func dnsResponse(id uint16, questions []dnsQuestion, answers, extra []dnsRR, legacy bool) []byte {
+	p := make([]byte, 12)
+	binary.BigEndian.PutUint16(p, id)
+	binary.BigEndian.PutUint16(p[2:], 0x8400) // response, authoritative
+	binary.BigEndian.PutUint16(p[4:], uint16(len(questions)))
+	binary.BigEndian.PutUint16(p[6:], uint16(len(answers)))
+	binary.BigEndian.PutUint16(p[10:], uint16(len(extra)))
+	for _, q := range questions {
+		p = appendDNSName(p, q.name)
+		p = binary.BigEndian.AppendUint16(p, q.qtype)
+		p = binary.BigEndian.AppendUint16(p, dnsClassIN)
+	}
+	for _, rr := range append(answers, extra...) {
+		class, ttl := uint16(dnsClassIN), rr.ttl
+		if legacy {
+			ttl = min(ttl, mdnsLegacyTTL)
+		} else if rr.flush {
+			class |= dnsCacheFlush
+		}
+		p = appendDNSName(p, rr.name)
+		p = binary.BigEndian.AppendUint16(p, rr.rtype)
+		p = binary.BigEndian.AppendUint16(p, class)
+		p = binary.BigEndian.AppendUint32(p, ttl)
+		p = binary.BigEndian.AppendUint16(p, uint16(len(rr.data)))
+		p = append(p, rr.data...)
+	}
+	return p
+}
+
+// probe claims the names: it asks for them and takes the next ones while
+// another host answers. The host's own avahi answering for the same
+// address isn't a conflict.
+func (m *mdnsResponder) probe(conn *net.UDPConn) error {
+	local := map[string]bool{}
+	for _, ip := range m.ips() {
+		local[ip.String()] = true
+	}
+	buf := make([]byte, 9000)
+	for n := 1; n <= mdnsMaxProbes; n++ {
+		m.rename(n)
+		query := dnsQuery(m.host, dnsTypeANY)
+		if _, err := conn.WriteToUDP(query, mdnsGroup); err != nil {
+			return err
+		}
+		if _, err := conn.WriteToUDP(dnsQuery(m.instanceName(), dnsTypeANY), mdnsGroup); err != nil {
+			return err
+		}
+		conflict := false
+		conn.SetReadDeadline(time.Now().Add(mdnsProbeWait))
+		for !conflict {
+			k, from, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				var ne net.Error
+				if errors.As(err, &ne) && ne.Timeout() {
+					break
+				}
+				return err
+			}
+			records, err := parseDNS(buf[:k])
+			if err != nil {
+				continue
+			}
+			for _, r := range records {
+				switch {
+				case r.rtype == dnsTypeA && strings.EqualFold(r.name, m.host) && !local[r.ip.String()]:
+					conflict = true
+				case strings.EqualFold(r.name, m.instanceName()) && !local[from.IP.String()]:
+					conflict = true
+				}
+			}
+		}
+		conn.SetReadDeadline(time.Time{})
+		if !conflict {
+			return nil
+		}
+	}
+	return fmt.Errorf("mdns: %s up to #%d are all taken", m.base, mdnsMaxProbes)
+}
+
+// advertise runs the responder until ctx is done, then says goodbye.
+func (s *Server) advertise(ctx context.Context, port int) {
+	conn, err := net.ListenMulticastUDP("udp4", nil, mdnsGroup)
+	if err != nil {
+		log.Printf("[web] mDNS: %v", err)
+		return
+	}
+	m := newMDNSResponder("golizer", port)
+	stop := context.AfterFunc(ctx, func() {
+		goodbye := m.all()
+		for i := range goodbye {
+			goodbye[i].ttl = 0
+		}
+		conn.WriteToUDP(dnsResponse(0, nil, goodbye, nil, false), mdnsGroup)
+		conn.Close()
+	})
+	defer stop()
+	if err := m.probe(conn); err != nil {
+		log.Printf("[web] mDNS: %v", err)
+		conn.Close()
+		return
+	}
+	log.Printf("[web] mDNS: advertising http://%s:%d as %q", m.host, port, m.instance)
+
+	// announce twice, a second apart
+	go func() {
+		for i := range 2 {
+			if i > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Second):
+				}
+			}
+			conn.WriteToUDP(dnsResponse(0, nil, m.all(), nil, false), mdnsGroup)
+		}
+	}()
+
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("[web] mDNS: %v", err)
+				conn.Close()
+			}
+			return
+		}
+		id, questions, err := parseDNSQuery(buf[:n])
+		if err != nil {
+			continue
+		}
+		legacy := from.Port != mdnsGroup.Port
+		var answered []dnsQuestion
+		var answers, extra []dnsRR
+		unicast := legacy
+		for _, q := range questions {
+			a, e := m.answer(q)
+			if len(a) == 0 {
+				continue
+			}
+			answered = append(answered, q)
+			answers = append(answers, a...)
+			extra = append(extra, e...)
+			unicast = unicast || q.unicast
+		}
+		if len(answers) == 0 {
+			continue
+		}
+		if legacy {
+			conn.WriteToUDP(dnsResponse(id, answered, answers, extra, true), from)
+		} else if unicast {
+			conn.WriteToUDP(dnsResponse(0, nil, answers, extra, false), from)
+		} else {
+			conn.WriteToUDP(dnsResponse(0, nil, answers, extra, false), mdnsGroup)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	apppkg "github.com/guidoenr/golizer/internal/app"
+	"github.com/guidoenr/golizer/internal/render"
+)
+
+// apiPrefix is where the versioned API lives. The unversioned /api/ paths
+// stay as aliases for scripts written before it.
+const apiPrefix = "/api/v1"
+
+// maxRequestBody bounds what an API request may send; the largest bodies
+// are imported configs and lyrics.
+const maxRequestBody = 1 << 20
+
+// ErrorResponse is the body of every failed API request.
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// APIError says what went wrong: the HTTP status, its text as a stable
+// code (e.g. "bad_request") and a human readable message. A request that
+// failed validation lists the rejected fields.
+type APIError struct {
+	Status  int          `json:"status"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// apiError replies with an ErrorResponse; it takes the arguments of
+// http.Error.
+func apiError(w http.ResponseWriter, message string, status int) {
+	writeAPIError(w, APIError{Status: status, Message: message})
+}
+
+func writeAPIError(w http.ResponseWriter, e APIError) {
+	e.Code = strings.ReplaceAll(strings.ToLower(http.StatusText(e.Status)), " ", "_")
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: e})
+}
+
+// apiRoute is one endpoint of the API, described well enough to mount it
+// and to write it into the OpenAPI spec.
+type apiRoute struct {
+	// path is relative to apiPrefix; a {name} segment is a path parameter
+	// and makes the route match everything under the part before it.
+	path     string
+	methods  []string
+	summary  string
+	handler  http.HandlerFunc
+	mutating bool // rejected in kiosk mode
+	// request and response are zero values of the JSON bodies, nil for
+	// none.
+	request, response any
+	// contentType is the response's media type when it isn't JSON.
+	contentType string
+}
+
+// apiRoutes lists every API endpoint.
+func (s *Server) apiRoutes() []apiRoute {
+	get, post := []string{http.MethodGet}, []string{http.MethodPost}
+	return []apiRoute{
+		{path: "/status", methods: get, summary: "Current look, levels and frame rate", handler: s.handleStatus, response: StatusResponse{}},
+		{path: "/events", methods: get, summary: "The status as Server-Sent Events, for clients that can't use /ws", handler: s.handleEvents, contentType: "text/event-stream"},
+		{path: "/update", methods: post, summary: "Change params, look, size and analysis settings; fields left out stay", handler: s.handleUpdate, mutating: true, request: UpdateRequest{}, response: map[string]string{}},
+		{path: "/save", methods: post, summary: "Write the current settings to the config file", handler: s.handleSave, mutating: true, request: SavedConfig{}, response: map[string]string{}},
+		{path: "/config/export", methods: get, summary: "The full config as a file", handler: s.handleConfigExport, response: SavedConfig{}},
+		{path: "/config/import", methods: post, summary: "Apply and save a config file of any older version", handler: s.handleConfigImport, mutating: true, request: SavedConfig{}, response: ImportResponse{}},
+		{path: "/calibrate", methods: post, summary: "Measure the room's noise floor", handler: s.handleCalibrate, mutating: true, request: CalibrateRequest{}, response: analyzer.NoiseFloors{}},
+		{path: "/audio/restart", methods: post, summary: "Reopen the sound card, optionally with another device or buffer size", handler: s.handleAudioRestart, mutating: true, request: AudioRestartRequest{}, response: apppkg.AudioInfo{}},
+		{path: "/palettes", methods: get, summary: "Palette names", handler: s.handlePalettes, response: []string{}},
+		{path: "/patterns", methods: get, summary: "Pattern names", handler: s.handlePatterns, response: []string{}},
+		{path: "/patterns/{name}/params", methods: []string{http.MethodGet, http.MethodPut, http.MethodPost}, summary: "Read or set a pattern's knobs", handler: s.handlePatternParams, mutating: true, request: map[string]float64{}, response: PatternParamsResponse{}},
+		{path: "/colorModes", methods: get, summary: "Color mode names", handler: s.handleColorModes, response: []string{}},
+		{path: "/colorModes/{name}", methods: []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete}, summary: "Read, change or reset a color mode's curve; without a name, every curve", handler: s.handleColorCurve, mutating: true, request: render.ColorCurve{}, response: render.ColorCurve{}},
+		{path: "/gradient", methods: []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete}, summary: "Read, replace or reset the gradient color mode's stops", handler: s.handleGradient, mutating: true, request: []render.GradientStop{}, response: []render.GradientStop{}},
+		{path: "/effects", methods: []string{http.MethodGet, http.MethodPost}, summary: "Read or replace the post effect pipeline", handler: s.handleEffects, mutating: true, request: []render.EffectConfig{}, response: EffectsResponse{}},
+		{path: "/lyrics", methods: []string{http.MethodGet, http.MethodPost}, summary: "Read or set the flashed words or lyrics", handler: s.handleLyrics, mutating: true, request: LyricsRequest{}, response: LyricsResponse{}},
+		{path: "/overlay", methods: []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete}, summary: "Read, replace or clear the text overlay", handler: s.handleOverlay, mutating: true, request: render.Overlay{}, response: render.Overlay{}},
+		{path: "/tap", methods: post, summary: "Register one tap-tempo tap", handler: s.handleTap, mutating: true, response: map[string]float64{}},
+		{path: "/trigger", methods: post, summary: "Fire a one-shot flash, strobe, drop or blackout", handler: s.handleTrigger, mutating: true, request: TriggerRequest{}, response: map[string]string{}},
+		{path: "/presets", methods: []string{http.MethodGet, http.MethodPost, http.MethodDelete}, summary: "List presets, save the current look (POST {\"name\"}) or delete ?name=", handler: s.handlePresets, mutating: true, request: struct {
+			Name string `json:"name"`
+		}{}, response: []apppkg.Preset{}},
+		{path: "/presets/{name}/load", methods: post, summary: "Switch to a preset", handler: s.handlePresetLoad, mutating: true, response: map[string]string{}},
+		{path: "/playlist", methods: []string{http.MethodGet, http.MethodPut, http.MethodPost}, summary: "Read or replace the playlist", handler: s.handlePlaylist, mutating: true, request: apppkg.Playlist{}, response: PlaylistResponse{}},
+		{path: "/playlist/{action}", methods: post, summary: "play or stop the playlist", handler: s.handlePlaylistControl, mutating: true, response: PlaylistResponse{}},
+		{path: "/capture/gif", methods: post, summary: "The last ?seconds= of frames as an animated GIF", handler: s.handleCaptureGIF, contentType: "image/gif"},
+		{path: "/snapshot.png", methods: get, summary: "The next rendered frame", handler: s.handleSnapshot, contentType: "image/png"},
+		{path: "/fleet", methods: get, summary: "Scan the network for other instances for ?wait= milliseconds", handler: s.handleFleet, response: []FleetPeer{}},
+		{path: "/fleet/proxy", methods: post, summary: "Send one API request to several instances", handler: s.handleFleetProxy, mutating: true, request: FleetRequest{}, response: []FleetResult{}},
+		{path: "/spec", methods: get, summary: "This OpenAPI description", handler: s.handleSpec, response: map[string]any{}},
+	}
+}
+
+// limitBody cuts a request body off after maxRequestBody bytes, so the
+// handlers' decoders fail instead of reading whatever a client sends.
+func limitBody(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+		h(w, r)
+	}
+}
+
+// mountAPI adds every route under apiPrefix, the /api/ aliases and a JSON
+// 404 for the paths in between.
+func (s *Server) mountAPI() {
+	mounted := map[string]bool{}
+	for _, route := range s.apiRoutes() {
+		pattern := apiPrefix + route.path
+		if prefix, _, found := strings.Cut(pattern, "{"); found {
+			pattern = prefix
+		}
+		if mounted[pattern] {
+			continue
+		}
+		mounted[pattern] = true
+		h := limitBody(route.handler)
+		if route.mutating {
+			h = s.mutating(h)
+		}
+		s.mux.HandleFunc(pattern, h)
+	}
+	s.mux.HandleFunc(apiPrefix+"/", func(w http.ResponseWriter, r *http.Request) {
+		apiError(w, "no such endpoint", http.StatusNotFound)
+	})
+	// /api/x is /api/v1/x
+	s.mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = apiPrefix + strings.TrimPrefix(r.URL.Path, "/api")
+		r2.URL.RawPath = ""
+		s.mux.ServeHTTP(w, r2)
+	})
+}
@@ -0,0 +1,126 @@
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimitBody(t *testing.T) {
+	h := limitBody(func(w http.ResponseWriter, r *http.Request) {
+		var v any
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+		}
+	})
+	for _, tc := range []struct {
+		size int
+		want int
+	}{
+		{1000, http.StatusOK},
+		{maxRequestBody + 1, http.StatusBadRequest},
+	} {
+		body := `"` + strings.Repeat("a", tc.size-2) + `"`
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodPost, "/api/v1/update", strings.NewReader(body)))
+		if rec.Code != tc.want {
+			t.Errorf("%d bytes: status %d, want %d", tc.size, rec.Code, tc.want)
+		}
+	}
+}
+
+// apiApp records the few calls the API tests make; anything else panics on
+// the nil AppInterface.
+type apiApp struct {
+	AppInterface
+	taps   int
+	gif    time.Duration
+	preset string
+}
+
+func (a *apiApp) Tap(time.Time) float64 { a.taps++; return 120 }
+
+func (a *apiApp) WriteGIF(w io.Writer, window time.Duration) error {
+	a.gif = window
+	return nil
+}
+
+func (a *apiApp) LoadPreset(name string) error {
+	a.preset = name
+	return nil
+}
+
+func newAPIServer(kiosk bool) (*Server, *apiApp) {
+	app := &apiApp{}
+	s := NewServer(app)
+	s.SetKiosk(kiosk)
+	s.mountAPI()
+	return s, app
+}
+
+func serveAPI(s *Server, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestAPIAliases(t *testing.T) {
+	s, app := newAPIServer(false)
+	if rec := serveAPI(s, http.MethodPost, "/api/capture/gif?seconds=2.5"); rec.Code != http.StatusOK || app.gif != 2500*time.Millisecond {
+		t.Errorf("/api/capture/gif?seconds=2.5: status %d, window %v", rec.Code, app.gif)
+	}
+	if rec := serveAPI(s, http.MethodPost, "/api/presets/late%20set/load"); rec.Code != http.StatusOK || app.preset != "late set" {
+		t.Errorf("/api/presets/late%%20set/load: status %d, preset %q", rec.Code, app.preset)
+	}
+	if rec := serveAPI(s, http.MethodPost, "/api/tap"); rec.Code != http.StatusOK || app.taps != 1 {
+		t.Errorf("/api/tap: status %d, %d taps", rec.Code, app.taps)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	s, app := newAPIServer(false)
+	for _, tc := range []struct {
+		method, target string
+		want           int
+	}{
+		{http.MethodGet, "/api/v1/nothing", http.StatusNotFound},
+		{http.MethodGet, "/api/nothing", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/presets/x/unload", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/tap", http.StatusMethodNotAllowed},
+		{http.MethodDelete, "/api/v1/update", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/capture/gif", http.StatusMethodNotAllowed},
+		{http.MethodPut, "/api/v1/fleet/proxy", http.StatusMethodNotAllowed},
+	} {
+		rec := serveAPI(s, tc.method, tc.target)
+		var body ErrorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || rec.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("%s %s: not a JSON error (%v)", tc.method, tc.target, err)
+		}
+		if rec.Code != tc.want || body.Error.Status != tc.want {
+			t.Errorf("%s %s: status %d (body %d), want %d", tc.method, tc.target, rec.Code, body.Error.Status, tc.want)
+		}
+	}
+	if app.taps != 0 {
+		t.Errorf("GET /api/v1/tap tapped")
+	}
+}
+
+func TestAPIKiosk(t *testing.T) {
+	s, app := newAPIServer(true)
+	for _, target := range []string{"/api/v1/tap", "/api/tap", "/api/v1/presets/x/load"} {
+		if rec := serveAPI(s, http.MethodPost, target); rec.Code != http.StatusForbidden {
+			t.Errorf("POST %s in kiosk mode: status %d, want %d", target, rec.Code, http.StatusForbidden)
+		}
+	}
+	if app.taps != 0 || app.preset != "" {
+		t.Errorf("kiosk mode let a write through: %d taps, preset %q", app.taps, app.preset)
+	}
+	// not mutating: capturing a GIF only reads
+	if rec := serveAPI(s, http.MethodPost, "/api/v1/capture/gif"); rec.Code != http.StatusOK {
+		t.Errorf("POST /api/v1/capture/gif in kiosk mode: status %d", rec.Code)
+	}
+}
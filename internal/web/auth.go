@@ -0,0 +1,64 @@
+package web
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// tokenCookie remembers a browser that has shown the token once, so the
+// panel's own requests and websockets need nothing extra.
+const tokenCookie = "golizer_token"
+
+// SetToken requires token on every request but static assets: as a bearer
+// token, as the password of basic auth (any user), as ?token= in the URL or
+// in the cookie set after any of those. Empty leaves the panel open. Call
+// before Start.
+func (s *Server) SetToken(token string) {
+	s.token = token
+}
+
+// authorized reports whether r carries the token, and whether it came some
+// other way than the cookie.
+func (s *Server) authorized(r *http.Request) (ok, fresh bool) {
+	match := func(v string) bool {
+		return v != "" && subtle.ConstantTimeCompare([]byte(v), []byte(s.token)) == 1
+	}
+	if c, err := r.Cookie(tokenCookie); err == nil && match(c.Value) {
+		return true, false
+	}
+	if bearer, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found && match(bearer) {
+		return true, true
+	}
+	if _, password, found := r.BasicAuth(); found && match(password) {
+		return true, true
+	}
+	return match(r.URL.Query().Get("token")), true
+}
+
+// requireToken wraps h with the token check.
+func (s *Server) requireToken(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token == "" || strings.HasPrefix(r.URL.Path, "/static/") {
+			h.ServeHTTP(w, r)
+			return
+		}
+		ok, fresh := s.authorized(r)
+		if !ok {
+			// browsers answer the challenge with a login prompt
+			w.Header().Set("WWW-Authenticate", `Basic realm="golizer"`)
+			apiError(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if fresh {
+			http.SetCookie(w, &http.Cookie{
+				Name:     tokenCookie,
+				Value:    s.token,
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			})
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,80 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthorized(t *testing.T) {
+	s := &Server{token: "s3cret"}
+	for _, tc := range []struct {
+		name      string
+		req       func(r *http.Request)
+		ok, fresh bool
+	}{
+		{"nothing", func(r *http.Request) {}, false, true},
+		{"cookie", func(r *http.Request) { r.AddCookie(&http.Cookie{Name: tokenCookie, Value: "s3cret"}) }, true, false},
+		{"wrong cookie", func(r *http.Request) { r.AddCookie(&http.Cookie{Name: tokenCookie, Value: "nope"}) }, false, true},
+		{"bearer", func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") }, true, true},
+		{"wrong bearer", func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cre") }, false, true},
+		{"basic auth", func(r *http.Request) { r.SetBasicAuth("anyone", "s3cret") }, true, true},
+		{"token as basic auth user", func(r *http.Request) { r.SetBasicAuth("s3cret", "") }, false, true},
+		{"query", func(r *http.Request) { r.URL.RawQuery = "token=s3cret" }, true, true},
+		{"empty query", func(r *http.Request) { r.URL.RawQuery = "token=" }, false, true},
+		{"wrong cookie, right bearer", func(r *http.Request) {
+			r.AddCookie(&http.Cookie{Name: tokenCookie, Value: "old"})
+			r.Header.Set("Authorization", "Bearer s3cret")
+		}, true, true},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/status", nil)
+		tc.req(r)
+		if ok, fresh := s.authorized(r); ok != tc.ok || ok && fresh != tc.fresh {
+			t.Errorf("%s: got %v, %v, want %v, %v", tc.name, ok, fresh, tc.ok, tc.fresh)
+		}
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	get := func(s *Server, path string, req func(r *http.Request)) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		req(r)
+		w := httptest.NewRecorder()
+		s.requireToken(ok).ServeHTTP(w, r)
+		return w
+	}
+	none := func(r *http.Request) {}
+
+	if w := get(&Server{}, "/api/v1/status", none); w.Code != http.StatusOK || len(w.Result().Cookies()) != 0 {
+		t.Errorf("no token: %d, cookies %v", w.Code, w.Result().Cookies())
+	}
+
+	s := &Server{token: "s3cret"}
+	if w := get(s, "/static/app.js", none); w.Code != http.StatusOK {
+		t.Errorf("static asset: %d", w.Code)
+	}
+	w := get(s, "/debug/pprof/", none)
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != `Basic realm="golizer"` {
+		t.Errorf("no credentials: %d, challenge %q", w.Code, w.Header().Get("WWW-Authenticate"))
+	}
+	if len(w.Result().Cookies()) != 0 {
+		t.Errorf("refused request got cookies %v", w.Result().Cookies())
+	}
+
+	w = get(s, "/?token=s3cret", none)
+	cookies := w.Result().Cookies()
+	if w.Code != http.StatusOK || len(cookies) != 1 {
+		t.Fatalf("token in the URL: %d, cookies %v", w.Code, cookies)
+	}
+	c := cookies[0]
+	if c.Name != tokenCookie || c.Value != "s3cret" || c.Path != "/" || !c.HttpOnly || c.SameSite != http.SameSiteStrictMode {
+		t.Errorf("cookie %+v", c)
+	}
+
+	// the cookie alone gets in, and isn't issued again
+	w = get(s, "/ws", func(r *http.Request) { r.AddCookie(c) })
+	if w.Code != http.StatusOK || len(w.Result().Cookies()) != 0 {
+		t.Errorf("with the cookie: %d, cookies %v", w.Code, w.Result().Cookies())
+	}
+}
@@ -0,0 +1,227 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/guidoenr/golizer/internal/analyzer"
+	configpkg "github.com/guidoenr/golizer/internal/config"
+	"github.com/guidoenr/golizer/internal/render"
+)
+
+// maxImportSize bounds an imported config file.
+const maxImportSize = 1 << 20
+
+// ImportResponse reports an import: the version the file was written as
+// and the settings that didn't apply.
+type ImportResponse struct {
+	Status   string   `json:"status"`
+	Path     string   `json:"path"`
+	From     int      `json:"fromVersion"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// currentConfig is everything the running instance would save, together
+// with the hand-edited parts of the config file.
+func (s *Server) currentConfig() SavedConfig {
+	s.mu.RLock()
+	renderer := s.app.GetRenderer()
+	currentParams := s.app.GetParams()
+	cfg := s.app.GetConfig()
+	s.mu.RUnlock()
+
+	config := SavedConfig{
+		Version:        configpkg.Version,
+		Params:         currentParams,
+		Palette:        renderer.PaletteName(),
+		Pattern:        renderer.PatternName(),
+		ColorMode:      renderer.ColorModeName(),
+		NoiseFloor:     cfg.NoiseFloor(),
+		NoiseFloors:    cfg.NoiseFloors(),
+		Envelopes:      cfg.Envelopes(),
+		BufferSize:     cfg.BufferSize(),
+		TargetFPS:      0, // always unlimited
+		Quality:        cfg.Quality(),
+		Width:          cfg.Width(),
+		Height:         cfg.Height(),
+		AutoRandomize:  cfg.AutoRandomize(),
+		RandomInterval: cfg.RandomInterval(),
+		ShowStatusBar:  cfg.ShowStatusBar(),
+		Effects:        renderer.Effects(),
+		ColorCurves:    customColorCurves(renderer.ColorCurves()),
+		PatternParams:  renderer.TunedPatterns(),
+	}
+	if renderer.CustomGradient() {
+		config.Gradient = renderer.Gradient()
+	}
+	if playlist, _ := s.app.Playlist(); len(playlist.Steps) > 0 {
+		config.Playlist = &playlist
+	}
+	if existing, err := loadConfig(s.configPath); err == nil {
+		config.Palettes = existing.Palettes
+		config.OutputProfiles = existing.OutputProfiles
+		config.Widgets = existing.Widgets
+	}
+	return config
+}
+
+// handleConfigExport serves GET /api/v1/config/export, the full config as a
+// file to keep or load on another instance.
+func (s *Server) handleConfigExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	data, err := json.MarshalIndent(s.currentConfig(), "", "  ")
+	if err != nil {
+		apiError(w, fmt.Sprintf("encode config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+time.Now().Format("golizer-config-20060102-150405.json")+`"`)
+	w.Write(data)
+}
+
+// handleConfigImport serves POST /api/v1/config/import: the body is a
+// config file of any version up to the current one. It is migrated,
+// applied to the running instance and saved in place of the config file.
+// Settings that don't apply are skipped and reported, like at startup.
+func (s *Server) handleConfigImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxImportSize+1))
+	if err != nil {
+		apiError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(data) > maxImportSize {
+		apiError(w, fmt.Sprintf("config larger than %d bytes", maxImportSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+	migrated, from, err := configpkg.Migrate(data)
+	if err != nil {
+		apiError(w, fmt.Sprintf("config: %v", err), http.StatusBadRequest)
+		return
+	}
+	var config SavedConfig
+	if err := json.Unmarshal(migrated, &config); err != nil {
+		apiError(w, fmt.Sprintf("config: %v", err), http.StatusBadRequest)
+		return
+	}
+	if errs := validateConfig(config); len(errs) > 0 {
+		invalidRequest(w, errs)
+		return
+	}
+
+	warnings := s.applyConfig(config)
+	if err := saveConfig(s.configPath, config); err != nil {
+		apiError(w, fmt.Sprintf("failed to save config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ImportResponse{Status: "imported", Path: s.configPath, From: from, Warnings: warnings})
+}
+
+// validateConfig checks the sizes of an imported config with the limits of
+// /api/v1/update; zero means unset, as in the file. Params aren't checked:
+// a saved file holds them as the music left them, past the sliders' ranges.
+func validateConfig(c SavedConfig) []FieldError {
+	var req UpdateRequest
+	if c.BufferSize != 0 {
+		req.BufferSize = &c.BufferSize
+	}
+	if c.Width != 0 {
+		req.Width = &c.Width
+	}
+	if c.Height != 0 {
+		req.Height = &c.Height
+	}
+	if c.Quality != "" {
+		req.Quality = &c.Quality
+	}
+	return validateUpdate(req)
+}
+
+// applyConfig switches the running instance to c and returns what didn't
+// apply. Sizes and the buffer take effect like /api/v1/update's; output
+// profiles and widgets are read at startup only.
+func (s *Server) applyConfig(c SavedConfig) []string {
+	var warnings []string
+	warn := func(format string, args ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+	for name, chars := range c.Palettes {
+		if err := render.RegisterPalette(name, chars); err != nil {
+			warn("palette %s: %v", name, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	renderer := s.app.GetRenderer()
+	s.app.SetParams(c.Params)
+	renderer.Configure(c.Palette, c.Pattern, c.ColorMode, renderer.ColorOnAudio())
+	if c.Quality != "" {
+		renderer.SetQuality(c.Quality)
+	}
+	if c.NoiseFloor > 0 {
+		s.app.SetNoiseFloor(c.NoiseFloor)
+	}
+	if !c.NoiseFloors.IsZero() {
+		s.app.SetNoiseFloors(c.NoiseFloors)
+	}
+	if !c.Envelopes.IsZero() {
+		for band, env := range map[string]analyzer.Envelope{
+			"sub": c.Envelopes.Sub, "bass": c.Envelopes.Bass, "lowMid": c.Envelopes.LowMid,
+			"mid": c.Envelopes.Mid, "highMid": c.Envelopes.HighMid, "treble": c.Envelopes.Treble,
+		} {
+			if err := s.app.SetEnvelope(band, env); err != nil {
+				warn("envelope %s: %v", band, err)
+			}
+		}
+	}
+	if c.BufferSize > 0 {
+		s.app.SetBufferSize(c.BufferSize)
+	}
+	if c.Width > 0 && c.Height > 0 {
+		s.app.SetDimensions(c.Width, c.Height)
+	}
+	s.app.SetAutoRandomize(c.AutoRandomize)
+	if c.RandomInterval > 0 {
+		s.app.SetRandomInterval(c.RandomInterval)
+	}
+	s.app.SetShowStatusBar(c.ShowStatusBar)
+
+	if err := renderer.SetEffects(c.Effects); err != nil {
+		warn("effects: %v", err)
+	}
+	for name := range render.DefaultColorCurves() {
+		if err := renderer.ResetColorCurve(name); err != nil {
+			warn("color curve %s: %v", name, err)
+		}
+	}
+	for name, curve := range c.ColorCurves {
+		if err := renderer.SetColorCurve(name, curve); err != nil {
+			warn("color curve %s: %v", name, err)
+		}
+	}
+	if err := renderer.SetGradient(c.Gradient); err != nil {
+		warn("gradient: %v", err)
+	}
+	for name, values := range c.PatternParams {
+		if err := renderer.SetPatternParams(name, values); err != nil {
+			warn("pattern params %s: %v", name, err)
+		}
+	}
+	if c.Playlist != nil {
+		if err := s.app.SetPlaylist(*c.Playlist); err != nil {
+			warn("playlist: %v", err)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,131 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apppkg "github.com/guidoenr/golizer/internal/app"
+	configpkg "github.com/guidoenr/golizer/internal/config"
+)
+
+// newConfigServer returns a server on a real app without audio or a
+// screen, saving to a config file in a temp dir.
+func newConfigServer(t *testing.T) (*Server, *apppkg.App) {
+	t.Helper()
+	dir := t.TempDir()
+	app, err := apppkg.New(apppkg.Config{
+		Backend:      "none",
+		DisableAudio: true,
+		PresetsPath:  filepath.Join(dir, "presets.json"),
+		Log:          log.New(io.Discard, "", 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { app.Close() })
+	s := NewServer(app)
+	s.SetConfigPath(filepath.Join(dir, "config.json"))
+	return s, app
+}
+
+func importConfig(s *Server, body []byte) (*httptest.ResponseRecorder, ImportResponse) {
+	rec := httptest.NewRecorder()
+	s.handleConfigImport(rec, httptest.NewRequest(http.MethodPost, "/api/v1/config/import", bytes.NewReader(body)))
+	var resp ImportResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec, resp
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	s, app := newConfigServer(t)
+	renderer := app.GetRenderer()
+	pattern := renderer.PatternNames()[1]
+	renderer.Configure(renderer.PaletteName(), pattern, renderer.ColorModeName(), renderer.ColorOnAudio())
+	p := app.GetParams()
+	p.Amplitude = 1.7
+	app.SetParams(p)
+
+	rec := httptest.NewRecorder()
+	s.handleConfigExport(rec, httptest.NewRequest(http.MethodGet, "/api/v1/config/export", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export: status %d: %s", rec.Code, rec.Body)
+	}
+	exported := rec.Body.Bytes()
+
+	// move away, then import what was exported
+	renderer.Configure(renderer.PaletteName(), renderer.PatternNames()[0], renderer.ColorModeName(), renderer.ColorOnAudio())
+	p.Amplitude = 0.3
+	app.SetParams(p)
+	rec, resp := importConfig(s, exported)
+	if rec.Code != http.StatusOK || resp.From != configpkg.Version || len(resp.Warnings) > 0 {
+		t.Fatalf("import: status %d, %+v", rec.Code, resp)
+	}
+	if got := renderer.PatternName(); got != pattern {
+		t.Errorf("pattern %q, want %q", got, pattern)
+	}
+	if got := app.GetParams().Amplitude; got != 1.7 {
+		t.Errorf("amplitude %v, want 1.7", got)
+	}
+	saved, err := loadConfig(s.configPath)
+	if err != nil || saved.Pattern != pattern || saved.Version != configpkg.Version {
+		t.Errorf("saved %+v, %v", saved, err)
+	}
+}
+
+func TestConfigImportOlderVersion(t *testing.T) {
+	s, app := newConfigServer(t)
+	// a version 0 file, from before Gamma was a param
+	rec, resp := importConfig(s, []byte(`{"palette": "block", "params": {"Amplitude": 1.2, "Gamma": 0}}`))
+	if rec.Code != http.StatusOK || resp.From != 0 {
+		t.Fatalf("status %d, %+v", rec.Code, resp)
+	}
+	if p := app.GetParams(); p.Amplitude != 1.2 || p.Gamma != 1 {
+		t.Errorf("amplitude %v gamma %v, want 1.2 and 1", p.Amplitude, p.Gamma)
+	}
+	data, err := os.ReadFile(s.configPath)
+	if err != nil || !strings.Contains(string(data), `"version": 2`) {
+		t.Errorf("saved as %s, %v", data, err)
+	}
+}
+
+func TestConfigImportWarnings(t *testing.T) {
+	s, app := newConfigServer(t)
+	rec, resp := importConfig(s, []byte(`{"version": 2, "params": {"Amplitude": 1.1}, "effects": [{"name": "nope", "enabled": true}]}`))
+	if rec.Code != http.StatusOK || len(resp.Warnings) != 1 || !strings.HasPrefix(resp.Warnings[0], "effects: ") {
+		t.Fatalf("status %d, %+v", rec.Code, resp)
+	}
+	// the rest applied
+	if got := app.GetParams().Amplitude; got != 1.1 {
+		t.Errorf("amplitude %v, want 1.1", got)
+	}
+}
+
+func TestConfigImportRejects(t *testing.T) {
+	s, _ := newConfigServer(t)
+	big := []byte(`{"palette": "` + strings.Repeat("a", maxImportSize) + `"}`)
+	for _, tc := range []struct {
+		name string
+		body []byte
+		want int
+	}{
+		{"too large", big, http.StatusRequestEntityTooLarge},
+		{"newer", []byte(`{"version": 99}`), http.StatusBadRequest},
+		{"broken", []byte(`{"version": `), http.StatusBadRequest},
+		{"invalid", []byte(`{"version": 2, "width": -4}`), http.StatusBadRequest},
+	} {
+		if rec, _ := importConfig(s, tc.body); rec.Code != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, rec.Code, tc.want)
+		}
+	}
+	if _, err := os.Stat(s.configPath); !os.IsNotExist(err) {
+		t.Errorf("a rejected import was saved: %v", err)
+	}
+}
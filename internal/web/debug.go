@@ -0,0 +1,76 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// startTime is when the process came up, for the uptime in /debug/runtime.
+var startTime = time.Now()
+
+// SetDebug mounts the pprof profiles under /debug/pprof/ and a runtime
+// summary at /debug/runtime, for profiling an installation in place. Call
+// before Start.
+func (s *Server) SetDebug(enabled bool) {
+	s.debug = enabled
+}
+
+// RuntimeResponse is a snapshot of the Go runtime.
+type RuntimeResponse struct {
+	Uptime       float64 `json:"uptimeSeconds"`
+	GoVersion    string  `json:"goVersion"`
+	NumCPU       int     `json:"numCPU"`
+	GOMAXPROCS   int     `json:"gomaxprocs"`
+	Goroutines   int     `json:"goroutines"`
+	HeapAlloc    uint64  `json:"heapAlloc"`
+	HeapInuse    uint64  `json:"heapInuse"`
+	Sys          uint64  `json:"sys"`
+	TotalAlloc   uint64  `json:"totalAlloc"`
+	Mallocs      uint64  `json:"mallocs"`
+	NumGC        uint32  `json:"numGC"`
+	LastGCPause  float64 `json:"lastGCPauseMs"`
+	GCCPUPercent float64 `json:"gcCPUPercent"`
+	FPS          float64 `json:"fps"`
+}
+
+// handler is the mux behind the rate limit and the token check, so
+// guessing the token counts against the limit too.
+func (s *Server) handler() http.Handler {
+	return s.limitRate(s.requireToken(s.mux))
+}
+
+// mountDebug adds the pprof profiles and the runtime summary to the mux.
+func (s *Server) mountDebug() {
+	s.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	s.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	s.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	s.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	s.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	s.mux.HandleFunc("/debug/runtime", s.handleRuntime)
+}
+
+func (s *Server) handleRuntime(w http.ResponseWriter, r *http.Request) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	resp := RuntimeResponse{
+		Uptime:       time.Since(startTime).Seconds(),
+		GoVersion:    runtime.Version(),
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		Goroutines:   runtime.NumGoroutine(),
+		HeapAlloc:    m.HeapAlloc,
+		HeapInuse:    m.HeapInuse,
+		Sys:          m.Sys,
+		TotalAlloc:   m.TotalAlloc,
+		Mallocs:      m.Mallocs,
+		NumGC:        m.NumGC,
+		LastGCPause:  float64(m.PauseNs[(m.NumGC+255)%256]) / 1e6,
+		GCCPUPercent: m.GCCPUFraction * 100,
+		FPS:          s.app.GetFPS(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,32 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+type fpsApp struct {
+	AppInterface
+	fps float64
+}
+
+func (a fpsApp) GetFPS() float64 { return a.fps }
+
+func TestHandleRuntime(t *testing.T) {
+	s := NewServer(fpsApp{fps: 29.5})
+	rec := httptest.NewRecorder()
+	s.handleRuntime(rec, httptest.NewRequest(http.MethodGet, "/debug/runtime", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("content type %q", ct)
+	}
+	var resp RuntimeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.GoVersion != runtime.Version() || resp.FPS != 29.5 || resp.Goroutines < 1 || resp.HeapAlloc == 0 || resp.Uptime <= 0 {
+		t.Errorf("got %+v", resp)
+	}
+}
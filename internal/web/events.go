@@ -0,0 +1,64 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sseKeepAlive is how often an idle event stream gets a comment, so
+// proxies don't time it out.
+const sseKeepAlive = 15 * time.Second
+
+// handleEvents serves GET /api/v1/events, the status broadcast of /ws as
+// Server-Sent Events for browsers and proxies that can't keep a websocket
+// open. Every message is a "status" event carrying a StatusResponse.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		apiError(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	events := make(chan []byte, 16)
+	s.mu.Lock()
+	s.sseClients[events] = true
+	payload := s.lastStatusPayload
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.sseClients, events)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx would hold the stream back
+	w.WriteHeader(http.StatusOK)
+	// a reconnecting EventSource waits this long
+	fmt.Fprint(w, "retry: 2000\n\n")
+	if payload != nil {
+		fmt.Fprintf(w, "event: status\ndata: %s\n\n", payload)
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case payload := <-events:
+			fmt.Fprintf(w, "event: status\ndata: %s\n\n", payload)
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,84 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleEvents(t *testing.T) {
+	s := NewServer(nil)
+	s.lastStatusPayload = []byte(`{"fps":30}`)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.broadcastLoop(ctx)
+	ts := httptest.NewServer(http.HandlerFunc(s.handleEvents))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("content type %q", ct)
+	}
+	lines := bufio.NewReader(resp.Body)
+	// next returns the next event, blank line included
+	next := func() string {
+		var ev strings.Builder
+		for {
+			line, err := lines.ReadString('\n')
+			if err != nil {
+				t.Fatalf("stream ended: %v after %q", err, ev.String())
+			}
+			ev.WriteString(line)
+			if line == "\n" {
+				return ev.String()
+			}
+		}
+	}
+
+	if got := next(); got != "retry: 2000\n\n" {
+		t.Errorf("first: got %q", got)
+	}
+	if got := next(); got != "event: status\ndata: {\"fps\":30}\n\n" {
+		t.Errorf("latest status: got %q", got)
+	}
+	s.broadcast <- []byte(`{"fps":60}`)
+	if got := next(); got != "event: status\ndata: {\"fps\":60}\n\n" {
+		t.Errorf("broadcast: got %q", got)
+	}
+
+	// shutdown ends the stream and forgets it
+	close(s.done)
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, lines)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("stream ended with %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream still open after shutdown")
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.sseClients) != 0 {
+		t.Errorf("%d streams still registered", len(s.sseClients))
+	}
+
+	rec := httptest.NewRecorder()
+	s.handleEvents(rec, httptest.NewRequest(http.MethodPost, "/api/v1/events", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: status %d", rec.Code)
+	}
+}
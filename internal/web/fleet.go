@@ -0,0 +1,234 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// fleetScan is how long a scan listens for answers by default; ?wait=
+	// (milliseconds) changes it up to fleetMaxScan.
+	fleetScan    = 1500 * time.Millisecond
+	fleetMaxScan = 5 * time.Second
+	// fleetTimeout bounds one proxied request.
+	fleetTimeout = 3 * time.Second
+)
+
+// FleetPeer is another golizer on the network, or this one.
+type FleetPeer struct {
+	Name string `json:"name"`
+	Host string `json:"host"`
+	Addr string `json:"addr"` // ip:port of its panel
+	Self bool   `json:"self,omitempty"`
+}
+
+// FleetRequest is a request to send to several instances.
+type FleetRequest struct {
+	// Peers are names from the last scan; empty means every one but this
+	// instance.
+	Peers  []string        `json:"peers,omitempty"`
+	Method string          `json:"method,omitempty"` // default POST
+	Path   string          `json:"path"`             // an /api/ path
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// FleetResult is how one peer answered.
+type FleetResult struct {
+	Peer   string `json:"peer"`
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// isGolizer picks golizer panels out of every http service on the network:
+// newer ones say so in their TXT record, older ones only by name.
+func isGolizer(svc mdnsService) bool {
+	return svc.Text["app"] == "golizer" || strings.HasPrefix(strings.ToLower(svc.Instance), "golizer")
+}
+
+// browseServices finds the http services on the network; tests replace it.
+var browseServices = browseMDNS
+
+// scanFleet browses for panels and remembers them for proxying.
+func (s *Server) scanFleet(ctx context.Context, wait time.Duration) ([]FleetPeer, error) {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	services, err := browseServices(ctx, "_http._tcp")
+	if err != nil {
+		return nil, err
+	}
+	local := map[string]bool{}
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, a := range addrs {
+			if ipnet, ok := a.(*net.IPNet); ok {
+				local[ipnet.IP.String()] = true
+			}
+		}
+	}
+	peers := []FleetPeer{}
+	for _, svc := range services {
+		if !isGolizer(svc) {
+			continue
+		}
+		peers = append(peers, FleetPeer{
+			Name: svc.Instance,
+			Host: svc.Host,
+			Addr: svc.addr(),
+			Self: local[svc.Addr.String()] && svc.Port == s.port,
+		})
+	}
+	slices.SortFunc(peers, func(a, b FleetPeer) int { return strings.Compare(a.Name, b.Name) })
+
+	s.mu.Lock()
+	s.fleet = peers
+	s.mu.Unlock()
+	return peers, nil
+}
+
+// handleFleet serves GET /api/v1/fleet, scanning the network for other
+// instances.
+func (s *Server) handleFleet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	wait := fleetScan
+	if ms, err := strconv.Atoi(r.URL.Query().Get("wait")); err == nil && ms > 0 {
+		wait = min(time.Duration(ms)*time.Millisecond, fleetMaxScan)
+	}
+	peers, err := s.scanFleet(r.Context(), wait)
+	if err != nil {
+		apiError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(peers)
+}
+
+// handleFleetProxy serves POST /api/v1/fleet/proxy, sending one API request
+// to several instances at once, e.g. the same preset to every screen. Only
+// peers found by a scan are reachable, and only their API.
+func (s *Server) handleFleetProxy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req FleetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Method == "" {
+		req.Method = http.MethodPost
+	}
+	if err := checkFleetRequest(req); err != nil {
+		apiError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.RLock()
+	fleet := s.fleet
+	s.mu.RUnlock()
+	if fleet == nil {
+		var err error
+		if fleet, err = s.scanFleet(r.Context(), fleetScan); err != nil {
+			apiError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	var targets []FleetPeer
+	for _, p := range fleet {
+		if len(req.Peers) == 0 && !p.Self || slices.Contains(req.Peers, p.Name) {
+			targets = append(targets, p)
+		}
+	}
+
+	results := make([]FleetResult, len(targets))
+	var wg sync.WaitGroup
+	for i, p := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.proxyTo(r.Context(), p, req)
+		}()
+	}
+	wg.Wait()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// checkFleetRequest keeps proxied requests to the peers' API. The path has
+// to be clean as sent and once unescaped: a peer's mux would resolve
+// /api/../debug/pprof/ to a page outside the API, or /api/./v1/fleet/proxy
+// to the proxy itself and pass one request on to the whole fleet.
+func checkFleetRequest(req FleetRequest) error {
+	switch req.Method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete:
+	default:
+		return fmt.Errorf("method %q not allowed (GET, POST, PUT or DELETE)", req.Method)
+	}
+	p, _, _ := strings.Cut(req.Path, "?")
+	unescaped, err := url.PathUnescape(p)
+	if err != nil || path.Clean(p) != p || path.Clean(unescaped) != unescaped || strings.Contains(p, "#") {
+		return errors.New("path must be clean, without . or .. segments")
+	}
+	if !strings.HasPrefix(unescaped, "/api/") || strings.HasPrefix(unescaped, "/api/fleet") || strings.HasPrefix(unescaped, apiPrefix+"/fleet") {
+		return errors.New("path must be an /api/ endpoint other than /api/fleet")
+	}
+	return nil
+}
+
+// SetFleetToken sets the token proxied requests carry to the peers, their
+// --web-token. Any device on the network can advertise itself as a
+// golizer and would get whatever token goes along, so this instance's own
+// is never sent and peers with a token are only reachable once one is set.
+// Call before Start.
+func (s *Server) SetFleetToken(token string) {
+	s.fleetToken = token
+}
+
+// proxyTo sends req to one peer, with the fleet token if one is set.
+func (s *Server) proxyTo(ctx context.Context, p FleetPeer, req FleetRequest) FleetResult {
+	ctx, cancel := context.WithTimeout(ctx, fleetTimeout)
+	defer cancel()
+	res := FleetResult{Peer: p.Name}
+	var body io.Reader
+	if len(req.Body) > 0 {
+		body = bytes.NewReader(req.Body)
+	}
+	out, err := http.NewRequestWithContext(ctx, req.Method, "http://"+p.Addr+req.Path, body)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if body != nil {
+		out.Header.Set("Content-Type", "application/json")
+	}
+	if s.fleetToken != "" {
+		out.Header.Set("Authorization", "Bearer "+s.fleetToken)
+	}
+	resp, err := http.DefaultClient.Do(out)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	defer resp.Body.Close()
+	res.Status = resp.StatusCode
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		res.Error = strings.TrimSpace(string(msg))
+	}
+	return res
+}
@@ -0,0 +1,160 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestProxyToSendsOnlyTheFleetToken(t *testing.T) {
+	var got string
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+	}))
+	defer peer.Close()
+	p := FleetPeer{Name: "golizer-x", Addr: strings.TrimPrefix(peer.URL, "http://")}
+	req := FleetRequest{Method: http.MethodPost, Path: "/api/v1/tap"}
+
+	s := &Server{token: "mine"}
+	if res := s.proxyTo(context.Background(), p, req); res.Status != http.StatusOK || got != "" {
+		t.Fatalf("without a fleet token: %+v, Authorization %q, want none", res, got)
+	}
+	s.SetFleetToken("fleet")
+	if s.proxyTo(context.Background(), p, req); got != "Bearer fleet" {
+		t.Fatalf("Authorization %q, want the fleet token", got)
+	}
+}
+
+func TestCheckFleetRequest(t *testing.T) {
+	for _, tc := range []struct {
+		method, path string
+		ok           bool
+	}{
+		{"POST", "/api/v1/presets/load", true},
+		{"GET", "/api/status", true},
+		{"PUT", "/api/v1/effects?x=1", true},
+		{"DELETE", "/api/v1/presets/a%20b", true},
+		{"GET", "/debug/pprof/", false},
+		{"GET", "/api/../debug/pprof/cmdline", false},
+		{"GET", "/api/%2e%2e/debug/pprof/cmdline", false},
+		{"POST", "/api/./v1/fleet/proxy", false},
+		{"POST", "/api//v1/fleet/proxy", false},
+		{"POST", "/api/v1/fleet/proxy", false},
+		{"POST", "/api/fleet/proxy", false},
+		{"POST", "/api/v1/%66leet/proxy", false},
+		{"GET", "/api/v1/status/", false},
+		{"GET", "/api/v1/status#x", false},
+		{"PATCH", "/api/v1/update", false},
+		{"CONNECT", "/api/v1/update", false},
+		{"get", "/api/v1/status", false},
+	} {
+		err := checkFleetRequest(FleetRequest{Method: tc.method, Path: tc.path})
+		if (err == nil) != tc.ok {
+			t.Errorf("%s %q: got %v, want ok %v", tc.method, tc.path, err, tc.ok)
+		}
+	}
+}
+
+// fakeFleet serves one httptest peer per name and answers scans with them,
+// the last one on this server's own port.
+func fakeFleet(t *testing.T, s *Server, names ...string) (hits map[string]int, scans *int) {
+	t.Helper()
+	hits, scans = map[string]int{}, new(int)
+	var mu sync.Mutex
+	var services []mdnsService
+	for i, name := range names {
+		peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits[name]++
+			mu.Unlock()
+		}))
+		t.Cleanup(peer.Close)
+		addr := peer.Listener.Addr().(*net.TCPAddr)
+		if i == len(names)-1 {
+			s.port = addr.Port
+		}
+		services = append(services, mdnsService{
+			Instance: name, Host: name + ".local", Addr: addr.IP, Port: addr.Port,
+			Text: map[string]string{"app": "golizer"},
+		})
+	}
+	// another http service on the network
+	services = append(services, mdnsService{Instance: "printer", Host: "printer.local", Addr: net.IPv4(10, 0, 0, 9), Port: 631, Text: map[string]string{}})
+	browse := browseServices
+	browseServices = func(ctx context.Context, service string) ([]mdnsService, error) {
+		*scans++
+		return services, nil
+	}
+	t.Cleanup(func() { browseServices = browse })
+	return hits, scans
+}
+
+func TestHandleFleet(t *testing.T) {
+	s := &Server{}
+	fakeFleet(t, s, "golizer-b", "golizer-a", "golizer-me")
+	w := httptest.NewRecorder()
+	s.handleFleet(w, httptest.NewRequest(http.MethodGet, "/api/v1/fleet?wait=10", nil))
+	var peers []FleetPeer
+	if err := json.NewDecoder(w.Body).Decode(&peers); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("%d %v", w.Code, err)
+	}
+	var got []string
+	for _, p := range peers {
+		got = append(got, fmt.Sprintf("%s self=%v", p.Name, p.Self))
+	}
+	want := []string{"golizer-a self=false", "golizer-b self=false", "golizer-me self=true"}
+	if !slices.Equal(got, want) {
+		t.Errorf("peers %q, want %q", got, want)
+	}
+	if !slices.Equal(s.fleet, peers) {
+		t.Errorf("scan not kept: %+v", s.fleet)
+	}
+}
+
+func TestHandleFleetProxy(t *testing.T) {
+	post := func(s *Server, req FleetRequest) (int, []FleetResult) {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		s.handleFleetProxy(w, httptest.NewRequest(http.MethodPost, "/api/v1/fleet/proxy", bytes.NewReader(body)))
+		var results []FleetResult
+		json.NewDecoder(w.Body).Decode(&results)
+		return w.Code, results
+	}
+
+	s := &Server{}
+	hits, scans := fakeFleet(t, s, "golizer-a", "golizer-b", "golizer-me")
+	// no peers named: everyone but this instance, found by a first scan
+	if code, results := post(s, FleetRequest{Path: "/api/v1/trigger"}); code != http.StatusOK || len(results) != 2 {
+		t.Fatalf("all peers: %d %+v", code, results)
+	}
+	if hits["golizer-a"] != 1 || hits["golizer-b"] != 1 || hits["golizer-me"] != 0 || *scans != 1 {
+		t.Errorf("all peers: hits %v, %d scans", hits, *scans)
+	}
+	// named peers, self included, from the kept scan
+	if _, results := post(s, FleetRequest{Peers: []string{"golizer-b", "golizer-me", "gone"}, Path: "/api/v1/trigger"}); len(results) != 2 {
+		t.Fatalf("named peers: %+v", results)
+	}
+	if hits["golizer-a"] != 1 || hits["golizer-b"] != 2 || hits["golizer-me"] != 1 || *scans != 1 {
+		t.Errorf("named peers: hits %v, %d scans", hits, *scans)
+	}
+	for _, req := range []FleetRequest{
+		{Path: "/api/../debug/pprof/"},
+		{Path: "/api/v1/fleet/proxy"},
+		{Method: "TRACE", Path: "/api/v1/status"},
+	} {
+		if code, _ := post(s, req); code != http.StatusBadRequest {
+			t.Errorf("%s %s: %d, want 400", req.Method, req.Path, code)
+		}
+	}
+	if hits["golizer-a"] != 1 || hits["golizer-b"] != 2 {
+		t.Errorf("refused requests reached peers: %v", hits)
+	}
+}
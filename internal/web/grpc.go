@@ -0,0 +1,354 @@
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+
+	golizerv1 "github.com/guidoenr/golizer/api/v1"
+	"github.com/guidoenr/golizer/internal/analyzer"
+	apppkg "github.com/guidoenr/golizer/internal/app"
+	"github.com/guidoenr/golizer/internal/params"
+	"github.com/guidoenr/golizer/internal/render"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// grpcFeaturesFPS is the default rate of StreamFeatures.
+	grpcFeaturesFPS    = 30
+	grpcFeaturesMaxFPS = 60
+)
+
+// grpcReadOnly are the methods kiosk mode still allows.
+var grpcReadOnly = map[string]bool{
+	"GetStatus":      true,
+	"ListPatterns":   true,
+	"ListPalettes":   true,
+	"ListColorModes": true,
+	"ListPresets":    true,
+	"StreamFeatures": true,
+}
+
+// SetGRPC serves the gRPC API of api/v1/golizer.proto on port as well (0 =
+// off). It checks the token and kiosk mode like the web API. Call before
+// Start.
+func (s *Server) SetGRPC(port int) {
+	s.grpcPort = port
+}
+
+// serveGRPC runs the gRPC listener until ctx is cancelled.
+func (s *Server) serveGRPC(ctx context.Context) error {
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", s.grpcPort))
+	if err != nil {
+		return err
+	}
+	srv := s.newGRPCServer()
+	stop := context.AfterFunc(ctx, func() {
+		// feature streams end on s.done; calls in flight get
+		// shutdownTimeout
+		s.closeSockets()
+		timer := time.AfterFunc(shutdownTimeout, srv.Stop)
+		srv.GracefulStop()
+		timer.Stop()
+	})
+	defer stop()
+
+	log.Printf("[web] gRPC API on :%d", s.grpcPort)
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// newGRPCServer returns the Golizer service behind the token and kiosk
+// checks of grpcAllowed.
+func (s *Server) newGRPCServer() *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, h grpc.UnaryHandler) (any, error) {
+			if err := s.grpcAllowed(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return h(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, h grpc.StreamHandler) error {
+			if err := s.grpcAllowed(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return h(srv, ss)
+		}),
+	)
+	golizerv1.RegisterGolizerServer(srv, &grpcService{s: s})
+	return srv
+}
+
+// grpcAllowed checks the token, sent as "authorization: Bearer <token>"
+// metadata, and kiosk mode for one call.
+func (s *Server) grpcAllowed(ctx context.Context, fullMethod string) error {
+	if s.token != "" {
+		md, _ := metadata.FromIncomingContext(ctx)
+		ok := false
+		for _, v := range md.Get("authorization") {
+			if bearer, found := strings.CutPrefix(v, "Bearer "); found && subtle.ConstantTimeCompare([]byte(bearer), []byte(s.token)) == 1 {
+				ok = true
+			}
+		}
+		if !ok {
+			return status.Error(codes.Unauthenticated, "unauthorized")
+		}
+	}
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	if s.kiosk && !grpcReadOnly[method] {
+		return status.Error(codes.PermissionDenied, "read-only (kiosk mode)")
+	}
+	return nil
+}
+
+// grpcService implements the Golizer service on top of the same calls the
+// REST handlers make.
+type grpcService struct {
+	golizerv1.UnimplementedGolizerServer
+	s *Server
+}
+
+func (g *grpcService) GetStatus(context.Context, *golizerv1.GetStatusRequest) (*golizerv1.Status, error) {
+	st := g.s.buildStatusSnapshot()
+	return &golizerv1.Status{
+		Fps:           st.FPS,
+		Features:      featuresMessage(st.Features, st.Features.Onset),
+		Palette:       st.Renderer.Palette,
+		Pattern:       st.Renderer.Pattern,
+		ColorMode:     st.Renderer.ColorMode,
+		Quality:       st.Quality,
+		ShowStatusBar: st.ShowStatusBar,
+		ReadOnly:      st.ReadOnly,
+		TapTempo:      st.TapTempo,
+		MidiClock:     st.MIDIClock,
+		RandomLocks:   locksMessage(st.RandomLocks),
+		Playlist:      playlistMessage(st.Playlist),
+		Paused:        st.Paused,
+	}, nil
+}
+
+func (g *grpcService) Update(_ context.Context, in *golizerv1.UpdateRequest) (*golizerv1.UpdateResponse, error) {
+	req, errs := updateFromMessage(in)
+	errs = append(errs, validateUpdate(req)...)
+	if len(errs) > 0 {
+		return nil, invalidArgument(errs)
+	}
+	if err := g.s.applyUpdate(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &golizerv1.UpdateResponse{}, nil
+}
+
+func (g *grpcService) ListPatterns(context.Context, *golizerv1.ListPatternsRequest) (*golizerv1.ListPatternsResponse, error) {
+	return &golizerv1.ListPatternsResponse{Names: g.s.app.GetRenderer().PatternNames()}, nil
+}
+
+func (g *grpcService) ListPalettes(context.Context, *golizerv1.ListPalettesRequest) (*golizerv1.ListPalettesResponse, error) {
+	return &golizerv1.ListPalettesResponse{Names: render.PaletteNames()}, nil
+}
+
+func (g *grpcService) ListColorModes(context.Context, *golizerv1.ListColorModesRequest) (*golizerv1.ListColorModesResponse, error) {
+	return &golizerv1.ListColorModesResponse{Names: render.ColorModeNames()}, nil
+}
+
+func (g *grpcService) ListPresets(context.Context, *golizerv1.ListPresetsRequest) (*golizerv1.ListPresetsResponse, error) {
+	var out golizerv1.ListPresetsResponse
+	for _, p := range g.s.app.Presets().List() {
+		out.Presets = append(out.Presets, presetMessage(p))
+	}
+	return &out, nil
+}
+
+func (g *grpcService) SavePreset(_ context.Context, in *golizerv1.SavePresetRequest) (*golizerv1.Preset, error) {
+	p, err := g.s.app.SavePreset(in.GetName())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return presetMessage(p), nil
+}
+
+func (g *grpcService) LoadPreset(_ context.Context, in *golizerv1.LoadPresetRequest) (*golizerv1.LoadPresetResponse, error) {
+	if err := g.s.app.LoadPreset(in.GetName()); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &golizerv1.LoadPresetResponse{}, nil
+}
+
+func (g *grpcService) DeletePreset(_ context.Context, in *golizerv1.DeletePresetRequest) (*golizerv1.DeletePresetResponse, error) {
+	if err := g.s.app.Presets().Delete(in.GetName()); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &golizerv1.DeletePresetResponse{}, nil
+}
+
+func (g *grpcService) PlayPlaylist(context.Context, *golizerv1.PlayPlaylistRequest) (*golizerv1.PlaylistState, error) {
+	if err := g.s.app.PlayPlaylist(); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	_, state := g.s.app.Playlist()
+	return playlistMessage(state), nil
+}
+
+func (g *grpcService) StopPlaylist(context.Context, *golizerv1.StopPlaylistRequest) (*golizerv1.PlaylistState, error) {
+	g.s.app.StopPlaylist()
+	_, state := g.s.app.Playlist()
+	return playlistMessage(state), nil
+}
+
+func (g *grpcService) Tap(context.Context, *golizerv1.TapRequest) (*golizerv1.TapResponse, error) {
+	return &golizerv1.TapResponse{Bpm: g.s.app.Tap(time.Now())}, nil
+}
+
+func (g *grpcService) Trigger(_ context.Context, in *golizerv1.TriggerRequest) (*golizerv1.TriggerResponse, error) {
+	if err := g.s.app.Trigger(in.GetEffect(), time.Duration(in.GetDuration()*float64(time.Second))); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &golizerv1.TriggerResponse{}, nil
+}
+
+func (g *grpcService) Save(context.Context, *golizerv1.SaveRequest) (*golizerv1.SaveResponse, error) {
+	if err := saveConfig(g.s.configPath, g.s.currentConfig()); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save config: %v", err)
+	}
+	return &golizerv1.SaveResponse{Path: g.s.configPath}, nil
+}
+
+func (g *grpcService) StreamFeatures(in *golizerv1.StreamFeaturesRequest, stream grpc.ServerStreamingServer[golizerv1.Features]) error {
+	fps := grpcFeaturesFPS
+	if in.GetFps() > 0 {
+		fps = min(grpcFeaturesMaxFPS, int(in.GetFps()))
+	}
+	ticker := time.NewTicker(time.Second / time.Duration(fps))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-g.s.done:
+			return status.Error(codes.Unavailable, "shutting down")
+		case <-ticker.C:
+		}
+		// a beat lasts one frame; look at every frame since the last tick
+		frames := max(1, int(math.Ceil(g.s.app.GetFPS()/float64(fps))))
+		onset := false
+		for _, f := range g.s.app.FeatureHistory(frames) {
+			onset = onset || f.Onset
+		}
+		if err := stream.Send(featuresMessage(g.s.app.GetFeatures(), onset)); err != nil {
+			return err
+		}
+	}
+}
+
+// updateFromMessage turns a gRPC update into the REST one, so both are
+// validated and applied alike. Unknown params are reported as field errors.
+func updateFromMessage(in *golizerv1.UpdateRequest) (UpdateRequest, []FieldError) {
+	req := UpdateRequest{
+		Palette:   in.Palette,
+		Pattern:   in.Pattern,
+		ColorMode: in.ColorMode,
+		Quality:   in.Quality,
+	}
+	var errs []FieldError
+	if len(in.GetParams()) > 0 {
+		known := params.Ranges()
+		var p params.Parameters
+		v := reflect.ValueOf(&p).Elem()
+		for name, value := range in.GetParams() {
+			if _, ok := known[name]; !ok {
+				errs = append(errs, FieldError{Field: "params." + name, Message: "unknown param"})
+				continue
+			}
+			v.FieldByName(name).SetFloat(value)
+		}
+		req.Params = &p
+	}
+	req.NoiseFloor = in.NoiseFloor
+	intPtr := func(v *int32) *int {
+		if v == nil {
+			return nil
+		}
+		n := int(*v)
+		return &n
+	}
+	req.BufferSize = intPtr(in.BufferSize)
+	req.Width = intPtr(in.Width)
+	req.Height = intPtr(in.Height)
+	req.RandomInterval = intPtr(in.RandomInterval)
+	req.AutoRandomize = in.AutoRandomize
+	req.ShowStatusBar = in.ShowStatusBar
+	req.Paused = in.Paused
+	if len(in.GetEnvelopes()) > 0 {
+		req.Envelopes = map[string]analyzer.Envelope{}
+		for band, env := range in.GetEnvelopes() {
+			req.Envelopes[band] = analyzer.Envelope{AttackMs: env.GetAttackMs(), ReleaseMs: env.GetReleaseMs()}
+		}
+	}
+	if l := in.GetRandomLocks(); l != nil {
+		req.RandomLocks = &apppkg.RandomLocks{Pattern: l.GetPattern(), Palette: l.GetPalette(), ColorMode: l.GetColorMode()}
+	}
+	return req, errs
+}
+
+// invalidArgument is the gRPC form of invalidRequest: the fields go into a
+// BadRequest detail.
+func invalidArgument(errs []FieldError) error {
+	st := status.New(codes.InvalidArgument, fmt.Sprintf("%d invalid field(s)", len(errs)))
+	var detail errdetails.BadRequest
+	for _, e := range errs {
+		detail.FieldViolations = append(detail.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: e.Field, Description: e.Message})
+	}
+	if withDetail, err := st.WithDetails(&detail); err == nil {
+		st = withDetail
+	}
+	return st.Err()
+}
+
+func featuresMessage(f analyzer.Features, onset bool) *golizerv1.Features {
+	return &golizerv1.Features{
+		Sub:             f.Sub,
+		Bass:            f.Bass,
+		LowMid:          f.LowMid,
+		Mid:             f.Mid,
+		HighMid:         f.HighMid,
+		Treble:          f.Treble,
+		Overall:         f.Overall,
+		Harmonic:        f.Harmonic,
+		Percussive:      f.Percussive,
+		BeatStrength:    f.BeatStrength,
+		IsDrop:          f.IsDrop,
+		Onset:           onset,
+		Tempo:           f.Tempo,
+		TempoConfidence: f.TempoConfidence,
+		Level:           f.Level,
+		Peak:            f.Peak,
+		Correlation:     f.Correlation,
+		Spectrum:        f.Spectrum[:],
+		Waveform:        f.Waveform[:],
+	}
+}
+
+func locksMessage(l apppkg.RandomLocks) *golizerv1.RandomLocks {
+	return &golizerv1.RandomLocks{Pattern: l.Pattern, Palette: l.Palette, ColorMode: l.ColorMode}
+}
+
+func playlistMessage(st apppkg.PlaylistState) *golizerv1.PlaylistState {
+	return &golizerv1.PlaylistState{Playing: st.Playing, Step: int32(st.Step), Progress: st.Progress}
+}
+
+func presetMessage(p apppkg.Preset) *golizerv1.Preset {
+	return &golizerv1.Preset{Name: p.Name, Pattern: p.Pattern, Palette: p.Palette, ColorMode: p.ColorMode}
+}
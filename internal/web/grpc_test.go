@@ -0,0 +1,106 @@
+package web
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	golizerv1 "github.com/guidoenr/golizer/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// grpcMutating are the methods kiosk mode refuses. Every method of the
+// service has to be in this list or in grpcReadOnly.
+var grpcMutating = []string{
+	"Update", "SavePreset", "LoadPreset", "DeletePreset",
+	"PlayPlaylist", "StopPlaylist", "Tap", "Trigger", "Save",
+}
+
+func TestGRPCMethodsAreClassified(t *testing.T) {
+	mutating := map[string]bool{}
+	for _, m := range grpcMutating {
+		if grpcReadOnly[m] {
+			t.Errorf("%s is both read-only and mutating", m)
+		}
+		mutating[m] = true
+	}
+	desc := golizerv1.Golizer_ServiceDesc
+	var names []string
+	for _, m := range desc.Methods {
+		names = append(names, m.MethodName)
+	}
+	for _, st := range desc.Streams {
+		names = append(names, st.StreamName)
+	}
+	for _, name := range names {
+		if !grpcReadOnly[name] && !mutating[name] {
+			t.Errorf("%s is neither in grpcReadOnly nor in grpcMutating", name)
+		}
+	}
+}
+
+// dialGRPC serves s over an in-memory listener and returns a client for it.
+func dialGRPC(t *testing.T, s *Server) golizerv1.GolizerClient {
+	t.Helper()
+	ln := bufconn.Listen(1 << 16)
+	srv := s.newGRPCServer()
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return golizerv1.NewGolizerClient(conn)
+}
+
+func TestGRPCToken(t *testing.T) {
+	client := dialGRPC(t, &Server{token: "secret"})
+	for _, tc := range []struct {
+		auth string
+		want codes.Code
+	}{
+		{"", codes.Unauthenticated},
+		{"Bearer wrong", codes.Unauthenticated},
+		{"secret", codes.Unauthenticated},
+		{"Bearer secret", codes.OK},
+	} {
+		ctx := context.Background()
+		if tc.auth != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tc.auth)
+		}
+		_, err := client.ListPalettes(ctx, &golizerv1.ListPalettesRequest{})
+		if got := status.Code(err); got != tc.want {
+			t.Errorf("%q: got %v, want %v", tc.auth, got, tc.want)
+		}
+	}
+}
+
+func TestGRPCKiosk(t *testing.T) {
+	client := dialGRPC(t, &Server{kiosk: true})
+	ctx := context.Background()
+	if _, err := client.ListPalettes(ctx, &golizerv1.ListPalettesRequest{}); err != nil {
+		t.Errorf("ListPalettes: %v", err)
+	}
+	for name, call := range map[string]func() error{
+		"Update":     func() error { _, err := client.Update(ctx, &golizerv1.UpdateRequest{}); return err },
+		"SavePreset": func() error { _, err := client.SavePreset(ctx, &golizerv1.SavePresetRequest{Name: "x"}); return err },
+		"DeletePreset": func() error {
+			_, err := client.DeletePreset(ctx, &golizerv1.DeletePresetRequest{Name: "x"})
+			return err
+		},
+		"Trigger": func() error { _, err := client.Trigger(ctx, &golizerv1.TriggerRequest{Effect: "strobe"}); return err },
+		"Save":    func() error { _, err := client.Save(ctx, &golizerv1.SaveRequest{}); return err },
+	} {
+		if got := status.Code(call()); got != codes.PermissionDenied {
+			t.Errorf("%s: got %v, want %v", name, got, codes.PermissionDenied)
+		}
+	}
+}
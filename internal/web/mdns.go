@@ -0,0 +1,267 @@
+package web
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DNS record types a service browse needs.
+const (
+	dnsTypeA   = 1
+	dnsTypePTR = 12
+	dnsTypeTXT = 16
+	dnsTypeSRV = 33
+	dnsClassIN = 1
+)
+
+var mdnsGroup = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// mdnsService is one instance of a service found on the network.
+type mdnsService struct {
+	Instance string // e.g. "golizer on stage-left"
+	Host     string // e.g. "stage-left.local"
+	Addr     net.IP
+	Port     int
+	Text     map[string]string
+}
+
+// dnsRecord is one resource record, with the data of the types browsing
+// uses decoded.
+type dnsRecord struct {
+	name   string
+	rtype  uint16
+	ptr    string // PTR
+	target string // SRV
+	port   int    // SRV
+	text   []string
+	ip     net.IP // A
+}
+
+// browseMDNS asks the link for instances of service (e.g. "_http._tcp")
+// and collects the answers until ctx is done. The query goes from a
+// random port, so responders answer it directly instead of to the group
+// (RFC 6762's legacy unicast), which needs no multicast membership.
+func browseMDNS(ctx context.Context, service string) ([]mdnsService, error) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	name := service + ".local"
+	if _, err := conn.WriteToUDP(dnsQuery(name, dnsTypePTR), mdnsGroup); err != nil {
+		return nil, err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(time.Second)
+	}
+	conn.SetReadDeadline(deadline)
+	go func() {
+		<-ctx.Done()
+		conn.SetReadDeadline(time.Now())
+	}()
+
+	var records []dnsRecord
+	senders := map[string]net.IP{} // instance -> who answered, for a missing A record
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				break
+			}
+			return nil, err
+		}
+		answers, err := parseDNS(buf[:n])
+		if err != nil {
+			continue
+		}
+		for _, r := range answers {
+			if r.rtype == dnsTypePTR && strings.EqualFold(r.name, name) {
+				senders[strings.ToLower(r.ptr)] = from.IP
+			}
+		}
+		records = append(records, answers...)
+	}
+	return collectServices(name, records, senders), nil
+}
+
+// collectServices joins the PTR, SRV, TXT and A records of every instance
+// of name.
+func collectServices(name string, records []dnsRecord, senders map[string]net.IP) []mdnsService {
+	find := func(rtype uint16, owner string) *dnsRecord {
+		for i := range records {
+			if records[i].rtype == rtype && strings.EqualFold(records[i].name, owner) {
+				return &records[i]
+			}
+		}
+		return nil
+	}
+	var services []mdnsService
+	seen := map[string]bool{}
+	for _, r := range records {
+		if r.rtype != dnsTypePTR || !strings.EqualFold(r.name, name) || seen[strings.ToLower(r.ptr)] {
+			continue
+		}
+		seen[strings.ToLower(r.ptr)] = true
+		srv := find(dnsTypeSRV, r.ptr)
+		if srv == nil {
+			continue
+		}
+		svc := mdnsService{
+			Instance: strings.TrimSuffix(r.ptr, "."+name),
+			Host:     srv.target,
+			Port:     srv.port,
+			Text:     map[string]string{},
+			Addr:     senders[strings.ToLower(r.ptr)],
+		}
+		if a := find(dnsTypeA, srv.target); a != nil {
+			svc.Addr = a.ip
+		}
+		if txt := find(dnsTypeTXT, r.ptr); txt != nil {
+			for _, kv := range txt.text {
+				k, v, _ := strings.Cut(kv, "=")
+				svc.Text[strings.ToLower(k)] = v
+			}
+		}
+		if svc.Addr != nil {
+			services = append(services, svc)
+		}
+	}
+	return services
+}
+
+// dnsQuery builds a one-question query.
+func dnsQuery(name string, qtype uint16) []byte {
+	p := make([]byte, 12)
+	binary.BigEndian.PutUint16(p[4:], 1) // one question
+	p = appendDNSName(p, name)
+	p = binary.BigEndian.AppendUint16(p, qtype)
+	return binary.BigEndian.AppendUint16(p, dnsClassIN)
+}
+
+func appendDNSName(p []byte, name string) []byte {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		p = append(p, byte(len(label)))
+		p = append(p, label...)
+	}
+	return append(p, 0)
+}
+
+var errShortDNS = errors.New("mdns: short message")
+
+// parseDNS returns the answer, authority and additional records of a
+// response.
+func parseDNS(msg []byte) ([]dnsRecord, error) {
+	if len(msg) < 12 {
+		return nil, errShortDNS
+	}
+	if msg[2]&0x80 == 0 {
+		return nil, errors.New("mdns: not a response")
+	}
+	questions := int(binary.BigEndian.Uint16(msg[4:]))
+	count := int(binary.BigEndian.Uint16(msg[6:])) + int(binary.BigEndian.Uint16(msg[8:])) + int(binary.BigEndian.Uint16(msg[10:]))
+	off := 12
+	for range questions {
+		_, next, err := readDNSName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		off = next + 4
+	}
+	var records []dnsRecord
+	for range count {
+		name, next, err := readDNSName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		if next+10 > len(msg) {
+			return nil, errShortDNS
+		}
+		r := dnsRecord{name: name, rtype: binary.BigEndian.Uint16(msg[next:])}
+		length := int(binary.BigEndian.Uint16(msg[next+8:]))
+		start, end := next+10, next+10+length
+		if end > len(msg) {
+			return nil, errShortDNS
+		}
+		data := msg[start:end]
+		switch r.rtype {
+		case dnsTypePTR:
+			if r.ptr, _, err = readDNSName(msg, start); err != nil {
+				return nil, err
+			}
+		case dnsTypeSRV:
+			if len(data) < 7 {
+				return nil, errShortDNS
+			}
+			r.port = int(binary.BigEndian.Uint16(data[4:]))
+			if r.target, _, err = readDNSName(msg, start+6); err != nil {
+				return nil, err
+			}
+		case dnsTypeTXT:
+			for i := 0; i < len(data); {
+				n := int(data[i])
+				if i+1+n > len(data) {
+					return nil, errShortDNS
+				}
+				r.text = append(r.text, string(data[i+1:i+1+n]))
+				i += 1 + n
+			}
+		case dnsTypeA:
+			if len(data) == 4 {
+				r.ip = net.IP(append([]byte(nil), data...))
+			}
+		}
+		records = append(records, r)
+		off = end
+	}
+	return records, nil
+}
+
+// readDNSName reads a possibly compressed name at off and returns it
+// without the trailing dot and the offset just after it.
+func readDNSName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	next := -1
+	for jumps := 0; ; {
+		if off >= len(msg) {
+			return "", 0, errShortDNS
+		}
+		n := int(msg[off])
+		switch {
+		case n == 0:
+			if next < 0 {
+				next = off + 1
+			}
+			return strings.Join(labels, "."), next, nil
+		case n&0xc0 == 0xc0:
+			if off+1 >= len(msg) {
+				return "", 0, errShortDNS
+			}
+			if jumps++; jumps > 16 {
+				return "", 0, errors.New("mdns: name pointer loop")
+			}
+			if next < 0 {
+				next = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:]) & 0x3fff)
+		default:
+			if off+1+n > len(msg) {
+				return "", 0, errShortDNS
+			}
+			labels = append(labels, string(msg[off+1:off+1+n]))
+			off += 1 + n
+		}
+	}
+}
+
+// addr returns host:port for dialling the service.
+func (m mdnsService) addr() string {
+	return net.JoinHostPort(m.Addr.String(), strconv.Itoa(m.Port))
+}
@@ -0,0 +1,199 @@
+package web
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+)
+
+// dnsHeader is a 12-byte header with the flags and the four counts.
+func dnsHeader(flags uint16, counts ...uint16) []byte {
+	p := make([]byte, 12)
+	binary.BigEndian.PutUint16(p[2:], flags)
+	for i, n := range counts {
+		binary.BigEndian.PutUint16(p[4+2*i:], n)
+	}
+	return p
+}
+
+func TestReadDNSName(t *testing.T) {
+	// "a.b" at 0, then "c" followed by a pointer to it at 5
+	msg := []byte{1, 'a', 1, 'b', 0, 1, 'c', 0xc0, 0}
+	for _, tc := range []struct {
+		msg  []byte
+		off  int
+		name string
+		next int
+		err  string
+	}{
+		{msg, 0, "a.b", 5, ""},
+		{msg, 5, "c.a.b", 9, ""},
+		{msg, 7, "a.b", 9, ""},
+		{[]byte{0}, 0, "", 1, ""},
+		{[]byte{3, 'a', 'b'}, 0, "", 0, "short"},     // label past the end
+		{[]byte{1, 'a'}, 0, "", 0, "short"},          // no terminator
+		{[]byte{1, 'a', 0xc0}, 0, "", 0, "short"},    // half a pointer
+		{[]byte{0xc0, 0}, 0, "", 0, "loop"},          // points at itself
+		{[]byte{1, 'a', 0xc0, 0}, 0, "", 0, "loop"},  // back to its own start
+		{[]byte{0xc0, 2, 0xc0, 0}, 0, "", 0, "loop"}, // two pointers at each other
+		{[]byte{0xc0, 9}, 0, "", 0, "short"},         // points past the end
+		{msg, len(msg), "", 0, "short"},
+	} {
+		name, next, err := readDNSName(tc.msg, tc.off)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("% x at %d: got %q, %v, want %q", tc.msg, tc.off, name, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || name != tc.name || next != tc.next {
+			t.Errorf("% x at %d: got %q, %d, %v, want %q, %d", tc.msg, tc.off, name, next, err, tc.name, tc.next)
+		}
+	}
+}
+
+func TestParseDNSErrors(t *testing.T) {
+	rr := func(rtype uint16, data ...byte) []byte {
+		p := dnsHeader(0x8400, 0, 1)
+		p = appendDNSName(p, "x.local")
+		p = binary.BigEndian.AppendUint16(p, rtype)
+		p = binary.BigEndian.AppendUint16(p, dnsClassIN)
+		p = binary.BigEndian.AppendUint32(p, 120)
+		p = binary.BigEndian.AppendUint16(p, uint16(len(data)))
+		return append(p, data...)
+	}
+	good := rr(dnsTypeA, 10, 0, 0, 1)
+	for _, tc := range []struct {
+		name string
+		msg  []byte
+		err  string
+	}{
+		{"short header", make([]byte, 11), "short"},
+		{"a query", dnsHeader(0), "not a response"},
+		{"cut in the record header", good[:len(good)-8], "short"},
+		{"cut in the data", good[:len(good)-1], "short"},
+		{"more records than sent", append(dnsHeader(0x8400, 0, 2), good[12:]...), "short"},
+		{"cut question", append(dnsHeader(0x8400, 1), 5, 'x'), "short"},
+		{"short SRV", rr(dnsTypeSRV, 0, 0, 0, 0, 0, 80), "short"},
+		{"TXT string past the data", rr(dnsTypeTXT, 5, 'a', 'b'), "short"},
+		// the data starts after the header, the name and the record header
+		{"PTR loop", rr(dnsTypePTR, 0xc0, 12+9+10), "loop"},
+	} {
+		if _, err := parseDNS(tc.msg); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.err)
+		}
+	}
+
+	records, err := parseDNS(good)
+	if err != nil || len(records) != 1 || !records[0].ip.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Fatalf("A record: %+v, %v", records, err)
+	}
+	// a five byte A record isn't an address, but the message is fine
+	if records, err := parseDNS(rr(dnsTypeA, 1, 2, 3, 4, 5)); err != nil || records[0].ip != nil {
+		t.Errorf("long A record: %+v, %v", records, err)
+	}
+}
+
+func TestParseDNSQuery(t *testing.T) {
+	id, questions, err := parseDNSQuery(dnsQuery("golizer.local", dnsTypeANY))
+	if err != nil || id != 0 || len(questions) != 1 {
+		t.Fatalf("got %d %+v %v", id, questions, err)
+	}
+	if q := questions[0]; q.name != "golizer.local" || q.qtype != dnsTypeANY || q.unicast {
+		t.Errorf("question %+v", q)
+	}
+
+	qu := dnsHeader(0, 2)
+	qu[0], qu[1] = 0x12, 0x34
+	qu = appendDNSName(qu, "_http._tcp.local")
+	qu = binary.BigEndian.AppendUint16(qu, dnsTypePTR)
+	qu = binary.BigEndian.AppendUint16(qu, dnsQU|dnsClassIN)
+	qu = append(qu, 0xc0, 12) // the same name again, compressed
+	qu = binary.BigEndian.AppendUint16(qu, dnsTypeSRV)
+	qu = binary.BigEndian.AppendUint16(qu, dnsClassIN)
+	id, questions, err = parseDNSQuery(qu)
+	if err != nil || id != 0x1234 || len(questions) != 2 {
+		t.Fatalf("two questions: %#x %+v %v", id, questions, err)
+	}
+	if q := questions[0]; q.name != "_http._tcp.local" || q.qtype != dnsTypePTR || !q.unicast {
+		t.Errorf("first question %+v", q)
+	}
+	if q := questions[1]; q.name != "_http._tcp.local" || q.qtype != dnsTypeSRV || q.unicast {
+		t.Errorf("compressed question %+v", q)
+	}
+
+	for _, tc := range []struct {
+		name string
+		msg  []byte
+		err  string
+	}{
+		{"short header", make([]byte, 5), "short"},
+		{"a response", dnsHeader(0x8400, 1), "not a standard query"},
+		{"no type and class", qu[:len(qu)-2], "short"},
+		{"no name", dnsHeader(0, 1), "short"},
+		{"pointer loop", append(dnsHeader(0, 1), 0xc0, 12), "loop"},
+	} {
+		if _, _, err := parseDNSQuery(tc.msg); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.err)
+		}
+	}
+}
+
+// TestDNSResponseRoundTrip has the responder answer a browse and reads the
+// reply back the way browseMDNS does.
+func TestDNSResponseRoundTrip(t *testing.T) {
+	m := newMDNSResponder("golizer", 8080)
+	m.rename(2)
+	m.ips = func() []net.IP { return []net.IP{net.IPv4(192, 168, 1, 7).To4()} }
+
+	q := dnsQuestion{name: mdnsServiceType, qtype: dnsTypePTR}
+	answers, extra := m.answer(q)
+	for _, legacy := range []bool{false, true} {
+		msg := dnsResponse(0x4242, []dnsQuestion{q}, answers, extra, legacy)
+		if binary.BigEndian.Uint16(msg) != 0x4242 {
+			t.Errorf("legacy %v: id % x", legacy, msg[:2])
+		}
+		records, err := parseDNS(msg)
+		if err != nil {
+			t.Fatalf("legacy %v: %v", legacy, err)
+		}
+		if len(records) != 4 {
+			t.Fatalf("legacy %v: %d records, want PTR, SRV, TXT and A", legacy, len(records))
+		}
+		services := collectServices(mdnsServiceType, records, nil)
+		if len(services) != 1 {
+			t.Fatalf("legacy %v: services %+v", legacy, services)
+		}
+		svc := services[0]
+		if svc.Instance != "golizer #2" || svc.Host != "golizer-2.local" || svc.Port != 8080 ||
+			svc.addr() != "192.168.1.7:8080" || svc.Text["app"] != "golizer" || svc.Text["path"] != "/" {
+			t.Errorf("legacy %v: service %+v", legacy, svc)
+		}
+
+		// the class and TTL of the SRV record, right after its name
+		srv := strings.Index(string(msg), "\x0agolizer #2\x05_http\x04_tcp\x05local\x00\x00\x21")
+		if srv < 0 {
+			t.Fatalf("legacy %v: no SRV record in % x", legacy, msg)
+		}
+		rest := msg[srv+len("\x0agolizer #2\x05_http\x04_tcp\x05local\x00")+2:]
+		class, ttl := binary.BigEndian.Uint16(rest), binary.BigEndian.Uint32(rest[2:])
+		if legacy && (class != dnsClassIN || ttl != mdnsLegacyTTL) {
+			t.Errorf("legacy SRV class %#x TTL %d, want no cache flush and %d", class, ttl, mdnsLegacyTTL)
+		}
+		if !legacy && (class != dnsCacheFlush|dnsClassIN || ttl != mdnsTTL) {
+			t.Errorf("SRV class %#x TTL %d, want cache flush and %d", class, ttl, mdnsTTL)
+		}
+	}
+
+	// a query read back to a response answers the same question
+	_, questions, err := parseDNSQuery(dnsQuery(m.instanceName(), dnsTypeANY))
+	if err != nil {
+		t.Fatal(err)
+	}
+	answers, extra = m.answer(questions[0])
+	records, err := parseDNS(dnsResponse(0, nil, answers, extra, false))
+	if err != nil || len(records) != 3 {
+		t.Fatalf("instance ANY: %+v, %v, want SRV, TXT and A", records, err)
+	}
+}
@@ -0,0 +1,195 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// apiTitle and apiVersion go into the spec's info block; apiVersion moves
+// with incompatible changes under apiPrefix.
+const (
+	apiTitle   = "golizer"
+	apiVersion = "1.0.0"
+)
+
+// specBuilder turns apiRoutes into an OpenAPI 3 document. Go types become
+// schemas by reflection, named structs once under components.
+type specBuilder struct {
+	schemas map[string]any
+}
+
+// openAPISpec describes the API as served.
+func (s *Server) openAPISpec() map[string]any {
+	b := &specBuilder{schemas: map[string]any{}}
+	paths := map[string]any{}
+	for _, route := range s.apiRoutes() {
+		item := map[string]any{}
+		for _, method := range route.methods {
+			item[strings.ToLower(method)] = b.operation(route, method)
+		}
+		paths[apiPrefix+route.path] = item
+	}
+	b.schemas["web.ErrorResponse"] = b.schema(reflect.TypeOf(ErrorResponse{}))
+
+	spec := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   apiTitle,
+			"version": apiVersion,
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": b.schemas},
+	}
+	if s.token != "" {
+		spec["components"].(map[string]any)["securitySchemes"] = map[string]any{
+			"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+		}
+		spec["security"] = []any{map[string]any{"bearer": []string{}}}
+	}
+	return spec
+}
+
+func (b *specBuilder) operation(route apiRoute, method string) map[string]any {
+	op := map[string]any{"summary": route.summary}
+	var params []any
+	for _, seg := range strings.Split(route.path, "/") {
+		if name, ok := strings.CutPrefix(seg, "{"); ok {
+			params = append(params, map[string]any{
+				"name":     strings.TrimSuffix(name, "}"),
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+	}
+	if params != nil {
+		op["parameters"] = params
+	}
+	if route.request != nil && method != http.MethodGet && method != http.MethodDelete {
+		op["requestBody"] = map[string]any{
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": b.schema(reflect.TypeOf(route.request))},
+			},
+		}
+	}
+
+	ok := map[string]any{"description": "OK"}
+	switch {
+	case route.contentType != "":
+		ok["content"] = map[string]any{
+			route.contentType: map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}},
+		}
+	case route.response != nil:
+		ok["content"] = map[string]any{
+			"application/json": map[string]any{"schema": b.schema(reflect.TypeOf(route.response))},
+		}
+	}
+	op["responses"] = map[string]any{
+		"200": ok,
+		"default": map[string]any{
+			"description": "Error",
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/web.ErrorResponse"}},
+			},
+		},
+	}
+	return op
+}
+
+var (
+	durationType   = reflect.TypeOf(time.Duration(0))
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// schema returns the JSON schema of t, a $ref for named structs.
+func (b *specBuilder) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case durationType:
+		return map[string]any{"type": "integer", "description": "nanoseconds"}
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		name := t.String()
+		if _, ok := b.schemas[name]; !ok {
+			b.schemas[name] = nil // stops recursion through self-referencing types
+			b.schemas[name] = b.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]any{}
+}
+
+// object is the schema of a struct's JSON fields, embedded ones inlined.
+func (b *specBuilder) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	b.fields(t, props)
+	return map[string]any{"type": "object", "properties": props}
+}
+
+func (b *specBuilder) fields(t reflect.Type, props map[string]any) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.fields(ft, props)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = b.schema(f.Type)
+	}
+}
+
+// handleSpec serves the OpenAPI description of the API.
+func (s *Server) handleSpec(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(s.openAPISpec())
+}
@@ -0,0 +1,104 @@
+package web
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRateLimit is how many API requests per second one client may
+	// make in the long run; the panel's sliders stay well below it.
+	defaultRateLimit = 20
+	// rateBurstSeconds worth of requests may come at once.
+	rateBurstSeconds = 2
+	// maxRateClients bounds the buckets kept before idle ones are dropped.
+	maxRateClients = 1024
+)
+
+// rateLimiter is a token bucket per client IP.
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // tokens per second
+	burst   float64
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	return &rateLimiter{
+		rate:    perSecond,
+		burst:   max(1, perSecond*rateBurstSeconds),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow takes a token from client's bucket. When it is empty, wait is how
+// long until the next one.
+func (l *rateLimiter) allow(client string, now time.Time) (ok bool, wait time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, found := l.buckets[client]
+	if !found {
+		if len(l.buckets) >= maxRateClients {
+			l.prune(now)
+		}
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// prune drops the buckets that have refilled, they'd start full anyway.
+func (l *rateLimiter) prune(now time.Time) {
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// SetRateLimit caps API requests per second per client IP; 0 turns the
+// limit off. Call before Start.
+func (s *Server) SetRateLimit(perSecond float64) {
+	if perSecond <= 0 {
+		s.limiter = nil
+		return
+	}
+	s.limiter = newRateLimiter(perSecond)
+}
+
+// limitRate answers 429 to clients over the rate limit. Only /api/ counts;
+// pages, static files and websockets are left alone.
+func (s *Server) limitRate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.limiter == nil || !strings.HasPrefix(r.URL.Path, "/api/") {
+			h.ServeHTTP(w, r)
+			return
+		}
+		client, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			client = r.RemoteAddr
+		}
+		if ok, wait := s.limiter.allow(client, time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			apiError(w, "too many requests, slow down", http.StatusTooManyRequests)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -1,33 +1,62 @@
 package web
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
+	"image/png"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/guidoenr/golizer/internal/analyzer"
 	apppkg "github.com/guidoenr/golizer/internal/app"
+	configpkg "github.com/guidoenr/golizer/internal/config"
+	"github.com/guidoenr/golizer/internal/lyrics"
 	"github.com/guidoenr/golizer/internal/params"
 	"github.com/guidoenr/golizer/internal/render"
+	"github.com/guidoenr/golizer/internal/sink"
 )
 
 type Server struct {
 	mu                sync.RWMutex
 	app               AppInterface
 	clients           map[*websocketClient]bool
+	sseClients        map[chan []byte]bool // /api/v1/events streams
 	broadcast         chan []byte
 	upgrader          websocket.Upgrader
 	lastFeatures      analyzer.Features
 	lastFPS           float64
 	lastStatusPayload []byte
+	kiosk             bool
+	debug             bool
+	mdns              bool
+	socket            string // control socket path, "" when off
+	grpcPort          int    // 0 when off
+	configPath        string // the file saving and importing write
+	token             string
+	fleetToken        string       // sent to peers by the fleet proxy
+	limiter           *rateLimiter // nil when off
+	mux               *http.ServeMux
+	port              int
+	fleet             []FleetPeer   // from the last scan
+	done              chan struct{} // closed on shutdown
+	closeOnce         sync.Once
 }
 
+// shutdownTimeout is how long requests in flight get once the app exits.
+const shutdownTimeout = 5 * time.Second
+
 type AppInterface interface {
 	GetParams() params.Parameters
 	SetParams(p params.Parameters)
@@ -36,12 +65,38 @@ type AppInterface interface {
 	GetFPS() float64
 	GetConfig() apppkg.ConfigGetter
 	SetNoiseFloor(float64)
+	SetNoiseFloors(analyzer.NoiseFloors)
+	SetEnvelope(string, analyzer.Envelope) error
+	SetWords([]string)
+	SetLyrics(lyrics.Track)
+	Lyrics() (mode, text string)
+	Tap(time.Time) float64
+	Trigger(string, time.Duration) error
+	TapTempo() float64
+	MIDIClockTempo() float64
+	WriteGIF(io.Writer, time.Duration) error
+	Snapshot(context.Context) (image.Image, error)
+	ViewFrame() ([]string, uint64, bool)
+	FeatureHistory(int) []analyzer.Features
+	Calibrate(context.Context, time.Duration) (analyzer.NoiseFloors, error)
 	SetBufferSize(int)
 	// SetTargetFPS removed - FPS always unlimited
 	SetDimensions(int, int)
 	SetAutoRandomize(bool)
 	SetRandomInterval(time.Duration)
+	RandomLocks() apppkg.RandomLocks
+	SetRandomLocks(apppkg.RandomLocks)
+	Presets() *apppkg.PresetStore
+	SavePreset(string) (apppkg.Preset, error)
+	LoadPreset(string) error
+	Playlist() (apppkg.Playlist, apppkg.PlaylistState)
+	SetPlaylist(apppkg.Playlist) error
+	PlayPlaylist() error
+	StopPlaylist()
 	SetShowStatusBar(bool)
+	SetPaused(bool)
+	Paused() bool
+	RestartAudio(context.Context, string) (apppkg.AudioInfo, error)
 }
 
 type websocketClient struct {
@@ -51,11 +106,18 @@ type websocketClient struct {
 }
 
 type StatusResponse struct {
-	FPS           float64           `json:"fps"`
-	Features      analyzer.Features `json:"features"` // only for display, not configurable
-	Renderer      RendererStatus    `json:"renderer"`
-	Quality       string            `json:"quality,omitempty"`
-	ShowStatusBar bool              `json:"showStatusBar"`
+	FPS           float64              `json:"fps"`
+	Features      analyzer.Features    `json:"features"` // only for display, not configurable
+	Renderer      RendererStatus       `json:"renderer"`
+	Quality       string               `json:"quality,omitempty"`
+	ShowStatusBar bool                 `json:"showStatusBar"`
+	Envelopes     analyzer.Envelopes   `json:"envelopes"`
+	ReadOnly      bool                 `json:"readOnly,omitempty"`
+	TapTempo      float64              `json:"tapTempo,omitempty"`  // BPM while tap tempo drives the beat clock
+	MIDIClock     float64              `json:"midiClock,omitempty"` // BPM while an incoming MIDI clock drives it
+	RandomLocks   apppkg.RandomLocks   `json:"randomLocks"`
+	Playlist      apppkg.PlaylistState `json:"playlist"`
+	Paused        bool                 `json:"paused"`
 }
 
 type RendererStatus struct {
@@ -72,35 +134,63 @@ type UpdateRequest struct {
 	Quality    *string            `json:"quality,omitempty"`
 	NoiseFloor *float64           `json:"noiseFloor,omitempty"`
 	BufferSize *int               `json:"bufferSize,omitempty"`
+	// Envelopes updates attack/release per band, keyed like analyzer.Envelopes
+	Envelopes map[string]analyzer.Envelope `json:"envelopes,omitempty"`
 	// TargetFPS removed - FPS always unlimited
 	Width          *int  `json:"width,omitempty"`
 	Height         *int  `json:"height,omitempty"`
 	AutoRandomize  *bool `json:"autoRandomize,omitempty"`
 	RandomInterval *int  `json:"randomInterval,omitempty"`
 	ShowStatusBar  *bool `json:"showStatusBar,omitempty"`
+	// RandomLocks replaces what randomize keeps
+	RandomLocks *apppkg.RandomLocks `json:"randomLocks,omitempty"`
+	// Paused holds the current frame on screen or lets it run again
+	Paused *bool `json:"paused,omitempty"`
 }
 
 type SavedConfig struct {
-	Params         params.Parameters `json:"params"`
-	Palette        string            `json:"palette"`
-	Pattern        string            `json:"pattern"`
-	ColorMode      string            `json:"colorMode"`
-	NoiseFloor     float64           `json:"noiseFloor"`
-	BufferSize     int               `json:"bufferSize"`
-	TargetFPS      float64           `json:"targetFPS"`
-	Quality        string            `json:"quality"`
-	Width          int               `json:"width"`
-	Height         int               `json:"height"`
-	AutoRandomize  bool              `json:"autoRandomize"`
-	RandomInterval time.Duration     `json:"randomInterval"`
-	ShowStatusBar  bool              `json:"showStatusBar"`
+	Version        int                   `json:"version"`
+	Params         params.Parameters     `json:"params"`
+	Palette        string                `json:"palette"`
+	Pattern        string                `json:"pattern"`
+	ColorMode      string                `json:"colorMode"`
+	NoiseFloor     float64               `json:"noiseFloor"`
+	NoiseFloors    analyzer.NoiseFloors  `json:"noiseFloors"`
+	Envelopes      analyzer.Envelopes    `json:"envelopes"`
+	BufferSize     int                   `json:"bufferSize"`
+	TargetFPS      float64               `json:"targetFPS"`
+	Quality        string                `json:"quality"`
+	Width          int                   `json:"width"`
+	Height         int                   `json:"height"`
+	AutoRandomize  bool                  `json:"autoRandomize"`
+	RandomInterval time.Duration         `json:"randomInterval"`
+	ShowStatusBar  bool                  `json:"showStatusBar"`
+	Effects        []render.EffectConfig `json:"effects,omitempty"`
+	// ColorCurves holds only the colour modes that differ from the defaults.
+	ColorCurves map[string]render.ColorCurve `json:"colorCurves,omitempty"`
+	// Gradient is left out while it is the default.
+	Gradient []render.GradientStop `json:"gradient,omitempty"`
+	// PatternParams holds the pattern knobs that differ from the defaults.
+	PatternParams map[string]map[string]float64 `json:"patternParams,omitempty"`
+	// Playlist is left out while it has no steps.
+	Playlist *apppkg.Playlist `json:"playlist,omitempty"`
+	// Palettes, OutputProfiles and Widgets are edited by hand; saving from
+	// the panel keeps them.
+	Palettes       map[string]string        `json:"palettes,omitempty"`
+	OutputProfiles map[string][]sink.Config `json:"outputProfiles,omitempty"`
+	Widgets        []apppkg.WidgetConfig    `json:"widgets,omitempty"`
 }
 
 func NewServer(app AppInterface) *Server {
 	return &Server{
-		app:       app,
-		clients:   make(map[*websocketClient]bool),
-		broadcast: make(chan []byte, 256),
+		app:        app,
+		clients:    make(map[*websocketClient]bool),
+		sseClients: make(map[chan []byte]bool),
+		broadcast:  make(chan []byte, 256),
+		mux:        http.NewServeMux(),
+		done:       make(chan struct{}),
+		limiter:    newRateLimiter(defaultRateLimit),
+		configPath: configpkg.Path(""),
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true
@@ -109,6 +199,29 @@ func NewServer(app AppInterface) *Server {
 	}
 }
 
+// SetKiosk makes the panel read-only and keeps the listener alive across
+// errors. Call before Start.
+func (s *Server) SetKiosk(enabled bool) {
+	s.kiosk = enabled
+}
+
+// SetConfigPath sets the config file the panel saves to and imports into,
+// the one the app was started with. Call before Start.
+func (s *Server) SetConfigPath(path string) {
+	s.configPath = path
+}
+
+// mutating rejects writes in kiosk mode; GET requests pass through.
+func (s *Server) mutating(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.kiosk && r.Method != http.MethodGet && !fromSocket(r) {
+			apiError(w, "read-only (kiosk mode)", http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
+
 func findWebDir() string {
 	// try current directory
 	if _, err := os.Stat("web/index.html"); err == nil {
@@ -135,30 +248,130 @@ func findWebDir() string {
 	return "web"
 }
 
-func (s *Server) Start(port int) error {
+// Start serves the panel on port until ctx is cancelled, then shuts down:
+// requests in flight get shutdownTimeout to finish and websockets are
+// closed. It returns nil after a shutdown. In kiosk mode a failing listener
+// is restarted instead of returned. Port 0 serves only the control socket
+// and gRPC.
+func (s *Server) Start(ctx context.Context, port int) error {
 	// find web directory (could be in repo root or relative to binary)
 	webDir := findWebDir()
+	s.port = port
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, webDir+"/index.html")
 	})
-	http.HandleFunc("/api/status", s.handleStatus)
-	http.HandleFunc("/api/update", s.handleUpdate)
-	http.HandleFunc("/api/save", s.handleSave)
-	http.HandleFunc("/api/palettes", s.handlePalettes)
-	http.HandleFunc("/api/patterns", s.handlePatterns)
-	http.HandleFunc("/api/colorModes", s.handleColorModes)
-	http.HandleFunc("/ws", s.handleWebSocket)
-	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir(webDir+"/static"))))
+	s.mountAPI()
+	s.mux.HandleFunc("/view", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, webDir+"/view.html")
+	})
+	s.mux.HandleFunc("/ws", s.handleWebSocket)
+	s.mux.HandleFunc("/ws/view", s.handleView)
+	s.mux.HandleFunc("/ws/spectrum", s.handleSpectrum)
+	s.mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir(webDir+"/static"))))
+	if s.debug {
+		s.mountDebug()
+	}
+
+	go s.broadcastLoop(ctx)
+	go s.statusUpdateLoop(ctx)
+
+	// the control socket and gRPC run next to the panel, or alone with
+	// port 0
+	var side sync.WaitGroup
+	if s.socket != "" {
+		side.Add(1)
+		go func() {
+			defer side.Done()
+			if err := s.serveSocket(ctx); err != nil {
+				log.Printf("[web] control socket: %v", err)
+			}
+		}()
+	}
+	if s.grpcPort > 0 {
+		side.Add(1)
+		go func() {
+			defer side.Done()
+			if err := s.serveGRPC(ctx); err != nil {
+				log.Printf("[web] gRPC: %v", err)
+			}
+		}()
+	}
+	defer side.Wait()
+	if port == 0 {
+		<-ctx.Done()
+		return nil
+	}
 
 	addr := fmt.Sprintf(":%d", port)
 	log.Printf("[web] server starting on http://0.0.0.0%s", addr)
-	log.Printf("[web] access from network: http://golizer.local%s or http://<pi-ip>%s", addr, addr)
+	log.Printf("[web] access from network: http://<pi-ip>%s", addr)
+	if s.token != "" {
+		log.Printf("[web] token required")
+	}
+	if s.debug {
+		who := "anyone on the network can reach them"
+		if s.token != "" {
+			who = "behind the token"
+		}
+		log.Printf("[web] debug endpoints on http://0.0.0.0%s/debug/pprof/ (%s)", addr, who)
+	}
+	if s.mdns {
+		go s.advertise(ctx, port)
+	}
 
-	go s.broadcastLoop()
-	go s.statusUpdateLoop()
+	backoff := time.Second
+	for {
+		err := s.serve(ctx, addr)
+		if err == nil || !s.kiosk {
+			return err
+		}
+		log.Printf("[web] server stopped (%v), restarting in %s", err, backoff)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
 
-	return http.ListenAndServe(addr, nil)
+// serve runs one listener until it fails or ctx is cancelled.
+func (s *Server) serve(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// websockets are hijacked, so Shutdown doesn't wait for them
+	srv.RegisterOnShutdown(s.closeSockets)
+	shutDown := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(shutDown)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("[web] shutdown: %v", err)
+		}
+	})
+	defer stop()
+
+	err := srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		<-shutDown
+		return nil
+	}
+	return err
+}
+
+// closeSockets ends every panel and /view websocket.
+func (s *Server) closeSockets() {
+	s.closeOnce.Do(func() { close(s.done) })
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for client := range s.clients {
+		client.conn.Close()
+	}
 }
 
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
@@ -176,7 +389,7 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	status := s.buildStatusSnapshot()
 	data, err := json.Marshal(status)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to encode status: %v", err), http.StatusInternalServerError)
+		apiError(w, fmt.Sprintf("failed to encode status: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -189,53 +402,38 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	var req UpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apiError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errs := validateUpdate(req); len(errs) > 0 {
+		invalidRequest(w, errs)
+		return
+	}
+
+	if err := s.applyUpdate(req); err != nil {
+		apiError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// applyUpdate carries out a validated update; the fields before a bad
+// envelope band stay applied.
+func (s *Server) applyUpdate(req UpdateRequest) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	// merge params if provided (partial update)
 	if req.Params != nil {
-		currentParams := s.app.GetParams()
-		// merge non-zero values from request into current params
-		if req.Params.Frequency > 0 {
-			currentParams.Frequency = req.Params.Frequency
-		}
-		if req.Params.Amplitude > 0 {
-			currentParams.Amplitude = req.Params.Amplitude
-		}
-		if req.Params.Speed > 0 {
-			currentParams.Speed = req.Params.Speed
-		}
-		if req.Params.Brightness > 0 {
-			currentParams.Brightness = req.Params.Brightness
-		}
-		if req.Params.Contrast > 0 {
-			currentParams.Contrast = req.Params.Contrast
-		}
-		if req.Params.Saturation > 0 {
-			currentParams.Saturation = req.Params.Saturation
-		}
-		if req.Params.BeatSensitivity > 0 {
-			currentParams.BeatSensitivity = req.Params.BeatSensitivity
-		}
-		if req.Params.BassInfluence > 0 {
-			currentParams.BassInfluence = req.Params.BassInfluence
-		}
-		if req.Params.MidInfluence > 0 {
-			currentParams.MidInfluence = req.Params.MidInfluence
-		}
-		if req.Params.TrebleInfluence > 0 {
-			currentParams.TrebleInfluence = req.Params.TrebleInfluence
-		}
+		currentParams := mergeParams(s.app.GetParams(), *req.Params)
 		s.app.SetParams(currentParams)
 	}
 
@@ -265,6 +463,11 @@ func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 	if req.NoiseFloor != nil {
 		s.app.SetNoiseFloor(*req.NoiseFloor)
 	}
+	for band, env := range req.Envelopes {
+		if err := s.app.SetEnvelope(band, env); err != nil {
+			return err
+		}
+	}
 	if req.BufferSize != nil {
 		s.app.SetBufferSize(*req.BufferSize)
 	}
@@ -289,39 +492,67 @@ func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 	if req.ShowStatusBar != nil {
 		s.app.SetShowStatusBar(*req.ShowStatusBar)
 	}
+	if req.RandomLocks != nil {
+		s.app.SetRandomLocks(*req.RandomLocks)
+	}
+	if req.Paused != nil {
+		s.app.SetPaused(*req.Paused)
+	}
+	return nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+// mergeParams copies the non-zero knobs the web panel sends over current so
+// partial payloads never zero out fields the panel doesn't display.
+func mergeParams(currentParams, incoming params.Parameters) params.Parameters {
+	if incoming.Frequency > 0 {
+		currentParams.Frequency = incoming.Frequency
+	}
+	if incoming.Amplitude > 0 {
+		currentParams.Amplitude = incoming.Amplitude
+	}
+	if incoming.Speed > 0 {
+		currentParams.Speed = incoming.Speed
+	}
+	if incoming.Brightness > 0 {
+		currentParams.Brightness = incoming.Brightness
+	}
+	if incoming.Contrast > 0 {
+		currentParams.Contrast = incoming.Contrast
+	}
+	if incoming.Saturation > 0 {
+		currentParams.Saturation = incoming.Saturation
+	}
+	if incoming.BeatSensitivity > 0 {
+		currentParams.BeatSensitivity = incoming.BeatSensitivity
+	}
+	if incoming.SubInfluence > 0 {
+		currentParams.SubInfluence = incoming.SubInfluence
+	}
+	if incoming.BassInfluence > 0 {
+		currentParams.BassInfluence = incoming.BassInfluence
+	}
+	if incoming.LowMidInfluence > 0 {
+		currentParams.LowMidInfluence = incoming.LowMidInfluence
+	}
+	if incoming.MidInfluence > 0 {
+		currentParams.MidInfluence = incoming.MidInfluence
+	}
+	if incoming.HighMidInfluence > 0 {
+		currentParams.HighMidInfluence = incoming.HighMidInfluence
+	}
+	if incoming.TrebleInfluence > 0 {
+		currentParams.TrebleInfluence = incoming.TrebleInfluence
+	}
+	return currentParams
 }
 
 func (s *Server) handleSave(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	s.mu.RLock()
-	renderer := s.app.GetRenderer()
-	currentParams := s.app.GetParams()
-	cfg := s.app.GetConfig()
-	s.mu.RUnlock()
-
-	// get current config from app
-	config := SavedConfig{
-		Params:         currentParams,
-		Palette:        renderer.PaletteName(),
-		Pattern:        renderer.PatternName(),
-		ColorMode:      renderer.ColorModeName(),
-		NoiseFloor:     cfg.NoiseFloor(),
-		BufferSize:     cfg.BufferSize(),
-		TargetFPS:      0, // always unlimited
-		Quality:        cfg.Quality(),
-		Width:          cfg.Width(),
-		Height:         cfg.Height(),
-		AutoRandomize:  cfg.AutoRandomize(),
-		RandomInterval: cfg.RandomInterval(),
-		ShowStatusBar:  cfg.ShowStatusBar(),
-	}
+	config := s.currentConfig()
 
 	// override with values from request if provided
 	var req SavedConfig
@@ -351,39 +582,110 @@ func (s *Server) handleSave(w http.ResponseWriter, r *http.Request) {
 		if req.Height > 0 {
 			config.Height = req.Height
 		}
-		if req.Params.Frequency > 0 {
-			config.Params = req.Params
-		}
+		config.Params = mergeParams(config.Params, req.Params)
 		config.ShowStatusBar = req.ShowStatusBar
 	}
 
 	// save to file
-	configPath := getConfigPath()
-	if err := saveConfig(configPath, config); err != nil {
-		http.Error(w, fmt.Sprintf("failed to save config: %v", err), http.StatusInternalServerError)
+	if err := saveConfig(s.configPath, config); err != nil {
+		apiError(w, fmt.Sprintf("failed to save config: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "saved", "path": configPath})
+	json.NewEncoder(w).Encode(map[string]string{"status": "saved", "path": s.configPath})
 }
 
-func getConfigPath() string {
-	// try to save in same directory as binary
-	if exe, err := os.Executable(); err == nil {
-		exeDir := filepath.Dir(exe)
-		return filepath.Join(exeDir, "golizer-config.json")
+// CalibrateRequest asks the app to measure room noise for a few seconds.
+type CalibrateRequest struct {
+	Seconds float64 `json:"seconds,omitempty"`
+}
+
+func (s *Server) handleCalibrate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CalibrateRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	seconds := req.Seconds
+	if seconds <= 0 {
+		seconds = 5
+	}
+	if seconds > 30 {
+		seconds = 30
+	}
+
+	// not holding s.mu: the render loop keeps running while we listen
+	floors, err := s.app.Calibrate(r.Context(), time.Duration(seconds*float64(time.Second)))
+	if err != nil {
+		apiError(w, fmt.Sprintf("calibration failed: %v", err), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"status": "calibrated", "noiseFloors": floors})
+}
+
+// AudioRestartRequest optionally switches the device or buffer size before
+// the sound card is reopened.
+type AudioRestartRequest struct {
+	Device     string `json:"device,omitempty"`
+	BufferSize int    `json:"bufferSize,omitempty"`
+}
+
+// handleAudioRestart reopens the sound card, applying a new buffer size or
+// device without restarting golizer.
+func (s *Server) handleAudioRestart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AudioRestartRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
-	// fallback to home directory
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".golizer-config.json")
+	if req.BufferSize != 0 {
+		if err := checkBufferSize(req.BufferSize); err != nil {
+			invalidRequest(w, []FieldError{{Field: "bufferSize", Message: err.Error()}})
+			return
+		}
+	}
+	if req.BufferSize > 0 {
+		s.app.SetBufferSize(req.BufferSize)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	info, err := s.app.RestartAudio(ctx, req.Device)
+	if err != nil {
+		apiError(w, fmt.Sprintf("audio restart failed: %v", err), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
 }
 
 func saveConfig(path string, config SavedConfig) error {
+	config.Version = configpkg.Version
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return err
 	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
 	return os.WriteFile(path, data, 0644)
 }
 
@@ -392,8 +694,17 @@ func loadConfig(path string) (*SavedConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	migrated, _, err := configpkg.Migrate(data)
+	if err != nil {
+		if migrated == nil {
+			return nil, err
+		}
+		// a newer build's file: keep the fields this one knows, as the
+		// visualizer does at startup, so a save doesn't drop the rest
+		log.Printf("[web] config: %v (loading known fields only)", err)
+	}
 	var config SavedConfig
-	if err := json.Unmarshal(data, &config); err != nil {
+	if err := json.Unmarshal(migrated, &config); err != nil {
 		return nil, err
 	}
 	return &config, nil
@@ -406,17 +717,454 @@ func (s *Server) handlePalettes(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handlePatterns(w http.ResponseWriter, r *http.Request) {
-	patterns := render.PatternNames()
+	patterns := s.app.GetRenderer().PatternNames()
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(patterns)
 }
 
+// PatternParamsResponse lists the knobs of one pattern, empty for a
+// pattern without any.
+type PatternParamsResponse struct {
+	Pattern string                `json:"pattern"`
+	Params  []render.PatternParam `json:"params"`
+}
+
+// handlePatternParams serves /api/v1/patterns/{name}/params: GET returns the
+// pattern's knobs and PUT/POST sets the ones in a {"name": value} object.
+func (s *Server) handlePatternParams(w http.ResponseWriter, r *http.Request) {
+	renderer := s.app.GetRenderer()
+	name, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, apiPrefix+"/patterns/"), "/")
+	name = strings.ToLower(name)
+	if strings.Trim(rest, "/") != "params" {
+		apiError(w, "not found", http.StatusNotFound)
+		return
+	}
+	if _, err := renderer.PatternParams(name); err != nil {
+		apiError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var values map[string]float64
+		if err := json.NewDecoder(r.Body).Decode(&values); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := renderer.SetPatternParams(name, values); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	knobs, _ := renderer.PatternParams(name)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PatternParamsResponse{Pattern: name, Params: knobs})
+}
+
 func (s *Server) handleColorModes(w http.ResponseWriter, r *http.Request) {
 	modes := render.ColorModeNames()
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(modes)
 }
 
+// handleColorCurve serves /api/v1/colorModes/{name}: GET returns the mode's
+// curve (or every curve without a name), PUT/POST changes the fields present
+// in the body and DELETE restores the default.
+func (s *Server) handleColorCurve(w http.ResponseWriter, r *http.Request) {
+	renderer := s.app.GetRenderer()
+	name := strings.ToLower(strings.Trim(strings.TrimPrefix(r.URL.Path, apiPrefix+"/colorModes/"), "/"))
+	curves := renderer.ColorCurves()
+	if name == "" {
+		if r.Method != http.MethodGet {
+			apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(curves)
+		return
+	}
+	curve, ok := curves[name]
+	if !ok {
+		apiError(w, fmt.Sprintf("unknown color mode %q", name), http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&curve); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := renderer.SetColorCurve(name, curve); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		if err := renderer.ResetColorCurve(name); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		curve = render.DefaultColorCurves()[name]
+	default:
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(curve)
+}
+
+// handleGradient serves /api/v1/gradient: GET returns the stops of the gradient
+// colour mode, PUT/POST replaces them and DELETE restores the default.
+func (s *Server) handleGradient(w http.ResponseWriter, r *http.Request) {
+	renderer := s.app.GetRenderer()
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var stops []render.GradientStop
+		if err := json.NewDecoder(r.Body).Decode(&stops); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if stops == nil {
+			stops = []render.GradientStop{}
+		}
+		if err := renderer.SetGradient(stops); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		renderer.SetGradient(nil)
+	default:
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(renderer.Gradient())
+}
+
+// handlePresets serves /api/v1/presets: GET lists the presets in number key
+// order, POST saves the current look under {"name": ...} and DELETE
+// removes ?name=.
+func (s *Server) handlePresets(w http.ResponseWriter, r *http.Request) {
+	presets := s.app.Presets()
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := s.app.SavePreset(req.Name); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		if err := presets.Delete(r.URL.Query().Get("name")); err != nil {
+			apiError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+	default:
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(presets.List())
+}
+
+// handlePresetLoad serves POST /api/v1/presets/{name}/load, switching to the
+// preset.
+func (s *Server) handlePresetLoad(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, apiPrefix+"/presets/"), "/load")
+	if !ok || name == "" {
+		apiError(w, "not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.app.LoadPreset(name); err != nil {
+		apiError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "preset": name})
+}
+
+// PlaylistResponse is the playlist with where it is at.
+type PlaylistResponse struct {
+	Playlist apppkg.Playlist      `json:"playlist"`
+	State    apppkg.PlaylistState `json:"state"`
+}
+
+// handlePlaylist serves /api/v1/playlist: GET returns the playlist and its
+// state, PUT/POST replaces the playlist.
+func (s *Server) handlePlaylist(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var playlist apppkg.Playlist
+		if err := json.NewDecoder(r.Body).Decode(&playlist); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.app.SetPlaylist(playlist); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.writePlaylist(w)
+}
+
+// handlePlaylistControl serves POST /api/v1/playlist/play and
+// /api/v1/playlist/stop.
+func (s *Server) handlePlaylistControl(w http.ResponseWriter, r *http.Request) {
+	action := strings.TrimPrefix(r.URL.Path, apiPrefix+"/playlist/")
+	if action != "play" && action != "stop" {
+		apiError(w, "not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if action == "stop" {
+		s.app.StopPlaylist()
+	} else if err := s.app.PlayPlaylist(); err != nil {
+		apiError(w, err.Error(), http.StatusConflict)
+		return
+	}
+	s.writePlaylist(w)
+}
+
+func (s *Server) writePlaylist(w http.ResponseWriter) {
+	playlist, state := s.app.Playlist()
+	if playlist.Steps == nil {
+		playlist.Steps = []apppkg.PlaylistStep{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PlaylistResponse{Playlist: playlist, State: state})
+}
+
+// handleOverlay serves /api/v1/overlay: GET returns the text overlay on
+// screen, POST/PUT replaces it and DELETE clears it.
+func (s *Server) handleOverlay(w http.ResponseWriter, r *http.Request) {
+	renderer := s.app.GetRenderer()
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var overlay render.Overlay
+		if err := json.NewDecoder(r.Body).Decode(&overlay); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := renderer.SetOverlay(overlay); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		renderer.ClearOverlay()
+	default:
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(renderer.Overlay())
+}
+
+// customColorCurves drops the curves that still match the defaults, so a
+// saved config keeps following future default tweaks.
+func customColorCurves(curves map[string]render.ColorCurve) map[string]render.ColorCurve {
+	defaults := render.DefaultColorCurves()
+	out := make(map[string]render.ColorCurve)
+	for name, curve := range curves {
+		if curve != defaults[name] {
+			out[name] = curve
+		}
+	}
+	return out
+}
+
+// EffectsResponse lists the post effect pipeline in order together with the
+// valid range of every param.
+type EffectsResponse struct {
+	Effects []render.EffectConfig            `json:"effects"`
+	Limits  map[string]map[string][2]float64 `json:"limits"`
+}
+
+func (s *Server) handleEffects(w http.ResponseWriter, r *http.Request) {
+	renderer := s.app.GetRenderer()
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req []render.EffectConfig
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := renderer.SetEffects(req); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(EffectsResponse{
+		Effects: renderer.Effects(),
+		Limits:  render.EffectLimits(),
+	})
+}
+
+// LyricsRequest sets the flashed text: a word list (one word per beat) or a
+// timed LRC document. An empty request turns the text off.
+type LyricsRequest struct {
+	Words []string `json:"words,omitempty"`
+	LRC   string   `json:"lrc,omitempty"`
+}
+
+// LyricsResponse reports the active source (off, words or lrc) and the
+// text on screen.
+type LyricsResponse struct {
+	Mode string `json:"mode"`
+	Text string `json:"text"`
+}
+
+func (s *Server) handleLyrics(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req LyricsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apiError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.LRC != "" {
+			track, err := lyrics.Parse(strings.NewReader(req.LRC))
+			if err != nil {
+				apiError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			s.app.SetLyrics(track)
+		} else {
+			s.app.SetWords(req.Words)
+		}
+	default:
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	mode, text := s.app.Lyrics()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LyricsResponse{Mode: mode, Text: text})
+}
+
+// handleTap registers one tap-tempo tap and returns the tapped BPM (0 until
+// two taps came in).
+func (s *Server) handleTap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	bpm := s.app.Tap(time.Now())
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]float64{"bpm": bpm})
+}
+
+// TriggerRequest fires a one-shot effect; Duration is in seconds, 0 for the
+// effect's default.
+type TriggerRequest struct {
+	Effect   string  `json:"effect"`
+	Duration float64 `json:"duration,omitempty"`
+}
+
+// handleTrigger serves POST /api/v1/trigger, a flash, strobe, drop or
+// blackout on top of whatever the music does.
+func (s *Server) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req TriggerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.app.Trigger(req.Effect, time.Duration(req.Duration*float64(time.Second))); err != nil {
+		apiError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "effect": req.Effect})
+}
+
+// handleCaptureGIF returns the last seconds (default: the whole buffer) of
+// rendered frames as an animated GIF.
+func (s *Server) handleCaptureGIF(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var window time.Duration
+	if v := r.URL.Query().Get("seconds"); v != "" {
+		secs, err := strconv.ParseFloat(v, 64)
+		if err != nil || secs <= 0 {
+			apiError(w, "seconds must be a positive number", http.StatusBadRequest)
+			return
+		}
+		window = time.Duration(secs * float64(time.Second))
+	}
+	var buf bytes.Buffer
+	if err := s.app.WriteGIF(&buf, window); err != nil {
+		apiError(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+time.Now().Format("golizer-20060102-150405.gif")+`"`)
+	w.Write(buf.Bytes())
+}
+
+// handleSnapshot returns the next rendered frame as a PNG.
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apiError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+	img, err := s.app.Snapshot(ctx)
+	if err != nil {
+		apiError(w, fmt.Sprintf("snapshot: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		apiError(w, fmt.Sprintf("encode snapshot: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(buf.Bytes())
+}
+
 func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -438,8 +1186,14 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	go client.readPump()
 }
 
-func (s *Server) broadcastLoop() {
-	for message := range s.broadcast {
+func (s *Server) broadcastLoop(ctx context.Context) {
+	for {
+		var message []byte
+		select {
+		case <-ctx.Done():
+			return
+		case message = <-s.broadcast:
+		}
 		s.mu.RLock()
 		for client := range s.clients {
 			select {
@@ -449,16 +1203,28 @@ func (s *Server) broadcastLoop() {
 				delete(s.clients, client)
 			}
 		}
+		for events := range s.sseClients {
+			select {
+			case events <- message:
+			default:
+				// a slow stream skips this update
+			}
+		}
 		s.mu.RUnlock()
 	}
 }
 
-func (s *Server) statusUpdateLoop() {
+func (s *Server) statusUpdateLoop(ctx context.Context) {
 	// reduced frequency to 500ms to save CPU/FPS
 	ticker := time.NewTicker(500 * time.Millisecond)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 		s.mu.Lock()
 		s.lastFeatures = s.app.GetFeatures()
 		s.lastFPS = s.app.GetFPS()
@@ -469,6 +1235,7 @@ func (s *Server) statusUpdateLoop() {
 			ColorMode: renderer.ColorModeName(),
 		}
 		cfg := s.app.GetConfig()
+		_, playlistState := s.app.Playlist()
 
 		status := StatusResponse{
 			FPS:           s.lastFPS,
@@ -476,6 +1243,13 @@ func (s *Server) statusUpdateLoop() {
 			Renderer:      currentRenderer,
 			Quality:       cfg.Quality(),
 			ShowStatusBar: cfg.ShowStatusBar(),
+			Envelopes:     cfg.Envelopes(),
+			ReadOnly:      s.kiosk,
+			TapTempo:      s.app.TapTempo(),
+			MIDIClock:     s.app.MIDIClockTempo(),
+			RandomLocks:   s.app.RandomLocks(),
+			Playlist:      playlistState,
+			Paused:        s.app.Paused(),
 		}
 		s.mu.Unlock()
 
@@ -500,6 +1274,7 @@ func (s *Server) buildStatusSnapshot() StatusResponse {
 
 	renderer := s.app.GetRenderer()
 	cfg := s.app.GetConfig()
+	_, playlistState := s.app.Playlist()
 
 	return StatusResponse{
 		FPS:      s.lastFPS,
@@ -511,6 +1286,13 @@ func (s *Server) buildStatusSnapshot() StatusResponse {
 		},
 		Quality:       cfg.Quality(),
 		ShowStatusBar: cfg.ShowStatusBar(),
+		Envelopes:     cfg.Envelopes(),
+		ReadOnly:      s.kiosk,
+		TapTempo:      s.app.TapTempo(),
+		MIDIClock:     s.app.MIDIClockTempo(),
+		RandomLocks:   s.app.RandomLocks(),
+		Playlist:      playlistState,
+		Paused:        s.app.Paused(),
 	}
 }
 
@@ -0,0 +1,188 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	apppkg "github.com/guidoenr/golizer/internal/app"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name, data string
+		palette    string
+		gamma      float64
+		ok         bool
+	}{
+		{"current", `{"version": 2, "palette": "block", "params": {"Gamma": 1.4}}`, "block", 1.4, true},
+		{"older", `{"palette": "dots", "params": {"Gamma": 0}}`, "dots", 1, true},
+		// a newer build's file keeps the fields this build knows
+		{"newer", `{"version": 99, "palette": "block", "params": {"Gamma": 1.2}, "future": true}`, "block", 1.2, true},
+		{"broken", `{"palette": `, "", 0, false},
+	} {
+		path := filepath.Join(dir, tc.name+".json")
+		if err := os.WriteFile(path, []byte(tc.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		config, err := loadConfig(path)
+		if !tc.ok {
+			if err == nil {
+				t.Errorf("%s: loaded %+v, want an error", tc.name, config)
+			}
+			continue
+		}
+		if err != nil || config.Palette != tc.palette || config.Params.Gamma != tc.gamma {
+			t.Errorf("%s: got %+v, %v, want palette %q gamma %v", tc.name, config, err, tc.palette, tc.gamma)
+		}
+	}
+}
+
+func TestServeShutsDownGracefully(t *testing.T) {
+	s := NewServer(nil)
+	started := make(chan struct{})
+	s.mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		io.WriteString(w, "done")
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- s.serve(ctx, addr) }()
+
+	var resp *http.Response
+	got := make(chan error, 1)
+	go func() {
+		for {
+			resp, err = http.Get("http://" + addr + "/slow")
+			if err == nil || ctx.Err() != nil {
+				got <- err
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	<-started
+	cancel()
+
+	// the request in flight finishes, then serve returns nil
+	if err := <-got; err != nil {
+		t.Fatalf("in-flight request: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "done" {
+		t.Errorf("in-flight request got %q", body)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	case <-time.After(shutdownTimeout):
+		t.Fatal("serve did not return")
+	}
+	select {
+	case <-s.done:
+	default:
+		t.Error("websockets were not told to close")
+	}
+	if _, err := http.Get("http://" + addr + "/slow"); err == nil {
+		t.Error("still serving after shutdown")
+	}
+}
+
+func TestDebugBehindToken(t *testing.T) {
+	s := NewServer(fpsApp{})
+	s.SetToken("secret")
+	s.mountDebug()
+	h := s.handler()
+	for _, path := range []string{"/debug/runtime", "/debug/pprof/cmdline"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s without the token: status %d", path, rec.Code)
+		}
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"?token=secret", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s with the token: status %d", path, rec.Code)
+		}
+	}
+}
+
+// restartApp records the audio restarts the panel asks for.
+type restartApp struct {
+	AppInterface
+	bufferSize int
+	device     string
+	err        error
+}
+
+func (a *restartApp) SetBufferSize(n int) { a.bufferSize = n }
+
+func (a *restartApp) RestartAudio(_ context.Context, device string) (apppkg.AudioInfo, error) {
+	a.device = device
+	if a.err != nil {
+		return apppkg.AudioInfo{}, a.err
+	}
+	return apppkg.AudioInfo{Device: "card " + device, SampleRate: 48000, BufferSize: a.bufferSize}, nil
+}
+
+func TestHandleAudioRestart(t *testing.T) {
+	app := &restartApp{}
+	s := NewServer(app)
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.handleAudioRestart(rec, httptest.NewRequest(http.MethodPost, "/api/audio/restart", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post(`{"device": "hw:1", "bufferSize": 512}`)
+	var info apppkg.AudioInfo
+	json.Unmarshal(rec.Body.Bytes(), &info)
+	if rec.Code != http.StatusOK || app.bufferSize != 512 || app.device != "hw:1" || info != (apppkg.AudioInfo{Device: "card hw:1", SampleRate: 48000, BufferSize: 512}) {
+		t.Errorf("restart: status %d, buffer %d, device %q, reply %+v", rec.Code, app.bufferSize, app.device, info)
+	}
+
+	// no body: the same card again
+	if rec := post(""); rec.Code != http.StatusOK || app.device != "" || app.bufferSize != 512 {
+		t.Errorf("empty restart: status %d, buffer %d, device %q", rec.Code, app.bufferSize, app.device)
+	}
+
+	app.device = "untouched"
+	for _, body := range []string{`{"bufferSize": -1}`, `{"device": `} {
+		if rec := post(body); rec.Code != http.StatusBadRequest || app.device != "untouched" {
+			t.Errorf("%s: status %d, device %q", body, rec.Code, app.device)
+		}
+	}
+
+	app.err = errors.New("device busy")
+	if rec := post(`{"device": "hw:2"}`); rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "device busy") {
+		t.Errorf("failed restart: status %d %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleAudioRestart(rec, httptest.NewRequest(http.MethodGet, "/api/audio/restart", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: status %d", rec.Code)
+	}
+}
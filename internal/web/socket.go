@@ -0,0 +1,87 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// socketKey marks requests that came in over the control socket.
+type socketKey struct{}
+
+// SetSocket serves the API on a unix socket at path as well, for local
+// scripts and golizer ctl; it works with the TCP listener disabled (port 0).
+// Who may use it is up to the file's permissions, so the token, the rate
+// limit and kiosk mode don't apply there. Empty turns it off. Call before
+// Start.
+func (s *Server) SetSocket(path string) {
+	s.socket = path
+}
+
+// fromSocket reports whether r came in over the control socket.
+func fromSocket(r *http.Request) bool {
+	return r.Context().Value(socketKey{}) != nil
+}
+
+// listenSocket creates the socket at path, replacing one left behind by an
+// instance that is gone. The file is readable and writable by its owner
+// only.
+func listenSocket(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another instance", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// serveSocket runs the control socket until ctx is cancelled; closing the
+// listener removes the file.
+func (s *Server) serveSocket(ctx context.Context) error {
+	ln, err := listenSocket(s.socket)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return context.WithValue(context.Background(), socketKey{}, true)
+		},
+	}
+	srv.RegisterOnShutdown(s.closeSockets)
+	stop := context.AfterFunc(ctx, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("[web] socket shutdown: %v", err)
+		}
+	})
+	defer stop()
+
+	log.Printf("[web] control socket on %s", s.socket)
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
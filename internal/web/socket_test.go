@@ -0,0 +1,97 @@
+package web
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestListenSocket(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "golizer.sock")
+
+	// one left behind by an instance that is gone
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+
+	ln, err := listenSocket(path)
+	if err != nil {
+		t.Fatalf("stale socket: %v", err)
+	}
+	defer ln.Close()
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("mode %v, %v, want 0600", info.Mode(), err)
+	}
+
+	if _, err := listenSocket(path); err == nil {
+		t.Errorf("took over a socket in use")
+	}
+	file := filepath.Join(dir, "file")
+	os.WriteFile(file, nil, 0o644)
+	if _, err := listenSocket(file); err == nil {
+		t.Errorf("replaced a regular file")
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("regular file: %v", err)
+	}
+}
+
+func TestFromSocket(t *testing.T) {
+	s := NewServer(nil)
+	s.SetSocket(filepath.Join(t.TempDir(), "golizer.sock"))
+	s.mux.HandleFunc("/probe", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strconv.FormatBool(fromSocket(r))))
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- s.serveSocket(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-served; err != nil {
+			t.Errorf("serveSocket: %v", err)
+		}
+	}()
+
+	probe := func(client *http.Client, url string) string {
+		t.Helper()
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var buf [8]byte
+		n, _ := resp.Body.Read(buf[:])
+		return string(buf[:n])
+	}
+	overSocket := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			for {
+				conn, err := d.DialContext(ctx, "unix", s.socket)
+				if err == nil || ctx.Err() != nil {
+					return conn, err
+				}
+				time.Sleep(10 * time.Millisecond) // not listening yet
+			}
+		},
+	}}
+	if got := probe(overSocket, "http://golizer/probe"); got != "true" {
+		t.Errorf("over the socket: fromSocket %s", got)
+	}
+	tcp := httptest.NewServer(s.mux)
+	defer tcp.Close()
+	if got := probe(tcp.Client(), tcp.URL+"/probe"); got != "false" {
+		t.Errorf("over TCP: fromSocket %s", got)
+	}
+}
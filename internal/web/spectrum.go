@@ -0,0 +1,115 @@
+package web
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+const (
+	// spectrumFPS is the default rate of /ws/spectrum; clients may ask for
+	// 1-30 with ?fps=.
+	spectrumFPS    = 20
+	spectrumMaxFPS = 30
+	// envelopePoints is how many buckets the waveform envelope has.
+	envelopePoints = 32
+)
+
+// spectrumMessage is one update of the /ws/spectrum stream, values rounded
+// to three decimals to keep it small.
+type spectrumMessage struct {
+	// Spectrum is the level of each log-spaced band, lowest first, 0-1
+	// against its own recent peak.
+	Spectrum []float64 `json:"spectrum"`
+	// Envelope is the waveform's peak magnitude per bucket, oldest first.
+	Envelope []float64 `json:"envelope"`
+	// Bands are the gated band levels the patterns react to.
+	Bands map[string]float64 `json:"bands"`
+	// Level and Peak are the raw input, before gain and noise floor, as a
+	// share of full scale.
+	Level float64 `json:"level"`
+	Peak  float64 `json:"peak"`
+	// Beat is true when a beat landed since the previous message.
+	Beat bool `json:"beat"`
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+func newSpectrumMessage(f analyzer.Features, beat bool) spectrumMessage {
+	msg := spectrumMessage{
+		Spectrum: make([]float64, len(f.Spectrum)),
+		Envelope: make([]float64, envelopePoints),
+		Bands: map[string]float64{
+			"sub": round3(f.Sub), "bass": round3(f.Bass), "lowMid": round3(f.LowMid),
+			"mid": round3(f.Mid), "highMid": round3(f.HighMid), "treble": round3(f.Treble),
+		},
+		Level: round3(f.Level),
+		Peak:  round3(f.Peak),
+		Beat:  beat,
+	}
+	for i, v := range f.Spectrum {
+		msg.Spectrum[i] = round3(v)
+	}
+	per := len(f.Waveform) / envelopePoints
+	for i := range msg.Envelope {
+		var peak float64
+		for _, v := range f.Waveform[i*per : (i+1)*per] {
+			peak = max(peak, math.Abs(v))
+		}
+		msg.Envelope[i] = round3(peak)
+	}
+	return msg
+}
+
+// handleSpectrum streams the spectrum, a waveform envelope and the band and
+// input levels for the panel's meters.
+func (s *Server) handleSpectrum(w http.ResponseWriter, r *http.Request) {
+	fps := spectrumFPS
+	if v, err := strconv.Atoi(r.URL.Query().Get("fps")); err == nil {
+		fps = max(1, min(spectrumMaxFPS, v))
+	}
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(time.Second / time.Duration(fps))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-s.done:
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+			return
+		case <-ticker.C:
+		}
+		// a beat lasts one frame; look at every frame since the last tick
+		frames := max(1, int(math.Ceil(s.app.GetFPS()/float64(fps))))
+		beat := false
+		for _, f := range s.app.FeatureHistory(frames) {
+			beat = beat || f.Onset
+		}
+		conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		if err := conn.WriteJSON(newSpectrumMessage(s.app.GetFeatures(), beat)); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/guidoenr/golizer/internal/analyzer"
+)
+
+func TestNewSpectrumMessage(t *testing.T) {
+	f := analyzer.Features{Bass: 0.33333, Level: 0.0004, Peak: 0.0006}
+	f.Spectrum[0], f.Spectrum[1] = 0.12345, 1
+	per := len(f.Waveform) / envelopePoints
+	f.Waveform[per+1] = -0.25
+	f.Waveform[per+2] = 0.125
+	f.Waveform[len(f.Waveform)-1] = 0.9
+	msg := newSpectrumMessage(f, true)
+
+	if len(msg.Spectrum) != len(f.Spectrum) || msg.Spectrum[0] != 0.123 || msg.Spectrum[1] != 1 {
+		t.Errorf("spectrum %v", msg.Spectrum)
+	}
+	if len(msg.Envelope) != envelopePoints || msg.Envelope[0] != 0 || msg.Envelope[1] != 0.25 || msg.Envelope[envelopePoints-1] != 0.9 {
+		t.Errorf("envelope %v", msg.Envelope)
+	}
+	if msg.Bands["bass"] != 0.333 || msg.Level != 0 || msg.Peak != 0.001 || !msg.Beat {
+		t.Errorf("got %+v", msg)
+	}
+}
+
+// spectrumApp feeds the stream a frame history with one beat in it.
+type spectrumApp struct {
+	AppInterface
+}
+
+func (spectrumApp) GetFPS() float64 { return 60 }
+
+func (spectrumApp) GetFeatures() analyzer.Features {
+	f := analyzer.Features{Bass: 0.5}
+	f.Spectrum[0] = 0.5
+	return f
+}
+
+func (spectrumApp) FeatureHistory(n int) []analyzer.Features {
+	h := make([]analyzer.Features, n)
+	h[n-1].Onset = true
+	return h
+}
+
+func TestSpectrumStream(t *testing.T) {
+	s := NewServer(spectrumApp{})
+	ts := httptest.NewServer(http.HandlerFunc(s.handleSpectrum))
+	defer ts.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"?fps=30", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var msg spectrumMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if !msg.Beat || msg.Bands["bass"] != 0.5 || msg.Spectrum[0] != 0.5 {
+		t.Errorf("got %+v", msg)
+	}
+
+	close(s.done)
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+				t.Errorf("got %v, want going away", err)
+			}
+			break
+		}
+	}
+}
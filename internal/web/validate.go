@@ -0,0 +1,121 @@
+package web
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/guidoenr/golizer/internal/params"
+	"github.com/guidoenr/golizer/internal/render"
+)
+
+// Limits on the settings /api/update changes besides the params.
+const (
+	minBufferSize     = 256
+	maxBufferSize     = 16384
+	minWidth          = 10
+	maxWidth          = 4096 // sdl resolutions, not just terminal columns
+	minHeight         = 5
+	maxHeight         = 2160
+	maxNoiseFloor     = 1
+	minRandomInterval = 1
+	maxRandomInterval = 3600
+	maxPatternLength  = 4200 // room for the longest expr: formula
+)
+
+// FieldError is one rejected field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// invalidRequest replies 400 with every rejected field.
+func invalidRequest(w http.ResponseWriter, fields []FieldError) {
+	writeAPIError(w, APIError{
+		Status:  http.StatusBadRequest,
+		Message: fmt.Sprintf("%d invalid field(s)", len(fields)),
+		Fields:  fields,
+	})
+}
+
+// validateUpdate checks every field of req against its range. The params
+// left at zero are not sent and aren't checked.
+func validateUpdate(req UpdateRequest) []FieldError {
+	var errs []FieldError
+	if req.Params != nil {
+		v := reflect.ValueOf(*req.Params)
+		for _, name := range slices.Sorted(maps.Keys(params.Ranges())) {
+			value := v.FieldByName(name).Float()
+			if value == 0 {
+				continue
+			}
+			if err := params.Check(name, value); err != nil {
+				errs = append(errs, FieldError{Field: "params." + name, Message: err.Error()})
+			}
+		}
+	}
+	if req.Pattern != nil {
+		if err := checkPattern(*req.Pattern); err != nil {
+			errs = append(errs, FieldError{Field: "pattern", Message: err.Error()})
+		}
+	}
+	if req.Palette != nil && !slices.Contains(render.PaletteNames(), *req.Palette) {
+		errs = append(errs, FieldError{Field: "palette", Message: fmt.Sprintf("unknown palette %q", *req.Palette)})
+	}
+	if req.ColorMode != nil && !slices.Contains(render.ColorModeNames(), *req.ColorMode) {
+		errs = append(errs, FieldError{Field: "colorMode", Message: fmt.Sprintf("unknown color mode %q", *req.ColorMode)})
+	}
+	if req.Quality != nil && !slices.Contains(render.QualityModeNames(), *req.Quality) {
+		errs = append(errs, FieldError{Field: "quality", Message: fmt.Sprintf("unknown quality %q", *req.Quality)})
+	}
+	if req.NoiseFloor != nil && (math.IsNaN(*req.NoiseFloor) || *req.NoiseFloor < 0 || *req.NoiseFloor > maxNoiseFloor) {
+		errs = append(errs, FieldError{Field: "noiseFloor", Message: fmt.Sprintf("must be between 0 and %d", maxNoiseFloor)})
+	}
+	if req.BufferSize != nil {
+		if err := checkBufferSize(*req.BufferSize); err != nil {
+			errs = append(errs, FieldError{Field: "bufferSize", Message: err.Error()})
+		}
+	}
+	if req.Width != nil && (*req.Width < minWidth || *req.Width > maxWidth) {
+		errs = append(errs, FieldError{Field: "width", Message: fmt.Sprintf("must be between %d and %d", minWidth, maxWidth)})
+	}
+	if req.Height != nil && (*req.Height < minHeight || *req.Height > maxHeight) {
+		errs = append(errs, FieldError{Field: "height", Message: fmt.Sprintf("must be between %d and %d", minHeight, maxHeight)})
+	}
+	if req.RandomInterval != nil && (*req.RandomInterval < minRandomInterval || *req.RandomInterval > maxRandomInterval) {
+		errs = append(errs, FieldError{Field: "randomInterval", Message: fmt.Sprintf("must be between %d and %d seconds", minRandomInterval, maxRandomInterval)})
+	}
+	return errs
+}
+
+// checkPattern accepts the pattern names the renderer knows: built-in and
+// plugin patterns, layers of them, valid expr: formulas and lua: and
+// shader: scripts, which the renderer looks up itself.
+func checkPattern(name string) error {
+	key := strings.ToLower(strings.TrimSpace(name))
+	switch {
+	case len(name) > maxPatternLength:
+		return fmt.Errorf("longer than %d characters", maxPatternLength)
+	case strings.HasPrefix(key, render.ExprPrefix):
+		return render.CheckExpr(render.ExprPrefix + strings.TrimSpace(name)[len(render.ExprPrefix):])
+	case strings.HasPrefix(key, render.LuaPrefix), strings.HasPrefix(key, render.ShaderPrefix):
+		return nil
+	case strings.Contains(key, "+"):
+		return render.CheckLayers(key)
+	case !slices.Contains(render.PatternNames(), key):
+		return fmt.Errorf("unknown pattern %q", name)
+	}
+	return nil
+}
+
+// checkBufferSize accepts FFT buffer sizes the analyzer copes with.
+func checkBufferSize(n int) error {
+	if n < minBufferSize || n > maxBufferSize {
+		return fmt.Errorf("must be between %d and %d", minBufferSize, maxBufferSize)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package web
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckPattern(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want string // "" for valid
+	}{
+		{"ripple", ""},
+		{"Plasma", ""},
+		{"ripple+spark:screen@0.5", ""},
+		{"expr:sin(r*8 - t*3) * bass", ""},
+		{"EXPR:x", ""},
+		{"lua:mine", ""},
+		{"shader:tunnel", ""},
+		{"nope", `unknown pattern "nope"`},
+		{"ripple+nope", "layers: unknown pattern"},
+		{"expr:(x", "expected )"},
+		{"expr:" + strings.Repeat("(", 100) + "x" + strings.Repeat(")", 100), "nested deeper than 64"},
+		{"expr:" + strings.Repeat("(", 5_000_000) + "x", "longer than 4200 characters"},
+	} {
+		err := checkPattern(tc.name)
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("%.40q: %v", tc.name, err)
+		case tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)):
+			t.Errorf("%.40q: got %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestValidateUpdateLook(t *testing.T) {
+	pattern, palette := "nope", "comic"
+	errs := validateUpdate(UpdateRequest{Pattern: &pattern, Palette: &palette})
+	if len(errs) != 2 || errs[0].Field != "pattern" || errs[1].Field != "palette" {
+		t.Fatalf("errors %+v, want pattern and palette", errs)
+	}
+	pattern, palette = "ripple", "braille"
+	if errs := validateUpdate(UpdateRequest{Pattern: &pattern, Palette: &palette}); len(errs) != 0 {
+		t.Fatalf("errors %+v for a valid look", errs)
+	}
+}
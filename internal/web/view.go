@@ -0,0 +1,92 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// viewFPS is the default rate frames are streamed to /view at; clients
+	// may ask for 1-30 with ?fps=.
+	viewFPS    = 15
+	viewMaxFPS = 30
+)
+
+// viewMessage is one update of the /ws/view stream. Rows holds only the
+// rows that changed since the previous message, keyed by row index.
+type viewMessage struct {
+	Seq    uint64         `json:"seq"`
+	Height int            `json:"height"`
+	Rows   map[int]string `json:"rows,omitempty"`
+	// Image tells the page the backend draws pixels; it polls
+	// /api/snapshot.png instead.
+	Image bool `json:"image,omitempty"`
+}
+
+// handleView streams the rendered terminal frames, ANSI colours included,
+// to one /view page. Each connection keeps the rows it sent last and only
+// sends the ones that changed.
+func (s *Server) handleView(w http.ResponseWriter, r *http.Request) {
+	fps := viewFPS
+	if v, err := strconv.Atoi(r.URL.Query().Get("fps")); err == nil {
+		fps = max(1, min(viewMaxFPS, v))
+	}
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("[web] view upgrade error: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// the page never sends anything; reading notices when it goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(time.Second / time.Duration(fps))
+	defer ticker.Stop()
+	var sent []string
+	var lastSeq uint64
+	for {
+		select {
+		case <-closed:
+			return
+		case <-s.done:
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+			return
+		case <-ticker.C:
+		}
+		lines, seq, ok := s.app.ViewFrame()
+		if !ok {
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			conn.WriteJSON(viewMessage{Image: true})
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		}
+		if seq == lastSeq {
+			continue
+		}
+		lastSeq = seq
+		msg := viewMessage{Seq: seq, Height: len(lines), Rows: make(map[int]string)}
+		for i, line := range lines {
+			if i >= len(sent) || sent[i] != line {
+				msg.Rows[i] = line
+			}
+		}
+		sent = lines
+		conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		if err := conn.WriteJSON(msg); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// viewApp serves the frames the view tests set.
+type viewApp struct {
+	AppInterface
+	mu    sync.Mutex
+	lines []string
+	seq   uint64
+	image bool
+}
+
+func (a *viewApp) ViewFrame() ([]string, uint64, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.lines, a.seq, !a.image
+}
+
+func (a *viewApp) show(lines ...string) {
+	a.mu.Lock()
+	a.lines = lines
+	a.seq++
+	a.mu.Unlock()
+}
+
+func dialView(t *testing.T, s *Server) *websocket.Conn {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(s.handleView))
+	t.Cleanup(ts.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"?fps=30", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+func TestViewSendsChangedRows(t *testing.T) {
+	app := &viewApp{}
+	app.show("a", "b", "c")
+	conn := dialView(t, NewServer(app))
+
+	var msg viewMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Seq != 1 || msg.Height != 3 || len(msg.Rows) != 3 || msg.Rows[0] != "a" || msg.Rows[2] != "c" {
+		t.Fatalf("first frame: got %+v", msg)
+	}
+
+	app.show("a", "B", "c", "d")
+	msg = viewMessage{}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Seq != 2 || msg.Height != 4 || len(msg.Rows) != 2 || msg.Rows[1] != "B" || msg.Rows[3] != "d" {
+		t.Fatalf("second frame: got %+v, want rows 1 and 3 only", msg)
+	}
+}
+
+func TestViewImageBackend(t *testing.T) {
+	conn := dialView(t, NewServer(&viewApp{image: true}))
+	var msg viewMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if !msg.Image {
+		t.Fatalf("got %+v, want Image", msg)
+	}
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Fatalf("got %v, want a normal close", err)
+	}
+}
+
+func TestViewClosesOnShutdown(t *testing.T) {
+	app := &viewApp{}
+	app.show("a")
+	s := NewServer(app)
+	conn := dialView(t, s)
+	var msg viewMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	close(s.done)
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("got %v, want going away", err)
+	}
+}
@@ -0,0 +1,36 @@
+// Package pattern is the contract of pattern plugins, the part of the
+// renderer's state a plugin built outside this repository can see. A
+// plugin is a package main built with -buildmode=plugin that exports
+//
+//	func Pattern(x, y float64, p pattern.Params, t float64) float64
+//
+// returning the intensity at x, y (centred on 0, negative leaves the cell
+// black, NaN and Inf count as 0), and optionally a DetailMix float64 (0-1,
+// how much fine noise the renderer mixes in). See examples/plugins/wobble.
+package pattern
+
+// Params are the live values a pattern is drawn with. They follow the
+// audio and the panel's sliders, so a pattern that scales its shape by
+// them reacts to the music like the built-in ones.
+type Params struct {
+	// Time is the animation clock in seconds, sped up and slowed down by
+	// Speed; it is the t a pattern is called with.
+	Time float64
+	// Frequency is how dense the built-in patterns draw their waves and
+	// rings.
+	Frequency float64
+	// Amplitude follows the bass.
+	Amplitude float64
+	// Speed is the clock's rate.
+	Speed float64
+	// Scale is the zoom, 1 at rest.
+	Scale float64
+	// ColorShift moves the palette, in radians.
+	ColorShift float64
+	// BeatDistortion jumps to 1 on a beat (more on a drop) and decays.
+	BeatDistortion float64
+	// BeatZoom is the zoom pulse of a beat.
+	BeatZoom float64
+	// DistortAmplitude is how far the coordinates are warped.
+	DistortAmplitude float64
+}
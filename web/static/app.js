@@ -1,12 +1,22 @@
 let ws = null;
 let statusInterval = null;
+// after this many websockets that never opened, status comes over
+// server-sent events instead
+const WS_MAX_FAILURES = 2;
+let wsFailures = 0;
+let events = null;
 let updateTimeout = null;
 const STATUS_POLL_INTERVAL = 1500;
 
 // initialize
 document.addEventListener("DOMContentLoaded", () => {
 	loadOptions();
+	loadEffects();
+	loadGradient();
+	loadPresets();
+	loadPlaylist();
 	connectWebSocket();
+	connectSpectrum();
 	setupControls();
 	startStatusPolling();
 });
@@ -15,9 +25,9 @@ document.addEventListener("DOMContentLoaded", () => {
 async function loadOptions() {
 	try {
 		const [palettes, patterns, colorModes] = await Promise.all([
-			fetch("/api/palettes").then((r) => r.json()),
-			fetch("/api/patterns").then((r) => r.json()),
-			fetch("/api/colorModes").then((r) => r.json()),
+			fetch("/api/v1/palettes").then((r) => r.json()),
+			fetch("/api/v1/patterns").then((r) => r.json()),
+			fetch("/api/v1/colorModes").then((r) => r.json()),
 		]);
 
 		populateSelect("palette", palettes);
@@ -66,14 +76,535 @@ function populateSelect(id, options) {
 	}
 }
 
+// post effect pipeline
+let effectsState = [];
+let effectLimits = {};
+let effectsTimeout = null;
+
+async function loadEffects() {
+	try {
+		const data = await fetch("/api/v1/effects").then((r) => r.json());
+		effectsState = data.effects || [];
+		effectLimits = data.limits || {};
+		renderEffects();
+	} catch (err) {
+		console.error("failed to load effects:", err);
+	}
+}
+
+function renderEffects() {
+	const container = document.getElementById("effects-list");
+	if (!container) return;
+	container.innerHTML = "";
+
+	effectsState.forEach((effect, index) => {
+		const group = document.createElement("div");
+		group.className = "control-group effect";
+
+		const header = document.createElement("label");
+		const toggle = document.createElement("input");
+		toggle.type = "checkbox";
+		toggle.checked = effect.enabled;
+		toggle.addEventListener("change", () => {
+			effect.enabled = toggle.checked;
+			sendEffects();
+		});
+		header.appendChild(toggle);
+		header.appendChild(document.createTextNode(" " + effect.name + " "));
+
+		const up = document.createElement("button");
+		up.className = "effect-move";
+		up.textContent = "▲";
+		up.disabled = index === 0;
+		up.addEventListener("click", () => moveEffect(index, -1));
+		const down = document.createElement("button");
+		down.className = "effect-move";
+		down.textContent = "▼";
+		down.disabled = index === effectsState.length - 1;
+		down.addEventListener("click", () => moveEffect(index, 1));
+		header.appendChild(up);
+		header.appendChild(down);
+		group.appendChild(header);
+
+		const limits = effectLimits[effect.name] || {};
+		Object.keys(effect.params || {})
+			.sort()
+			.forEach((param) => {
+				const [min, max] = limits[param] || [0, 1];
+				const label = document.createElement("label");
+				const value = document.createElement("span");
+				value.textContent = effect.params[param].toFixed(2);
+				label.textContent = param + " ";
+				label.appendChild(value);
+
+				const input = document.createElement("input");
+				input.type = "range";
+				input.min = min;
+				input.max = max;
+				input.step = (max - min) / 100;
+				input.value = effect.params[param];
+				input.addEventListener("input", () => {
+					effect.params[param] = parseFloat(input.value);
+					value.textContent = effect.params[param].toFixed(2);
+					clearTimeout(effectsTimeout);
+					effectsTimeout = setTimeout(sendEffects, 100);
+				});
+				group.appendChild(label);
+				group.appendChild(input);
+			});
+
+		container.appendChild(group);
+	});
+}
+
+function moveEffect(index, delta) {
+	const target = index + delta;
+	if (target < 0 || target >= effectsState.length) return;
+	const [effect] = effectsState.splice(index, 1);
+	effectsState.splice(target, 0, effect);
+	renderEffects();
+	sendEffects();
+}
+
+function sendEffects() {
+	fetch("/api/v1/effects", {
+		method: "POST",
+		headers: { "Content-Type": "application/json" },
+		body: JSON.stringify(effectsState),
+	}).catch((err) => console.error("effects update failed:", err));
+}
+
+// knobs of the current pattern
+let patternParamsName = null;
+let patternParamsTimeout = null;
+
+async function loadPatternParams(name) {
+	if (!name || name === patternParamsName) return;
+	patternParamsName = name;
+	const container = document.getElementById("pattern-params");
+	if (!container) return;
+	container.innerHTML = "";
+	let data;
+	try {
+		const res = await fetch(`/api/v1/patterns/${encodeURIComponent(name)}/params`);
+		if (!res.ok) return;
+		data = await res.json();
+	} catch (err) {
+		console.error("failed to load pattern params:", err);
+		return;
+	}
+	if (data.pattern !== patternParamsName) return;
+
+	const values = {};
+	(data.params || []).forEach((param) => {
+		values[param.name] = param.value;
+		const group = document.createElement("div");
+		group.className = "control-group";
+		const label = document.createElement("label");
+		const value = document.createElement("span");
+		value.textContent = param.value.toFixed(2);
+		label.textContent = param.name + " ";
+		label.appendChild(value);
+
+		const input = document.createElement("input");
+		input.type = "range";
+		input.min = param.min;
+		input.max = param.max;
+		input.step = (param.max - param.min) / 100;
+		input.value = param.value;
+		input.addEventListener("input", () => {
+			values[param.name] = parseFloat(input.value);
+			value.textContent = values[param.name].toFixed(2);
+			clearTimeout(patternParamsTimeout);
+			patternParamsTimeout = setTimeout(
+				() => sendPatternParams(data.pattern, values),
+				100,
+			);
+		});
+		group.appendChild(label);
+		group.appendChild(input);
+		container.appendChild(group);
+	});
+}
+
+function sendPatternParams(name, values) {
+	fetch(`/api/v1/patterns/${encodeURIComponent(name)}/params`, {
+		method: "POST",
+		headers: { "Content-Type": "application/json" },
+		body: JSON.stringify(values),
+	}).catch((err) => console.error("pattern params update failed:", err));
+}
+
+// presets, in number key order
+let presetNames = [];
+
+async function loadPresets() {
+	try {
+		renderPresets(await fetch("/api/v1/presets").then((r) => r.json()));
+	} catch (err) {
+		console.error("failed to load presets:", err);
+	}
+}
+
+function renderPresets(presets) {
+	presetNames = (presets || []).map((preset) => preset.name);
+	renderPlaylist();
+	const container = document.getElementById("presets-list");
+	if (!container) return;
+	container.innerHTML = "";
+
+	(presets || []).forEach((preset, index) => {
+		const btn = document.createElement("button");
+		btn.className = "option-btn";
+		btn.textContent = index < 9 ? `${index + 1} ${preset.name}` : preset.name;
+		btn.title = `${preset.pattern} / ${preset.palette} / ${preset.colorMode}`;
+		btn.addEventListener("click", () => {
+			fetch(`/api/v1/presets/${encodeURIComponent(preset.name)}/load`, {
+				method: "POST",
+			})
+				.then(fetchStatusSnapshot)
+				.catch((err) => console.error("preset load failed:", err));
+			if (document.getElementById("fleetPresets")?.checked) {
+				fleetApplyPreset(preset);
+			}
+		});
+
+		const remove = document.createElement("button");
+		remove.className = "effect-move";
+		remove.textContent = "✕";
+		remove.title = "delete " + preset.name;
+		remove.addEventListener("click", () => {
+			if (!confirm(`delete preset "${preset.name}"?`)) return;
+			fetch(`/api/v1/presets?name=${encodeURIComponent(preset.name)}`, {
+				method: "DELETE",
+			})
+				.then((r) => r.json())
+				.then(renderPresets)
+				.catch((err) => console.error("preset delete failed:", err));
+		});
+
+		container.appendChild(btn);
+		container.appendChild(remove);
+	});
+}
+
+function savePreset() {
+	const input = document.getElementById("presetName");
+	const name = input.value.trim();
+	if (!name) return;
+	fetch("/api/v1/presets", {
+		method: "POST",
+		headers: { "Content-Type": "application/json" },
+		body: JSON.stringify({ name }),
+	})
+		.then((r) => r.json())
+		.then((presets) => {
+			input.value = "";
+			renderPresets(presets);
+		})
+		.catch((err) => console.error("preset save failed:", err));
+}
+
+// other instances on the network
+let fleetPeers = [];
+const fleetUnchecked = new Set();
+
+async function scanFleet() {
+	const button = document.getElementById("fleetScan");
+	button.disabled = true;
+	try {
+		fleetPeers = await fetch("/api/v1/fleet").then((r) => r.json());
+		renderFleet();
+	} catch (err) {
+		console.error("fleet scan failed:", err);
+	} finally {
+		button.disabled = false;
+	}
+}
+
+function renderFleet() {
+	const container = document.getElementById("fleet-list");
+	if (!container) return;
+	container.innerHTML = "";
+	if (fleetPeers.length === 0) {
+		container.textContent = "no other screens found";
+		return;
+	}
+	fleetPeers.forEach((peer) => {
+		const row = document.createElement("div");
+		row.className = "effect";
+		const label = document.createElement("label");
+		const check = document.createElement("input");
+		check.type = "checkbox";
+		check.checked = !peer.self && !fleetUnchecked.has(peer.name);
+		check.disabled = peer.self;
+		check.addEventListener("change", () => {
+			if (check.checked) fleetUnchecked.delete(peer.name);
+			else fleetUnchecked.add(peer.name);
+		});
+		const link = document.createElement("a");
+		link.href = `http://${peer.addr}/`;
+		link.target = "_blank";
+		link.textContent = peer.self ? `${peer.name} (this one)` : peer.name;
+		label.appendChild(check);
+		label.appendChild(link);
+		row.appendChild(label);
+		container.appendChild(row);
+	});
+}
+
+// fleetApplyPreset sends the preset's look to the checked screens, so it
+// works whether or not they have a preset of that name
+function fleetApplyPreset(preset) {
+	const peers = fleetPeers
+		.filter((peer) => !peer.self && !fleetUnchecked.has(peer.name))
+		.map((peer) => peer.name);
+	if (peers.length === 0) return;
+	fetch("/api/v1/fleet/proxy", {
+		method: "POST",
+		headers: { "Content-Type": "application/json" },
+		body: JSON.stringify({
+			peers,
+			path: "/api/update",
+			body: {
+				params: preset.params,
+				palette: preset.palette,
+				pattern: preset.pattern,
+				colorMode: preset.colorMode,
+			},
+		}),
+	})
+		.then((r) => r.json())
+		.then((results) =>
+			results
+				.filter((res) => res.error)
+				.forEach((res) => console.error(`fleet: ${res.peer}: ${res.error}`)),
+		)
+		.catch((err) => console.error("fleet preset failed:", err));
+}
+
+// playlist of presets
+let playlistState = { steps: [], autoplay: false };
+let playlistStep = -1;
+let playlistTimeout = null;
+
+async function loadPlaylist() {
+	try {
+		const data = await fetch("/api/v1/playlist").then((r) => r.json());
+		playlistState = data.playlist;
+		playlistStep = data.state.playing ? data.state.step : -1;
+		renderPlaylist();
+	} catch (err) {
+		console.error("failed to load playlist:", err);
+	}
+}
+
+function renderPlaylist() {
+	const container = document.getElementById("playlist-steps");
+	if (!container) return;
+	container.innerHTML = "";
+	document.getElementById("playlistAutoplay").checked = playlistState.autoplay;
+
+	playlistState.steps.forEach((step, index) => {
+		const group = document.createElement("div");
+		group.className = "control-group effect";
+		if (index === playlistStep) group.classList.add("active");
+
+		const preset = document.createElement("select");
+		const names = presetNames.includes(step.preset)
+			? presetNames
+			: [step.preset, ...presetNames];
+		names.forEach((name) => {
+			const option = document.createElement("option");
+			option.value = name;
+			option.textContent = name;
+			preset.appendChild(option);
+		});
+		preset.value = step.preset;
+		preset.addEventListener("change", () => {
+			step.preset = preset.value;
+			queuePlaylist();
+		});
+
+		const length = document.createElement("input");
+		length.type = "number";
+		length.min = 1;
+		length.value = step.bars || step.seconds;
+		const unit = document.createElement("select");
+		["bars", "seconds"].forEach((name) => {
+			const option = document.createElement("option");
+			option.value = name;
+			option.textContent = name;
+			unit.appendChild(option);
+		});
+		unit.value = step.bars ? "bars" : "seconds";
+		const setLength = () => {
+			const value = Math.max(1, parseFloat(length.value) || 1);
+			delete step.bars;
+			delete step.seconds;
+			step[unit.value] = unit.value === "bars" ? Math.round(value) : value;
+			queuePlaylist();
+		};
+		length.addEventListener("input", setLength);
+		unit.addEventListener("change", setLength);
+
+		const remove = document.createElement("button");
+		remove.className = "effect-move";
+		remove.textContent = "✕";
+		remove.addEventListener("click", () => {
+			playlistState.steps.splice(index, 1);
+			renderPlaylist();
+			sendPlaylist();
+		});
+
+		const label = document.createElement("label");
+		label.textContent = `${index + 1} `;
+		label.appendChild(remove);
+		group.appendChild(label);
+		group.appendChild(preset);
+		group.appendChild(length);
+		group.appendChild(unit);
+		container.appendChild(group);
+	});
+}
+
+function queuePlaylist() {
+	clearTimeout(playlistTimeout);
+	playlistTimeout = setTimeout(sendPlaylist, 300);
+}
+
+function sendPlaylist() {
+	const steps = playlistState.steps.filter((step) => step.preset);
+	fetch("/api/v1/playlist", {
+		method: "PUT",
+		headers: { "Content-Type": "application/json" },
+		body: JSON.stringify({ ...playlistState, steps }),
+	}).catch((err) => console.error("playlist update failed:", err));
+}
+
+function playlistControl(action) {
+	fetch(`/api/v1/playlist/${action}`, { method: "POST" })
+		.then(loadPlaylist)
+		.catch((err) => console.error(`playlist ${action} failed:`, err));
+}
+
+// gradient colour mode
+let gradientStops = [];
+let gradientTimeout = null;
+
+async function loadGradient() {
+	try {
+		gradientStops = await fetch("/api/v1/gradient").then((r) => r.json());
+		renderGradient();
+	} catch (err) {
+		console.error("failed to load gradient:", err);
+	}
+	document.getElementById("gradient-add")?.addEventListener("click", () => {
+		if (gradientStops.length >= 16) return;
+		gradientStops.push({ color: "#ffffff", pos: 1 });
+		renderGradient();
+		sendGradient();
+	});
+	document.getElementById("gradient-reset")?.addEventListener("click", () => {
+		fetch("/api/v1/gradient", { method: "DELETE" })
+			.then((r) => r.json())
+			.then((stops) => {
+				gradientStops = stops;
+				renderGradient();
+			})
+			.catch((err) => console.error("gradient reset failed:", err));
+	});
+}
+
+function renderGradient() {
+	const container = document.getElementById("gradient-stops");
+	if (!container) return;
+	container.innerHTML = "";
+	updateGradientPreview();
+
+	gradientStops.forEach((stop, index) => {
+		const row = document.createElement("div");
+		row.className = "gradient-stop";
+
+		const color = document.createElement("input");
+		color.type = "color";
+		color.value = stop.color.length === 4 ? expandHex(stop.color) : stop.color;
+		color.addEventListener("input", () => {
+			stop.color = color.value;
+			updateGradientPreview();
+			queueGradient();
+		});
+
+		const pos = document.createElement("input");
+		pos.type = "range";
+		pos.min = 0;
+		pos.max = 1;
+		pos.step = 0.01;
+		pos.value = stop.pos;
+		const value = document.createElement("span");
+		value.textContent = stop.pos.toFixed(2);
+		pos.addEventListener("input", () => {
+			stop.pos = parseFloat(pos.value);
+			value.textContent = stop.pos.toFixed(2);
+			updateGradientPreview();
+			queueGradient();
+		});
+
+		const remove = document.createElement("button");
+		remove.className = "effect-move";
+		remove.textContent = "✕";
+		remove.disabled = gradientStops.length <= 2;
+		remove.addEventListener("click", () => {
+			gradientStops.splice(index, 1);
+			renderGradient();
+			sendGradient();
+		});
+
+		row.appendChild(color);
+		row.appendChild(pos);
+		row.appendChild(value);
+		row.appendChild(remove);
+		container.appendChild(row);
+	});
+}
+
+function expandHex(hex) {
+	return "#" + [...hex.slice(1)].map((c) => c + c).join("");
+}
+
+function updateGradientPreview() {
+	const preview = document.getElementById("gradient-preview");
+	if (!preview) return;
+	const stops = [...gradientStops]
+		.sort((a, b) => a.pos - b.pos)
+		.map((stop) => `${stop.color} ${(stop.pos * 100).toFixed(1)}%`);
+	preview.style.background = `linear-gradient(to right, ${stops.join(", ")})`;
+}
+
+function queueGradient() {
+	clearTimeout(gradientTimeout);
+	gradientTimeout = setTimeout(sendGradient, 100);
+}
+
+function sendGradient() {
+	fetch("/api/v1/gradient", {
+		method: "PUT",
+		headers: { "Content-Type": "application/json" },
+		body: JSON.stringify(gradientStops),
+	}).catch((err) => console.error("gradient update failed:", err));
+}
+
 // websocket connection
 function connectWebSocket() {
 	const protocol = window.location.protocol === "https:" ? "wss:" : "ws:";
 	const wsUrl = `${protocol}//${window.location.host}/ws`;
 
 	ws = new WebSocket(wsUrl);
+	let opened = false;
 
 	ws.onopen = () => {
+		opened = true;
+		wsFailures = 0;
 		updateConnectionStatus("connected");
 		stopStatusPolling();
 	};
@@ -95,10 +626,121 @@ function connectWebSocket() {
 	ws.onclose = () => {
 		updateConnectionStatus("disconnected");
 		startStatusPolling();
+		if (!opened && ++wsFailures >= WS_MAX_FAILURES) {
+			connectEvents();
+			return;
+		}
 		setTimeout(connectWebSocket, 2000);
 	};
 }
 
+// server-sent events: the same status updates over plain http, for
+// proxies and browsers that break websockets. EventSource reconnects by
+// itself; polling covers the gaps.
+function connectEvents() {
+	if (events || typeof EventSource === "undefined") return;
+	events = new EventSource("/api/v1/events");
+
+	events.onopen = () => {
+		updateConnectionStatus("connected");
+		stopStatusPolling();
+	};
+
+	events.addEventListener("status", (event) => {
+		try {
+			updateUI(JSON.parse(event.data));
+		} catch (err) {
+			console.error("failed to parse status event:", err);
+		}
+	});
+
+	events.onerror = () => {
+		updateConnectionStatus("disconnected");
+		startStatusPolling();
+	};
+}
+
+// spectrum stream: bars, the waveform envelope and input meters, drawn
+// as they arrive
+function connectSpectrum() {
+	const protocol = window.location.protocol === "https:" ? "wss:" : "ws:";
+	const socket = new WebSocket(`${protocol}//${window.location.host}/ws/spectrum`);
+
+	socket.onmessage = (event) => {
+		try {
+			drawSpectrum(JSON.parse(event.data));
+		} catch (err) {
+			console.error("failed to parse spectrum message:", err);
+		}
+	};
+
+	socket.onclose = () => {
+		setTimeout(connectSpectrum, 2000);
+	};
+}
+
+const SPECTRUM_BANDS = ["sub", "bass", "lowMid", "mid", "highMid", "treble"];
+const METER_WIDTH = 12;
+
+function drawSpectrum(data) {
+	const canvas = document.getElementById("spectrum");
+	if (!canvas) return;
+	const ctx = canvas.getContext("2d");
+	const w = canvas.width;
+	const h = canvas.height;
+	const accent = getComputedStyle(document.documentElement).getPropertyValue("--accent").trim();
+	ctx.clearRect(0, 0, w, h);
+
+	// level and peak meters on the right, bars and envelope left of them
+	const meters = 2 * (METER_WIDTH + 4);
+	const plotW = w - meters;
+	const bandH = 12;
+	const plotH = h - bandH - 4;
+
+	const bars = data.spectrum || [];
+	const barW = plotW / Math.max(bars.length, 1);
+	ctx.fillStyle = accent;
+	bars.forEach((v, i) => {
+		const bh = Math.min(v, 1) * plotH;
+		ctx.fillRect(i * barW, plotH - bh, Math.max(barW - 1, 1), bh);
+	});
+
+	const env = data.envelope || [];
+	if (env.length > 1) {
+		ctx.strokeStyle = data.beat ? "#fff" : "rgba(255, 255, 255, 0.5)";
+		ctx.beginPath();
+		const mid = plotH / 2;
+		env.forEach((v, i) => {
+			const x = (i / (env.length - 1)) * plotW;
+			ctx[i ? "lineTo" : "moveTo"](x, mid - (Math.min(v, 1) * plotH) / 2);
+		});
+		for (let i = env.length - 1; i >= 0; i--) {
+			const x = (i / (env.length - 1)) * plotW;
+			ctx.lineTo(x, mid + (Math.min(env[i], 1) * plotH) / 2);
+		}
+		ctx.stroke();
+	}
+
+	// the six gated bands the patterns react to, along the bottom
+	const bandW = plotW / SPECTRUM_BANDS.length;
+	SPECTRUM_BANDS.forEach((name, i) => {
+		const v = Math.min((data.bands || {})[name] || 0, 1);
+		ctx.fillStyle = "rgba(255, 255, 255, 0.15)";
+		ctx.fillRect(i * bandW, h - bandH, bandW - 2, bandH);
+		ctx.fillStyle = accent;
+		ctx.fillRect(i * bandW, h - bandH, (bandW - 2) * v, bandH);
+	});
+
+	[data.level, data.peak].forEach((v, i) => {
+		const x = plotW + 4 + i * (METER_WIDTH + 4);
+		const mh = Math.min(v || 0, 1) * h;
+		ctx.fillStyle = "rgba(255, 255, 255, 0.15)";
+		ctx.fillRect(x, 0, METER_WIDTH, h);
+		ctx.fillStyle = v >= 0.99 ? "#e05050" : accent;
+		ctx.fillRect(x, h - mh, METER_WIDTH, mh);
+	});
+}
+
 function updateConnectionStatus(status) {
 	const indicator = document.getElementById("connection");
 	indicator.className = `status-indicator ${status}`;
@@ -120,7 +762,7 @@ function stopStatusPolling() {
 
 async function fetchStatusSnapshot() {
 	try {
-		const response = await fetch("/api/status");
+		const response = await fetch("/api/v1/status");
 		const data = await response.json();
 		updateUI(data);
 	} catch (err) {
@@ -138,16 +780,51 @@ function updateUI(data) {
 	}
 
 	if (data.features) {
+		document.getElementById("sub").textContent = data.features.Sub.toFixed(2);
 		document.getElementById("bass").textContent = data.features.Bass.toFixed(2);
+		document.getElementById("lowMid").textContent =
+			data.features.LowMid.toFixed(2);
+		document.getElementById("highMid").textContent =
+			data.features.HighMid.toFixed(2);
 		document.getElementById("mid").textContent = data.features.Mid.toFixed(2);
 		document.getElementById("treble").textContent =
 			data.features.Treble.toFixed(2);
 		document.getElementById("beat").textContent =
 			data.features.BeatStrength.toFixed(2);
+		document.getElementById("harmonic").textContent =
+			data.features.Harmonic.toFixed(2);
+		document.getElementById("percussive").textContent =
+			data.features.Percussive.toFixed(2);
+		document.getElementById("tempo").textContent =
+			data.features.Tempo > 0
+				? `${data.features.Tempo.toFixed(0)}${
+						data.midiClock ? " (midi)" : data.tapTempo ? " (tap)" : ""
+					}`
+				: "--";
+	}
+
+	if (data.playlist) {
+		const step = data.playlist.playing ? data.playlist.step : -1;
+		if (step !== playlistStep) {
+			playlistStep = step;
+			document
+				.querySelectorAll("#playlist-steps .control-group")
+				.forEach((group, index) =>
+					group.classList.toggle("active", index === step)
+				);
+		}
+	}
+
+	if (data.randomLocks) {
+		document.getElementById("lockPattern").checked = data.randomLocks.pattern;
+		document.getElementById("lockPalette").checked = data.randomLocks.palette;
+		document.getElementById("lockColorMode").checked =
+			data.randomLocks.colorMode;
 	}
 
 	if (data.renderer) {
 		setSelectValue("pattern", data.renderer.pattern);
+		loadPatternParams(data.renderer.pattern);
 		setSelectValue("palette", data.renderer.palette);
 		setSelectValue("colorMode", data.renderer.colorMode);
 	}
@@ -163,10 +840,25 @@ function updateUI(data) {
 		}
 	}
 
+	if (data.paused !== undefined) {
+		const pauseToggle = document.getElementById("paused");
+		if (pauseToggle) {
+			pauseToggle.checked = data.paused;
+		}
+	}
+
 	if (data.quality) {
 		setSelectValue("quality", data.quality);
 	}
 
+	if (data.envelopes) {
+		renderEnvelopes(data.envelopes);
+	}
+
+	if (data.readOnly) {
+		setReadOnly();
+	}
+
 	if (data.params) {
 		updateParam("frequency", data.params.Frequency);
 		updateParam("amplitude", data.params.Amplitude);
@@ -175,12 +867,88 @@ function updateUI(data) {
 		updateParam("contrast", data.params.Contrast);
 		updateParam("saturation", data.params.Saturation);
 		updateParam("beatSensitivity", data.params.BeatSensitivity);
+		updateParam("subInfluence", data.params.SubInfluence);
 		updateParam("bassInfluence", data.params.BassInfluence);
+		updateParam("lowMidInfluence", data.params.LowMidInfluence);
 		updateParam("midInfluence", data.params.MidInfluence);
+		updateParam("highMidInfluence", data.params.HighMidInfluence);
 		updateParam("trebleInfluence", data.params.TrebleInfluence);
 	}
 }
 
+// per-band attack/release, built from the first status that carries them
+const ENVELOPE_BANDS = ["sub", "bass", "lowMid", "highMid", "mid", "treble"];
+const ENVELOPE_MAX_MS = 1000;
+let envelopesBuilt = false;
+let envelopeTimeout = null;
+
+function renderEnvelopes(envelopes) {
+	const container = document.getElementById("envelopes-list");
+	if (!container) return;
+	if (envelopesBuilt) {
+		ENVELOPE_BANDS.forEach((band) => {
+			["attackMs", "releaseMs"].forEach((key) => {
+				const input = document.getElementById(`env-${band}-${key}`);
+				if (input && document.activeElement !== input) {
+					input.value = envelopes[band][key];
+					input.title = `${band} ${key}: ${Math.round(input.value)}`;
+				}
+			});
+		});
+		return;
+	}
+	envelopesBuilt = true;
+	container.innerHTML = "";
+
+	ENVELOPE_BANDS.forEach((band) => {
+		const row = document.createElement("div");
+		row.className = "envelope-row";
+		const name = document.createElement("span");
+		name.textContent = band;
+		row.appendChild(name);
+
+		["attackMs", "releaseMs"].forEach((key) => {
+			const input = document.createElement("input");
+			input.type = "range";
+			input.id = `env-${band}-${key}`;
+			input.min = 0;
+			input.max = ENVELOPE_MAX_MS;
+			input.step = 1;
+			input.value = envelopes[band][key];
+			input.title = `${band} ${key}: ${Math.round(input.value)}`;
+			input.addEventListener("input", () => {
+				input.title = `${band} ${key}: ${Math.round(input.value)}`;
+				clearTimeout(envelopeTimeout);
+				envelopeTimeout = setTimeout(() => sendEnvelope(band), 150);
+			});
+			row.appendChild(input);
+		});
+		container.appendChild(row);
+	});
+}
+
+function sendEnvelope(band) {
+	const attack = document.getElementById(`env-${band}-attackMs`);
+	const release = document.getElementById(`env-${band}-releaseMs`);
+	sendUpdate({
+		envelopes: {
+			[band]: {
+				attackMs: parseFloat(attack.value),
+				releaseMs: parseFloat(release.value),
+			},
+		},
+	});
+}
+
+// kiosk mode: the server rejects writes, so lock every control
+function setReadOnly() {
+	if (document.body.classList.contains("read-only")) return;
+	document.body.classList.add("read-only");
+	document
+		.querySelectorAll("input, select, button")
+		.forEach((el) => (el.disabled = true));
+}
+
 function updateParam(id, value) {
 	const input = document.getElementById(id);
 	if (input && input.value != value) {
@@ -254,6 +1022,14 @@ function setupControls() {
 		});
 	}
 
+	// pause
+	const pauseToggle = document.getElementById("paused");
+	if (pauseToggle) {
+		pauseToggle.addEventListener("change", (e) => {
+			sendUpdate({ paused: e.target.checked });
+		});
+	}
+
 	// critical sliders that affect visuals directly - send immediately
 	const immediateSliders = [
 		"frequency",
@@ -282,8 +1058,11 @@ function setupControls() {
 		"height",
 		"speed",
 		"beatSensitivity",
+		"subInfluence",
 		"bassInfluence",
+		"lowMidInfluence",
 		"midInfluence",
+		"highMidInfluence",
 		"trebleInfluence",
 		"randomInterval",
 	];
@@ -306,12 +1085,15 @@ function setupControls() {
 	document
 		.getElementById("autoRandomize")
 		.addEventListener("change", debouncedUpdate);
+	["lockPattern", "lockPalette", "lockColorMode"].forEach((id) =>
+		document.getElementById(id).addEventListener("change", debouncedUpdate)
+	);
 
 	// buttons
 	document.getElementById("randomizeBtn").addEventListener("click", () => {
 		// trigger randomize via pattern change
 		const patternSelector = document.getElementById("pattern-selector");
-		if (patternSelector) {
+		if (patternSelector && !document.getElementById("lockPattern").checked) {
 			const patterns = Array.from(
 				patternSelector.querySelectorAll(".option-btn")
 			).map((b) => b.dataset.value);
@@ -329,9 +1111,44 @@ function setupControls() {
 		}
 	});
 
+	document.getElementById("presetSave").addEventListener("click", savePreset);
+	document.getElementById("fleetScan").addEventListener("click", scanFleet);
+	document.getElementById("playlistAdd").addEventListener("click", () => {
+		playlistState.steps.push({ preset: presetNames[0] || "", bars: 16 });
+		renderPlaylist();
+		sendPlaylist();
+	});
+	document.getElementById("playlistAutoplay").addEventListener("change", (e) => {
+		playlistState.autoplay = e.target.checked;
+		sendPlaylist();
+	});
+	document
+		.getElementById("playlistPlay")
+		.addEventListener("click", () => playlistControl("play"));
+	document
+		.getElementById("playlistStop")
+		.addEventListener("click", () => playlistControl("stop"));
+
 	// save button
 	document.getElementById("saveBtn").addEventListener("click", saveConfig);
 
+	// noise calibration
+	const calibrateBtn = document.getElementById("calibrateBtn");
+	if (calibrateBtn) {
+		calibrateBtn.addEventListener("click", calibrateNoise);
+	}
+
+	const restartAudioBtn = document.getElementById("restartAudioBtn");
+	if (restartAudioBtn) {
+		restartAudioBtn.addEventListener("click", restartAudio);
+	}
+
+	// tap tempo
+	const tapBtn = document.getElementById("tapBtn");
+	if (tapBtn) {
+		tapBtn.addEventListener("click", tapTempo);
+	}
+
 	// buffer size selector
 	const bufferButtons = document.querySelectorAll(
 		"#bufferSize-options .option-btn"
@@ -345,6 +1162,79 @@ function setupControls() {
 	});
 }
 
+// restartAudio reopens the sound card so a new buffer size takes effect.
+function restartAudio() {
+	const btn = document.getElementById("restartAudioBtn");
+	const bufferSize = parseInt(document.getElementById("bufferSize").value, 10);
+	btn.disabled = true;
+	btn.textContent = "restarting...";
+	fetch("/api/v1/audio/restart", {
+		method: "POST",
+		headers: { "Content-Type": "application/json" },
+		body: JSON.stringify({ bufferSize }),
+	})
+		.then((r) => {
+			if (!r.ok) {
+				return r.json().then((body) => Promise.reject(new Error(body.error.message)));
+			}
+			return r.json();
+		})
+		.then((info) => {
+			btn.textContent = `${info.device} @ ${Math.round(info.sampleRate)} Hz`;
+		})
+		.catch((err) => {
+			console.error("audio restart failed:", err);
+			btn.textContent = "restart failed";
+		})
+		.finally(() => {
+			setTimeout(() => {
+				btn.disabled = false;
+				btn.textContent = "restart audio";
+			}, 2000);
+		});
+}
+
+function tapTempo() {
+	const btn = document.getElementById("tapBtn");
+	fetch("/api/v1/tap", { method: "POST" })
+		.then((r) => (r.ok ? r.json() : Promise.reject(new Error(r.statusText))))
+		.then((data) => {
+			btn.textContent = data.bpm > 0 ? `tap tempo (${data.bpm.toFixed(0)})` : "tap tempo";
+		})
+		.catch((err) => console.error("tap failed:", err));
+}
+
+function calibrateNoise() {
+	const btn = document.getElementById("calibrateBtn");
+	btn.disabled = true;
+	btn.textContent = "stay quiet... (5s)";
+
+	fetch("/api/v1/calibrate", {
+		method: "POST",
+		headers: { "Content-Type": "application/json" },
+		body: JSON.stringify({ seconds: 5 }),
+	})
+		.then((r) => {
+			if (!r.ok) {
+				return r.json().then((body) => Promise.reject(new Error(body.error.message)));
+			}
+			return r.json();
+		})
+		.then(() => {
+			btn.textContent = "✓ calibrated (SAVE to keep)";
+		})
+		.catch((err) => {
+			console.error("calibration failed:", err);
+			btn.textContent = "✗ calibration failed";
+		})
+		.finally(() => {
+			setTimeout(() => {
+				btn.textContent = "calibrate noise";
+				btn.disabled = false;
+			}, 2500);
+		});
+}
+
 function saveConfig() {
 	const btn = document.getElementById("saveBtn");
 	btn.classList.add("saving");
@@ -383,8 +1273,11 @@ function saveConfig() {
 		"contrast",
 		"saturation",
 		"beatSensitivity",
+		"subInfluence",
 		"bassInfluence",
+		"lowMidInfluence",
 		"midInfluence",
+		"highMidInfluence",
 		"trebleInfluence",
 	];
 
@@ -397,7 +1290,7 @@ function saveConfig() {
 		}
 	});
 
-	fetch("/api/save", {
+	fetch("/api/v1/save", {
 		method: "POST",
 		headers: { "Content-Type": "application/json" },
 		body: JSON.stringify(config),
@@ -447,8 +1340,11 @@ function sendUpdate(baseUpdates = null) {
 			"contrast",
 			"saturation",
 			"beatSensitivity",
+			"subInfluence",
 			"bassInfluence",
+			"lowMidInfluence",
 			"midInfluence",
+			"highMidInfluence",
 			"trebleInfluence",
 		];
 
@@ -517,6 +1413,12 @@ function sendUpdate(baseUpdates = null) {
 		return ri ? parseInt(ri.value, 10) : undefined;
 	});
 
+	ensureConfigValue("randomLocks", () => ({
+		pattern: document.getElementById("lockPattern").checked,
+		palette: document.getElementById("lockPalette").checked,
+		colorMode: document.getElementById("lockColorMode").checked,
+	}));
+
 	ensureConfigValue("showStatusBar", () => {
 		const sb = document.getElementById("showStatusBar");
 		return sb ? sb.checked : undefined;
@@ -533,7 +1435,7 @@ function sendParamUpdate(id, rawValue) {
 }
 
 function postUpdatePayload(payload) {
-	fetch("/api/update", {
+	fetch("/api/v1/update", {
 		method: "POST",
 		headers: { "Content-Type": "application/json" },
 		body: JSON.stringify(payload),
@@ -0,0 +1,189 @@
+// remote view: renders the terminal frames streamed over /ws/view, or polls
+// snapshots when the visualizer draws pixels
+const STILL_INTERVAL = 250;
+const screen = document.getElementById("screen");
+const still = document.getElementById("still");
+const viewStatus = document.getElementById("viewStatus");
+const palette = buildPalette();
+let rows = [];
+let columns = 0;
+let height = 0;
+
+document.addEventListener("DOMContentLoaded", connectView);
+window.addEventListener("resize", fitScreen);
+
+function connectView() {
+	const protocol = window.location.protocol === "https:" ? "wss:" : "ws:";
+	const fps = new URLSearchParams(window.location.search).get("fps");
+	const ws = new WebSocket(
+		`${protocol}//${window.location.host}/ws/view${fps ? `?fps=${fps}` : ""}`,
+	);
+	let pixels = false;
+
+	ws.onopen = () => {
+		viewStatus.textContent = "";
+	};
+
+	ws.onmessage = (event) => {
+		const msg = JSON.parse(event.data);
+		if (msg.image) {
+			pixels = true;
+			showStills();
+			return;
+		}
+		applyFrame(msg);
+	};
+
+	ws.onclose = () => {
+		if (pixels) {
+			return;
+		}
+		viewStatus.textContent = "disconnected, retrying...";
+		setTimeout(connectView, 2000);
+	};
+}
+
+// applyFrame replaces the rows that changed
+function applyFrame(msg) {
+	while (rows.length < msg.height) {
+		const row = document.createElement("div");
+		screen.appendChild(row);
+		rows.push({ el: row, width: 0 });
+	}
+	while (rows.length > msg.height) {
+		rows.pop().el.remove();
+	}
+	let resized = false;
+	for (const [index, line] of Object.entries(msg.rows || {})) {
+		const row = rows[Number(index)];
+		if (!row) {
+			continue;
+		}
+		row.width = renderRow(row.el, line);
+		resized = true;
+	}
+	if (resized) {
+		const widest = rows.reduce((w, row) => Math.max(w, row.width), 0);
+		if (widest !== columns || msg.height !== height) {
+			columns = widest;
+			height = msg.height;
+			fitScreen();
+		}
+	}
+}
+
+// renderRow turns one ANSI row into coloured spans and returns its width
+function renderRow(el, line) {
+	const fragment = document.createDocumentFragment();
+	let fg = null;
+	let bg = null;
+	let text = "";
+	let width = 0;
+
+	const flush = () => {
+		if (!text) {
+			return;
+		}
+		const span = document.createElement("span");
+		span.textContent = text;
+		if (fg !== null) {
+			span.style.color = palette[fg];
+		}
+		if (bg !== null) {
+			span.style.background = palette[bg];
+		}
+		fragment.appendChild(span);
+		text = "";
+	};
+
+	const re = /\x1b\[([0-9;]*)([A-Za-z])/g;
+	let last = 0;
+	let match;
+	while ((match = re.exec(line)) !== null) {
+		text += line.slice(last, match.index);
+		last = re.lastIndex;
+		if (match[2] !== "m") {
+			continue;
+		}
+		flush();
+		const params = match[1].split(";");
+		for (let i = 0; i < params.length; i++) {
+			const p = params[i];
+			if (p === "" || p === "0") {
+				fg = null;
+				bg = null;
+			} else if (p === "39") {
+				fg = null;
+			} else if (p === "49") {
+				bg = null;
+			} else if ((p === "38" || p === "48") && params[i + 1] === "5") {
+				const n = Number(params[i + 2]);
+				if (p === "38") {
+					fg = n;
+				} else {
+					bg = n;
+				}
+				i += 2;
+			}
+		}
+	}
+	text += line.slice(last);
+	flush();
+
+	for (const node of fragment.childNodes) {
+		width += [...node.textContent].length;
+	}
+	el.replaceChildren(fragment);
+	return width;
+}
+
+// fitScreen scales the font so the whole frame fits the window
+function fitScreen() {
+	if (!columns || !height) {
+		return;
+	}
+	// monospace cells are roughly 0.6em wide
+	const size = Math.min(
+		window.innerWidth / (columns * 0.6),
+		window.innerHeight / height,
+	);
+	screen.style.fontSize = `${Math.max(2, Math.floor(size * 10) / 10)}px`;
+}
+
+function showStills() {
+	screen.hidden = true;
+	still.hidden = false;
+	viewStatus.textContent = "";
+	const next = () => {
+		const img = new Image();
+		img.onload = () => {
+			still.src = img.src;
+			setTimeout(next, STILL_INTERVAL);
+		};
+		img.onerror = () => setTimeout(next, 2000);
+		img.src = `/api/v1/snapshot.png?t=${Date.now()}`;
+	};
+	next();
+}
+
+// buildPalette returns the xterm 256-colour palette as css colours
+function buildPalette() {
+	const colors = [
+		"#000000", "#cd0000", "#00cd00", "#cdcd00",
+		"#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+		"#7f7f7f", "#ff0000", "#00ff00", "#ffff00",
+		"#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+	];
+	const levels = [0, 95, 135, 175, 215, 255];
+	for (let i = 0; i < 216; i++) {
+		const r = levels[Math.floor(i / 36)];
+		const g = levels[Math.floor(i / 6) % 6];
+		const b = levels[i % 6];
+		colors.push(`rgb(${r},${g},${b})`);
+	}
+	for (let i = 0; i < 24; i++) {
+		const v = 8 + 10 * i;
+		colors.push(`rgb(${v},${v},${v})`);
+	}
+	return colors;
+}